@@ -0,0 +1,451 @@
+// Package arrow converts godbc result sets into record batches in the Apache
+// Arrow columnar format, so data can move from ODBC to Arrow consumers such
+// as Parquet writers without building a Go value per row. Batches are read
+// with godbc's NextBatch, so with WithRowArraySize each record batch is one
+// block-fetched rowset.
+//
+// The package has no dependencies. Each column holds its Arrow buffers (the
+// validity bitmap, then the values, or the offsets and data of strings), which
+// the Apache Arrow Go library wraps without copying:
+//
+//	col := batch.Columns[0] // an Int64 column
+//	buffers := make([]*memory.Buffer, len(col.Buffers))
+//	for i, b := range col.Buffers {
+//	    if b != nil {
+//	        buffers[i] = memory.NewBufferBytes(b)
+//	    }
+//	}
+//	data := array.NewData(arrow.PrimitiveTypes.Int64, col.Len, buffers, nil, col.NullCount, 0)
+//	ids := array.NewInt64Data(data)
+package arrow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/slingdata-io/godbc"
+)
+
+// Type is the Arrow data type of a column
+type Type int
+
+const (
+	Boolean        Type = iota + 1 // bool, bit-packed
+	Int64                          // int64
+	Uint64                         // uint64
+	Float64                        // float64
+	Utf8                           // UTF-8 string with int32 offsets
+	Binary                         // Bytes with int32 offsets
+	Date32                         // Days since the Unix epoch, as int32
+	TimestampMicro                 // Microseconds since the Unix epoch in UTC, as int64; finer digits are dropped
+	TimestampNano                  // Nanoseconds since the Unix epoch in UTC, as int64
+	Time64                         // Nanoseconds since midnight, as int64
+)
+
+// String returns the Arrow name of the type
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "bool"
+	case Int64:
+		return "int64"
+	case Uint64:
+		return "uint64"
+	case Float64:
+		return "float64"
+	case Utf8:
+		return "utf8"
+	case Binary:
+		return "binary"
+	case Date32:
+		return "date32[day]"
+	case TimestampMicro:
+		return "timestamp[us, tz=UTC]"
+	case TimestampNano:
+		return "timestamp[ns, tz=UTC]"
+	case Time64:
+		return "time64[ns]"
+	}
+	return fmt.Sprintf("Type(%d)", int(t))
+}
+
+// Field describes a column of the schema
+type Field struct {
+	Name     string
+	Type     Type
+	Nullable bool
+}
+
+// Schema describes the columns of the record batches of a Reader
+type Schema struct {
+	Fields []Field
+}
+
+// Column is an Arrow array of Len values
+type Column struct {
+	Type      Type
+	Len       int
+	NullCount int
+
+	// Buffers are the Arrow buffers of the array: the validity bitmap, with
+	// bit i set if value i is not null (nil if there are no nulls), then the
+	// values for fixed-width types, or the int32 offsets and the data for
+	// Utf8 and Binary. Values are little-endian.
+	Buffers [][]byte
+}
+
+// RecordBatch is a batch of rows held as one Arrow array per column
+type RecordBatch struct {
+	Schema  *Schema
+	NumRows int
+	Columns []Column
+}
+
+// Reader reads the rows of a result set as record batches
+type Reader struct {
+	rows    *godbc.Rows
+	schema  *Schema
+	convert []func(godbc.ColumnVector, int) (Column, error)
+	batch   godbc.ColumnBatch
+}
+
+// NewReader returns a Reader for the current result set of rows. The rows
+// must not be read with Next while the Reader is in use.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    rows, err := dc.(driver.QueryerContext).QueryContext(ctx, "SELECT * FROM sales", nil)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    defer rows.Close()
+//	    r := arrow.NewReader(rows.(*godbc.Rows))
+//	    for {
+//	        batch, err := r.Next()
+//	        if err == io.EOF {
+//	            return nil
+//	        } else if err != nil {
+//	            return err
+//	        }
+//	        writeParquet(batch)
+//	    }
+//	})
+func NewReader(rows *godbc.Rows) *Reader {
+	names := rows.Columns()
+	r := &Reader{
+		rows:    rows,
+		schema:  &Schema{Fields: make([]Field, len(names))},
+		convert: make([]func(godbc.ColumnVector, int) (Column, error), len(names)),
+	}
+	for i, name := range names {
+		typ, convert := columnType(rows, i)
+		nullable, ok := rows.ColumnTypeNullable(i)
+		r.schema.Fields[i] = Field{Name: name, Type: typ, Nullable: nullable || !ok}
+		r.convert[i] = convert
+	}
+	return r
+}
+
+// Schema returns the schema of the record batches
+func (r *Reader) Schema() *Schema {
+	return r.schema
+}
+
+// Next reads the next record batch. Returns io.EOF when no more rows are
+// available.
+func (r *Reader) Next() (*RecordBatch, error) {
+	if err := r.rows.NextBatch(&r.batch); err != nil {
+		return nil, err
+	}
+	rb := &RecordBatch{Schema: r.schema, NumRows: r.batch.Len, Columns: make([]Column, len(r.convert))}
+	for i, convert := range r.convert {
+		col, err := convert(r.batch.Columns[i], r.batch.Len)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", r.schema.Fields[i].Name, err)
+		}
+		rb.Columns[i] = col
+	}
+	return rb, nil
+}
+
+// columnType returns the Arrow type of a column and the function converting
+// its vectors
+func columnType(rows *godbc.Rows, i int) (Type, func(godbc.ColumnVector, int) (Column, error)) {
+	sqlType := rows.ColumnSQLType(i)
+	switch rows.ColumnKind(i) {
+	case godbc.ColumnInt64:
+		if sqlType == godbc.SQL_TYPE_TIMESTAMP || sqlType == godbc.SQL_DATETIME {
+			return TimestampNano, int64Column(TimestampNano)
+		}
+		return Int64, int64Column(Int64)
+	case godbc.ColumnFloat64:
+		return Float64, float64Column
+	case godbc.ColumnTime:
+		if sqlType == godbc.SQL_TYPE_DATE {
+			return Date32, dateColumn
+		}
+		if isTimeOfDay(sqlType) {
+			return Time64, time64Column
+		}
+		return TimestampMicro, timeColumn
+	}
+
+	switch rows.ColumnTypeScanType(i) {
+	case reflect.TypeOf(false):
+		return Boolean, boolColumn
+	case reflect.TypeOf(int64(0)):
+		return Int64, int64ValueColumn
+	case reflect.TypeOf(uint64(0)):
+		return Uint64, uint64Column
+	case reflect.TypeOf(float64(0)), reflect.TypeOf(float32(0)):
+		return Float64, float64ValueColumn
+	case reflect.TypeOf(time.Time{}):
+		if isTimeOfDay(sqlType) {
+			return Time64, valueTime64Column
+		}
+		return TimestampMicro, valueTimeColumn
+	case reflect.TypeOf([]byte{}):
+		return Binary, varColumn(Binary)
+	}
+	return Utf8, varColumn(Utf8)
+}
+
+// isTimeOfDay reports whether a SQL type holds a time of day without a date
+func isTimeOfDay(sqlType godbc.SQLSMALLINT) bool {
+	return sqlType == godbc.SQL_TYPE_TIME || sqlType == godbc.SQL_SS_TIME2
+}
+
+// nanosOfDay returns the nanoseconds since midnight of the time of day of t
+func nanosOfDay(t time.Time) int64 {
+	h, m, s := t.Clock()
+	return (int64(h)*3600+int64(m)*60+int64(s))*int64(time.Second) + int64(t.Nanosecond())
+}
+
+// newColumn returns a column of n values with the validity bitmap of nulls
+// and a values buffer of size bytes
+func newColumn(typ Type, nulls []bool, n, size int) Column {
+	col := Column{Type: typ, Len: n, Buffers: [][]byte{nil, make([]byte, size)}}
+	for _, null := range nulls[:n] {
+		if null {
+			col.NullCount++
+		}
+	}
+	if col.NullCount > 0 {
+		validity := make([]byte, (n+7)/8)
+		for j, null := range nulls[:n] {
+			if !null {
+				validity[j/8] |= 1 << (j % 8)
+			}
+		}
+		col.Buffers[0] = validity
+	}
+	return col
+}
+
+// int64Column converts integer and epoch timestamp vectors
+func int64Column(typ Type) func(godbc.ColumnVector, int) (Column, error) {
+	return func(v godbc.ColumnVector, n int) (Column, error) {
+		col := newColumn(typ, v.Nulls, n, 8*n)
+		for j, x := range v.Int64[:n] {
+			binary.LittleEndian.PutUint64(col.Buffers[1][8*j:], uint64(x))
+		}
+		return col, nil
+	}
+}
+
+// float64Column converts floating point vectors
+func float64Column(v godbc.ColumnVector, n int) (Column, error) {
+	col := newColumn(Float64, v.Nulls, n, 8*n)
+	for j, x := range v.Float64[:n] {
+		binary.LittleEndian.PutUint64(col.Buffers[1][8*j:], math.Float64bits(x))
+	}
+	return col, nil
+}
+
+// timeColumn converts timestamp vectors to microseconds since the epoch,
+// dropping sub-microsecond digits; with godbc.TimestampFetchEpochNanos
+// timestamps are read as nanoseconds and converted by int64Column instead
+func timeColumn(v godbc.ColumnVector, n int) (Column, error) {
+	col := newColumn(TimestampMicro, v.Nulls, n, 8*n)
+	for j, t := range v.Time[:n] {
+		if !v.Nulls[j] {
+			binary.LittleEndian.PutUint64(col.Buffers[1][8*j:], uint64(t.UnixMicro()))
+		}
+	}
+	return col, nil
+}
+
+// time64Column converts time of day vectors to nanoseconds since midnight
+func time64Column(v godbc.ColumnVector, n int) (Column, error) {
+	col := newColumn(Time64, v.Nulls, n, 8*n)
+	for j, t := range v.Time[:n] {
+		if !v.Nulls[j] {
+			binary.LittleEndian.PutUint64(col.Buffers[1][8*j:], uint64(nanosOfDay(t)))
+		}
+	}
+	return col, nil
+}
+
+// dateColumn converts date vectors to days since the epoch
+func dateColumn(v godbc.ColumnVector, n int) (Column, error) {
+	col := newColumn(Date32, v.Nulls, n, 4*n)
+	for j, t := range v.Time[:n] {
+		if !v.Nulls[j] {
+			days := t.Unix() / 86400
+			if t.Unix() < 0 && t.Unix()%86400 != 0 {
+				days--
+			}
+			binary.LittleEndian.PutUint32(col.Buffers[1][4*j:], uint32(int32(days)))
+		}
+	}
+	return col, nil
+}
+
+// boolColumn converts vectors of bool values to a bit-packed array
+func boolColumn(v godbc.ColumnVector, n int) (Column, error) {
+	col := newColumn(Boolean, v.Nulls, n, (n+7)/8)
+	for j, val := range v.Values[:n] {
+		if val == nil {
+			continue
+		}
+		b, ok := val.(bool)
+		if !ok {
+			return Column{}, fmt.Errorf("%T value in a bool column", val)
+		}
+		if b {
+			col.Buffers[1][j/8] |= 1 << (j % 8)
+		}
+	}
+	return col, nil
+}
+
+// uint64Column converts vectors of unsigned BIGINT values
+func uint64Column(v godbc.ColumnVector, n int) (Column, error) {
+	col := newColumn(Uint64, v.Nulls, n, 8*n)
+	for j, val := range v.Values[:n] {
+		if val == nil {
+			continue
+		}
+		x, ok := val.(uint64)
+		if !ok {
+			return Column{}, fmt.Errorf("%T value in a uint64 column", val)
+		}
+		binary.LittleEndian.PutUint64(col.Buffers[1][8*j:], x)
+	}
+	return col, nil
+}
+
+// int64ValueColumn converts vectors of int64 values, such as cast columns
+func int64ValueColumn(v godbc.ColumnVector, n int) (Column, error) {
+	col := newColumn(Int64, v.Nulls, n, 8*n)
+	for j, val := range v.Values[:n] {
+		if val == nil {
+			continue
+		}
+		x, ok := val.(int64)
+		if !ok {
+			return Column{}, fmt.Errorf("%T value in an int64 column", val)
+		}
+		binary.LittleEndian.PutUint64(col.Buffers[1][8*j:], uint64(x))
+	}
+	return col, nil
+}
+
+// float64ValueColumn converts vectors of floating point values, such as cast
+// columns
+func float64ValueColumn(v godbc.ColumnVector, n int) (Column, error) {
+	col := newColumn(Float64, v.Nulls, n, 8*n)
+	for j, val := range v.Values[:n] {
+		var f float64
+		switch val := val.(type) {
+		case nil:
+			continue
+		case float64:
+			f = val
+		case float32:
+			f = float64(val)
+		default:
+			return Column{}, fmt.Errorf("%T value in a float64 column", val)
+		}
+		binary.LittleEndian.PutUint64(col.Buffers[1][8*j:], math.Float64bits(f))
+	}
+	return col, nil
+}
+
+// valueTimeColumn converts vectors of time.Time values, such as TIMESTAMP
+// WITH TIME ZONE columns, to microseconds since the epoch
+func valueTimeColumn(v godbc.ColumnVector, n int) (Column, error) {
+	col := newColumn(TimestampMicro, v.Nulls, n, 8*n)
+	for j, val := range v.Values[:n] {
+		if val == nil {
+			continue
+		}
+		t, ok := val.(time.Time)
+		if !ok {
+			return Column{}, fmt.Errorf("%T value in a timestamp column", val)
+		}
+		binary.LittleEndian.PutUint64(col.Buffers[1][8*j:], uint64(t.UnixMicro()))
+	}
+	return col, nil
+}
+
+// valueTime64Column converts vectors of time.Time values of TIME columns to
+// nanoseconds since midnight
+func valueTime64Column(v godbc.ColumnVector, n int) (Column, error) {
+	col := newColumn(Time64, v.Nulls, n, 8*n)
+	for j, val := range v.Values[:n] {
+		if val == nil {
+			continue
+		}
+		t, ok := val.(time.Time)
+		if !ok {
+			return Column{}, fmt.Errorf("%T value in a time column", val)
+		}
+		binary.LittleEndian.PutUint64(col.Buffers[1][8*j:], uint64(nanosOfDay(t)))
+	}
+	return col, nil
+}
+
+// varColumn converts vectors of variable-length values. Strings and byte
+// slices are copied as they are, streamed large objects are read whole, and
+// other values, such as godbc.Decimal, are stored in their string form.
+func varColumn(typ Type) func(godbc.ColumnVector, int) (Column, error) {
+	return func(v godbc.ColumnVector, n int) (Column, error) {
+		col := newColumn(typ, v.Nulls, n, 4*(n+1))
+		offsets := col.Buffers[1]
+		var data []byte
+		for j, val := range v.Values[:n] {
+			switch val := val.(type) {
+			case nil:
+			case string:
+				data = append(data, val...)
+			case []byte:
+				data = append(data, val...)
+			case io.Reader:
+				b, err := io.ReadAll(val)
+				if err != nil {
+					return Column{}, err
+				}
+				data = append(data, b...)
+			case fmt.Stringer:
+				data = append(data, val.String()...)
+			default:
+				data = fmt.Append(data, val)
+			}
+			if len(data) > math.MaxInt32 {
+				return Column{}, fmt.Errorf("batch data exceeds the 2 GiB limit of %s offsets", typ)
+			}
+			binary.LittleEndian.PutUint32(offsets[4*(j+1):], uint32(len(data)))
+		}
+		if data == nil {
+			data = []byte{}
+		}
+		col.Buffers = append(col.Buffers, data)
+		return col, nil
+	}
+}
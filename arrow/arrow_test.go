@@ -0,0 +1,155 @@
+package arrow
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slingdata-io/godbc"
+)
+
+// int32s decodes a buffer of little-endian int32 values
+func int32s(b []byte) []int32 {
+	values := make([]int32, len(b)/4)
+	for i := range values {
+		values[i] = int32(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return values
+}
+
+// int64s decodes a buffer of little-endian int64 values
+func int64s(b []byte) []int64 {
+	values := make([]int64, len(b)/8)
+	for i := range values {
+		values[i] = int64(binary.LittleEndian.Uint64(b[8*i:]))
+	}
+	return values
+}
+
+func TestNewColumn_Validity(t *testing.T) {
+	// Nulls at 1, 8 and 9 span two bitmap bytes; only the first n count
+	nulls := []bool{false, true, false, false, false, false, false, false, true, true, true}
+	col := newColumn(Int64, nulls, 10, 80)
+	if col.Len != 10 || col.NullCount != 3 || len(col.Buffers[1]) != 80 {
+		t.Fatalf("unexpected column %+v", col)
+	}
+	if want := []byte{0b11111101, 0b00}; !bytes.Equal(col.Buffers[0], want) {
+		t.Errorf("expected validity %08b, got %08b", want, col.Buffers[0])
+	}
+
+	// Columns without nulls have no validity bitmap
+	if col := newColumn(Int64, make([]bool, 3), 3, 24); col.NullCount != 0 || col.Buffers[0] != nil {
+		t.Errorf("expected no validity bitmap, got %+v", col)
+	}
+}
+
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+func TestVarColumn(t *testing.T) {
+	v := godbc.ColumnVector{
+		Values: []driver.Value{"héllo", nil, []byte{0xde, 0xad}, stringer("12.50"), strings.NewReader("lob"), int64(7)},
+		Nulls:  []bool{false, true, false, false, false, false},
+	}
+	col, err := varColumn(Utf8)(v, len(v.Values))
+	if err != nil {
+		t.Fatalf("varColumn: %v", err)
+	}
+	if want := []int32{0, 6, 6, 8, 13, 16, 17}; !slices.Equal(int32s(col.Buffers[1]), want) {
+		t.Errorf("expected offsets %v, got %v", want, int32s(col.Buffers[1]))
+	}
+	if want := "héllo\xde\xad12.50lob7"; string(col.Buffers[2]) != want {
+		t.Errorf("expected data %q, got %q", want, col.Buffers[2])
+	}
+	if col.NullCount != 1 || !bytes.Equal(col.Buffers[0], []byte{0b111101}) {
+		t.Errorf("expected one null at 1, got %d and %08b", col.NullCount, col.Buffers[0])
+	}
+
+	// An empty batch still has its first offset and a data buffer
+	col, err = varColumn(Binary)(godbc.ColumnVector{}, 0)
+	if err != nil || len(col.Buffers) != 3 || len(col.Buffers[1]) != 4 || col.Buffers[2] == nil {
+		t.Errorf("unexpected empty column %+v (%v)", col, err)
+	}
+
+	v = godbc.ColumnVector{Values: []driver.Value{errReader{}}, Nulls: []bool{false}}
+	if _, err := varColumn(Binary)(v, 1); err == nil {
+		t.Error("expected the read error of a streamed value")
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("connection lost") }
+
+func TestDateColumn(t *testing.T) {
+	dates := []time.Time{
+		time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC), // rounds down, not toward zero
+		time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+		{},
+		time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+	}
+	nulls := []bool{false, false, false, false, true, false}
+	col, err := dateColumn(godbc.ColumnVector{Time: dates, Nulls: nulls}, len(dates))
+	if err != nil {
+		t.Fatalf("dateColumn: %v", err)
+	}
+	if want := []int32{0, -1, -1, -25567, 0, 19782}; !slices.Equal(int32s(col.Buffers[1]), want) {
+		t.Errorf("expected days %v, got %v", want, int32s(col.Buffers[1]))
+	}
+	if col.Type != Date32 || col.NullCount != 1 {
+		t.Errorf("unexpected column %+v", col)
+	}
+}
+
+func TestBoolColumn(t *testing.T) {
+	values := []driver.Value{true, false, nil, true, true, false, false, false, true, nil}
+	nulls := make([]bool, len(values))
+	for j, v := range values {
+		nulls[j] = v == nil
+	}
+	col, err := boolColumn(godbc.ColumnVector{Values: values, Nulls: nulls}, len(values))
+	if err != nil {
+		t.Fatalf("boolColumn: %v", err)
+	}
+	if want := []byte{0b00011001, 0b01}; !bytes.Equal(col.Buffers[1], want) {
+		t.Errorf("expected bits %08b, got %08b", want, col.Buffers[1])
+	}
+	if want := []byte{0b11111011, 0b01}; !bytes.Equal(col.Buffers[0], want) {
+		t.Errorf("expected validity %08b, got %08b", want, col.Buffers[0])
+	}
+
+	if _, err := boolColumn(godbc.ColumnVector{Values: []driver.Value{"yes"}, Nulls: []bool{false}}, 1); err == nil {
+		t.Error("expected an error for a non-bool value")
+	}
+}
+
+func TestTimeColumns(t *testing.T) {
+	at := time.Date(2024, 3, 9, 12, 30, 15, 123456789, time.UTC)
+	col, err := timeColumn(godbc.ColumnVector{Time: []time.Time{at}, Nulls: []bool{false}}, 1)
+	if err != nil || int64s(col.Buffers[1])[0] != at.UnixMicro() {
+		t.Errorf("expected microseconds %d, got %v (%v)", at.UnixMicro(), int64s(col.Buffers[1]), err)
+	}
+
+	// Times of day are nanoseconds since midnight, whatever the date
+	clock := time.Date(0, 1, 1, 12, 30, 15, 123456789, time.UTC)
+	want := int64(12*time.Hour + 30*time.Minute + 15*time.Second + 123456789)
+	col, err = time64Column(godbc.ColumnVector{Time: []time.Time{clock, {}}, Nulls: []bool{false, true}}, 2)
+	if err != nil || col.Type != Time64 || int64s(col.Buffers[1])[0] != want || col.NullCount != 1 {
+		t.Errorf("expected %d nanoseconds and a null, got %+v (%v)", want, col, err)
+	}
+	col, err = valueTime64Column(godbc.ColumnVector{Values: []driver.Value{clock, nil}, Nulls: []bool{false, true}}, 2)
+	if err != nil || int64s(col.Buffers[1])[0] != want || col.NullCount != 1 {
+		t.Errorf("expected %d nanoseconds and a null, got %+v (%v)", want, col, err)
+	}
+	if _, err := valueTime64Column(godbc.ColumnVector{Values: []driver.Value{"12:30"}, Nulls: []bool{false}}, 1); err == nil {
+		t.Error("expected an error for a non-time value")
+	}
+}
@@ -0,0 +1,249 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+)
+
+// maxValuesRows limits the number of rows in a single synthesized VALUES list.
+// SQL Server rejects table value constructors with more than 1000 rows.
+const maxValuesRows = 1000
+
+// multiRowInsert describes an INSERT ... VALUES (...) statement that can be
+// expanded into a multi-row INSERT ... VALUES (...), (...), ... statement
+type multiRowInsert struct {
+	prefix    string // Everything up to and including the VALUES keyword
+	row       string // The parenthesized value group, e.g. "(?, ?, ?)"
+	numParams int    // Number of ? placeholders in the value group
+}
+
+// parseMultiRowInsert parses a single-row parameterized INSERT statement.
+// Returns false if the statement is not of the form INSERT ... VALUES (...)
+// with only ? placeholders and nothing but whitespace or ';' after the value group.
+func parseMultiRowInsert(query string) (*multiRowInsert, bool) {
+	if !isInsertStatement(query) {
+		return nil, false
+	}
+
+	// Locate the VALUES keyword outside of quotes
+	valuesEnd := -1
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if c == '\'' || c == '"' {
+			i = skipQuoted(query, i)
+			continue
+		}
+		if (c == 'V' || c == 'v') && i+6 <= len(query) && strings.EqualFold(query[i:i+6], "VALUES") {
+			if i > 0 && isIdentChar(query[i-1]) {
+				continue
+			}
+			if i+6 < len(query) && isIdentChar(query[i+6]) {
+				continue
+			}
+			valuesEnd = i + 6
+			break
+		}
+	}
+	if valuesEnd < 0 {
+		return nil, false
+	}
+
+	// The value group must follow VALUES
+	start := valuesEnd
+	for start < len(query) && isSpace(query[start]) {
+		start++
+	}
+	if start >= len(query) || query[start] != '(' {
+		return nil, false
+	}
+
+	// Find the matching closing parenthesis, counting placeholders
+	depth := 0
+	numParams := 0
+	end := -1
+	for i := start; i < len(query) && end < 0; i++ {
+		switch query[i] {
+		case '\'', '"':
+			i = skipQuoted(query, i)
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				end = i + 1
+			}
+		case '?':
+			numParams++
+		}
+	}
+	if end < 0 || numParams == 0 {
+		return nil, false
+	}
+
+	// Only whitespace and a terminating semicolon may follow the value group
+	for i := end; i < len(query); i++ {
+		if !isSpace(query[i]) && query[i] != ';' {
+			return nil, false
+		}
+	}
+
+	return &multiRowInsert{
+		prefix:    query[:valuesEnd],
+		row:       query[start:end],
+		numParams: numParams,
+	}, true
+}
+
+// build returns the INSERT statement for the given number of rows
+func (m *multiRowInsert) build(rows int) string {
+	var sb strings.Builder
+	sb.Grow(len(m.prefix) + 1 + rows*(len(m.row)+2))
+	sb.WriteString(m.prefix)
+	sb.WriteByte(' ')
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(m.row)
+	}
+	return sb.String()
+}
+
+// rowsPerStatement returns how many rows fit in one statement under the given parameter limit
+func (m *multiRowInsert) rowsPerStatement(paramLimit int) int {
+	n := paramLimit / m.numParams
+	if n > maxValuesRows {
+		n = maxValuesRows
+	}
+	return n
+}
+
+// skipQuoted returns the index of the closing quote for the quoted section starting at i.
+// Doubled quotes inside the section are treated as escapes.
+func skipQuoted(query string, i int) int {
+	quote := query[i]
+	for j := i + 1; j < len(query); j++ {
+		if query[j] == quote {
+			if j+1 < len(query) && query[j+1] == quote {
+				j++
+				continue
+			}
+			return j
+		}
+	}
+	return len(query) - 1
+}
+
+// isSpace reports whether c is an ASCII whitespace character
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// execBatchMultiRow executes a batch by synthesizing multi-row INSERT statements.
+// Returns true if the statement could be expanded, false if the caller should fall back.
+func (s *Stmt) execBatchMultiRow(ctx context.Context, paramSets [][]driver.NamedValue, result *BatchResult) bool {
+	if s.namedParams != nil {
+		return false
+	}
+	insert, ok := parseMultiRowInsert(s.query)
+	if !ok {
+		return false
+	}
+	for _, params := range paramSets {
+		if len(params) != insert.numParams {
+			return false
+		}
+	}
+
+	limit := s.conn.Capabilities().MaxParams
+	chunkSize := insert.rowsPerStatement(limit)
+	if chunkSize < 2 {
+		// Not worth synthesizing if only one row fits per statement. A row
+		// wider than the limit is left to the prepared statement as well,
+		// which the driver accepted, to run row by row.
+		return false
+	}
+
+	for start := 0; start < len(paramSets); start += chunkSize {
+		end := start + chunkSize
+		if end > len(paramSets) {
+			end = len(paramSets)
+		}
+
+		if err := ctx.Err(); err != nil {
+			for i := start; i < len(paramSets); i++ {
+				result.Errors[i] = err
+			}
+			break
+		}
+
+		rowsAffected, err := s.execMultiRowChunk(insert, paramSets[start:end])
+		result.RowsProcessed += uint64(end - start)
+		if err != nil {
+			for i := start; i < end; i++ {
+				result.Errors[i] = err
+				result.StatusCodes[i] = SQL_PARAM_ERROR
+			}
+			continue
+		}
+
+		result.TotalRowsAffected += rowsAffected
+		perRow := int64(1)
+		if n := int64(end - start); rowsAffected > 0 && rowsAffected != n {
+			perRow = rowsAffected / n
+		}
+		for i := start; i < end; i++ {
+			result.RowCounts[i] = perRow
+			result.StatusCodes[i] = SQL_PARAM_SUCCESS
+		}
+	}
+
+	return true
+}
+
+// execMultiRowChunk prepares and executes one synthesized multi-row INSERT
+func (s *Stmt) execMultiRowChunk(insert *multiRowInsert, paramSets [][]driver.NamedValue) (int64, error) {
+	var stmtHandle SQLHSTMT
+	ret := AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(s.conn.dbc), (*SQLHANDLE)(&stmtHandle))
+	if !IsSuccess(ret) {
+		return 0, s.conn.newError(SQL_HANDLE_DBC, SQLHANDLE(s.conn.dbc))
+	}
+	defer FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+
+	ret = s.conn.prepare(stmtHandle, insert.build(len(paramSets)))
+	if !IsSuccess(ret) {
+		return 0, s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+	}
+
+	// Use a temporary statement so bindParam keeps buffers alive until execution
+	numParams := len(paramSets) * insert.numParams
+	chunk := &Stmt{
+		conn:         s.conn,
+		stmt:         stmtHandle,
+		paramBuffers: make([]interface{}, numParams),
+		paramLengths: make([]SQLLEN, numParams),
+	}
+
+	paramNum := 0
+	for _, params := range paramSets {
+		for _, param := range params {
+			paramNum++
+			if err := chunk.bindParam(SQLUSMALLINT(paramNum), param.Value); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	ret, err := chunk.execute()
+	if err != nil {
+		return 0, err
+	}
+	if !IsSuccess(ret) && ret != SQL_NO_DATA {
+		return 0, s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+	}
+
+	var rowCount SQLLEN
+	RowCount(stmtHandle, &rowCount)
+	return int64(rowCount), nil
+}
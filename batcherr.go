@@ -0,0 +1,87 @@
+package godbc
+
+import (
+	"database/sql/driver"
+)
+
+// BatchErrorValues configures the copies of parameter values ExecBatch keeps
+// for failed parameter sets (see WithBatchErrorValues)
+type BatchErrorValues struct {
+	// MaxValueSize truncates string and []byte values to this many bytes,
+	// keeping strings valid UTF-8 (0 = no limit)
+	MaxValueSize int
+
+	// Redact, if not nil, returns the value to keep for a parameter, e.g. a
+	// placeholder for secrets. It receives the original value with its Ordinal
+	// and Name, before truncation.
+	Redact func(param driver.NamedValue) interface{}
+}
+
+// BatchRowError is the error BatchResult.Errors holds for a failed parameter
+// set when WithBatchErrorValues is set. Its message is that of the underlying
+// error, so the values are not written to logs by accident.
+type BatchRowError struct {
+	Row    int           // Index of the parameter set in the batch
+	Values []interface{} // Copies of the parameter values, redacted and truncated
+	Err    error         // The error the parameter set failed with
+}
+
+func (e *BatchRowError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BatchRowError) Unwrap() error {
+	return e.Err
+}
+
+// values returns the copies of a parameter set kept for a BatchRowError.
+// Streamed values cannot be read again and are kept as nil.
+func (v *BatchErrorValues) values(params []driver.NamedValue) []interface{} {
+	values := make([]interface{}, len(params))
+	for i, param := range params {
+		value := param.Value
+		if v.Redact != nil {
+			value = v.Redact(param)
+		}
+		switch val := value.(type) {
+		case StreamParam, *StreamParam:
+			value = nil
+		case string:
+			value = v.truncateString(val)
+		case []byte:
+			if v.MaxValueSize > 0 && len(val) > v.MaxValueSize {
+				val = val[:v.MaxValueSize]
+			}
+			value = append([]byte(nil), val...)
+		}
+		values[i] = value
+	}
+	return values
+}
+
+// truncateString shortens s to at most MaxValueSize bytes without splitting a character
+func (v *BatchErrorValues) truncateString(s string) string {
+	if v.MaxValueSize <= 0 || len(s) <= v.MaxValueSize {
+		return s
+	}
+	b := []byte(s[:v.MaxValueSize])
+	return string(b[:len(b)-incompleteRuneSuffix(b)])
+}
+
+// keepBatchErrorValues replaces the errors of failed parameter sets with a
+// BatchRowError holding their values, if the connection is configured to
+func (s *Stmt) keepBatchErrorValues(paramSets [][]driver.NamedValue, result *BatchResult) {
+	cfg := s.conn.batchErrorValues
+	if cfg == nil {
+		return
+	}
+	for i, err := range result.Errors {
+		if err == nil || i >= len(paramSets) {
+			continue
+		}
+		if _, ok := err.(*BatchRowError); ok {
+			continue
+		}
+		result.Errors[i] = &BatchRowError{Row: i, Values: cfg.values(paramSets[i]), Err: err}
+	}
+}
@@ -1,6 +1,7 @@
 package godbc
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -93,6 +94,74 @@ func BenchmarkUTF16ToString_SurrogatePairs(b *testing.B) {
 	}
 }
 
+func BenchmarkUTF16ToString_LongASCII(b *testing.B) {
+	input := stringToUTF16(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100))
+	input = input[:len(input)-1]
+	b.SetBytes(int64(len(input) * 2))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		utf16ToString(input)
+	}
+}
+
+func BenchmarkUTF16ToString_LongUnicode(b *testing.B) {
+	input := stringToUTF16(strings.Repeat("Grüße 中文 😀 text ", 200))
+	input = input[:len(input)-1]
+	b.SetBytes(int64(len(input) * 2))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		utf16ToString(input)
+	}
+}
+
+func BenchmarkStringToUTF16_ASCII(b *testing.B) {
+	input := "Hello World"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stringToUTF16(input)
+	}
+}
+
+func BenchmarkStringToUTF16_LongASCII(b *testing.B) {
+	input := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stringToUTF16(input)
+	}
+}
+
+func BenchmarkStringToUTF16_LongUnicode(b *testing.B) {
+	input := strings.Repeat("Grüße 中文 😀 text ", 200)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stringToUTF16(input)
+	}
+}
+
+// =============================================================================
+// Timestamp Conversion Benchmarks
+// =============================================================================
+
+func BenchmarkTimestampToEpochNanos(b *testing.B) {
+	ts := &SQL_TIMESTAMP_STRUCT{Year: 2024, Month: 6, Day: 15, Hour: 10, Minute: 30, Second: 45, Fraction: 123456789}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		timestampToEpochNanos(ts)
+	}
+}
+
+func BenchmarkTimestampToTime(b *testing.B) {
+	ts := &SQL_TIMESTAMP_STRUCT{Year: 2024, Month: 6, Day: 15, Hour: 10, Minute: 30, Second: 45, Fraction: 123456789}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var v interface{} = time.Date(int(ts.Year), time.Month(ts.Month), int(ts.Day),
+			int(ts.Hour), int(ts.Minute), int(ts.Second), int(ts.Fraction), time.UTC)
+		_ = v
+	}
+}
+
 // =============================================================================
 // GUID Parsing Benchmarks
 // =============================================================================
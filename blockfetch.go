@@ -0,0 +1,339 @@
+package godbc
+
+import (
+	"fmt"
+	"io"
+	"time"
+	"unsafe"
+)
+
+// maxBlockElemSize is the largest per-row buffer a column may need for block
+// fetching. Result sets with wider or unbounded columns (LOBs, VARCHAR(MAX))
+// fall back to SQLGetData, which can read values of any length in chunks.
+const maxBlockElemSize = 32 << 10
+
+// blockColumn is a result column bound with SQLBindCol to an array of rowArraySize values
+type blockColumn struct {
+	cType    SQLSMALLINT
+	elemSize int      // bytes per row in data
+	data     []byte   // column-wise array of values
+	ind      []SQLLEN // length/indicator per row
+}
+
+// blockFetch holds the bound buffers of a result set fetched in rowsets with
+// SQL_ATTR_ROW_ARRAY_SIZE. The buffers are referenced by the driver until
+// the columns are unbound, so they live as long as the block fetch.
+type blockFetch struct {
+	columns  []blockColumn
+	statuses []SQLUSMALLINT
+	fetched  SQLULEN // rows in the current rowset, written by the driver
+	pos      int     // current row within the rowset
+}
+
+// blockColumnLayout returns the C type and per-row buffer size used to bind a
+// column for block fetching, or ok=false if the column must be read with SQLGetData.
+// wide binds CHAR/VARCHAR columns as SQL_C_WCHAR (see WithWideFetch).
+func blockColumnLayout(colType SQLSMALLINT, colSize SQLULEN, sizeUnknown, wide bool) (cType SQLSMALLINT, elemSize int, ok bool) {
+	switch colType {
+	case SQL_BIT, SQL_BOOLEAN:
+		return SQL_C_BIT, 1, true
+	case SQL_TINYINT, SQL_SMALLINT, SQL_INTEGER, SQL_BIGINT:
+		return SQL_C_SBIGINT, 8, true
+	case SQL_REAL:
+		return SQL_C_FLOAT, 4, true
+	case SQL_FLOAT, SQL_DOUBLE:
+		return SQL_C_DOUBLE, 8, true
+	case SQL_TYPE_DATE:
+		return SQL_C_DATE, int(unsafe.Sizeof(SQL_DATE_STRUCT{})), true
+	case SQL_TYPE_TIME:
+		return SQL_C_TIME, int(unsafe.Sizeof(SQL_TIME_STRUCT{})), true
+	case SQL_TYPE_TIMESTAMP, SQL_DATETIME:
+		return SQL_C_TIMESTAMP, int(unsafe.Sizeof(SQL_TIMESTAMP_STRUCT{})), true
+	case SQL_GUID:
+		return SQL_C_GUID, int(unsafe.Sizeof(SQL_GUID_STRUCT{})), true
+	case SQL_SS_TIME2:
+		return SQL_C_SS_TIME2, int(unsafe.Sizeof(SQL_SS_TIME2_STRUCT{})), true
+	case SQL_SS_TIMESTAMPOFFSET:
+		return SQL_C_SS_TIMESTAMPOFFSET, int(unsafe.Sizeof(SQL_SS_TIMESTAMPOFFSET_STRUCT{})), true
+	}
+
+	if sizeUnknown {
+		return 0, 0, false
+	}
+	switch colType {
+	case SQL_NUMERIC, SQL_DECIMAL:
+		// Digits plus sign, decimal point, leading zero and terminator
+		cType, elemSize = SQL_C_CHAR, int(colSize)+4
+	case SQL_CHAR, SQL_VARCHAR, SQL_LONGVARCHAR:
+		if wide {
+			cType, elemSize = SQL_C_WCHAR, (int(colSize)*2+1)*wcharSize()
+			break
+		}
+		// Sizes are in characters; allow for multi-byte encodings
+		cType, elemSize = SQL_C_CHAR, int(colSize)*4+1
+	case SQL_WCHAR, SQL_WVARCHAR, SQL_WLONGVARCHAR:
+		// Allow for surrogate pairs in UTF-16
+		cType, elemSize = SQL_C_WCHAR, (int(colSize)*2+1)*wcharSize()
+	case SQL_BINARY, SQL_VARBINARY, SQL_LONGVARBINARY:
+		cType, elemSize = SQL_C_BINARY, int(colSize)
+	default:
+		return 0, 0, false
+	}
+	if elemSize > maxBlockElemSize {
+		return 0, 0, false
+	}
+	return cType, elemSize, true
+}
+
+// rowArraySize returns the connection's block fetch size, or 0 if disabled
+func (r *Rows) rowArraySize() int {
+	if r.stmt == nil || r.stmt.conn == nil || r.stmt.cursorType != CursorForwardOnly {
+		return 0
+	}
+	if n := r.stmt.conn.rowArraySize; n > 1 {
+		return n
+	}
+	return 0
+}
+
+// startBlockFetch binds the result columns and sets the rowset size. It
+// returns nil, leaving the statement unchanged, when block fetching is disabled,
+// LOB columns are streamed, a column cannot be bound, or the driver rejects the
+// rowset attributes.
+func (r *Rows) startBlockFetch() *blockFetch {
+	size := r.rowArraySize()
+	if size == 0 || len(r.columns) == 0 || r.lobTypes != nil {
+		return nil
+	}
+
+	b := &blockFetch{
+		columns:  make([]blockColumn, len(r.columns)),
+		statuses: make([]SQLUSMALLINT, size),
+	}
+	for i := range r.columns {
+		cType, elemSize, ok := blockColumnLayout(r.colTypes[i], r.colSizes[i], r.sizeUnknown[i], r.wideFetch())
+		if r.isRaw(i) {
+			cType, elemSize, ok = rawBlockLayout(r.colTypes[i], r.colSizes[i], r.sizeUnknown[i])
+		} else if r.colTypes[i] == SQL_BIGINT && r.isUnsigned(i) {
+			cType = SQL_C_UBIGINT
+		}
+		if !ok {
+			return nil
+		}
+		b.columns[i] = blockColumn{
+			cType:    cType,
+			elemSize: elemSize,
+			data:     make([]byte, size*elemSize),
+			ind:      make([]SQLLEN, size),
+		}
+	}
+
+	stmt := r.stmt.stmt
+	if !IsSuccess(SetStmtAttr(stmt, SQL_ATTR_ROW_BIND_TYPE, SQL_BIND_BY_COLUMN, 0)) ||
+		!IsSuccess(SetStmtAttr(stmt, SQL_ATTR_ROW_ARRAY_SIZE, uintptr(size), 0)) ||
+		!b.bind(stmt) {
+		resetBlockFetch(stmt)
+		return nil
+	}
+	return b
+}
+
+// bind points the statement's rowset status, rows fetched and column bindings
+// at the buffers of b, so the next SQLFetch fills them
+func (b *blockFetch) bind(stmt SQLHSTMT) bool {
+	if !IsSuccess(SetStmtAttr(stmt, SQL_ATTR_ROWS_FETCHED, uintptr(unsafe.Pointer(&b.fetched)), 0)) ||
+		!IsSuccess(SetStmtAttr(stmt, SQL_ATTR_ROW_STATUS_PTR, uintptr(unsafe.Pointer(&b.statuses[0])), 0)) {
+		return false
+	}
+	for i := range b.columns {
+		col := &b.columns[i]
+		ret := BindCol(stmt, SQLUSMALLINT(i+1), col.cType, uintptr(unsafe.Pointer(&col.data[0])), SQLLEN(col.elemSize), &col.ind[0])
+		if !IsSuccess(ret) {
+			return false
+		}
+	}
+	return true
+}
+
+// sibling allocates an unbound set of buffers with the same layout as b
+func (b *blockFetch) sibling() *blockFetch {
+	s := &blockFetch{
+		columns:  make([]blockColumn, len(b.columns)),
+		statuses: make([]SQLUSMALLINT, len(b.statuses)),
+	}
+	for i, col := range b.columns {
+		s.columns[i] = blockColumn{
+			cType:    col.cType,
+			elemSize: col.elemSize,
+			data:     make([]byte, len(col.data)),
+			ind:      make([]SQLLEN, len(col.ind)),
+		}
+	}
+	return s
+}
+
+// resetBlockFetch unbinds the columns and restores single-row fetching, so the
+// statement can be re-executed or moved to the next result set with SQLGetData
+func resetBlockFetch(stmt SQLHSTMT) {
+	FreeStmt(stmt, SQL_UNBIND)
+	SetStmtAttr(stmt, SQL_ATTR_ROW_ARRAY_SIZE, 1, 0)
+	SetStmtAttr(stmt, SQL_ATTR_ROWS_FETCHED, 0, 0)
+	SetStmtAttr(stmt, SQL_ATTR_ROW_STATUS_PTR, 0, 0)
+}
+
+// endBlockFetch releases the block fetch of the current result set, if any
+func (r *Rows) endBlockFetch() {
+	r.stopPrefetch()
+	if r.block != nil {
+		resetBlockFetch(r.stmt.stmt)
+		r.block = nil
+	}
+	r.blockChecked = false
+}
+
+// advanceBlock moves to the next row, fetching the next rowset when the current one is exhausted
+func (r *Rows) advanceBlock() error {
+	for {
+		b := r.block
+		b.pos++
+		if b.pos >= int(b.fetched) {
+			if err := r.nextRowset(); err != nil {
+				return err
+			}
+			b = r.block
+		}
+
+		switch b.statuses[b.pos] {
+		case SQL_ROW_NOROW:
+			continue
+		case SQL_ROW_ERROR:
+			return fmt.Errorf("error fetching row %d of rowset", b.pos+1)
+		}
+		return nil
+	}
+}
+
+// nextRowset makes the next non-empty rowset current, taking it from the
+// prefetcher when one is running. Returns io.EOF at the end of the result set.
+func (r *Rows) nextRowset() error {
+	var ret SQLRETURN
+	var err error
+	if res, ok := r.waitPrefetch(); ok {
+		r.prefetch.spare = r.block
+		r.block = res.block
+		ret, err = res.ret, res.err
+	} else {
+		ret = Fetch(r.stmt.stmt)
+		if ret != SQL_NO_DATA && !IsSuccess(ret) {
+			err = r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		}
+	}
+
+	b := r.block
+	b.pos = 0
+	if err != nil {
+		b.fetched = 0
+		return err
+	}
+	if ret == SQL_NO_DATA || b.fetched == 0 {
+		b.fetched = 0
+		return io.EOF
+	}
+	r.startPrefetch()
+	return nil
+}
+
+// blockValue decodes the value of a column in the current row of the rowset,
+// producing the same Go types as the SQLGetData path
+func (r *Rows) blockValue(idx int) (interface{}, error) {
+	b := r.block
+	col := &b.columns[idx]
+	ind := col.ind[b.pos]
+	if isNullIndicator(ind) {
+		return nil, nil
+	}
+	p := unsafe.Pointer(&col.data[b.pos*col.elemSize])
+
+	switch col.cType {
+	case SQL_C_BIT:
+		return *(*byte)(p) != 0, nil
+	case SQL_C_SBIGINT:
+		return *(*int64)(p), nil
+	case SQL_C_UBIGINT:
+		return *(*uint64)(p), nil
+	case SQL_C_FLOAT:
+		return float64(*(*float32)(p)), nil
+	case SQL_C_DOUBLE:
+		return *(*float64)(p), nil
+	case SQL_C_DATE:
+		d := (*SQL_DATE_STRUCT)(p)
+		return dateTimeValue(int(d.Year), int(d.Month), int(d.Day), 0, 0, 0, 0, r.outOfRangeTimeMode()), nil
+	case SQL_C_TIME:
+		t := (*SQL_TIME_STRUCT)(p)
+		return time.Date(0, 1, 1, int(t.Hour), int(t.Minute), int(t.Second), 0, time.UTC), nil
+	case SQL_C_TIMESTAMP:
+		ts := (*SQL_TIMESTAMP_STRUCT)(p)
+		if r.timestampFetchMode() == TimestampFetchEpochNanos {
+			return timestampToEpochNanos(ts), nil
+		}
+		return dateTimeValue(int(ts.Year), int(ts.Month), int(ts.Day),
+			int(ts.Hour), int(ts.Minute), int(ts.Second), int(ts.Fraction), r.outOfRangeTimeMode()), nil
+	case SQL_C_SS_TIME2:
+		t := (*SQL_SS_TIME2_STRUCT)(p)
+		return time.Date(0, 1, 1, int(t.Hour), int(t.Minute), int(t.Second), int(t.Fraction), time.UTC), nil
+	case SQL_C_SS_TIMESTAMPOFFSET:
+		ts := (*SQL_SS_TIMESTAMPOFFSET_STRUCT)(p)
+		return offsetTimeValue(int(ts.Year), int(ts.Month), int(ts.Day), int(ts.Hour), int(ts.Minute), int(ts.Second),
+			int(ts.Fraction), int(ts.TimezoneHour), int(ts.TimezoneMinute), r.outOfRangeTimeMode()), nil
+	case SQL_C_GUID:
+		guid := *(*SQL_GUID_STRUCT)(p)
+		if r.guidFetchMode() == GUIDFetchBinary {
+			g := guidFromStruct(guid)
+			return g[:], nil
+		}
+		return guid.String(), nil
+	}
+
+	// Variable-length data: the indicator is the length in bytes, excluding the terminator
+	n := int(ind)
+	limit := col.elemSize
+	if col.cType != SQL_C_BINARY {
+		limit -= terminatorSize(col.cType)
+	}
+	if n < 0 || n > limit {
+		return nil, fmt.Errorf("column %q: value exceeds the bound buffer of %d bytes during block fetch", r.columns[idx], limit)
+	}
+	data := col.data[b.pos*col.elemSize : b.pos*col.elemSize+n]
+	switch col.cType {
+	case SQL_C_CHAR:
+		if r.isRaw(idx) {
+			return r.borrowBytes(data), nil
+		}
+		if r.decimalFetchMode() == DecimalFetchNumeric && (r.colTypes[idx] == SQL_NUMERIC || r.colTypes[idx] == SQL_DECIMAL) {
+			// Decimals are bound as text; normalize them like getNumeric does
+			if d, ok := decimalFromText(string(data), int(r.colSizes[idx]), int(r.decDigits[idx])); ok {
+				return d, nil
+			}
+		}
+		return string(data), nil
+	case SQL_C_WCHAR:
+		if n == 0 {
+			return "", nil
+		}
+		if wcharSize() == 4 {
+			return utf32ToString(unsafe.Slice((*uint32)(p), n/4)), nil
+		}
+		return utf16ToString(unsafe.Slice((*uint16)(p), n/2)), nil
+	default:
+		return r.borrowBytes(data), nil
+	}
+}
+
+// terminatorSize returns the size of the null terminator the driver writes
+// after character data of the given C type
+func terminatorSize(cType SQLSMALLINT) int {
+	if cType == SQL_C_WCHAR {
+		return wcharSize()
+	}
+	return 1
+}
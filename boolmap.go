@@ -0,0 +1,146 @@
+package godbc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BoolRule maps columns that store flags in a non-boolean type, such as MySQL
+// TINYINT(1) or legacy CHAR(1) 'Y'/'N' columns, to bool at fetch time, and
+// optionally bool parameters back to the stored representation at bind time.
+//
+// Example:
+//
+//	godbc.WithBoolRules(
+//	    godbc.BoolRule{Dialect: "mysql", TypeName: regexp.MustCompile(`(?i)^tinyint$`), ColumnSize: 1},
+//	    godbc.BoolRule{Dialect: "oracle", TypeName: regexp.MustCompile(`(?i)^char$`), ColumnSize: 1, True: "Y", False: "N", Bind: true},
+//	)
+type BoolRule struct {
+	// Dialect restricts the rule to databases whose DBMS name contains it,
+	// case-insensitively (e.g. "mysql"); empty applies to every database
+	Dialect string
+
+	// TypeName matches the native type name of a result column, as reported by
+	// SQL_DESC_TYPE_NAME (e.g. "tinyint" or "char"). A rule without TypeName
+	// converts no columns and only applies to parameters.
+	TypeName *regexp.Regexp
+
+	// ColumnSize, if non-zero, must equal the size the driver reports for the column
+	ColumnSize int
+
+	// True and False are the stored text values (e.g. "Y" and "N"), compared
+	// case-insensitively after trimming spaces. If both are empty the column is
+	// numeric: zero is false and any other number true. Values that match
+	// neither are returned unchanged.
+	True  string
+	False string
+
+	// Bind converts bool parameters to the stored representation, True and
+	// False or 1 and 0, on connections the rule applies to. Parameters are bound
+	// without knowing their target column, so set it on at most one rule per
+	// dialect, and only where no parameter targets a native boolean column.
+	Bind bool
+}
+
+// appliesTo reports whether the rule applies to a database type
+func (r *BoolRule) appliesTo(dbType string) bool {
+	return r.Dialect == "" || strings.Contains(strings.ToLower(dbType), strings.ToLower(r.Dialect))
+}
+
+// matches reports whether the rule converts a result column
+func (r *BoolRule) matches(typeName string, colSize SQLULEN) bool {
+	if r.TypeName == nil || !r.TypeName.MatchString(typeName) {
+		return false
+	}
+	return r.ColumnSize == 0 || SQLULEN(r.ColumnSize) == colSize
+}
+
+// textual reports whether the rule stores flags as text
+func (r *BoolRule) textual() bool {
+	return r.True != "" || r.False != ""
+}
+
+// toBool converts a fetched value to bool. NULL, and values the rule does
+// not recognize, are returned unchanged.
+func (r *BoolRule) toBool(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool:
+		return value
+	case int64:
+		if !r.textual() {
+			return v != 0
+		}
+	case float64:
+		if !r.textual() {
+			return v != 0
+		}
+	}
+
+	s := strings.TrimSpace(castToString(value))
+	if r.textual() {
+		switch {
+		case strings.EqualFold(s, r.True):
+			return true
+		case strings.EqualFold(s, r.False):
+			return false
+		}
+		return value
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n != 0
+	}
+	return value
+}
+
+// fromBool returns the stored representation of a bool parameter
+func (r *BoolRule) fromBool(b bool) interface{} {
+	if r.textual() {
+		if b {
+			return r.True
+		}
+		return r.False
+	}
+	if b {
+		return int64(1)
+	}
+	return int64(0)
+}
+
+// columnBoolRules returns the rule that converts each result column, or nil
+// when no column is converted
+func (c *Conn) columnBoolRules(nativeTypes []string, colSizes []SQLULEN) []*BoolRule {
+	if c == nil || len(c.boolRules) == 0 {
+		return nil
+	}
+	var rules []*BoolRule
+	for i, typeName := range nativeTypes {
+		for j := range c.boolRules {
+			rule := &c.boolRules[j]
+			if !rule.appliesTo(c.dbType) || i >= len(colSizes) || !rule.matches(typeName, colSizes[i]) {
+				continue
+			}
+			if rules == nil {
+				rules = make([]*BoolRule, len(nativeTypes))
+			}
+			rules[i] = rule
+			break
+		}
+	}
+	return rules
+}
+
+// bindBool converts a bool parameter with the connection's Bind rule, if any.
+// Other values are returned unchanged.
+func (c *Conn) bindBool(value interface{}) interface{} {
+	b, ok := value.(bool)
+	if !ok || c == nil {
+		return value
+	}
+	for i := range c.boolRules {
+		if rule := &c.boolRules[i]; rule.Bind && rule.appliesTo(c.dbType) {
+			return rule.fromBool(b)
+		}
+	}
+	return value
+}
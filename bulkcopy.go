@@ -0,0 +1,510 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultBulkBatchSize is the number of rows BulkCopy sends per batch when none is configured
+const defaultBulkBatchSize = 1000
+
+// errBulkCopyClosed is returned when rows are added to a closed BulkCopy
+var errBulkCopyClosed = errors.New("bulk copy is closed")
+
+// bulkKind is the Go representation BulkCopy converts a column's values to,
+// so every value of a column binds to the same C type
+type bulkKind int
+
+const (
+	bulkString bulkKind = iota // string, bound as SQL_C_WCHAR
+	bulkInt                    // int64, bound as SQL_C_SBIGINT
+	bulkFloat                  // float64, bound as SQL_C_DOUBLE
+	bulkBool                   // bool, bound as SQL_C_BIT
+	bulkTime                   // time.Time, bound as SQL_C_TIMESTAMP
+	bulkBinary                 // []byte, bound as SQL_C_BINARY
+)
+
+// bulkTimeLayouts are the layouts accepted for strings loaded into date and time columns
+var bulkTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// bulkColumnKind returns the value kind of a SQL data type, and whether the
+// type is a standard ODBC type the parameters can be bound as. Values of
+// driver-specific types are sent as strings with the driver converting them.
+func bulkColumnKind(dataType SQLSMALLINT) (bulkKind, bool) {
+	switch dataType {
+	case SQL_TINYINT, SQL_SMALLINT, SQL_INTEGER, SQL_BIGINT:
+		return bulkInt, true
+	case SQL_REAL, SQL_FLOAT, SQL_DOUBLE:
+		return bulkFloat, true
+	case SQL_BIT, SQL_BOOLEAN:
+		return bulkBool, true
+	case SQL_DATETIME, SQL_TYPE_DATE, SQL_TYPE_TIME, SQL_TYPE_TIMESTAMP:
+		return bulkTime, true
+	case SQL_BINARY, SQL_VARBINARY, SQL_LONGVARBINARY:
+		return bulkBinary, true
+	case SQL_CHAR, SQL_VARCHAR, SQL_LONGVARCHAR, SQL_WCHAR, SQL_WVARCHAR, SQL_WLONGVARCHAR,
+		SQL_DECIMAL, SQL_NUMERIC, SQL_GUID:
+		return bulkString, true
+	default:
+		return bulkString, false
+	}
+}
+
+// bulkValue converts a value to the Go type of a column kind
+func bulkValue(kind bulkKind, v interface{}) (driver.Value, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return nil, err
+		}
+	}
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case Decimal:
+		v = val.Value
+	case WideString:
+		v = string(val)
+	case GUID:
+		v = val.String()
+	}
+	value, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		if kind != bulkString {
+			return nil, err
+		}
+		return fmt.Sprint(v), nil
+	}
+
+	switch kind {
+	case bulkInt:
+		switch val := value.(type) {
+		case int64:
+			return val, nil
+		case float64:
+			if val == float64(int64(val)) {
+				return int64(val), nil
+			}
+		case bool:
+			if val {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case string:
+			if n, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64); err == nil {
+				return n, nil
+			}
+		}
+	case bulkFloat:
+		switch val := value.(type) {
+		case float64:
+			return val, nil
+		case int64:
+			return float64(val), nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+				return f, nil
+			}
+		}
+	case bulkBool:
+		switch val := value.(type) {
+		case bool:
+			return val, nil
+		case int64:
+			return val != 0, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(val)); err == nil {
+				return b, nil
+			}
+		}
+	case bulkTime:
+		switch val := value.(type) {
+		case time.Time:
+			return val, nil
+		case string:
+			for _, layout := range bulkTimeLayouts {
+				if t, err := time.Parse(layout, strings.TrimSpace(val)); err == nil {
+					return t, nil
+				}
+			}
+		}
+	case bulkBinary:
+		switch val := value.(type) {
+		case []byte:
+			// The caller may reuse its buffer before the batch is sent
+			return append([]byte(nil), val...), nil
+		case string:
+			return []byte(val), nil
+		}
+	default:
+		switch val := value.(type) {
+		case string:
+			return val, nil
+		case []byte:
+			return string(val), nil
+		case int64:
+			return strconv.FormatInt(val, 10), nil
+		case float64:
+			return strconv.FormatFloat(val, 'g', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(val), nil
+		case time.Time:
+			return val.Format("2006-01-02 15:04:05.999999999"), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %T value %v", value, value)
+}
+
+// applyColumnType binds the column buffer with the SQL type, size and decimal
+// digits of its target column, so the driver converts the values as it would
+// for the column instead of as the type inferred from the Go values
+func (cb *ColumnBuffer) applyColumnType(col ColumnInfo) {
+	if col.DataType == SQL_UNKNOWN_TYPE {
+		return
+	}
+	cb.SQLType = col.DataType
+	if cb.CType == SQL_C_TIMESTAMP {
+		// Keep the millisecond precision the values were truncated to;
+		// drivers reject fractions beyond the decimal digits bound
+		return
+	}
+	if col.ColumnSize > 0 {
+		cb.ColSize = SQLULEN(col.ColumnSize)
+	}
+	cb.DecDigits = SQLSMALLINT(col.DecimalDigits)
+}
+
+// BulkCopyOption configures a BulkCopy
+type BulkCopyOption func(*BulkCopy)
+
+// WithBulkColumns sets the target columns, in the order values are given
+// (defaults to every column of the table except auto-increment columns)
+func WithBulkColumns(columns ...string) BulkCopyOption {
+	return func(b *BulkCopy) {
+		b.columnNames = columns
+	}
+}
+
+// WithBulkBatchSize sets the number of rows sent and committed per batch (defaults to 1000)
+func WithBulkBatchSize(n int) BulkCopyOption {
+	return func(b *BulkCopy) {
+		b.batchSize = n
+	}
+}
+
+// BulkRowSource supplies rows to BulkCopy.WriteRows. Next fills dest with the
+// values of the next row and returns io.EOF after the last row; driver.Rows
+// implements it, so the result of a query can be copied directly.
+type BulkRowSource interface {
+	Next(dest []driver.Value) error
+}
+
+// BulkCopyError reports a batch that BulkCopy could not write
+type BulkCopyError struct {
+	Row    int64        // Index of the first row of the batch, counting from 0
+	Rows   int          // Number of rows in the batch
+	Result *BatchResult // Per-row outcome, nil if the batch was not executed
+	Err    error
+}
+
+func (e *BulkCopyError) Error() string {
+	return fmt.Sprintf("bulk copy rows %d-%d: %v", e.Row, e.Row+int64(e.Rows)-1, e.Err)
+}
+
+func (e *BulkCopyError) Unwrap() error {
+	return e.Err
+}
+
+// BulkCopy loads rows into a table in batches. Rows are buffered until a
+// batch is full and then sent in one execution with column-wise array
+// binding (falling back to ExecBatch's row-by-row execution when the driver
+// does not support it). Values are converted to the types of the target
+// columns, as reported by SQLColumns, and bound with their SQL types.
+//
+// Unless the connection is already in a transaction, each batch is committed
+// in its own transaction, so a failed batch is rolled back as a whole while
+// earlier batches stay committed. After a failed batch the BulkCopy only
+// reports the error; RowsCopied tells where to resume.
+type BulkCopy struct {
+	conn        *Conn
+	table       string
+	columnNames []string
+	batchSize   int
+
+	columns []ColumnInfo
+	kinds   []bulkKind
+	stmt    *Stmt
+
+	values  []driver.NamedValue   // backing array of the pending rows
+	pending [][]driver.NamedValue // rows buffered for the next batch
+	copied  int64
+	err     error // error of a failed batch, or errBulkCopyClosed; the BulkCopy cannot continue
+}
+
+// BulkCopy prepares a bulk load into a table, looking up its columns and
+// preparing the INSERT statement. The table name may be schema-qualified and
+// quoted. Call Close when done to send the last batch.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    bc, err := dc.(*godbc.Conn).BulkCopy(ctx, "sales.orders", godbc.WithBulkBatchSize(5000))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    for _, o := range orders {
+//	        if err := bc.AddRow(ctx, o.ID, o.Customer, o.Total); err != nil {
+//	            bc.Close(ctx)
+//	            return err
+//	        }
+//	    }
+//	    return bc.Close(ctx)
+//	})
+func (c *Conn) BulkCopy(ctx context.Context, table string, opts ...BulkCopyOption) (*BulkCopy, error) {
+	parts, err := parseTableName(table)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &BulkCopy{
+		conn:      c,
+		table:     table,
+		batchSize: defaultBulkBatchSize,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.batchSize < 1 {
+		return nil, fmt.Errorf("invalid batch size %d", b.batchSize)
+	}
+
+	var schema string
+	if len(parts) > 1 {
+		schema = c.NormalizeIdentifier(parts[len(parts)-2])
+	}
+	tableColumns, err := c.catalogColumns(ctx, schema, c.NormalizeIdentifier(parts[len(parts)-1]))
+	if err != nil {
+		return nil, fmt.Errorf("looking up columns of %s: %w", table, err)
+	}
+	if len(tableColumns) == 0 {
+		return nil, fmt.Errorf("table %s not found", table)
+	}
+	if b.columns, err = c.bulkColumns(tableColumns, b.columnNames); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(b.columns))
+	markers := make([]string, len(b.columns))
+	paramColumns := make([]ColumnInfo, len(b.columns))
+	b.kinds = make([]bulkKind, len(b.columns))
+	for i, col := range b.columns {
+		names[i] = c.QuoteIdentifier(col.Name)
+		markers[i] = "?"
+		kind, standard := bulkColumnKind(col.DataType)
+		b.kinds[i] = kind
+		if standard {
+			paramColumns[i] = col
+		}
+	}
+	query := "INSERT INTO " + table + " (" + strings.Join(names, ", ") + ") VALUES (" + strings.Join(markers, ", ") + ")"
+	ds, err := c.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	b.stmt = ds.(*Stmt)
+	b.stmt.paramColumns = paramColumns
+
+	b.values = make([]driver.NamedValue, b.batchSize*len(b.columns))
+	b.pending = make([][]driver.NamedValue, 0, b.batchSize)
+	return b, nil
+}
+
+// bulkColumns selects the target columns of a bulk copy from the table's
+// columns, by name, or all columns except auto-increment ones if names is empty
+func (c *Conn) bulkColumns(tableColumns []ColumnInfo, names []string) ([]ColumnInfo, error) {
+	if len(names) == 0 {
+		var columns []ColumnInfo
+		for _, col := range tableColumns {
+			if !col.AutoIncrement {
+				columns = append(columns, col)
+			}
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("table has no columns to copy into")
+		}
+		return columns, nil
+	}
+
+	columns := make([]ColumnInfo, len(names))
+	for i, name := range names {
+		if _, err := unquoteColumnName(name); err != nil {
+			return nil, err
+		}
+		stored := c.NormalizeIdentifier(name)
+		found := false
+		for _, col := range tableColumns {
+			if c.identifiersEqual(col.Name, stored) {
+				columns[i] = col
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("column %s not found", name)
+		}
+	}
+	return columns, nil
+}
+
+// Columns returns the target columns, in the order values are given
+func (b *BulkCopy) Columns() []ColumnInfo {
+	return b.columns
+}
+
+// RowsCopied returns the number of rows written by successful batches
+func (b *BulkCopy) RowsCopied() int64 {
+	return b.copied
+}
+
+// AddRow buffers a row, with one value per target column, sending the batch
+// when it is full. A value that cannot be converted to its column's type is
+// rejected with an error and the row is not added.
+func (b *BulkCopy) AddRow(ctx context.Context, values ...interface{}) error {
+	if b.err != nil {
+		return b.err
+	}
+	if len(values) != len(b.columns) {
+		return fmt.Errorf("bulk copy: got %d values for %d columns", len(values), len(b.columns))
+	}
+
+	start := len(b.pending) * len(b.columns)
+	row := b.values[start : start+len(b.columns)]
+	for i, v := range values {
+		value, err := bulkValue(b.kinds[i], v)
+		if err != nil {
+			return fmt.Errorf("bulk copy column %s: %w", b.columns[i].Name, err)
+		}
+		row[i] = driver.NamedValue{Ordinal: i + 1, Value: value}
+	}
+	b.pending = append(b.pending, row)
+
+	if len(b.pending) == b.batchSize {
+		return b.Flush(ctx)
+	}
+	return nil
+}
+
+// WriteRows adds every row of src and sends the last, partial batch. It
+// returns the number of rows read from src.
+func (b *BulkCopy) WriteRows(ctx context.Context, src BulkRowSource) (int64, error) {
+	dest := make([]driver.Value, len(b.columns))
+	values := make([]interface{}, len(b.columns))
+	var n int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		if err := src.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return n, err
+		}
+		for i, v := range dest {
+			values[i] = v
+		}
+		if err := b.AddRow(ctx, values...); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, b.Flush(ctx)
+}
+
+// Flush sends the buffered rows as a batch
+func (b *BulkCopy) Flush(ctx context.Context) error {
+	if b.err != nil {
+		return b.err
+	}
+	if len(b.pending) == 0 {
+		return nil
+	}
+
+	if result, err := b.writeBatch(ctx); err != nil {
+		b.err = &BulkCopyError{Row: b.copied, Rows: len(b.pending), Result: result, Err: err}
+		return b.err
+	}
+	b.copied += int64(len(b.pending))
+	b.pending = b.pending[:0]
+	return nil
+}
+
+// writeBatch executes the pending rows, in a transaction of their own unless
+// the connection is already in one. The result is nil if the batch did not run.
+// Shutdown waits for the whole batch, including its commit or rollback.
+func (b *BulkCopy) writeBatch(ctx context.Context) (*BatchResult, error) {
+	op, err := lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer op.end()
+
+	var tx driver.Tx
+	if !b.conn.inTx {
+		if tx, err = b.conn.BeginTx(ctx, driver.TxOptions{}); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := b.stmt.ExecBatch(ctx, b.pending)
+	if err == nil {
+		for _, rowErr := range result.Errors {
+			if rowErr != nil {
+				err = rowErr
+				break
+			}
+		}
+	}
+	if err != nil {
+		if tx != nil {
+			tx.Rollback()
+		}
+		return result, err
+	}
+	if tx != nil {
+		return result, tx.Commit()
+	}
+	return result, nil
+}
+
+// Close sends the buffered rows and releases the prepared statement. After a
+// failed batch it only releases the statement.
+func (b *BulkCopy) Close(ctx context.Context) error {
+	if b.stmt == nil {
+		return nil
+	}
+	var err error
+	if b.err == nil {
+		err = b.Flush(ctx)
+	}
+	if closeErr := b.stmt.Close(); err == nil {
+		err = closeErr
+	}
+	b.stmt = nil
+	if b.err == nil {
+		b.err = errBulkCopyClosed
+	}
+	return err
+}
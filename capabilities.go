@@ -0,0 +1,307 @@
+package godbc
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// defaultParamLimit is the parameter limit used when the database type is unknown.
+// It matches the most restrictive common limit (SQLite's historical default).
+const defaultParamLimit = 999
+
+// paramLimits maps database types to the maximum number of parameters per statement
+var paramLimits = map[string]int{
+	"sql server": 2100,
+	"postgresql": 65535,
+	"mysql":      65535,
+	"mariadb":    65535,
+	"sqlite":     999,
+	"oracle":     65535,
+	"db2":        32767,
+	"snowflake":  16384,
+}
+
+// Capabilities describes dialect-specific limits detected for a connection
+type Capabilities struct {
+	// DBMSName is the database product name reported by SQL_DBMS_NAME
+	DBMSName string
+
+	// MaxParams is the maximum number of parameters allowed in a single statement.
+	// It is the smaller of the database's limit and the driver's own binding limit.
+	MaxParams int
+
+	// DriverManager is the ODBC driver manager the connection was made through
+	DriverManager DriverManager
+
+	// WCharSize is the size of SQLWCHAR in bytes used for wide-character data:
+	// 2 (UTF-16) for unixODBC and Windows, 4 (UTF-32) for iODBC
+	WCharSize int
+
+	// DefaultIsolation is the isolation level transactions use when BeginTx is
+	// given none, from SQL_DEFAULT_TXN_ISOLATION (sql.LevelDefault if unknown)
+	DefaultIsolation sql.IsolationLevel
+
+	// IsolationLevels are the isolation levels the data source supports, from
+	// SQL_TXN_ISOLATION_OPTION, weakest first (nil if the driver does not report them)
+	IsolationLevels []sql.IsolationLevel
+
+	// MaxConcurrentActivities is the number of statements the driver can
+	// keep active on the connection at once, from
+	// SQL_MAX_CONCURRENT_ACTIVITIES (0 if there is no limit or it is unknown)
+	MaxConcurrentActivities int
+
+	// GetDataExtensions is the SQL_GETDATA_EXTENSIONS bitmask (SQL_GD_*) of
+	// the ways the driver lets SQLGetData read columns. Without
+	// SQL_GD_ANY_ORDER, only the last column of a row is streamed with
+	// WithLOBStreaming.
+	GetDataExtensions uint32
+}
+
+// txnIsolationLevels maps database/sql isolation levels to SQL_TXN_* bits,
+// weakest first. sql.LevelSnapshot is SQL Server's snapshot isolation.
+var txnIsolationLevels = []struct {
+	level sql.IsolationLevel
+	bit   uint32
+}{
+	{sql.LevelReadUncommitted, SQL_TXN_READ_UNCOMMITTED},
+	{sql.LevelReadCommitted, SQL_TXN_READ_COMMITTED},
+	{sql.LevelRepeatableRead, SQL_TXN_REPEATABLE_READ},
+	{sql.LevelSnapshot, SQL_TXN_SS_SNAPSHOT},
+	{sql.LevelSerializable, SQL_TXN_SERIALIZABLE},
+}
+
+// Capabilities returns the detected capabilities of the connection
+func (c *Conn) Capabilities() Capabilities {
+	limit := c.paramLimit()
+	if limit > maxParameters {
+		limit = maxParameters
+	}
+	return Capabilities{
+		DBMSName:         c.dbType,
+		MaxParams:        limit,
+		DriverManager:    libraryInfo.DriverManager,
+		WCharSize:        wcharSize(),
+		DefaultIsolation: isolationLevel(c.defaultTxnIsolation),
+		IsolationLevels:  isolationLevels(c.txnIsolationOptions),
+
+		MaxConcurrentActivities: c.maxConcurrentActivities,
+		GetDataExtensions:       c.getDataExtensions,
+	}
+}
+
+// SupportedIsolationLevels returns the transaction isolation levels the data
+// source supports, weakest first, so callers can pick the strongest level
+// they accept instead of failing at BeginTx. Returns nil if the driver does
+// not report them.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    levels := dc.(*godbc.Conn).SupportedIsolationLevels()
+//	    if len(levels) > 0 {
+//	        opts.Isolation = levels[len(levels)-1]
+//	    }
+//	    return nil
+//	})
+func (c *Conn) SupportedIsolationLevels() []sql.IsolationLevel {
+	return isolationLevels(c.txnIsolationOptions)
+}
+
+// supportsIsolation reports whether the driver reports support for a
+// SQL_TXN_* isolation level
+func (c *Conn) supportsIsolation(bit uint32) bool {
+	return c.txnIsolationOptions&bit != 0
+}
+
+// detectTxnIsolation queries the driver for the default and supported
+// transaction isolation levels
+func (c *Conn) detectTxnIsolation() {
+	if value, ret := GetInfoBitmask(c.dbc, SQL_DEFAULT_TXN_ISOLATION); IsSuccess(ret) {
+		c.defaultTxnIsolation = uint32(value)
+	}
+	if value, ret := GetInfoBitmask(c.dbc, SQL_TXN_ISOLATION_OPTION); IsSuccess(ret) {
+		c.txnIsolationOptions = uint32(value)
+	}
+}
+
+// detectDriverLimits queries the driver for the number of statements it can
+// keep active at once and the SQLGetData extensions it supports. A driver
+// that does not report its extensions is trusted to read columns in any
+// order.
+func (c *Conn) detectDriverLimits() {
+	if value, ret := GetInfoInt(c.dbc, SQL_MAX_CONCURRENT_ACTIVITIES); IsSuccess(ret) {
+		c.maxConcurrentActivities = int(value)
+	}
+	if value, ret := GetInfoBitmask(c.dbc, SQL_GETDATA_EXTENSIONS); IsSuccess(ret) {
+		c.getDataExtensions = uint32(value)
+		c.getDataInOrder = value&SQL_GD_ANY_ORDER == 0
+	}
+}
+
+// isolationLevel returns the database/sql isolation level of a SQL_TXN_* value
+func isolationLevel(bit uint32) sql.IsolationLevel {
+	for _, l := range txnIsolationLevels {
+		if l.bit == bit {
+			return l.level
+		}
+	}
+	return sql.LevelDefault
+}
+
+// isolationLevels returns the database/sql isolation levels in a
+// SQL_TXN_ISOLATION_OPTION bitmask, weakest first
+func isolationLevels(options uint32) []sql.IsolationLevel {
+	var levels []sql.IsolationLevel
+	for _, l := range txnIsolationLevels {
+		if options&l.bit != 0 {
+			levels = append(levels, l.level)
+		}
+	}
+	return levels
+}
+
+// paramLimit returns the database's maximum number of parameters per statement
+func (c *Conn) paramLimit() int {
+	if dbTypeLower := strings.ToLower(c.dbType); dbTypeLower != "" {
+		for dbName, limit := range paramLimits {
+			if strings.Contains(dbTypeLower, dbName) {
+				return limit
+			}
+		}
+	}
+	return defaultParamLimit
+}
+
+// ParamLimitError is returned when a statement would need more parameters than
+// the database allows, and the work cannot be split to fit the limit.
+type ParamLimitError struct {
+	Count int // Number of parameters required
+	Limit int // Maximum number of parameters allowed
+}
+
+func (e *ParamLimitError) Error() string {
+	return fmt.Sprintf("statement requires %d parameters, exceeding the limit of %d", e.Count, e.Limit)
+}
+
+// InQuery is a single statement produced by ExpandIn
+type InQuery struct {
+	Query string
+	Args  []interface{}
+}
+
+// ExpandIn expands the IN (?) list in query to one placeholder per value, splitting
+// the values across as many statements as needed so that none exceeds the
+// connection's parameter limit. See ExpandIn for details.
+func (c *Conn) ExpandIn(query string, values []interface{}, args []interface{}) ([]InQuery, error) {
+	return ExpandIn(query, values, args, c.Capabilities().MaxParams)
+}
+
+// ExpandIn expands the IN (?) list in query to one placeholder per value, splitting
+// the values across as many statements as needed so that none exceeds maxParams.
+//
+// The query must contain exactly one "IN (?)" marker (case-insensitive, whitespace
+// allowed). Any other ? placeholders are bound from args in order, with the IN values
+// inserted at the marker's position in each statement's argument list.
+//
+// Example:
+//
+//	queries, err := godbc.ExpandIn(
+//	    "SELECT * FROM users WHERE tenant = ? AND id IN (?)",
+//	    ids, []interface{}{tenant}, 2100)
+func ExpandIn(query string, values []interface{}, args []interface{}, maxParams int) ([]InQuery, error) {
+	markerStart, markerEnd, before, err := findInMarker(query)
+	if err != nil {
+		return nil, err
+	}
+	if before > len(args) {
+		return nil, fmt.Errorf("query has %d placeholders before IN (?) but only %d args", before, len(args))
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("IN list requires at least one value")
+	}
+
+	chunkSize := maxParams - len(args)
+	if chunkSize < 1 {
+		return nil, &ParamLimitError{Count: len(args) + 1, Limit: maxParams}
+	}
+
+	var result []InQuery
+	for start := 0; start < len(values); start += chunkSize {
+		end := start + chunkSize
+		if end > len(values) {
+			end = len(values)
+		}
+		chunk := values[start:end]
+
+		var sb strings.Builder
+		sb.WriteString(query[:markerStart])
+		sb.WriteString("IN (")
+		for i := range chunk {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteByte('?')
+		}
+		sb.WriteByte(')')
+		sb.WriteString(query[markerEnd:])
+
+		chunkArgs := make([]interface{}, 0, len(args)+len(chunk))
+		chunkArgs = append(chunkArgs, args[:before]...)
+		chunkArgs = append(chunkArgs, chunk...)
+		chunkArgs = append(chunkArgs, args[before:]...)
+
+		result = append(result, InQuery{Query: sb.String(), Args: chunkArgs})
+	}
+	return result, nil
+}
+
+// findInMarker locates the single "IN (?)" marker in query, ignoring quoted text.
+// Returns the marker's byte range and the number of ? placeholders before it.
+func findInMarker(query string) (start, end, before int, err error) {
+	start = -1
+	placeholders := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(query, i)
+		case c == '?':
+			placeholders++
+		case (c == 'I' || c == 'i') && i+1 < len(query) && (query[i+1] == 'N' || query[i+1] == 'n'):
+			if i > 0 && isIdentChar(query[i-1]) {
+				continue
+			}
+			j := i + 2
+			for j < len(query) && isSpace(query[j]) {
+				j++
+			}
+			if j >= len(query) || query[j] != '(' {
+				continue
+			}
+			j++
+			for j < len(query) && isSpace(query[j]) {
+				j++
+			}
+			if j >= len(query) || query[j] != '?' {
+				continue
+			}
+			j++
+			for j < len(query) && isSpace(query[j]) {
+				j++
+			}
+			if j >= len(query) || query[j] != ')' {
+				continue
+			}
+			if start >= 0 {
+				return 0, 0, 0, fmt.Errorf("query contains more than one IN (?) marker")
+			}
+			start, end, before = i, j+1, placeholders
+			i = j
+		}
+	}
+	if start < 0 {
+		return 0, 0, 0, fmt.Errorf("query does not contain an IN (?) marker")
+	}
+	return start, end, before, nil
+}
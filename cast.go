@@ -0,0 +1,283 @@
+package godbc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CastType specifies the Go type a column value should be converted to at fetch time
+type CastType int
+
+const (
+	// CastNone leaves the value as returned by the driver (default)
+	CastNone CastType = iota
+	// CastString converts the value to string
+	CastString
+	// CastInt64 converts the value to int64; floating point values and decimal
+	// text must hold an integer
+	CastInt64
+	// CastFloat64 converts the value to float64
+	CastFloat64
+	// CastTime converts the value to time.Time
+	CastTime
+	// CastBytes converts the value to []byte
+	CastBytes
+)
+
+// String returns the name of the cast type
+func (c CastType) String() string {
+	switch c {
+	case CastNone:
+		return "none"
+	case CastString:
+		return "string"
+	case CastInt64:
+		return "int64"
+	case CastFloat64:
+		return "float64"
+	case CastTime:
+		return "time.Time"
+	case CastBytes:
+		return "[]byte"
+	default:
+		return fmt.Sprintf("CastType(%d)", int(c))
+	}
+}
+
+// ColumnCasts maps column names to the Go type their values should be converted to.
+// Column names are matched exactly first, then case-insensitively.
+type ColumnCasts map[string]CastType
+
+// castTimeLayouts are the layouts tried, in order, when casting a string to time.Time
+var castTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// columnCastsKey is the context key for per-query column casts
+type columnCastsKey struct{}
+
+// WithColumnCasts returns a context that applies the given cast map to the result
+// set of a query executed with it. Values are converted inside the driver as each
+// row is fetched, so heterogeneous drivers can be normalized to a consistent schema.
+//
+// Example:
+//
+//	ctx := godbc.WithColumnCasts(ctx, godbc.ColumnCasts{
+//	    "id":         godbc.CastInt64,
+//	    "amount":     godbc.CastFloat64,
+//	    "created_at": godbc.CastTime,
+//	})
+//	rows, err := db.QueryContext(ctx, "SELECT id, amount, created_at FROM orders")
+func WithColumnCasts(ctx context.Context, casts ColumnCasts) context.Context {
+	return context.WithValue(ctx, columnCastsKey{}, casts)
+}
+
+// columnCastsFromContext returns the cast map stored in ctx, if any
+func columnCastsFromContext(ctx context.Context) ColumnCasts {
+	if ctx == nil {
+		return nil
+	}
+	casts, _ := ctx.Value(columnCastsKey{}).(ColumnCasts)
+	return casts
+}
+
+// resolve returns the cast type for each column, in column order
+func (m ColumnCasts) resolve(columns []string) []CastType {
+	if len(m) == 0 || len(columns) == 0 {
+		return nil
+	}
+	result := make([]CastType, len(columns))
+	found := false
+	for i, name := range columns {
+		if c, ok := m[name]; ok {
+			result[i] = c
+			found = true
+			continue
+		}
+		for key, c := range m {
+			if strings.EqualFold(key, name) {
+				result[i] = c
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return result
+}
+
+// castValue converts a fetched column value to the requested Go type.
+// NULL values are returned unchanged.
+func castValue(value interface{}, cast CastType) (interface{}, error) {
+	if value == nil || cast == CastNone {
+		return value, nil
+	}
+	if d, ok := value.(Decimal); ok {
+		value = d.Value
+	}
+
+	switch cast {
+	case CastString:
+		return castToString(value), nil
+	case CastInt64:
+		return castToInt64(value)
+	case CastFloat64:
+		return castToFloat64(value)
+	case CastTime:
+		return castToTime(value)
+	case CastBytes:
+		switch v := value.(type) {
+		case []byte:
+			return v, nil
+		default:
+			return []byte(castToString(v)), nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cast type %s", cast)
+	}
+}
+
+// castToString formats a fetched value as a string
+func castToString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// castToInt64 converts a fetched value to int64
+func castToInt64(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return nil, fmt.Errorf("cannot cast %d to int64: out of range", v)
+		}
+		return int64(v), nil
+	case float64:
+		n, ok := floatToInt64(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot cast %v to int64", v)
+		}
+		return n, nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case time.Time:
+		return v.Unix(), nil
+	case string, []byte:
+		s := strings.TrimSpace(castToString(v))
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+		// Accept decimal strings with an integral value (e.g. NUMERIC "42.000")
+		f, err := strconv.ParseFloat(s, 64)
+		n, ok := floatToInt64(f)
+		if err != nil || !ok {
+			return nil, fmt.Errorf("cannot cast %q to int64", s)
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("cannot cast %T to int64", value)
+	}
+}
+
+// floatToInt64 converts f to int64 if it is an integer in the range of int64
+func floatToInt64(f float64) (int64, bool) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// castToFloat64 converts a fetched value to float64
+func castToFloat64(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case bool:
+		if v {
+			return float64(1), nil
+		}
+		return float64(0), nil
+	case string, []byte:
+		s := strings.TrimSpace(castToString(v))
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot cast %q to float64", s)
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("cannot cast %T to float64", value)
+	}
+}
+
+// castToTime converts a fetched value to time.Time
+func castToTime(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case int64:
+		return time.Unix(v, 0).UTC(), nil
+	case string, []byte:
+		s := strings.TrimSpace(castToString(v))
+		for _, layout := range castTimeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("cannot cast %q to time.Time", s)
+	default:
+		return nil, fmt.Errorf("cannot cast %T to time.Time", value)
+	}
+}
+
+// castScanType returns the Go type produced by a cast, or nil for CastNone
+func castScanType(cast CastType) reflect.Type {
+	switch cast {
+	case CastString:
+		return reflect.TypeOf("")
+	case CastInt64:
+		return reflect.TypeOf(int64(0))
+	case CastFloat64:
+		return reflect.TypeOf(float64(0))
+	case CastTime:
+		return reflect.TypeOf(time.Time{})
+	case CastBytes:
+		return reflect.TypeOf([]byte{})
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,445 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// rowCountQuery builds a statistics query returning an approximate row count.
+// schema is empty when the table name is unqualified.
+type rowCountQuery func(schema, table string) (string, []interface{})
+
+// rowCountQueries maps database types to queries against their statistics views.
+// These avoid a full scan but may be stale until statistics are refreshed.
+var rowCountQueries = map[string]rowCountQuery{
+	"sql server": func(schema, table string) (string, []interface{}) {
+		name := table
+		if schema != "" {
+			name = schema + "." + table
+		}
+		return "SELECT SUM(p.rows) FROM sys.partitions p WHERE p.object_id = OBJECT_ID(?) AND p.index_id IN (0, 1)",
+			[]interface{}{name}
+	},
+	"postgresql": func(schema, table string) (string, []interface{}) {
+		name := table
+		if schema != "" {
+			name = schema + "." + table
+		}
+		return "SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass(?)", []interface{}{name}
+	},
+	"mysql": func(schema, table string) (string, []interface{}) {
+		if schema == "" {
+			return "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+				[]interface{}{table}
+		}
+		return "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+			[]interface{}{schema, table}
+	},
+	"oracle": func(schema, table string) (string, []interface{}) {
+		if schema == "" {
+			return "SELECT NUM_ROWS FROM USER_TABLES WHERE TABLE_NAME = UPPER(?)", []interface{}{table}
+		}
+		return "SELECT NUM_ROWS FROM ALL_TABLES WHERE OWNER = UPPER(?) AND TABLE_NAME = UPPER(?)",
+			[]interface{}{schema, table}
+	},
+	"db2": func(schema, table string) (string, []interface{}) {
+		if schema == "" {
+			return "SELECT CARD FROM SYSCAT.TABLES WHERE TABSCHEMA = CURRENT SCHEMA AND TABNAME = UPPER(?)",
+				[]interface{}{table}
+		}
+		return "SELECT CARD FROM SYSCAT.TABLES WHERE TABSCHEMA = UPPER(?) AND TABNAME = UPPER(?)",
+			[]interface{}{schema, table}
+	},
+	"snowflake": func(schema, table string) (string, []interface{}) {
+		if schema == "" {
+			return "SELECT ROW_COUNT FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = CURRENT_SCHEMA() AND TABLE_NAME = UPPER(?)",
+				[]interface{}{table}
+		}
+		return "SELECT ROW_COUNT FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = UPPER(?) AND TABLE_NAME = UPPER(?)",
+			[]interface{}{schema, table}
+	},
+}
+
+func init() {
+	rowCountQueries["mariadb"] = rowCountQueries["mysql"]
+}
+
+// TableExists reports whether a table or view exists, using the SQLTables catalog function.
+// An empty schema searches all schemas visible to the connection.
+// Unquoted names are folded the way the database folds identifiers (see
+// IdentifierCasePolicy), so "orders" finds ORDERS on Oracle and DB2.
+func (c *Conn) TableExists(ctx context.Context, schema, table string) (bool, error) {
+	if table == "" {
+		return false, fmt.Errorf("table name is required")
+	}
+	schema = c.lookupIdentifier(schema)
+	table = c.lookupIdentifier(table)
+	rows, err := c.catalogRows(ctx, func(stmt SQLHSTMT) SQLRETURN {
+		return c.odbcTables(stmt, "", schema, table, "")
+	})
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	// Result columns: TABLE_CAT, TABLE_SCHEM, TABLE_NAME, TABLE_TYPE, REMARKS.
+	// Patterns treat '_' and '%' as wildcards, so compare names explicitly.
+	dest := make([]driver.Value, len(rows.Columns()))
+	if len(dest) < 3 {
+		return false, fmt.Errorf("unexpected SQLTables result with %d columns", len(dest))
+	}
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				return false, nil
+			}
+			return false, err
+		}
+		if !c.identifiersEqual(castToString(dest[2]), table) {
+			continue
+		}
+		if schema != "" && dest[1] != nil && !c.identifiersEqual(castToString(dest[1]), schema) {
+			continue
+		}
+		return true, nil
+	}
+}
+
+// TableInfo describes a table or view as reported by SQLTables
+type TableInfo struct {
+	Catalog string
+	Schema  string
+	Name    string
+	Type    string // "TABLE", "VIEW", "SYSTEM TABLE", ... as named by the driver
+	Remarks string
+}
+
+// Tables lists the tables and views matching a schema and table name pattern,
+// using the SQLTables catalog function. In patterns '%' matches any sequence of
+// characters and '_' any single character; empty patterns match everything.
+// tableTypes restricts the result to the given types, e.g. "TABLE", "VIEW".
+// Names are looked up and returned according to the connection's IdentifierCasePolicy.
+//
+// Example:
+//
+//	tables, err := c.Tables(ctx, "sales", "", "TABLE")
+func (c *Conn) Tables(ctx context.Context, schema, table string, tableTypes ...string) ([]TableInfo, error) {
+	tables, err := c.tables(ctx, c.lookupIdentifier(schema), c.lookupIdentifier(table), tableTypes)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tables {
+		tables[i].Schema = c.resultIdentifier(tables[i].Schema)
+		tables[i].Name = c.resultIdentifier(tables[i].Name)
+	}
+	return tables, nil
+}
+
+// tables lists tables with SQLTables, returning names as stored by the database
+func (c *Conn) tables(ctx context.Context, schema, table string, tableTypes []string) ([]TableInfo, error) {
+	rows, err := c.catalogRows(ctx, func(stmt SQLHSTMT) SQLRETURN {
+		return c.odbcTables(stmt, "", schema, table, strings.Join(tableTypes, ","))
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Result columns: TABLE_CAT, TABLE_SCHEM, TABLE_NAME, TABLE_TYPE, REMARKS
+	dest := make([]driver.Value, len(rows.Columns()))
+	if len(dest) < 5 {
+		return nil, fmt.Errorf("unexpected SQLTables result with %d columns", len(dest))
+	}
+	var tables []TableInfo
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				return tables, nil
+			}
+			return nil, err
+		}
+		tables = append(tables, TableInfo{
+			Catalog: castToString(dest[0]),
+			Schema:  castToString(dest[1]),
+			Name:    castToString(dest[2]),
+			Type:    castToString(dest[3]),
+			Remarks: castToString(dest[4]),
+		})
+	}
+}
+
+// PrimaryKeyInfo describes the primary key of a table
+type PrimaryKeyInfo struct {
+	Name    string   // Constraint name, empty if the database does not report it
+	Columns []string // Key columns in key sequence order
+}
+
+// PrimaryKey returns the primary key of a table with its constraint name, using
+// the SQLPrimaryKeys catalog function, or nil if the table has no primary key.
+// Names are looked up and returned according to the connection's IdentifierCasePolicy.
+func (c *Conn) PrimaryKey(ctx context.Context, schema, table string) (*PrimaryKeyInfo, error) {
+	name, columns, err := c.primaryKey(ctx, schema, table)
+	if err != nil || len(columns) == 0 {
+		return nil, err
+	}
+	for i, col := range columns {
+		columns[i] = c.resultIdentifier(col)
+	}
+	return &PrimaryKeyInfo{Name: c.resultIdentifier(name), Columns: columns}, nil
+}
+
+// PrimaryKeyColumns returns the primary key columns of a table in key sequence order,
+// using the SQLPrimaryKeys catalog function. It returns an empty slice if the table
+// has no primary key. Names are looked up and returned according to the
+// connection's IdentifierCasePolicy.
+func (c *Conn) PrimaryKeyColumns(ctx context.Context, schema, table string) ([]string, error) {
+	columns, err := c.primaryKeyColumns(ctx, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	for i, col := range columns {
+		columns[i] = c.resultIdentifier(col)
+	}
+	return columns, nil
+}
+
+// primaryKeyColumns returns the primary key columns of a table as stored by the database
+func (c *Conn) primaryKeyColumns(ctx context.Context, schema, table string) ([]string, error) {
+	_, columns, err := c.primaryKey(ctx, schema, table)
+	return columns, err
+}
+
+// primaryKey returns the primary key name and columns of a table as stored by the database
+func (c *Conn) primaryKey(ctx context.Context, schema, table string) (string, []string, error) {
+	if table == "" {
+		return "", nil, fmt.Errorf("table name is required")
+	}
+	schema = c.lookupIdentifier(schema)
+	table = c.lookupIdentifier(table)
+	rows, err := c.catalogRows(ctx, func(stmt SQLHSTMT) SQLRETURN {
+		return c.odbcPrimaryKeys(stmt, "", schema, table)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	// Result columns: TABLE_CAT, TABLE_SCHEM, TABLE_NAME, COLUMN_NAME, KEY_SEQ, PK_NAME
+	dest := make([]driver.Value, len(rows.Columns()))
+	if len(dest) < 5 {
+		return "", nil, fmt.Errorf("unexpected SQLPrimaryKeys result with %d columns", len(dest))
+	}
+	type keyColumn struct {
+		name string
+		seq  int64
+	}
+	var keys []keyColumn
+	var name string
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", nil, err
+		}
+		seq, _ := castToInt64(dest[4])
+		n, _ := seq.(int64)
+		keys = append(keys, keyColumn{name: castToString(dest[3]), seq: n})
+		if len(dest) > 5 && dest[5] != nil {
+			name = castToString(dest[5])
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].seq < keys[j].seq })
+	columns := make([]string, len(keys))
+	for i, k := range keys {
+		columns[i] = k.name
+	}
+	return name, columns, nil
+}
+
+// catalogRows allocates a statement, runs a catalog function on it and returns
+// the result set. The statement is freed when the rows are closed.
+func (c *Conn) catalogRows(ctx context.Context, fn func(stmt SQLHSTMT) SQLRETURN) (*Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	op, err := lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer op.end() // no-op once handed off to the rows
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, driver.ErrBadConn
+	}
+	var stmtHandle SQLHSTMT
+	ret := AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(c.dbc), (*SQLHANDLE)(&stmtHandle))
+	if !IsSuccess(ret) {
+		err := c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.mu.Unlock()
+
+	ret = fn(stmtHandle)
+	if !IsSuccess(ret) {
+		err := c.newError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+		FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+		return nil, err
+	}
+
+	rows, err := newRows(&Stmt{conn: c, stmt: stmtHandle}, true)
+	if err != nil {
+		FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+		return nil, err
+	}
+	rows.op = op.handoff()
+	return rows, nil
+}
+
+// ApproxRowCount returns an approximate number of rows in a table.
+// It reads the database's statistics views where available (sys.partitions,
+// pg_class, information_schema, ALL_TABLES, SYSCAT.TABLES) and falls back to
+// SELECT COUNT(*) when the database is unknown or has no statistics for the table.
+// The table may be schema-qualified, e.g. "sales.orders".
+func (c *Conn) ApproxRowCount(ctx context.Context, table string) (int64, error) {
+	parts, err := parseTableName(table)
+	if err != nil {
+		return 0, err
+	}
+	name, _ := unquoteIdentifier(parts[len(parts)-1])
+	var schema string
+	if len(parts) > 1 {
+		schema, _ = unquoteIdentifier(parts[len(parts)-2])
+	}
+
+	if query := c.rowCountQuery(); query != nil {
+		// Statistics may be missing (NULL or -1 until analyzed) or unreadable without privileges
+		q, args := query(schema, name)
+		if count, err := c.queryInt64(ctx, q, args); err == nil && count >= 0 {
+			return count, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	qualified, err := c.qualifiedName(parts)
+	if err != nil {
+		return 0, err
+	}
+	return c.queryInt64(ctx, "SELECT COUNT(*) FROM "+qualified, nil)
+}
+
+// rowCountQuery returns the statistics query for the connection's database type
+func (c *Conn) rowCountQuery() rowCountQuery {
+	if dbTypeLower := strings.ToLower(c.dbType); dbTypeLower != "" {
+		for dbName, query := range rowCountQueries {
+			if strings.Contains(dbTypeLower, dbName) {
+				return query
+			}
+		}
+	}
+	return nil
+}
+
+// queryInt64 runs a query returning a single integer value
+func (c *Conn) queryInt64(ctx context.Context, query string, args []interface{}) (int64, error) {
+	rows, err := c.QueryContext(ctx, query, namedValues(args))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, len(rows.Columns()))
+	if len(dest) == 0 {
+		return 0, fmt.Errorf("query returned no columns")
+	}
+	if err := rows.Next(dest); err != nil {
+		if err == io.EOF {
+			return 0, fmt.Errorf("query returned no rows")
+		}
+		return 0, err
+	}
+	if dest[0] == nil {
+		return 0, fmt.Errorf("query returned NULL")
+	}
+	v, err := castToInt64(dest[0])
+	if err != nil {
+		return 0, err
+	}
+	return v.(int64), nil
+}
+
+// qualifiedName joins the parts of a name returned by parseTableName for use
+// in SQL text. Unquoted parts hold only identifier characters and are kept as
+// they are, so the database folds their case as usual; quoted parts are
+// quoted again with the connection's quote character and embedded quotes
+// doubled, so their contents cannot end the identifier.
+func (c *Conn) qualifiedName(parts []string) (string, error) {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		name, ok := unquoteIdentifier(part)
+		switch {
+		case !ok:
+			quoted[i] = part
+		case c.identifierQuote == " ":
+			return "", fmt.Errorf("quoted name %s is not supported: the database does not quote identifiers", part)
+		default:
+			quoted[i] = c.QuoteIdentifier(name)
+		}
+	}
+	return strings.Join(quoted, "."), nil
+}
+
+// parseTableName splits a possibly qualified table name into its parts.
+// Parts may be quoted ("name", [name] or `name`) and keep their quotes;
+// unquoted parts may only contain letters, digits, '_' and '$', so the
+// name is safe to embed in a query.
+func parseTableName(table string) ([]string, error) {
+	if table == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+	var parts []string
+	for i := 0; i < len(table); {
+		var part string
+		switch table[i] {
+		case '"', '[', '`':
+			closing := table[i]
+			if closing == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(table[i+1:], closing)
+			if end < 1 {
+				return nil, fmt.Errorf("invalid table name %q", table)
+			}
+			part = table[i : i+end+2]
+			i += end + 2
+		default:
+			start := i
+			for i < len(table) && (isIdentChar(table[i]) || table[i] == '$') {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("invalid table name %q", table)
+			}
+			part = table[start:i]
+		}
+		parts = append(parts, part)
+
+		if i < len(table) {
+			if table[i] != '.' || i == len(table)-1 {
+				return nil, fmt.Errorf("invalid table name %q", table)
+			}
+			i++
+		}
+	}
+	return parts, nil
+}
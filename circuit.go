@@ -0,0 +1,196 @@
+package godbc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen matches the error Connect returns while a circuit breaker is
+// open, with errors.Is. The error itself is a *CircuitOpenError.
+var ErrCircuitOpen = errors.New("godbc: circuit breaker open")
+
+// CircuitOpenError is returned by Connect, without trying to connect, while
+// the connector's circuit breaker is open
+type CircuitOpenError struct {
+	Failures int       // consecutive failed connects
+	RetryAt  time.Time // when the next connect attempt is allowed
+	Err      error     // the last connect error
+}
+
+// Error implements the error interface
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v after %d consecutive connect failures, retry after %s: %v",
+		ErrCircuitOpen, e.Failures, e.RetryAt.Format(time.RFC3339), e.Err)
+}
+
+// Unwrap returns the last connect error
+func (e *CircuitOpenError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrCircuitOpen
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every connect through (the initial state)
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen fails connects immediately until the cooldown has passed
+	CircuitOpen
+
+	// CircuitHalfOpen lets a single probe connect through to test the database
+	CircuitHalfOpen
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitBreaker stops a connector from connecting while the database is
+// down. After Threshold consecutive failed connects the circuit opens and
+// Connect fails immediately with a *CircuitOpenError, instead of
+// database/sql retrying into a login timeout on every call. Once Cooldown
+// has passed the circuit is half-open: a single connect is let through as a
+// probe, closing the circuit if it succeeds and reopening it, with the
+// cooldown doubled up to MaxCooldown, if it fails.
+//
+// A CircuitBreaker may be shared by connectors to the same database.
+//
+// Example:
+//
+//	cb := godbc.NewCircuitBreaker(5, time.Second)
+//	cb.MaxCooldown = time.Minute
+//	connector, _ := godbc.OpenConnectorWithOptions(connString, godbc.WithCircuitBreaker(cb))
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive failed connects that open the circuit
+	Threshold int
+
+	// Cooldown is how long the circuit stays open before a probe is let through
+	Cooldown time.Duration
+
+	// MaxCooldown caps the cooldown, which doubles each time a probe fails
+	// (0 = the cooldown does not grow)
+	MaxCooldown time.Duration
+
+	// OnStateChange, if set, is called when the circuit changes state
+	OnStateChange func(from, to CircuitState)
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	cooldown time.Duration // current cooldown
+	retryAt  time.Time
+	probing  bool // a half-open probe is in progress
+	lastErr  error
+	now      func() time.Time
+}
+
+// NewCircuitBreaker returns a circuit breaker that opens after threshold
+// consecutive failed connects and lets a probe through after cooldown
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// State returns the current state of the circuit
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// allow reports whether a connect may be attempted, returning a
+// *CircuitOpenError if not. A nil error while half-open makes the caller
+// the probe, which must be followed by done.
+func (cb *CircuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case CircuitOpen:
+		if cb.clock().Before(cb.retryAt) {
+			return cb.openError()
+		}
+		cb.setState(CircuitHalfOpen)
+		cb.probing = true
+	case CircuitHalfOpen:
+		if cb.probing {
+			return cb.openError()
+		}
+		cb.probing = true
+	}
+	return nil
+}
+
+// done records the outcome of an allowed connect. Connects abandoned because
+// their context ended say nothing about the database and are not counted.
+func (cb *CircuitBreaker) done(err error, abandoned bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	probe := cb.probing
+	cb.probing = false
+	switch {
+	case err == nil:
+		cb.failures = 0
+		cb.cooldown = 0
+		cb.setState(CircuitClosed)
+	case abandoned:
+	case probe:
+		cb.failures++
+		cb.lastErr = err
+		cb.cooldown = min(2*cb.cooldown, max(cb.MaxCooldown, cb.Cooldown))
+		cb.open()
+	default:
+		cb.failures++
+		cb.lastErr = err
+		if cb.state == CircuitClosed && cb.failures >= max(cb.Threshold, 1) {
+			cb.cooldown = cb.Cooldown
+			cb.open()
+		}
+	}
+}
+
+// open opens the circuit for the current cooldown. The caller must hold cb.mu.
+func (cb *CircuitBreaker) open() {
+	cb.retryAt = cb.clock().Add(cb.cooldown)
+	cb.setState(CircuitOpen)
+}
+
+// setState changes the state, notifying OnStateChange. The caller must hold cb.mu.
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	if state == cb.state {
+		return
+	}
+	from := cb.state
+	cb.state = state
+	if cb.OnStateChange != nil {
+		cb.OnStateChange(from, state)
+	}
+}
+
+// openError returns the error for a rejected connect. The caller must hold cb.mu.
+func (cb *CircuitBreaker) openError() error {
+	return &CircuitOpenError{Failures: cb.failures, RetryAt: cb.retryAt, Err: cb.lastErr}
+}
+
+// clock returns the current time
+func (cb *CircuitBreaker) clock() time.Time {
+	if cb.now != nil {
+		return cb.now()
+	}
+	return time.Now()
+}
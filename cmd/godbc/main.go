@@ -0,0 +1,422 @@
+// Command godbc runs queries and diagnostics against ODBC data sources using
+// the godbc driver, for checking a driver setup or connection string without
+// writing a program.
+//
+// Usage:
+//
+//	godbc [-c connection-string] [-timeout duration] <command> [arguments]
+//
+// The connection string can also be set with the GODBC_CONN environment variable.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/slingdata-io/godbc"
+)
+
+const usage = `Usage: godbc [-c connection-string] [-timeout duration] <command> [arguments]
+
+Commands:
+  query [-format table|csv|json] <sql>   run a statement and print its results
+  tables [-schema pattern] [pattern]     list tables and views (like \dt)
+  columns <[schema.]table>               describe the columns of a table (like \d)
+  drivers                                list installed ODBC drivers and data sources
+  info                                   print a diagnostic report of the connection
+  selftest                               check the connection and driver features
+
+Flags:
+  -c string         ODBC connection string (default $GODBC_CONN)
+  -timeout duration timeout for the command (0 = none)
+`
+
+// Overridden by tests
+var (
+	driverName  = "odbc"
+	drivers     = godbc.Drivers
+	dataSources = godbc.DataSources
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Getenv("GODBC_CONN"), os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run runs the command line args, writing output to stdout and errors to
+// stderr, and returns the exit code: 2 for usage errors, 1 for failed commands
+func run(ctx context.Context, args []string, defaultConn string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("godbc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	connString := fs.String("c", defaultConn, "ODBC connection string")
+	timeout := fs.Duration("timeout", 0, "timeout for the command (0 = none)")
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	cmd, cmdArgs := fs.Arg(0), fs.Args()[1:]
+	var err error
+	switch cmd {
+	case "drivers":
+		err = listDrivers(stdout)
+	case "query", "tables", "columns", "info", "selftest":
+		err = withConn(ctx, *connString, func(conn *sql.Conn) error {
+			switch cmd {
+			case "query":
+				return runQuery(ctx, conn, cmdArgs, stdout)
+			case "tables":
+				return listTables(ctx, conn, cmdArgs, stdout)
+			case "columns":
+				return describeTable(ctx, conn, cmdArgs, stdout)
+			case "info":
+				return conn.Raw(func(dc interface{}) error {
+					return dc.(*godbc.Conn).DebugDump(stdout)
+				})
+			default:
+				return selfTest(ctx, conn, stdout)
+			}
+		})
+	default:
+		fmt.Fprintf(stderr, "godbc: unknown command %q\n\n", cmd)
+		fs.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "godbc: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// withConn opens a connection for the duration of fn
+func withConn(ctx context.Context, connString string, fn func(conn *sql.Conn) error) error {
+	if connString == "" {
+		return errors.New("no connection string: use -c or set GODBC_CONN")
+	}
+	db, err := sql.Open(driverName, connString)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+	return fn(conn)
+}
+
+// runQuery runs a statement and writes its result sets, or its row count if
+// it returns none
+func runQuery(ctx context.Context, conn *sql.Conn, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", "table", "output format: table, csv or json")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+	if fs.NArg() == 0 {
+		return errors.New("query: no SQL statement given")
+	}
+	query := strings.Join(fs.Args(), " ")
+
+	var write func(w io.Writer, columns []string, rows [][]interface{}) error
+	switch *format {
+	case "table":
+		write = writeTable
+	case "csv":
+		write = writeCSV
+	case "json":
+		write = writeJSON
+	default:
+		return fmt.Errorf("query: unknown format %q", *format)
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for {
+		columns, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			// A statement without a result set, such as an INSERT
+			if !rows.NextResultSet() {
+				break
+			}
+			continue
+		}
+		var values [][]interface{}
+		for rows.Next() {
+			row := make([]interface{}, len(columns))
+			ptrs := make([]interface{}, len(columns))
+			for i := range row {
+				ptrs[i] = &row[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				return err
+			}
+			values = append(values, row)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if err := write(w, columns, values); err != nil {
+			return err
+		}
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+	return rows.Err()
+}
+
+// formatValue returns the text of a scanned value, and whether it is NULL
+func formatValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", true
+	case []byte:
+		return string(v), false
+	case time.Time:
+		return v.Format(time.RFC3339Nano), false
+	default:
+		return fmt.Sprint(v), false
+	}
+}
+
+// writeTable writes rows as aligned columns
+func writeTable(w io.Writer, columns []string, rows [][]interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	dashes := make([]string, len(columns))
+	for i, c := range columns {
+		dashes[i] = strings.Repeat("-", max(len(c), 4))
+	}
+	fmt.Fprintln(tw, strings.Join(dashes, "\t"))
+	for _, row := range rows {
+		fields := make([]string, len(row))
+		for i, v := range row {
+			s, null := formatValue(v)
+			if null {
+				s = "NULL"
+			}
+			fields[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(s)
+		}
+		fmt.Fprintln(tw, strings.Join(fields, "\t"))
+	}
+	fmt.Fprintf(tw, "(%d rows)\n\n", len(rows))
+	return tw.Flush()
+}
+
+// writeCSV writes rows as CSV with a header line; NULL is an empty field
+func writeCSV(w io.Writer, columns []string, rows [][]interface{}) error {
+	cw := csv.NewWriter(w)
+	cw.Write(columns)
+	for _, row := range rows {
+		fields := make([]string, len(row))
+		for i, v := range row {
+			fields[i], _ = formatValue(v)
+		}
+		cw.Write(fields)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSON writes rows as a JSON array of objects keyed by column name
+func writeJSON(w io.Writer, columns []string, rows [][]interface{}) error {
+	out := make([]map[string]interface{}, len(rows))
+	for r, row := range rows {
+		obj := make(map[string]interface{}, len(columns))
+		for i, v := range row {
+			switch v.(type) {
+			case nil, []byte, time.Time:
+				if s, null := formatValue(v); !null {
+					obj[columns[i]] = s
+				} else {
+					obj[columns[i]] = nil
+				}
+			default:
+				obj[columns[i]] = v
+			}
+		}
+		out[r] = obj
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// listTables lists the tables and views matching a name pattern
+func listTables(ctx context.Context, conn *sql.Conn, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("tables", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	schema := fs.String("schema", "", "schema name pattern")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("tables: %w", err)
+	}
+	pattern := fs.Arg(0)
+
+	var tables []godbc.TableInfo
+	err := conn.Raw(func(dc interface{}) error {
+		var err error
+		tables, err = dc.(*godbc.Conn).Tables(ctx, *schema, pattern)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, len(tables))
+	for i, t := range tables {
+		rows[i] = []interface{}{t.Schema, t.Name, t.Type}
+	}
+	return writeTable(w, []string{"schema", "name", "type"}, rows)
+}
+
+// describeTable lists the columns of a table
+func describeTable(ctx context.Context, conn *sql.Conn, args []string, w io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("columns: expected one [schema.]table argument")
+	}
+	schema, table := "", args[0]
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		schema, table = table[:i], table[i+1:]
+	}
+
+	var columns []godbc.ColumnInfo
+	err := conn.Raw(func(dc interface{}) error {
+		var err error
+		columns, err = dc.(*godbc.Conn).Columns(ctx, schema, table)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("table %q not found", args[0])
+	}
+
+	rows := make([][]interface{}, len(columns))
+	for i, c := range columns {
+		nullable := "NOT NULL"
+		if c.Nullable {
+			nullable = "NULL"
+		}
+		var def interface{}
+		if c.Default != nil {
+			def = *c.Default
+		}
+		rows[i] = []interface{}{c.Name, c.TypeName, c.ColumnSize, c.DecimalDigits, nullable, def}
+	}
+	return writeTable(w, []string{"column", "type", "size", "digits", "nullable", "default"}, rows)
+}
+
+// listDrivers lists the installed drivers and the defined data sources
+func listDrivers(w io.Writer) error {
+	installed, err := drivers()
+	if err != nil {
+		return err
+	}
+	rows := make([][]interface{}, len(installed))
+	for i, d := range installed {
+		rows[i] = []interface{}{d.Name, d.Attributes["Driver"]}
+	}
+	if err := writeTable(w, []string{"driver", "library"}, rows); err != nil {
+		return err
+	}
+
+	sources, err := dataSources()
+	if err != nil {
+		return err
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+	rows = make([][]interface{}, len(sources))
+	for i, s := range sources {
+		rows[i] = []interface{}{s.Name, s.Driver}
+	}
+	return writeTable(w, []string{"data source", "driver"}, rows)
+}
+
+// selfTest runs a series of checks against the connection, reporting each,
+// and fails if any fails
+func selfTest(ctx context.Context, conn *sql.Conn, w io.Writer) error {
+	failed := 0
+	check := func(name string, fn func() (string, error)) {
+		start := time.Now()
+		detail, err := fn()
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "FAIL  %-14s %v\n", name, err)
+			return
+		}
+		fmt.Fprintf(w, "ok    %-14s %s (%s)\n", name, detail, elapsed)
+	}
+
+	check("ping", func() (string, error) {
+		return "connection alive", conn.PingContext(ctx)
+	})
+	check("capabilities", func() (string, error) {
+		var caps godbc.Capabilities
+		err := conn.Raw(func(dc interface{}) error {
+			caps = dc.(*godbc.Conn).Capabilities()
+			return nil
+		})
+		return fmt.Sprintf("%s via %s, max %d params, %d-byte SQLWCHAR",
+			caps.DBMSName, caps.DriverManager, caps.MaxParams, caps.WCharSize), err
+	})
+	check("catalog", func() (string, error) {
+		var tables []godbc.TableInfo
+		err := conn.Raw(func(dc interface{}) error {
+			var err error
+			tables, err = dc.(*godbc.Conn).Tables(ctx, "", "")
+			return err
+		})
+		return fmt.Sprintf("%d tables and views", len(tables)), err
+	})
+	check("transaction", func() (string, error) {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return "", err
+		}
+		return "begin and rollback", tx.Rollback()
+	})
+
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	return nil
+}
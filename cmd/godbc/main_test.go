@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/slingdata-io/godbc"
+)
+
+// stubDriver is a database/sql driver whose queries return a fixed result
+// set, or fail for statements starting with FAIL
+type stubDriver struct{}
+
+func (stubDriver) Open(name string) (driver.Conn, error) { return stubConn{}, nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(query string) (driver.Stmt, error) { return stubStmt{query: query}, nil }
+func (stubConn) Close() error                              { return nil }
+func (stubConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type stubStmt struct{ query string }
+
+func (s stubStmt) Close() error  { return nil }
+func (s stubStmt) NumInput() int { return 0 }
+
+func (s stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if strings.HasPrefix(s.query, "FAIL") {
+		return nil, errors.New("syntax error")
+	}
+	return &stubRows{values: [][]driver.Value{
+		{int64(1), "alpha", 1.5},
+		{int64(2), nil, 2.0},
+	}}, nil
+}
+
+type stubRows struct {
+	values [][]driver.Value
+}
+
+func (r *stubRows) Columns() []string { return []string{"id", "name", "score"} }
+func (r *stubRows) Close() error      { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("godbc-stub", stubDriver{})
+}
+
+// runStub runs the command line against the stub driver, returning the exit
+// code and the output written to stdout and stderr
+func runStub(t *testing.T, defaultConn string, args ...string) (int, string, string) {
+	t.Helper()
+	saved := driverName
+	t.Cleanup(func() { driverName = saved })
+	driverName = "godbc-stub"
+
+	var stdout, stderr strings.Builder
+	code := run(context.Background(), args, defaultConn, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Usage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no command", nil, "Usage: godbc"},
+		{"unknown command", []string{"frobnicate"}, `unknown command "frobnicate"`},
+		{"bad flag", []string{"-timeout", "soon", "query", "SELECT 1"}, "invalid value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runStub(t, "", tt.args...)
+			if code != 2 || stdout != "" || !strings.Contains(stderr, tt.want) {
+				t.Errorf("run(%q) = %d, stdout %q, stderr %q; want 2 and %q", tt.args, code, stdout, stderr, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_ConnectionString(t *testing.T) {
+	code, _, stderr := runStub(t, "", "query", "SELECT 1")
+	if code != 1 || !strings.Contains(stderr, "no connection string") {
+		t.Errorf("expected a missing connection string error, got %d %q", code, stderr)
+	}
+	// The default comes from GODBC_CONN, and -c overrides it
+	if code, _, stderr := runStub(t, "DSN=stub", "query", "SELECT 1"); code != 0 {
+		t.Errorf("expected the default connection string to be used, got %d %q", code, stderr)
+	}
+	if code, _, stderr := runStub(t, "", "-c", "DSN=stub", "-timeout", "5s", "query", "SELECT 1"); code != 0 {
+		t.Errorf("expected -c and -timeout to be accepted, got %d %q", code, stderr)
+	}
+}
+
+func TestRun_QueryFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"table", "id    name   score\n----  ----   -----\n1     alpha  1.5\n2     NULL   2\n(2 rows)\n\n"},
+		{"csv", "id,name,score\n1,alpha,1.5\n2,,2\n"},
+		{"json", "[\n  {\n    \"id\": 1,\n    \"name\": \"alpha\",\n    \"score\": 1.5\n  },\n" +
+			"  {\n    \"id\": 2,\n    \"name\": null,\n    \"score\": 2\n  }\n]\n"},
+	}
+	for _, tt := range tests {
+		code, stdout, stderr := runStub(t, "DSN=stub", "query", "-format", tt.format, "SELECT", "*", "FROM", "t")
+		if code != 0 || stdout != tt.want {
+			t.Errorf("-format %s: got %d %q (stderr %q), want %q", tt.format, code, stdout, stderr, tt.want)
+		}
+	}
+}
+
+func TestRun_QueryErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"query"}, "query: no SQL statement given"},
+		{[]string{"query", "-format", "xml", "SELECT 1"}, `query: unknown format "xml"`},
+		{[]string{"query", "-limit", "5", "SELECT 1"}, "query: flag provided but not defined: -limit"},
+		{[]string{"query", "FAIL"}, "syntax error"},
+		{[]string{"columns"}, "columns: expected one [schema.]table argument"},
+	}
+	for _, tt := range tests {
+		code, stdout, stderr := runStub(t, "DSN=stub", tt.args...)
+		if code != 1 || stdout != "" || stderr != "godbc: "+tt.want+"\n" {
+			t.Errorf("run(%q) = %d, stdout %q, stderr %q; want 1 and %q", tt.args, code, stdout, stderr, tt.want)
+		}
+	}
+}
+
+func TestRun_Drivers(t *testing.T) {
+	savedDrivers, savedSources := drivers, dataSources
+	t.Cleanup(func() { drivers, dataSources = savedDrivers, savedSources })
+	drivers = func() ([]godbc.DriverInfo, error) {
+		return []godbc.DriverInfo{{Name: "SQLite3", Attributes: map[string]string{"Driver": "libsqlite3odbc.so"}}}, nil
+	}
+	dataSources = func() ([]godbc.DataSourceInfo, error) {
+		return []godbc.DataSourceInfo{{Name: "sales", Driver: "PostgreSQL"}, {Name: "audit", Driver: "SQLite3"}}, nil
+	}
+
+	code, stdout, stderr := runStub(t, "", "drivers")
+	want := "driver   library\n------   -------\nSQLite3  libsqlite3odbc.so\n(1 rows)\n\n" +
+		"data source  driver\n-----------  ------\naudit        SQLite3\nsales        PostgreSQL\n(2 rows)\n\n"
+	if code != 0 || stdout != want {
+		t.Errorf("got %d %q (stderr %q), want %q", code, stdout, stderr, want)
+	}
+
+	dataSources = func() ([]godbc.DataSourceInfo, error) { return nil, errors.New("no driver manager") }
+	if code, _, stderr := runStub(t, "", "drivers"); code != 1 || stderr != "godbc: no driver manager\n" {
+		t.Errorf("expected the data source error, got %d %q", code, stderr)
+	}
+}
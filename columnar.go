@@ -0,0 +1,296 @@
+package godbc
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"time"
+	"unsafe"
+)
+
+// DefaultColumnBatchSize is the number of rows NextBatch reads per batch when
+// the connection does not block fetch (see WithRowArraySize)
+const DefaultColumnBatchSize = 1024
+
+// ColumnKind is the slice of a ColumnVector that holds a column's values
+type ColumnKind int
+
+const (
+	// ColumnValues holds values in Values, as Next returns them. Columns that
+	// are not integers, floating point numbers, dates or timestamps use it, as
+	// do columns with a cast, a bool rule or raw fetching set.
+	ColumnValues ColumnKind = iota
+
+	// ColumnInt64 holds TINYINT, SMALLINT, INTEGER and signed BIGINT columns
+	// in Int64, and TIMESTAMP columns with TimestampFetchEpochNanos
+	ColumnInt64
+
+	// ColumnFloat64 holds REAL, FLOAT and DOUBLE columns in Float64
+	ColumnFloat64
+
+	// ColumnTime holds DATE and TIMESTAMP columns in Time, in UTC
+	ColumnTime
+)
+
+// ColumnVector holds the values of one column of a ColumnBatch in the slice
+// its Kind selects; the other slices are empty. A NULL value is the zero
+// value of its slice, with Nulls set for that row.
+type ColumnVector struct {
+	Kind    ColumnKind
+	Int64   []int64
+	Float64 []float64
+	Time    []time.Time
+	Values  []driver.Value
+	Nulls   []bool
+}
+
+// ColumnBatch is a batch of rows read by NextBatch, held column by column
+type ColumnBatch struct {
+	Len     int            // Number of rows in the batch
+	Columns []ColumnVector // One vector per result column
+}
+
+// reset empties the batch for the columns of r, keeping the capacity of its slices
+func (b *ColumnBatch) reset(r *Rows) {
+	b.Len = 0
+	if len(b.Columns) != len(r.columns) {
+		b.Columns = make([]ColumnVector, len(r.columns))
+	}
+	for i := range b.Columns {
+		v := &b.Columns[i]
+		v.Kind = r.columnKind(i)
+		v.Int64 = v.Int64[:0]
+		v.Float64 = v.Float64[:0]
+		v.Time = v.Time[:0]
+		v.Values = v.Values[:0]
+		v.Nulls = v.Nulls[:0]
+	}
+}
+
+// NextBatch reads the next rows of the result set into b, reusing its slices,
+// for analytics reads that would otherwise box every value in an interface.
+// Integer, floating point, date and timestamp columns are returned in typed
+// slices (see ColumnKind); other columns as in Next. With block fetching
+// enabled (see WithRowArraySize), each batch is a rowset, copied from the
+// bound arrays without converting values to interfaces; otherwise a batch is
+// up to DefaultColumnBatchSize rows read with SQLGetData. NextBatch and Next
+// can be mixed. Returns io.EOF when no more rows are available.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    rows, err := dc.(driver.QueryerContext).QueryContext(ctx, "SELECT id, amount, created_at FROM sales", nil)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    defer rows.Close()
+//	    var batch godbc.ColumnBatch
+//	    for rows.(*godbc.Rows).NextBatch(&batch) == nil {
+//	        ids, amounts := batch.Columns[0].Int64, batch.Columns[1].Float64
+//	        aggregate(ids, amounts, batch.Columns[1].Nulls)
+//	    }
+//	    return nil
+//	})
+func (r *Rows) NextBatch(b *ColumnBatch) error {
+	if r.closed || len(r.columns) == 0 {
+		return io.EOF
+	}
+	if !r.blockChecked {
+		r.blockChecked = true
+		r.block = r.startBlockFetch()
+		r.startPrefetcher()
+	}
+	if r.block == nil {
+		return r.nextBatchRows(b)
+	}
+
+	if err := r.ctxErr(); err != nil {
+		return err
+	}
+	start := time.Now()
+	defer func() { r.stats.FetchTime += time.Since(start) }()
+
+	b.reset(r)
+	if err := r.advanceBlock(); err != nil {
+		if ctxErr := r.ctxErr(); ctxErr != nil && err != io.EOF {
+			err = ctxErr
+		}
+		if err != io.EOF {
+			r.stmt.lastErr = err
+			r.stmt.conn.observeError(MetricsFetch, r.stmt.query, err)
+			recordSpanError(r.span, err)
+		}
+		return err
+	}
+	block := r.block
+	for {
+		r.rowGen++
+		r.stats.RowsFetched++
+		if err := r.appendBlockRow(b); err != nil {
+			return err
+		}
+		// Take the rest of the rowset, leaving the next one to the next call
+		for block.pos+1 < int(block.fetched) && block.statuses[block.pos+1] == SQL_ROW_NOROW {
+			block.pos++
+		}
+		if block.pos+1 >= int(block.fetched) {
+			return nil
+		}
+		block.pos++
+		if block.statuses[block.pos] == SQL_ROW_ERROR {
+			return fmt.Errorf("error fetching row %d of rowset", block.pos+1)
+		}
+	}
+}
+
+// nextBatchRows reads a batch with Next when the result set is not block fetched
+func (r *Rows) nextBatchRows(b *ColumnBatch) error {
+	b.reset(r)
+	dest := make([]driver.Value, len(r.columns))
+	for b.Len < DefaultColumnBatchSize {
+		if err := r.Next(dest); err != nil {
+			if err == io.EOF && b.Len > 0 {
+				return nil
+			}
+			return err
+		}
+		for i, val := range dest {
+			if err := b.Columns[i].append(val); err != nil {
+				return fmt.Errorf("column %q: %w", r.columns[i], err)
+			}
+		}
+		b.Len++
+	}
+	return nil
+}
+
+// appendBlockRow appends the current row of the rowset to the batch
+func (r *Rows) appendBlockRow(b *ColumnBatch) error {
+	block := r.block
+	for i := range b.Columns {
+		v := &b.Columns[i]
+		if v.Kind == ColumnValues {
+			val, err := r.getColumnData(SQLUSMALLINT(i + 1))
+			if err != nil {
+				return err
+			}
+			v.Values = append(v.Values, val)
+			v.Nulls = append(v.Nulls, val == nil)
+			continue
+		}
+
+		col := &block.columns[i]
+		null := isNullIndicator(col.ind[block.pos])
+		v.Nulls = append(v.Nulls, null)
+		p := unsafe.Pointer(&col.data[block.pos*col.elemSize])
+		switch v.Kind {
+		case ColumnInt64:
+			var n int64
+			if !null {
+				if col.cType == SQL_C_TIMESTAMP {
+					n = timestampToEpochNanos((*SQL_TIMESTAMP_STRUCT)(p))
+				} else {
+					n = *(*int64)(p)
+				}
+			}
+			v.Int64 = append(v.Int64, n)
+		case ColumnFloat64:
+			var f float64
+			if !null {
+				if col.cType == SQL_C_FLOAT {
+					f = float64(*(*float32)(p))
+				} else {
+					f = *(*float64)(p)
+				}
+			}
+			v.Float64 = append(v.Float64, f)
+		case ColumnTime:
+			var t time.Time
+			if !null {
+				var val interface{}
+				if col.cType == SQL_C_DATE {
+					d := (*SQL_DATE_STRUCT)(p)
+					val = dateTimeValue(int(d.Year), int(d.Month), int(d.Day), 0, 0, 0, 0, r.outOfRangeTimeMode())
+				} else {
+					ts := (*SQL_TIMESTAMP_STRUCT)(p)
+					val = dateTimeValue(int(ts.Year), int(ts.Month), int(ts.Day),
+						int(ts.Hour), int(ts.Minute), int(ts.Second), int(ts.Fraction), r.outOfRangeTimeMode())
+				}
+				var ok bool
+				if t, ok = val.(time.Time); !ok {
+					return fmt.Errorf("column %q: %v is outside the range of a time column (see OutOfRangeTimeClamp)", r.columns[i], val)
+				}
+			}
+			v.Time = append(v.Time, t)
+		}
+	}
+	b.Len++
+	return nil
+}
+
+// columnKind returns the kind of vector a column is read into by NextBatch
+func (r *Rows) columnKind(idx int) ColumnKind {
+	if r.isRaw(idx) ||
+		(idx < len(r.casts) && r.casts[idx] != CastNone) ||
+		(idx < len(r.boolRules) && r.boolRules[idx] != nil) ||
+		(idx < len(r.pgKinds) && r.pgKinds[idx] != pgValueDefault) {
+		return ColumnValues
+	}
+	switch r.colTypes[idx] {
+	case SQL_TINYINT, SQL_SMALLINT, SQL_INTEGER:
+		return ColumnInt64
+	case SQL_BIGINT:
+		if r.isUnsigned(idx) {
+			return ColumnValues
+		}
+		return ColumnInt64
+	case SQL_REAL, SQL_FLOAT, SQL_DOUBLE:
+		return ColumnFloat64
+	case SQL_TYPE_DATE:
+		return ColumnTime
+	case SQL_TYPE_TIMESTAMP, SQL_DATETIME:
+		if r.timestampFetchMode() == TimestampFetchEpochNanos {
+			return ColumnInt64
+		}
+		return ColumnTime
+	}
+	return ColumnValues
+}
+
+// ColumnKind returns the kind of vector NextBatch reads a column into, or
+// ColumnValues if index is out of range
+func (r *Rows) ColumnKind(index int) ColumnKind {
+	if index < 0 || index >= len(r.colTypes) {
+		return ColumnValues
+	}
+	return r.columnKind(index)
+}
+
+// append adds a value read with Next to the vector
+func (v *ColumnVector) append(val driver.Value) error {
+	null := val == nil
+	v.Nulls = append(v.Nulls, null)
+	switch v.Kind {
+	case ColumnInt64:
+		n, ok := val.(int64)
+		if !ok && !null {
+			return fmt.Errorf("%T value in an integer column", val)
+		}
+		v.Int64 = append(v.Int64, n)
+	case ColumnFloat64:
+		f, ok := val.(float64)
+		if !ok && !null {
+			return fmt.Errorf("%T value in a floating point column", val)
+		}
+		v.Float64 = append(v.Float64, f)
+	case ColumnTime:
+		t, ok := val.(time.Time)
+		if !ok && !null {
+			return fmt.Errorf("%v is outside the range of a time column (see OutOfRangeTimeClamp)", val)
+		}
+		v.Time = append(v.Time, t)
+	default:
+		v.Values = append(v.Values, val)
+	}
+	return nil
+}
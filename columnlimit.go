@@ -0,0 +1,158 @@
+package godbc
+
+import (
+	"fmt"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// ColumnTooLargeError is returned when a character or binary value is larger
+// than the connection's MaxColumnBytes and the truncation policy is
+// TruncationError (see WithMaxColumnBytes)
+type ColumnTooLargeError struct {
+	Column string // Column name
+	Size   int64  // Size of the value in bytes as the driver reports it, or -1 if unknown
+	Limit  int    // MaxColumnBytes
+}
+
+func (e *ColumnTooLargeError) Error() string {
+	if e.Size < 0 {
+		return fmt.Sprintf("column %q: value exceeds the %d byte limit", e.Column, e.Limit)
+	}
+	return fmt.Sprintf("column %q: value of %d bytes exceeds the %d byte limit", e.Column, e.Size, e.Limit)
+}
+
+// maxColumnBytes returns the connection's limit on values read with
+// SQLGetData, or 0 if there is none
+func (r *Rows) maxColumnBytes() int {
+	if r.stmt == nil || r.stmt.conn == nil {
+		return 0
+	}
+	return r.stmt.conn.maxColumnBytes
+}
+
+// exceedsColumnLimit reports whether the first SQLGetData call for a value
+// found it larger than the limit, or truncated it without reporting its size
+func (r *Rows) exceedsColumnLimit(ret SQLRETURN, indicator SQLLEN) bool {
+	limit := r.maxColumnBytes()
+	if limit <= 0 {
+		return false
+	}
+	return indicator > SQLLEN(limit) || (ret == SQL_SUCCESS_WITH_INFO && indicator == SQL_NO_TOTAL)
+}
+
+// limitedValue handles a value that may exceed the column limit, given the
+// buffer of the first SQLGetData call (terminator included) and the indicator
+// it returned. It reads on until the limit is passed or the value ends, and
+// returns the whole value if it fits after all; otherwise the connection's
+// truncation policy decides.
+func (r *Rows) limitedValue(colNum SQLUSMALLINT, cType SQLSMALLINT, buf []byte, indicator SQLLEN) (interface{}, error) {
+	limit := r.maxColumnBytes()
+	term := 0
+	if cType != SQL_C_BINARY {
+		term = terminatorSize(cType)
+	}
+	n := len(buf) - term
+	more := indicator == SQL_NO_TOTAL || indicator > SQLLEN(n)
+	if indicator >= 0 && indicator < SQLLEN(n) {
+		n = int(indicator)
+	}
+	data := buf[:n]
+
+	chunk := make([]byte, lobChunkSize)
+	for more && len(data) <= limit {
+		var ind SQLLEN
+		ret := r.getData(colNum, cType, uintptr(unsafe.Pointer(&chunk[0])), SQLLEN(len(chunk)), &ind)
+		if ret == SQL_NO_DATA {
+			break
+		}
+		if !IsSuccess(ret) {
+			return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		}
+		m := len(chunk) - term
+		more = ret == SQL_SUCCESS_WITH_INFO && (ind == SQL_NO_TOTAL || ind > SQLLEN(m))
+		if ind >= 0 && ind < SQLLEN(m) {
+			m = int(ind)
+		}
+		data = append(data, chunk[:m]...)
+	}
+	if !more && len(data) <= limit {
+		return columnValue(cType, data), nil
+	}
+
+	switch r.truncationPolicy() {
+	case TruncationTruncate:
+		return columnValue(cType, truncateColumnData(cType, data, limit)), nil
+	case TruncationStream:
+		if int(colNum) == len(r.columns) {
+			l := &LOBReader{rows: r, colNum: colNum, cType: cType, row: r.rowGen, size: -1, eof: !more}
+			if indicator >= 0 {
+				l.size = int64(indicator)
+			}
+			if cType == SQL_C_WCHAR {
+				l.pending = l.decodeWide(data)
+			} else {
+				l.pending = data
+			}
+			return l, nil
+		}
+	}
+	size := int64(-1)
+	if indicator >= 0 {
+		size = int64(indicator)
+	}
+	return nil, &ColumnTooLargeError{Column: r.columns[colNum-1], Size: size, Limit: limit}
+}
+
+// truncationPolicy returns the connection's truncation policy
+func (r *Rows) truncationPolicy() TruncationPolicy {
+	if r.stmt == nil || r.stmt.conn == nil {
+		return TruncationError
+	}
+	return r.stmt.conn.truncationPolicy
+}
+
+// truncateColumnData cuts data to at most limit bytes at a character
+// boundary: a whole SQLWCHAR without a dangling high surrogate for wide
+// data, the start of a UTF-8 sequence for character data
+func truncateColumnData(cType SQLSMALLINT, data []byte, limit int) []byte {
+	if len(data) <= limit {
+		return data
+	}
+	switch cType {
+	case SQL_C_WCHAR:
+		size := wcharSize()
+		limit -= limit % size
+		if size == 2 && limit >= 2 {
+			if unit := *(*uint16)(unsafe.Pointer(&data[limit-2])); unit >= 0xD800 && unit < 0xDC00 {
+				limit -= 2
+			}
+		}
+	case SQL_C_CHAR:
+		for limit > 0 && !utf8.RuneStart(data[limit]) {
+			limit--
+		}
+	}
+	return data[:limit]
+}
+
+// columnValue converts the data of a character or binary value to the type
+// the column is returned as
+func columnValue(cType SQLSMALLINT, data []byte) interface{} {
+	switch cType {
+	case SQL_C_BINARY:
+		return append([]byte(nil), data...)
+	case SQL_C_WCHAR:
+		if wcharSize() == 4 {
+			if len(data) < 4 {
+				return ""
+			}
+			return utf32ToString(unsafe.Slice((*uint32)(unsafe.Pointer(&data[0])), len(data)/4))
+		}
+		if len(data) < 2 {
+			return ""
+		}
+		return utf16ToString(unsafe.Slice((*uint16)(unsafe.Pointer(&data[0])), len(data)/2))
+	}
+	return string(data)
+}
@@ -0,0 +1,286 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ColumnInfo describes a table column as reported by SQLColumns, or a result
+// column as reported by Stmt.Describe
+type ColumnInfo struct {
+	Name          string
+	Ordinal       int         // 1-based position in the table
+	DataType      SQLSMALLINT // SQL data type (SQL_VARCHAR, SQL_INTEGER, ...)
+	TypeName      string      // Native type name (e.g. "nvarchar", "int identity")
+	ColumnSize    int64
+	DecimalDigits int64
+	Nullable      bool
+	Remarks       string
+
+	// Default is the column's default value expression (COLUMN_DEF) as the
+	// database reports it, e.g. "(getdate())" or "'active'::text", or nil if none
+	Default *string
+
+	// AutoIncrement reports identity, serial and auto_increment columns
+	AutoIncrement bool
+
+	// IdentitySeed and IdentityIncrement are the identity start value and step,
+	// or nil if the column is not an identity column or the database does not report them
+	IdentitySeed      *int64
+	IdentityIncrement *int64
+}
+
+// identityQuery builds a query returning the identity columns of a table as rows of
+// (column name, seed, increment); seed and increment may be NULL.
+// schema is empty when the table name is unqualified.
+type identityQuery func(schema, table string) (string, []interface{})
+
+// identityQueries maps database types to queries against their catalogs for
+// identity columns, used when SQLColumns does not report auto-increment columns
+// and to read identity seeds, which SQLColumns never reports
+var identityQueries = map[string]identityQuery{
+	"sql server": func(schema, table string) (string, []interface{}) {
+		name := table
+		if schema != "" {
+			name = schema + "." + table
+		}
+		return "SELECT name, CAST(seed_value AS BIGINT), CAST(increment_value AS BIGINT) FROM sys.identity_columns WHERE object_id = OBJECT_ID(?)",
+			[]interface{}{name}
+	},
+	"postgresql": func(schema, table string) (string, []interface{}) {
+		const query = "SELECT column_name, CAST(identity_start AS BIGINT), CAST(identity_increment AS BIGINT) FROM information_schema.columns " +
+			"WHERE table_schema = %s AND table_name = ? AND (is_identity = 'YES' OR column_default LIKE 'nextval(%%')"
+		if schema == "" {
+			return fmt.Sprintf(query, "current_schema()"), []interface{}{table}
+		}
+		return fmt.Sprintf(query, "?"), []interface{}{schema, table}
+	},
+	"mysql": func(schema, table string) (string, []interface{}) {
+		const query = "SELECT COLUMN_NAME, NULL, NULL FROM information_schema.COLUMNS " +
+			"WHERE TABLE_SCHEMA = %s AND TABLE_NAME = ? AND EXTRA LIKE '%%auto_increment%%'"
+		if schema == "" {
+			return fmt.Sprintf(query, "DATABASE()"), []interface{}{table}
+		}
+		return fmt.Sprintf(query, "?"), []interface{}{schema, table}
+	},
+	"oracle": func(schema, table string) (string, []interface{}) {
+		if schema == "" {
+			return "SELECT COLUMN_NAME, NULL, NULL FROM USER_TAB_IDENTITY_COLS WHERE TABLE_NAME = UPPER(?)", []interface{}{table}
+		}
+		return "SELECT COLUMN_NAME, NULL, NULL FROM ALL_TAB_IDENTITY_COLS WHERE OWNER = UPPER(?) AND TABLE_NAME = UPPER(?)",
+			[]interface{}{schema, table}
+	},
+	"db2": func(schema, table string) (string, []interface{}) {
+		if schema == "" {
+			return "SELECT COLNAME, BIGINT(START), BIGINT(INCREMENT) FROM SYSCAT.COLIDENTATTRIBUTES WHERE TABSCHEMA = CURRENT SCHEMA AND TABNAME = UPPER(?)",
+				[]interface{}{table}
+		}
+		return "SELECT COLNAME, BIGINT(START), BIGINT(INCREMENT) FROM SYSCAT.COLIDENTATTRIBUTES WHERE TABSCHEMA = UPPER(?) AND TABNAME = UPPER(?)",
+			[]interface{}{schema, table}
+	},
+	"snowflake": func(schema, table string) (string, []interface{}) {
+		const query = "SELECT COLUMN_NAME, IDENTITY_START, IDENTITY_INCREMENT FROM INFORMATION_SCHEMA.COLUMNS " +
+			"WHERE TABLE_SCHEMA = %s AND TABLE_NAME = UPPER(?) AND IS_IDENTITY = 'YES'"
+		if schema == "" {
+			return fmt.Sprintf(query, "CURRENT_SCHEMA()"), []interface{}{table}
+		}
+		return fmt.Sprintf(query, "UPPER(?)"), []interface{}{schema, table}
+	},
+}
+
+func init() {
+	identityQueries["mariadb"] = identityQueries["mysql"]
+}
+
+// autoIncrementColumns are driver-specific SQLColumns result columns flagging
+// auto-increment columns, beyond the 18 columns defined by ODBC
+var autoIncrementColumns = []string{"IS_AUTOINCREMENT", "SS_IS_IDENTITY", "AUTO_INCREMENT"}
+
+// Columns returns the columns of a table in ordinal order, using the SQLColumns
+// catalog function, with default values and auto-increment information.
+// Identity seeds and auto-increment columns the driver does not flag are read
+// from the database's catalog views where available (sys.identity_columns,
+// information_schema, ALL_TAB_IDENTITY_COLS, SYSCAT.COLIDENTATTRIBUTES).
+// Names are looked up and returned according to the connection's IdentifierCasePolicy.
+func (c *Conn) Columns(ctx context.Context, schema, table string) ([]ColumnInfo, error) {
+	if table == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+	schema = c.lookupIdentifier(schema)
+	table = c.lookupIdentifier(table)
+
+	columns, err := c.catalogColumns(ctx, schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	if query := c.identityQuery(); query != nil && len(columns) > 0 {
+		// Catalog views may be unreadable without privileges; keep the SQLColumns data
+		q, args := query(schema, table)
+		if err := c.applyIdentityInfo(ctx, columns, q, args); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+		}
+	}
+
+	for i := range columns {
+		columns[i].Name = c.resultIdentifier(columns[i].Name)
+	}
+	return columns, nil
+}
+
+// catalogColumns reads the SQLColumns result for a table
+func (c *Conn) catalogColumns(ctx context.Context, schema, table string) ([]ColumnInfo, error) {
+	rows, err := c.catalogRows(ctx, func(stmt SQLHSTMT) SQLRETURN {
+		return c.odbcColumns(stmt, "", schema, table, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Result columns: TABLE_CAT, TABLE_SCHEM, TABLE_NAME, COLUMN_NAME, DATA_TYPE, TYPE_NAME,
+	// COLUMN_SIZE, BUFFER_LENGTH, DECIMAL_DIGITS, NUM_PREC_RADIX, NULLABLE, REMARKS,
+	// COLUMN_DEF, SQL_DATA_TYPE, SQL_DATETIME_SUB, CHAR_OCTET_LENGTH, ORDINAL_POSITION,
+	// IS_NULLABLE, then driver-specific columns
+	resultColumns := rows.Columns()
+	if len(resultColumns) < 17 {
+		return nil, fmt.Errorf("unexpected SQLColumns result with %d columns", len(resultColumns))
+	}
+	autoIncIndex := -1
+	for i := 18; i < len(resultColumns) && autoIncIndex < 0; i++ {
+		for _, name := range autoIncrementColumns {
+			if strings.EqualFold(resultColumns[i], name) {
+				autoIncIndex = i
+				break
+			}
+		}
+	}
+
+	var columns []ColumnInfo
+	dest := make([]driver.Value, len(resultColumns))
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		// Patterns treat '_' and '%' as wildcards, so compare names explicitly
+		if !c.identifiersEqual(castToString(dest[2]), table) {
+			continue
+		}
+		if schema != "" && dest[1] != nil && !c.identifiersEqual(castToString(dest[1]), schema) {
+			continue
+		}
+
+		col := ColumnInfo{
+			Name:          castToString(dest[3]),
+			DataType:      SQLSMALLINT(catalogInt64(dest[4])),
+			TypeName:      castToString(dest[5]),
+			ColumnSize:    catalogInt64(dest[6]),
+			DecimalDigits: catalogInt64(dest[8]),
+			Nullable:      catalogInt64(dest[10]) == int64(SQL_NULLABLE),
+			Remarks:       castToString(dest[11]),
+			Ordinal:       int(catalogInt64(dest[16])),
+		}
+		if dest[12] != nil {
+			def := castToString(dest[12])
+			col.Default = &def
+		}
+		if autoIncIndex >= 0 {
+			col.AutoIncrement = isTruthy(dest[autoIncIndex])
+		}
+		if col.Default != nil && strings.HasPrefix(strings.ToLower(*col.Default), "nextval(") {
+			col.AutoIncrement = true // PostgreSQL serial columns
+		}
+		columns = append(columns, col)
+	}
+
+	sort.SliceStable(columns, func(i, j int) bool { return columns[i].Ordinal < columns[j].Ordinal })
+	return columns, nil
+}
+
+// applyIdentityInfo marks identity columns and records their seed and increment
+func (c *Conn) applyIdentityInfo(ctx context.Context, columns []ColumnInfo, query string, args []interface{}) error {
+	rows, err := c.QueryContext(ctx, query, namedValues(args))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, len(rows.Columns()))
+	if len(dest) < 3 {
+		return fmt.Errorf("identity query returned %d columns", len(dest))
+	}
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		name := castToString(dest[0])
+		for i := range columns {
+			if !c.identifiersEqual(columns[i].Name, name) {
+				continue
+			}
+			columns[i].AutoIncrement = true
+			if dest[1] != nil {
+				seed := catalogInt64(dest[1])
+				columns[i].IdentitySeed = &seed
+			}
+			if dest[2] != nil {
+				increment := catalogInt64(dest[2])
+				columns[i].IdentityIncrement = &increment
+			}
+		}
+	}
+}
+
+// identityQuery returns the identity column query for the connection's database type
+func (c *Conn) identityQuery() identityQuery {
+	if dbTypeLower := strings.ToLower(c.dbType); dbTypeLower != "" {
+		for dbName, query := range identityQueries {
+			if strings.Contains(dbTypeLower, dbName) {
+				return query
+			}
+		}
+	}
+	return nil
+}
+
+// catalogInt64 reads an integer catalog value, returning 0 for NULL or unparsable values
+func catalogInt64(value driver.Value) int64 {
+	if value == nil {
+		return 0
+	}
+	v, err := castToInt64(value)
+	if err != nil {
+		return 0
+	}
+	return v.(int64)
+}
+
+// isTruthy interprets driver-specific flag values such as "YES", 1 or true
+func isTruthy(value driver.Value) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case nil:
+		return false
+	default:
+		switch strings.ToUpper(strings.TrimSpace(castToString(v))) {
+		case "YES", "Y", "TRUE", "1":
+			return true
+		}
+		return false
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql/driver"
 	"errors"
+	"log/slog"
 	"strings"
 	"sync"
 	"time"
@@ -27,6 +28,15 @@ var lastInsertIdQueries = map[string]string{
 	// Oracle uses RETURNING clause or sequences
 }
 
+// pingQueries maps database types to their ping query, for databases that
+// cannot run SELECT without a table
+var pingQueries = map[string]string{
+	"oracle":   "SELECT 1 FROM DUAL",
+	"db2":      "SELECT 1 FROM SYSIBM.SYSDUMMY1",
+	"firebird": "SELECT 1 FROM RDB$DATABASE",
+	"informix": "SELECT 1 FROM systables WHERE tabid = 1",
+}
+
 // Conn implements driver.Conn and represents a connection to a database
 type Conn struct {
 	env    SQLHENV
@@ -35,12 +45,101 @@ type Conn struct {
 	mu     sync.Mutex
 	closed bool
 
+	// sharedEnv is true when env belongs to an Environment and must not be freed here
+	sharedEnv bool
+
+	// diagnostics keeps the connection's recent diagnostic records for DebugDump
+	diagnostics diagnosticLog
+
+	// connectedDSN is the redacted connection string completed by the driver
+	connectedDSN string
+
 	// Database type detection for LastInsertId
 	dbType               string
 	lastInsertIdBehavior LastInsertIdBehavior
 
+	// quirk is the registered quirk for the database type, or nil
+	quirk *Quirk
+
+	// Result metadata options
+	unknownColumnSize  UnknownColumnSizeBehavior
+	timestampFetchMode TimestampFetchMode
+	guidFetchMode      GUIDFetchMode
+	decimalFetchMode   DecimalFetchMode
+	outOfRangeTime     OutOfRangeTimeMode
+	timezone           *time.Location // Location for timestamps in map rows (nil = UTC)
+	boolRules          []BoolRule     // Flag columns converted to bool (see WithBoolRules)
+	maxColumnBytes     int            // Largest value read with SQLGetData (0 = unlimited)
+	truncationPolicy   TruncationPolicy
+	copyMode           CopyMode // Who owns []byte column values (see WithCopyMode)
+
+	// Parameter binding options
+	timeBindMode TimeBindMode
+	timeLayout   string
+	invalidText  InvalidTextMode
+
+	// Identifier rules detected from the driver
+	identifierCase       IdentifierCase
+	identifierQuote      string
+	identifierCasePolicy IdentifierCasePolicy
+
+	// Transaction isolation levels reported by the driver as SQL_TXN_* bits
+	// (0 = not reported)
+	defaultTxnIsolation uint32
+	txnIsolationOptions uint32
+	txnIsolationSet     bool // BeginTx changed the isolation level; ResetSession restores it
+
+	// Driver limits detected after connecting
+	maxConcurrentActivities int    // SQL_MAX_CONCURRENT_ACTIVITIES (0 = no limit or unknown)
+	getDataExtensions       uint32 // SQL_GETDATA_EXTENSIONS bitmask (0 = not reported)
+	getDataInOrder          bool   // SQLGetData reads unbound columns in increasing order only
+
+	// serverInfo holds the SQLGetInfo values read by the first ServerInfo call
+	serverInfo *ServerInfo
+
+	// Session state restored by ResetSession
+	catalog      string        // SQL_ATTR_CURRENT_CATALOG after connecting
+	connectAttrs []ConnectAttr // attributes set before connecting (see WithConnectAttr)
+	resetQuery   string        // run when the connection is reused (see WithResetQuery)
+	pingQuery    string        // checks connectivity, "" for the database's default (see WithPingQuery)
+
+	// accessMode is the SQL_ATTR_ACCESS_MODE restored after a transaction
+	// (SQL_MODE_READ_WRITE unless set with WithConnectAttr)
+	accessMode uintptr
+
+	// unicode selects the ANSI or W entry points for SQL text and column names
+	unicode UnicodeMode
+
+	// wideFetch fetches CHAR/VARCHAR columns as SQL_C_WCHAR (see WithWideFetch)
+	wideFetch bool
+
 	// Query execution options
-	queryTimeout time.Duration
+	queryTimeout        time.Duration
+	rowArraySize        int  // Rows fetched per SQLFetch in block fetch mode (<= 1 = disabled)
+	prefetch            bool // Fetch the next rowset in the background during block fetching
+	correlationComments bool // Prefix statements with the context's correlation ID
+
+	// workloadHint is the workload hint last applied to the session (see WithWorkloadHint)
+	workloadHint WorkloadHint
+
+	// Driver manager trace started with StartTrace (nil when not tracing)
+	trace *tracer
+
+	// Keepalive state (see WithKeepAlive)
+	pinger    *keepAlivePinger // nil when keepalive is disabled
+	idleSince time.Time        // when database/sql returned the connection to the pool, zero while in use
+	dead      bool             // broken, or must not return to the pool; database/sql discards it
+
+	// Batch execution options
+	multiRowInsert   bool
+	batchErrorValues *BatchErrorValues
+
+	metrics MetricsCollector // receives driver events, nil if disabled
+	tracer  Tracer           // creates spans around operations, nil if disabled
+	logger  *slog.Logger     // logs driver warnings and retries, nil if disabled
+	retryer *Retryer         // retries transient failures of read-only queries, nil if disabled
+
+	stmtCache *stmtCache // prepared statements reused by query text, nil if disabled
 }
 
 // Prepare prepares a statement for execution
@@ -49,7 +148,16 @@ func (c *Conn) Prepare(query string) (driver.Stmt, error) {
 }
 
 // PrepareContext prepares a statement with context support
-func (c *Conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+func (c *Conn) PrepareContext(ctx context.Context, query string) (_ driver.Stmt, err error) {
+	op, err := lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer op.end()
+
+	span := c.startSpan(ctx, SpanPrepare, query)
+	defer func() { endSpan(span, err) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -63,21 +171,28 @@ func (c *Conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, e
 	if namedParams != nil {
 		prepareQuery = namedParams.Query
 	}
+	prepareQuery = c.tagQuery(ctx, prepareQuery)
 
 	// Allocate statement handle
 	var stmtHandle SQLHSTMT
 	ret := AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(c.dbc), (*SQLHANDLE)(&stmtHandle))
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+		return nil, c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
 	}
+	c.applyStmtAttrs(ctx, stmtHandle)
 
 	// Prepare the statement
-	ret = Prepare(stmtHandle, prepareQuery)
+	start := time.Now()
+	ret = c.prepare(stmtHandle, prepareQuery)
+	prepareTime := time.Since(start)
 	if !IsSuccess(ret) {
-		err := NewError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+		err := c.newError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
 		FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+		c.observePrepare(query, prepareTime, err)
 		return nil, err
 	}
+	c.observePrepare(query, prepareTime, nil)
+	c.logInfo(ctx, ret, stmtHandle, "prepare")
 
 	// Get number of parameters
 	var numParams SQLSMALLINT
@@ -85,6 +200,10 @@ func (c *Conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, e
 	if !IsSuccess(ret) {
 		// Non-fatal: some drivers don't support NumParams, default to -1 (unknown)
 		numParams = -1
+	} else if namedParams != nil {
+		// database/sql checks the argument count against NumInput, and a
+		// named parameter takes one argument however often it appears
+		numParams = SQLSMALLINT(len(namedParams.Names))
 	}
 
 	stmt := &Stmt{
@@ -93,6 +212,9 @@ func (c *Conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, e
 		query:       query,
 		numInput:    int(numParams),
 		namedParams: namedParams,
+		paramNames:  c.procParamNames(query, namedParams),
+		returnValue: namedParams == nil && hasReturnValue(query),
+		prepareTime: prepareTime,
 	}
 
 	return stmt, nil
@@ -107,7 +229,18 @@ func (c *Conn) Close() error {
 	if c.closed {
 		return nil
 	}
+	c.stopTraceLocked()
 	c.closed = true
+	if c.stmtCache != nil {
+		c.stmtCache.purge()
+	}
+	lifecycle.removeConn(c)
+	if c.pinger != nil {
+		c.pinger.remove(c)
+	}
+	if c.metrics != nil {
+		c.metrics.Disconnect()
+	}
 
 	// Disconnect and free handles
 	if c.dbc != 0 {
@@ -116,13 +249,22 @@ func (c *Conn) Close() error {
 		c.dbc = 0
 	}
 	if c.env != 0 {
-		FreeHandle(SQL_HANDLE_ENV, SQLHANDLE(c.env))
+		if !c.sharedEnv {
+			FreeHandle(SQL_HANDLE_ENV, SQLHANDLE(c.env))
+		}
 		c.env = 0
 	}
 
 	return nil
 }
 
+// isClosed reports whether the connection has been closed
+func (c *Conn) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
 // Begin starts a new transaction with default options.
 // Deprecated: Use BeginTx with context and options instead.
 func (c *Conn) Begin() (driver.Tx, error) {
@@ -156,8 +298,11 @@ func (c *Conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, e
 			isoLevel = SQL_TXN_READ_COMMITTED
 		case driver.IsolationLevel(4): // LevelRepeatableRead
 			isoLevel = SQL_TXN_REPEATABLE_READ
-		case driver.IsolationLevel(5): // LevelSnapshot (use serializable as fallback)
+		case driver.IsolationLevel(5): // LevelSnapshot (SQL Server snapshot, else serializable)
 			isoLevel = SQL_TXN_SERIALIZABLE
+			if c.supportsIsolation(SQL_TXN_SS_SNAPSHOT) {
+				isoLevel = SQL_TXN_SS_SNAPSHOT
+			}
 		case driver.IsolationLevel(6): // LevelSerializable
 			isoLevel = SQL_TXN_SERIALIZABLE
 		case driver.IsolationLevel(7): // LevelLinearizable (use serializable)
@@ -167,22 +312,23 @@ func (c *Conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, e
 		}
 		ret := SetConnectAttr(c.dbc, SQL_ATTR_TXN_ISOLATION, isoLevel, 0)
 		if !IsSuccess(ret) {
-			return nil, NewError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+			return nil, c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
 		}
+		c.txnIsolationSet = true
 	}
 
 	// Set read-only mode if requested
 	if opts.ReadOnly {
 		ret := SetConnectAttr(c.dbc, SQL_ATTR_ACCESS_MODE, SQL_MODE_READ_ONLY, 0)
 		if !IsSuccess(ret) {
-			return nil, NewError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+			return nil, c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
 		}
 	}
 
 	// Disable autocommit to start transaction
 	ret := SetConnectAttr(c.dbc, SQL_ATTR_AUTOCOMMIT, uintptr(SQL_AUTOCOMMIT_OFF), 0)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+		return nil, c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
 	}
 
 	c.inTx = true
@@ -190,7 +336,11 @@ func (c *Conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, e
 }
 
 // Ping verifies the database connection is still alive.
-// It executes a simple query (SELECT 1) to check connectivity.
+// It reads SQL_ATTR_CONNECTION_DEAD, which reports without a round trip
+// whether the driver has found the connection lost, or if the driver does not
+// support it executes a simple query (SELECT 1, or the one set with
+// WithPingQuery) to check connectivity. The query is cancelled when ctx is
+// done, returning the context's error.
 // Returns driver.ErrBadConn if the connection is no longer valid.
 func (c *Conn) Ping(ctx context.Context) error {
 	c.mu.Lock()
@@ -199,7 +349,59 @@ func (c *Conn) Ping(ctx context.Context) error {
 	if c.closed {
 		return driver.ErrBadConn
 	}
+	if dead, ok := getConnectAttrInt(c.dbc, SQL_ATTR_CONNECTION_DEAD); ok {
+		if dead == SQL_CD_TRUE {
+			return driver.ErrBadConn
+		}
+		return nil
+	}
+	return c.pingLocked(ctx)
+}
+
+// cancelOnDone calls cancel on another goroutine if ctx is done before the
+// returned stop function is called. stop waits for a cancel in progress, so
+// the handle cancel uses can be freed once it returns.
+func cancelOnDone(ctx context.Context, cancel func()) (stop func()) {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// pingQueryFor returns the query that checks the connection's connectivity
+func (c *Conn) pingQueryFor() string {
+	if c.pingQuery != "" {
+		return c.pingQuery
+	}
+	if c.quirk != nil && c.quirk.PingQuery != "" {
+		return c.quirk.PingQuery
+	}
+	if dbTypeLower := strings.ToLower(c.dbType); dbTypeLower != "" {
+		for dbName, q := range pingQueries {
+			if strings.Contains(dbTypeLower, dbName) {
+				return q
+			}
+		}
+	}
+	return "SELECT 1"
+}
 
+// pingLocked runs the ping query, cancelling it when ctx is done. The caller
+// must hold c.mu.
+func (c *Conn) pingLocked(ctx context.Context) error {
 	// Allocate a temporary statement handle
 	var stmtHandle SQLHSTMT
 	ret := AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(c.dbc), (*SQLHANDLE)(&stmtHandle))
@@ -209,14 +411,19 @@ func (c *Conn) Ping(ctx context.Context) error {
 	defer FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
 
 	// Execute a simple query to verify connection
-	ret = ExecDirect(stmtHandle, "SELECT 1")
+	stop := cancelOnDone(ctx, func() { Cancel(stmtHandle) })
+	ret = c.execDirect(stmtHandle, c.pingQueryFor())
+	stop()
 	if !IsSuccess(ret) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		// Check if it's a connection error
-		if err := NewError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle)); IsConnectionError(err) {
+		if err := c.newError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle)); IsConnectionError(err) {
 			return driver.ErrBadConn
 		}
-		// Some databases don't support "SELECT 1", try just allocating a handle
-		// If the handle allocation succeeded, the connection is likely fine
+		// Some databases reject the query; if the handle allocation
+		// succeeded, the connection is likely fine
 		return nil
 	}
 
@@ -226,9 +433,20 @@ func (c *Conn) Ping(ctx context.Context) error {
 // ExecContext executes a query that doesn't return rows (INSERT, UPDATE, DELETE).
 // It supports context cancellation and query timeout. If args is empty, the query
 // is executed directly; otherwise a prepared statement is used.
-func (c *Conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+func (c *Conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (_ driver.Result, err error) {
 	// If no args, use direct execution
 	if len(args) == 0 {
+		var op *operation
+		op, err = lifecycle.begin()
+		if err != nil {
+			return nil, err
+		}
+		defer op.end()
+
+		c.applyWorkloadHint(ctx)
+		span := c.startSpan(ctx, SpanExec, query)
+		defer func() { endSpan(span, err) }()
+
 		c.mu.Lock()
 		if c.closed {
 			c.mu.Unlock()
@@ -238,20 +456,17 @@ func (c *Conn) ExecContext(ctx context.Context, query string, args []driver.Name
 		var stmtHandle SQLHSTMT
 		ret := AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(c.dbc), (*SQLHANDLE)(&stmtHandle))
 		if !IsSuccess(ret) {
-			err := NewError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+			err := c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
 			c.mu.Unlock()
 			return nil, err
 		}
 		c.mu.Unlock()
 		defer FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+		c.applyStmtAttrs(ctx, stmtHandle)
 
-		// Set query timeout if configured
-		if c.queryTimeout > 0 {
-			timeoutSecs := int(c.queryTimeout.Seconds())
-			if timeoutSecs < 1 {
-				timeoutSecs = 1
-			}
-			SetStmtAttr(stmtHandle, SQL_ATTR_QUERY_TIMEOUT, uintptr(timeoutSecs), 0)
+		// Set query timeout from the context deadline or connection default
+		if secs := c.queryTimeoutSecs(ctx); secs > 0 {
+			SetStmtAttr(stmtHandle, SQL_ATTR_QUERY_TIMEOUT, secs, 0)
 		}
 
 		// Start cancellation goroutine if context has deadline/cancel
@@ -272,36 +487,87 @@ func (c *Conn) ExecContext(ctx context.Context, query string, args []driver.Name
 			return nil, err
 		}
 
-		ret = ExecDirect(stmtHandle, query)
+		start := time.Now()
+		ret = c.execDirect(stmtHandle, c.tagQuery(ctx, query))
+		executeTime := time.Since(start)
 		if !IsSuccess(ret) && ret != SQL_NO_DATA {
 			// Check if cancelled by context
 			if ctx.Err() != nil {
-				return nil, ctx.Err()
+				return nil, c.observeError(MetricsExec, query, ctx.Err())
 			}
-			return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+			return nil, c.observeError(MetricsExec, query, c.newError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle)))
 		}
+		c.logInfo(ctx, ret, stmtHandle, "exec")
 
 		var rowCount SQLLEN
 		RowCount(stmtHandle, &rowCount)
+		c.observeExec(MetricsExec, query, executeTime, int64(rowCount))
+		if span != nil {
+			span.SetAttributes(Attribute{Key: AttrRowsAffected, Value: int64(rowCount)})
+		}
+
+		statsCollectorFromContext(ctx).add(QueryStats{Queries: 1, ExecuteTime: executeTime})
 
-		return &Result{rowsAffected: int64(rowCount)}, nil
+		return &Result{rowsAffected: int64(rowCount), allAffected: rowCounts(stmtHandle, int64(rowCount))}, nil
 	}
 
-	// Use prepared statement for parameterized queries
-	stmt, err := c.PrepareContext(ctx, query)
+	// Use a prepared statement, cached if WithStmtCacheSize is set, for parameterized queries
+	stmt, release, err := c.prepareCached(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
-	return stmt.(*Stmt).ExecContext(ctx, args)
+	defer func() { release(err) }()
+	return stmt.ExecContext(ctx, args)
+}
+
+// queryTimeoutSecs returns the SQL_ATTR_QUERY_TIMEOUT for an execution: the
+// time left until the context's deadline, or the connection's QueryTimeout if
+// it is shorter or the context has no deadline, rounded up to whole seconds
+// (0 = no timeout). The timeout limits execution on the server; deadlines
+// closer than a second are still enforced by SQLCancel when ctx is done.
+func (c *Conn) queryTimeoutSecs(ctx context.Context) uintptr {
+	timeout := c.queryTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		left := time.Until(deadline)
+		if left < time.Second {
+			return 1
+		}
+		if timeout <= 0 || left < timeout {
+			timeout = left
+		}
+	}
+	if timeout <= 0 {
+		return 0
+	}
+	return uintptr((timeout + time.Second - 1) / time.Second)
 }
 
 // QueryContext executes a query that returns rows (SELECT).
 // It supports context cancellation and query timeout. If args is empty, the query
-// is executed directly; otherwise a prepared statement is used.
+// is executed directly; otherwise a prepared statement is used. Read-only queries
+// are retried on transient errors if the connector has a Retryer.
 func (c *Conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.retriesQuery(query, args) {
+		return c.retryQuery(ctx, func() (driver.Rows, error) { return c.queryContext(ctx, query, args) })
+	}
+	return c.queryContext(ctx, query, args)
+}
+
+// queryContext makes a single attempt to execute a query
+func (c *Conn) queryContext(ctx context.Context, query string, args []driver.NamedValue) (_ driver.Rows, err error) {
 	// If no args, use direct execution
 	if len(args) == 0 {
+		var op *operation
+		op, err = lifecycle.begin()
+		if err != nil {
+			return nil, err
+		}
+		defer op.end() // no-op once handed off to the rows
+
+		c.applyWorkloadHint(ctx)
+		span := c.startSpan(ctx, SpanQuery, query)
+		defer func() { endSpan(span, err) }()
+
 		c.mu.Lock()
 		if c.closed {
 			c.mu.Unlock()
@@ -311,19 +577,16 @@ func (c *Conn) QueryContext(ctx context.Context, query string, args []driver.Nam
 		var stmtHandle SQLHSTMT
 		ret := AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(c.dbc), (*SQLHANDLE)(&stmtHandle))
 		if !IsSuccess(ret) {
-			err := NewError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+			err := c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
 			c.mu.Unlock()
 			return nil, err
 		}
 		c.mu.Unlock()
+		c.applyStmtAttrs(ctx, stmtHandle)
 
-		// Set query timeout if configured
-		if c.queryTimeout > 0 {
-			timeoutSecs := int(c.queryTimeout.Seconds())
-			if timeoutSecs < 1 {
-				timeoutSecs = 1
-			}
-			SetStmtAttr(stmtHandle, SQL_ATTR_QUERY_TIMEOUT, uintptr(timeoutSecs), 0)
+		// Set query timeout from the context deadline or connection default
+		if secs := c.queryTimeoutSecs(ctx); secs > 0 {
+			SetStmtAttr(stmtHandle, SQL_ATTR_QUERY_TIMEOUT, secs, 0)
 		}
 
 		// Start cancellation goroutine if context has deadline/cancel
@@ -345,17 +608,20 @@ func (c *Conn) QueryContext(ctx context.Context, query string, args []driver.Nam
 			return nil, err
 		}
 
-		ret = ExecDirect(stmtHandle, query)
+		start := time.Now()
+		ret = c.execDirect(stmtHandle, c.tagQuery(ctx, query))
+		executeTime := time.Since(start)
 		if !IsSuccess(ret) {
 			// Check if cancelled by context
 			if ctx.Err() != nil {
 				FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
-				return nil, ctx.Err()
+				return nil, c.observeError(MetricsQuery, query, ctx.Err())
 			}
-			err := NewError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+			err := c.newError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
 			FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
-			return nil, err
+			return nil, c.observeError(MetricsQuery, query, err)
 		}
+		c.observeExec(MetricsQuery, query, executeTime, -1)
 
 		// Create a temporary stmt wrapper for rows
 		stmt := &Stmt{
@@ -363,26 +629,41 @@ func (c *Conn) QueryContext(ctx context.Context, query string, args []driver.Nam
 			stmt:  stmtHandle,
 			query: query,
 		}
-		return newRows(stmt, true) // closeStmt=true since we own the handle
+		rows, err := newRows(stmt, true) // closeStmt=true since we own the handle
+		if err != nil {
+			return nil, c.observeError(MetricsQuery, query, err)
+		}
+		rows.setColumnCasts(columnCastsFromContext(ctx))
+		rows.setLOBStreaming(lobStreamingFromContext(ctx))
+		rows.setStats(ctx, 0, executeTime)
+		rows.watchContext(ctx)
+		rows.span = c.startSpan(ctx, SpanFetch, query)
+		rows.op = op.handoff()
+		return rows, nil
 	}
 
-	// Use prepared statement for parameterized queries
-	stmt, err := c.PrepareContext(ctx, query)
+	// Use a prepared statement, cached if WithStmtCacheSize is set, for parameterized queries
+	stmt, release, err := c.prepareCached(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	rows, err := stmt.(*Stmt).QueryContext(ctx, args)
+	rows, err := stmt.queryContext(ctx, args)
 	if err != nil {
-		stmt.Close()
+		release(err)
 		return nil, err
 	}
-	// Set closeStmt on rows so statement is closed when rows are closed
-	rows.(*Rows).closeStmt = true
+	// The statement is released, or closed if not cached, when the rows are closed
+	rows.(*Rows).release = release
 	return rows, nil
 }
 
-// ResetSession is called by database/sql before a connection is returned to the pool.
-// It verifies the connection is in a valid state (not closed, not in a transaction).
+// ResetSession is called by database/sql before a connection that was used is
+// handed to its next borrower. It verifies the connection is in a valid state
+// (not closed, not in a transaction), then restores the session state the
+// previous borrower may have changed: autocommit, the isolation level set by
+// BeginTx, the query timeout and max rows defaults set on the connection and
+// the original catalog (database), and runs the reset query set with
+// WithResetQuery. If that fails the connection is discarded.
 func (c *Conn) ResetSession(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -396,15 +677,30 @@ func (c *Conn) ResetSession(ctx context.Context) error {
 		return driver.ErrBadConn
 	}
 
+	// The connection is leaving the pool; a keepalive ping may have found it broken
+	c.idleSince = time.Time{}
+	if c.dead {
+		return driver.ErrBadConn
+	}
+
+	if err := c.resetSession(ctx); err != nil {
+		return c.resetFailed(ctx, err)
+	}
 	return nil
 }
 
 // IsValid implements driver.Validator and returns true if the connection is usable.
 // Used by database/sql to check if a connection should be discarded.
+// database/sql calls it when the connection is returned to the pool, so it
+// also starts the idle period tracked for keepalive pings.
 func (c *Conn) IsValid() bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return !c.closed && c.dbc != 0
+	valid := !c.closed && c.dbc != 0 && !c.dead
+	if valid {
+		c.idleSince = time.Now()
+	}
+	return valid
 }
 
 // CheckNamedValue validates and converts named values
@@ -421,8 +717,11 @@ func (c *Conn) getLastInsertId() int64 {
 
 	// Find the appropriate query for this database type
 	var query string
+	if c.quirk != nil {
+		query = c.quirk.LastInsertIdQuery
+	}
 
-	if dbTypeLower := strings.ToLower(c.dbType); dbTypeLower != "" {
+	if dbTypeLower := strings.ToLower(c.dbType); query == "" && dbTypeLower != "" {
 		for dbName, q := range lastInsertIdQueries {
 			if strings.Contains(dbTypeLower, dbName) {
 				query = q
@@ -466,24 +765,53 @@ func (c *Conn) getLastInsertId() int64 {
 	return value
 }
 
-// detectDatabaseType queries the ODBC driver for the database type
+// detectDatabaseType queries the ODBC driver for the database type and looks
+// up the quirk registered for it
 func (c *Conn) detectDatabaseType() {
-	buf := make([]byte, 256)
-	strLen, ret := GetInfo(c.dbc, SQL_DBMS_NAME, buf)
-	if IsSuccess(ret) && strLen > 0 {
-		// Find the null terminator
-		end := int(strLen)
-		if end > len(buf) {
-			end = len(buf)
-		}
-		for i := 0; i < end; i++ {
-			if buf[i] == 0 {
-				end = i
-				break
-			}
+	if name, ok := c.getInfoString(SQL_DBMS_NAME); ok {
+		// Some drivers count a null terminator in the length
+		if i := strings.IndexByte(name, 0); i >= 0 {
+			name = name[:i]
 		}
-		c.dbType = string(buf[:end])
+		c.dbType = name
+	}
+	c.quirk = lookupQuirk(c.dbType)
+}
+
+// QueryScrollable executes a query with a scrollable cursor (CursorStatic,
+// CursorKeyset or CursorDynamic) and returns rows that can be navigated with
+// First, Last, Prior, Absolute and Relative, and counted with RowCount.
+// Closing the rows also closes the statement. Drivers that do not support
+// the cursor type may substitute another one.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    rows, err := dc.(*godbc.Conn).QueryScrollable(ctx, "SELECT id, name FROM users", godbc.CursorStatic)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    defer rows.Close()
+//	    total, err := rows.RowCount()
+//	    ...
+//	})
+func (c *Conn) QueryScrollable(ctx context.Context, query string, cursorType CursorType, args ...driver.NamedValue) (ScrollableRows, error) {
+	if cursorType == CursorForwardOnly {
+		return nil, errors.New("QueryScrollable requires a scrollable cursor type")
+	}
+	ds, err := c.PrepareWithCursor(ctx, query, cursorType)
+	if err != nil {
+		return nil, err
+	}
+	stmt := ds.(*Stmt)
+	dr, err := stmt.QueryContext(ctx, args)
+	if err != nil {
+		stmt.Close()
+		return nil, err
 	}
+	rows := dr.(*Rows)
+	rows.closeStmt = true
+	return rows, nil
 }
 
 // PrepareWithCursor prepares a statement with a specific cursor type.
@@ -500,8 +828,9 @@ func (c *Conn) PrepareWithCursor(ctx context.Context, query string, cursorType C
 	var stmtHandle SQLHSTMT
 	ret := AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(c.dbc), (*SQLHANDLE)(&stmtHandle))
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+		return nil, c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
 	}
+	c.applyStmtAttrs(ctx, stmtHandle)
 
 	// Set cursor type
 	var odbcCursorType uintptr
@@ -520,6 +849,7 @@ func (c *Conn) PrepareWithCursor(ctx context.Context, query string, cursorType C
 	if !IsSuccess(ret) {
 		// Non-fatal: cursor type may not be supported
 	}
+	c.logInfo(ctx, ret, stmtHandle, "set cursor type")
 
 	// Set scrollable if not forward-only
 	if cursorType != CursorForwardOnly {
@@ -530,12 +860,17 @@ func (c *Conn) PrepareWithCursor(ctx context.Context, query string, cursorType C
 	}
 
 	// Prepare the statement
-	ret = Prepare(stmtHandle, query)
+	start := time.Now()
+	ret = c.prepare(stmtHandle, c.tagQuery(ctx, query))
+	prepareTime := time.Since(start)
 	if !IsSuccess(ret) {
-		err := NewError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+		err := c.newError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
 		FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+		c.observePrepare(query, prepareTime, err)
 		return nil, err
 	}
+	c.observePrepare(query, prepareTime, nil)
+	c.logInfo(ctx, ret, stmtHandle, "prepare")
 
 	// Get number of parameters
 	var numParams SQLSMALLINT
@@ -545,11 +880,12 @@ func (c *Conn) PrepareWithCursor(ctx context.Context, query string, cursorType C
 	}
 
 	stmt := &Stmt{
-		conn:       c,
-		stmt:       stmtHandle,
-		query:      query,
-		numInput:   int(numParams),
-		cursorType: cursorType,
+		conn:        c,
+		stmt:        stmtHandle,
+		query:       query,
+		numInput:    int(numParams),
+		cursorType:  cursorType,
+		returnValue: hasReturnValue(query),
 	}
 
 	return stmt, nil
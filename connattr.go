@@ -0,0 +1,101 @@
+package godbc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+	"unsafe"
+)
+
+// ConnectAttr is a connection attribute set with SQLSetConnectAttr before the
+// connection is made (see WithConnectAttr)
+type ConnectAttr struct {
+	Attr  SQLINTEGER  // Attribute, e.g. SQL_ATTR_LOGIN_TIMEOUT or a driver-specific value
+	Value interface{} // Integer, bool, time.Duration (whole seconds) or string
+}
+
+// setConnectAttrs sets connection attributes on an unconnected handle
+// and logs drivers' warnings about them, such as substituted values
+func setConnectAttrs(ctx context.Context, dbc SQLHDBC, attrs []ConnectAttr, logger *slog.Logger) error {
+	for _, a := range attrs {
+		ret, err := setConnectAttr(dbc, a)
+		if err != nil {
+			return err
+		}
+		logDiagnostics(ctx, logger, ret, SQL_HANDLE_DBC, SQLHANDLE(dbc), "set connect attr")
+	}
+	return nil
+}
+
+// setConnectAttr sets a single connection attribute. Strings are passed as
+// null-terminated text; other values are passed as integers.
+func setConnectAttr(dbc SQLHDBC, a ConnectAttr) (SQLRETURN, error) {
+	var ret SQLRETURN
+	if s, ok := a.Value.(string); ok {
+		buf := append([]byte(s), 0)
+		ret = SetConnectAttr(dbc, a.Attr, uintptr(unsafe.Pointer(&buf[0])), SQL_NTS)
+		runtime.KeepAlive(buf)
+	} else {
+		value, ok := connectAttrInt(a.Value)
+		if !ok {
+			return SQL_ERROR, fmt.Errorf("connection attribute %d: unsupported value %v (%T)", a.Attr, a.Value, a.Value)
+		}
+		ret = SetConnectAttr(dbc, a.Attr, value, 0)
+	}
+	if !IsSuccess(ret) {
+		return ret, fmt.Errorf("connection attribute %d: %w", a.Attr, NewError(SQL_HANDLE_DBC, SQLHANDLE(dbc)))
+	}
+	return ret, nil
+}
+
+// connectAttrInt converts an integer attribute value, reporting false for
+// negative numbers and unsupported types. Durations are whole seconds, the
+// unit of the ODBC timeout attributes.
+func connectAttrInt(value interface{}) (uintptr, bool) {
+	var n int64
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return SQL_TRUE, true
+		}
+		return SQL_FALSE, true
+	case time.Duration:
+		n = int64((v + time.Second - 1) / time.Second)
+	case int:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint:
+		return uintptr(v), true
+	case uint32:
+		return uintptr(v), true
+	case uint64:
+		return uintptr(v), true
+	case uintptr:
+		return v, true
+	default:
+		return 0, false
+	}
+	if n < 0 {
+		return 0, false
+	}
+	return uintptr(n), true
+}
+
+// accessModeOf returns the SQL_ATTR_ACCESS_MODE set by connection attributes,
+// which transactions restore when they end
+func accessModeOf(attrs []ConnectAttr) uintptr {
+	mode := uintptr(SQL_MODE_READ_WRITE)
+	for _, a := range attrs {
+		if a.Attr == SQL_ATTR_ACCESS_MODE {
+			if v, ok := connectAttrInt(a.Value); ok {
+				mode = v
+			}
+		}
+	}
+	return mode
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql/driver"
 	"errors"
+	"log/slog"
+	"sync"
 	"time"
 )
 
@@ -17,8 +19,71 @@ type Connector struct {
 	DefaultTimestampPrecision TimestampPrecision   // Default precision for Timestamp type (defaults to Milliseconds)
 	LastInsertIdBehavior      LastInsertIdBehavior // How to handle LastInsertId() (defaults to Auto)
 
+	// Result metadata options
+	UnknownColumnSize    UnknownColumnSizeBehavior // How to report columns without a usable size (defaults to Unbounded)
+	IdentifierCasePolicy IdentifierCasePolicy      // How metadata helpers fold identifier case (defaults to Auto)
+	TimestampFetchMode   TimestampFetchMode        // How TIMESTAMP columns are returned (defaults to time.Time)
+	GUIDFetchMode        GUIDFetchMode             // How GUID columns are returned (defaults to formatted string)
+	DecimalFetchMode     DecimalFetchMode          // How DECIMAL/NUMERIC columns are returned (defaults to driver text)
+	OutOfRangeTime       OutOfRangeTimeMode        // How unrepresentable dates and timestamps are returned (defaults to OutOfRangeTime)
+	BoolRules            []BoolRule                // Columns that store flags in non-boolean types, converted to bool (defaults to none)
+	MaxColumnBytes       int                       // Largest character or binary value read with SQLGetData (0 = unlimited)
+	TruncationPolicy     TruncationPolicy          // What happens to values larger than MaxColumnBytes (defaults to Error)
+	CopyMode             CopyMode                  // Who owns []byte column values (defaults to Always)
+
+	// Parameter binding options
+	TimeBindMode TimeBindMode    // How time parameters are bound (defaults to Auto)
+	TimeLayout   string          // Layout of time parameters bound as strings (defaults to the dialect's or DefaultTimeLayout)
+	InvalidText  InvalidTextMode // How string parameters that are not valid UTF-8 are bound (defaults to Replace)
+
+	// Character set options
+	Unicode   UnicodeMode // ANSI or wide (W) entry points for connection strings, SQL text and column names (defaults to Auto)
+	WideFetch bool        // Fetch CHAR/VARCHAR columns as SQL_C_WCHAR instead of SQL_C_CHAR
+
 	// Query execution options
-	QueryTimeout time.Duration // Default query timeout (0 = no timeout)
+	QueryTimeout        time.Duration // Default query timeout (0 = no timeout)
+	RowArraySize        int           // Rows fetched per round trip with bound columns (0 or 1 = SQLGetData per value)
+	Prefetch            bool          // Fetch the next rowset in the background while the current one is read (requires RowArraySize)
+	CorrelationComments bool          // Prefix statements with the context's correlation ID (see WithCorrelationID)
+	StmtCacheSize       int           // Prepared statements kept per connection for queries with arguments (0 = disabled)
+
+	// Batch execution options
+	MultiRowInsert   bool              // Synthesize multi-row INSERTs when array binding is unsupported
+	BatchErrorValues *BatchErrorValues // Keep the parameter values of failed rows in BatchResult.Errors (nil = disabled)
+
+	// Metrics receives connection, statement and fetch events (nil = disabled)
+	Metrics MetricsCollector
+
+	// Tracer creates spans around connects, prepares, executions and fetches (nil = disabled)
+	Tracer Tracer
+
+	// Logger receives driver warnings, the completed connection string and retry decisions (nil = disabled)
+	Logger *slog.Logger
+
+	// Pool options
+	KeepAlive time.Duration // Ping connections idle in the pool at this interval (0 = disabled)
+
+	// CircuitBreaker fails connects fast while the database is down (nil = disabled)
+	CircuitBreaker *CircuitBreaker
+
+	// Retryer retries transient failures of connects and read-only queries (nil = disabled)
+	Retryer *Retryer
+
+	// ConnectAttrs are connection attributes set before connecting (see WithConnectAttr)
+	ConnectAttrs []ConnectAttr
+
+	// ResetQuery is run when a pooled connection is reused (see WithResetQuery)
+	ResetQuery string
+
+	// PingQuery checks connectivity when the driver cannot report a lost
+	// connection, and for keepalive pings ("" = SELECT 1 or the database's equivalent)
+	PingQuery string
+
+	// Environment is a shared environment to allocate connections on (nil = private per connection)
+	Environment *Environment
+
+	mu     sync.Mutex
+	pinger *keepAlivePinger // Keepalive pinger shared by the connector's connections, created on first use
 }
 
 // ConnectorOption configures a Connector
@@ -45,6 +110,152 @@ func WithLastInsertIdBehavior(behavior LastInsertIdBehavior) ConnectorOption {
 	}
 }
 
+// WithUnknownColumnSize sets how ColumnTypeLength reports columns whose size
+// the driver reports as 0, negative (SQL_NO_TOTAL) or overflowed
+func WithUnknownColumnSize(behavior UnknownColumnSizeBehavior) ConnectorOption {
+	return func(c *Connector) {
+		c.UnknownColumnSize = behavior
+	}
+}
+
+// WithIdentifierCasePolicy sets how metadata helpers treat identifier case
+func WithIdentifierCasePolicy(policy IdentifierCasePolicy) ConnectorOption {
+	return func(c *Connector) {
+		c.IdentifierCasePolicy = policy
+	}
+}
+
+// WithTimestampFetchMode sets how TIMESTAMP columns are returned.
+// TimestampFetchEpochNanos returns int64 nanoseconds since the Unix epoch,
+// which avoids per-value time.Time construction on very large scans.
+func WithTimestampFetchMode(mode TimestampFetchMode) ConnectorOption {
+	return func(c *Connector) {
+		c.TimestampFetchMode = mode
+	}
+}
+
+// WithGUIDFetchMode sets how GUID/UNIQUEIDENTIFIER columns are returned.
+// GUIDFetchBinary returns the 16 bytes of each GUID instead of formatted
+// strings, for systems that store UUIDs as binary; scan them into a
+// godbc.GUID to use them as parameters.
+func WithGUIDFetchMode(mode GUIDFetchMode) ConnectorOption {
+	return func(c *Connector) {
+		c.GUIDFetchMode = mode
+	}
+}
+
+// WithDecimalFetchMode sets how DECIMAL/NUMERIC columns are returned.
+// DecimalFetchNumeric fetches them as SQL_C_NUMERIC and returns godbc.Decimal
+// values, so the formatting does not depend on the driver.
+func WithDecimalFetchMode(mode DecimalFetchMode) ConnectorOption {
+	return func(c *Connector) {
+		c.DecimalFetchMode = mode
+	}
+}
+
+// WithTimeBinding sets how time.Time, Timestamp and TimestampTZ parameters
+// are bound. Some drivers, such as older Microsoft Access and some Informix
+// builds, reject SQL_C_TIMESTAMP parameters but accept datetime literals;
+// TimeBindString binds the values as strings formatted with layout, a
+// time.Format layout. An empty layout uses the database's known literal
+// format, or DefaultTimeLayout. TimestampTZ values are formatted in UTC.
+//
+// Example:
+//
+//	godbc.WithTimeBinding(godbc.TimeBindString, "2006-01-02 15:04:05")
+func WithTimeBinding(mode TimeBindMode, layout string) ConnectorOption {
+	return func(c *Connector) {
+		c.TimeBindMode = mode
+		c.TimeLayout = layout
+	}
+}
+
+// WithOutOfRangeTimeMode sets how DATE and TIMESTAMP values outside years
+// 1-9999 (BC dates, PostgreSQL 'infinity') or with invalid fields are returned.
+// OutOfRangeTimeClamp maps 'infinity' and '-infinity' to MaxTime and MinTime.
+func WithOutOfRangeTimeMode(mode OutOfRangeTimeMode) ConnectorOption {
+	return func(c *Connector) {
+		c.OutOfRangeTime = mode
+	}
+}
+
+// WithBoolRules converts columns that store flags in non-boolean types, such
+// as MySQL TINYINT(1) or CHAR(1) 'Y'/'N', to bool at fetch time, and bool
+// parameters back to the stored representation for rules with Bind set.
+// The first rule that matches a column applies.
+func WithBoolRules(rules ...BoolRule) ConnectorOption {
+	return func(c *Connector) {
+		c.BoolRules = rules
+	}
+}
+
+// WithUnicode selects the ODBC entry points used for the connection string,
+// SQL text, catalog arguments, cursor names, column names and attributes,
+// driver information and diagnostic messages. On Unix the ANSI functions can mangle non-ASCII
+// passwords, table names and SQL text with some drivers; UnicodeWide passes
+// all of them to the W functions as SQLWCHAR text instead. UnicodeAuto, the
+// default, does so only for text that is not plain ASCII, except on Windows,
+// where it always does.
+func WithUnicode(mode UnicodeMode) ConnectorOption {
+	return func(c *Connector) {
+		c.Unicode = mode
+	}
+}
+
+// WithWideFetch fetches CHAR, VARCHAR and LONGVARCHAR columns as SQL_C_WCHAR
+// and converts them from UTF-16, as NCHAR columns are. Drivers convert
+// SQL_C_CHAR data to the client code page, which garbles text that code page
+// cannot represent with some drivers (such as Oracle and DB2 with a non-UTF-8
+// locale); the driver's conversion to UTF-16 is lossless.
+func WithWideFetch(enabled bool) ConnectorOption {
+	return func(c *Connector) {
+		c.WideFetch = enabled
+	}
+}
+
+// WithInvalidTextMode sets how string parameters that are not valid UTF-8 are
+// bound. By default each invalid sequence is replaced with U+FFFD;
+// InvalidTextReject fails the statement with an *InvalidTextError naming the
+// parameter instead, so corrupt text is not written silently.
+func WithInvalidTextMode(mode InvalidTextMode) ConnectorOption {
+	return func(c *Connector) {
+		c.InvalidText = mode
+	}
+}
+
+// WithMaxColumnBytes bounds the memory a single character or binary value
+// read with SQLGetData can use to n bytes, as the driver returns the data
+// (UTF-16 or UTF-32 for wide character columns). Larger values are handled
+// according to the truncation policy (see WithTruncationPolicy), which fails
+// the fetch by default. A value of 0 means no limit (the default).
+func WithMaxColumnBytes(n int) ConnectorOption {
+	return func(c *Connector) {
+		c.MaxColumnBytes = n
+	}
+}
+
+// WithTruncationPolicy sets what happens to values larger than
+// WithMaxColumnBytes: TruncationError fails the fetch with a
+// *ColumnTooLargeError (the default), TruncationTruncate returns the first
+// bytes of the value, and TruncationStream returns the last column of the
+// row as a *LOBReader instead.
+func WithTruncationPolicy(policy TruncationPolicy) ConnectorOption {
+	return func(c *Connector) {
+		c.TruncationPolicy = policy
+	}
+}
+
+// WithCopyMode sets who owns the []byte values returned by Rows:
+// CopyAlways returns copies the caller owns (the default), and
+// CopyBorrowUntilNext returns values that share the driver's buffers and are
+// only valid until the next row, avoiding a copy per value when the caller
+// consumes each row before moving on, as with sql.RawBytes or NextBatch.
+func WithCopyMode(mode CopyMode) ConnectorOption {
+	return func(c *Connector) {
+		c.CopyMode = mode
+	}
+}
+
 // WithQueryTimeout sets the default query timeout for all statements.
 // The timeout is applied using SQL_ATTR_QUERY_TIMEOUT and context cancellation.
 // A value of 0 means no timeout (the default).
@@ -54,57 +265,380 @@ func WithQueryTimeout(d time.Duration) ConnectorOption {
 	}
 }
 
-// Connect establishes a new connection to the database
+// WithRowArraySize enables block fetching: result columns are bound once with
+// SQLBindCol and n rows are fetched per SQLFetch using SQL_ATTR_ROW_ARRAY_SIZE,
+// instead of one SQLGetData call per column per row. Result sets with LOB or
+// unbounded columns, and scrollable cursors, still use SQLGetData.
+func WithRowArraySize(n int) ConnectorOption {
+	return func(c *Connector) {
+		c.RowArraySize = n
+	}
+}
+
+// WithStmtCacheSize keeps up to n prepared statements per connection, by query
+// text, for queries with arguments run through Conn.ExecContext and
+// Conn.QueryContext (db.Exec and db.Query), so running the same query again
+// skips SQLPrepare. The least recently used statement is closed when the cache
+// is full. A statement that fails is not reused, and a connection error clears
+// the cache. A value of 0 disables the cache (the default).
+func WithStmtCacheSize(n int) ConnectorOption {
+	return func(c *Connector) {
+		c.StmtCacheSize = n
+	}
+}
+
+// WithPrefetch fetches the next rowset on a background goroutine while the
+// current one is being consumed, hiding round-trip latency to remote warehouses.
+// It applies only when block fetching is in effect (see WithRowArraySize) and
+// doubles the memory used for rowset buffers.
+func WithPrefetch(enabled bool) ConnectorOption {
+	return func(c *Connector) {
+		c.Prefetch = enabled
+	}
+}
+
+// WithCorrelationComments prefixes each statement executed with a context
+// carrying a correlation ID (see WithCorrelationID) with a
+// /* correlation_id=... */ comment, so DBA-side monitoring can be joined
+// with application traces. Prepared statements are tagged when prepared.
+func WithCorrelationComments(enabled bool) ConnectorOption {
+	return func(c *Connector) {
+		c.CorrelationComments = enabled
+	}
+}
+
+// WithMultiRowInsert enables multi-row INSERT synthesis for ExecBatch.
+// When the driver does not support array binding, single-row INSERT ... VALUES (?, ...)
+// statements are expanded into INSERT ... VALUES (...), (...), ... statements sized to
+// respect the database's parameter limit, instead of executing one row at a time.
+func WithMultiRowInsert(enabled bool) ConnectorOption {
+	return func(c *Connector) {
+		c.MultiRowInsert = enabled
+	}
+}
+
+// WithBatchErrorValues keeps copies of the parameter values of each parameter
+// set that fails in ExecBatch, so ETL jobs can move the offending rows to a
+// dead-letter table. The error for the row in BatchResult.Errors becomes a
+// *BatchRowError holding the values; use errors.As to read them.
+//
+// Example:
+//
+//	godbc.WithBatchErrorValues(godbc.BatchErrorValues{
+//	    MaxValueSize: 1024,
+//	    Redact: func(p driver.NamedValue) interface{} {
+//	        if p.Ordinal == 3 {
+//	            return "***"
+//	        }
+//	        return p.Value
+//	    },
+//	})
+func WithBatchErrorValues(cfg BatchErrorValues) ConnectorOption {
+	return func(c *Connector) {
+		c.BatchErrorValues = &cfg
+	}
+}
+
+// WithMetrics reports connections, prepared statements, executions, fetched
+// rows and errors to a MetricsCollector, e.g. to maintain Prometheus or
+// OpenTelemetry counters and latency histograms.
+func WithMetrics(m MetricsCollector) ConnectorOption {
+	return func(c *Connector) {
+		c.Metrics = m
+	}
+}
+
+// WithTracer creates spans around Connect, Prepare, Exec, Query and the fetch
+// loop of each result set, annotated following the OpenTelemetry database
+// semantic conventions: the database system and DBMS name, the statement with
+// literals replaced by ?, rows affected or fetched, and the SQLSTATE of errors.
+// Spans are children of the span in the operation's context.
+func WithTracer(t Tracer) ConnectorOption {
+	return func(c *Connector) {
+		c.Tracer = t
+	}
+}
+
+// WithLogger logs what the driver would otherwise discard: the diagnostics of
+// calls that return SQL_SUCCESS_WITH_INFO, such as truncation (01004) and
+// changed option values (01S02), at warn level (informational 01000 messages
+// at debug level); the connection string completed by the driver, with
+// secrets redacted, at debug level; and statement retries and discarded
+// connections at warn level.
+func WithLogger(logger *slog.Logger) ConnectorOption {
+	return func(c *Connector) {
+		c.Logger = logger
+	}
+}
+
+// WithKeepAlive pings connections that have been idle in the database/sql pool
+// for the given interval, so firewalls and load balancers that drop quiet TCP
+// sessions cannot silently kill them between batch runs. A connection whose
+// ping fails with a connection error is discarded when it is next taken from
+// the pool, and the query runs on a new connection instead. Use an interval
+// shorter than the network's idle timeout. A value of 0 disables keepalive.
+func WithKeepAlive(interval time.Duration) ConnectorOption {
+	return func(c *Connector) {
+		c.KeepAlive = interval
+	}
+}
+
+// WithCircuitBreaker stops connecting while the database is down: after the
+// breaker's threshold of consecutive failed connects, Connect fails
+// immediately with a *CircuitOpenError (matching ErrCircuitOpen) until the
+// cooldown has passed and a probe connect succeeds. See CircuitBreaker.
+func WithCircuitBreaker(cb *CircuitBreaker) ConnectorOption {
+	return func(c *Connector) {
+		c.CircuitBreaker = cb
+	}
+}
+
+// WithRetryer retries connects and read-only queries that fail with a
+// transient error, such as a connection failure (08xxx), a serialization
+// failure (40001) or a timeout (HYT00), with r's backoff. Only SELECT and
+// VALUES statements run outside a transaction are retried, and a query whose
+// connection failed is not, since the connection cannot be used again.
+// Retries are logged to the connector's Logger. See Retryer.
+func WithRetryer(r *Retryer) ConnectorOption {
+	return func(c *Connector) {
+		c.Retryer = r
+	}
+}
+
+// WithConnectAttr sets a connection attribute with SQLSetConnectAttr before
+// each connection is made, such as SQL_ATTR_LOGIN_TIMEOUT, SQL_ATTR_PACKET_SIZE,
+// SQL_ATTR_ACCESS_MODE or SQL_ATTR_CURRENT_CATALOG, or a driver-specific
+// attribute. The value is an integer, a bool, a time.Duration for timeouts
+// (whole seconds) or a string. A read-only SQL_ATTR_ACCESS_MODE is restored
+// after each transaction. Connecting fails if the driver rejects an attribute.
+//
+// Example:
+//
+//	godbc.WithConnectAttr(godbc.SQL_ATTR_LOGIN_TIMEOUT, 5*time.Second)
+//	godbc.WithConnectAttr(godbc.SQL_ATTR_ACCESS_MODE, godbc.SQL_MODE_READ_ONLY)
+func WithConnectAttr(attr SQLINTEGER, value interface{}) ConnectorOption {
+	return func(c *Connector) {
+		c.ConnectAttrs = append(c.ConnectAttrs, ConnectAttr{Attr: attr, Value: value})
+	}
+}
+
+// WithResetQuery runs a statement each time database/sql hands a used
+// connection to a new borrower, after autocommit, the isolation level and the
+// catalog have been restored, to clear session state the driver cannot, such
+// as temporary tables and session variables: e.g. "DISCARD ALL" on PostgreSQL
+// or "RESET CONNECTION" on MySQL 8. A connection whose reset query fails is
+// discarded.
+func WithResetQuery(query string) ConnectorOption {
+	return func(c *Connector) {
+		c.ResetQuery = query
+	}
+}
+
+// WithPingQuery sets the query Ping runs when the driver does not support
+// SQL_ATTR_CONNECTION_DEAD, and that keepalive pings run, replacing SELECT 1 or
+// the database's equivalent (SELECT 1 FROM DUAL on Oracle, SELECT 1 FROM
+// SYSIBM.SYSDUMMY1 on DB2), for databases that need another statement or a
+// cheaper one.
+func WithPingQuery(query string) ConnectorOption {
+	return func(c *Connector) {
+		c.PingQuery = query
+	}
+}
+
+// Connect establishes a new connection to the database, retrying transient
+// failures if a Retryer is set
 func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
-	// Allocate environment handle
+	if c.Retryer == nil {
+		return c.connect(ctx)
+	}
+	var conn driver.Conn
+	err := c.Retryer.do(ctx, func() error {
+		var err error
+		conn, err = c.connect(ctx)
+		return err
+	}, c.Retryer.retryable, logRetry(ctx, c.Logger, "connect"))
+	return conn, err
+}
+
+// connect makes a single connection attempt
+func (c *Connector) connect(ctx context.Context) (_ driver.Conn, err error) {
+	op, err := lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer op.end()
+
+	var span Span
+	if c.Tracer != nil {
+		span = c.Tracer.Start(ctx, SpanConnect, nil)
+		defer func() { endSpan(span, err) }()
+	}
+
+	if cb := c.CircuitBreaker; cb != nil {
+		if err := cb.allow(); err != nil {
+			return nil, err
+		}
+		defer func() { cb.done(err, ctx.Err() != nil) }()
+	}
+
+	if c.Unicode == UnicodeWide && !wideAPI {
+		return nil, errors.New("UnicodeWide requires the wide (W) ODBC functions, which the loaded library does not export")
+	}
+
+	// Use the shared environment if configured, otherwise allocate a private one
 	var env SQLHENV
-	ret := AllocHandle(SQL_HANDLE_ENV, SQL_NULL_HANDLE, (*SQLHANDLE)(&env))
-	if !IsSuccess(ret) {
-		return nil, errors.New("failed to allocate ODBC environment handle")
+	sharedEnv := c.Environment != nil
+	freeEnv := func() {
+		if !sharedEnv {
+			FreeHandle(SQL_HANDLE_ENV, SQLHANDLE(env))
+		}
 	}
+	if sharedEnv {
+		c.Environment.mu.Lock()
+		closed := c.Environment.closed
+		env = c.Environment.env
+		c.Environment.mu.Unlock()
+		if closed {
+			return nil, errors.New("environment is closed")
+		}
+	} else {
+		ret := AllocHandle(SQL_HANDLE_ENV, SQL_NULL_HANDLE, (*SQLHANDLE)(&env))
+		if !IsSuccess(ret) {
+			return nil, errors.New("failed to allocate ODBC environment handle")
+		}
 
-	// Set ODBC version to 3.x
-	ret = SetEnvAttr(env, SQL_ATTR_ODBC_VERSION, uintptr(SQL_OV_ODBC3), 0)
-	if !IsSuccess(ret) {
-		FreeHandle(SQL_HANDLE_ENV, SQLHANDLE(env))
-		return nil, NewError(SQL_HANDLE_ENV, SQLHANDLE(env))
+		// Set ODBC version to 3.x
+		ret = SetEnvAttr(env, SQL_ATTR_ODBC_VERSION, uintptr(SQL_OV_ODBC3), 0)
+		if !IsSuccess(ret) {
+			err := NewError(SQL_HANDLE_ENV, SQLHANDLE(env))
+			FreeHandle(SQL_HANDLE_ENV, SQLHANDLE(env))
+			return nil, err
+		}
 	}
 
 	// Allocate connection handle
 	var dbc SQLHDBC
-	ret = AllocHandle(SQL_HANDLE_DBC, SQLHANDLE(env), (*SQLHANDLE)(&dbc))
+	ret := AllocHandle(SQL_HANDLE_DBC, SQLHANDLE(env), (*SQLHANDLE)(&dbc))
 	if !IsSuccess(ret) {
 		err := NewError(SQL_HANDLE_ENV, SQLHANDLE(env))
-		FreeHandle(SQL_HANDLE_ENV, SQLHANDLE(env))
+		freeEnv()
 		return nil, err
 	}
 
-	// Connect using the connection string
-	outConnStr := make([]byte, 1024)
-	_, ret = DriverConnect(dbc, 0, c.dsn, outConnStr, SQL_DRIVER_NOPROMPT)
+	if err := setConnectAttrs(ctx, dbc, c.ConnectAttrs, c.Logger); err != nil {
+		FreeHandle(SQL_HANDLE_DBC, SQLHANDLE(dbc))
+		freeEnv()
+		return nil, err
+	}
+
+	// Connect using the connection string. Where the library exports
+	// SQLCancelHandle, the login is cancelled when ctx is done, on ODBC 3.8
+	// drivers that support cancelling connection functions.
+	start := time.Now()
+	stop := func() {}
+	if sqlCancelHandle != nil {
+		stop = cancelOnDone(ctx, func() { CancelHandle(SQL_HANDLE_DBC, SQLHANDLE(dbc)) })
+	}
+	connectedDSN, ret := driverConnect(dbc, c.Unicode, c.dsn)
+	stop()
+	connectTime := time.Since(start)
 	if !IsSuccess(ret) {
 		err := NewError(SQL_HANDLE_DBC, SQLHANDLE(dbc))
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
 		FreeHandle(SQL_HANDLE_DBC, SQLHANDLE(dbc))
-		FreeHandle(SQL_HANDLE_ENV, SQLHANDLE(env))
+		freeEnv()
+		if c.Metrics != nil {
+			c.Metrics.Error(MetricsConnect, "", err)
+		}
 		return nil, err
 	}
+	logDiagnostics(ctx, c.Logger, ret, SQL_HANDLE_DBC, SQLHANDLE(dbc), "connect")
 
 	// Create and return the connection
 	conn := &Conn{
 		env:                  env,
+		sharedEnv:            sharedEnv,
 		dbc:                  dbc,
 		lastInsertIdBehavior: c.LastInsertIdBehavior,
+		unknownColumnSize:    c.UnknownColumnSize,
+		identifierCasePolicy: c.IdentifierCasePolicy,
+		timestampFetchMode:   c.TimestampFetchMode,
+		guidFetchMode:        c.GUIDFetchMode,
+		decimalFetchMode:     c.DecimalFetchMode,
+		timeBindMode:         c.TimeBindMode,
+		timeLayout:           c.TimeLayout,
+		invalidText:          c.InvalidText,
+		outOfRangeTime:       c.OutOfRangeTime,
+		timezone:             c.DefaultTimezone,
+		boolRules:            c.BoolRules,
+		maxColumnBytes:       c.MaxColumnBytes,
+		truncationPolicy:     c.TruncationPolicy,
+		copyMode:             c.CopyMode,
 		queryTimeout:         c.QueryTimeout,
+		rowArraySize:         c.RowArraySize,
+		correlationComments:  c.CorrelationComments,
+		prefetch:             c.Prefetch,
+		multiRowInsert:       c.MultiRowInsert,
+		batchErrorValues:     c.BatchErrorValues,
+		unicode:              c.Unicode,
+		wideFetch:            c.WideFetch,
+		accessMode:           accessModeOf(c.ConnectAttrs),
+		connectAttrs:         c.ConnectAttrs,
+		resetQuery:           c.ResetQuery,
+		pingQuery:            c.PingQuery,
+		stmtCache:            newStmtCache(c.StmtCacheSize),
+		connectedDSN:         RedactConnString(connectedDSN),
+		metrics:              c.Metrics,
+		tracer:               c.Tracer,
+		logger:               c.Logger,
+		retryer:              c.Retryer,
+	}
+	if conn.metrics != nil {
+		conn.metrics.Connect(connectTime)
 	}
 
-	// Detect database type for LastInsertId support
-	if conn.lastInsertIdBehavior == LastInsertIdAuto {
-		conn.detectDatabaseType()
+	if err := conn.checkLengthWidth(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// Detect database type for LastInsertId support and dialect-specific limits
+	conn.detectDatabaseType()
+	if c.Logger != nil {
+		c.Logger.LogAttrs(ctx, slog.LevelDebug, "odbc connected", logAttrs(ctx,
+			slog.String("dsn", conn.connectedDSN),
+			slog.String("dbms", conn.dbType))...)
+	}
+	if span != nil {
+		span.SetAttributes(
+			Attribute{Key: AttrDBSystemName, Value: dbSystemName(conn.dbType)},
+			Attribute{Key: AttrDBMSName, Value: conn.dbType})
 	}
+	conn.detectIdentifierRules()
+	conn.detectTxnIsolation()
+	conn.detectDriverLimits()
+	conn.catalog, _ = getConnectAttrString(conn.dbc, SQL_ATTR_CURRENT_CATALOG)
 
+	lifecycle.addConn(conn)
+	if c.KeepAlive > 0 {
+		conn.pinger = c.keepAlivePinger()
+		conn.pinger.add(conn)
+	}
 	return conn, nil
 }
 
+// keepAlivePinger returns the connector's keepalive pinger, creating it on first use
+func (c *Connector) keepAlivePinger() *keepAlivePinger {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pinger == nil {
+		c.pinger = newKeepAlivePinger(c.KeepAlive)
+	}
+	return c.pinger
+}
+
 // Driver returns the underlying Driver
 func (c *Connector) Driver() driver.Driver {
 	return c.driver
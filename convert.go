@@ -1,11 +1,15 @@
 package godbc
 
 import (
+	"database/sql/driver"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf16"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -49,20 +53,177 @@ func timestampColumnSize(precision TimestampPrecision) SQLULEN {
 
 // stringToUTF16 converts a UTF-8 string to UTF-16LE with null terminator
 func stringToUTF16(s string) []uint16 {
-	runes := []rune(s)
-	result := make([]uint16, 0, len(runes)+1)
-	for _, r := range runes {
-		if r > 0xFFFF {
-			// Encode as surrogate pair
-			r -= 0x10000
-			result = append(result, uint16((r>>10)+0xD800))
-			result = append(result, uint16((r&0x3FF)+0xDC00))
-		} else {
-			result = append(result, uint16(r))
-		}
-	}
-	result = append(result, 0) // Null terminator
-	return result
+	// A rune never needs more UTF-16 code units than UTF-8 bytes,
+	// so len(s)+1 always fits the result and its terminator
+	result := make([]uint16, len(s)+1)
+
+	// Fast path: ASCII bytes map one-to-one onto code units
+	n := asciiPrefixLen(s)
+	for i := 0; i < n; i++ {
+		result[i] = uint16(s[i])
+	}
+	if n == len(s) {
+		return result // Terminator is already zero
+	}
+
+	out := result[:n]
+	for _, r := range s[n:] {
+		out = utf16.AppendRune(out, r)
+	}
+	return append(out, 0) // Null terminator
+}
+
+// asciiPrefixLen returns the length of the leading ASCII run of s,
+// checking eight bytes at a time
+func asciiPrefixLen(s string) int {
+	i := 0
+	for ; i+8 <= len(s); i += 8 {
+		word := uint64(s[i]) | uint64(s[i+1])<<8 | uint64(s[i+2])<<16 | uint64(s[i+3])<<24 |
+			uint64(s[i+4])<<32 | uint64(s[i+5])<<40 | uint64(s[i+6])<<48 | uint64(s[i+7])<<56
+		if word&0x8080808080808080 != 0 {
+			break
+		}
+	}
+	for ; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			break
+		}
+	}
+	return i
+}
+
+// utf16ToString converts a UTF-16 encoded slice to a UTF-8 string.
+// Unpaired surrogates are replaced with U+FFFD.
+func utf16ToString(u []uint16) string {
+	n := utf16ASCIIPrefixLen(u)
+	if n == len(u) {
+		// Fast path: every code unit is a single ASCII byte
+		buf := make([]byte, len(u))
+		for i, c := range u {
+			buf[i] = byte(c)
+		}
+		return unsafe.String(unsafe.SliceData(buf), len(buf))
+	}
+
+	// BMP code units need at most 3 UTF-8 bytes; surrogate pairs need 4 bytes for 2 units
+	buf := make([]byte, n, n+(len(u)-n)*3)
+	for i := 0; i < n; i++ {
+		buf[i] = byte(u[i])
+	}
+	for i := n; i < len(u); i++ {
+		r := rune(u[i])
+		switch {
+		case r < utf8.RuneSelf:
+			buf = append(buf, byte(r))
+			continue
+		case utf16.IsSurrogate(r):
+			if i+1 < len(u) {
+				if dec := utf16.DecodeRune(r, rune(u[i+1])); dec != utf8.RuneError {
+					r = dec
+					i++
+					break
+				}
+			}
+			r = utf8.RuneError
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return unsafe.String(unsafe.SliceData(buf), len(buf))
+}
+
+// stringToUTF32 converts a UTF-8 string to UTF-32 with null terminator,
+// for driver managers with 4-byte SQLWCHAR
+func stringToUTF32(s string) []uint32 {
+	result := make([]uint32, 0, len(s)+1)
+	for _, r := range s {
+		result = append(result, uint32(r))
+	}
+	return append(result, 0) // Null terminator
+}
+
+// utf32ToString converts a UTF-32 encoded slice to a UTF-8 string.
+// Invalid code points are replaced with U+FFFD.
+func utf32ToString(u []uint32) string {
+	buf := make([]byte, 0, len(u))
+	for _, c := range u {
+		buf = utf8.AppendRune(buf, rune(c))
+	}
+	return string(buf)
+}
+
+// wideStringParam converts a string to a null-terminated SQLWCHAR buffer of the
+// driver manager's width, returning the buffer, its length in characters and its
+// length in bytes, both excluding the terminator
+func wideStringParam(s string) (buf interface{}, charCount int, byteLen int) {
+	if wcharSize() == 4 {
+		utf32Buf := stringToUTF32(s)
+		charCount = len(utf32Buf) - 1
+		return utf32Buf, charCount, charCount * 4
+	}
+	utf16Buf := stringToUTF16(s)
+	charCount = len(utf16Buf) - 1
+	return utf16Buf, charCount, charCount * 2
+}
+
+// wideBufferString decodes the first chars characters of an SQLWCHAR output
+// buffer of the driver manager's width, stopping at a null terminator
+func wideBufferString(buf []byte, chars int) string {
+	return decodeWideBuffer(buf, chars, true)
+}
+
+// wideBufferList decodes the first chars characters of an SQLWCHAR output
+// buffer like wideBufferString, but keeps null characters, which separate the
+// entries of lists such as the SQLDriversW attributes
+func wideBufferList(buf []byte, chars int) string {
+	return decodeWideBuffer(buf, chars, false)
+}
+
+func decodeWideBuffer(buf []byte, chars int, stopAtNull bool) string {
+	size := wcharSize()
+	if chars < 0 {
+		return ""
+	}
+	if limit := len(buf) / size; chars > limit {
+		chars = limit
+	}
+	if chars == 0 {
+		return ""
+	}
+	if size == 4 {
+		u := unsafe.Slice((*uint32)(unsafe.Pointer(&buf[0])), chars)
+		for i, c := range u {
+			if c == 0 && stopAtNull {
+				u = u[:i]
+				break
+			}
+		}
+		return utf32ToString(u)
+	}
+	u := unsafe.Slice((*uint16)(unsafe.Pointer(&buf[0])), chars)
+	for i, c := range u {
+		if c == 0 && stopAtNull {
+			u = u[:i]
+			break
+		}
+	}
+	return utf16ToString(u)
+}
+
+// utf16ASCIIPrefixLen returns the length of the leading ASCII run of u,
+// checking four code units at a time
+func utf16ASCIIPrefixLen(u []uint16) int {
+	i := 0
+	for ; i+4 <= len(u); i += 4 {
+		if (u[i]|u[i+1]|u[i+2]|u[i+3])&0xFF80 != 0 {
+			break
+		}
+	}
+	for ; i < len(u); i++ {
+		if u[i] >= utf8.RuneSelf {
+			break
+		}
+	}
+	return i
 }
 
 // =============================================================================
@@ -106,9 +267,98 @@ func ParseGUID(s string) (GUID, error) {
 	return g, nil
 }
 
-// convertToODBC converts a Go value to ODBC binding parameters
+// String formats the GUID as xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx, the inverse of ParseGUID
+func (g GUID) String() string {
+	return guidStruct(g).String()
+}
+
+// Value implements driver.Valuer, returning the GUID as a formatted string for
+// drivers and tools that do not know the type. godbc itself binds GUID
+// parameters as SQL_C_GUID without calling Value.
+func (g GUID) Value() (driver.Value, error) {
+	return g.String(), nil
+}
+
+// Scan implements sql.Scanner. It accepts a GUID, a formatted string with or
+// without braces, or a []byte holding either the text form or the 16 bytes
+// of a GUID in the layout ParseGUID produces. Use *GUID or sql.Null[GUID]
+// for nullable columns.
+func (g *GUID) Scan(src any) error {
+	switch v := src.(type) {
+	case GUID:
+		*g = v
+		return nil
+	case string:
+		return g.UnmarshalText([]byte(v))
+	case []byte:
+		if len(v) == len(g) {
+			copy(g[:], v)
+			return nil
+		}
+		return g.UnmarshalText(v)
+	case nil:
+		return fmt.Errorf("cannot scan NULL into GUID")
+	}
+	return fmt.Errorf("cannot scan %T into GUID", src)
+}
+
+// MarshalText implements encoding.TextMarshaler, so GUIDs encode as their
+// formatted string in JSON and other text formats
+func (g GUID) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the formats
+// ParseGUID does, optionally enclosed in braces.
+func (g *GUID) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if len(s) >= 2 && s[0] == '{' && s[len(s)-1] == '}' {
+		s = s[1 : len(s)-1]
+	}
+	parsed, err := ParseGUID(s)
+	if err != nil {
+		return err
+	}
+	*g = parsed
+	return nil
+}
+
+// guidFromStruct converts a fetched SQL_GUID_STRUCT to a GUID, storing Data1,
+// Data2 and Data3 little-endian regardless of host byte order (the layout
+// ParseGUID produces and SQL_C_GUID parameters expect)
+func guidFromStruct(s SQL_GUID_STRUCT) GUID {
+	var g GUID
+	binary.LittleEndian.PutUint32(g[0:4], s.Data1)
+	binary.LittleEndian.PutUint16(g[4:6], s.Data2)
+	binary.LittleEndian.PutUint16(g[6:8], s.Data3)
+	copy(g[8:], s.Data4[:])
+	return g
+}
+
+// guidStruct converts a GUID to its SQL_GUID_STRUCT fields
+func guidStruct(g GUID) SQL_GUID_STRUCT {
+	s := SQL_GUID_STRUCT{
+		Data1: binary.LittleEndian.Uint32(g[0:4]),
+		Data2: binary.LittleEndian.Uint16(g[4:6]),
+		Data3: binary.LittleEndian.Uint16(g[6:8]),
+	}
+	copy(s.Data4[:], g[8:])
+	return s
+}
+
+// convertToODBC converts a Go value to ODBC binding parameters, using the
+// converter registered for its type if there is one
 // Returns: buffer, C type, SQL type, column size, decimal digits, length indicator, error
 func convertToODBC(value interface{}) (interface{}, SQLSMALLINT, SQLSMALLINT, SQLULEN, SQLSMALLINT, SQLLEN, error) {
+	if conv := lookupConverter(value); conv != nil {
+		return convertRegistered(value, conv)
+	}
+	return convertValueToODBC(value)
+}
+
+// convertValueToODBC converts a Go value to ODBC binding parameters with the
+// driver's own conversions
+func convertValueToODBC(value interface{}) (interface{}, SQLSMALLINT, SQLSMALLINT, SQLULEN, SQLSMALLINT, SQLLEN, error) {
 	if value == nil {
 		return nil, SQL_C_CHAR, SQL_VARCHAR, 0, 0, SQLLEN(SQL_NULL_DATA), nil
 	}
@@ -183,11 +433,16 @@ func convertToODBC(value interface{}) (interface{}, SQLSMALLINT, SQLSMALLINT, SQ
 		return val, SQL_C_DOUBLE, SQL_DOUBLE, 15, 0, 8, nil
 
 	case string:
-		// Use UTF-16 for proper Unicode support across all databases
-		utf16Buf := stringToUTF16(v)
-		charCount := len(utf16Buf) - 1 // Exclude null terminator
-		bufBytes := charCount * 2      // 2 bytes per UTF-16 code unit
-		return utf16Buf, SQL_C_WCHAR, SQL_WVARCHAR, SQLULEN(charCount), 0, SQLLEN(bufBytes), nil
+		// Use wide characters for proper Unicode support across all databases
+		wideBuf, charCount, bufBytes := wideStringParam(v)
+		return wideBuf, SQL_C_WCHAR, SQL_WVARCHAR, SQLULEN(charCount), 0, SQLLEN(bufBytes), nil
+
+	case BitString:
+		return convertValueToODBC(string(v))
+
+	case []bool:
+		// PostgreSQL array literal, e.g. {t,f,t}
+		return convertValueToODBC(formatBoolArray(v))
 
 	case []byte:
 		if len(v) == 0 {
@@ -260,13 +515,10 @@ func convertToODBC(value interface{}) (interface{}, SQLSMALLINT, SQLSMALLINT, SQ
 		return ts, SQL_C_TIMESTAMP, SQL_TYPE_TIMESTAMP, colSize, decDigits, SQLLEN(unsafe.Sizeof(*ts)), nil
 
 	case WideString:
-		// UTF-16 wide string for NVARCHAR/NCHAR columns
-		utf16Buf := stringToUTF16(string(v))
-		// Column size is character count (excluding null terminator)
-		charCount := len(utf16Buf) - 1
-		// Buffer size in bytes (2 bytes per code unit), excluding null terminator
-		bufBytes := charCount * 2
-		return utf16Buf, SQL_C_WCHAR, SQL_WVARCHAR, SQLULEN(charCount), 0, SQLLEN(bufBytes), nil
+		// Wide string for NVARCHAR/NCHAR columns. Column size is the character
+		// count and the buffer length is in bytes, both excluding the terminator.
+		wideBuf, charCount, bufBytes := wideStringParam(string(v))
+		return wideBuf, SQL_C_WCHAR, SQL_WVARCHAR, SQLULEN(charCount), 0, SQLLEN(bufBytes), nil
 
 	case Decimal:
 		// Decimal with explicit precision/scale - bind as string for maximum compatibility
@@ -359,6 +611,13 @@ func getBufferPtr(buf interface{}) (uintptr, SQLLEN) {
 		}
 		return uintptr(unsafe.Pointer(&v[0])), SQLLEN(len(v) * 2)
 
+	case []uint32:
+		// For wide strings (UTF-32, 4-byte SQLWCHAR)
+		if len(v) == 0 {
+			return 0, 0
+		}
+		return uintptr(unsafe.Pointer(&v[0])), SQLLEN(len(v) * 4)
+
 	case *SQL_INTERVAL_STRUCT:
 		return uintptr(unsafe.Pointer(v)), SQLLEN(unsafe.Sizeof(*v))
 
@@ -385,6 +644,27 @@ func AllocateColumnArray(values []interface{}, numRows int) (*ColumnBuffer, erro
 		return nil, nil
 	}
 
+	values, binding, err := convertColumnValues(values)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := allocateColumnArray(values, numRows)
+	if err != nil || binding == nil || binding.SQLType == 0 {
+		return buf, err
+	}
+	buf.SQLType = binding.SQLType
+	if binding.ColumnSize != 0 {
+		buf.ColSize = binding.ColumnSize
+	}
+	if binding.DecimalDigits != 0 {
+		buf.DecDigits = binding.DecimalDigits
+	}
+	return buf, nil
+}
+
+// allocateColumnArray allocates a column buffer for values with the driver's
+// own conversions
+func allocateColumnArray(values []interface{}, numRows int) (*ColumnBuffer, error) {
 	// Find the first non-nil value to determine the type
 	var typeHint interface{}
 	for _, v := range values {
@@ -531,26 +811,42 @@ func AllocateColumnArray(values []interface{}, numRows int) (*ColumnBuffer, erro
 		if maxCharCount == 0 {
 			maxCharCount = 255
 		}
-		// Each element: (maxCharCount + 1) UTF-16 code units * 2 bytes each
-		elemSize := (maxCharCount + 1) * 2 // +1 for null terminator
+		// Each element: (maxCharCount + 1) SQLWCHAR code units. The UTF-16 unit
+		// count is also enough for UTF-32, which never needs more units.
+		unitSize := wcharSize()
+		elemSize := (maxCharCount + 1) * unitSize // +1 for null terminator
 
 		data := make([]byte, numRows*elemSize)
 		for i, v := range values {
 			if v == nil {
 				buf.Lengths[i] = SQL_NULL_DATA
 			} else if s, ok := v.(string); ok {
-				utf16Data := stringToUTF16(s)
 				offset := i * elemSize
-				// Copy UTF-16 data as bytes (little-endian)
-				for j, u := range utf16Data {
-					byteOffset := offset + j*2
-					if byteOffset+1 < len(data) {
-						data[byteOffset] = byte(u)
-						data[byteOffset+1] = byte(u >> 8)
+				var units int
+				if unitSize == 4 {
+					// Copy UTF-32 data as bytes (little-endian)
+					utf32Data := stringToUTF32(s)
+					for j, u := range utf32Data {
+						byteOffset := offset + j*4
+						if byteOffset+3 < len(data) {
+							binary.LittleEndian.PutUint32(data[byteOffset:], u)
+						}
+					}
+					units = len(utf32Data) - 1
+				} else {
+					// Copy UTF-16 data as bytes (little-endian)
+					utf16Data := stringToUTF16(s)
+					for j, u := range utf16Data {
+						byteOffset := offset + j*2
+						if byteOffset+1 < len(data) {
+							data[byteOffset] = byte(u)
+							data[byteOffset+1] = byte(u >> 8)
+						}
 					}
+					units = len(utf16Data) - 1
 				}
 				// Length is byte count excluding null terminator
-				buf.Lengths[i] = SQLLEN((len(utf16Data) - 1) * 2)
+				buf.Lengths[i] = SQLLEN(units * unitSize)
 			}
 		}
 		buf.Data = data
@@ -718,6 +1014,10 @@ func SQLTypeName(sqlType SQLSMALLINT) string {
 		return "TIMESTAMP"
 	case SQL_DATETIME:
 		return "DATETIME"
+	case SQL_TYPE_TIME_WITH_TIMEZONE:
+		return "TIME WITH TIME ZONE"
+	case SQL_TYPE_TIMESTAMP_WITH_TIMEZONE:
+		return "TIMESTAMP WITH TIME ZONE"
 	case SQL_GUID:
 		return "GUID"
 	// Interval types
@@ -748,6 +1048,9 @@ func SQLTypeName(sqlType SQLSMALLINT) string {
 	case SQL_INTERVAL_MINUTE_TO_SECOND:
 		return "INTERVAL MINUTE TO SECOND"
 	default:
+		if name, ok := LookupSQLTypeName(sqlType); ok {
+			return name
+		}
 		return fmt.Sprintf("UNKNOWN(%d)", sqlType)
 	}
 }
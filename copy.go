@@ -0,0 +1,238 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"time"
+)
+
+// CopyProgress reports the progress of a Copy after each committed batch
+type CopyProgress struct {
+	// Rows is the number of source rows committed to the destination,
+	// including those skipped with WithCopyResume; it is the value to resume from
+	Rows int64
+
+	// Batches is the number of batches committed by this Copy
+	Batches int
+
+	// Elapsed is the time since the Copy started
+	Elapsed time.Duration
+}
+
+// CopyOption configures a Copy
+type CopyOption func(*copier)
+
+// copier holds the settings of a Copy
+type copier struct {
+	columns    []string
+	batchSize  int
+	resume     int64
+	progress   func(CopyProgress)
+	checkpoint func(rows int64) error
+}
+
+// WithCopyColumns sets the destination columns the source columns are
+// copied into, in source column order (defaults to the source column names)
+func WithCopyColumns(columns ...string) CopyOption {
+	return func(c *copier) {
+		c.columns = columns
+	}
+}
+
+// WithCopyBatchSize sets the number of rows written and committed per batch
+// (defaults to 1000)
+func WithCopyBatchSize(n int) CopyOption {
+	return func(c *copier) {
+		c.batchSize = n
+	}
+}
+
+// WithCopyProgress sets a function called after each committed batch
+func WithCopyProgress(fn func(CopyProgress)) CopyOption {
+	return func(c *copier) {
+		c.progress = fn
+	}
+}
+
+// WithCopyCheckpoint sets a function called with the number of source rows
+// committed after each batch, to be saved and passed to WithCopyResume if
+// the copy is interrupted. An error stops the copy.
+func WithCopyCheckpoint(fn func(rows int64) error) CopyOption {
+	return func(c *copier) {
+		c.checkpoint = fn
+	}
+}
+
+// WithCopyResume skips the first n source rows, as committed by an earlier,
+// interrupted copy. The query must return its rows in a stable order, e.g.
+// with ORDER BY on a key, for the skipped rows to be the ones copied before.
+func WithCopyResume(n int64) CopyOption {
+	return func(c *copier) {
+		c.resume = n
+	}
+}
+
+// Copy streams the rows of a query on src into a table on dst and returns
+// the number of rows it wrote. Rows are written with a BulkCopy, in batches
+// with column-wise array binding, each committed in its own transaction
+// unless dst is in one; values are converted to the types of the destination
+// columns as reported by the catalog. The next batch is read while the
+// current one is written, and reading waits while a batch is pending, so a
+// slow destination holds back the source rather than filling memory.
+//
+// A failed batch returns a *BulkCopyError; earlier batches stay committed.
+// To resume, pass the last checkpoint to WithCopyResume.
+//
+// Example:
+//
+//	var src, dst *godbc.Conn // from sql.Conn.Raw on two connections
+//	n, err := godbc.Copy(ctx, src, "SELECT id, customer, total FROM orders ORDER BY id", dst, "staging.orders",
+//	    godbc.WithCopyBatchSize(5000),
+//	    godbc.WithCopyCheckpoint(func(rows int64) error { return saveCheckpoint(rows) }),
+//	    godbc.WithCopyResume(loadCheckpoint()))
+func Copy(ctx context.Context, src *Conn, query string, dst *Conn, table string, opts ...CopyOption) (int64, error) {
+	cp := &copier{batchSize: defaultBulkBatchSize}
+	for _, opt := range opts {
+		opt(cp)
+	}
+	if cp.batchSize < 1 {
+		return 0, fmt.Errorf("invalid batch size %d", cp.batchSize)
+	}
+	if cp.resume < 0 {
+		return 0, fmt.Errorf("invalid resume position %d", cp.resume)
+	}
+	if src == dst {
+		return 0, fmt.Errorf("copy needs separate source and destination connections")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rows, err := src.QueryContext(ctx, query, nil)
+	if err != nil {
+		return 0, fmt.Errorf("copy source: %w", err)
+	}
+	defer rows.Close()
+
+	srcColumns := rows.Columns()
+	columns := cp.columns
+	if columns == nil {
+		columns = srcColumns
+	}
+	if len(columns) != len(srcColumns) {
+		return 0, fmt.Errorf("copy: %d destination columns for %d source columns", len(columns), len(srcColumns))
+	}
+
+	bc, err := dst.BulkCopy(ctx, table, WithBulkColumns(columns...), WithBulkBatchSize(cp.batchSize))
+	if err != nil {
+		return 0, fmt.Errorf("copy destination: %w", err)
+	}
+
+	if err := skipRows(ctx, rows, len(srcColumns), cp.resume); err != nil {
+		bc.Close(ctx)
+		return 0, err
+	}
+
+	// The reader stays at most one batch ahead of the writer
+	batches := make(chan [][]driver.Value, 1)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(batches)
+		readErr <- readBatches(ctx, rows, len(srcColumns), cp.batchSize, batches)
+	}()
+
+	copied, err := cp.write(ctx, bc, batches)
+	if err != nil {
+		// Stop the reader and wait for it before the rows are closed
+		cancel()
+		for range batches {
+		}
+		bc.Close(ctx)
+		return copied, err
+	}
+	if err := <-readErr; err != nil {
+		bc.Close(ctx)
+		return copied, fmt.Errorf("copy source: %w", err)
+	}
+	return copied, bc.Close(ctx)
+}
+
+// write adds the batches to the bulk copy, committing each one, and returns
+// the number of rows committed
+func (cp *copier) write(ctx context.Context, bc *BulkCopy, batches <-chan [][]driver.Value) (int64, error) {
+	start := time.Now()
+	values := make([]interface{}, len(bc.Columns()))
+	n := 0
+	for batch := range batches {
+		for _, row := range batch {
+			for i, v := range row {
+				values[i] = v
+			}
+			if err := bc.AddRow(ctx, values...); err != nil {
+				return bc.RowsCopied(), err
+			}
+		}
+		if err := bc.Flush(ctx); err != nil {
+			return bc.RowsCopied(), err
+		}
+		n++
+		rows := cp.resume + bc.RowsCopied()
+		if cp.checkpoint != nil {
+			if err := cp.checkpoint(rows); err != nil {
+				return bc.RowsCopied(), fmt.Errorf("copy checkpoint: %w", err)
+			}
+		}
+		if cp.progress != nil {
+			cp.progress(CopyProgress{Rows: rows, Batches: n, Elapsed: time.Since(start)})
+		}
+	}
+	return bc.RowsCopied(), nil
+}
+
+// readBatches reads the rows of src into batches of up to size rows, sending
+// each one when it is full and the last one at the end of the rows
+func readBatches(ctx context.Context, src driver.Rows, numCols, size int, batches chan<- [][]driver.Value) error {
+	for {
+		batch := make([][]driver.Value, 0, size)
+		var err error
+		for len(batch) < size {
+			row := make([]driver.Value, numCols)
+			if err = src.Next(row); err != nil {
+				break
+			}
+			batch = append(batch, row)
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(batch) > 0 {
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// skipRows reads and discards the first n rows of src
+func skipRows(ctx context.Context, src driver.Rows, numCols int, n int64) error {
+	row := make([]driver.Value, numCols)
+	for i := int64(0); i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := src.Next(row); err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("copy: cannot resume at row %d, the source has %d rows", n, i)
+			}
+			return fmt.Errorf("copy source: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package godbc
+
+import (
+	"bytes"
+	"database/sql/driver"
+)
+
+// copyMode returns the connection's copy mode
+func (r *Rows) copyMode() CopyMode {
+	if r.stmt == nil || r.stmt.conn == nil {
+		return CopyAlways
+	}
+	return r.stmt.conn.copyMode
+}
+
+// ownValues replaces the []byte values of dest with copies under CopyAlways,
+// so values handed out do not share memory with the kept row, which borrows
+// driver buffers (see readRow), and the caller can modify them without
+// changing a later read of the row
+func (r *Rows) ownValues(dest []driver.Value) {
+	if r.copyMode() != CopyAlways {
+		return
+	}
+	for i, v := range dest {
+		if b, ok := v.([]byte); ok {
+			dest[i] = bytes.Clone(b)
+		}
+	}
+}
+
+// borrowsBuffers reports whether column values may share memory with driver
+// buffers: under CopyBorrowUntilNext, and while readRow reads the row it keeps,
+// whose values ownValues copies under CopyAlways
+func (r *Rows) borrowsBuffers() bool {
+	return r.readingRow || r.copyMode() == CopyBorrowUntilNext
+}
+
+// borrowBytes returns data, which belongs to a driver buffer, as a column
+// value: data itself if values borrow driver buffers, capped so appending to
+// it cannot overwrite the rows after it, or a copy otherwise
+func (r *Rows) borrowBytes(data []byte) []byte {
+	if r.borrowsBuffers() {
+		return data[:len(data):len(data)]
+	}
+	return append([]byte{}, data...)
+}
+
+// byteBuffer returns a SQLGetData buffer of n bytes for a binary column: the
+// column's buffer reused from row to row if values borrow driver buffers, a
+// new one otherwise. The buffer is capped at n bytes, so appending to a value
+// read into it cannot overwrite it.
+func (r *Rows) byteBuffer(colNum SQLUSMALLINT, n int) []byte {
+	if !r.borrowsBuffers() {
+		return make([]byte, n)
+	}
+	idx := int(colNum) - 1
+	if idx >= len(r.byteBufs) {
+		r.byteBufs = append(r.byteBufs, make([][]byte, idx+1-len(r.byteBufs))...)
+	}
+	if cap(r.byteBufs[idx]) < n {
+		r.byteBufs[idx] = make([]byte, n)
+	}
+	return r.byteBufs[idx][:n:n]
+}
@@ -0,0 +1,73 @@
+package godbc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// correlationIDKey is the context key for query correlation IDs
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a context carrying a correlation ID (such as a
+// request or trace ID) for the queries executed with it. When the connector
+// enables WithCorrelationComments, the ID is prepended to each statement as a
+// SQL comment, so it shows up in driver manager traces and in database-side
+// monitoring (pg_stat_activity, sys.dm_exec_requests, slow query logs) and can
+// be joined with distributed traces.
+//
+// Example:
+//
+//	ctx = godbc.WithCorrelationID(ctx, span.SpanContext().TraceID().String())
+//	rows, err := db.QueryContext(ctx, "SELECT * FROM orders")
+//	// executed as: /* correlation_id=4bf92f3577b34da6a3ce929d0e0e4736 */ SELECT * FROM orders
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID stored in ctx, or "" if there is none
+func CorrelationID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// logAttrs appends the correlation ID stored in ctx, if any, to the
+// attributes of a log record
+func logAttrs(ctx context.Context, attrs ...slog.Attr) []slog.Attr {
+	if id := CorrelationID(ctx); id != "" {
+		attrs = append(attrs, slog.String("correlation_id", id))
+	}
+	return attrs
+}
+
+// correlationComment formats id as a SQL comment prefix. Characters that could
+// end the comment or break the statement are replaced, so the ID cannot inject SQL.
+func correlationComment(id string) string {
+	id = strings.Map(func(r rune) rune {
+		switch {
+		case r == '*' || r == '/' || r == '\\' || r == '\'' || r == '"' || r == '?':
+			return '_'
+		case r < 0x20 || r == 0x7f:
+			return '_'
+		}
+		return r
+	}, id)
+	return "/* correlation_id=" + id + " */ "
+}
+
+// tagQuery prefixes query with the context's correlation ID comment when
+// correlation comments are enabled. ODBC escape sequences such as
+// {call proc(?)} are left untouched, since drivers expect them at the start.
+func (c *Conn) tagQuery(ctx context.Context, query string) string {
+	if !c.correlationComments {
+		return query
+	}
+	id := CorrelationID(ctx)
+	if id == "" || strings.HasPrefix(strings.TrimSpace(query), "{") {
+		return query
+	}
+	return correlationComment(id) + query
+}
@@ -0,0 +1,270 @@
+package godbc
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// maxRecentDiagnostics is the number of diagnostic events kept for DebugDump
+const maxRecentDiagnostics = 20
+
+// diagnosticEvent is a set of diagnostic records returned by the driver for one failed call
+type diagnosticEvent struct {
+	time    time.Time
+	records []DiagRecord
+}
+
+// diagnosticLog keeps the most recent diagnostic events, oldest first
+type diagnosticLog struct {
+	mu     sync.Mutex
+	events []diagnosticEvent
+}
+
+// add records a diagnostic event, discarding the oldest beyond maxRecentDiagnostics
+func (l *diagnosticLog) add(records []DiagRecord) {
+	if len(records) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, diagnosticEvent{time: time.Now(), records: records})
+	if n := len(l.events) - maxRecentDiagnostics; n > 0 {
+		l.events = append(l.events[:0:0], l.events[n:]...)
+	}
+}
+
+// snapshot returns a copy of the recorded events
+func (l *diagnosticLog) snapshot() []diagnosticEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]diagnosticEvent(nil), l.events...)
+}
+
+// debugEntry is a name/value line of a DebugDump section
+type debugEntry struct {
+	name  string
+	value string
+}
+
+// debugInfoTypes are the SQLGetInfo string values included in DebugDump
+var debugInfoTypes = []struct {
+	name     string
+	infoType SQLUSMALLINT
+}{
+	{"SQL_DRIVER_NAME", SQL_DRIVER_NAME},
+	{"SQL_DRIVER_VER", SQL_DRIVER_VER},
+	{"SQL_DRIVER_ODBC_VER", SQL_DRIVER_ODBC_VER},
+	{"SQL_DBMS_NAME", SQL_DBMS_NAME},
+	{"SQL_DBMS_VER", SQL_DBMS_VER},
+	{"SQL_SERVER_NAME", SQL_SERVER_NAME},
+	{"SQL_DATABASE_NAME", SQL_DATABASE_NAME},
+	{"SQL_IDENTIFIER_QUOTE_CHAR", SQL_IDENTIFIER_QUOTE_CHAR},
+}
+
+// debugConnAttrs are the integer connection attributes included in DebugDump
+var debugConnAttrs = []struct {
+	name string
+	attr SQLINTEGER
+}{
+	{"SQL_ATTR_AUTOCOMMIT", SQL_ATTR_AUTOCOMMIT},
+	{"SQL_ATTR_ACCESS_MODE", SQL_ATTR_ACCESS_MODE},
+	{"SQL_ATTR_TXN_ISOLATION", SQL_ATTR_TXN_ISOLATION},
+	{"SQL_ATTR_LOGIN_TIMEOUT", SQL_ATTR_LOGIN_TIMEOUT},
+	{"SQL_ATTR_CONNECTION_TIMEOUT", SQL_ATTR_CONNECTION_TIMEOUT},
+	{"SQL_ATTR_PACKET_SIZE", SQL_ATTR_PACKET_SIZE},
+}
+
+// DebugDump writes a plain-text report of the connection for support bundles:
+// the loaded library and driver manager, the redacted connection string, the
+// effective connection attributes and driver options, SQLGetInfo values,
+// detected capabilities, and the most recent driver diagnostics (across all
+// connections). Attach its output when reporting driver-specific issues.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    return dc.(*godbc.Conn).DebugDump(os.Stderr)
+//	})
+func (c *Conn) DebugDump(w io.Writer) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return driver.ErrBadConn
+	}
+	var info, attrs []debugEntry
+	for _, it := range debugInfoTypes {
+		value, ok := c.getInfoString(it.infoType)
+		if !ok {
+			value = "(unavailable)"
+		}
+		info = append(info, debugEntry{it.name, value})
+	}
+	for _, a := range debugConnAttrs {
+		value := "(unavailable)"
+		if v, ok := getConnectAttrInt(c.dbc, a.attr); ok {
+			value = fmt.Sprint(v)
+		}
+		attrs = append(attrs, debugEntry{a.name, value})
+	}
+	if catalog, ok := getConnectAttrString(c.dbc, SQL_ATTR_CURRENT_CATALOG); ok {
+		attrs = append(attrs, debugEntry{"SQL_ATTR_CURRENT_CATALOG", catalog})
+	}
+	trace := "off"
+	if c.trace != nil {
+		trace = c.trace.opts.Path
+	}
+	keepAlive := "off"
+	if c.pinger != nil {
+		keepAlive = c.pinger.interval.String()
+	}
+	retryer := "off"
+	if c.retryer != nil {
+		retryer = fmt.Sprintf("%d attempts, %s backoff", c.retryer.MaxAttempts, c.retryer.Backoff)
+	}
+	stmtCache := "off"
+	if c.stmtCache != nil {
+		stmtCache = fmt.Sprintf("%d/%d", c.stmtCache.len(), c.stmtCache.size)
+	}
+	c.mu.Unlock()
+
+	lib := Library()
+	diag := diagLimits()
+	caps := c.Capabilities()
+	sections := []struct {
+		title   string
+		entries []debugEntry
+	}{
+		{"Library", []debugEntry{
+			{"Path", lib.Path},
+			{"DriverManager", lib.DriverManager.String()},
+			{"ODBCVersion", fmt.Sprint(lib.ODBCVersion)},
+			{"WCharSize", fmt.Sprint(lib.WCharSize)},
+			{"SQLLENSize", fmt.Sprint(sqlLenSize)},
+			{"WideAPI", fmt.Sprint(wideAPI)},
+			{"DiagLimits", fmt.Sprintf("%d records, %d bytes", diag.MaxRecords, diag.MaxMessageLen)},
+		}},
+		{"Connection", []debugEntry{
+			{"ConnectedDSN", c.connectedDSN},
+			{"DBType", c.dbType},
+			{"InTransaction", fmt.Sprint(c.inTx)},
+			{"Trace", trace},
+		}},
+		{"Connection Attributes", attrs},
+		{"Options", []debugEntry{
+			{"LastInsertIdBehavior", fmt.Sprint(c.lastInsertIdBehavior)},
+			{"UnknownColumnSize", fmt.Sprint(c.unknownColumnSize)},
+			{"TimestampFetchMode", fmt.Sprint(c.timestampFetchMode)},
+			{"GUIDFetchMode", fmt.Sprint(c.guidFetchMode)},
+			{"DecimalFetchMode", fmt.Sprint(c.decimalFetchMode)},
+			{"OutOfRangeTime", fmt.Sprint(c.outOfRangeTime)},
+			{"BoolRules", fmt.Sprint(len(c.boolRules))},
+			{"MaxColumnBytes", fmt.Sprint(c.maxColumnBytes)},
+			{"TruncationPolicy", fmt.Sprint(c.truncationPolicy)},
+			{"CopyMode", fmt.Sprint(c.copyMode)},
+			{"TimeBindMode", fmt.Sprint(c.timeBindMode)},
+			{"TimeLayout", c.timeLayout},
+			{"InvalidText", fmt.Sprint(c.invalidText)},
+			{"AccessMode", fmt.Sprint(c.accessMode)},
+			{"Catalog", c.catalog},
+			{"ResetQuery", c.resetQuery},
+			{"PingQuery", c.pingQueryFor()},
+			{"IdentifierCasePolicy", fmt.Sprint(c.identifierCasePolicy)},
+			{"Unicode", fmt.Sprint(c.unicode)},
+			{"WideFetch", fmt.Sprint(c.wideFetch)},
+			{"QueryTimeout", c.queryTimeout.String()},
+			{"MultiRowInsert", fmt.Sprint(c.multiRowInsert)},
+			{"BatchErrorValues", fmt.Sprint(c.batchErrorValues != nil)},
+			{"RowArraySize", fmt.Sprint(c.rowArraySize)},
+			{"Prefetch", fmt.Sprint(c.prefetch)},
+			{"CorrelationComments", fmt.Sprint(c.correlationComments)},
+			{"StmtCache", stmtCache},
+			{"KeepAlive", keepAlive},
+			{"Retryer", retryer},
+			{"Metrics", fmt.Sprint(c.metrics != nil)},
+			{"Tracer", fmt.Sprint(c.tracer != nil)},
+			{"Logger", fmt.Sprint(c.logger != nil)},
+		}},
+		{"GetInfo", info},
+		{"Capabilities", []debugEntry{
+			{"DBMSName", caps.DBMSName},
+			{"MaxParams", fmt.Sprint(caps.MaxParams)},
+			{"DefaultIsolation", caps.DefaultIsolation.String()},
+			{"IsolationLevels", fmt.Sprint(caps.IsolationLevels)},
+			{"MaxConcurrentActivities", fmt.Sprint(caps.MaxConcurrentActivities)},
+			{"GetDataExtensions", fmt.Sprintf("%#x", caps.GetDataExtensions)},
+			{"IdentifierCase", fmt.Sprint(c.identifierCase)},
+			{"IdentifierQuote", c.identifierQuote},
+		}},
+		{"Recent Diagnostics", diagnosticEntries(c.diagnostics.snapshot())},
+	}
+
+	var sb strings.Builder
+	sb.WriteString("godbc debug dump\n")
+	for _, s := range sections {
+		writeDebugSection(&sb, s.title, s.entries)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeDebugSection formats a titled section with aligned name/value lines
+func writeDebugSection(sb *strings.Builder, title string, entries []debugEntry) {
+	fmt.Fprintf(sb, "\n== %s ==\n", title)
+	if len(entries) == 0 {
+		sb.WriteString("(none)\n")
+		return
+	}
+	width := 0
+	for _, e := range entries {
+		if len(e.name) > width {
+			width = len(e.name)
+		}
+	}
+	for _, e := range entries {
+		fmt.Fprintf(sb, "%-*s  %s\n", width, e.name, e.value)
+	}
+}
+
+// diagnosticEntries formats diagnostic events as DebugDump entries, one per record
+func diagnosticEntries(events []diagnosticEvent) []debugEntry {
+	var entries []debugEntry
+	for _, ev := range events {
+		stamp := ev.time.UTC().Format(time.RFC3339Nano)
+		for _, rec := range ev.records {
+			entries = append(entries, debugEntry{
+				name:  stamp,
+				value: fmt.Sprintf("[%s] %s (native error: %d)", rec.SQLState, rec.Message, rec.NativeError),
+			})
+		}
+	}
+	return entries
+}
+
+// getConnectAttrInt reads an integer connection attribute
+func getConnectAttrInt(dbc SQLHDBC, attr SQLINTEGER) (uint64, bool) {
+	// Some drivers write SQLULEN-sized values, so read into the wider type
+	var value SQLULEN
+	if !IsSuccess(GetConnectAttr(dbc, attr, uintptr(unsafe.Pointer(&value)), 0, nil)) {
+		return 0, false
+	}
+	return uint64(value), true
+}
+
+// getConnectAttrString reads a string connection attribute
+func getConnectAttrString(dbc SQLHDBC, attr SQLINTEGER) (string, bool) {
+	buf := make([]byte, 256)
+	var strLen SQLINTEGER
+	if !IsSuccess(GetConnectAttr(dbc, attr, uintptr(unsafe.Pointer(&buf[0])), SQLINTEGER(len(buf)), &strLen)) {
+		return "", false
+	}
+	end := int(strLen)
+	if end < 0 || end > len(buf) {
+		end = len(buf)
+	}
+	return strings.TrimRight(string(buf[:end]), "\x00"), true
+}
@@ -0,0 +1,91 @@
+package godbc
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// String returns the decimal value as text
+func (d Decimal) String() string {
+	return d.Value
+}
+
+// Rat returns the decimal value as an exact rational number
+func (d Decimal) Rat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(d.Value))
+	if !ok {
+		return nil, fmt.Errorf("decimal: invalid value %q", d.Value)
+	}
+	return r, nil
+}
+
+// decimalFromNumeric converts a SQL_NUMERIC_STRUCT to a Decimal. Val holds
+// the unscaled magnitude as a little-endian 128-bit integer.
+func decimalFromNumeric(ns SQL_NUMERIC_STRUCT) Decimal {
+	be := make([]byte, len(ns.Val))
+	for i, b := range ns.Val {
+		be[len(be)-1-i] = byte(b)
+	}
+	unscaled := new(big.Int).SetBytes(be)
+	if ns.Sign == 0 {
+		unscaled.Neg(unscaled)
+	}
+
+	scale := int(ns.Scale)
+	r := new(big.Rat).SetInt(unscaled)
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(scale))), nil)
+	if scale >= 0 {
+		r.Quo(r, new(big.Rat).SetInt(pow))
+	} else {
+		r.Mul(r, new(big.Rat).SetInt(pow))
+		scale = 0
+	}
+	return normalizedDecimal(r, int(ns.Precision), scale)
+}
+
+// decimalFromText converts the text a driver returns for a NUMERIC/DECIMAL
+// value to a Decimal with a consistent format: ".01", "+0.010" and "0.01"
+// with scale 2 all become "0.01". Significant digits beyond scale are kept;
+// a negative scale means the column scale is unknown.
+func decimalFromText(s string, precision, scale int) (Decimal, bool) {
+	s = strings.TrimSpace(s)
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, false
+	}
+	if frac := fractionDigits(s); frac > scale {
+		scale = frac
+	}
+	return normalizedDecimal(r, precision, scale), true
+}
+
+// normalizedDecimal formats r with scale fractional digits. A precision of 0
+// or less is replaced by the number of digits the value needs.
+func normalizedDecimal(r *big.Rat, precision, scale int) Decimal {
+	value := r.FloatString(scale)
+	if precision <= 0 {
+		intPart := strings.TrimLeft(value, "-")
+		if i := strings.IndexByte(intPart, '.'); i >= 0 {
+			intPart = intPart[:i]
+		}
+		precision = len(strings.TrimLeft(intPart, "0")) + scale
+		if precision < 1 {
+			precision = 1
+		}
+	}
+	return Decimal{Value: value, Precision: precision, Scale: scale}
+}
+
+// fractionDigits returns the number of significant fractional digits in a
+// decimal string, ignoring trailing zeros and any exponent
+func fractionDigits(s string) int {
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		s = s[:i]
+	}
+	i := strings.IndexByte(s, '.')
+	if i < 0 {
+		return 0
+	}
+	return len(strings.TrimRight(s[i+1:], "0"))
+}
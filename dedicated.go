@@ -0,0 +1,56 @@
+package godbc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// WithDedicatedConn runs fn on one physical connection taken from db, for work
+// that depends on session state across several statements, such as SET
+// IDENTITY_INSERT, temporary tables or session-scoped bulk settings. Every
+// statement fn runs through c uses the same connection, and no other caller
+// can use it until fn returns. The connection is then closed instead of being
+// returned to the pool, so session state left behind never reaches later
+// queries.
+//
+// Example:
+//
+//	err := godbc.WithDedicatedConn(ctx, db, func(c *godbc.Conn) error {
+//	    if _, err := c.ExecContext(ctx, "SET IDENTITY_INSERT dbo.users ON", nil); err != nil {
+//	        return err
+//	    }
+//	    _, err := c.ExecContext(ctx, "INSERT INTO dbo.users (id, name) VALUES (?, ?)",
+//	        []driver.NamedValue{{Ordinal: 1, Value: 42}, {Ordinal: 2, Value: "alice"}})
+//	    return err
+//	})
+func WithDedicatedConn(ctx context.Context, db *sql.DB, fn func(c *Conn) error) error {
+	return withConn(ctx, db, func(c *Conn) error {
+		defer c.discard()
+		return fn(c)
+	})
+}
+
+// withConn runs fn on a godbc connection from the pool
+func withConn(ctx context.Context, db *sql.DB, fn func(c *Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(dc interface{}) error {
+		c, ok := dc.(*Conn)
+		if !ok {
+			return fmt.Errorf("connection is a %T, not a godbc connection", dc)
+		}
+		return fn(c)
+	})
+}
+
+// discard marks the connection so database/sql closes it instead of returning
+// it to the pool
+func (c *Conn) discard() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dead = true
+}
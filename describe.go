@@ -0,0 +1,254 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"runtime"
+	"strings"
+)
+
+// StmtDescription is the result and parameter metadata of a prepared statement
+type StmtDescription struct {
+	// Columns describes the result set, empty for statements that return no rows.
+	// Remarks, Default and identity fields are not reported for result columns.
+	Columns []ColumnInfo
+
+	// Params describes the parameter markers in order, or is nil if the driver
+	// cannot describe parameters
+	Params []ParamInfo
+
+	// Executed reports that the driver could not describe the result of the
+	// unexecuted statement, so it was executed once to read the columns
+	Executed bool
+}
+
+// ParamInfo describes a parameter marker as reported by SQLDescribeParam
+type ParamInfo struct {
+	DataType      SQLSMALLINT // SQL data type (SQL_VARCHAR, SQL_INTEGER, ...)
+	ColumnSize    int64
+	DecimalDigits int64
+	Nullable      bool
+}
+
+// Describe returns the result columns and parameter metadata of the prepared
+// statement without executing it, so tools can validate queries and build
+// schemas cheaply. If the driver cannot describe the result of an unexecuted
+// statement, SELECT and VALUES statements are executed once with every
+// parameter NULL and SQL_ATTR_MAX_ROWS limiting the result, and the cursor is
+// closed without fetching. Other statements are never executed.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    stmt, err := dc.(*godbc.Conn).PrepareContext(ctx, "SELECT id, name FROM users WHERE id = ?")
+//	    if err != nil {
+//	        return err
+//	    }
+//	    defer stmt.Close()
+//	    desc, err := stmt.(*godbc.Stmt).Describe(ctx)
+//	    ...
+//	})
+func (s *Stmt) Describe(ctx context.Context) (*StmtDescription, error) {
+	op, err := lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer op.end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrStmtClosed
+	}
+	if s.conn.isClosed() {
+		return nil, driver.ErrBadConn
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	desc := &StmtDescription{Params: s.describeParams(ctx)}
+
+	var numCols SQLSMALLINT
+	ret := NumResultCols(s.stmt, &numCols)
+	if (!IsSuccess(ret) || numCols == 0) && isRowQuery(s.query) {
+		desc.Columns, err = s.describeByExecuting(ctx, desc.Params)
+		desc.Executed = err == nil
+		return desc, err
+	}
+	if !IsSuccess(ret) {
+		return nil, s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	desc.Columns, err = s.describeColumns(int(numCols))
+	return desc, err
+}
+
+// describeParams describes the parameter markers, returning nil if the driver
+// cannot. A driver that supports automatic IPD population but does not
+// describe parameters by default gets a second try with SQL_ATTR_ENABLE_AUTO_IPD
+// turned on, which takes effect when the statement is prepared again.
+func (s *Stmt) describeParams(ctx context.Context) []ParamInfo {
+	params, ok := s.describeParamMarkers()
+	if ok {
+		return params
+	}
+	if v, supported := getConnectAttrInt(s.conn.dbc, SQL_ATTR_AUTO_IPD); !supported || v != SQL_TRUE {
+		return nil
+	}
+	if !IsSuccess(SetStmtAttr(s.stmt, SQL_ATTR_ENABLE_AUTO_IPD, SQL_TRUE, 0)) {
+		return nil
+	}
+	s.resultCols = nil
+	if !IsSuccess(s.conn.prepare(s.stmt, s.conn.tagQuery(ctx, s.preparedQuery()))) {
+		return nil
+	}
+	params, _ = s.describeParamMarkers()
+	return params
+}
+
+// describeParamMarkers calls SQLDescribeParam for each parameter marker
+func (s *Stmt) describeParamMarkers() ([]ParamInfo, bool) {
+	var numParams SQLSMALLINT
+	if !IsSuccess(NumParams(s.stmt, &numParams)) {
+		return nil, false
+	}
+	params := make([]ParamInfo, numParams)
+	for i := range params {
+		dataType, size, digits, nullable, ret := DescribeParam(s.stmt, SQLUSMALLINT(i+1))
+		if !IsSuccess(ret) {
+			return nil, false
+		}
+		params[i] = ParamInfo{
+			DataType:      dataType,
+			ColumnSize:    int64(size),
+			DecimalDigits: int64(digits),
+			Nullable:      nullable == SQL_NULLABLE,
+		}
+	}
+	return params, true
+}
+
+// describeColumns describes the columns of the statement's result set
+func (s *Stmt) describeColumns(numCols int) ([]ColumnInfo, error) {
+	columns := make([]ColumnInfo, numCols)
+	colName := make([]byte, 256)
+	typeName := make([]byte, 256)
+	for i := range columns {
+		colNum := SQLUSMALLINT(i + 1)
+		name, dataType, colSize, digits, nullable, ret := s.conn.describeCol(s.stmt, colNum, colName)
+		if !IsSuccess(ret) {
+			return nil, s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+		}
+		col := ColumnInfo{
+			Name:          name,
+			Ordinal:       i + 1,
+			DataType:      dataType,
+			ColumnSize:    int64(colSize),
+			DecimalDigits: int64(digits),
+			Nullable:      nullable == SQL_NULLABLE,
+		}
+		if name, ret := s.conn.colAttributeString(s.stmt, colNum, SQL_DESC_TYPE_NAME, typeName); IsSuccess(ret) {
+			col.TypeName = name
+		}
+		if _, autoIncrement, ret := ColAttribute(s.stmt, colNum, SQL_DESC_AUTO_UNIQUE_VALUE, nil); IsSuccess(ret) {
+			col.AutoIncrement = autoIncrement == SQL_TRUE
+		}
+		columns[i] = col
+	}
+	return columns, nil
+}
+
+// describeByExecuting executes a row-returning statement with NULL parameters
+// and at most one row to read its result columns. ODBC treats a
+// SQL_ATTR_MAX_ROWS of 0 as unlimited, so the limit is 1; no row is fetched.
+func (s *Stmt) describeByExecuting(ctx context.Context, params []ParamInfo) ([]ColumnInfo, error) {
+	numParams := s.numInput
+	if numParams < 0 {
+		numParams = len(params)
+	}
+	indicators := make([]SQLLEN, numParams)
+	for i := range indicators {
+		sqlType := SQL_VARCHAR
+		if i < len(params) && params[i].DataType != 0 {
+			sqlType = params[i].DataType
+		}
+		indicators[i] = SQL_NULL_DATA
+		ret := BindParameter(s.stmt, SQLUSMALLINT(i+1), SQL_PARAM_INPUT, SQL_C_CHAR, sqlType, 1, 0, 0, 0, &indicators[i])
+		if !IsSuccess(ret) {
+			s.resetParams()
+			return nil, s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+		}
+	}
+	defer s.resetParams()
+
+	SetStmtAttr(s.stmt, SQL_ATTR_MAX_ROWS, 1, 0)
+	defer SetStmtAttr(s.stmt, SQL_ATTR_MAX_ROWS, 0, 0)
+
+	if ctx.Done() != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				Cancel(s.stmt)
+			case <-done:
+			}
+		}()
+	}
+
+	ret := Execute(s.stmt)
+	runtime.KeepAlive(indicators)
+	if !IsSuccess(ret) && ret != SQL_NO_DATA {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	defer CloseCursor(s.stmt)
+
+	var numCols SQLSMALLINT
+	if !IsSuccess(NumResultCols(s.stmt, &numCols)) {
+		return nil, s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	return s.describeColumns(int(numCols))
+}
+
+// isRowQuery reports whether a statement is a plain SELECT or VALUES query,
+// the only statements Describe executes. SELECT ... INTO creates a table on
+// some databases, and a batch may run other statements after the query, so
+// both are excluded.
+func isRowQuery(query string) bool {
+	q := strings.TrimLeft(query, " \t\r\n(")
+	word := q
+	if i := strings.IndexFunc(q, func(r rune) bool { return !isIdentChar(byte(r)) || r > 0x7f }); i >= 0 {
+		word = q[:i]
+	}
+	switch strings.ToUpper(word) {
+	case "SELECT", "VALUES":
+		return isSingleQuery(q)
+	}
+	return false
+}
+
+// isSingleQuery reports whether query, outside quoted text, has no INTO
+// keyword and no ';' followed by another statement
+func isSingleQuery(query string) bool {
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(query, i)
+		case c == ';':
+			if strings.TrimSpace(query[i+1:]) != "" {
+				return false
+			}
+		case (c == 'I' || c == 'i') && (i == 0 || !isIdentChar(query[i-1])):
+			end := i + len("INTO")
+			if end <= len(query) && strings.EqualFold(query[i:end], "INTO") && (end == len(query) || !isIdentChar(query[end])) {
+				return false
+			}
+		}
+	}
+	return true
+}
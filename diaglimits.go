@@ -0,0 +1,154 @@
+package godbc
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"unsafe"
+)
+
+// Default diagnostic limits (see SetDiagLimits)
+const (
+	DefaultMaxDiagRecords    = 32
+	DefaultMaxDiagMessageLen = 1024
+)
+
+// DiagLimits bounds the diagnostic records read for each error and warning.
+// Some drivers report thousands of repeated warnings for one statement, which
+// would otherwise all be read and kept in the error.
+type DiagLimits struct {
+	// MaxRecords is the number of records read per handle (0 = DefaultMaxDiagRecords)
+	MaxRecords int
+
+	// MaxMessageLen is the number of message bytes kept per record
+	// (0 = DefaultMaxDiagMessageLen)
+	MaxMessageLen int
+}
+
+var (
+	diagLimitsMu      sync.RWMutex
+	currentDiagLimits = DiagLimits{MaxRecords: DefaultMaxDiagRecords, MaxMessageLen: DefaultMaxDiagMessageLen}
+)
+
+// SetDiagLimits sets the diagnostic limits for all connections in the
+// process, since errors are read from handles without regard to the
+// connection they belong to. Zero fields select the defaults.
+//
+// Example:
+//
+//	godbc.SetDiagLimits(godbc.DiagLimits{MaxRecords: 5, MaxMessageLen: 512})
+func SetDiagLimits(limits DiagLimits) {
+	if limits.MaxRecords <= 0 {
+		limits.MaxRecords = DefaultMaxDiagRecords
+	}
+	if limits.MaxMessageLen <= 0 {
+		limits.MaxMessageLen = DefaultMaxDiagMessageLen
+	}
+	// Record numbers and message buffer lengths are SQLSMALLINTs
+	limits.MaxRecords = min(limits.MaxRecords, math.MaxInt16-1)
+	limits.MaxMessageLen = min(limits.MaxMessageLen, math.MaxInt16-1)
+	diagLimitsMu.Lock()
+	defer diagLimitsMu.Unlock()
+	currentDiagLimits = limits
+}
+
+// diagLimits returns the current diagnostic limits
+func diagLimits() DiagLimits {
+	diagLimitsMu.RLock()
+	defer diagLimitsMu.RUnlock()
+	return currentDiagLimits
+}
+
+// diagBuffer holds the SQLGetDiagRec output buffers, reused across calls
+type diagBuffer struct {
+	sqlState  [6]byte
+	wideState [6 * 4]byte
+	msg       []byte
+}
+
+// diagBuffers pools diagBuffers so reading diagnostics does not allocate
+// message buffers per record
+var diagBuffers = sync.Pool{New: func() any { return new(diagBuffer) }}
+
+// message returns a buffer for messages of up to maxLen bytes and the
+// terminating NUL
+func (b *diagBuffer) message(maxLen int) []byte {
+	if cap(b.msg) < maxLen+1 {
+		b.msg = make([]byte, maxLen+1)
+	}
+	return b.msg[:maxLen+1]
+}
+
+// read reads diagnostic record recNum with SQLGetDiagRec, keeping up to
+// maxLen bytes of the message
+func (b *diagBuffer) read(handleType SQLSMALLINT, handle SQLHANDLE, recNum SQLSMALLINT, maxLen int) (DiagRecord, SQLRETURN) {
+	message := b.message(maxLen)
+	nativeError, msgLen, ret := GetDiagRec(handleType, handle, recNum, b.sqlState[:], message)
+	if !IsSuccess(ret) {
+		return DiagRecord{}, ret
+	}
+	return DiagRecord{
+		SQLState:    string(b.sqlState[:5]),
+		NativeError: int32(nativeError),
+		Message:     diagMessage(message, int(msgLen)),
+	}, ret
+}
+
+// readWide reads diagnostic record recNum with SQLGetDiagRecW. The message
+// is read in up to maxLen characters and cut to maxLen bytes of UTF-8 as in read.
+func (b *diagBuffer) readWide(handleType SQLSMALLINT, handle SQLHANDLE, recNum SQLSMALLINT, maxLen int) (DiagRecord, SQLRETURN) {
+	size := wcharSize()
+	message := b.message((maxLen+1)*size - 1)
+	state := b.wideState[:6*size]
+	nativeError, msgLen, ret := GetDiagRecW(handleType, handle, recNum, state, message)
+	if !IsSuccess(ret) {
+		return DiagRecord{}, ret
+	}
+	text := wideBufferString(message, int(msgLen))
+	if int(msgLen) > maxLen || len(text) > maxLen {
+		text = text[:min(len(text), maxLen)]
+		text = text[:len(text)-incompleteRuneSuffix([]byte(text))] + "..."
+	}
+	return DiagRecord{
+		SQLState:    wideBufferString(state, 5),
+		NativeError: int32(nativeError),
+		Message:     text,
+	}, ret
+}
+
+// diagMessage returns the message SQLGetDiagRec wrote to buf. msgLen is the
+// full length of the message, which is cut at a character boundary and
+// marked with "..." when it did not fit.
+func diagMessage(buf []byte, msgLen int) string {
+	if msgLen < 0 {
+		msgLen = 0
+	}
+	if msgLen < len(buf) {
+		return string(buf[:msgLen])
+	}
+	kept := buf[:len(buf)-1]
+	kept = kept[:len(kept)-incompleteRuneSuffix(kept)]
+	return string(kept) + "..."
+}
+
+// omittedDiagRecords describes the records beyond the first read ones, or
+// returns "" if there are none. The count comes from SQL_DIAG_NUMBER; if the
+// driver does not report it, the next record is probed instead.
+func omittedDiagRecords(handleType SQLSMALLINT, handle SQLHANDLE, read int) string {
+	if read == 0 {
+		return ""
+	}
+	var total SQLINTEGER
+	if IsSuccess(GetDiagField(handleType, handle, 0, SQL_DIAG_NUMBER, uintptr(unsafe.Pointer(&total)), 0, nil)) {
+		if n := int(total) - read; n > 0 {
+			return fmt.Sprintf("(and %d more)", n)
+		}
+		return ""
+	}
+	var sqlState [6]byte
+	var message [1]byte
+	if _, _, ret := GetDiagRec(handleType, handle, SQLSMALLINT(read+1), sqlState[:], message[:]); ret != SQL_NO_DATA && ret != SQL_ERROR && ret != SQL_INVALID_HANDLE {
+		return "(and more)"
+	}
+	return ""
+}
@@ -26,17 +26,30 @@ func (d *Driver) Open(name string) (driver.Conn, error) {
 }
 
 // OpenConnector returns a new Connector for the given connection string
-// This implements driver.DriverContext for connection pooling efficiency
+// This implements driver.DriverContext for connection pooling efficiency.
+// godbc_* attributes set connector options, e.g.
+// godbc_query_timeout=30s;godbc_rowset_size=1000, and are not passed to the driver.
 func (d *Driver) OpenConnector(name string) (driver.Connector, error) {
+	dsnOpts, dsn, err := extractDSNOptions(name)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize ODBC library if not already done
 	if err := initODBC(); err != nil {
 		return nil, err
 	}
-	return &Connector{dsn: name, driver: d}, nil
+	c := &Connector{dsn: dsn, driver: d}
+	for _, opt := range dsnOpts {
+		opt(c)
+	}
+	return c, nil
 }
 
 // OpenConnectorWithOptions returns a Connector with custom options for enhanced type handling.
 // Use this when you need to configure timezone, timestamp precision, or other options.
+// Options set with godbc_* attributes in the connection string are applied
+// first, so opts take precedence over them.
 //
 // Example:
 //
@@ -47,15 +60,19 @@ func (d *Driver) OpenConnector(name string) (driver.Connector, error) {
 //	    odbc.WithTimestampPrecision(odbc.TimestampPrecisionMicroseconds),
 //	)
 func (d *Driver) OpenConnectorWithOptions(name string, opts ...ConnectorOption) (*Connector, error) {
+	dsnOpts, dsn, err := extractDSNOptions(name)
+	if err != nil {
+		return nil, err
+	}
 	if err := initODBC(); err != nil {
 		return nil, err
 	}
 	c := &Connector{
-		dsn:                       name,
+		dsn:                       dsn,
 		driver:                    d,
 		DefaultTimestampPrecision: TimestampPrecisionMilliseconds, // Default
 	}
-	for _, opt := range opts {
+	for _, opt := range append(dsnOpts, opts...) {
 		opt(c)
 	}
 	return c, nil
@@ -0,0 +1,152 @@
+package godbc
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+// DriverManager identifies the ODBC driver manager implementation that was loaded
+type DriverManager int
+
+const (
+	// DriverManagerUnknown is a library that could not be identified, such as a
+	// driver loaded directly without a driver manager
+	DriverManagerUnknown DriverManager = iota
+
+	// DriverManagerUnixODBC is unixODBC (libodbc), which uses 2-byte SQLWCHAR
+	DriverManagerUnixODBC
+
+	// DriverManagerIODBC is iODBC (libiodbc), which uses 4-byte wchar_t SQLWCHAR
+	DriverManagerIODBC
+
+	// DriverManagerWindows is the Windows driver manager (odbc32.dll)
+	DriverManagerWindows
+)
+
+// String returns the driver manager name
+func (m DriverManager) String() string {
+	switch m {
+	case DriverManagerUnixODBC:
+		return "unixODBC"
+	case DriverManagerIODBC:
+		return "iODBC"
+	case DriverManagerWindows:
+		return "Windows"
+	default:
+		return "unknown"
+	}
+}
+
+// driverManagerSymbols are exported symbols unique to each driver manager
+var driverManagerSymbols = []struct {
+	manager DriverManager
+	symbols []string
+}{
+	{DriverManagerUnixODBC, []string{"uodbc_get_stats", "uodbc_open_stats"}},
+	{DriverManagerIODBC, []string{"_iodbcdm_getproc", "iodbc_version"}},
+}
+
+// LibraryInfo describes the loaded ODBC library, probed once when it is loaded
+type LibraryInfo struct {
+	// Path is the library path that was loaded
+	Path string
+
+	// DriverManager is the detected driver manager implementation
+	DriverManager DriverManager
+
+	// ODBCVersion is the highest ODBC version the driver manager accepted for
+	// SQL_ATTR_ODBC_VERSION: SQL_OV_ODBC3_80 (380), SQL_OV_ODBC3 (3), or 0 if unknown
+	ODBCVersion int
+
+	// WCharSize is the size of SQLWCHAR in bytes: 2 (UTF-16) or 4 (UTF-32)
+	WCharSize int
+}
+
+// libraryInfo holds the probed information for the loaded library
+var libraryInfo LibraryInfo
+
+// Library returns information about the loaded ODBC library.
+// It is zero-valued until the first connector or environment is opened.
+func Library() LibraryInfo {
+	return libraryInfo
+}
+
+// probeLibrary identifies the driver manager from symbol fingerprints and the
+// library name, and checks which ODBC version its environments accept
+func probeLibrary(lib uintptr, libPath string) LibraryInfo {
+	info := LibraryInfo{Path: libPath}
+	if runtime.GOOS == "windows" {
+		info.DriverManager = DriverManagerWindows
+	} else {
+		info.DriverManager = detectDriverManager(libPath, func(name string) bool {
+			return hasSymbol(lib, name)
+		})
+	}
+	info.WCharSize = wcharSizeFor(info.DriverManager)
+	info.ODBCVersion = probeODBCVersion()
+	return info
+}
+
+// detectDriverManager matches exported symbols first, then falls back to the library file name
+func detectDriverManager(libPath string, hasSymbol func(name string) bool) DriverManager {
+	for _, fp := range driverManagerSymbols {
+		for _, symbol := range fp.symbols {
+			if hasSymbol(symbol) {
+				return fp.manager
+			}
+		}
+	}
+
+	name := strings.ToLower(filepath.Base(libPath))
+	switch {
+	case strings.Contains(name, "iodbc"):
+		return DriverManagerIODBC
+	case strings.HasPrefix(name, "libodbc."):
+		return DriverManagerUnixODBC
+	case strings.HasPrefix(name, "odbc32"):
+		return DriverManagerWindows
+	}
+	return DriverManagerUnknown
+}
+
+// wcharSizeFor returns the SQLWCHAR size used by a driver manager.
+// iODBC uses the platform wchar_t, which is 4 bytes outside Windows;
+// unixODBC, Windows and unidentified libraries use 2-byte UTF-16.
+func wcharSizeFor(manager DriverManager) int {
+	if manager == DriverManagerIODBC && runtime.GOOS != "windows" {
+		return 4
+	}
+	return 2
+}
+
+// probeODBCVersion allocates a temporary environment and reports the highest
+// ODBC version it accepts, read back with SQLGetEnvAttr
+func probeODBCVersion() int {
+	var env SQLHENV
+	if !IsSuccess(AllocHandle(SQL_HANDLE_ENV, SQL_NULL_HANDLE, (*SQLHANDLE)(&env))) {
+		return 0
+	}
+	defer FreeHandle(SQL_HANDLE_ENV, SQLHANDLE(env))
+
+	for _, version := range []uintptr{SQL_OV_ODBC3_80, SQL_OV_ODBC3} {
+		if !IsSuccess(SetEnvAttr(env, SQL_ATTR_ODBC_VERSION, version, 0)) {
+			continue
+		}
+		var value SQLINTEGER
+		if IsSuccess(GetEnvAttr(env, SQL_ATTR_ODBC_VERSION, uintptr(unsafe.Pointer(&value)), 0, nil)) {
+			return int(value)
+		}
+		return int(version)
+	}
+	return 0
+}
+
+// wcharSize returns the SQLWCHAR size in bytes used for wide-character data
+func wcharSize() int {
+	if libraryInfo.WCharSize == 4 {
+		return 4
+	}
+	return 2
+}
@@ -0,0 +1,187 @@
+package godbc
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// DriverInfo describes an ODBC driver installed in the driver manager
+type DriverInfo struct {
+	// Name is the driver's description, as used in Driver={...} connection strings
+	Name string
+
+	// Attributes are the driver's keywords from odbcinst.ini or the registry,
+	// such as "Driver", "Setup" and "FileUsage"
+	Attributes map[string]string
+}
+
+// DataSourceInfo describes a data source name (DSN) defined in the driver manager
+type DataSourceInfo struct {
+	Name   string // Name used in DSN=... connection strings
+	Driver string // Description of the data source's driver
+}
+
+// errNoDriverManager is returned when the loaded library cannot list drivers
+// and data sources, as when a driver is loaded without a driver manager
+var errNoDriverManager = errors.New("the loaded ODBC library is not a driver manager and cannot list drivers or data sources")
+
+// Drivers returns the ODBC drivers installed in the driver manager, so tools
+// can show users which Driver={...} names a connection string can use.
+//
+// Example:
+//
+//	drivers, err := godbc.Drivers()
+//	for _, d := range drivers {
+//	    fmt.Println(d.Name, d.Attributes["Driver"])
+//	}
+func Drivers() ([]DriverInfo, error) {
+	env, err := NewEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	defer env.Close()
+	if sqlDrivers == nil {
+		return nil, errNoDriverManager
+	}
+	return listDrivers(env.env)
+}
+
+// listDrivers enumerates the drivers of an environment. SQLDrivers cannot
+// fetch the same driver again, so when a description or attribute list is
+// truncated (01004) the enumeration restarts with a buffer of the reported length.
+// In UnicodeAuto mode on Windows the driver manager is asked with SQLDriversW.
+func listDrivers(env SQLHENV) ([]DriverInfo, error) {
+	wide, unit := wideDriverManager(sqlDriversW != nil)
+	fetch := fetchDriver
+	if wide {
+		fetch = fetchDriverW
+	}
+	var drivers []DriverInfo
+	desc := make([]byte, 256*unit)
+	attrs := make([]byte, 4096*unit)
+	direction := SQLUSMALLINT(SQL_FETCH_FIRST)
+	for {
+		descLen, attrsLen, ret := fetch(env, direction, desc, attrs)
+		if ret == SQL_NO_DATA {
+			return drivers, nil
+		}
+		if !IsSuccess(ret) {
+			return drivers, NewError(SQL_HANDLE_ENV, SQLHANDLE(env))
+		}
+		if ret == SQL_SUCCESS_WITH_INFO {
+			var descGrown, attrsGrown bool
+			desc, descGrown = growCatalogBuffer(desc, descLen, unit)
+			attrs, attrsGrown = growCatalogBuffer(attrs, attrsLen, unit)
+			if descGrown || attrsGrown {
+				drivers, direction = nil, SQLUSMALLINT(SQL_FETCH_FIRST)
+				continue
+			}
+		}
+		drivers = append(drivers, DriverInfo{
+			Name:       driverManagerText(desc, descLen, unit),
+			Attributes: parseDriverAttributes([]byte(driverManagerText(attrs, attrsLen, unit))),
+		})
+		direction = SQLUSMALLINT(SQL_FETCH_NEXT)
+	}
+}
+
+// DataSources returns the user and system data sources (DSNs) defined in the
+// driver manager
+func DataSources() ([]DataSourceInfo, error) {
+	env, err := NewEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	defer env.Close()
+	if sqlDataSources == nil {
+		return nil, errNoDriverManager
+	}
+	return listDataSources(env.env)
+}
+
+// listDataSources enumerates the data sources of an environment, restarting
+// with larger buffers when a name or description is truncated, as listDrivers does
+func listDataSources(env SQLHENV) ([]DataSourceInfo, error) {
+	wide, unit := wideDriverManager(sqlDataSourcesW != nil)
+	fetch := fetchDataSource
+	if wide {
+		fetch = fetchDataSourceW
+	}
+	var sources []DataSourceInfo
+	name := make([]byte, 256*unit)
+	desc := make([]byte, 256*unit)
+	direction := SQLUSMALLINT(SQL_FETCH_FIRST)
+	for {
+		nameLen, descLen, ret := fetch(env, direction, name, desc)
+		if ret == SQL_NO_DATA {
+			return sources, nil
+		}
+		if !IsSuccess(ret) {
+			return sources, NewError(SQL_HANDLE_ENV, SQLHANDLE(env))
+		}
+		if ret == SQL_SUCCESS_WITH_INFO {
+			var nameGrown, descGrown bool
+			name, nameGrown = growCatalogBuffer(name, nameLen, unit)
+			desc, descGrown = growCatalogBuffer(desc, descLen, unit)
+			if nameGrown || descGrown {
+				sources, direction = nil, SQLUSMALLINT(SQL_FETCH_FIRST)
+				continue
+			}
+		}
+		sources = append(sources, DataSourceInfo{
+			Name:   driverManagerText(name, nameLen, unit),
+			Driver: driverManagerText(desc, descLen, unit),
+		})
+		direction = SQLUSMALLINT(SQL_FETCH_NEXT)
+	}
+}
+
+// wideDriverManager reports whether drivers and data sources are listed with
+// the W entry points, as in UnicodeAuto mode on Windows when the library has
+// them, and the size in bytes of the characters of the buffers
+func wideDriverManager(hasWide bool) (bool, int) {
+	if hasWide && wideByDefault {
+		return true, wcharSize()
+	}
+	return false, 1
+}
+
+// growCatalogBuffer returns a buffer for a value of n characters of unit bytes
+// and its null terminator, and whether it is larger than buf. Buffer lengths
+// are passed as SQLSMALLINT, so the size is capped at math.MaxInt16 characters.
+func growCatalogBuffer(buf []byte, n SQLSMALLINT, unit int) ([]byte, bool) {
+	size := min(int(n)+1, math.MaxInt16) * unit
+	if size <= len(buf) {
+		return buf, false
+	}
+	return make([]byte, size), true
+}
+
+// driverManagerText returns the value of n characters of unit bytes the
+// driver manager wrote to buf, keeping the null characters that separate the
+// entries of the SQLDrivers attribute list
+func driverManagerText(buf []byte, n SQLSMALLINT, unit int) string {
+	if unit > 1 {
+		return wideBufferList(buf, clampLen(n, len(buf)/unit))
+	}
+	return string(buf[:clampLen(n, len(buf))])
+}
+
+// clampLen limits a length reported by the driver manager to the buffer
+// size, since a truncated value reports its full length
+func clampLen(n SQLSMALLINT, size int) int {
+	return max(0, min(int(n), size-1))
+}
+
+// parseDriverAttributes parses the attribute list SQLDrivers returns:
+// "key=value" pairs each ended by a null byte, the list ended by another
+func parseDriverAttributes(buf []byte) map[string]string {
+	attrs := make(map[string]string)
+	for _, pair := range strings.Split(string(buf), "\x00") {
+		if key, value, ok := strings.Cut(pair, "="); ok && key != "" {
+			attrs[key] = value
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,324 @@
+package godbc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// connStringOutSize is the size of the buffer receiving the completed connection
+// string from SQLDriverConnect. The ODBC specification recommends at least 1024 bytes.
+const connStringOutSize = 4096
+
+// redactedValue replaces secret values in redacted connection strings
+const redactedValue = "***"
+
+// secretKeyFragments identifies connection string keys whose values are secrets
+var secretKeyFragments = []string{"pwd", "password", "passwd", "secret", "token", "apikey", "privatekey", "private_key"}
+
+// isSecretKey reports whether a connection string key holds a secret value
+func isSecretKey(key string) bool {
+	key = strings.ToLower(strings.TrimSpace(key))
+	for _, fragment := range secretKeyFragments {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactConnString returns the connection string with secret values
+// (PWD, Password, tokens, ...) replaced by ***. Values wrapped in braces
+// may contain ';' and are handled as a single value.
+func RedactConnString(connStr string) string {
+	var sb strings.Builder
+	sb.Grow(len(connStr))
+
+	i := 0
+	for i < len(connStr) {
+		// Key runs up to '=' (or the end of the attribute if there is none)
+		eq := strings.IndexAny(connStr[i:], "=;")
+		if eq < 0 || connStr[i+eq] == ';' {
+			end := len(connStr)
+			if eq >= 0 {
+				end = i + eq + 1
+			}
+			sb.WriteString(connStr[i:end])
+			i = end
+			continue
+		}
+		key := connStr[i : i+eq]
+		sb.WriteString(key)
+		sb.WriteByte('=')
+		i += eq + 1
+
+		// Value is either {braced} with }} as an escaped brace, or runs to the next ';'
+		start := i
+		if i < len(connStr) && connStr[i] == '{' {
+			i++
+			for i < len(connStr) {
+				if connStr[i] == '}' {
+					if i+1 < len(connStr) && connStr[i+1] == '}' {
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+		} else {
+			for i < len(connStr) && connStr[i] != ';' {
+				i++
+			}
+		}
+
+		if isSecretKey(key) {
+			sb.WriteString(redactedValue)
+		} else {
+			sb.WriteString(connStr[start:i])
+		}
+		// Copy the separator, if any
+		if i < len(connStr) && connStr[i] == ';' {
+			sb.WriteByte(';')
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// connStringFromBuffer extracts the completed connection string from the
+// SQLDriverConnect output buffer, tolerating truncation and missing terminators
+func connStringFromBuffer(buf []byte, outLen SQLSMALLINT) string {
+	end := int(outLen)
+	if end < 0 {
+		return ""
+	}
+	if end > len(buf) {
+		end = len(buf)
+	}
+	for i := 0; i < end; i++ {
+		if buf[i] == 0 {
+			end = i
+			break
+		}
+	}
+	return string(buf[:end])
+}
+
+// ConnectedDSN returns the completed connection string reported by the driver after
+// connecting, with defaults filled in (resolved ports, failover partners, driver options).
+// Secret values are redacted. It returns an empty string if the driver reported none.
+func (c *Conn) ConnectedDSN() string {
+	return c.connectedDSN
+}
+
+// removeConnAttrs removes the attributes of a connection string for which
+// remove returns true. remove is called with each key and value, trimmed and
+// with {braced} values unwrapped.
+func removeConnAttrs(connStr string, remove func(key, value string) bool) string {
+	var sb strings.Builder
+	sb.Grow(len(connStr))
+
+	i := 0
+	for i < len(connStr) {
+		// Find the end of the attribute, skipping over a {braced} value
+		start := i
+		eq := strings.IndexAny(connStr[i:], "=;")
+		if eq >= 0 && connStr[i+eq] == '=' {
+			i += eq + 1
+			if i < len(connStr) && connStr[i] == '{' {
+				for i++; i < len(connStr); i++ {
+					if connStr[i] == '}' {
+						if i+1 < len(connStr) && connStr[i+1] == '}' {
+							i++
+							continue
+						}
+						i++
+						break
+					}
+				}
+			}
+		}
+		for i < len(connStr) && connStr[i] != ';' {
+			i++
+		}
+		attr := connStr[start:i]
+		if i < len(connStr) {
+			i++ // Skip the separator
+		}
+
+		k, v, found := strings.Cut(attr, "=")
+		if !found {
+			sb.WriteString(connStr[start:i])
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && v[0] == '{' && v[len(v)-1] == '}' {
+			v = strings.ReplaceAll(v[1:len(v)-1], "}}", "}")
+		}
+		if !remove(strings.TrimSpace(k), v) {
+			sb.WriteString(connStr[start:i])
+		}
+	}
+	return sb.String()
+}
+
+// dsnOptionPrefix starts the connection string keys holding driver options,
+// such as godbc_query_timeout. They are removed from the connection string
+// before it is passed to the driver.
+const dsnOptionPrefix = "godbc_"
+
+// dsnOptions maps the driver options accepted in connection strings, by key
+// without the prefix, to the parsers of their values
+var dsnOptions = map[string]func(value string) (ConnectorOption, error){
+	"query_timeout":        dsnDuration(WithQueryTimeout),
+	"keepalive":            dsnDuration(WithKeepAlive),
+	"wide_fetch":           dsnBool(WithWideFetch),
+	"prefetch":             dsnBool(WithPrefetch),
+	"correlation_comments": dsnBool(WithCorrelationComments),
+	"multi_row_insert":     dsnBool(WithMultiRowInsert),
+	"rowset_size":          dsnInt(WithRowArraySize),
+	"stmt_cache_size":      dsnInt(WithStmtCacheSize),
+	"max_column_bytes":     dsnInt(WithMaxColumnBytes),
+	"ping_query":           dsnString(WithPingQuery),
+	"reset_query":          dsnString(WithResetQuery),
+	"timezone": func(value string) (ConnectorOption, error) {
+		tz, err := time.LoadLocation(value)
+		if err != nil {
+			return nil, err
+		}
+		return WithTimezone(tz), nil
+	},
+	"timestamp_precision": dsnEnum(WithTimestampPrecision, map[string]TimestampPrecision{
+		"seconds":      TimestampPrecisionSeconds,
+		"milliseconds": TimestampPrecisionMilliseconds,
+		"microseconds": TimestampPrecisionMicroseconds,
+		"nanoseconds":  TimestampPrecisionNanoseconds,
+	}),
+	"unicode": dsnEnum(WithUnicode, map[string]UnicodeMode{
+		"auto": UnicodeAuto,
+		"ansi": UnicodeANSI,
+		"wide": UnicodeWide,
+	}),
+	"invalid_text": dsnEnum(WithInvalidTextMode, map[string]InvalidTextMode{
+		"replace": InvalidTextReplace,
+		"reject":  InvalidTextReject,
+	}),
+	"decimal_fetch": dsnEnum(WithDecimalFetchMode, map[string]DecimalFetchMode{
+		"string":  DecimalFetchString,
+		"numeric": DecimalFetchNumeric,
+	}),
+	"guid_fetch": dsnEnum(WithGUIDFetchMode, map[string]GUIDFetchMode{
+		"string": GUIDFetchString,
+		"binary": GUIDFetchBinary,
+	}),
+	"timestamp_fetch": dsnEnum(WithTimestampFetchMode, map[string]TimestampFetchMode{
+		"time":        TimestampFetchTime,
+		"epoch_nanos": TimestampFetchEpochNanos,
+	}),
+	"truncation": dsnEnum(WithTruncationPolicy, map[string]TruncationPolicy{
+		"error":    TruncationError,
+		"truncate": TruncationTruncate,
+		"stream":   TruncationStream,
+	}),
+	"copy_mode": dsnEnum(WithCopyMode, map[string]CopyMode{
+		"always":            CopyAlways,
+		"borrow_until_next": CopyBorrowUntilNext,
+	}),
+	"identifier_case": dsnEnum(WithIdentifierCasePolicy, map[string]IdentifierCasePolicy{
+		"auto":     IdentifierCasePolicyAuto,
+		"preserve": IdentifierCasePolicyPreserve,
+		"lower":    IdentifierCasePolicyLower,
+		"upper":    IdentifierCasePolicyUpper,
+	}),
+}
+
+// extractDSNOptions removes the driver options (godbc_* keys) from a connection
+// string and returns them as connector options, in the order they appear. Unknown godbc_* keys and invalid values
+// are errors, so a misspelled option is not silently ignored.
+func extractDSNOptions(connStr string) ([]ConnectorOption, string, error) {
+	var opts []ConnectorOption
+	var errs []error
+	rest := removeConnAttrs(connStr, func(key, value string) bool {
+		lower := strings.ToLower(key)
+		if !strings.HasPrefix(lower, dsnOptionPrefix) {
+			return false
+		}
+		parse, ok := dsnOptions[strings.TrimPrefix(lower, dsnOptionPrefix)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown connection string option %s", key))
+			return true
+		}
+		opt, err := parse(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("connection string option %s: %w", key, err))
+			return true
+		}
+		opts = append(opts, opt)
+		return true
+	})
+	if len(errs) > 0 {
+		return nil, "", errors.Join(errs...)
+	}
+	return opts, rest, nil
+}
+
+// dsnDuration parses a duration such as 30s, or a number of seconds
+func dsnDuration(with func(time.Duration) ConnectorOption) func(string) (ConnectorOption, error) {
+	return func(value string) (ConnectorOption, error) {
+		if secs, err := strconv.Atoi(value); err == nil {
+			return with(time.Duration(secs) * time.Second), nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		return with(d), nil
+	}
+}
+
+// dsnBool parses a boolean such as true, false, 1 or 0
+func dsnBool(with func(bool) ConnectorOption) func(string) (ConnectorOption, error) {
+	return func(value string) (ConnectorOption, error) {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return with(b), nil
+	}
+}
+
+// dsnInt parses a non-negative integer
+func dsnInt(with func(int) ConnectorOption) func(string) (ConnectorOption, error) {
+	return func(value string) (ConnectorOption, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("negative value %d", n)
+		}
+		return with(n), nil
+	}
+}
+
+// dsnString takes the value as is
+func dsnString(with func(string) ConnectorOption) func(string) (ConnectorOption, error) {
+	return func(value string) (ConnectorOption, error) {
+		return with(value), nil
+	}
+}
+
+// dsnEnum parses one of the names of an enumeration (case-insensitive)
+func dsnEnum[T any](with func(T) ConnectorOption, names map[string]T) func(string) (ConnectorOption, error) {
+	return func(value string) (ConnectorOption, error) {
+		v, ok := names[strings.ToLower(value)]
+		if !ok {
+			return nil, fmt.Errorf("invalid value %q", value)
+		}
+		return with(v), nil
+	}
+}
@@ -0,0 +1,106 @@
+package godbc
+
+import (
+	"errors"
+	"sync"
+)
+
+// Environment is a shared ODBC environment handle. Connections created by a
+// Connector configured WithEnvironment are allocated on this environment instead
+// of a private one, which allows committing or rolling back all of them together.
+//
+// Environment-level transaction completion is an advanced feature with caveats:
+//   - It is not a two-phase commit. The driver manager calls SQLEndTran on each
+//     connection in turn, so a failure part way through can leave some connections
+//     committed and others not. The returned error reports the first failure only.
+//   - Support varies by driver manager and driver. Some drivers reject
+//     SQL_HANDLE_ENV completion with HY092/HYC00; use per-connection Tx instead.
+//   - Only connections with an open transaction (started via BeginTx) are affected.
+//     Call Commit/Rollback on each Tx afterwards to restore autocommit mode.
+type Environment struct {
+	env    SQLHENV
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewEnvironment allocates a new ODBC 3.x environment handle.
+// The caller must Close the environment after all its connections are closed.
+func NewEnvironment() (*Environment, error) {
+	if err := initODBC(); err != nil {
+		return nil, err
+	}
+
+	var env SQLHENV
+	ret := AllocHandle(SQL_HANDLE_ENV, SQL_NULL_HANDLE, (*SQLHANDLE)(&env))
+	if !IsSuccess(ret) {
+		return nil, errors.New("failed to allocate ODBC environment handle")
+	}
+
+	ret = SetEnvAttr(env, SQL_ATTR_ODBC_VERSION, uintptr(SQL_OV_ODBC3), 0)
+	if !IsSuccess(ret) {
+		err := NewError(SQL_HANDLE_ENV, SQLHANDLE(env))
+		FreeHandle(SQL_HANDLE_ENV, SQLHANDLE(env))
+		return nil, err
+	}
+
+	e := &Environment{env: env}
+	lifecycle.addEnvironment(e)
+	return e, nil
+}
+
+// WithEnvironment configures the Connector to allocate connections on a shared
+// Environment. Connections do not free the environment when they are closed.
+func WithEnvironment(env *Environment) ConnectorOption {
+	return func(c *Connector) {
+		c.Environment = env
+	}
+}
+
+// Commit commits the open transactions of all connections on the environment.
+// See the Environment documentation for driver support caveats.
+func (e *Environment) Commit() error {
+	return e.endTran(SQL_COMMIT)
+}
+
+// Rollback rolls back the open transactions of all connections on the environment.
+// See the Environment documentation for driver support caveats.
+func (e *Environment) Rollback() error {
+	return e.endTran(SQL_ROLLBACK)
+}
+
+// endTran completes transactions for all connections on the environment
+func (e *Environment) endTran(completionType SQLSMALLINT) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return errors.New("environment is closed")
+	}
+
+	ret := EndTran(SQL_HANDLE_ENV, SQLHANDLE(e.env), completionType)
+	if !IsSuccess(ret) {
+		return NewError(SQL_HANDLE_ENV, SQLHANDLE(e.env))
+	}
+	return nil
+}
+
+// Close frees the environment handle. All connections allocated on the
+// environment must be closed first, otherwise the driver manager returns an error.
+// It is safe to call Close multiple times; subsequent calls are no-ops.
+func (e *Environment) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return nil
+	}
+
+	ret := FreeHandle(SQL_HANDLE_ENV, SQLHANDLE(e.env))
+	if !IsSuccess(ret) {
+		return NewError(SQL_HANDLE_ENV, SQLHANDLE(e.env))
+	}
+	e.closed = true
+	e.env = 0
+	lifecycle.removeEnvironment(e)
+	return nil
+}
@@ -1,10 +1,20 @@
 package godbc
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
 
+// ErrStmtClosed is returned when a closed prepared statement is used.
+//
+// The driver only returns driver.ErrBadConn when the connection itself is unusable
+// and the operation was not started, because database/sql treats ErrBadConn as a
+// signal to silently retry on another connection. A closed statement says nothing
+// about the connection, so it gets its own error rather than triggering a retry
+// that could duplicate non-idempotent work.
+var ErrStmtClosed = errors.New("godbc: statement is closed")
+
 // Error represents an ODBC error with diagnostic information from the driver.
 // It implements the error interface and provides SQLState, native error code,
 // and a human-readable message.
@@ -62,36 +72,62 @@ func (e Errors) Error() string {
 	return sb.String()
 }
 
-// GetDiagRecords retrieves all diagnostic records for a handle
+// GetDiagRecords retrieves the diagnostic records for a handle, up to the
+// limits set with SetDiagLimits. Messages beyond the length limit are cut
+// with "...", and the last record kept notes how many more were left out,
+// e.g. "(and 213 more)".
+//
+// Messages are read with SQLGetDiagRecW in UnicodeAuto mode on Windows, as
+// for a connection's errors in UnicodeWide mode.
 func GetDiagRecords(handleType SQLSMALLINT, handle SQLHANDLE) []DiagRecord {
-	var records []DiagRecord
-	sqlState := make([]byte, 6)
-	message := make([]byte, 1024)
+	return getDiagRecords(handleType, handle, wideOutput(UnicodeAuto))
+}
 
-	for i := SQLSMALLINT(1); ; i++ {
-		nativeError, msgLen, ret := GetDiagRec(handleType, handle, i, sqlState, message)
-		if ret == SQL_NO_DATA {
-			break
-		}
-		if IsSuccess(ret) {
-			// Trim null terminator if present
-			state := string(sqlState[:5])
-			msg := string(message[:msgLen])
-			records = append(records, DiagRecord{
-				SQLState:    state,
-				NativeError: int32(nativeError),
-				Message:     msg,
-			})
+// getDiagRecords retrieves the diagnostic records for a handle with
+// SQLGetDiagRec, or SQLGetDiagRecW if wide is set
+func getDiagRecords(handleType SQLSMALLINT, handle SQLHANDLE, wide bool) []DiagRecord {
+	limits := diagLimits()
+	buf := diagBuffers.Get().(*diagBuffer)
+	defer diagBuffers.Put(buf)
+
+	var records []DiagRecord
+	for i := SQLSMALLINT(1); int(i) <= limits.MaxRecords; i++ {
+		var rec DiagRecord
+		var ret SQLRETURN
+		if wide {
+			rec, ret = buf.readWide(handleType, handle, i, limits.MaxMessageLen)
 		} else {
-			break
+			rec, ret = buf.read(handleType, handle, i, limits.MaxMessageLen)
 		}
+		if ret == SQL_NO_DATA || !IsSuccess(ret) {
+			return records
+		}
+		records = append(records, rec)
+	}
+
+	if omitted := omittedDiagRecords(handleType, handle, len(records)); omitted != "" {
+		records[len(records)-1].Message += " " + omitted
 	}
 	return records
 }
 
 // NewError creates an Error from diagnostic records
 func NewError(handleType SQLSMALLINT, handle SQLHANDLE) error {
-	records := GetDiagRecords(handleType, handle)
+	return diagError(GetDiagRecords(handleType, handle))
+}
+
+// newError creates an Error from the diagnostic records of a handle of the
+// connection and records them for DebugDump
+func (c *Conn) newError(handleType SQLSMALLINT, handle SQLHANDLE) error {
+	records := getDiagRecords(handleType, handle, wideOutput(c.unicodeMode()))
+	if c != nil {
+		c.diagnostics.add(records)
+	}
+	return diagError(records)
+}
+
+// diagError creates an Error from diagnostic records
+func diagError(records []DiagRecord) error {
 	if len(records) == 0 {
 		return &Error{
 			SQLState: "HY000",
@@ -146,6 +182,9 @@ const (
 	SQLStateInvalidCursorState = "24000" // Invalid cursor state
 	SQLStateInvalidTransState  = "25000" // Invalid transaction state
 
+	// Prepared statement errors (26xxx)
+	SQLStateInvalidStatementName = "26000" // Invalid SQL statement name
+
 	// Transaction errors (40xxx)
 	SQLStateDeadlock          = "40001" // Serialization failure (deadlock)
 	SQLStateTransactionFailed = "40003" // Statement completion unknown
@@ -196,6 +235,83 @@ func IsDataTruncation(err error) bool {
 	return false
 }
 
+// IsFunctionSequenceError reports whether err is a function sequence error
+// (HY010), which a driver returns when a statement handle is used while an
+// earlier operation on it has not been finished.
+func IsFunctionSequenceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if e, ok := err.(*Error); ok {
+		return e.SQLState == SQLStateFunctionSequenceError
+	}
+	if es, ok := err.(Errors); ok {
+		for _, e := range es {
+			if e.SQLState == SQLStateFunctionSequenceError {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// schemaChangeMessages are message fragments of schema change errors that
+// drivers report under a generic SQLState, matched case-insensitively
+var schemaChangeMessages = []string{
+	"cached plan must not change result type", // PostgreSQL, after DDL on a table a prepared statement reads
+}
+
+// IsSchemaChangeError reports whether err indicates that a prepared statement
+// no longer matches the schema it was prepared against, as after DDL on a
+// table it reads: an invalid statement name (26000, a server-side prepared
+// statement that no longer exists) or a driver-specific message. Such
+// statements succeed once prepared again. An invalid cursor state (24000)
+// usually means the application misused a cursor and is not matched; see
+// Quirk.ReprepareOnInvalidCursorState for drivers that report schema changes so.
+func IsSchemaChangeError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var records []Error
+	if e, ok := err.(*Error); ok {
+		records = []Error{*e}
+	} else if es, ok := err.(Errors); ok {
+		records = es
+	}
+	for _, e := range records {
+		if e.SQLState == SQLStateInvalidStatementName {
+			return true
+		}
+		msg := strings.ToLower(e.Message)
+		for _, m := range schemaChangeMessages {
+			if strings.Contains(msg, m) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SequenceError is returned when a statement keeps failing with a function
+// sequence error (HY010) after it was reset and prepared again. Cause is the
+// earlier failure that left the statement in the invalid state, which is the
+// error worth reporting; Err is the HY010 error itself.
+type SequenceError struct {
+	Cause error
+	Err   error
+}
+
+// Error implements the error interface
+func (e *SequenceError) Error() string {
+	return fmt.Sprintf("statement unusable after earlier error: %v", e.Cause)
+}
+
+// Unwrap returns the cause and the function sequence error, so errors.Is and
+// errors.As match either
+func (e *SequenceError) Unwrap() []error {
+	return []error{e.Cause, e.Err}
+}
+
 // IsRetryable reports whether err represents a transient error that may
 // succeed if retried. Transient errors include connection failures,
 // timeouts, and deadlocks.
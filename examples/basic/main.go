@@ -12,7 +12,7 @@ import (
 	"strings"
 	"time"
 
-	_ "github.com/slingdata-io/godbc"
+	"github.com/slingdata-io/godbc"
 )
 
 // DBType represents the type of database
@@ -234,6 +234,12 @@ func main() {
 		log.Fatalf("Test failed: %v", err)
 	}
 
+	if dbType == DBTypePostgres {
+		if err := runPostgresTypesTest(db, tableName+"_pg"); err != nil {
+			log.Fatalf("PostgreSQL types test failed: %v", err)
+		}
+	}
+
 	log.Println("All tests passed!")
 }
 
@@ -503,6 +509,41 @@ func runTest(db *sql.DB, dbType DBType, tableName string, ddl DDLTemplates) erro
 	return nil
 }
 
+// runPostgresTypesTest round-trips bit strings and boolean arrays, which
+// psqlODBC returns as text and godbc decodes to BitString and []bool
+func runPostgresTypesTest(db *sql.DB, tableName string) error {
+	log.Printf("Testing PostgreSQL bit string and boolean array types in %s...", tableName)
+	_, _ = db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName))
+	if _, err := db.Exec(fmt.Sprintf("CREATE TABLE %s (id INTEGER PRIMARY KEY, flags BIT(4), mask VARBIT(16), checks BOOLEAN[])", tableName)); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+	defer db.Exec(fmt.Sprintf("DROP TABLE %s", tableName))
+
+	flags := godbc.BitString("1010")
+	mask := godbc.BitStringFromBools([]bool{true, true, false})
+	checks := []bool{true, false, true}
+	_, err := db.Exec(fmt.Sprintf("INSERT INTO %s (id, flags, mask, checks) VALUES (?, CAST(? AS BIT(4)), CAST(? AS VARBIT), CAST(? AS BOOLEAN[]))", tableName),
+		1, flags, mask, checks)
+	if err != nil {
+		return fmt.Errorf("failed to insert: %w", err)
+	}
+
+	var gotFlags, gotMask godbc.BitString
+	var gotChecks []bool
+	err = db.QueryRow(fmt.Sprintf("SELECT flags, mask, checks FROM %s WHERE id = 1", tableName)).Scan(&gotFlags, &gotMask, &gotChecks)
+	if err != nil {
+		return fmt.Errorf("failed to select: %w", err)
+	}
+	if gotFlags != flags || gotMask != mask {
+		return fmt.Errorf("bit strings mismatch: got %q, %q, want %q, %q", gotFlags, gotMask, flags, mask)
+	}
+	if fmt.Sprint(gotChecks) != fmt.Sprint(checks) {
+		return fmt.Errorf("boolean array mismatch: got %v, want %v", gotChecks, checks)
+	}
+	log.Printf("  flags=%s mask=%s checks=%v", gotFlags, gotMask, gotChecks)
+	return nil
+}
+
 // validateNativeTypes checks that the native type names match expected values for each database type
 func validateNativeTypes(dbType DBType, colTypes []*sql.ColumnType) error {
 	// Build a map of column name to type name
@@ -0,0 +1,78 @@
+// Package main serves an ODBC data source over HTTP with the godbc server
+// package, so clients in any language can stream query results from it.
+//
+// Usage:
+//
+//	GODBC_SERVER_TOKEN=secret ./server -conn-string "Driver={...};..." -addr :8080
+//	curl -H "Authorization: Bearer secret" -d '{"query":"SELECT 1 AS n"}' http://localhost:8080/query
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/slingdata-io/godbc"
+	"github.com/slingdata-io/godbc/server"
+)
+
+func main() {
+	dsn := flag.String("conn-string", "", "ODBC connection string (required)")
+	addr := flag.String("addr", ":8080", "address to listen on")
+	readOnly := flag.Bool("read-only", true, "only accept SELECT and WITH queries")
+	flag.Parse()
+
+	token := os.Getenv("GODBC_SERVER_TOKEN")
+	if *dsn == "" || token == "" {
+		log.Fatal("-conn-string and the GODBC_SERVER_TOKEN environment variable are required")
+	}
+
+	connector, err := (&godbc.Driver{}).OpenConnectorWithOptions(*dsn,
+		godbc.WithRowArraySize(1024),
+		godbc.WithMaxColumnBytes(16<<20))
+	if err != nil {
+		log.Fatalf("open: %v", err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	var opts []server.Option
+	if *readOnly {
+		opts = append(opts, server.WithQueryFilter(selectOnly))
+	}
+	http.Handle("/query", requireToken(token, server.New(db, opts...)))
+
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// requireToken rejects requests without the bearer token
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// selectOnly accepts queries that start with SELECT or WITH. It is a coarse
+// check; give the server a database user with read-only grants as well.
+func selectOnly(_ context.Context, query string) error {
+	fields := strings.Fields(query)
+	if len(fields) > 0 {
+		switch strings.ToUpper(fields[0]) {
+		case "SELECT", "WITH":
+			return nil
+		}
+	}
+	return errors.New("only SELECT queries are accepted")
+}
@@ -0,0 +1,403 @@
+package godbc
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultExportRowArraySize is the rowset size used by Export when the
+// connection does not configure block fetching
+const defaultExportRowArraySize = 1024
+
+// ExportSink receives the tables extracted by Export. Begin is called from
+// several goroutines at once when Export runs in parallel; each ExportWriter
+// it returns is used by a single goroutine.
+type ExportSink interface {
+	Begin(table TableInfo, columns []string) (ExportWriter, error)
+}
+
+// ExportWriter receives the rows of one table
+type ExportWriter interface {
+	// WriteRow writes one row. values is reused for the next row, so it must
+	// be copied if retained.
+	WriteRow(values []driver.Value) error
+
+	// Close finishes the table. err is the extraction error, if any, so
+	// partial output can be discarded.
+	Close(err error) error
+}
+
+// ExportFunc is an ExportSink that calls a function for every row
+type ExportFunc func(table TableInfo, columns []string, values []driver.Value) error
+
+// Begin implements ExportSink
+func (f ExportFunc) Begin(table TableInfo, columns []string) (ExportWriter, error) {
+	return &exportFuncWriter{fn: f, table: table, columns: columns}, nil
+}
+
+// exportFuncWriter is the ExportWriter of an ExportFunc
+type exportFuncWriter struct {
+	fn      ExportFunc
+	table   TableInfo
+	columns []string
+}
+
+func (w *exportFuncWriter) WriteRow(values []driver.Value) error {
+	return w.fn(w.table, w.columns, values)
+}
+
+func (w *exportFuncWriter) Close(error) error {
+	return nil
+}
+
+// CSVSink writes each table to a CSV file named schema.table.csv in Dir,
+// with a header row of column names. Dots, '%' and characters not allowed in
+// file names are percent-encoded within the schema and table names. NULL is
+// written as an empty field, binary values as standard base64 and times in
+// RFC 3339 format. The file of a table that fails to export is removed.
+type CSVSink struct {
+	Dir string
+}
+
+// Begin implements ExportSink
+func (s CSVSink) Begin(table TableInfo, columns []string) (ExportWriter, error) {
+	path := filepath.Join(s.Dir, exportFileName(table, ".csv"))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &csvExportWriter{file: f, buf: bufio.NewWriter(f)}
+	w.csv = csv.NewWriter(w.buf)
+	if err := w.csv.Write(columns); err != nil {
+		w.Close(err)
+		return nil, err
+	}
+	return w, nil
+}
+
+// csvExportWriter is the ExportWriter of a CSVSink
+type csvExportWriter struct {
+	file   *os.File
+	buf    *bufio.Writer
+	csv    *csv.Writer
+	record []string
+}
+
+func (w *csvExportWriter) WriteRow(values []driver.Value) error {
+	w.record = w.record[:0]
+	for _, v := range values {
+		w.record = append(w.record, csvField(v))
+	}
+	return w.csv.Write(w.record)
+}
+
+func (w *csvExportWriter) Close(err error) error {
+	if err == nil {
+		w.csv.Flush()
+		err = w.csv.Error()
+		if err == nil {
+			err = w.buf.Flush()
+		}
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(w.file.Name())
+	}
+	return err
+}
+
+// csvField formats a column value as a CSV field
+func csvField(v driver.Value) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// exportFileName returns the file name of a table. Characters that are not
+// safe in file names, '%' and the '.' separating schema and table are
+// percent-encoded within each part, so distinct tables such as a.b + c and
+// a + b.c get distinct names.
+func exportFileName(table TableInfo, ext string) string {
+	name := escapeFileNamePart(table.Name)
+	if table.Schema != "" {
+		name = escapeFileNamePart(table.Schema) + "." + name
+	}
+	return name + ext
+}
+
+// escapeFileNamePart percent-encodes the characters of a schema or table name
+// that exportFileName cannot use as is
+func escapeFileNamePart(part string) string {
+	var sb strings.Builder
+	for _, r := range part {
+		switch {
+		case r == '%' || r == '.' || r < ' ' || strings.ContainsRune(`/\:*?"<>|`, r):
+			fmt.Fprintf(&sb, "%%%02X", r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// ExportOption configures Export
+type ExportOption func(*exporter)
+
+// WithExportParallelism sets the number of tables extracted at once, each on
+// its own connection from the pool (defaults to 1)
+func WithExportParallelism(n int) ExportOption {
+	return func(e *exporter) {
+		e.parallelism = n
+	}
+}
+
+// WithExportTableTypes sets the table types exported, as named by the driver
+// (defaults to "TABLE")
+func WithExportTableTypes(types ...string) ExportOption {
+	return func(e *exporter) {
+		e.tableTypes = types
+	}
+}
+
+// WithExportFilter restricts the export to the tables for which keep returns true
+func WithExportFilter(keep func(TableInfo) bool) ExportOption {
+	return func(e *exporter) {
+		e.filter = keep
+	}
+}
+
+// WithExportRowArraySize sets the rowset size used to fetch tables when the
+// connection does not configure one with WithRowArraySize (defaults to 1024)
+func WithExportRowArraySize(n int) ExportOption {
+	return func(e *exporter) {
+		e.rowArraySize = n
+	}
+}
+
+// WithExportReadOnlyTx sets whether each table is read inside a read-only
+// transaction (defaults to true). Disable it for drivers that do not support
+// read-only transactions; tables are then read in autocommit mode.
+func WithExportReadOnlyTx(enabled bool) ExportOption {
+	return func(e *exporter) {
+		e.readOnlyTx = enabled
+	}
+}
+
+// ExportReport summarizes an Export
+type ExportReport struct {
+	Tables   []ExportTableResult // One entry per table, in catalog order
+	Rows     int64               // Total rows exported
+	Duration time.Duration
+}
+
+// ExportTableResult is the outcome of exporting one table
+type ExportTableResult struct {
+	Table    TableInfo
+	Rows     int64
+	Duration time.Duration
+	Err      error // nil if the table was exported completely
+}
+
+// Failed returns the tables that could not be exported
+func (r *ExportReport) Failed() []ExportTableResult {
+	var failed []ExportTableResult
+	for _, t := range r.Tables {
+		if t.Err != nil {
+			failed = append(failed, t)
+		}
+	}
+	return failed
+}
+
+// exporter holds the settings of an Export
+type exporter struct {
+	parallelism  int
+	tableTypes   []string
+	filter       func(TableInfo) bool
+	rowArraySize int
+	readOnlyTx   bool
+	sink         ExportSink
+}
+
+// Export extracts every table of a schema to a sink. Tables are listed with
+// SQLTables and read in full with SELECT * inside a read-only transaction
+// (see WithExportReadOnlyTx), using block fetching. A table that fails does not stop the others: the
+// report lists the outcome of every table, and the returned error joins the
+// errors of the failed tables. Tables exported in parallel are read in
+// separate transactions, so they are not a single consistent snapshot.
+//
+// Example:
+//
+//	report, err := godbc.Export(ctx, db, "sales", godbc.CSVSink{Dir: "/backup"},
+//	    godbc.WithExportParallelism(4))
+//	for _, t := range report.Failed() {
+//	    log.Printf("%s: %v", t.Table.Name, t.Err)
+//	}
+func Export(ctx context.Context, db *sql.DB, schema string, sink ExportSink, opts ...ExportOption) (*ExportReport, error) {
+	e := &exporter{
+		parallelism:  1,
+		tableTypes:   []string{"TABLE"},
+		rowArraySize: defaultExportRowArraySize,
+		readOnlyTx:   true,
+		sink:         sink,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.parallelism < 1 {
+		return nil, fmt.Errorf("invalid export parallelism %d", e.parallelism)
+	}
+
+	start := time.Now()
+	tables, err := e.listTables(ctx, db, schema)
+	if err != nil {
+		return nil, fmt.Errorf("listing tables: %w", err)
+	}
+
+	report := &ExportReport{Tables: make([]ExportTableResult, len(tables))}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < e.parallelism && i < len(tables); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.worker(ctx, db, tables, report.Tables, jobs)
+		}()
+	}
+	for i := range tables {
+		report.Tables[i].Table = tables[i]
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var errs []error
+	for _, t := range report.Tables {
+		report.Rows += t.Rows
+		if t.Err != nil {
+			errs = append(errs, fmt.Errorf("exporting %s: %w", exportFileName(t.Table, ""), t.Err))
+		}
+	}
+	report.Duration = time.Since(start)
+	return report, errors.Join(errs...)
+}
+
+// listTables returns the tables to export, with names as stored by the database
+func (e *exporter) listTables(ctx context.Context, db *sql.DB, schema string) ([]TableInfo, error) {
+	var tables []TableInfo
+	err := withConn(ctx, db, func(c *Conn) error {
+		var err error
+		tables, err = c.tables(ctx, c.lookupIdentifier(schema), "", e.tableTypes)
+		return err
+	})
+	if err != nil || e.filter == nil {
+		return tables, err
+	}
+	kept := tables[:0]
+	for _, t := range tables {
+		if e.filter(t) {
+			kept = append(kept, t)
+		}
+	}
+	return kept, nil
+}
+
+// worker exports the tables whose indexes it receives, each on a connection from the pool
+func (e *exporter) worker(ctx context.Context, db *sql.DB, tables []TableInfo, results []ExportTableResult, jobs <-chan int) {
+	for i := range jobs {
+		start := time.Now()
+		res := &results[i]
+		if err := ctx.Err(); err != nil {
+			res.Err = err
+			continue
+		}
+		res.Err = withConn(ctx, db, func(c *Conn) error {
+			var err error
+			res.Rows, err = e.exportTable(ctx, c, tables[i])
+			return err
+		})
+		res.Duration = time.Since(start)
+	}
+}
+
+// exportTable reads one table into the sink, returning the number of rows written
+func (e *exporter) exportTable(ctx context.Context, c *Conn, table TableInfo) (int64, error) {
+	if c.rowArraySize <= 1 && e.rowArraySize > 1 {
+		saved := c.rowArraySize
+		c.rowArraySize = e.rowArraySize
+		defer func() { c.rowArraySize = saved }()
+	}
+
+	if e.readOnlyTx {
+		tx, err := c.BeginTx(ctx, driver.TxOptions{ReadOnly: true})
+		if err != nil {
+			return 0, err
+		}
+		// Nothing is written, so the transaction is always rolled back
+		defer tx.Rollback()
+	}
+
+	name := c.QuoteIdentifier(table.Name)
+	if table.Schema != "" {
+		name = c.QuoteIdentifier(table.Schema) + "." + name
+	}
+	rows, err := c.QueryContext(ctx, "SELECT * FROM "+name, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	w, err := e.sink.Begin(table, rows.Columns())
+	if err != nil {
+		return 0, err
+	}
+	dest := make([]driver.Value, len(rows.Columns()))
+	var n int64
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			w.Close(err)
+			return n, err
+		}
+		if err := w.WriteRow(dest); err != nil {
+			w.Close(err)
+			return n, err
+		}
+		n++
+	}
+	return n, w.Close(nil)
+}
@@ -0,0 +1,133 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ForeignKeyInfo describes a foreign key of a table, as reported by SQLForeignKeys
+type ForeignKeyInfo struct {
+	Name    string   // Constraint name, empty if the database does not name it
+	Columns []string // Referencing columns, in key order
+
+	// The referenced table, its primary or unique key name and its columns,
+	// matching Columns position by position
+	ReferencedSchema  string
+	ReferencedTable   string
+	ReferencedKey     string
+	ReferencedColumns []string
+
+	// UpdateRule and DeleteRule are SQL_CASCADE, SQL_RESTRICT, SQL_SET_NULL,
+	// SQL_NO_ACTION or SQL_SET_DEFAULT, or -1 if not reported
+	UpdateRule SQLSMALLINT
+	DeleteRule SQLSMALLINT
+}
+
+// foreignKeyRow is one row of an SQLForeignKeys result
+type foreignKeyRow struct {
+	pkSchema   string
+	pkTable    string
+	pkColumn   string
+	fkColumn   string
+	keySeq     int64
+	updateRule SQLSMALLINT
+	deleteRule SQLSMALLINT
+	fkName     string
+	pkName     string
+}
+
+// ForeignKeys returns the foreign keys of a table using the SQLForeignKeys
+// catalog function, with the referencing and referenced columns in key order.
+// Names are looked up and returned according to the connection's IdentifierCasePolicy.
+func (c *Conn) ForeignKeys(ctx context.Context, schema, table string) ([]ForeignKeyInfo, error) {
+	if table == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+	schema = c.lookupIdentifier(schema)
+	name := c.lookupIdentifier(table)
+
+	rows, err := c.catalogRows(ctx, func(stmt SQLHSTMT) SQLRETURN {
+		return c.odbcForeignKeys(stmt, "", "", "", "", schema, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Result columns: PKTABLE_CAT, PKTABLE_SCHEM, PKTABLE_NAME, PKCOLUMN_NAME,
+	// FKTABLE_CAT, FKTABLE_SCHEM, FKTABLE_NAME, FKCOLUMN_NAME, KEY_SEQ,
+	// UPDATE_RULE, DELETE_RULE, FK_NAME, PK_NAME, DEFERRABILITY
+	dest := make([]driver.Value, len(rows.Columns()))
+	if len(dest) < 13 {
+		return nil, fmt.Errorf("unexpected SQLForeignKeys result with %d columns", len(dest))
+	}
+	var keyRows []foreignKeyRow
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		keyRows = append(keyRows, foreignKeyRow{
+			pkSchema:   c.resultIdentifier(castToString(dest[1])),
+			pkTable:    c.resultIdentifier(castToString(dest[2])),
+			pkColumn:   c.resultIdentifier(castToString(dest[3])),
+			fkColumn:   c.resultIdentifier(castToString(dest[7])),
+			keySeq:     catalogInt64(dest[8]),
+			updateRule: catalogRule(dest[9]),
+			deleteRule: catalogRule(dest[10]),
+			fkName:     c.resultIdentifier(castToString(dest[11])),
+			pkName:     c.resultIdentifier(castToString(dest[12])),
+		})
+	}
+	return groupForeignKeyRows(keyRows), nil
+}
+
+// groupForeignKeyRows groups SQLForeignKeys rows into foreign keys, keeping the
+// driver's key order and sorting each key's columns by KEY_SEQ
+func groupForeignKeyRows(rows []foreignKeyRow) []ForeignKeyInfo {
+	var groups [][]foreignKeyRow
+	positions := make(map[string]int)
+	for _, row := range rows {
+		key := row.pkSchema + "\x00" + row.pkTable + "\x00" + row.fkName
+		pos, ok := positions[key]
+		if !ok {
+			pos = len(groups)
+			positions[key] = pos
+			groups = append(groups, nil)
+		}
+		groups[pos] = append(groups[pos], row)
+	}
+
+	var keys []ForeignKeyInfo
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool { return group[i].keySeq < group[j].keySeq })
+		first := group[0]
+		fk := ForeignKeyInfo{
+			Name:             first.fkName,
+			ReferencedSchema: first.pkSchema,
+			ReferencedTable:  first.pkTable,
+			ReferencedKey:    first.pkName,
+			UpdateRule:       first.updateRule,
+			DeleteRule:       first.deleteRule,
+		}
+		for _, row := range group {
+			fk.Columns = append(fk.Columns, row.fkColumn)
+			fk.ReferencedColumns = append(fk.ReferencedColumns, row.pkColumn)
+		}
+		keys = append(keys, fk)
+	}
+	return keys
+}
+
+// catalogRule converts an UPDATE_RULE or DELETE_RULE value, returning -1 for NULL
+func catalogRule(value driver.Value) SQLSMALLINT {
+	if value == nil {
+		return -1
+	}
+	return SQLSMALLINT(catalogInt64(value))
+}
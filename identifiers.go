@@ -0,0 +1,162 @@
+package godbc
+
+import "strings"
+
+// IdentifierCase describes how a database stores unquoted identifiers,
+// as reported by SQLGetInfo(SQL_IDENTIFIER_CASE)
+type IdentifierCase int
+
+const (
+	// IdentifierCaseUnknown means the driver did not report identifier case
+	IdentifierCaseUnknown IdentifierCase = iota
+
+	// IdentifierCaseUpper folds unquoted identifiers to upper case (Oracle, DB2, Snowflake)
+	IdentifierCaseUpper
+
+	// IdentifierCaseLower folds unquoted identifiers to lower case (PostgreSQL)
+	IdentifierCaseLower
+
+	// IdentifierCaseSensitive stores identifiers as written and compares them case-sensitively
+	IdentifierCaseSensitive
+
+	// IdentifierCaseMixed stores identifiers as written and compares them case-insensitively (SQL Server)
+	IdentifierCaseMixed
+)
+
+// IdentifierCasePolicy specifies how metadata helpers treat identifier case
+type IdentifierCasePolicy int
+
+const (
+	// IdentifierCasePolicyAuto folds unquoted names passed to metadata helpers
+	// (TableExists, PrimaryKeyColumns, NewTableReader) the way the database does,
+	// so "orders" finds ORDERS on Oracle. Names in results are returned as stored.
+	IdentifierCasePolicyAuto IdentifierCasePolicy = iota
+
+	// IdentifierCasePolicyPreserve passes names to catalog functions in the
+	// case given, without folding; quoted names are unquoted
+	IdentifierCasePolicyPreserve
+
+	// IdentifierCasePolicyLower folds input names like Auto and returns names in
+	// metadata results in lower case
+	IdentifierCasePolicyLower
+
+	// IdentifierCasePolicyUpper folds input names like Auto and returns names in
+	// metadata results in upper case
+	IdentifierCasePolicyUpper
+)
+
+// detectIdentifierRules queries the driver for identifier case and quote character
+func (c *Conn) detectIdentifierRules() {
+	if identCase, ret := GetInfoInt(c.dbc, SQL_IDENTIFIER_CASE); IsSuccess(ret) {
+		switch identCase {
+		case SQL_IC_UPPER:
+			c.identifierCase = IdentifierCaseUpper
+		case SQL_IC_LOWER:
+			c.identifierCase = IdentifierCaseLower
+		case SQL_IC_SENSITIVE:
+			c.identifierCase = IdentifierCaseSensitive
+		case SQL_IC_MIXED:
+			c.identifierCase = IdentifierCaseMixed
+		}
+	}
+
+	if quote, ok := c.getInfoString(SQL_IDENTIFIER_QUOTE_CHAR); ok && quote != "" {
+		c.identifierQuote = quote
+	}
+}
+
+// IdentifierCase returns how the database stores unquoted identifiers
+func (c *Conn) IdentifierCase() IdentifierCase {
+	return c.identifierCase
+}
+
+// NormalizeIdentifier returns an identifier as the database stores it.
+// Quoted identifiers ("Name", [Name] or `Name`) are unquoted and kept as written;
+// unquoted identifiers are folded to upper or lower case if the database folds them.
+func (c *Conn) NormalizeIdentifier(name string) string {
+	if unquoted, ok := unquoteIdentifier(name); ok {
+		return unquoted
+	}
+	switch c.identifierCase {
+	case IdentifierCaseUpper:
+		return strings.ToUpper(name)
+	case IdentifierCaseLower:
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
+
+// QuoteIdentifier quotes an identifier with the driver's quote character
+// (SQL_IDENTIFIER_QUOTE_CHAR), doubling any embedded quote characters.
+// If the driver does not support quoted identifiers, name is returned unchanged.
+func (c *Conn) QuoteIdentifier(name string) string {
+	quote := c.identifierQuote
+	if quote == "" {
+		quote = `"`
+	}
+	if quote == " " {
+		return name
+	}
+	closing := quote
+	if quote == "[" {
+		closing = "]"
+	}
+	return quote + strings.ReplaceAll(name, closing, closing+closing) + closing
+}
+
+// lookupIdentifier prepares a name for a catalog function according to the policy
+func (c *Conn) lookupIdentifier(name string) string {
+	if name == "" {
+		return name
+	}
+	if c.identifierCasePolicy == IdentifierCasePolicyPreserve {
+		// Catalog functions match names as stored, without quotes
+		unquoted, _ := unquoteIdentifier(name)
+		return unquoted
+	}
+	return c.NormalizeIdentifier(name)
+}
+
+// resultIdentifier formats a name returned by a catalog function according to the policy
+func (c *Conn) resultIdentifier(name string) string {
+	switch c.identifierCasePolicy {
+	case IdentifierCasePolicyLower:
+		return strings.ToLower(name)
+	case IdentifierCasePolicyUpper:
+		return strings.ToUpper(name)
+	default:
+		return name
+	}
+}
+
+// identifiersEqual compares two stored identifiers using the database's case rules
+func (c *Conn) identifiersEqual(a, b string) bool {
+	if c.identifierCase == IdentifierCaseSensitive {
+		return a == b
+	}
+	return strings.EqualFold(a, b)
+}
+
+// unquoteIdentifier removes identifier quotes from a fully quoted name
+func unquoteIdentifier(name string) (string, bool) {
+	if len(name) < 2 {
+		return name, false
+	}
+	var closing byte
+	switch name[0] {
+	case '"':
+		closing = '"'
+	case '[':
+		closing = ']'
+	case '`':
+		closing = '`'
+	default:
+		return name, false
+	}
+	if name[len(name)-1] != closing {
+		return name, false
+	}
+	inner := name[1 : len(name)-1]
+	return strings.ReplaceAll(inner, string(closing)+string(closing), string(closing)), true
+}
@@ -0,0 +1,146 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// IndexInfo describes an index on a table, as reported by SQLStatistics
+type IndexInfo struct {
+	Name        string
+	Qualifier   string      // Catalog or schema qualifying the index name, if any
+	Unique      bool        // Whether the index enforces uniqueness
+	Type        SQLSMALLINT // SQL_INDEX_CLUSTERED, SQL_INDEX_HASHED or SQL_INDEX_OTHER
+	Columns     []IndexColumn
+	Cardinality int64  // Number of unique values in the index, or 0 if not reported
+	Filter      string // Filter condition of a filtered/partial index, if reported
+}
+
+// IndexColumn is a column of an index, in key order
+type IndexColumn struct {
+	Name       string // Column name; empty for expression-based key parts
+	Descending bool
+}
+
+// indexRow is one row of an SQLStatistics result
+type indexRow struct {
+	qualifier   string
+	name        string
+	nonUnique   bool
+	indexType   SQLSMALLINT
+	ordinal     int64
+	column      string
+	ascOrDesc   string
+	cardinality int64
+	filter      string
+}
+
+// Indexes returns the indexes of a table using the SQLStatistics catalog function,
+// with their columns in key order, uniqueness and cardinality. Table statistics
+// rows and unnamed indexes are omitted. Names are looked up and returned
+// according to the connection's IdentifierCasePolicy.
+func (c *Conn) Indexes(ctx context.Context, schema, table string) ([]IndexInfo, error) {
+	if table == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+	schema = c.lookupIdentifier(schema)
+	name := c.lookupIdentifier(table)
+
+	rows, err := c.catalogRows(ctx, func(stmt SQLHSTMT) SQLRETURN {
+		return c.odbcStatistics(stmt, "", schema, name, SQL_INDEX_ALL, SQL_QUICK)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Result columns: TABLE_CAT, TABLE_SCHEM, TABLE_NAME, NON_UNIQUE, INDEX_QUALIFIER,
+	// INDEX_NAME, TYPE, ORDINAL_POSITION, COLUMN_NAME, ASC_OR_DESC, CARDINALITY,
+	// PAGES, FILTER_CONDITION
+	dest := make([]driver.Value, len(rows.Columns()))
+	if len(dest) < 13 {
+		return nil, fmt.Errorf("unexpected SQLStatistics result with %d columns", len(dest))
+	}
+	var indexRows []indexRow
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		indexType := SQLSMALLINT(catalogInt64(dest[6]))
+		if indexType == SQL_TABLE_STAT || dest[5] == nil {
+			continue
+		}
+		indexRows = append(indexRows, indexRow{
+			qualifier:   castToString(dest[4]),
+			name:        castToString(dest[5]),
+			nonUnique:   isTruthy(dest[3]),
+			indexType:   indexType,
+			ordinal:     catalogInt64(dest[7]),
+			column:      c.resultIdentifier(castToString(dest[8])),
+			ascOrDesc:   castToString(dest[9]),
+			cardinality: catalogInt64(dest[10]),
+			filter:      castToString(dest[12]),
+		})
+	}
+
+	indexes := groupIndexRows(indexRows)
+	for i := range indexes {
+		indexes[i].Name = c.resultIdentifier(indexes[i].Name)
+	}
+	return indexes, nil
+}
+
+// groupIndexRows groups SQLStatistics rows into indexes, keeping the driver's
+// index order and sorting each index's columns by ordinal position
+func groupIndexRows(rows []indexRow) []IndexInfo {
+	var indexes []IndexInfo
+	ordinals := make(map[string][]int64)
+	positions := make(map[string]int)
+	for _, row := range rows {
+		key := row.qualifier + "\x00" + row.name
+		pos, ok := positions[key]
+		if !ok {
+			pos = len(indexes)
+			positions[key] = pos
+			indexes = append(indexes, IndexInfo{
+				Name:        row.name,
+				Qualifier:   row.qualifier,
+				Unique:      !row.nonUnique,
+				Type:        row.indexType,
+				Cardinality: row.cardinality,
+				Filter:      row.filter,
+			})
+		}
+		indexes[pos].Columns = append(indexes[pos].Columns, IndexColumn{
+			Name:       row.column,
+			Descending: strings.EqualFold(row.ascOrDesc, "D"),
+		})
+		ordinals[key] = append(ordinals[key], row.ordinal)
+	}
+
+	for key, pos := range positions {
+		cols, ords := indexes[pos].Columns, ordinals[key]
+		sort.Sort(indexColumnsByOrdinal{cols, ords})
+	}
+	return indexes
+}
+
+// indexColumnsByOrdinal sorts index columns by their ordinal positions
+type indexColumnsByOrdinal struct {
+	columns  []IndexColumn
+	ordinals []int64
+}
+
+func (s indexColumnsByOrdinal) Len() int           { return len(s.columns) }
+func (s indexColumnsByOrdinal) Less(i, j int) bool { return s.ordinals[i] < s.ordinals[j] }
+func (s indexColumnsByOrdinal) Swap(i, j int) {
+	s.columns[i], s.columns[j] = s.columns[j], s.columns[i]
+	s.ordinals[i], s.ordinals[j] = s.ordinals[j], s.ordinals[i]
+}
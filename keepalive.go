@@ -0,0 +1,112 @@
+package godbc
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// keepAlivePinger pings the idle connections of a Connector on a background
+// ticker, so firewalls and load balancers do not drop them while they sit in
+// the database/sql pool. Connections are added when they connect and removed
+// when they close; the ticker runs only while connections are registered.
+type keepAlivePinger struct {
+	interval time.Duration
+
+	mu    sync.Mutex
+	conns map[*Conn]struct{}
+	stop  chan struct{} // closed to stop the ticker goroutine, nil when it is not running
+}
+
+// newKeepAlivePinger creates a pinger for connections idle longer than interval
+func newKeepAlivePinger(interval time.Duration) *keepAlivePinger {
+	return &keepAlivePinger{interval: interval, conns: make(map[*Conn]struct{})}
+}
+
+// add registers a connection, starting the ticker for the first one
+func (p *keepAlivePinger) add(c *Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.conns[c] = struct{}{}
+	if p.stop == nil {
+		p.stop = make(chan struct{})
+		go p.run(p.stop)
+	}
+}
+
+// remove unregisters a connection, stopping the ticker after the last one
+func (p *keepAlivePinger) remove(c *Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.conns, c)
+	if len(p.conns) == 0 && p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+}
+
+// run checks the connections twice per interval until stopped
+func (p *keepAlivePinger) run(stop chan struct{}) {
+	tick := p.interval / 2
+	if tick <= 0 {
+		tick = p.interval
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			p.pingIdle(now)
+		}
+	}
+}
+
+// pingIdle pings every connection that has been idle for at least the interval
+func (p *keepAlivePinger) pingIdle(now time.Time) {
+	p.mu.Lock()
+	conns := make([]*Conn, 0, len(p.conns))
+	for c := range p.conns {
+		conns = append(conns, c)
+	}
+	p.mu.Unlock()
+
+	for _, c := range conns {
+		c.keepAlivePing(now, p.interval)
+	}
+}
+
+// keepAlivePing pings the connection if it is idle in the pool and nothing has
+// been sent on it for at least interval. A connection that fails with a
+// connection error is marked dead, so database/sql discards it at checkout
+// instead of handing it to the next query.
+func (c *Conn) keepAlivePing(now time.Time, interval time.Duration) {
+	// A locked connection is in use, so it does not need a ping
+	if !c.mu.TryLock() {
+		return
+	}
+	defer c.mu.Unlock()
+	if c.closed || c.dead || c.idleSince.IsZero() || now.Sub(c.idleSince) < interval {
+		return
+	}
+
+	op, err := lifecycle.begin()
+	if err != nil {
+		return
+	}
+	defer op.end()
+
+	if err := c.pingLocked(context.Background()); err != nil {
+		c.dead = true
+		if c.logger != nil {
+			c.logger.LogAttrs(context.Background(), slog.LevelWarn, "odbc keepalive ping failed; connection will be discarded",
+				slog.String("dbms", c.dbType),
+				slog.String("error", err.Error()))
+		}
+		return
+	}
+	// Restart the idle period, so the next ping is due one interval from now
+	c.idleSince = now
+}
@@ -0,0 +1,66 @@
+package godbc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+)
+
+// sqlLenSize is the size in bytes of SQLLEN and SQLULEN in this build
+const sqlLenSize = int(unsafe.Sizeof(SQLLEN(0)))
+
+// lengthProbeSentinel pre-fills the probe buffer so untouched bytes can be detected
+const lengthProbeSentinel = 0xA5
+
+// checkLengthWidth verifies that the driver manager uses the same SQLLEN width
+// as this build. A mismatch corrupts indicators and bound arrays, so it is
+// reported as an error rather than left to fail in unpredictable ways.
+func (c *Conn) checkLengthWidth() error {
+	var stmtHandle SQLHSTMT
+	if !IsSuccess(AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(c.dbc), (*SQLHANDLE)(&stmtHandle))) {
+		return nil
+	}
+	defer FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+
+	width := probeLengthWidth(stmtHandle)
+	if width == 0 || width == sqlLenSize {
+		return nil
+	}
+	hint := "rebuild with -tags godbc_sqllen32"
+	if width > sqlLenSize {
+		hint = "rebuild without the godbc_sqllen32 tag"
+	}
+	return fmt.Errorf("ODBC driver manager uses %d-bit SQLLEN but godbc was built with %d-bit SQLLEN; %s",
+		width*8, sqlLenSize*8, hint)
+}
+
+// probeLengthWidth reports the SQLULEN width, in bytes, used by the driver manager.
+// It reads SQL_ATTR_ROW_ARRAY_SIZE (an SQLULEN that defaults to 1) into a buffer
+// large enough for either width and checks how many bytes were written.
+// It returns 0 if the width cannot be determined.
+func probeLengthWidth(stmt SQLHSTMT) int {
+	var buf [8]byte
+	for i := range buf {
+		buf[i] = lengthProbeSentinel
+	}
+	if !IsSuccess(GetStmtAttr(stmt, SQL_ATTR_ROW_ARRAY_SIZE, uintptr(unsafe.Pointer(&buf[0])), 0, nil)) {
+		return 0
+	}
+	return lengthWidth(buf)
+}
+
+// lengthWidth interprets a probe buffer holding the value 1 as a 4 or 8 byte integer
+func lengthWidth(buf [8]byte) int {
+	if binary.NativeEndian.Uint64(buf[:]) == 1 {
+		return 8
+	}
+	if binary.NativeEndian.Uint32(buf[:4]) == 1 {
+		for _, b := range buf[4:] {
+			if b != lengthProbeSentinel {
+				return 0
+			}
+		}
+		return 4
+	}
+	return 0
+}
@@ -0,0 +1,262 @@
+package godbc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"unsafe"
+)
+
+// lobChunkSize is the SQLGetData buffer size used by LOBReader, a multiple of every SQLWCHAR size
+const lobChunkSize = 64 << 10
+
+// ErrLOBReaderStale is returned by LOBReader.Read after the rows have moved to
+// another row or result set, or were closed, since the value can no longer be read
+var ErrLOBReaderStale = errors.New("godbc: LOB reader used after its row was left")
+
+// lobStreamingKey is the context key for LOB streaming
+type lobStreamingKey struct{}
+
+// WithLOBStreaming returns a context that makes queries executed with it return
+// large object columns as *LOBReader instead of string or []byte, so multi-GB
+// values can be copied without holding them in memory. It applies to
+// LONGVARCHAR, WLONGVARCHAR and LONGVARBINARY columns (and DB2 LOB and SQL Server
+// XML columns), as well as character and binary columns with no usable size
+// such as VARCHAR(MAX), when they come last in the select list. Select other
+// columns before them: drivers generally return column data in order only.
+// Drivers that do not report SQL_GD_ANY_ORDER in SQL_GETDATA_EXTENSIONS stream
+// only the last column; large object columns before it are read whole.
+//
+// A LOBReader can be read until the next call to Rows.Next. Scan it into a
+// *io.Reader or **godbc.LOBReader; NULL values scan as nil.
+//
+// Example:
+//
+//	ctx = godbc.WithLOBStreaming(ctx)
+//	rows, err := db.QueryContext(ctx, "SELECT name, content FROM documents")
+//	for rows.Next() {
+//	    var name string
+//	    var content io.Reader
+//	    if err := rows.Scan(&name, &content); err != nil {
+//	        return err
+//	    }
+//	    if content != nil {
+//	        _, err = io.Copy(w, content)
+//	    }
+//	}
+func WithLOBStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lobStreamingKey{}, true)
+}
+
+// lobStreamingFromContext reports whether ctx enables LOB streaming
+func lobStreamingFromContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	enabled, _ := ctx.Value(lobStreamingKey{}).(bool)
+	return enabled
+}
+
+// lobCType returns the C type a column is streamed as, or 0 if it is not a large object
+func lobCType(colType SQLSMALLINT, sizeUnknown bool) SQLSMALLINT {
+	switch colType {
+	case SQL_LONGVARBINARY, SQL_DB2_BLOB:
+		return SQL_C_BINARY
+	case SQL_LONGVARCHAR:
+		return SQL_C_CHAR
+	case SQL_WLONGVARCHAR, SQL_SS_XML, SQL_DB2_CLOB, SQL_DB2_DBCLOB:
+		return SQL_C_WCHAR
+	}
+	if !sizeUnknown {
+		return 0
+	}
+	switch colType {
+	case SQL_BINARY, SQL_VARBINARY:
+		return SQL_C_BINARY
+	case SQL_CHAR, SQL_VARCHAR:
+		return SQL_C_CHAR
+	case SQL_WCHAR, SQL_WVARCHAR:
+		return SQL_C_WCHAR
+	}
+	return 0
+}
+
+// lobColumnTypes returns the streaming C type of each column, or nil if none is
+// streamed. Only the trailing run of large object columns is streamed, so the
+// readers are the last values read from each row. Unless the driver reads
+// columns in any order, only the last column is streamed, since the readers
+// of a run are read after every column of the row has been probed.
+func lobColumnTypes(colTypes []SQLSMALLINT, sizeUnknown []bool, anyOrder bool) []SQLSMALLINT {
+	var types []SQLSMALLINT
+	for i := len(colTypes) - 1; i >= 0 && (anyOrder || types == nil); i-- {
+		cType := lobCType(colTypes[i], sizeUnknown[i])
+		if cType == 0 {
+			break
+		}
+		if types == nil {
+			types = make([]SQLSMALLINT, len(colTypes))
+		}
+		types[i] = cType
+	}
+	return types
+}
+
+// setLOBStreaming enables streaming of the large object columns of each result set
+func (r *Rows) setLOBStreaming(enabled bool) {
+	r.lobStreaming = enabled
+	r.lobTypes = nil
+	if enabled {
+		r.lobTypes = lobColumnTypes(r.colTypes, r.sizeUnknown, r.getDataAnyOrder())
+	}
+	if r.wideFetch() {
+		for i, cType := range r.lobTypes {
+			if cType == SQL_C_CHAR {
+				r.lobTypes[i] = SQL_C_WCHAR
+			}
+		}
+	}
+}
+
+// getDataAnyOrder reports whether the connection's driver reads the columns
+// of a row in any order with SQLGetData
+func (r *Rows) getDataAnyOrder() bool {
+	if r.stmt == nil || r.stmt.conn == nil {
+		return true
+	}
+	return !r.stmt.conn.getDataInOrder
+}
+
+// LOBReader streams a large object column value with repeated SQLGetData
+// calls. Character data is returned as UTF-8. See WithLOBStreaming.
+type LOBReader struct {
+	rows   *Rows
+	colNum SQLUSMALLINT
+	cType  SQLSMALLINT
+	row    uint64 // Rows.rowGen of the row the value belongs to
+	size   int64
+
+	buf       []byte
+	pending   []byte // data fetched but not yet returned
+	surrogate uint16 // UTF-16 high surrogate split from its pair at a chunk boundary
+	eof       bool
+}
+
+// newLOBReader returns a reader for a column of the current row, or nil for NULL.
+// A zero-length SQLGetData call reports NULL and the length without consuming data.
+func (r *Rows) newLOBReader(colNum SQLUSMALLINT, cType SQLSMALLINT) (interface{}, error) {
+	var probe [4]byte
+	var indicator SQLLEN
+	ret := r.getData(colNum, cType, uintptr(unsafe.Pointer(&probe[0])), 0, &indicator)
+	if ret != SQL_NO_DATA && !IsSuccess(ret) {
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+	}
+	if ret != SQL_NO_DATA && isNullIndicator(indicator) {
+		return nil, nil
+	}
+
+	l := &LOBReader{rows: r, colNum: colNum, cType: cType, row: r.rowGen, size: -1}
+	if ret == SQL_NO_DATA || (ret == SQL_SUCCESS && indicator == 0) {
+		l.eof, l.size = true, 0
+	} else if indicator >= 0 {
+		l.size = int64(indicator)
+	}
+	return l, nil
+}
+
+// Size returns the length of the value in bytes as reported by the driver, or
+// -1 if the driver does not know it in advance. For character columns the
+// length is in the driver's encoding, which may differ from the UTF-8 read.
+func (l *LOBReader) Size() int64 {
+	return l.size
+}
+
+// Read reads the next chunk of the value. It returns ErrLOBReaderStale once the
+// rows have advanced past the value's row.
+func (l *LOBReader) Read(p []byte) (int, error) {
+	for len(l.pending) == 0 {
+		if l.eof {
+			return 0, io.EOF
+		}
+		if err := l.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, l.pending)
+	l.pending = l.pending[n:]
+	return n, nil
+}
+
+// fill reads the next chunk of the value into pending
+func (l *LOBReader) fill() error {
+	r := l.rows
+	if r.closed || r.rowGen != l.row {
+		return ErrLOBReaderStale
+	}
+	if l.buf == nil {
+		l.buf = make([]byte, lobChunkSize)
+	}
+
+	var indicator SQLLEN
+	ret := r.getData(l.colNum, l.cType, uintptr(unsafe.Pointer(&l.buf[0])), SQLLEN(len(l.buf)), &indicator)
+	if ret == SQL_NO_DATA || (IsSuccess(ret) && isNullIndicator(indicator)) {
+		l.eof = true
+		l.flushSurrogate()
+		return nil
+	}
+	if !IsSuccess(ret) {
+		return r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+	}
+
+	// A truncated chunk fills the buffer except for the terminator
+	n := len(l.buf)
+	if l.cType != SQL_C_BINARY {
+		n -= terminatorSize(l.cType)
+	}
+	if ret == SQL_SUCCESS {
+		// The rest of the value fit in the buffer
+		l.eof = true
+		if indicator >= 0 && int(indicator) < n {
+			n = int(indicator)
+		}
+	}
+
+	if l.cType == SQL_C_WCHAR {
+		l.pending = l.decodeWide(l.buf[:n])
+	} else {
+		l.pending = l.buf[:n]
+	}
+	return nil
+}
+
+// decodeWide converts a chunk of SQLWCHAR data to UTF-8, holding back a UTF-16
+// high surrogate at the end of a chunk until its pair arrives
+func (l *LOBReader) decodeWide(data []byte) []byte {
+	if wcharSize() == 4 {
+		if len(data) < 4 {
+			return nil
+		}
+		return []byte(utf32ToString(unsafe.Slice((*uint32)(unsafe.Pointer(&data[0])), len(data)/4)))
+	}
+
+	units := make([]uint16, 0, len(data)/2+1)
+	if l.surrogate != 0 {
+		units = append(units, l.surrogate)
+		l.surrogate = 0
+	}
+	if len(data) >= 2 {
+		units = append(units, unsafe.Slice((*uint16)(unsafe.Pointer(&data[0])), len(data)/2)...)
+	}
+	if n := len(units); n > 0 && !l.eof && units[n-1] >= 0xD800 && units[n-1] < 0xDC00 {
+		l.surrogate = units[n-1]
+		units = units[:n-1]
+	}
+	return []byte(utf16ToString(units))
+}
+
+// flushSurrogate moves a high surrogate held at the end of the value to pending
+func (l *LOBReader) flushSurrogate() {
+	if l.surrogate != 0 {
+		l.pending = []byte(utf16ToString([]uint16{l.surrogate}))
+		l.surrogate = 0
+	}
+}
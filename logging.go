@@ -0,0 +1,36 @@
+package godbc
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logDiagnostics logs the diagnostic records of a call that returned
+// SQL_SUCCESS_WITH_INFO, which are otherwise discarded. General warnings
+// (SQLSTATE 01000), which drivers use for informational messages such as a
+// changed database context, are logged at debug level; other warnings, such
+// as truncation (01004) or a changed option value (01S02), at warn level.
+func logDiagnostics(ctx context.Context, logger *slog.Logger, ret SQLRETURN, handleType SQLSMALLINT, handle SQLHANDLE, op string) {
+	if logger == nil || ret != SQL_SUCCESS_WITH_INFO {
+		return
+	}
+	for _, rec := range GetDiagRecords(handleType, handle) {
+		level := slog.LevelWarn
+		if rec.SQLState == "01000" {
+			level = slog.LevelDebug
+		}
+		logger.LogAttrs(ctx, level, "odbc diagnostic", logAttrs(ctx,
+			slog.String("op", op),
+			slog.String("sqlstate", rec.SQLState),
+			slog.Int("native_error", int(rec.NativeError)),
+			slog.String("message", rec.Message))...)
+	}
+}
+
+// logInfo logs the diagnostics of a statement call that returned SQL_SUCCESS_WITH_INFO
+func (c *Conn) logInfo(ctx context.Context, ret SQLRETURN, stmt SQLHSTMT, op string) {
+	if c == nil || c.logger == nil {
+		return
+	}
+	logDiagnostics(ctx, c.logger, ret, SQL_HANDLE_STMT, SQLHANDLE(stmt), op)
+}
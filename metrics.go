@@ -0,0 +1,83 @@
+package godbc
+
+import (
+	"time"
+)
+
+// MetricsOp identifies the operation a MetricsCollector callback reports on
+type MetricsOp string
+
+const (
+	MetricsConnect MetricsOp = "connect"
+	MetricsPrepare MetricsOp = "prepare"
+	MetricsExec    MetricsOp = "exec"
+	MetricsQuery   MetricsOp = "query"
+	MetricsFetch   MetricsOp = "fetch"
+)
+
+// MetricsCollector receives driver events, so applications can maintain
+// Prometheus or OpenTelemetry instruments without wrapping every call site.
+// Callbacks run synchronously on the goroutine making the call, possibly for
+// many connections at once, so implementations must be safe for concurrent
+// use and return quickly. The query is the statement text as the application
+// passed it; normalize it before using it as a label. Embed NopMetrics to
+// implement only some callbacks.
+type MetricsCollector interface {
+	// Connect is called when a connection is established, with the time spent
+	// in SQLDriverConnect
+	Connect(d time.Duration)
+
+	// Disconnect is called when a connection that was reported to Connect is closed
+	Disconnect()
+
+	// Prepare is called when a statement is prepared, with the time spent in SQLPrepare
+	Prepare(query string, d time.Duration)
+
+	// Exec is called when a statement executes successfully, with op MetricsExec
+	// or MetricsQuery. rowsAffected is -1 for queries that return rows.
+	Exec(op MetricsOp, query string, d time.Duration, rowsAffected int64)
+
+	// Fetch is called when the rows of a query are closed, with the number of
+	// rows fetched and the time spent fetching them
+	Fetch(query string, rows int64, d time.Duration)
+
+	// Error is called when an operation fails, including by context cancellation
+	Error(op MetricsOp, query string, err error)
+}
+
+// NopMetrics implements MetricsCollector with callbacks that do nothing
+type NopMetrics struct{}
+
+func (NopMetrics) Connect(time.Duration)                        {}
+func (NopMetrics) Disconnect()                                  {}
+func (NopMetrics) Prepare(string, time.Duration)                {}
+func (NopMetrics) Exec(MetricsOp, string, time.Duration, int64) {}
+func (NopMetrics) Fetch(string, int64, time.Duration)           {}
+func (NopMetrics) Error(MetricsOp, string, error)               {}
+
+// observePrepare reports a prepared statement, or the error preparing it
+func (c *Conn) observePrepare(query string, d time.Duration, err error) {
+	if c == nil || c.metrics == nil {
+		return
+	}
+	if err != nil {
+		c.metrics.Error(MetricsPrepare, query, err)
+		return
+	}
+	c.metrics.Prepare(query, d)
+}
+
+// observeExec reports a successful execution
+func (c *Conn) observeExec(op MetricsOp, query string, d time.Duration, rowsAffected int64) {
+	if c != nil && c.metrics != nil {
+		c.metrics.Exec(op, query, d, rowsAffected)
+	}
+}
+
+// observeError reports a failed operation and returns err
+func (c *Conn) observeError(op MetricsOp, query string, err error) error {
+	if c != nil && c.metrics != nil && err != nil {
+		c.metrics.Error(op, query, err)
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+)
+
+// maxBatchResults bounds the number of statement results ExecMulti reads, in
+// case a driver never reports the end of the batch
+const maxBatchResults = 1 << 16
+
+// StatementResult is the outcome of one statement of a batch run with ExecMulti
+type StatementResult struct {
+	// RowsAffected is the row count the driver reports for the statement, or
+	// -1 if it reports none (as for DDL and most SELECT statements)
+	RowsAffected int64
+
+	// Diagnostics holds the warnings and informational messages the statement
+	// produced, such as SQL Server PRINT output
+	Diagnostics []DiagRecord
+
+	// Err is the error of a statement that failed. Whether later statements of
+	// the batch still run depends on the database.
+	Err error
+}
+
+// ExecMulti executes a batch of statements separated by the database's
+// delimiter, e.g. "INSERT ...; UPDATE ...", and returns a result per statement
+// by walking the results with SQLMoreResults. Unlike ExecContext, whose
+// Result.AllRowsAffected ends at the first failed statement, it reports the
+// error of each statement and keeps reading the results after it. Result
+// sets produced by statements in the batch are discarded. Statements that
+// produce no result, such as those run with SET NOCOUNT ON on SQL Server, have
+// no entry.
+//
+// The returned error is the first statement error, also reported in the
+// results, or the error that prevented the batch from running.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    results, err := dc.(*godbc.Conn).ExecMulti(ctx, "INSERT INTO t VALUES (1); UPDATE t SET x = 2")
+//	    for i, r := range results {
+//	        fmt.Println(i, r.RowsAffected, r.Err)
+//	    }
+//	    return err
+//	})
+func (c *Conn) ExecMulti(ctx context.Context, query string) (_ []StatementResult, err error) {
+	op, err := lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer op.end()
+
+	c.applyWorkloadHint(ctx)
+	span := c.startSpan(ctx, SpanExec, query)
+	defer func() { endSpan(span, err) }()
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, driver.ErrBadConn
+	}
+	var stmtHandle SQLHSTMT
+	ret := AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(c.dbc), (*SQLHANDLE)(&stmtHandle))
+	if !IsSuccess(ret) {
+		err := c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.mu.Unlock()
+	defer FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+	c.applyStmtAttrs(ctx, stmtHandle)
+
+	if secs := c.queryTimeoutSecs(ctx); secs > 0 {
+		SetStmtAttr(stmtHandle, SQL_ATTR_QUERY_TIMEOUT, secs, 0)
+	}
+	if ctx.Done() != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				Cancel(stmtHandle)
+			case <-done:
+			}
+		}()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	ret = c.execDirect(stmtHandle, c.tagQuery(ctx, query))
+	results, err := c.batchResults(ctx, stmtHandle, ret)
+	executeTime := time.Since(start)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return results, c.observeError(MetricsExec, query, ctxErr)
+	}
+
+	var total int64
+	for _, r := range results {
+		if r.RowsAffected > 0 {
+			total += r.RowsAffected
+		}
+	}
+	c.observeExec(MetricsExec, query, executeTime, total)
+	if span != nil {
+		span.SetAttributes(Attribute{Key: AttrRowsAffected, Value: total})
+	}
+	statsCollectorFromContext(ctx).add(QueryStats{Queries: 1, ExecuteTime: executeTime})
+
+	if err != nil {
+		return results, c.observeError(MetricsExec, query, err)
+	}
+	return results, nil
+}
+
+// batchResults reads the result of each statement of an executed batch. ret
+// is the return code of the execution, which describes the first statement.
+// A statement error is recorded and the remaining results are still read, so
+// statements the database ran after it are reported; the first such error is
+// returned. A function sequence error (HY010) means the handle has no further
+// results and ends the batch.
+func (c *Conn) batchResults(ctx context.Context, stmt SQLHSTMT, ret SQLRETURN) ([]StatementResult, error) {
+	var results []StatementResult
+	var firstErr error
+	for len(results) < maxBatchResults {
+		if !IsSuccess(ret) && ret != SQL_NO_DATA {
+			stmtErr := c.newError(SQL_HANDLE_STMT, SQLHANDLE(stmt))
+			if len(results) > 0 && IsFunctionSequenceError(stmtErr) {
+				break
+			}
+			results = append(results, StatementResult{RowsAffected: -1, Err: stmtErr})
+			if firstErr == nil {
+				firstErr = stmtErr
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		} else {
+			result := StatementResult{RowsAffected: -1}
+			if ret == SQL_SUCCESS_WITH_INFO {
+				result.Diagnostics = GetDiagRecords(SQL_HANDLE_STMT, SQLHANDLE(stmt))
+			}
+			var rowCount SQLLEN
+			if IsSuccess(RowCount(stmt, &rowCount)) {
+				result.RowsAffected = int64(rowCount)
+			}
+			results = append(results, result)
+		}
+
+		ret = MoreResults(stmt)
+		if ret == SQL_NO_DATA {
+			break
+		}
+	}
+	return results, firstErr
+}
+
+// rowCounts returns the row count of the current result of an executed
+// statement, first, followed by those of the later results of a batch. It
+// stops at the end of the batch or at the first result that fails.
+func rowCounts(stmt SQLHSTMT, first int64) []int64 {
+	counts := []int64{first}
+	for len(counts) < maxBatchResults && IsSuccess(MoreResults(stmt)) {
+		var rowCount SQLLEN
+		if !IsSuccess(RowCount(stmt, &rowCount)) {
+			rowCount = -1
+		}
+		counts = append(counts, int64(rowCount))
+	}
+	return counts
+}
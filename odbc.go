@@ -35,6 +35,8 @@ var (
 	sqlColAttribute   func(stmt SQLHSTMT, colNum SQLUSMALLINT, fieldId SQLUSMALLINT, charAttr uintptr, bufferLen SQLSMALLINT, strLen *SQLSMALLINT, numAttr *SQLLEN) SQLRETURN
 	sqlBindCol        func(stmt SQLHSTMT, colNum SQLUSMALLINT, targetType SQLSMALLINT, targetValue uintptr, bufferLen SQLLEN, strLenOrInd *SQLLEN) SQLRETURN
 	sqlBindParameter  func(stmt SQLHSTMT, paramNum SQLUSMALLINT, ioType SQLSMALLINT, valueType SQLSMALLINT, paramType SQLSMALLINT, colSize SQLULEN, decDigits SQLSMALLINT, paramValue uintptr, bufferLen SQLLEN, strLenOrInd *SQLLEN) SQLRETURN
+	sqlParamData      func(stmt SQLHSTMT, value *uintptr) SQLRETURN
+	sqlPutData        func(stmt SQLHSTMT, data uintptr, strLenOrInd SQLLEN) SQLRETURN
 	sqlFetch          func(stmt SQLHSTMT) SQLRETURN
 	sqlFetchScroll    func(stmt SQLHSTMT, fetchOrientation SQLSMALLINT, fetchOffset SQLLEN) SQLRETURN
 	sqlGetData        func(stmt SQLHSTMT, colNum SQLUSMALLINT, targetType SQLSMALLINT, targetValue uintptr, bufferLen SQLLEN, strLenOrInd *SQLLEN) SQLRETURN
@@ -50,10 +52,144 @@ var (
 	sqlMoreResults    func(stmt SQLHSTMT) SQLRETURN
 	sqlSetStmtAttr    func(stmt SQLHSTMT, attribute SQLINTEGER, value uintptr, stringLength SQLINTEGER) SQLRETURN
 	sqlGetStmtAttr    func(stmt SQLHSTMT, attribute SQLINTEGER, value uintptr, bufferLength SQLINTEGER, stringLength *SQLINTEGER) SQLRETURN
+	sqlSetDescField   func(desc SQLHDESC, recNum SQLSMALLINT, fieldId SQLSMALLINT, value uintptr, bufferLength SQLINTEGER) SQLRETURN
 	sqlTables         func(stmt SQLHSTMT, catalogName *byte, nameLen1 SQLSMALLINT, schemaName *byte, nameLen2 SQLSMALLINT, tableName *byte, nameLen3 SQLSMALLINT, tableType *byte, nameLen4 SQLSMALLINT) SQLRETURN
 	sqlColumns        func(stmt SQLHSTMT, catalogName *byte, nameLen1 SQLSMALLINT, schemaName *byte, nameLen2 SQLSMALLINT, tableName *byte, nameLen3 SQLSMALLINT, columnName *byte, nameLen4 SQLSMALLINT) SQLRETURN
+	sqlSetCursorName  func(stmt SQLHSTMT, cursorName *byte, nameLength SQLSMALLINT) SQLRETURN
+	sqlGetCursorName  func(stmt SQLHSTMT, cursorName *byte, bufferLength SQLSMALLINT, nameLength *SQLSMALLINT) SQLRETURN
+	sqlPrimaryKeys    func(stmt SQLHSTMT, catalogName *byte, nameLen1 SQLSMALLINT, schemaName *byte, nameLen2 SQLSMALLINT, tableName *byte, nameLen3 SQLSMALLINT) SQLRETURN
+	sqlStatistics     func(stmt SQLHSTMT, catalogName *byte, nameLen1 SQLSMALLINT, schemaName *byte, nameLen2 SQLSMALLINT, tableName *byte, nameLen3 SQLSMALLINT, unique SQLUSMALLINT, reserved SQLUSMALLINT) SQLRETURN
+	sqlForeignKeys    func(stmt SQLHSTMT, pkCatalogName *byte, nameLen1 SQLSMALLINT, pkSchemaName *byte, nameLen2 SQLSMALLINT, pkTableName *byte, nameLen3 SQLSMALLINT, fkCatalogName *byte, nameLen4 SQLSMALLINT, fkSchemaName *byte, nameLen5 SQLSMALLINT, fkTableName *byte, nameLen6 SQLSMALLINT) SQLRETURN
+	sqlGetTypeInfo    func(stmt SQLHSTMT, dataType SQLSMALLINT) SQLRETURN
 )
 
+// Wide-character (W) entry points used in Unicode mode. Text arguments are
+// SQLWCHAR buffers of the driver manager's width, and lengths are in characters.
+var (
+	sqlDriverConnectW func(dbc SQLHDBC, hwnd uintptr, inConnStr uintptr, inConnStrLen SQLSMALLINT, outConnStr uintptr, outConnStrMax SQLSMALLINT, outConnStrLen *SQLSMALLINT, driverCompletion SQLUSMALLINT) SQLRETURN
+	sqlExecDirectW    func(stmt SQLHSTMT, stmtText uintptr, textLength SQLINTEGER) SQLRETURN
+	sqlPrepareW       func(stmt SQLHSTMT, stmtText uintptr, textLength SQLINTEGER) SQLRETURN
+	sqlDescribeColW   func(stmt SQLHSTMT, colNum SQLUSMALLINT, colName uintptr, bufferLen SQLSMALLINT, nameLen *SQLSMALLINT, dataType *SQLSMALLINT, colSize *SQLULEN, decDigits *SQLSMALLINT, nullable *SQLSMALLINT) SQLRETURN
+	sqlGetInfoW       func(dbc SQLHDBC, infoType SQLUSMALLINT, infoValue uintptr, bufferLength SQLSMALLINT, stringLength *SQLSMALLINT) SQLRETURN
+	sqlColAttributeW  func(stmt SQLHSTMT, colNum SQLUSMALLINT, fieldId SQLUSMALLINT, charAttr uintptr, bufferLen SQLSMALLINT, strLen *SQLSMALLINT, numAttr *SQLLEN) SQLRETURN
+	sqlGetDiagRecW    func(handleType SQLSMALLINT, handle SQLHANDLE, recNum SQLSMALLINT, sqlState uintptr, nativeError *SQLINTEGER, msgText uintptr, bufferLen SQLSMALLINT, textLen *SQLSMALLINT) SQLRETURN
+	sqlTablesW        func(stmt SQLHSTMT, catalogName uintptr, nameLen1 SQLSMALLINT, schemaName uintptr, nameLen2 SQLSMALLINT, tableName uintptr, nameLen3 SQLSMALLINT, tableType uintptr, nameLen4 SQLSMALLINT) SQLRETURN
+	sqlColumnsW       func(stmt SQLHSTMT, catalogName uintptr, nameLen1 SQLSMALLINT, schemaName uintptr, nameLen2 SQLSMALLINT, tableName uintptr, nameLen3 SQLSMALLINT, columnName uintptr, nameLen4 SQLSMALLINT) SQLRETURN
+	sqlPrimaryKeysW   func(stmt SQLHSTMT, catalogName uintptr, nameLen1 SQLSMALLINT, schemaName uintptr, nameLen2 SQLSMALLINT, tableName uintptr, nameLen3 SQLSMALLINT) SQLRETURN
+	sqlStatisticsW    func(stmt SQLHSTMT, catalogName uintptr, nameLen1 SQLSMALLINT, schemaName uintptr, nameLen2 SQLSMALLINT, tableName uintptr, nameLen3 SQLSMALLINT, unique SQLUSMALLINT, reserved SQLUSMALLINT) SQLRETURN
+	sqlForeignKeysW   func(stmt SQLHSTMT, pkCatalogName uintptr, nameLen1 SQLSMALLINT, pkSchemaName uintptr, nameLen2 SQLSMALLINT, pkTableName uintptr, nameLen3 SQLSMALLINT, fkCatalogName uintptr, nameLen4 SQLSMALLINT, fkSchemaName uintptr, nameLen5 SQLSMALLINT, fkTableName uintptr, nameLen6 SQLSMALLINT) SQLRETURN
+	sqlGetTypeInfoW   func(stmt SQLHSTMT, dataType SQLSMALLINT) SQLRETURN
+	sqlSetCursorNameW func(stmt SQLHSTMT, cursorName uintptr, nameLength SQLSMALLINT) SQLRETURN
+	sqlGetCursorNameW func(stmt SQLHSTMT, cursorName uintptr, bufferLength SQLSMALLINT, nameLength *SQLSMALLINT) SQLRETURN
+
+	// wideAPI is true when the loaded library exports all of the W entry points
+	wideAPI bool
+)
+
+// Driver manager entry points, which a driver loaded directly does not
+// export; they are nil unless the library has them
+var (
+	sqlDrivers     func(env SQLHENV, direction SQLUSMALLINT, driverDesc *byte, descMax SQLSMALLINT, descLen *SQLSMALLINT, driverAttrs *byte, attrsMax SQLSMALLINT, attrsLen *SQLSMALLINT) SQLRETURN
+	sqlDataSources func(env SQLHENV, direction SQLUSMALLINT, serverName *byte, nameMax SQLSMALLINT, nameLen *SQLSMALLINT, description *byte, descMax SQLSMALLINT, descLen *SQLSMALLINT) SQLRETURN
+
+	sqlDriversW     func(env SQLHENV, direction SQLUSMALLINT, driverDesc uintptr, descMax SQLSMALLINT, descLen *SQLSMALLINT, driverAttrs uintptr, attrsMax SQLSMALLINT, attrsLen *SQLSMALLINT) SQLRETURN
+	sqlDataSourcesW func(env SQLHENV, direction SQLUSMALLINT, serverName uintptr, nameMax SQLSMALLINT, nameLen *SQLSMALLINT, description uintptr, descMax SQLSMALLINT, descLen *SQLSMALLINT) SQLRETURN
+)
+
+// sqlCancelHandle is the ODBC 3.8 SQLCancelHandle, which also cancels
+// functions running on a connection handle; nil unless the library exports it
+var sqlCancelHandle func(handleType SQLSMALLINT, handle SQLHANDLE) SQLRETURN
+
+// odbcFuncs maps the core ODBC entry points to their function pointers.
+// Functions taking text are registered under their ANSI (A) names on
+// Windows; Unix driver managers export the ANSI versions without the suffix.
+// The signatures are checked against the ODBC headers by TestODBCSignatures.
+var odbcFuncs = []struct {
+	name string
+	fptr interface{}
+	ansi bool
+}{
+	{"SQLAllocHandle", &sqlAllocHandle, false},
+	{"SQLFreeHandle", &sqlFreeHandle, false},
+	{"SQLSetEnvAttr", &sqlSetEnvAttr, false},
+	{"SQLGetEnvAttr", &sqlGetEnvAttr, false},
+	{"SQLDriverConnect", &sqlDriverConnect, true},
+	{"SQLGetInfo", &sqlGetInfo, true},
+	{"SQLDisconnect", &sqlDisconnect, false},
+	{"SQLSetConnectAttr", &sqlSetConnectAttr, false},
+	{"SQLGetConnectAttr", &sqlGetConnectAttr, false},
+	{"SQLExecDirect", &sqlExecDirect, true},
+	{"SQLPrepare", &sqlPrepare, true},
+	{"SQLDescribeCol", &sqlDescribeCol, true},
+	{"SQLColAttribute", &sqlColAttribute, true},
+	{"SQLGetDiagRec", &sqlGetDiagRec, true},
+	{"SQLTables", &sqlTables, true},
+	{"SQLColumns", &sqlColumns, true},
+	{"SQLPrimaryKeys", &sqlPrimaryKeys, true},
+	{"SQLStatistics", &sqlStatistics, true},
+	{"SQLForeignKeys", &sqlForeignKeys, true},
+	{"SQLGetTypeInfo", &sqlGetTypeInfo, true},
+	{"SQLSetCursorName", &sqlSetCursorName, true},
+	{"SQLGetCursorName", &sqlGetCursorName, true},
+	{"SQLExecute", &sqlExecute, false},
+	{"SQLNumResultCols", &sqlNumResultCols, false},
+	{"SQLBindCol", &sqlBindCol, false},
+	{"SQLBindParameter", &sqlBindParameter, false},
+	{"SQLParamData", &sqlParamData, false},
+	{"SQLPutData", &sqlPutData, false},
+	{"SQLFetch", &sqlFetch, false},
+	{"SQLFetchScroll", &sqlFetchScroll, false},
+	{"SQLGetData", &sqlGetData, false},
+	{"SQLRowCount", &sqlRowCount, false},
+	{"SQLNumParams", &sqlNumParams, false},
+	{"SQLDescribeParam", &sqlDescribeParam, false},
+	{"SQLGetDiagField", &sqlGetDiagField, false},
+	{"SQLEndTran", &sqlEndTran, false},
+	{"SQLCloseCursor", &sqlCloseCursor, false},
+	{"SQLCancel", &sqlCancel, false},
+	{"SQLFreeStmt", &sqlFreeStmt, false},
+	{"SQLMoreResults", &sqlMoreResults, false},
+	{"SQLSetStmtAttr", &sqlSetStmtAttr, false},
+	{"SQLGetStmtAttr", &sqlGetStmtAttr, false},
+	{"SQLSetDescField", &sqlSetDescField, false},
+}
+
+// wideFuncs maps the W entry points to their function pointers
+var wideFuncs = []struct {
+	name string
+	fptr interface{}
+}{
+	{"SQLDriverConnectW", &sqlDriverConnectW},
+	{"SQLExecDirectW", &sqlExecDirectW},
+	{"SQLPrepareW", &sqlPrepareW},
+	{"SQLDescribeColW", &sqlDescribeColW},
+	{"SQLGetInfoW", &sqlGetInfoW},
+	{"SQLColAttributeW", &sqlColAttributeW},
+	{"SQLGetDiagRecW", &sqlGetDiagRecW},
+	{"SQLTablesW", &sqlTablesW},
+	{"SQLColumnsW", &sqlColumnsW},
+	{"SQLPrimaryKeysW", &sqlPrimaryKeysW},
+	{"SQLStatisticsW", &sqlStatisticsW},
+	{"SQLForeignKeysW", &sqlForeignKeysW},
+	{"SQLGetTypeInfoW", &sqlGetTypeInfoW},
+	{"SQLSetCursorNameW", &sqlSetCursorNameW},
+	{"SQLGetCursorNameW", &sqlGetCursorNameW},
+}
+
+// driverManagerFuncs maps the optional driver manager entry points to their
+// function pointers; the ANSI ones are registered under their A names on
+// Windows like odbcFuncs
+var driverManagerFuncs = []struct {
+	name string
+	fptr interface{}
+	ansi bool
+}{
+	{"SQLDrivers", &sqlDrivers, true},
+	{"SQLDataSources", &sqlDataSources, true},
+	{"SQLDriversW", &sqlDriversW, false},
+	{"SQLDataSourcesW", &sqlDataSourcesW, false},
+}
+
 // getLibraryPath returns the platform-specific ODBC library path.
 // The GODBC_LIBRARY_PATH environment variable can override the default path.
 func getLibraryPath() string {
@@ -98,63 +234,44 @@ func initODBC() error {
 			return
 		}
 
-		// Register core handle management functions
-		purego.RegisterLibFunc(&sqlAllocHandle, odbcLib, "SQLAllocHandle")
-		purego.RegisterLibFunc(&sqlFreeHandle, odbcLib, "SQLFreeHandle")
-
-		// Register environment functions
-		purego.RegisterLibFunc(&sqlSetEnvAttr, odbcLib, "SQLSetEnvAttr")
-		purego.RegisterLibFunc(&sqlGetEnvAttr, odbcLib, "SQLGetEnvAttr")
-
-		// Register connection functions
-		// Use ANSI versions on Unix, which don't have 'A' suffix
-		if runtime.GOOS == "windows" {
-			purego.RegisterLibFunc(&sqlDriverConnect, odbcLib, "SQLDriverConnectA")
-			purego.RegisterLibFunc(&sqlGetInfo, odbcLib, "SQLGetInfoA")
-		} else {
-			purego.RegisterLibFunc(&sqlDriverConnect, odbcLib, "SQLDriverConnect")
-			purego.RegisterLibFunc(&sqlGetInfo, odbcLib, "SQLGetInfo")
+		// Register the core entry points
+		for _, f := range odbcFuncs {
+			name := f.name
+			if f.ansi && runtime.GOOS == "windows" {
+				name += "A"
+			}
+			purego.RegisterLibFunc(f.fptr, odbcLib, name)
 		}
-		purego.RegisterLibFunc(&sqlDisconnect, odbcLib, "SQLDisconnect")
-		purego.RegisterLibFunc(&sqlSetConnectAttr, odbcLib, "SQLSetConnectAttr")
-		purego.RegisterLibFunc(&sqlGetConnectAttr, odbcLib, "SQLGetConnectAttr")
-
-		// Register statement functions
-		if runtime.GOOS == "windows" {
-			purego.RegisterLibFunc(&sqlExecDirect, odbcLib, "SQLExecDirectA")
-			purego.RegisterLibFunc(&sqlPrepare, odbcLib, "SQLPrepareA")
-			purego.RegisterLibFunc(&sqlDescribeCol, odbcLib, "SQLDescribeColA")
-			purego.RegisterLibFunc(&sqlColAttribute, odbcLib, "SQLColAttributeA")
-			purego.RegisterLibFunc(&sqlGetDiagRec, odbcLib, "SQLGetDiagRecA")
-			purego.RegisterLibFunc(&sqlTables, odbcLib, "SQLTablesA")
-			purego.RegisterLibFunc(&sqlColumns, odbcLib, "SQLColumnsA")
-		} else {
-			purego.RegisterLibFunc(&sqlExecDirect, odbcLib, "SQLExecDirect")
-			purego.RegisterLibFunc(&sqlPrepare, odbcLib, "SQLPrepare")
-			purego.RegisterLibFunc(&sqlDescribeCol, odbcLib, "SQLDescribeCol")
-			purego.RegisterLibFunc(&sqlColAttribute, odbcLib, "SQLColAttribute")
-			purego.RegisterLibFunc(&sqlGetDiagRec, odbcLib, "SQLGetDiagRec")
-			purego.RegisterLibFunc(&sqlTables, odbcLib, "SQLTables")
-			purego.RegisterLibFunc(&sqlColumns, odbcLib, "SQLColumns")
+
+		// Register the wide entry points if the library exports them all;
+		// drivers loaded without a driver manager may be ANSI-only
+		wideAPI = true
+		for _, f := range wideFuncs {
+			if !hasSymbol(odbcLib, f.name) {
+				wideAPI = false
+				break
+			}
 		}
-		purego.RegisterLibFunc(&sqlExecute, odbcLib, "SQLExecute")
-		purego.RegisterLibFunc(&sqlNumResultCols, odbcLib, "SQLNumResultCols")
-		purego.RegisterLibFunc(&sqlBindCol, odbcLib, "SQLBindCol")
-		purego.RegisterLibFunc(&sqlBindParameter, odbcLib, "SQLBindParameter")
-		purego.RegisterLibFunc(&sqlFetch, odbcLib, "SQLFetch")
-		purego.RegisterLibFunc(&sqlFetchScroll, odbcLib, "SQLFetchScroll")
-		purego.RegisterLibFunc(&sqlGetData, odbcLib, "SQLGetData")
-		purego.RegisterLibFunc(&sqlRowCount, odbcLib, "SQLRowCount")
-		purego.RegisterLibFunc(&sqlNumParams, odbcLib, "SQLNumParams")
-		purego.RegisterLibFunc(&sqlDescribeParam, odbcLib, "SQLDescribeParam")
-		purego.RegisterLibFunc(&sqlGetDiagField, odbcLib, "SQLGetDiagField")
-		purego.RegisterLibFunc(&sqlEndTran, odbcLib, "SQLEndTran")
-		purego.RegisterLibFunc(&sqlCloseCursor, odbcLib, "SQLCloseCursor")
-		purego.RegisterLibFunc(&sqlCancel, odbcLib, "SQLCancel")
-		purego.RegisterLibFunc(&sqlFreeStmt, odbcLib, "SQLFreeStmt")
-		purego.RegisterLibFunc(&sqlMoreResults, odbcLib, "SQLMoreResults")
-		purego.RegisterLibFunc(&sqlSetStmtAttr, odbcLib, "SQLSetStmtAttr")
-		purego.RegisterLibFunc(&sqlGetStmtAttr, odbcLib, "SQLGetStmtAttr")
+		if wideAPI {
+			for _, f := range wideFuncs {
+				purego.RegisterLibFunc(f.fptr, odbcLib, f.name)
+			}
+		}
+
+		for _, f := range driverManagerFuncs {
+			name := f.name
+			if f.ansi && runtime.GOOS == "windows" {
+				name += "A"
+			}
+			if hasSymbol(odbcLib, name) {
+				purego.RegisterLibFunc(f.fptr, odbcLib, name)
+			}
+		}
+		if hasSymbol(odbcLib, "SQLCancelHandle") {
+			purego.RegisterLibFunc(&sqlCancelHandle, odbcLib, "SQLCancelHandle")
+		}
+
+		libraryInfo = probeLibrary(odbcLib, libPath)
 	})
 	return initErr
 }
@@ -174,6 +291,11 @@ func SetEnvAttr(env SQLHENV, attribute SQLINTEGER, value uintptr, stringLength S
 	return sqlSetEnvAttr(env, attribute, value, stringLength)
 }
 
+// GetEnvAttr gets an environment attribute
+func GetEnvAttr(env SQLHENV, attribute SQLINTEGER, value uintptr, bufferLength SQLINTEGER, stringLength *SQLINTEGER) SQLRETURN {
+	return sqlGetEnvAttr(env, attribute, value, bufferLength, stringLength)
+}
+
 // DriverConnect connects to a data source using a connection string
 func DriverConnect(dbc SQLHDBC, hwnd uintptr, inConnStr string, outConnStr []byte, driverCompletion SQLUSMALLINT) (outLen SQLSMALLINT, ret SQLRETURN) {
 	inBytes := append([]byte(inConnStr), 0)
@@ -188,6 +310,22 @@ func DriverConnect(dbc SQLHDBC, hwnd uintptr, inConnStr string, outConnStr []byt
 	return outLenPtr, ret
 }
 
+// DriverConnectW connects to a data source using SQLDriverConnectW, so the
+// connection string is passed as SQLWCHAR text. outChars is the size of the
+// buffer for the completed connection string, in characters.
+func DriverConnectW(dbc SQLHDBC, hwnd uintptr, inConnStr string, outChars int, driverCompletion SQLUSMALLINT) (outConnStr string, ret SQLRETURN) {
+	in, _, _ := wideStringParam(inConnStr)
+	inPtr, _ := getBufferPtr(in)
+	out := make([]byte, (outChars+1)*wcharSize())
+	var outLen SQLSMALLINT
+	ret = sqlDriverConnectW(dbc, hwnd, inPtr, SQLSMALLINT(SQL_NTS), uintptr(unsafe.Pointer(&out[0])), SQLSMALLINT(outChars+1), &outLen, driverCompletion)
+	runtime.KeepAlive(in)
+	if IsSuccess(ret) {
+		outConnStr = wideBufferString(out, int(outLen))
+	}
+	return outConnStr, ret
+}
+
 // Disconnect disconnects from a data source
 func Disconnect(dbc SQLHDBC) SQLRETURN {
 	return sqlDisconnect(dbc)
@@ -198,6 +336,11 @@ func SetConnectAttr(dbc SQLHDBC, attribute SQLINTEGER, value uintptr, stringLeng
 	return sqlSetConnectAttr(dbc, attribute, value, stringLength)
 }
 
+// GetConnectAttr gets a connection attribute
+func GetConnectAttr(dbc SQLHDBC, attribute SQLINTEGER, value uintptr, bufferLength SQLINTEGER, stringLength *SQLINTEGER) SQLRETURN {
+	return sqlGetConnectAttr(dbc, attribute, value, bufferLength, stringLength)
+}
+
 // GetInfo retrieves driver/data source information
 func GetInfo(dbc SQLHDBC, infoType SQLUSMALLINT, infoValue []byte) (stringLength SQLSMALLINT, ret SQLRETURN) {
 	var strLen SQLSMALLINT
@@ -211,18 +354,249 @@ func GetInfo(dbc SQLHDBC, infoType SQLUSMALLINT, infoValue []byte) (stringLength
 	return strLen, ret
 }
 
+// GetInfoW retrieves driver/data source information using SQLGetInfoW, so
+// string values are written to infoValue as SQLWCHAR text. Lengths are in bytes.
+func GetInfoW(dbc SQLHDBC, infoType SQLUSMALLINT, infoValue []byte) (stringLength SQLSMALLINT, ret SQLRETURN) {
+	var strLen SQLSMALLINT
+	ret = sqlGetInfoW(dbc, infoType, uintptr(0), 0, &strLen)
+	if !IsSuccess(ret) {
+		return 0, ret
+	}
+	if len(infoValue) > 0 {
+		ret = sqlGetInfoW(dbc, infoType, uintptr(unsafe.Pointer(&infoValue[0])), SQLSMALLINT(len(infoValue)), &strLen)
+	}
+	return strLen, ret
+}
+
+// GetInfoInt retrieves an SQLUSMALLINT information value, such as
+// SQL_MAX_CONCURRENT_ACTIVITIES or SQL_IDENTIFIER_CASE
+func GetInfoInt(dbc SQLHDBC, infoType SQLUSMALLINT) (SQLUSMALLINT, SQLRETURN) {
+	var value SQLUSMALLINT
+	ret := sqlGetInfo(dbc, infoType, uintptr(unsafe.Pointer(&value)), SQLSMALLINT(unsafe.Sizeof(value)), nil)
+	return value, ret
+}
+
+// GetInfoBitmask retrieves an SQLUINTEGER information value, such as the
+// SQL_TXN_ISOLATION_OPTION or SQL_GETDATA_EXTENSIONS bitmask
+func GetInfoBitmask(dbc SQLHDBC, infoType SQLUSMALLINT) (SQLUINTEGER, SQLRETURN) {
+	var value SQLUINTEGER
+	ret := sqlGetInfo(dbc, infoType, uintptr(unsafe.Pointer(&value)), SQLSMALLINT(unsafe.Sizeof(value)), nil)
+	return value, ret
+}
+
 // ExecDirect executes an SQL statement directly
 func ExecDirect(stmt SQLHSTMT, query string) SQLRETURN {
 	queryBytes := append([]byte(query), 0)
 	return sqlExecDirect(stmt, &queryBytes[0], SQLINTEGER(SQL_NTS))
 }
 
+// ExecDirectW executes an SQL statement directly using SQLExecDirectW
+func ExecDirectW(stmt SQLHSTMT, query string) SQLRETURN {
+	text, _, _ := wideStringParam(query)
+	ptr, _ := getBufferPtr(text)
+	ret := sqlExecDirectW(stmt, ptr, SQLINTEGER(SQL_NTS))
+	runtime.KeepAlive(text)
+	return ret
+}
+
+// SetCursorName associates a cursor name with a statement
+func SetCursorName(stmt SQLHSTMT, cursorName string) SQLRETURN {
+	nameBytes := append([]byte(cursorName), 0)
+	return sqlSetCursorName(stmt, &nameBytes[0], SQLSMALLINT(SQL_NTS))
+}
+
+// SetCursorNameW associates a cursor name with a statement using SQLSetCursorNameW
+func SetCursorNameW(stmt SQLHSTMT, cursorName string) SQLRETURN {
+	name, _, _ := wideStringParam(cursorName)
+	ptr, _ := getBufferPtr(name)
+	ret := sqlSetCursorNameW(stmt, ptr, SQLSMALLINT(SQL_NTS))
+	runtime.KeepAlive(name)
+	return ret
+}
+
+// GetCursorName returns the cursor name associated with a statement
+func GetCursorName(stmt SQLHSTMT, cursorName []byte) (nameLength SQLSMALLINT, ret SQLRETURN) {
+	ret = sqlGetCursorName(stmt, &cursorName[0], SQLSMALLINT(len(cursorName)), &nameLength)
+	return nameLength, ret
+}
+
+// GetCursorNameW returns the cursor name associated with a statement using
+// SQLGetCursorNameW. nameChars is the size of the name buffer, in characters.
+func GetCursorNameW(stmt SQLHSTMT, nameChars int) (cursorName string, ret SQLRETURN) {
+	buf := make([]byte, (nameChars+1)*wcharSize())
+	var nameLen SQLSMALLINT
+	ret = sqlGetCursorNameW(stmt, uintptr(unsafe.Pointer(&buf[0])), SQLSMALLINT(nameChars+1), &nameLen)
+	if IsSuccess(ret) {
+		cursorName = wideBufferString(buf, int(nameLen))
+	}
+	return cursorName, ret
+}
+
+// Tables returns the list of tables matching the given catalog, schema, table and type patterns.
+// Empty arguments are passed as NULL, which matches everything.
+func Tables(stmt SQLHSTMT, catalogName, schemaName, tableName, tableType string) SQLRETURN {
+	catalog, catalogLen := catalogArg(catalogName)
+	schema, schemaLen := catalogArg(schemaName)
+	table, tableLen := catalogArg(tableName)
+	types, typesLen := catalogArg(tableType)
+	return sqlTables(stmt, catalog, catalogLen, schema, schemaLen, table, tableLen, types, typesLen)
+}
+
+// TablesW is Tables using SQLTablesW, so the patterns are passed as SQLWCHAR text
+func TablesW(stmt SQLHSTMT, catalogName, schemaName, tableName, tableType string) SQLRETURN {
+	var args wideCatalogArgs
+	catalog, catalogLen := args.add(catalogName)
+	schema, schemaLen := args.add(schemaName)
+	table, tableLen := args.add(tableName)
+	types, typesLen := args.add(tableType)
+	ret := sqlTablesW(stmt, catalog, catalogLen, schema, schemaLen, table, tableLen, types, typesLen)
+	runtime.KeepAlive(args)
+	return ret
+}
+
+// Columns returns the columns of tables matching the given catalog, schema, table and column patterns.
+// Empty arguments are passed as NULL, which matches everything.
+func Columns(stmt SQLHSTMT, catalogName, schemaName, tableName, columnName string) SQLRETURN {
+	catalog, catalogLen := catalogArg(catalogName)
+	schema, schemaLen := catalogArg(schemaName)
+	table, tableLen := catalogArg(tableName)
+	column, columnLen := catalogArg(columnName)
+	return sqlColumns(stmt, catalog, catalogLen, schema, schemaLen, table, tableLen, column, columnLen)
+}
+
+// ColumnsW is Columns using SQLColumnsW
+func ColumnsW(stmt SQLHSTMT, catalogName, schemaName, tableName, columnName string) SQLRETURN {
+	var args wideCatalogArgs
+	catalog, catalogLen := args.add(catalogName)
+	schema, schemaLen := args.add(schemaName)
+	table, tableLen := args.add(tableName)
+	column, columnLen := args.add(columnName)
+	ret := sqlColumnsW(stmt, catalog, catalogLen, schema, schemaLen, table, tableLen, column, columnLen)
+	runtime.KeepAlive(args)
+	return ret
+}
+
+// PrimaryKeys returns the columns that make up the primary key of a table
+func PrimaryKeys(stmt SQLHSTMT, catalogName, schemaName, tableName string) SQLRETURN {
+	catalog, catalogLen := catalogArg(catalogName)
+	schema, schemaLen := catalogArg(schemaName)
+	table, tableLen := catalogArg(tableName)
+	return sqlPrimaryKeys(stmt, catalog, catalogLen, schema, schemaLen, table, tableLen)
+}
+
+// PrimaryKeysW is PrimaryKeys using SQLPrimaryKeysW
+func PrimaryKeysW(stmt SQLHSTMT, catalogName, schemaName, tableName string) SQLRETURN {
+	var args wideCatalogArgs
+	catalog, catalogLen := args.add(catalogName)
+	schema, schemaLen := args.add(schemaName)
+	table, tableLen := args.add(tableName)
+	ret := sqlPrimaryKeysW(stmt, catalog, catalogLen, schema, schemaLen, table, tableLen)
+	runtime.KeepAlive(args)
+	return ret
+}
+
+// Statistics returns statistics about a table and its indexes.
+// unique is SQL_INDEX_UNIQUE or SQL_INDEX_ALL; reserved is SQL_QUICK or SQL_ENSURE.
+func Statistics(stmt SQLHSTMT, catalogName, schemaName, tableName string, unique, reserved SQLUSMALLINT) SQLRETURN {
+	catalog, catalogLen := catalogArg(catalogName)
+	schema, schemaLen := catalogArg(schemaName)
+	table, tableLen := catalogArg(tableName)
+	return sqlStatistics(stmt, catalog, catalogLen, schema, schemaLen, table, tableLen, unique, reserved)
+}
+
+// StatisticsW is Statistics using SQLStatisticsW
+func StatisticsW(stmt SQLHSTMT, catalogName, schemaName, tableName string, unique, reserved SQLUSMALLINT) SQLRETURN {
+	var args wideCatalogArgs
+	catalog, catalogLen := args.add(catalogName)
+	schema, schemaLen := args.add(schemaName)
+	table, tableLen := args.add(tableName)
+	ret := sqlStatisticsW(stmt, catalog, catalogLen, schema, schemaLen, table, tableLen, unique, reserved)
+	runtime.KeepAlive(args)
+	return ret
+}
+
+// ForeignKeys returns the foreign keys of the FK table that reference the PK
+// table. Pass only the PK table to list the keys referencing it, or only the
+// FK table to list the keys it defines. Empty arguments are passed as NULL.
+func ForeignKeys(stmt SQLHSTMT, pkCatalogName, pkSchemaName, pkTableName, fkCatalogName, fkSchemaName, fkTableName string) SQLRETURN {
+	pkCatalog, pkCatalogLen := catalogArg(pkCatalogName)
+	pkSchema, pkSchemaLen := catalogArg(pkSchemaName)
+	pkTable, pkTableLen := catalogArg(pkTableName)
+	fkCatalog, fkCatalogLen := catalogArg(fkCatalogName)
+	fkSchema, fkSchemaLen := catalogArg(fkSchemaName)
+	fkTable, fkTableLen := catalogArg(fkTableName)
+	return sqlForeignKeys(stmt, pkCatalog, pkCatalogLen, pkSchema, pkSchemaLen, pkTable, pkTableLen,
+		fkCatalog, fkCatalogLen, fkSchema, fkSchemaLen, fkTable, fkTableLen)
+}
+
+// ForeignKeysW is ForeignKeys using SQLForeignKeysW
+func ForeignKeysW(stmt SQLHSTMT, pkCatalogName, pkSchemaName, pkTableName, fkCatalogName, fkSchemaName, fkTableName string) SQLRETURN {
+	var args wideCatalogArgs
+	pkCatalog, pkCatalogLen := args.add(pkCatalogName)
+	pkSchema, pkSchemaLen := args.add(pkSchemaName)
+	pkTable, pkTableLen := args.add(pkTableName)
+	fkCatalog, fkCatalogLen := args.add(fkCatalogName)
+	fkSchema, fkSchemaLen := args.add(fkSchemaName)
+	fkTable, fkTableLen := args.add(fkTableName)
+	ret := sqlForeignKeysW(stmt, pkCatalog, pkCatalogLen, pkSchema, pkSchemaLen, pkTable, pkTableLen,
+		fkCatalog, fkCatalogLen, fkSchema, fkSchemaLen, fkTable, fkTableLen)
+	runtime.KeepAlive(args)
+	return ret
+}
+
+// GetTypeInfo returns the data types the data source supports of a SQL type,
+// or all of them for SQL_ALL_TYPES
+func GetTypeInfo(stmt SQLHSTMT, dataType SQLSMALLINT) SQLRETURN {
+	return sqlGetTypeInfo(stmt, dataType)
+}
+
+// GetTypeInfoW is GetTypeInfo using SQLGetTypeInfoW, so a driver manager
+// mapping an ANSI application to a Unicode driver is not involved
+func GetTypeInfoW(stmt SQLHSTMT, dataType SQLSMALLINT) SQLRETURN {
+	return sqlGetTypeInfoW(stmt, dataType)
+}
+
+// catalogArg converts a catalog function argument to a null-terminated string,
+// or NULL if the argument is empty
+func catalogArg(s string) (*byte, SQLSMALLINT) {
+	if s == "" {
+		return nil, 0
+	}
+	b := append([]byte(s), 0)
+	return &b[0], SQLSMALLINT(SQL_NTS)
+}
+
+// wideCatalogArgs holds the SQLWCHAR buffers of the arguments of a W catalog
+// function, keeping them alive until the call returns
+type wideCatalogArgs []interface{}
+
+// add converts a catalog function argument to a null-terminated SQLWCHAR
+// string, or NULL if the argument is empty
+func (a *wideCatalogArgs) add(s string) (uintptr, SQLSMALLINT) {
+	if s == "" {
+		return 0, 0
+	}
+	buf, _, _ := wideStringParam(s)
+	*a = append(*a, buf)
+	ptr, _ := getBufferPtr(buf)
+	return ptr, SQLSMALLINT(SQL_NTS)
+}
+
 // Prepare prepares an SQL statement for execution
 func Prepare(stmt SQLHSTMT, query string) SQLRETURN {
 	queryBytes := append([]byte(query), 0)
 	return sqlPrepare(stmt, &queryBytes[0], SQLINTEGER(SQL_NTS))
 }
 
+// PrepareW prepares an SQL statement for execution using SQLPrepareW
+func PrepareW(stmt SQLHSTMT, query string) SQLRETURN {
+	text, _, _ := wideStringParam(query)
+	ptr, _ := getBufferPtr(text)
+	ret := sqlPrepareW(stmt, ptr, SQLINTEGER(SQL_NTS))
+	runtime.KeepAlive(text)
+	return ret
+}
+
 // Execute executes a prepared statement
 func Execute(stmt SQLHSTMT) SQLRETURN {
 	return sqlExecute(stmt)
@@ -239,6 +613,18 @@ func DescribeCol(stmt SQLHSTMT, colNum SQLUSMALLINT, colName []byte) (nameLen SQ
 	return
 }
 
+// DescribeColW describes a column in a result set using SQLDescribeColW,
+// returning a column name of at most nameChars characters
+func DescribeColW(stmt SQLHSTMT, colNum SQLUSMALLINT, nameChars int) (colName string, dataType SQLSMALLINT, colSize SQLULEN, decDigits SQLSMALLINT, nullable SQLSMALLINT, ret SQLRETURN) {
+	buf := make([]byte, (nameChars+1)*wcharSize())
+	var nameLen SQLSMALLINT
+	ret = sqlDescribeColW(stmt, colNum, uintptr(unsafe.Pointer(&buf[0])), SQLSMALLINT(nameChars+1), &nameLen, &dataType, &colSize, &decDigits, &nullable)
+	if IsSuccess(ret) {
+		colName = wideBufferString(buf, int(nameLen))
+	}
+	return
+}
+
 // ColAttribute returns a column attribute
 func ColAttribute(stmt SQLHSTMT, colNum SQLUSMALLINT, fieldId SQLUSMALLINT, charAttr []byte) (strLen SQLSMALLINT, numAttr SQLLEN, ret SQLRETURN) {
 	var charPtr uintptr
@@ -251,11 +637,36 @@ func ColAttribute(stmt SQLHSTMT, colNum SQLUSMALLINT, fieldId SQLUSMALLINT, char
 	return
 }
 
+// ColAttributeW returns a column attribute using SQLColAttributeW, so string
+// attributes are written to charAttr as SQLWCHAR text. Lengths are in bytes.
+func ColAttributeW(stmt SQLHSTMT, colNum SQLUSMALLINT, fieldId SQLUSMALLINT, charAttr []byte) (strLen SQLSMALLINT, numAttr SQLLEN, ret SQLRETURN) {
+	var charPtr uintptr
+	var bufLen SQLSMALLINT
+	if len(charAttr) > 0 {
+		charPtr = uintptr(unsafe.Pointer(&charAttr[0]))
+		bufLen = SQLSMALLINT(len(charAttr))
+	}
+	ret = sqlColAttributeW(stmt, colNum, fieldId, charPtr, bufLen, &strLen, &numAttr)
+	return
+}
+
 // BindParameter binds a parameter to a statement
 func BindParameter(stmt SQLHSTMT, paramNum SQLUSMALLINT, ioType SQLSMALLINT, valueType SQLSMALLINT, paramType SQLSMALLINT, colSize SQLULEN, decDigits SQLSMALLINT, paramValue uintptr, bufferLen SQLLEN, strLenOrInd *SQLLEN) SQLRETURN {
 	return sqlBindParameter(stmt, paramNum, ioType, valueType, paramType, colSize, decDigits, paramValue, bufferLen, strLenOrInd)
 }
 
+// ParamData returns the token of the next data-at-execution parameter the
+// driver needs, with SQL_NEED_DATA, or the result of the execution once all data is sent
+func ParamData(stmt SQLHSTMT) (token uintptr, ret SQLRETURN) {
+	ret = sqlParamData(stmt, &token)
+	return token, ret
+}
+
+// PutData sends a chunk of a data-at-execution parameter value
+func PutData(stmt SQLHSTMT, data uintptr, strLenOrInd SQLLEN) SQLRETURN {
+	return sqlPutData(stmt, data, strLenOrInd)
+}
+
 // Fetch fetches the next row from the result set
 func Fetch(stmt SQLHSTMT) SQLRETURN {
 	return sqlFetch(stmt)
@@ -266,6 +677,11 @@ func FetchScroll(stmt SQLHSTMT, fetchOrientation SQLSMALLINT, fetchOffset SQLLEN
 	return sqlFetchScroll(stmt, fetchOrientation, fetchOffset)
 }
 
+// BindCol binds a buffer to a result column
+func BindCol(stmt SQLHSTMT, colNum SQLUSMALLINT, targetType SQLSMALLINT, targetValue uintptr, bufferLen SQLLEN, strLenOrInd *SQLLEN) SQLRETURN {
+	return sqlBindCol(stmt, colNum, targetType, targetValue, bufferLen, strLenOrInd)
+}
+
 // GetData retrieves data for a single column
 func GetData(stmt SQLHSTMT, colNum SQLUSMALLINT, targetType SQLSMALLINT, targetValue uintptr, bufferLen SQLLEN, strLenOrInd *SQLLEN) SQLRETURN {
 	return sqlGetData(stmt, colNum, targetType, targetValue, bufferLen, strLenOrInd)
@@ -281,12 +697,33 @@ func NumParams(stmt SQLHSTMT, paramCount *SQLSMALLINT) SQLRETURN {
 	return sqlNumParams(stmt, paramCount)
 }
 
+// DescribeParam describes a parameter marker of a prepared statement
+func DescribeParam(stmt SQLHSTMT, paramNum SQLUSMALLINT) (dataType SQLSMALLINT, paramSize SQLULEN, decDigits SQLSMALLINT, nullable SQLSMALLINT, ret SQLRETURN) {
+	ret = sqlDescribeParam(stmt, paramNum, &dataType, &paramSize, &decDigits, &nullable)
+	return
+}
+
 // GetDiagRec retrieves diagnostic records
 func GetDiagRec(handleType SQLSMALLINT, handle SQLHANDLE, recNum SQLSMALLINT, sqlState []byte, message []byte) (nativeError SQLINTEGER, msgLen SQLSMALLINT, ret SQLRETURN) {
 	ret = sqlGetDiagRec(handleType, handle, recNum, &sqlState[0], &nativeError, &message[0], SQLSMALLINT(len(message)), &msgLen)
 	return
 }
 
+// GetDiagRecW retrieves diagnostic records using SQLGetDiagRecW. sqlState
+// and message are SQLWCHAR buffers; sqlState must hold 6 characters, and
+// msgLen is in characters.
+func GetDiagRecW(handleType SQLSMALLINT, handle SQLHANDLE, recNum SQLSMALLINT, sqlState []byte, message []byte) (nativeError SQLINTEGER, msgLen SQLSMALLINT, ret SQLRETURN) {
+	ret = sqlGetDiagRecW(handleType, handle, recNum, uintptr(unsafe.Pointer(&sqlState[0])), &nativeError,
+		uintptr(unsafe.Pointer(&message[0])), SQLSMALLINT(len(message)/wcharSize()), &msgLen)
+	return
+}
+
+// GetDiagField retrieves a field of a diagnostic record, or of the diagnostic
+// header when recNum is 0
+func GetDiagField(handleType SQLSMALLINT, handle SQLHANDLE, recNum SQLSMALLINT, diagId SQLSMALLINT, diagInfo uintptr, bufferLen SQLSMALLINT, stringLen *SQLSMALLINT) SQLRETURN {
+	return sqlGetDiagField(handleType, handle, recNum, diagId, diagInfo, bufferLen, stringLen)
+}
+
 // EndTran commits or rolls back a transaction
 func EndTran(handleType SQLSMALLINT, handle SQLHANDLE, completionType SQLSMALLINT) SQLRETURN {
 	return sqlEndTran(handleType, handle, completionType)
@@ -302,6 +739,17 @@ func Cancel(stmt SQLHSTMT) SQLRETURN {
 	return sqlCancel(stmt)
 }
 
+// CancelHandle cancels the function running on a connection or statement
+// handle with SQLCancelHandle. Cancelling a connection handle interrupts
+// functions such as SQLDriverConnect on ODBC 3.8 drivers that support it.
+// Returns SQL_ERROR if the library does not export SQLCancelHandle.
+func CancelHandle(handleType SQLSMALLINT, handle SQLHANDLE) SQLRETURN {
+	if sqlCancelHandle == nil {
+		return SQL_ERROR
+	}
+	return sqlCancelHandle(handleType, handle)
+}
+
 // FreeStmt frees resources associated with a statement
 func FreeStmt(stmt SQLHSTMT, option SQLUSMALLINT) SQLRETURN {
 	return sqlFreeStmt(stmt, option)
@@ -316,3 +764,46 @@ func MoreResults(stmt SQLHSTMT) SQLRETURN {
 func SetStmtAttr(stmt SQLHSTMT, attribute SQLINTEGER, value uintptr, stringLength SQLINTEGER) SQLRETURN {
 	return sqlSetStmtAttr(stmt, attribute, value, stringLength)
 }
+
+// GetStmtAttr gets a statement attribute
+func GetStmtAttr(stmt SQLHSTMT, attribute SQLINTEGER, value uintptr, bufferLength SQLINTEGER, stringLength *SQLINTEGER) SQLRETURN {
+	return sqlGetStmtAttr(stmt, attribute, value, bufferLength, stringLength)
+}
+
+// SetDescField sets a field of a descriptor record. For string fields, value
+// points to the text and bufferLength is its length or SQL_NTS.
+func SetDescField(desc SQLHDESC, recNum SQLSMALLINT, fieldId SQLUSMALLINT, value uintptr, bufferLength SQLINTEGER) SQLRETURN {
+	return sqlSetDescField(desc, recNum, SQLSMALLINT(fieldId), value, bufferLength)
+}
+
+// fetchDriver returns the description and attributes of the next installed
+// driver, or the first if direction is SQL_FETCH_FIRST
+func fetchDriver(env SQLHENV, direction SQLUSMALLINT, desc, attrs []byte) (descLen, attrsLen SQLSMALLINT, ret SQLRETURN) {
+	ret = sqlDrivers(env, direction, &desc[0], SQLSMALLINT(len(desc)), &descLen, &attrs[0], SQLSMALLINT(len(attrs)), &attrsLen)
+	return descLen, attrsLen, ret
+}
+
+// fetchDriverW is fetchDriver using SQLDriversW: desc and attrs are SQLWCHAR
+// buffers, and the lengths are in characters
+func fetchDriverW(env SQLHENV, direction SQLUSMALLINT, desc, attrs []byte) (descLen, attrsLen SQLSMALLINT, ret SQLRETURN) {
+	size := wcharSize()
+	ret = sqlDriversW(env, direction, uintptr(unsafe.Pointer(&desc[0])), SQLSMALLINT(len(desc)/size), &descLen,
+		uintptr(unsafe.Pointer(&attrs[0])), SQLSMALLINT(len(attrs)/size), &attrsLen)
+	return descLen, attrsLen, ret
+}
+
+// fetchDataSource returns the name and driver description of the next data
+// source, or the first if direction is SQL_FETCH_FIRST
+func fetchDataSource(env SQLHENV, direction SQLUSMALLINT, name, desc []byte) (nameLen, descLen SQLSMALLINT, ret SQLRETURN) {
+	ret = sqlDataSources(env, direction, &name[0], SQLSMALLINT(len(name)), &nameLen, &desc[0], SQLSMALLINT(len(desc)), &descLen)
+	return nameLen, descLen, ret
+}
+
+// fetchDataSourceW is fetchDataSource using SQLDataSourcesW: name and desc are
+// SQLWCHAR buffers, and the lengths are in characters
+func fetchDataSourceW(env SQLHENV, direction SQLUSMALLINT, name, desc []byte) (nameLen, descLen SQLSMALLINT, ret SQLRETURN) {
+	size := wcharSize()
+	ret = sqlDataSourcesW(env, direction, uintptr(unsafe.Pointer(&name[0])), SQLSMALLINT(len(name)/size), &nameLen,
+		uintptr(unsafe.Pointer(&desc[0])), SQLSMALLINT(len(desc)/size), &descLen)
+	return nameLen, descLen, ret
+}
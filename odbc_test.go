@@ -1,9 +1,30 @@
 package godbc
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unicode/utf16"
+	"unsafe"
 )
 
 // =============================================================================
@@ -314,6 +335,100 @@ func TestConvertToODBC_GUID(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// Parameter Converter Tests (paramconv.go)
+// =============================================================================
+
+type testUUID [16]byte
+
+type testDate struct{ y, m, d int }
+
+// registerTestConverter registers a converter for the type of v until the test ends
+func registerTestConverter(t *testing.T, v interface{}, fn func(interface{}) (ODBCBinding, error)) {
+	t.Helper()
+	RegisterConverter(reflect.TypeOf(v), fn)
+	t.Cleanup(func() { RegisterConverter(reflect.TypeOf(v), nil) })
+}
+
+func TestRegisterConverter(t *testing.T) {
+	registerTestConverter(t, testUUID{}, func(v interface{}) (ODBCBinding, error) {
+		return ODBCBinding{Value: GUID(v.(testUUID))}, nil
+	})
+	registerTestConverter(t, testDate{}, func(v interface{}) (ODBCBinding, error) {
+		d := v.(testDate)
+		return ODBCBinding{Value: fmt.Sprintf("%04d-%02d-%02d", d.y, d.m, d.d), SQLType: SQL_TYPE_DATE, ColumnSize: 10}, nil
+	})
+
+	_, cType, sqlType, colSize, _, length, err := convertToODBC(testUUID{1, 2, 3})
+	if err != nil || cType != SQL_C_GUID || sqlType != SQL_GUID || colSize != 16 || length != 16 {
+		t.Errorf("UUID: got C type %d, SQL type %d, size %d, length %d, err %v; want a GUID binding", cType, sqlType, colSize, length, err)
+	}
+
+	_, _, sqlType, colSize, _, _, err = convertToODBC(testDate{2024, 2, 29})
+	if err != nil || sqlType != SQL_TYPE_DATE || colSize != 10 {
+		t.Errorf("date: got SQL type %d, size %d, err %v; want SQL_TYPE_DATE(10)", sqlType, colSize, err)
+	}
+
+	// Removing the converter restores the text fallback
+	RegisterConverter(reflect.TypeOf(testDate{}), nil)
+	if _, _, sqlType, _, _, _, _ := convertToODBC(testDate{2024, 2, 29}); sqlType != SQL_VARCHAR {
+		t.Errorf("after removal: SQL type %d, want SQL_VARCHAR", sqlType)
+	}
+}
+
+func TestRegisterConverter_RawAndErrors(t *testing.T) {
+	registerTestConverter(t, testDate{}, func(v interface{}) (ODBCBinding, error) {
+		d := v.(testDate)
+		switch {
+		case d.y == 0:
+			return ODBCBinding{}, errors.New("no year")
+		case d.y < 0:
+			return ODBCBinding{Value: "x", CType: SQL_C_DATE, SQLType: SQL_TYPE_DATE}, nil
+		}
+		raw := make([]byte, 6)
+		binary.LittleEndian.PutUint16(raw[0:], uint16(d.y))
+		binary.LittleEndian.PutUint16(raw[2:], uint16(d.m))
+		binary.LittleEndian.PutUint16(raw[4:], uint16(d.d))
+		return ODBCBinding{Value: raw, CType: SQL_C_DATE, SQLType: SQL_TYPE_DATE}, nil
+	})
+
+	buf, cType, sqlType, colSize, _, length, err := convertToODBC(testDate{2024, 2, 29})
+	if err != nil || cType != SQL_C_DATE || sqlType != SQL_TYPE_DATE || colSize != 6 || length != 6 || len(buf.([]byte)) != 6 {
+		t.Errorf("raw: got C type %d, SQL type %d, size %d, length %d, err %v", cType, sqlType, colSize, length, err)
+	}
+	if _, _, _, _, _, _, err := convertToODBC(testDate{}); err == nil || !strings.Contains(err.Error(), "no year") {
+		t.Errorf("converter error: got %v", err)
+	}
+	if _, _, _, _, _, _, err := convertToODBC(testDate{y: -1}); err == nil {
+		t.Error("expected an error for a C type binding without a []byte value")
+	}
+	if _, err := AllocateColumnArray([]interface{}{testDate{2024, 1, 1}}, 1); err == nil {
+		t.Error("expected an error for a C type binding in a batch column")
+	}
+}
+
+func TestAllocateColumnArray_Converter(t *testing.T) {
+	registerTestConverter(t, testDate{}, func(v interface{}) (ODBCBinding, error) {
+		d := v.(testDate)
+		return ODBCBinding{Value: int32(d.y*10000 + d.m*100 + d.d), SQLType: SQL_DECIMAL, ColumnSize: 8}, nil
+	})
+
+	values := []interface{}{nil, testDate{2024, 2, 29}, int32(5)}
+	buf, err := AllocateColumnArray(values, len(values))
+	if err != nil {
+		t.Fatalf("AllocateColumnArray failed: %v", err)
+	}
+	if buf.CType != SQL_C_SLONG || buf.SQLType != SQL_DECIMAL || buf.ColSize != 8 {
+		t.Errorf("got C type %d, SQL type %d, size %d; want SQL_C_SLONG as SQL_DECIMAL(8)", buf.CType, buf.SQLType, buf.ColSize)
+	}
+	if data := buf.Data.([]int32); data[1] != 20240229 || data[2] != 5 || buf.Lengths[0] != SQL_NULL_DATA {
+		t.Errorf("got data %v, lengths %v", data, buf.Lengths)
+	}
+	if _, ok := values[1].(testDate); !ok {
+		t.Error("AllocateColumnArray modified the caller's values")
+	}
+}
+
 // =============================================================================
 // GUID Tests (convert.go)
 // =============================================================================
@@ -355,8 +470,98 @@ func TestParseGUID_Invalid(t *testing.T) {
 	}
 }
 
+func TestGUID_String(t *testing.T) {
+	const input = "550E8400-E29B-41D4-A716-446655440000"
+	g, err := ParseGUID(input)
+	if err != nil {
+		t.Fatalf("ParseGUID failed: %v", err)
+	}
+	if got := g.String(); got != input {
+		t.Errorf("expected %s, got %s", input, got)
+	}
+}
+
+func TestGUID_Scan(t *testing.T) {
+	want, _ := ParseGUID("550E8400-E29B-41D4-A716-446655440000")
+	for _, src := range []any{
+		want,
+		"550e8400-e29b-41d4-a716-446655440000",
+		"{550E8400-E29B-41D4-A716-446655440000}",
+		[]byte("550E8400-E29B-41D4-A716-446655440000"),
+		want[:],
+	} {
+		var g GUID
+		if err := g.Scan(src); err != nil {
+			t.Errorf("Scan(%v) failed: %v", src, err)
+		} else if g != want {
+			t.Errorf("Scan(%v) = %v, want %v", src, g, want)
+		}
+	}
+
+	for _, src := range []any{nil, 42, "not-a-guid", []byte{1, 2, 3}} {
+		var g GUID
+		if err := g.Scan(src); err == nil {
+			t.Errorf("Scan(%v) should have failed", src)
+		}
+	}
+}
+
+func TestGUID_ValueAndText(t *testing.T) {
+	const input = "550E8400-E29B-41D4-A716-446655440000"
+	g, _ := ParseGUID(input)
+	if v, err := g.Value(); err != nil || v != input {
+		t.Errorf("Value() = %v, %v; want %s", v, err, input)
+	}
+
+	data, err := json.Marshal(map[string]GUID{"id": g})
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	if string(data) != `{"id":"`+input+`"}` {
+		t.Errorf("unexpected JSON %s", data)
+	}
+	var decoded map[string]GUID
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if decoded["id"] != g {
+		t.Errorf("round trip = %v, want %v", decoded["id"], g)
+	}
+}
+
+func TestConn_CheckNamedValue_GUID(t *testing.T) {
+	g, _ := ParseGUID("550E8400-E29B-41D4-A716-446655440000")
+	nv := &driver.NamedValue{Ordinal: 1, Value: g}
+	if err := (&Conn{}).CheckNamedValue(nv); err != nil {
+		t.Fatalf("CheckNamedValue failed: %v", err)
+	}
+	if _, ok := nv.Value.(GUID); !ok {
+		t.Errorf("expected GUID to be bound as SQL_C_GUID, got %T", nv.Value)
+	}
+}
+
+func TestGUIDFromStruct(t *testing.T) {
+	s := SQL_GUID_STRUCT{
+		Data1: 0x550E8400,
+		Data2: 0xE29B,
+		Data3: 0x41D4,
+		Data4: [8]byte{0xA7, 0x16, 0x44, 0x66, 0x55, 0x44, 0x00, 0x00},
+	}
+	want, _ := ParseGUID("550e8400-e29b-41d4-a716-446655440000")
+	got := guidFromStruct(s)
+	if got != want {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+	if got.String() != s.String() {
+		t.Errorf("expected %s, got %s", s.String(), got.String())
+	}
+	if guidStruct(got) != s {
+		t.Errorf("round trip mismatch: %+v", guidStruct(got))
+	}
+}
+
 // =============================================================================
-// UTF-16 Conversion Tests (rows.go)
+// UTF-16 Conversion Tests (convert.go)
 // =============================================================================
 
 func TestUTF16ToString_ASCII(t *testing.T) {
@@ -409,6 +614,39 @@ func TestUTF16ToString_Empty(t *testing.T) {
 	}
 }
 
+func TestUTF16ToString_UnpairedSurrogates(t *testing.T) {
+	tests := []struct {
+		input    []uint16
+		expected string
+	}{
+		{[]uint16{'a', 0xD83D}, "a\uFFFD"},
+		{[]uint16{0xDE00, 'b'}, "\uFFFDb"},
+		{[]uint16{0xD83D, 0xD83D, 0xDE00}, "\uFFFD😀"},
+	}
+	for _, tt := range tests {
+		if got := utf16ToString(tt.input); got != tt.expected {
+			t.Errorf("utf16ToString(%04X) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestUTF16_RoundTripLong(t *testing.T) {
+	inputs := []string{
+		strings.Repeat("abcdefgh", 1000),
+		strings.Repeat("abcdefgh", 1000) + "中文😀",
+		"x" + strings.Repeat("中文😀 mixed text ", 500),
+	}
+	for _, input := range inputs {
+		encoded := stringToUTF16(input)
+		if encoded[len(encoded)-1] != 0 {
+			t.Fatal("expected null terminator")
+		}
+		if got := utf16ToString(encoded[:len(encoded)-1]); got != input {
+			t.Errorf("round trip mismatch for input of length %d", len(input))
+		}
+	}
+}
+
 // =============================================================================
 // SQL_GUID_STRUCT Tests (types.go)
 // =============================================================================
@@ -596,6 +834,78 @@ func TestIsRetryable(t *testing.T) {
 	}
 }
 
+func TestIsFunctionSequenceError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{&Error{SQLState: "HY010"}, true},
+		{&Error{SQLState: "HY000"}, false},
+		{Errors{{SQLState: "01000"}, {SQLState: "HY010"}}, true},
+		{Errors{{SQLState: "42S02"}}, false},
+		{errors.New("HY010"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		result := IsFunctionSequenceError(tt.err)
+		if result != tt.expected {
+			t.Errorf("IsFunctionSequenceError(%v): expected %v, got %v", tt.err, tt.expected, result)
+		}
+	}
+}
+
+func TestIsSchemaChangeError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{&Error{SQLState: "24000"}, false},
+		{&Error{SQLState: "26000", Message: "prepared statement \"p1\" does not exist"}, true},
+		{&Error{SQLState: "0A000", Message: "ERROR: cached plan must not change result type"}, true},
+		{&Error{SQLState: "0A000", Message: "feature not supported"}, false},
+		{Errors{{SQLState: "01000"}, {SQLState: "26000"}}, true},
+		{Errors{{SQLState: "01000"}, {SQLState: "24000"}}, false},
+		{&Error{SQLState: "42S02"}, false},
+		{errors.New("24000"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		result := IsSchemaChangeError(tt.err)
+		if result != tt.expected {
+			t.Errorf("IsSchemaChangeError(%v): expected %v, got %v", tt.err, tt.expected, result)
+		}
+	}
+}
+
+func TestSequenceError(t *testing.T) {
+	cause := &Error{SQLState: "22001", Message: "String data, right truncation"}
+	seq := &Error{SQLState: "HY010", Message: "Function sequence error"}
+	err := error(&SequenceError{Cause: cause, Err: seq})
+
+	if !strings.Contains(err.Error(), "String data, right truncation") {
+		t.Errorf("message should name the cause, got %q", err.Error())
+	}
+	if !errors.Is(err, &Error{SQLState: "22001"}) {
+		t.Error("SequenceError should match its cause")
+	}
+	if !errors.Is(err, &Error{SQLState: "HY010"}) {
+		t.Error("SequenceError should match the function sequence error")
+	}
+}
+
+func TestStmt_PreparedQuery(t *testing.T) {
+	s := &Stmt{query: "SELECT * FROM t WHERE id = ?"}
+	if got := s.preparedQuery(); got != s.query {
+		t.Errorf("preparedQuery() = %q", got)
+	}
+	s = &Stmt{query: "SELECT * FROM t WHERE id = :id", namedParams: &NamedParams{Query: "SELECT * FROM t WHERE id = ?"}}
+	if got := s.preparedQuery(); got != "SELECT * FROM t WHERE id = ?" {
+		t.Errorf("preparedQuery() with named params = %q", got)
+	}
+}
+
 func TestError_Is(t *testing.T) {
 	err1 := &Error{SQLState: "42S02", NativeError: 208, Message: "Table not found"}
 	err2 := &Error{SQLState: "42S02", NativeError: 100, Message: "Different message"}
@@ -1269,6 +1579,21 @@ func TestBatchResult_HasErrors(t *testing.T) {
 	}
 }
 
+func TestBatchResult_Unprocessed(t *testing.T) {
+	br := &BatchResult{
+		StatusCodes:   []uint16{SQL_PARAM_SUCCESS, SQL_PARAM_ERROR, SQL_PARAM_UNUSED, SQL_PARAM_UNUSED},
+		RowsProcessed: 2,
+	}
+	unprocessed := br.Unprocessed()
+	if len(unprocessed) != 2 || unprocessed[0] != 2 || unprocessed[1] != 3 {
+		t.Errorf("expected [2 3], got %v", unprocessed)
+	}
+
+	if got := (&BatchResult{}).Unprocessed(); len(got) != 0 {
+		t.Errorf("expected no unprocessed rows for empty batch, got %v", got)
+	}
+}
+
 // CursorType Tests
 
 func TestCursorType_Constants(t *testing.T) {
@@ -1286,6 +1611,26 @@ func TestCursorType_Constants(t *testing.T) {
 	}
 }
 
+// QueryScrollable Tests
+
+func TestRows_ImplementsScrollableRows(t *testing.T) {
+	var _ ScrollableRows = (*Rows)(nil)
+}
+
+func TestRows_RowCountClosed(t *testing.T) {
+	r := &Rows{closed: true}
+	if _, err := r.RowCount(); err != io.EOF {
+		t.Errorf("expected io.EOF for closed rows, got %v", err)
+	}
+}
+
+func TestConn_QueryScrollableForwardOnly(t *testing.T) {
+	c := &Conn{}
+	if _, err := c.QueryScrollable(context.Background(), "SELECT 1", CursorForwardOnly); err == nil {
+		t.Error("expected an error for a forward-only cursor")
+	}
+}
+
 // LastInsertIdBehavior Tests
 
 func TestLastInsertIdBehavior_Constants(t *testing.T) {
@@ -1538,6 +1883,36 @@ func TestParseNamedParams_Underscore(t *testing.T) {
 	}
 }
 
+func TestParseNamedParams_CastsAndSystemVariables(t *testing.T) {
+	// PostgreSQL casts and SQL Server @@ variables are not parameters
+	result := ParseNamedParams("SELECT :id::text, @@ROWCOUNT, created::date FROM t WHERE a = @a")
+	if result == nil {
+		t.Fatal("expected non-nil result")
+	}
+	if want := []string{"id", "a"}; !reflect.DeepEqual(result.Names, want) {
+		t.Errorf("Names = %v, want %v", result.Names, want)
+	}
+	if want := "SELECT ?::text, @@ROWCOUNT, created::date FROM t WHERE a = ?"; result.Query != want {
+		t.Errorf("Query = %q, want %q", result.Query, want)
+	}
+
+	if result := ParseNamedParams("SELECT x::int, @@VERSION"); result != nil {
+		t.Errorf("expected nil result without parameters, got %+v", result)
+	}
+}
+
+func TestBindNamedParams_UnknownName(t *testing.T) {
+	s := &Stmt{namedParams: ParseNamedParams("UPDATE t SET a = :a WHERE id = :id")}
+	err := s.bindNamedParams([]driver.NamedValue{
+		{Name: "a", Ordinal: 1, Value: int64(1)},
+		{Name: "ID", Ordinal: 2, Value: int64(2)},
+	})
+	var paramErr *ParameterError
+	if !errors.As(err, &paramErr) || paramErr.Name != "ID" {
+		t.Fatalf("expected ParameterError for ID, got %v", err)
+	}
+}
+
 func TestParameterError(t *testing.T) {
 	err := &ParameterError{Name: "foo", Message: "missing value"}
 	expected := "parameter 'foo': missing value"
@@ -1725,3 +2100,7212 @@ func TestWithQueryTimeout(t *testing.T) {
 		t.Errorf("expected 5s timeout, got %v", connector.QueryTimeout)
 	}
 }
+
+func TestConn_QueryTimeoutSecs(t *testing.T) {
+	withTimeout := func(d time.Duration) context.Context {
+		ctx, cancel := context.WithTimeout(context.Background(), d)
+		t.Cleanup(cancel)
+		return ctx
+	}
+	tests := []struct {
+		name string
+		conn time.Duration
+		ctx  context.Context
+		want uintptr
+	}{
+		{"none", 0, context.Background(), 0},
+		{"connection default", 30 * time.Second, context.Background(), 30},
+		{"connection default rounded up", 1500 * time.Millisecond, context.Background(), 2},
+		{"deadline rounded up", 0, withTimeout(90 * time.Second), 90},
+		{"sub-second deadline", 0, withTimeout(200 * time.Millisecond), 1},
+		{"deadline shorter than default", time.Minute, withTimeout(10 * time.Second), 10},
+		{"default shorter than deadline", 5 * time.Second, withTimeout(time.Minute), 5},
+	}
+	for _, tt := range tests {
+		c := &Conn{queryTimeout: tt.conn}
+		if got := c.queryTimeoutSecs(tt.ctx); got != tt.want {
+			t.Errorf("%s: queryTimeoutSecs() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// =============================================================================
+// Column Cast Tests (cast.go)
+// =============================================================================
+
+func TestColumnCasts_Resolve(t *testing.T) {
+	casts := ColumnCasts{"id": CastInt64, "CREATED_AT": CastTime}
+	resolved := casts.resolve([]string{"id", "name", "created_at"})
+	if len(resolved) != 3 {
+		t.Fatalf("expected 3 resolved casts, got %d", len(resolved))
+	}
+	if resolved[0] != CastInt64 || resolved[1] != CastNone || resolved[2] != CastTime {
+		t.Errorf("unexpected resolved casts: %v", resolved)
+	}
+
+	if got := casts.resolve([]string{"other"}); got != nil {
+		t.Errorf("expected nil when no columns match, got %v", got)
+	}
+	if got := ColumnCasts(nil).resolve([]string{"id"}); got != nil {
+		t.Errorf("expected nil for nil cast map, got %v", got)
+	}
+}
+
+func TestCastValue(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		value    interface{}
+		cast     CastType
+		expected interface{}
+	}{
+		{"nil stays nil", nil, CastInt64, nil},
+		{"none passthrough", int64(5), CastNone, int64(5)},
+		{"int64 to string", int64(42), CastString, "42"},
+		{"float64 to string", 1.5, CastString, "1.5"},
+		{"time to string", ts, CastString, "2024-01-15T10:30:00Z"},
+		{"string to int64", "123", CastInt64, int64(123)},
+		{"decimal string to int64", "42.000", CastInt64, int64(42)},
+		{"bool to int64", true, CastInt64, int64(1)},
+		{"integral float64 to int64", float64(42), CastInt64, int64(42)},
+		{"string to float64", "3.25", CastFloat64, 3.25},
+		{"int64 to float64", int64(7), CastFloat64, float64(7)},
+		{"string to time", "2024-01-15 10:30:00", CastTime, ts},
+		{"string to bytes", "abc", CastBytes, []byte("abc")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := castValue(tt.value, tt.cast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCastValue_Invalid(t *testing.T) {
+	if _, err := castValue("abc", CastInt64); err == nil {
+		t.Error("expected error casting 'abc' to int64")
+	}
+	if _, err := castValue("1.5", CastInt64); err == nil {
+		t.Error("expected error casting '1.5' to int64")
+	}
+	if _, err := castValue(1.5, CastInt64); err == nil {
+		t.Error("expected error casting 1.5 to int64, as for the string '1.5'")
+	}
+	if _, err := castValue(math.Inf(1), CastInt64); err == nil {
+		t.Error("expected error casting +Inf to int64")
+	}
+	if _, err := castValue("not a date", CastTime); err == nil {
+		t.Error("expected error casting invalid date to time.Time")
+	}
+}
+
+func TestRows_ColumnTypeScanType_Cast(t *testing.T) {
+	r := &Rows{
+		columns:  []string{"amount"},
+		colTypes: []SQLSMALLINT{SQL_DECIMAL},
+	}
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf("") {
+		t.Errorf("expected string scan type without cast, got %v", got)
+	}
+	r.setColumnCasts(ColumnCasts{"amount": CastFloat64})
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf(float64(0)) {
+		t.Errorf("expected float64 scan type with cast, got %v", got)
+	}
+}
+
+// =============================================================================
+// Null Bitmap Fetch Tests (rows.go)
+// =============================================================================
+
+func TestRows_ZeroValue(t *testing.T) {
+	r := &Rows{
+		columns:  []string{"a", "b", "c", "d", "e"},
+		colTypes: []SQLSMALLINT{SQL_INTEGER, SQL_VARCHAR, SQL_TYPE_TIMESTAMP, SQL_VARBINARY, SQL_UNKNOWN_TYPE},
+	}
+	expected := []interface{}{int64(0), "", time.Time{}, []byte(nil), nil}
+	for i, want := range expected {
+		if got := r.zeroValue(i); !reflect.DeepEqual(got, want) {
+			t.Errorf("column %d: expected %#v, got %#v", i, want, got)
+		}
+	}
+}
+
+func TestRows_NextWithNulls_ShortNulls(t *testing.T) {
+	r := &Rows{closed: true}
+	dest := make([]driver.Value, 2)
+	if err := r.NextWithNulls(dest, make([]bool, 1)); err == nil {
+		t.Error("expected error for short nulls slice")
+	}
+}
+
+// =============================================================================
+// Multi-Row INSERT Synthesis Tests (batch_insert.go)
+// =============================================================================
+
+func TestParseMultiRowInsert(t *testing.T) {
+	insert, ok := parseMultiRowInsert("INSERT INTO t (a, b, c) VALUES (?, ?, ?);")
+	if !ok {
+		t.Fatal("expected INSERT to be parsed")
+	}
+	if insert.numParams != 3 {
+		t.Errorf("expected 3 params, got %d", insert.numParams)
+	}
+	if insert.row != "(?, ?, ?)" {
+		t.Errorf("unexpected row group: %q", insert.row)
+	}
+	expected := "INSERT INTO t (a, b, c) VALUES (?, ?, ?), (?, ?, ?)"
+	if got := insert.build(2); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestParseMultiRowInsert_Expressions(t *testing.T) {
+	insert, ok := parseMultiRowInsert("insert into t values (?, UPPER(?), 'a?b')")
+	if !ok {
+		t.Fatal("expected INSERT to be parsed")
+	}
+	if insert.numParams != 2 {
+		t.Errorf("expected 2 params (quoted ? ignored), got %d", insert.numParams)
+	}
+}
+
+func TestParseMultiRowInsert_Unsupported(t *testing.T) {
+	queries := []string{
+		"UPDATE t SET a = ?",
+		"INSERT INTO t SELECT * FROM s WHERE a = ?",
+		"INSERT INTO t VALUES (?, ?) RETURNING id",
+		"INSERT INTO t VALUES (1, 2)",
+		"INSERT INTO t (values_col) SELECT ?",
+	}
+	for _, q := range queries {
+		if _, ok := parseMultiRowInsert(q); ok {
+			t.Errorf("expected %q not to be expandable", q)
+		}
+	}
+}
+
+func TestMultiRowInsert_RowsPerStatement(t *testing.T) {
+	insert := &multiRowInsert{numParams: 3}
+	if got := insert.rowsPerStatement(2100); got != 700 {
+		t.Errorf("expected 700 rows for SQL Server limit, got %d", got)
+	}
+	if got := insert.rowsPerStatement(65535); got != maxValuesRows {
+		t.Errorf("expected rows capped at %d, got %d", maxValuesRows, got)
+	}
+}
+
+func TestStmt_ExecBatchMultiRow_WideRow(t *testing.T) {
+	// A row of 1000 parameters exceeds SQLite's limit of 999
+	query := "INSERT INTO t VALUES (?" + strings.Repeat(", ?", 999) + ")"
+	s := &Stmt{conn: &Conn{dbType: "SQLite"}, query: query}
+	row := make([]driver.NamedValue, 1000)
+	paramSets := [][]driver.NamedValue{row, row}
+	result := &BatchResult{
+		RowCounts:   make([]int64, 2),
+		Errors:      make([]error, 2),
+		StatusCodes: make([]uint16, 2),
+	}
+	if s.execBatchMultiRow(context.Background(), paramSets, result) {
+		t.Fatal("expected rows wider than the parameter limit to fall back to row-by-row execution")
+	}
+	for i, err := range result.Errors {
+		if err != nil {
+			t.Errorf("row %d: unexpected error %v", i, err)
+		}
+	}
+}
+
+func TestConn_ParamLimit(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		expected int
+	}{
+		{"Microsoft SQL Server", 2100},
+		{"PostgreSQL", 65535},
+		{"SQLite", 999},
+		{"", defaultParamLimit},
+		{"SomeOtherDB", defaultParamLimit},
+	}
+	for _, tt := range tests {
+		c := &Conn{dbType: tt.dbType}
+		if got := c.paramLimit(); got != tt.expected {
+			t.Errorf("%q: expected %d, got %d", tt.dbType, tt.expected, got)
+		}
+	}
+}
+
+func TestWithMultiRowInsert(t *testing.T) {
+	connector := &Connector{}
+	WithMultiRowInsert(true)(connector)
+	if !connector.MultiRowInsert {
+		t.Error("expected MultiRowInsert to be enabled")
+	}
+}
+
+// =============================================================================
+// Capabilities and IN Expansion Tests (capabilities.go)
+// =============================================================================
+
+func TestConn_Capabilities(t *testing.T) {
+	c := &Conn{dbType: "Microsoft SQL Server"}
+	caps := c.Capabilities()
+	if caps.DBMSName != "Microsoft SQL Server" {
+		t.Errorf("unexpected DBMSName: %q", caps.DBMSName)
+	}
+	if caps.MaxParams != 2100 {
+		t.Errorf("expected MaxParams 2100, got %d", caps.MaxParams)
+	}
+
+	// Database limits above the driver's binding limit are capped
+	c = &Conn{dbType: "PostgreSQL"}
+	if got := c.Capabilities().MaxParams; got != maxParameters {
+		t.Errorf("expected MaxParams capped at %d, got %d", maxParameters, got)
+	}
+}
+
+func TestConn_DetectDriverLimits(t *testing.T) {
+	orig := sqlGetInfo
+	t.Cleanup(func() { sqlGetInfo = orig })
+	var lengths []SQLSMALLINT
+	sqlGetInfo = func(_ SQLHDBC, infoType SQLUSMALLINT, value uintptr, bufferLength SQLSMALLINT, _ *SQLSMALLINT) SQLRETURN {
+		lengths = append(lengths, bufferLength)
+		switch infoType {
+		case SQL_MAX_CONCURRENT_ACTIVITIES:
+			*(*SQLUSMALLINT)(unsafe.Add(nil, value)) = 1
+		case SQL_GETDATA_EXTENSIONS:
+			*(*SQLUINTEGER)(unsafe.Add(nil, value)) = SQL_GD_ANY_COLUMN | SQL_GD_BOUND
+		default:
+			return SQL_ERROR
+		}
+		return SQL_SUCCESS
+	}
+
+	c := &Conn{dbc: 1}
+	c.detectDriverLimits()
+	if !reflect.DeepEqual(lengths, []SQLSMALLINT{2, 4}) {
+		t.Errorf("expected integer buffers of 2 and 4 bytes, got %v", lengths)
+	}
+	caps := c.Capabilities()
+	if caps.MaxConcurrentActivities != 1 || caps.GetDataExtensions != SQL_GD_ANY_COLUMN|SQL_GD_BOUND {
+		t.Errorf("unexpected limits %d, %#x", caps.MaxConcurrentActivities, caps.GetDataExtensions)
+	}
+	r := &Rows{
+		stmt:        &Stmt{conn: c},
+		colTypes:    []SQLSMALLINT{SQL_LONGVARCHAR, SQL_LONGVARBINARY},
+		sizeUnknown: []bool{false, false},
+	}
+	r.setLOBStreaming(true)
+	if want := []SQLSMALLINT{0, SQL_C_BINARY}; !reflect.DeepEqual(r.lobTypes, want) {
+		t.Errorf("lobTypes = %v, want %v without SQL_GD_ANY_ORDER", r.lobTypes, want)
+	}
+
+	// Drivers that do not report their extensions keep streaming every trailing column
+	sqlGetInfo = func(SQLHDBC, SQLUSMALLINT, uintptr, SQLSMALLINT, *SQLSMALLINT) SQLRETURN { return SQL_ERROR }
+	c = &Conn{dbc: 1}
+	c.detectDriverLimits()
+	r.stmt.conn = c
+	r.setLOBStreaming(true)
+	if want := []SQLSMALLINT{SQL_C_CHAR, SQL_C_BINARY}; !reflect.DeepEqual(r.lobTypes, want) {
+		t.Errorf("lobTypes = %v, want %v", r.lobTypes, want)
+	}
+}
+
+func TestConn_SupportedIsolationLevels(t *testing.T) {
+	c := &Conn{
+		defaultTxnIsolation: SQL_TXN_READ_COMMITTED,
+		txnIsolationOptions: SQL_TXN_SERIALIZABLE | SQL_TXN_READ_COMMITTED | SQL_TXN_SS_SNAPSHOT,
+	}
+	want := []sql.IsolationLevel{sql.LevelReadCommitted, sql.LevelSnapshot, sql.LevelSerializable}
+	if got := c.SupportedIsolationLevels(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SupportedIsolationLevels() = %v, want %v", got, want)
+	}
+	caps := c.Capabilities()
+	if caps.DefaultIsolation != sql.LevelReadCommitted {
+		t.Errorf("DefaultIsolation = %v, want Read Committed", caps.DefaultIsolation)
+	}
+	if !reflect.DeepEqual(caps.IsolationLevels, want) {
+		t.Errorf("IsolationLevels = %v, want %v", caps.IsolationLevels, want)
+	}
+
+	// Drivers that do not report isolation levels
+	c = &Conn{}
+	if got := c.SupportedIsolationLevels(); got != nil {
+		t.Errorf("SupportedIsolationLevels() = %v, want nil", got)
+	}
+	if got := c.Capabilities().DefaultIsolation; got != sql.LevelDefault {
+		t.Errorf("DefaultIsolation = %v, want Default", got)
+	}
+}
+
+func TestExpandIn(t *testing.T) {
+	values := []interface{}{1, 2, 3, 4, 5}
+	queries, err := ExpandIn("SELECT * FROM t WHERE a = ? AND id in ( ? ) AND b = ?", values, []interface{}{"x", "y"}, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(queries))
+	}
+	if queries[0].Query != "SELECT * FROM t WHERE a = ? AND id IN (?, ?, ?) AND b = ?" {
+		t.Errorf("unexpected first query: %q", queries[0].Query)
+	}
+	if !reflect.DeepEqual(queries[0].Args, []interface{}{"x", 1, 2, 3, "y"}) {
+		t.Errorf("unexpected first args: %v", queries[0].Args)
+	}
+	if queries[1].Query != "SELECT * FROM t WHERE a = ? AND id IN (?, ?) AND b = ?" {
+		t.Errorf("unexpected second query: %q", queries[1].Query)
+	}
+	if !reflect.DeepEqual(queries[1].Args, []interface{}{"x", 4, 5, "y"}) {
+		t.Errorf("unexpected second args: %v", queries[1].Args)
+	}
+}
+
+func TestExpandIn_Errors(t *testing.T) {
+	if _, err := ExpandIn("SELECT * FROM t WHERE id = ?", []interface{}{1}, nil, 10); err == nil {
+		t.Error("expected error for missing IN (?) marker")
+	}
+	if _, err := ExpandIn("SELECT * FROM t WHERE a IN (?) OR b IN (?)", []interface{}{1}, nil, 10); err == nil {
+		t.Error("expected error for multiple markers")
+	}
+	if _, err := ExpandIn("SELECT * FROM t WHERE a IN (?)", nil, nil, 10); err == nil {
+		t.Error("expected error for empty values")
+	}
+	_, err := ExpandIn("SELECT * FROM t WHERE a = ? AND b = ? AND id IN (?)", []interface{}{1}, []interface{}{1, 2}, 2)
+	if _, ok := err.(*ParamLimitError); !ok {
+		t.Errorf("expected *ParamLimitError, got %T (%v)", err, err)
+	}
+	// Markers inside string literals are ignored
+	if _, err := ExpandIn("SELECT 'IN (?)' WHERE id IN (?)", []interface{}{1}, nil, 10); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// =============================================================================
+// Environment Tests (environment.go)
+// =============================================================================
+
+func TestWithEnvironment(t *testing.T) {
+	env := &Environment{}
+	connector := &Connector{}
+	WithEnvironment(env)(connector)
+	if connector.Environment != env {
+		t.Error("expected Environment to be set")
+	}
+}
+
+func TestEnvironment_ClosedEndTran(t *testing.T) {
+	env := &Environment{closed: true}
+	if err := env.Commit(); err == nil {
+		t.Error("expected error committing on closed environment")
+	}
+	if err := env.Rollback(); err == nil {
+		t.Error("expected error rolling back on closed environment")
+	}
+	if err := env.Close(); err != nil {
+		t.Errorf("expected Close on closed environment to be a no-op, got %v", err)
+	}
+}
+
+// =============================================================================
+// Closed Statement Error Tests (errors.go, stmt.go)
+// =============================================================================
+
+func TestStmt_ClosedReturnsErrStmtClosed(t *testing.T) {
+	s := &Stmt{conn: &Conn{}, closed: true}
+
+	_, err := s.ExecContext(context.Background(), nil)
+	if !errors.Is(err, ErrStmtClosed) {
+		t.Errorf("ExecContext: expected ErrStmtClosed, got %v", err)
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		t.Error("ExecContext: closed statement must not report ErrBadConn")
+	}
+
+	_, err = s.QueryContext(context.Background(), nil)
+	if !errors.Is(err, ErrStmtClosed) {
+		t.Errorf("QueryContext: expected ErrStmtClosed, got %v", err)
+	}
+
+	_, err = s.ExecBatch(context.Background(), nil)
+	if !errors.Is(err, ErrStmtClosed) {
+		t.Errorf("ExecBatch: expected ErrStmtClosed, got %v", err)
+	}
+}
+
+func TestStmt_ClosedConnReturnsErrBadConn(t *testing.T) {
+	s := &Stmt{conn: &Conn{closed: true}}
+	_, err := s.ExecContext(context.Background(), nil)
+	if !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("expected ErrBadConn for closed connection, got %v", err)
+	}
+}
+
+// =============================================================================
+// Zero-Column Result Tests (rows.go)
+// =============================================================================
+
+func TestRows_Next_NoColumns(t *testing.T) {
+	// Simulates an INSERT run via Query: no result columns. Next must return
+	// io.EOF without calling SQLFetch (which is not loaded in unit tests).
+	r := &Rows{stmt: &Stmt{}}
+	if err := r.Next(nil); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+	dest := make([]driver.Value, 0)
+	nulls := make([]bool, 0)
+	if err := r.NextWithNulls(dest, nulls); err != io.EOF {
+		t.Errorf("expected io.EOF from NextWithNulls, got %v", err)
+	}
+	if cols := r.Columns(); len(cols) != 0 {
+		t.Errorf("expected no columns, got %v", cols)
+	}
+}
+
+// =============================================================================
+// Column Metadata Cache Tests (rows.go)
+// =============================================================================
+
+// fakeResultColumns makes the ODBC stubs describe a result set with the given
+// column names, all INTEGER, and returns a counter of SQLDescribeCol calls
+func fakeResultColumns(t *testing.T, names ...string) *int {
+	t.Helper()
+	origNum, origDescribe, origAttr := sqlNumResultCols, sqlDescribeCol, sqlColAttribute
+	t.Cleanup(func() { sqlNumResultCols, sqlDescribeCol, sqlColAttribute = origNum, origDescribe, origAttr })
+
+	calls := new(int)
+	sqlNumResultCols = func(_ SQLHSTMT, count *SQLSMALLINT) SQLRETURN {
+		*count = SQLSMALLINT(len(names))
+		return SQL_SUCCESS
+	}
+	sqlDescribeCol = func(_ SQLHSTMT, colNum SQLUSMALLINT, colName *byte, bufferLen SQLSMALLINT, nameLen *SQLSMALLINT, dataType *SQLSMALLINT, colSize *SQLULEN, decDigits *SQLSMALLINT, nullable *SQLSMALLINT) SQLRETURN {
+		*calls++
+		name := names[colNum-1]
+		copy(unsafe.Slice(colName, bufferLen), name+"\x00")
+		*nameLen = SQLSMALLINT(len(name))
+		*dataType, *colSize, *decDigits, *nullable = SQL_INTEGER, 10, 0, SQL_NULLABLE
+		return SQL_SUCCESS
+	}
+	sqlColAttribute = func(SQLHSTMT, SQLUSMALLINT, SQLUSMALLINT, uintptr, SQLSMALLINT, *SQLSMALLINT, *SQLLEN) SQLRETURN {
+		return SQL_ERROR
+	}
+	return calls
+}
+
+func TestNewRows_CachesResultColumns(t *testing.T) {
+	calls := fakeResultColumns(t, "id", "name")
+	s := &Stmt{conn: &Conn{}, query: "SELECT id, name FROM users WHERE id = ?"}
+
+	for i := 0; i < 3; i++ {
+		r, err := newRows(s, false)
+		if err != nil {
+			t.Fatalf("newRows failed: %v", err)
+		}
+		if !reflect.DeepEqual(r.Columns(), []string{"id", "name"}) || r.colTypes[1] != SQL_INTEGER {
+			t.Fatalf("unexpected columns %v %v", r.Columns(), r.colTypes)
+		}
+	}
+	if *calls != 2 {
+		t.Errorf("expected the columns to be described once, got %d SQLDescribeCol calls", *calls)
+	}
+
+	// A different column count means the result changed
+	calls = fakeResultColumns(t, "id", "name", "email")
+	r, err := newRows(s, false)
+	if err != nil {
+		t.Fatalf("newRows failed: %v", err)
+	}
+	if *calls != 3 || len(r.Columns()) != 3 {
+		t.Errorf("expected the new result to be described, got %d calls and columns %v", *calls, r.Columns())
+	}
+}
+
+func TestNewRows_ResultColumnsNotCached(t *testing.T) {
+	fakeResultColumns(t, "id")
+
+	owned := &Stmt{conn: &Conn{}, query: "SELECT id FROM users"}
+	if _, err := newRows(owned, true); err != nil {
+		t.Fatalf("newRows failed: %v", err)
+	}
+	if owned.resultCols != nil {
+		t.Error("expected no cache on a statement owned by its rows")
+	}
+
+	proc := &Stmt{conn: &Conn{}, query: "{CALL dbo.list_users}"}
+	if _, err := newRows(proc, false); err != nil {
+		t.Fatalf("newRows failed: %v", err)
+	}
+	if proc.resultCols != nil {
+		t.Error("expected no cache for a procedure call, whose results can differ per execution")
+	}
+}
+
+func TestRows_NextResultSet_ClearsResultColumns(t *testing.T) {
+	fakeResultColumns(t, "id")
+	origMore := sqlMoreResults
+	t.Cleanup(func() { sqlMoreResults = origMore })
+	sqlMoreResults = func(SQLHSTMT) SQLRETURN { return SQL_SUCCESS }
+
+	s := &Stmt{conn: &Conn{}, query: "SELECT id FROM a; SELECT id FROM b"}
+	r, err := newRows(s, false)
+	if err != nil {
+		t.Fatalf("newRows failed: %v", err)
+	}
+	if s.resultCols == nil {
+		t.Fatal("expected the first result set to be cached")
+	}
+	if err := r.NextResultSet(); err != nil {
+		t.Fatalf("NextResultSet failed: %v", err)
+	}
+	if s.resultCols != nil {
+		t.Error("expected NextResultSet to clear the cached columns")
+	}
+}
+
+// =============================================================================
+// Unsigned Integer Tests (rows.go)
+// =============================================================================
+
+func TestDescribeResult_Unsigned(t *testing.T) {
+	fakeResultColumns(t, "id", "n")
+	sqlColAttribute = func(_ SQLHSTMT, colNum SQLUSMALLINT, fieldId SQLUSMALLINT, _ uintptr, _ SQLSMALLINT, _ *SQLSMALLINT, numAttr *SQLLEN) SQLRETURN {
+		if fieldId != SQL_DESC_UNSIGNED {
+			return SQL_ERROR
+		}
+		*numAttr = SQL_FALSE
+		if colNum == 2 {
+			*numAttr = SQL_TRUE
+		}
+		return SQL_SUCCESS
+	}
+
+	rc, err := describeResult(&Stmt{conn: &Conn{}}, 2)
+	if err != nil {
+		t.Fatalf("describeResult failed: %v", err)
+	}
+	if !reflect.DeepEqual(rc.unsigned, []bool{false, true}) {
+		t.Errorf("expected only the second column to be unsigned, got %v", rc.unsigned)
+	}
+}
+
+func TestRows_UnsignedBigint(t *testing.T) {
+	origGetData := sqlGetData
+	t.Cleanup(func() { sqlGetData = origGetData })
+	var gotType SQLSMALLINT
+	sqlGetData = func(_ SQLHSTMT, _ SQLUSMALLINT, targetType SQLSMALLINT, targetValue uintptr, _ SQLLEN, ind *SQLLEN) SQLRETURN {
+		gotType = targetType
+		*(*uint64)(unsafe.Add(nil, targetValue)) = math.MaxUint64
+		*ind = 8
+		return SQL_SUCCESS
+	}
+
+	r := &Rows{
+		stmt:     &Stmt{},
+		columns:  []string{"big", "small"},
+		colTypes: []SQLSMALLINT{SQL_BIGINT, SQL_TINYINT},
+		colSizes: []SQLULEN{20, 3},
+		unsigned: []bool{true, true},
+	}
+	v, err := r.fetchColumnData(1)
+	if err != nil || v != uint64(math.MaxUint64) || gotType != SQL_C_UBIGINT {
+		t.Errorf("expected uint64 max via SQL_C_UBIGINT, got %#v (type %d, %v)", v, gotType, err)
+	}
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf(uint64(0)) {
+		t.Errorf("expected uint64 scan type, got %v", got)
+	}
+
+	// Smaller unsigned types are widened to int64
+	if _, err := r.fetchColumnData(2); err != nil || gotType != SQL_C_SBIGINT {
+		t.Errorf("expected unsigned TINYINT to be fetched as SQL_C_SBIGINT, got type %d (%v)", gotType, err)
+	}
+	if got := r.ColumnTypeScanType(1); got != reflect.TypeOf(int64(0)) {
+		t.Errorf("expected int64 scan type, got %v", got)
+	}
+}
+
+func TestCastToInt64_Uint64(t *testing.T) {
+	if v, err := castToInt64(uint64(42)); err != nil || v != int64(42) {
+		t.Errorf("expected 42, got %v (%v)", v, err)
+	}
+	if _, err := castToInt64(uint64(math.MaxUint64)); err == nil {
+		t.Error("expected an out of range error")
+	}
+}
+
+// =============================================================================
+// Raw Column Tests (rawcolumns.go)
+// =============================================================================
+
+func TestRows_SetColumnRaw(t *testing.T) {
+	r := &Rows{columns: []string{"id", "amount"}}
+	if err := r.SetColumnRaw(2, true); err == nil {
+		t.Error("expected an error for an out of range index")
+	}
+	if err := r.SetColumnRaw(0, false); err != nil || r.raw != nil {
+		t.Errorf("expected clearing an unset column to be a no-op, got %v %v", r.raw, err)
+	}
+	if err := r.SetColumnRaw(1, true); err != nil {
+		t.Fatalf("SetColumnRaw failed: %v", err)
+	}
+	if r.isRaw(0) || !r.isRaw(1) {
+		t.Errorf("expected only column 1 to be raw, got %v", r.raw)
+	}
+
+	r.blockChecked = true
+	if err := r.SetColumnRaw(0, true); err == nil {
+		t.Error("expected an error after the first Next")
+	}
+	r.closed = true
+	if err := r.SetColumnRaw(0, true); err == nil {
+		t.Error("expected an error on closed rows")
+	}
+}
+
+func TestRows_GetColumnData_Raw(t *testing.T) {
+	origGetData := sqlGetData
+	t.Cleanup(func() { sqlGetData = origGetData })
+	var gotType SQLSMALLINT
+	sqlGetData = func(_ SQLHSTMT, _ SQLUSMALLINT, targetType SQLSMALLINT, targetValue uintptr, bufferLen SQLLEN, ind *SQLLEN) SQLRETURN {
+		gotType = targetType
+		const text = "12.3400"
+		copy(unsafe.Slice((*byte)(unsafe.Add(nil, targetValue)), bufferLen), text+"\x00")
+		*ind = SQLLEN(len(text))
+		return SQL_SUCCESS
+	}
+
+	r := &Rows{
+		stmt:      &Stmt{conn: &Conn{decimalFetchMode: DecimalFetchNumeric}},
+		columns:   []string{"amount"},
+		colTypes:  []SQLSMALLINT{SQL_DECIMAL},
+		colSizes:  []SQLULEN{10},
+		decDigits: []SQLSMALLINT{4},
+		casts:     []CastType{CastFloat64},
+	}
+	if err := r.SetColumnRaw(0, true); err != nil {
+		t.Fatalf("SetColumnRaw failed: %v", err)
+	}
+	v, err := r.getColumnData(1)
+	if err != nil || !reflect.DeepEqual(v, []byte("12.3400")) || gotType != SQL_C_CHAR {
+		t.Errorf("expected the raw text via SQL_C_CHAR, got %#v (type %d, %v)", v, gotType, err)
+	}
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf([]byte{}) {
+		t.Errorf("expected []byte scan type, got %v", got)
+	}
+}
+
+func TestRawBlockLayout(t *testing.T) {
+	if cType, size, ok := rawBlockLayout(SQL_VARBINARY, 16, false); !ok || cType != SQL_C_BINARY || size != 16 {
+		t.Errorf("expected (SQL_C_BINARY, 16), got (%d, %d, %v)", cType, size, ok)
+	}
+	if cType, size, ok := rawBlockLayout(SQL_TYPE_TIMESTAMP, 27, false); !ok || cType != SQL_C_CHAR || size != 109 {
+		t.Errorf("expected (SQL_C_CHAR, 109), got (%d, %d, %v)", cType, size, ok)
+	}
+	if _, _, ok := rawBlockLayout(SQL_VARCHAR, 0, true); ok {
+		t.Error("expected a column of unknown size not to be block fetched")
+	}
+}
+
+func TestRows_BlockValue_Raw(t *testing.T) {
+	col := blockColumn{cType: SQL_C_CHAR, elemSize: 8, data: make([]byte, 8), ind: []SQLLEN{4}}
+	copy(col.data, "1.50\x00")
+	r := &Rows{
+		stmt:      &Stmt{conn: &Conn{decimalFetchMode: DecimalFetchNumeric}},
+		columns:   []string{"amount"},
+		colTypes:  []SQLSMALLINT{SQL_DECIMAL},
+		colSizes:  []SQLULEN{3},
+		decDigits: []SQLSMALLINT{2},
+		raw:       []bool{true},
+		block:     &blockFetch{columns: []blockColumn{col}},
+	}
+	v, err := r.blockValue(0)
+	if err != nil || !reflect.DeepEqual(v, []byte("1.50")) {
+		t.Errorf("expected raw bytes 1.50, got %#v (%v)", v, err)
+	}
+}
+
+// =============================================================================
+// Column Size Limit Tests (columnlimit.go)
+// =============================================================================
+
+// fakeColumnValue makes SQLGetData return value in parts as drivers do,
+// reporting the remaining length, or SQL_NO_TOTAL if noTotal is set
+func fakeColumnValue(t *testing.T, value []byte, noTotal bool) {
+	orig := sqlGetData
+	t.Cleanup(func() { sqlGetData = orig })
+	offset := 0
+	sqlGetData = func(_ SQLHSTMT, _ SQLUSMALLINT, targetType SQLSMALLINT, targetValue uintptr, bufferLen SQLLEN, ind *SQLLEN) SQLRETURN {
+		if offset == len(value) && offset > 0 {
+			return SQL_NO_DATA
+		}
+		term := 1
+		if targetType == SQL_C_BINARY {
+			term = 0
+		}
+		rest := value[offset:]
+		n := min(len(rest), int(bufferLen)-term)
+		buf := unsafe.Slice((*byte)(unsafe.Add(nil, targetValue)), bufferLen)
+		copy(buf, rest[:n])
+		if term > 0 {
+			buf[n] = 0
+		}
+		offset += n
+		*ind = SQLLEN(len(rest))
+		if n < len(rest) {
+			if noTotal {
+				*ind = SQL_NO_TOTAL
+			}
+			return SQL_SUCCESS_WITH_INFO
+		}
+		return SQL_SUCCESS
+	}
+}
+
+func limitedRows(policy TruncationPolicy, limit int, colType SQLSMALLINT) *Rows {
+	return &Rows{
+		stmt:        &Stmt{conn: &Conn{maxColumnBytes: limit, truncationPolicy: policy}},
+		columns:     []string{"body"},
+		colTypes:    []SQLSMALLINT{colType},
+		colSizes:    []SQLULEN{0},
+		sizeUnknown: []bool{true},
+	}
+}
+
+func TestRows_MaxColumnBytes_Error(t *testing.T) {
+	fakeColumnValue(t, bytes.Repeat([]byte("x"), 1000), false)
+	_, err := limitedRows(TruncationError, 100, SQL_VARCHAR).getColumnData(1)
+	var tooLarge *ColumnTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Column != "body" || tooLarge.Size != 1000 || tooLarge.Limit != 100 {
+		t.Fatalf("expected a *ColumnTooLargeError for 1000 bytes, got %v", err)
+	}
+
+	// Values within the limit are returned whole
+	fakeColumnValue(t, []byte("short"), false)
+	if v, err := limitedRows(TruncationError, 100, SQL_VARCHAR).getColumnData(1); err != nil || v != "short" {
+		t.Errorf("expected the value within the limit, got %#v (%v)", v, err)
+	}
+}
+
+func TestRows_MaxColumnBytes_Truncate(t *testing.T) {
+	// The cut falls inside the two-byte é and moves back before it
+	value := []byte(strings.Repeat("a", 99) + "é" + strings.Repeat("b", 300))
+	fakeColumnValue(t, value, false)
+	v, err := limitedRows(TruncationTruncate, 100, SQL_VARCHAR).getColumnData(1)
+	if err != nil || v != strings.Repeat("a", 99) {
+		t.Errorf("expected the first 99 bytes, got %q (%v)", v, err)
+	}
+
+	fakeColumnValue(t, bytes.Repeat([]byte{7}, 300000), false)
+	v, err = limitedRows(TruncationTruncate, 70000, SQL_VARBINARY).getColumnData(1)
+	if b, ok := v.([]byte); err != nil || !ok || len(b) != 70000 {
+		t.Errorf("expected 70000 bytes read past the first buffer, got %d bytes (%v)", len(b), err)
+	}
+}
+
+func TestRows_MaxColumnBytes_NoTotal(t *testing.T) {
+	// A driver that does not report lengths is read until the limit is passed
+	fakeColumnValue(t, bytes.Repeat([]byte("y"), 70000), true)
+	v, err := limitedRows(TruncationError, 100000, SQL_VARCHAR).getColumnData(1)
+	if s, ok := v.(string); err != nil || !ok || len(s) != 70000 {
+		t.Errorf("expected the whole 70000 byte value, got %d bytes (%v)", len(s), err)
+	}
+
+	fakeColumnValue(t, bytes.Repeat([]byte("y"), 200000), true)
+	_, err = limitedRows(TruncationError, 100000, SQL_VARCHAR).getColumnData(1)
+	var tooLarge *ColumnTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Size != -1 {
+		t.Errorf("expected a *ColumnTooLargeError of unknown size, got %v", err)
+	}
+}
+
+func TestRows_MaxColumnBytes_Stream(t *testing.T) {
+	value := bytes.Repeat([]byte("0123456789"), 30000)
+	fakeColumnValue(t, value, false)
+	v, err := limitedRows(TruncationStream, 1000, SQL_VARBINARY).getColumnData(1)
+	l, ok := v.(*LOBReader)
+	if err != nil || !ok {
+		t.Fatalf("expected a *LOBReader, got %T (%v)", v, err)
+	}
+	got, err := io.ReadAll(l)
+	if err != nil || !bytes.Equal(got, value) || l.Size() != int64(len(value)) {
+		t.Errorf("expected the whole value from the reader, got %d bytes, size %d (%v)", len(got), l.Size(), err)
+	}
+
+	// Columns before the last one cannot be streamed
+	fakeColumnValue(t, value, false)
+	r := limitedRows(TruncationStream, 1000, SQL_VARBINARY)
+	r.columns = append(r.columns, "id")
+	var tooLarge *ColumnTooLargeError
+	if _, err := r.getColumnData(1); !errors.As(err, &tooLarge) {
+		t.Errorf("expected a *ColumnTooLargeError for a column before the last, got %v", err)
+	}
+}
+
+// =============================================================================
+// Driver-Specific SQL Type Tests (sqltypes.go)
+// =============================================================================
+
+func TestSQLTypeName_VendorTypes(t *testing.T) {
+	tests := []struct {
+		sqlType  SQLSMALLINT
+		expected string
+	}{
+		{SQL_SS_TIME2, "TIME2"},
+		{SQL_SS_TIMESTAMPOFFSET, "DATETIMEOFFSET"},
+		{SQL_SS_VARIANT, "SQL_VARIANT"},
+		{SQL_SS_XML, "XML"},
+		{SQL_DB2_DECFLOAT, "DECFLOAT"},
+	}
+	for _, tt := range tests {
+		if got := SQLTypeName(tt.sqlType); got != tt.expected {
+			t.Errorf("SQLTypeName(%d) = %q, expected %q", tt.sqlType, got, tt.expected)
+		}
+	}
+}
+
+func TestRegisterSQLTypeName(t *testing.T) {
+	const customType SQLSMALLINT = -9999
+	if _, ok := LookupSQLTypeName(customType); ok {
+		t.Fatal("expected custom type to be unregistered")
+	}
+	RegisterSQLTypeName(customType, "CUSTOM")
+	defer func() {
+		sqlTypeNamesMu.Lock()
+		delete(sqlTypeNames, customType)
+		sqlTypeNamesMu.Unlock()
+	}()
+
+	if got := SQLTypeName(customType); got != "CUSTOM" {
+		t.Errorf("expected CUSTOM, got %q", got)
+	}
+	r := &Rows{colTypes: []SQLSMALLINT{customType}, nativeTypes: []string{""}}
+	if got := r.ColumnTypeDatabaseTypeName(0); got != "CUSTOM" {
+		t.Errorf("expected CUSTOM database type name, got %q", got)
+	}
+}
+
+func TestRows_ColumnTypeScanType_VendorTypes(t *testing.T) {
+	r := &Rows{colTypes: []SQLSMALLINT{SQL_SS_TIME2, SQL_SS_XML, SQL_DB2_BLOB}}
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf(time.Time{}) {
+		t.Errorf("expected time.Time for TIME2, got %v", got)
+	}
+	if got := r.ColumnTypeScanType(1); got != reflect.TypeOf("") {
+		t.Errorf("expected string for XML, got %v", got)
+	}
+	if got := r.ColumnTypeScanType(2); got != reflect.TypeOf([]byte{}) {
+		t.Errorf("expected []byte for BLOB, got %v", got)
+	}
+}
+
+// =============================================================================
+// Column Size Sanitization Tests (rows.go)
+// =============================================================================
+
+func TestSanitizeColumnSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		colSize     SQLULEN
+		wantSize    SQLULEN
+		wantUnknown bool
+	}{
+		{"normal", 255, 255, false},
+		{"max int32", math.MaxInt32, math.MaxInt32, false},
+		{"zero (max type)", 0, 0, true},
+		{"SQL_NO_TOTAL as unsigned", ^SQLULEN(3), 0, true},
+		{"zero-extended -1", 0xFFFFFFFF, 0, true},
+		{"overflow", math.MaxInt32 + 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, unknown := sanitizeColumnSize(tt.colSize)
+			if size != tt.wantSize || unknown != tt.wantUnknown {
+				t.Errorf("sanitizeColumnSize(%d) = (%d, %v), expected (%d, %v)",
+					tt.colSize, size, unknown, tt.wantSize, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestRows_ColumnTypeLength_UnknownSize(t *testing.T) {
+	r := &Rows{
+		colTypes:    []SQLSMALLINT{SQL_VARCHAR, SQL_WVARCHAR, SQL_NUMERIC},
+		colSizes:    []SQLULEN{50, 0, 0},
+		sizeUnknown: []bool{false, true, true},
+		decDigits:   []SQLSMALLINT{0, 0, 0},
+	}
+
+	if length, ok := r.ColumnTypeLength(0); !ok || length != 50 {
+		t.Errorf("expected (50, true), got (%d, %v)", length, ok)
+	}
+	if r.ColumnSizeUnknown(0) {
+		t.Error("expected column 0 size to be known")
+	}
+	if !r.ColumnSizeUnknown(1) {
+		t.Error("expected column 1 size to be unknown")
+	}
+	if length, ok := r.ColumnTypeLength(1); !ok || length != math.MaxInt64 {
+		t.Errorf("expected (MaxInt64, true) by default, got (%d, %v)", length, ok)
+	}
+	if _, _, ok := r.ColumnTypePrecisionScale(2); ok {
+		t.Error("expected ok=false for NUMERIC with unknown precision")
+	}
+
+	r.stmt = &Stmt{conn: &Conn{unknownColumnSize: UnknownColumnSizeNotOK}}
+	if length, ok := r.ColumnTypeLength(1); ok || length != 0 {
+		t.Errorf("expected (0, false) with UnknownColumnSizeNotOK, got (%d, %v)", length, ok)
+	}
+}
+
+func TestWithUnknownColumnSize(t *testing.T) {
+	c := &Connector{}
+	WithUnknownColumnSize(UnknownColumnSizeNotOK)(c)
+	if c.UnknownColumnSize != UnknownColumnSizeNotOK {
+		t.Errorf("expected UnknownColumnSizeNotOK, got %v", c.UnknownColumnSize)
+	}
+}
+
+// =============================================================================
+// SQLLEN Width Tests (lenwidth.go)
+// =============================================================================
+
+func TestSQLLenSize(t *testing.T) {
+	if sqlLenSize != int(unsafe.Sizeof(SQLULEN(0))) {
+		t.Errorf("SQLLEN and SQLULEN sizes differ: %d vs %d", sqlLenSize, unsafe.Sizeof(SQLULEN(0)))
+	}
+	if sqlLenSize != 4 && sqlLenSize != 8 {
+		t.Errorf("unexpected SQLLEN size %d", sqlLenSize)
+	}
+}
+
+func TestLengthWidth(t *testing.T) {
+	sentinel := func() [8]byte {
+		var buf [8]byte
+		for i := range buf {
+			buf[i] = lengthProbeSentinel
+		}
+		return buf
+	}
+
+	buf := sentinel()
+	binary.NativeEndian.PutUint64(buf[:], 1)
+	if got := lengthWidth(buf); got != 8 {
+		t.Errorf("expected 8 for a 64-bit write, got %d", got)
+	}
+
+	buf = sentinel()
+	binary.NativeEndian.PutUint32(buf[:4], 1)
+	if got := lengthWidth(buf); got != 4 {
+		t.Errorf("expected 4 for a 32-bit write, got %d", got)
+	}
+
+	if got := lengthWidth(sentinel()); got != 0 {
+		t.Errorf("expected 0 for an untouched buffer, got %d", got)
+	}
+}
+
+// =============================================================================
+// Session Variable Tests (session.go)
+// =============================================================================
+
+func TestIsSessionVarName(t *testing.T) {
+	valid := []string{"LOCK_TIMEOUT", "search_path", "CURRENT SCHEMA", "app.user_id", "NLS_DATE_FORMAT"}
+	for _, name := range valid {
+		if !isSessionVarName(name) {
+			t.Errorf("expected %q to be valid", name)
+		}
+	}
+	invalid := []string{"", " x", "x ", "a  b", "x; DROP TABLE t", "x'y", "x=1"}
+	for _, name := range invalid {
+		if isSessionVarName(name) {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+}
+
+func TestSessionLiteral(t *testing.T) {
+	tests := []struct {
+		value      interface{}
+		quoteWords bool
+		expected   string
+	}{
+		{"ON", false, "ON"},
+		{"ON", true, "'ON'"},
+		{"dmy format", false, "'dmy format'"},
+		{"it's", false, "'it''s'"},
+		{true, false, "ON"},
+		{false, true, "OFF"},
+		{5000, false, "5000"},
+		{int64(-1), false, "-1"},
+		{1.5, false, "1.5"},
+	}
+	for _, tt := range tests {
+		got, err := sessionLiteral(tt.value, tt.quoteWords)
+		if err != nil {
+			t.Errorf("sessionLiteral(%v) unexpected error: %v", tt.value, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("sessionLiteral(%v, %v) = %q, expected %q", tt.value, tt.quoteWords, got, tt.expected)
+		}
+	}
+	if _, err := sessionLiteral(struct{}{}, false); err == nil {
+		t.Error("expected error for unsupported value type")
+	}
+}
+
+func TestConn_SessionDialect(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		setQuery string
+		setArgs  int
+		getQuery string
+	}{
+		{"Microsoft SQL Server", "SET LOCK_TIMEOUT 5000", 0, "SELECT @@LOCK_TIMEOUT"},
+		{"PostgreSQL", "SELECT set_config(?, ?, false)", 2, "SELECT current_setting(?)"},
+		{"MySQL", "SET SESSION LOCK_TIMEOUT = 5000", 0, "SELECT @@SESSION.LOCK_TIMEOUT"},
+		{"MariaDB", "SET SESSION LOCK_TIMEOUT = 5000", 0, "SELECT @@SESSION.LOCK_TIMEOUT"},
+		{"SQLite", "PRAGMA LOCK_TIMEOUT = 5000", 0, "PRAGMA LOCK_TIMEOUT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			c := &Conn{dbType: tt.dbType}
+			dialect, err := c.sessionDialect("LOCK_TIMEOUT")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			query, args, err := dialect.set("LOCK_TIMEOUT", 5000)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if query != tt.setQuery || len(args) != tt.setArgs {
+				t.Errorf("set = (%q, %d args), expected (%q, %d args)", query, len(args), tt.setQuery, tt.setArgs)
+			}
+			if query, _ := dialect.get("LOCK_TIMEOUT"); query != tt.getQuery {
+				t.Errorf("get = %q, expected %q", query, tt.getQuery)
+			}
+		})
+	}
+
+	// SQL Server ANSI options are read through SESSIONPROPERTY
+	c := &Conn{dbType: "Microsoft SQL Server"}
+	dialect, _ := c.sessionDialect("ANSI_NULLS")
+	if query, args := dialect.get("ANSI_NULLS"); query != "SELECT SESSIONPROPERTY(?)" || len(args) != 1 {
+		t.Errorf("unexpected ANSI_NULLS query %q", query)
+	}
+
+	if _, err := (&Conn{dbType: "UnknownDB"}).sessionDialect("x"); err == nil {
+		t.Error("expected error for unsupported database")
+	}
+	if _, err := c.sessionDialect("x; DROP TABLE t"); err == nil {
+		t.Error("expected error for invalid name")
+	}
+}
+
+// =============================================================================
+// Catalog Helper Tests (catalog.go)
+// =============================================================================
+
+func TestParseTableName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"orders", []string{"orders"}},
+		{"sales.orders", []string{"sales", "orders"}},
+		{"db.dbo.orders", []string{"db", "dbo", "orders"}},
+		{`"My Schema"."Order Lines"`, []string{`"My Schema"`, `"Order Lines"`}},
+		{"[dbo].[order details]", []string{"[dbo]", "[order details]"}},
+		{"`shop`.items$1", []string{"`shop`", "items$1"}},
+	}
+	for _, tt := range tests {
+		got, err := parseTableName(tt.input)
+		if err != nil {
+			t.Errorf("parseTableName(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("parseTableName(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	invalid := []string{"", "orders;DROP TABLE x", "a..b", "a.", ".a", `"unterminated`, "a b", `""`}
+	for _, input := range invalid {
+		if _, err := parseTableName(input); err == nil {
+			t.Errorf("parseTableName(%q) expected error", input)
+		}
+	}
+}
+
+func TestConn_QualifiedName(t *testing.T) {
+	tests := []struct {
+		quote string
+		input string
+		want  string
+	}{
+		{`"`, "sales.orders", "sales.orders"},
+		{`"`, `"My Schema"."Order Lines"`, `"My Schema"."Order Lines"`},
+		{`"`, `[x"; DROP TABLE t; --]`, `"x""; DROP TABLE t; --"`},
+		{`[`, `"a]b".orders`, `[a]]b].orders`},
+		{"`", "[dbo].[it`s]", "`dbo`.`it``s`"},
+	}
+	for _, tt := range tests {
+		parts, err := parseTableName(tt.input)
+		if err != nil {
+			t.Fatalf("parseTableName(%q) error: %v", tt.input, err)
+		}
+		c := &Conn{identifierQuote: tt.quote}
+		if got, err := c.qualifiedName(parts); err != nil || got != tt.want {
+			t.Errorf("qualifiedName(%q) with quote %s = %q (%v), want %q", tt.input, tt.quote, got, err, tt.want)
+		}
+	}
+
+	c := &Conn{identifierQuote: " "}
+	if _, err := c.qualifiedName([]string{`"a b"`}); err == nil {
+		t.Error("expected an error for a quoted name when the database does not quote identifiers")
+	}
+}
+
+func TestConn_RowCountQuery(t *testing.T) {
+	tests := []struct {
+		dbType string
+		args   int
+	}{
+		{"Microsoft SQL Server", 1},
+		{"PostgreSQL", 1},
+		{"MySQL", 2},
+		{"MariaDB", 2},
+		{"Oracle", 2},
+		{"DB2/LINUXX8664", 2},
+		{"Snowflake", 2},
+	}
+	for _, tt := range tests {
+		query := (&Conn{dbType: tt.dbType}).rowCountQuery()
+		if query == nil {
+			t.Errorf("%s: expected statistics query", tt.dbType)
+			continue
+		}
+		if _, args := query("sales", "orders"); len(args) != tt.args {
+			t.Errorf("%s: expected %d args, got %d", tt.dbType, tt.args, len(args))
+		}
+	}
+
+	if (&Conn{dbType: "SQLite"}).rowCountQuery() != nil {
+		t.Error("expected no statistics query for SQLite")
+	}
+}
+
+// =============================================================================
+// Table Reader Tests (table_reader.go)
+// =============================================================================
+
+func TestTableReader_ChunkQuery(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		hasKey   bool
+		expected string
+	}{
+		{"PostgreSQL", false, "SELECT * FROM sales.orders ORDER BY id LIMIT 500"},
+		{"PostgreSQL", true, "SELECT * FROM sales.orders WHERE id > ? ORDER BY id LIMIT 500"},
+		{"Microsoft SQL Server", true, "SELECT TOP 500 * FROM sales.orders WHERE id > ? ORDER BY id"},
+		{"Oracle", true, "SELECT * FROM sales.orders WHERE id > ? ORDER BY id FETCH FIRST 500 ROWS ONLY"},
+		{"DB2/LINUXX8664", false, "SELECT * FROM sales.orders ORDER BY id FETCH FIRST 500 ROWS ONLY"},
+	}
+	for _, tt := range tests {
+		r := &TableReader{
+			conn:      &Conn{dbType: tt.dbType},
+			table:     "sales.orders",
+			key:       "id",
+			chunkSize: 500,
+			hasKey:    tt.hasKey,
+		}
+		if got := r.chunkQuery(); got != tt.expected {
+			t.Errorf("%s: got %q, expected %q", tt.dbType, got, tt.expected)
+		}
+	}
+}
+
+func TestNewTableReader_Options(t *testing.T) {
+	c := &Conn{dbType: "PostgreSQL"}
+	r, err := c.NewTableReader(context.Background(), "orders",
+		WithKeyColumn(`"Id"`),
+		WithSelectColumns("name", "total"),
+		WithChunkSize(100),
+		WithResumeAfter(int64(42)),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.keyName != "Id" {
+		t.Errorf("expected key name Id, got %q", r.keyName)
+	}
+	if !reflect.DeepEqual(r.columns, []string{"name", "total", `"Id"`}) {
+		t.Errorf("expected key column to be appended, got %v", r.columns)
+	}
+	if r.LastKey() != int64(42) || !r.hasKey {
+		t.Errorf("expected resume key 42, got %v", r.LastKey())
+	}
+	expected := `SELECT name, total, "Id" FROM orders WHERE "Id" > ? ORDER BY "Id" LIMIT 100`
+	if got := r.chunkQuery(); got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+
+	if _, err := c.NewTableReader(context.Background(), "orders", WithKeyColumn("id"), WithChunkSize(0)); err == nil {
+		t.Error("expected error for zero chunk size")
+	}
+	if _, err := c.NewTableReader(context.Background(), "orders", WithKeyColumn("id; DROP TABLE x")); err == nil {
+		t.Error("expected error for invalid key column")
+	}
+	if _, err := c.NewTableReader(context.Background(), "orders", WithKeyColumn("id"), WithSelectColumns("a.b")); err == nil {
+		t.Error("expected error for qualified select column")
+	}
+}
+
+// =============================================================================
+// Connection String Tests (dsn.go)
+// =============================================================================
+
+func TestRedactConnString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"DSN=mydsn;UID=user;PWD=secret", "DSN=mydsn;UID=user;PWD=***"},
+		{"Driver={SQL Server};Server=db,1433;Password=p@ss;", "Driver={SQL Server};Server=db,1433;Password=***;"},
+		{"UID=u;PWD={a;b}}c};Port=5432", "UID=u;PWD=***;Port=5432"},
+		{"AccessToken=abc;ClientSecret=xyz;Server=s", "AccessToken=***;ClientSecret=***;Server=s"},
+		{"Server=s;Trusted_Connection=yes", "Server=s;Trusted_Connection=yes"},
+		{"", ""},
+		{"flag;pwd=x", "flag;pwd=***"},
+	}
+	for _, tt := range tests {
+		if got := RedactConnString(tt.input); got != tt.expected {
+			t.Errorf("RedactConnString(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestConnStringFromBuffer(t *testing.T) {
+	buf := append([]byte("DSN=x;UID=u"), 0, 'z', 'z')
+	if got := connStringFromBuffer(buf, 11); got != "DSN=x;UID=u" {
+		t.Errorf("got %q", got)
+	}
+	// Truncated output reports the full length; clamp to the buffer
+	if got := connStringFromBuffer([]byte("DSN=x"), 100); got != "DSN=x" {
+		t.Errorf("got %q", got)
+	}
+	if got := connStringFromBuffer(buf, -1); got != "" {
+		t.Errorf("expected empty string, got %q", got)
+	}
+}
+
+func TestRemoveConnAttrs(t *testing.T) {
+	tests := []struct {
+		connStr string
+		value   string
+		rest    string
+	}{
+		{"DSN=test;UID=sa", "", "DSN=test;UID=sa"},
+		{"godbc_ping_query=SELECT 1;DSN=test", "SELECT 1", "DSN=test"},
+		{"DSN=test;GODBC_PING_QUERY=SELECT 2;UID=sa", "SELECT 2", "DSN=test;UID=sa"},
+		{"DSN=test;godbc_ping_query={SELECT 1; -- x}", "SELECT 1; -- x", "DSN=test;"},
+		{"DSN=test;PWD={a;godbc_ping_query=x}", "", "DSN=test;PWD={a;godbc_ping_query=x}"},
+		{" godbc_ping_query = {a}}b} ;DSN=test", "a}b", "DSN=test"},
+	}
+	for _, tt := range tests {
+		var value string
+		rest := removeConnAttrs(tt.connStr, func(key, v string) bool {
+			if !strings.EqualFold(key, "godbc_ping_query") {
+				return false
+			}
+			value = v
+			return true
+		})
+		if value != tt.value || rest != tt.rest {
+			t.Errorf("removeConnAttrs(%q) = %q, %q; expected %q, %q", tt.connStr, value, rest, tt.value, tt.rest)
+		}
+	}
+}
+
+func TestExtractDSNOptions(t *testing.T) {
+	opts, rest, err := extractDSNOptions("Driver={x;godbc_prefetch=1};GODBC_Query_Timeout=30s;godbc_wide_fetch=true;" +
+		"godbc_rowset_size=1000;godbc_keepalive=60;godbc_ping_query={SELECT 1; -- x};" +
+		"godbc_unicode=Wide;godbc_timestamp_precision=microseconds;UID=sa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rest != "Driver={x;godbc_prefetch=1};UID=sa" {
+		t.Errorf("unexpected connection string %q", rest)
+	}
+	c := &Connector{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.QueryTimeout != 30*time.Second || !c.WideFetch || c.RowArraySize != 1000 || c.KeepAlive != time.Minute {
+		t.Errorf("unexpected options %+v", c)
+	}
+	if c.PingQuery != "SELECT 1; -- x" || c.Unicode != UnicodeWide || c.DefaultTimestampPrecision != TimestampPrecisionMicroseconds {
+		t.Errorf("unexpected options %+v", c)
+	}
+	if c.Prefetch {
+		t.Error("expected an option inside a braced value to be left alone")
+	}
+
+	for _, connStr := range []string{
+		"DSN=x;godbc_query_timout=30s",
+		"DSN=x;godbc_wide_fetch=maybe",
+		"DSN=x;godbc_rowset_size=-1",
+		"DSN=x;godbc_unicode=utf8",
+		"DSN=x;godbc_library=/opt/lib/libodbc.so.2",
+	} {
+		if _, _, err := extractDSNOptions(connStr); err == nil {
+			t.Errorf("expected an error for %q", connStr)
+		}
+	}
+}
+
+func TestOpenConnectorWithOptions_DSNOptions(t *testing.T) {
+	d := &Driver{}
+	if _, err := d.OpenConnector("DSN=x;godbc_bogus=1"); err == nil || !strings.Contains(err.Error(), "godbc_bogus") {
+		t.Errorf("expected an unknown option error, got %v", err)
+	}
+	c, err := d.OpenConnectorWithOptions("DSN=x;godbc_query_timeout=30s;godbc_stmt_cache_size=10", WithQueryTimeout(time.Minute))
+	if err != nil {
+		t.Skipf("ODBC library not available: %v", err)
+	}
+	if c.dsn != "DSN=x" || c.QueryTimeout != time.Minute || c.StmtCacheSize != 10 {
+		t.Errorf("expected explicit options to take precedence, got dsn %q, timeout %v, cache %d", c.dsn, c.QueryTimeout, c.StmtCacheSize)
+	}
+}
+
+// =============================================================================
+// Identifier Case Tests (identifiers.go)
+// =============================================================================
+
+func TestConn_NormalizeIdentifier(t *testing.T) {
+	tests := []struct {
+		identCase IdentifierCase
+		input     string
+		expected  string
+	}{
+		{IdentifierCaseUpper, "orders", "ORDERS"},
+		{IdentifierCaseUpper, `"Orders"`, "Orders"},
+		{IdentifierCaseLower, "Orders", "orders"},
+		{IdentifierCaseLower, `"Orders"`, "Orders"},
+		{IdentifierCaseLower, `"a""b"`, `a"b`},
+		{IdentifierCaseMixed, "Orders", "Orders"},
+		{IdentifierCaseMixed, "[Order Details]", "Order Details"},
+		{IdentifierCaseUnknown, "Orders", "Orders"},
+	}
+	for _, tt := range tests {
+		c := &Conn{identifierCase: tt.identCase}
+		if got := c.NormalizeIdentifier(tt.input); got != tt.expected {
+			t.Errorf("NormalizeIdentifier(%q) with case %d = %q, expected %q", tt.input, tt.identCase, got, tt.expected)
+		}
+	}
+}
+
+func TestConn_QuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		quote    string
+		input    string
+		expected string
+	}{
+		{"", "Orders", `"Orders"`},
+		{`"`, `a"b`, `"a""b"`},
+		{"`", "Orders", "`Orders`"},
+		{"[", "a]b", "[a]]b]"},
+		{" ", "Orders", "Orders"},
+	}
+	for _, tt := range tests {
+		c := &Conn{identifierQuote: tt.quote}
+		if got := c.QuoteIdentifier(tt.input); got != tt.expected {
+			t.Errorf("QuoteIdentifier(%q) with quote %q = %q, expected %q", tt.input, tt.quote, got, tt.expected)
+		}
+	}
+}
+
+func TestConn_IdentifierCasePolicy(t *testing.T) {
+	c := &Conn{identifierCase: IdentifierCaseUpper}
+	if got := c.lookupIdentifier("orders"); got != "ORDERS" {
+		t.Errorf("Auto: expected ORDERS, got %q", got)
+	}
+	if got := c.resultIdentifier("ORDERS"); got != "ORDERS" {
+		t.Errorf("Auto: expected result unchanged, got %q", got)
+	}
+
+	c.identifierCasePolicy = IdentifierCasePolicyPreserve
+	if got := c.lookupIdentifier("orders"); got != "orders" {
+		t.Errorf("Preserve: expected orders, got %q", got)
+	}
+	if got := c.lookupIdentifier(`"Order Lines"`); got != "Order Lines" {
+		t.Errorf("Preserve: expected the quotes stripped, got %q", got)
+	}
+	if got := c.lookupIdentifier("[dbo]"); got != "dbo" {
+		t.Errorf("Preserve: expected the brackets stripped, got %q", got)
+	}
+
+	c.identifierCasePolicy = IdentifierCasePolicyLower
+	if got := c.lookupIdentifier("orders"); got != "ORDERS" {
+		t.Errorf("Lower: expected lookup ORDERS, got %q", got)
+	}
+	if got := c.resultIdentifier("ORDER_ID"); got != "order_id" {
+		t.Errorf("Lower: expected order_id, got %q", got)
+	}
+
+	if !c.identifiersEqual("Orders", "ORDERS") {
+		t.Error("expected case-insensitive comparison")
+	}
+	c.identifierCase = IdentifierCaseSensitive
+	if c.identifiersEqual("Orders", "ORDERS") {
+		t.Error("expected case-sensitive comparison")
+	}
+}
+
+// =============================================================================
+// Cursor Name Tests (stmt.go)
+// =============================================================================
+
+func TestStmt_CursorName_Closed(t *testing.T) {
+	s := &Stmt{closed: true}
+	if err := s.SetCursorName("c1"); !errors.Is(err, ErrStmtClosed) {
+		t.Errorf("expected ErrStmtClosed from SetCursorName, got %v", err)
+	}
+	if _, err := s.CursorName(); !errors.Is(err, ErrStmtClosed) {
+		t.Errorf("expected ErrStmtClosed from CursorName, got %v", err)
+	}
+}
+
+// =============================================================================
+// Parameter Binding Reuse Tests (stmt.go)
+// =============================================================================
+
+// boundStmt returns a statement whose first parameter was bound with value
+func boundStmt(t *testing.T, value interface{}) *Stmt {
+	t.Helper()
+	buf, cType, sqlType, colSize, decDigits, length, err := convertToODBC(value)
+	if err != nil {
+		t.Fatalf("convertToODBC(%v) error = %v", value, err)
+	}
+	s := &Stmt{}
+	s.paramSlots(1)
+	_, callerBytes := value.([]byte)
+	s.paramBuffers[0] = buf
+	s.paramLengths[0] = length
+	s.bindings[0] = paramBinding{bound: true, owned: !callerBytes, cType: cType, sqlType: sqlType, colSize: colSize, decDigits: decDigits}
+	return s
+}
+
+// reuse converts value and tries to write it into the first parameter's binding
+func reuse(t *testing.T, s *Stmt, value interface{}) bool {
+	t.Helper()
+	buf, cType, sqlType, colSize, decDigits, length, err := convertToODBC(value)
+	if err != nil {
+		t.Fatalf("convertToODBC(%v) error = %v", value, err)
+	}
+	return s.reuseBinding(0, buf, cType, sqlType, colSize, decDigits, length)
+}
+
+func TestStmt_ReuseBinding(t *testing.T) {
+	s := boundStmt(t, int64(1))
+	bound := s.paramBuffers[0].(*int64)
+	if !reuse(t, s, int64(42)) {
+		t.Fatal("expected int64 binding to be reused")
+	}
+	if s.paramBuffers[0].(*int64) != bound || *bound != 42 || s.paramLengths[0] != 8 {
+		t.Errorf("bound buffer = %d, length %d, want 42 in the same buffer", *bound, s.paramLengths[0])
+	}
+
+	// NULL only sets the indicator; the next value reuses the buffer again
+	if !reuse(t, s, nil) || s.paramLengths[0] != SQL_NULL_DATA {
+		t.Errorf("expected NULL to reuse the binding, length = %d", s.paramLengths[0])
+	}
+	if !reuse(t, s, int64(7)) || *bound != 7 {
+		t.Errorf("expected int64 after NULL to reuse the binding, got %d", *bound)
+	}
+
+	// A different type needs a new binding
+	if reuse(t, s, "text") {
+		t.Error("expected string value not to reuse an int64 binding")
+	}
+}
+
+func TestStmt_ReuseBinding_Strings(t *testing.T) {
+	s := boundStmt(t, "hello")
+	if !reuse(t, s, "hi") {
+		t.Fatal("expected shorter string to reuse the binding")
+	}
+	if got := s.paramLengths[0]; got != SQLLEN(2*wcharSize()) {
+		t.Errorf("length = %d, want %d", got, 2*wcharSize())
+	}
+	if reuse(t, s, "hello, world") {
+		t.Error("expected longer string to need a new binding")
+	}
+
+	// Caller-owned byte slices are bound directly and never overwritten
+	data := []byte{1, 2, 3}
+	s = boundStmt(t, data)
+	if reuse(t, s, []byte{4, 5}) {
+		t.Error("expected []byte binding not to be reused")
+	}
+	if !reflect.DeepEqual(data, []byte{1, 2, 3}) {
+		t.Errorf("caller slice modified: %v", data)
+	}
+}
+
+func TestStmt_ParamSlots(t *testing.T) {
+	s := boundStmt(t, int64(1))
+	lengths := &s.paramLengths[0]
+	s.outputParams = []outputParamInfo{{index: 0}}
+	s.paramSlots(1)
+	if &s.paramLengths[0] != lengths || !s.bindings[0].bound {
+		t.Error("expected slots and bindings to be kept for the same number of parameters")
+	}
+	if s.outputParams != nil {
+		t.Error("expected output parameters to be cleared")
+	}
+
+	// Without bindings, for example after resetParams, slots are allocated again
+	s.bindings = nil
+	s.paramSlots(1)
+	if &s.paramLengths[0] == lengths || s.bindings[0].bound {
+		t.Error("expected new slots without bindings")
+	}
+}
+
+// =============================================================================
+// Timestamp Fetch Mode Tests (rows.go)
+// =============================================================================
+
+func TestTimestampToEpochNanos(t *testing.T) {
+	times := []time.Time{
+		time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 29, 23, 59, 59, 999999999, time.UTC),
+		time.Date(2000, 3, 1, 12, 30, 0, 500, time.UTC),
+		time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 59, 123000000, time.UTC),
+		time.Date(2262, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1678, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, tm := range times {
+		ts := &SQL_TIMESTAMP_STRUCT{
+			Year:     SQLSMALLINT(tm.Year()),
+			Month:    SQLUSMALLINT(tm.Month()),
+			Day:      SQLUSMALLINT(tm.Day()),
+			Hour:     SQLUSMALLINT(tm.Hour()),
+			Minute:   SQLUSMALLINT(tm.Minute()),
+			Second:   SQLUSMALLINT(tm.Second()),
+			Fraction: SQLUINTEGER(tm.Nanosecond()),
+		}
+		if got := timestampToEpochNanos(ts); got != tm.UnixNano() {
+			t.Errorf("%v: got %d, expected %d", tm, got, tm.UnixNano())
+		}
+	}
+
+	// Sentinel dates outside the int64 range are clamped
+	if got := timestampToEpochNanos(&SQL_TIMESTAMP_STRUCT{Year: 9999, Month: 12, Day: 31}); got != math.MaxInt64 {
+		t.Errorf("expected MaxInt64 for 9999-12-31, got %d", got)
+	}
+	if got := timestampToEpochNanos(&SQL_TIMESTAMP_STRUCT{Year: 1, Month: 1, Day: 1}); got != math.MinInt64 {
+		t.Errorf("expected MinInt64 for 0001-01-01, got %d", got)
+	}
+}
+
+func TestRows_ColumnTypeScanType_TimestampFetchMode(t *testing.T) {
+	r := &Rows{colTypes: []SQLSMALLINT{SQL_TYPE_TIMESTAMP, SQL_TYPE_DATE}}
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf(time.Time{}) {
+		t.Errorf("expected time.Time by default, got %v", got)
+	}
+
+	r.stmt = &Stmt{conn: &Conn{timestampFetchMode: TimestampFetchEpochNanos}}
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf(int64(0)) {
+		t.Errorf("expected int64 for epoch nanos, got %v", got)
+	}
+	if got := r.ColumnTypeScanType(1); got != reflect.TypeOf(time.Time{}) {
+		t.Errorf("expected DATE to remain time.Time, got %v", got)
+	}
+}
+
+func TestWithTimestampFetchMode(t *testing.T) {
+	c := &Connector{}
+	WithTimestampFetchMode(TimestampFetchEpochNanos)(c)
+	if c.TimestampFetchMode != TimestampFetchEpochNanos {
+		t.Errorf("expected TimestampFetchEpochNanos, got %v", c.TimestampFetchMode)
+	}
+}
+
+func TestRows_ColumnTypeScanType_GUIDFetchMode(t *testing.T) {
+	r := &Rows{colTypes: []SQLSMALLINT{SQL_GUID}}
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf("") {
+		t.Errorf("expected string by default, got %v", got)
+	}
+
+	r.stmt = &Stmt{conn: &Conn{guidFetchMode: GUIDFetchBinary}}
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf([]byte{}) {
+		t.Errorf("expected []byte for binary mode, got %v", got)
+	}
+}
+
+func TestRows_BlockValue_GUIDFetchBinary(t *testing.T) {
+	want, err := ParseGUID("6F9619FF-8B86-D011-B42D-00C04FC964FF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := int(unsafe.Sizeof(SQL_GUID_STRUCT{}))
+	col := blockColumn{cType: SQL_C_GUID, elemSize: size, data: make([]byte, size), ind: []SQLLEN{SQLLEN(size)}}
+	*(*SQL_GUID_STRUCT)(unsafe.Pointer(&col.data[0])) = guidStruct(want)
+	r := &Rows{
+		stmt:     &Stmt{conn: &Conn{guidFetchMode: GUIDFetchBinary}},
+		columns:  []string{"id"},
+		colTypes: []SQLSMALLINT{SQL_GUID},
+		block:    &blockFetch{columns: []blockColumn{col}},
+	}
+	v, err := r.blockValue(0)
+	if err != nil {
+		t.Fatalf("blockValue() error: %v", err)
+	}
+	if !driver.IsValue(v) {
+		t.Fatalf("%T is not a valid driver.Value", v)
+	}
+	var got GUID
+	if err := got.Scan(v); err != nil || got != want {
+		t.Errorf("Scan(%v) = %v (%v), want %v", v, got, err, want)
+	}
+}
+
+func TestWithGUIDFetchMode(t *testing.T) {
+	c := &Connector{}
+	WithGUIDFetchMode(GUIDFetchBinary)(c)
+	if c.GUIDFetchMode != GUIDFetchBinary {
+		t.Errorf("expected GUIDFetchBinary, got %v", c.GUIDFetchMode)
+	}
+}
+
+// =============================================================================
+// Out-of-Range Time Tests (timerange.go)
+// =============================================================================
+
+func TestDateTimeValue_InRange(t *testing.T) {
+	got := dateTimeValue(2024, 2, 29, 13, 45, 30, 123, OutOfRangeTimeValue)
+	want := time.Date(2024, 2, 29, 13, 45, 30, 123, time.UTC)
+	if got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := dateTimeValue(9999, 12, 31, 23, 59, 59, 999999999, OutOfRangeTimeClamp); got != MaxTime {
+		t.Errorf("expected MaxTime to be in range, got %v", got)
+	}
+}
+
+func TestDateTimeValue_OutOfRange(t *testing.T) {
+	tests := []struct {
+		name                        string
+		year, month, day, hour      int
+		wantString                  string
+		wantBeforeMin, wantAfterMax bool
+	}{
+		{"BC date", -44, 3, 15, 0, "-0044-03-15 00:00:00", true, false},
+		{"year zero", 0, 1, 1, 0, "0000-01-01 00:00:00", true, false},
+		{"far future", 32767, 1, 1, 0, "32767-01-01 00:00:00", false, true},
+		{"invalid day", 2023, 2, 30, 0, "2023-02-30 00:00:00", false, false},
+		{"invalid hour", 2023, 1, 1, 24, "2023-01-01 24:00:00", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := dateTimeValue(tt.year, tt.month, tt.day, tt.hour, 0, 0, 0, OutOfRangeTimeValue).(OutOfRangeTime)
+			if !ok {
+				t.Fatalf("expected OutOfRangeTime")
+			}
+			if got.String() != tt.wantString {
+				t.Errorf("expected %q, got %q", tt.wantString, got.String())
+			}
+			if got.BeforeMin() != tt.wantBeforeMin || got.AfterMax() != tt.wantAfterMax {
+				t.Errorf("BeforeMin/AfterMax = %v/%v", got.BeforeMin(), got.AfterMax())
+			}
+			if s := dateTimeValue(tt.year, tt.month, tt.day, tt.hour, 0, 0, 0, OutOfRangeTimeString); s != tt.wantString {
+				t.Errorf("expected string %q, got %v", tt.wantString, s)
+			}
+		})
+	}
+}
+
+func TestDateTimeValue_Clamp(t *testing.T) {
+	if got := dateTimeValue(-4713, 11, 24, 0, 0, 0, 0, OutOfRangeTimeClamp); got != MinTime {
+		t.Errorf("expected MinTime, got %v", got)
+	}
+	if got := dateTimeValue(10000, 1, 1, 0, 0, 0, 0, OutOfRangeTimeClamp); got != MaxTime {
+		t.Errorf("expected MaxTime, got %v", got)
+	}
+	if _, ok := dateTimeValue(2023, 13, 1, 0, 0, 0, 0, OutOfRangeTimeClamp).(OutOfRangeTime); !ok {
+		t.Errorf("expected invalid fields to remain OutOfRangeTime")
+	}
+}
+
+func TestOutOfRangeTime_StringFraction(t *testing.T) {
+	v := OutOfRangeTime{Year: -1, Month: 12, Day: 31, Hour: 23, Minute: 59, Second: 59, Nanosecond: 500}
+	if got, want := v.String(), "-0001-12-31 23:59:59.000000500"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWithOutOfRangeTimeMode(t *testing.T) {
+	c := &Connector{}
+	WithOutOfRangeTimeMode(OutOfRangeTimeClamp)(c)
+	if c.OutOfRangeTime != OutOfRangeTimeClamp {
+		t.Errorf("expected OutOfRangeTimeClamp, got %v", c.OutOfRangeTime)
+	}
+}
+
+// =============================================================================
+// Debug Dump Tests (debug.go)
+// =============================================================================
+
+func TestDiagnosticLog_KeepsMostRecent(t *testing.T) {
+	l := &diagnosticLog{}
+	l.add(nil)
+	if got := len(l.snapshot()); got != 0 {
+		t.Fatalf("expected empty record sets to be ignored, got %d events", got)
+	}
+	for i := 0; i < maxRecentDiagnostics+5; i++ {
+		l.add([]DiagRecord{{SQLState: "HY000", NativeError: int32(i), Message: "failed"}})
+	}
+	events := l.snapshot()
+	if len(events) != maxRecentDiagnostics {
+		t.Fatalf("expected %d events, got %d", maxRecentDiagnostics, len(events))
+	}
+	if got := events[0].records[0].NativeError; got != 5 {
+		t.Errorf("expected oldest kept event to be 5, got %d", got)
+	}
+	if got := events[len(events)-1].records[0].NativeError; got != maxRecentDiagnostics+4 {
+		t.Errorf("expected newest event last, got %d", got)
+	}
+}
+
+func TestDiagnosticEntries(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := diagnosticEntries([]diagnosticEvent{{
+		time: at,
+		records: []DiagRecord{
+			{SQLState: "08S01", NativeError: 10054, Message: "Communication link failure"},
+			{SQLState: "01000", Message: "General warning"},
+		},
+	}})
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].name != "2024-05-01T12:00:00Z" {
+		t.Errorf("unexpected timestamp %q", entries[0].name)
+	}
+	if want := "[08S01] Communication link failure (native error: 10054)"; entries[0].value != want {
+		t.Errorf("expected %q, got %q", want, entries[0].value)
+	}
+}
+
+func TestConn_NewError_RecordsPerConn(t *testing.T) {
+	fakeDiagRecords(t, 1, "Communication link failure", false)
+
+	c1, c2 := &Conn{}, &Conn{}
+	if err := c1.newError(SQL_HANDLE_DBC, 1); err == nil {
+		t.Fatal("expected an error")
+	}
+	if events := c1.diagnostics.snapshot(); len(events) != 1 {
+		t.Errorf("expected 1 event on c1, got %d", len(events))
+	}
+	if events := c2.diagnostics.snapshot(); len(events) != 0 {
+		t.Errorf("expected no events on c2, got %d", len(events))
+	}
+
+	var nilConn *Conn
+	if err := nilConn.newError(SQL_HANDLE_DBC, 1); err == nil {
+		t.Error("expected an error from a nil conn")
+	}
+}
+
+func TestWriteDebugSection(t *testing.T) {
+	var sb strings.Builder
+	writeDebugSection(&sb, "Options", []debugEntry{{"QueryTimeout", "30s"}, {"MultiRowInsert", "true"}})
+	writeDebugSection(&sb, "Recent Diagnostics", nil)
+	want := "\n== Options ==\nQueryTimeout    30s\nMultiRowInsert  true\n" +
+		"\n== Recent Diagnostics ==\n(none)\n"
+	if sb.String() != want {
+		t.Errorf("expected %q, got %q", want, sb.String())
+	}
+}
+
+func TestDebugDump_ClosedConn(t *testing.T) {
+	c := &Conn{closed: true}
+	var sb strings.Builder
+	if err := c.DebugDump(&sb); err != driver.ErrBadConn {
+		t.Errorf("expected driver.ErrBadConn, got %v", err)
+	}
+	if sb.Len() != 0 {
+		t.Errorf("expected nothing written, got %q", sb.String())
+	}
+}
+
+// =============================================================================
+// Trace Tests (trace.go)
+// =============================================================================
+
+func TestTraceOptions_WithDefaults(t *testing.T) {
+	opts := TraceOptions{Path: "trace.log"}.withDefaults()
+	if opts.MaxSize != defaultTraceMaxSize || opts.MaxFiles != defaultTraceMaxFiles || opts.MaxDuration != defaultTraceDuration {
+		t.Errorf("unexpected defaults: %+v", opts)
+	}
+
+	opts = TraceOptions{Path: "trace.log", MaxSize: 1024, MaxFiles: 1, MaxDuration: -1}.withDefaults()
+	if opts.MaxSize != 1024 || opts.MaxFiles != 1 || opts.MaxDuration != -1 {
+		t.Errorf("expected explicit values to be kept, got %+v", opts)
+	}
+}
+
+func TestRotateTraceFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "odbc.trace")
+	write := func(name, content string) {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+
+	write(path, "current")
+	write(path+".1", "older")
+	write(path+".2", "oldest")
+	if err := rotateTraceFiles(path, 2); err != nil {
+		t.Fatalf("rotateTraceFiles failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be moved", path)
+	}
+	if got := read(path + ".1"); got != "current" {
+		t.Errorf("expected .1 to hold current trace, got %q", got)
+	}
+	if got := read(path + ".2"); got != "older" {
+		t.Errorf("expected .2 to hold older trace, got %q", got)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected no more than 2 rotated files")
+	}
+
+	// Rotating with no trace file yet is not an error
+	if err := rotateTraceFiles(filepath.Join(dir, "missing.trace"), 3); err != nil {
+		t.Errorf("expected no error for missing files, got %v", err)
+	}
+}
+
+func TestStartTrace_Validation(t *testing.T) {
+	c := &Conn{}
+	if err := c.StartTrace(TraceOptions{}); err == nil {
+		t.Error("expected error for empty trace path")
+	}
+
+	c.closed = true
+	if err := c.StartTrace(TraceOptions{Path: "odbc.trace"}); err != driver.ErrBadConn {
+		t.Errorf("expected driver.ErrBadConn on closed connection, got %v", err)
+	}
+	if err := c.StopTrace(); err != nil {
+		t.Errorf("expected StopTrace without a trace to be a no-op, got %v", err)
+	}
+	if c.Tracing() {
+		t.Error("expected Tracing to be false")
+	}
+}
+
+// =============================================================================
+// PostgreSQL Type Tests (pgtypes.go)
+// =============================================================================
+
+func TestPGValueKinds(t *testing.T) {
+	native := []string{"int4", "bit", "varbit", "_bool", "text"}
+	if kinds := pgValueKinds("Microsoft SQL Server", native); kinds != nil {
+		t.Errorf("expected no kinds for non-PostgreSQL database, got %v", kinds)
+	}
+	if kinds := pgValueKinds("PostgreSQL", []string{"int4", "text"}); kinds != nil {
+		t.Errorf("expected nil when no column needs decoding, got %v", kinds)
+	}
+	kinds := pgValueKinds("PostgreSQL", native)
+	want := []pgValueKind{pgValueDefault, pgValueBitString, pgValueBitString, pgValueBoolArray, pgValueDefault}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("expected %v, got %v", want, kinds)
+	}
+}
+
+func TestDecodePGValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		kind  pgValueKind
+		want  interface{}
+	}{
+		{"bit string", "1010", pgValueBitString, BitString("1010")},
+		{"empty bit string", "", pgValueBitString, BitString("")},
+		{"invalid bit string", "10x1", pgValueBitString, "10x1"},
+		{"bool array", "{t,f,t}", pgValueBoolArray, []bool{true, false, true}},
+		{"bool array words", "{true, FALSE}", pgValueBoolArray, []bool{true, false}},
+		{"empty bool array", "{}", pgValueBoolArray, []bool{}},
+		{"bool array with NULL", "{t,NULL}", pgValueBoolArray, "{t,NULL}"},
+		{"nested bool array", "{{t,f},{f,t}}", pgValueBoolArray, "{{t,f},{f,t}}"},
+		{"NULL value", nil, pgValueBoolArray, nil},
+		{"non-string value", true, pgValueBitString, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodePGValue(tt.value, tt.kind); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBitString_RoundTrip(t *testing.T) {
+	bits := []bool{true, false, true, true}
+	b := BitStringFromBools(bits)
+	if b != "1011" {
+		t.Errorf("expected 1011, got %q", b)
+	}
+	if got := b.Bools(); !reflect.DeepEqual(got, bits) {
+		t.Errorf("expected %v, got %v", bits, got)
+	}
+	if got := decodePGValue(string(b), pgValueBitString); got != b {
+		t.Errorf("expected %q after decode, got %#v", b, got)
+	}
+}
+
+func TestBoolArray_RoundTrip(t *testing.T) {
+	bools := []bool{true, false, false}
+	literal := formatBoolArray(bools)
+	if literal != "{t,f,f}" {
+		t.Errorf("expected {t,f,f}, got %q", literal)
+	}
+	if got := decodePGValue(literal, pgValueBoolArray); !reflect.DeepEqual(got, bools) {
+		t.Errorf("expected %v, got %#v", bools, got)
+	}
+}
+
+func TestConvertToODBC_PGTypes(t *testing.T) {
+	for _, v := range []interface{}{BitString("101"), []bool{true, false}} {
+		_, cType, sqlType, _, _, _, err := convertToODBC(v)
+		if err != nil {
+			t.Fatalf("convertToODBC(%#v) failed: %v", v, err)
+		}
+		if cType != SQL_C_WCHAR || sqlType != SQL_WVARCHAR {
+			t.Errorf("expected %#v to bind as a wide string, got C type %d, SQL type %d", v, cType, sqlType)
+		}
+	}
+}
+
+func TestRows_ColumnTypeScanType_PGTypes(t *testing.T) {
+	r := &Rows{
+		colTypes: []SQLSMALLINT{SQL_VARCHAR, SQL_LONGVARCHAR},
+		pgKinds:  []pgValueKind{pgValueBitString, pgValueBoolArray},
+	}
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf(BitString("")) {
+		t.Errorf("expected BitString, got %v", got)
+	}
+	if got := r.ColumnTypeScanType(1); got != reflect.TypeOf([]bool{}) {
+		t.Errorf("expected []bool, got %v", got)
+	}
+}
+
+// =============================================================================
+// Block Fetch Tests (blockfetch.go)
+// =============================================================================
+
+func TestBlockColumnLayout(t *testing.T) {
+	tests := []struct {
+		name        string
+		colType     SQLSMALLINT
+		colSize     SQLULEN
+		sizeUnknown bool
+		wantCType   SQLSMALLINT
+		wantSize    int
+		wantOK      bool
+	}{
+		{"bit", SQL_BIT, 1, false, SQL_C_BIT, 1, true},
+		{"integer", SQL_INTEGER, 10, false, SQL_C_SBIGINT, 8, true},
+		{"real", SQL_REAL, 7, false, SQL_C_FLOAT, 4, true},
+		{"double", SQL_DOUBLE, 15, false, SQL_C_DOUBLE, 8, true},
+		{"timestamp", SQL_TYPE_TIMESTAMP, 23, false, SQL_C_TIMESTAMP, int(unsafe.Sizeof(SQL_TIMESTAMP_STRUCT{})), true},
+		{"guid", SQL_GUID, 36, false, SQL_C_GUID, 16, true},
+		{"time2", SQL_SS_TIME2, 16, false, SQL_C_SS_TIME2, 12, true},
+		{"datetimeoffset", SQL_SS_TIMESTAMPOFFSET, 34, false, SQL_C_SS_TIMESTAMPOFFSET, 20, true},
+		{"decimal", SQL_DECIMAL, 18, false, SQL_C_CHAR, 22, true},
+		{"varchar", SQL_VARCHAR, 50, false, SQL_C_CHAR, 201, true},
+		{"wvarchar", SQL_WVARCHAR, 50, false, SQL_C_WCHAR, 101 * wcharSize(), true},
+		{"varbinary", SQL_VARBINARY, 16, false, SQL_C_BINARY, 16, true},
+		{"varchar(max)", SQL_VARCHAR, 0, true, 0, 0, false},
+		{"wide text", SQL_WLONGVARCHAR, 1 << 30, false, 0, 0, false},
+		{"interval", SQL_INTERVAL_DAY, 10, false, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cType, size, ok := blockColumnLayout(tt.colType, tt.colSize, tt.sizeUnknown, false)
+			if ok != tt.wantOK || cType != tt.wantCType || size != tt.wantSize {
+				t.Errorf("expected (%d, %d, %v), got (%d, %d, %v)", tt.wantCType, tt.wantSize, tt.wantOK, cType, size, ok)
+			}
+		})
+	}
+}
+
+func TestBlockColumnLayout_WideFetch(t *testing.T) {
+	for _, colType := range []SQLSMALLINT{SQL_CHAR, SQL_VARCHAR, SQL_LONGVARCHAR} {
+		cType, size, ok := blockColumnLayout(colType, 50, false, true)
+		if !ok || cType != SQL_C_WCHAR || size != 101*wcharSize() {
+			t.Errorf("type %d: expected (%d, %d, true), got (%d, %d, %v)", colType, SQL_C_WCHAR, 101*wcharSize(), cType, size, ok)
+		}
+	}
+	if cType, _, _ := blockColumnLayout(SQL_DECIMAL, 18, false, true); cType != SQL_C_CHAR {
+		t.Errorf("expected DECIMAL to stay SQL_C_CHAR, got %d", cType)
+	}
+	if _, _, ok := blockColumnLayout(SQL_VARCHAR, 0, true, true); ok {
+		t.Error("expected VARCHAR(MAX) to use SQLGetData")
+	}
+}
+
+func TestWithWideFetch(t *testing.T) {
+	c := &Connector{}
+	WithWideFetch(true)(c)
+	if !c.WideFetch {
+		t.Error("expected WideFetch to be set")
+	}
+	if (&Rows{}).wideFetch() {
+		t.Error("expected rows without a connection not to fetch wide")
+	}
+	r := &Rows{
+		stmt:        &Stmt{conn: &Conn{wideFetch: true}},
+		colTypes:    []SQLSMALLINT{SQL_LONGVARCHAR, SQL_LONGVARBINARY},
+		sizeUnknown: []bool{false, false},
+	}
+	if !r.wideFetch() {
+		t.Error("expected rows to follow the connection's WideFetch")
+	}
+	r.setLOBStreaming(true)
+	if want := []SQLSMALLINT{SQL_C_WCHAR, SQL_C_BINARY}; !reflect.DeepEqual(r.lobTypes, want) {
+		t.Errorf("lobTypes = %v, want %v", r.lobTypes, want)
+	}
+}
+
+func TestRows_RowArraySize(t *testing.T) {
+	r := &Rows{}
+	if n := r.rowArraySize(); n != 0 {
+		t.Errorf("expected 0 without a connection, got %d", n)
+	}
+	r.stmt = &Stmt{conn: &Conn{rowArraySize: 1}}
+	if n := r.rowArraySize(); n != 0 {
+		t.Errorf("expected 0 for a row array size of 1, got %d", n)
+	}
+	r.stmt.conn.rowArraySize = 500
+	if n := r.rowArraySize(); n != 500 {
+		t.Errorf("expected 500, got %d", n)
+	}
+	r.stmt.cursorType = CursorStatic
+	if n := r.rowArraySize(); n != 0 {
+		t.Errorf("expected scrollable cursors to disable block fetch, got %d", n)
+	}
+}
+
+func TestRows_BlockValue(t *testing.T) {
+	const rows = 2
+	newColumn := func(cType SQLSMALLINT, elemSize int) blockColumn {
+		return blockColumn{cType: cType, elemSize: elemSize, data: make([]byte, rows*elemSize), ind: make([]SQLLEN, rows)}
+	}
+	ints := newColumn(SQL_C_SBIGINT, 8)
+	binary.LittleEndian.PutUint64(ints.data[8:], 42)
+	ints.ind[0], ints.ind[1] = SQLLEN(SQL_NULL_DATA), 8
+
+	chars := newColumn(SQL_C_CHAR, 8)
+	copy(chars.data[8:], "hello\x00")
+	chars.ind[0], chars.ind[1] = 0, 5
+
+	timestamps := newColumn(SQL_C_TIMESTAMP, int(unsafe.Sizeof(SQL_TIMESTAMP_STRUCT{})))
+	*(*SQL_TIMESTAMP_STRUCT)(unsafe.Pointer(&timestamps.data[timestamps.elemSize])) = SQL_TIMESTAMP_STRUCT{Year: 2024, Month: 3, Day: 9, Hour: 8}
+	timestamps.ind[1] = SQLLEN(timestamps.elemSize)
+
+	overflow := newColumn(SQL_C_CHAR, 4)
+	overflow.ind[1] = 10
+
+	r := &Rows{
+		columns: []string{"id", "name", "ts", "code"},
+		block:   &blockFetch{columns: []blockColumn{ints, chars, timestamps, overflow}},
+	}
+
+	if v, err := r.blockValue(0); err != nil || v != nil {
+		t.Errorf("expected NULL, got %v (%v)", v, err)
+	}
+	if v, err := r.blockValue(1); err != nil || v != "" {
+		t.Errorf("expected empty string, got %#v (%v)", v, err)
+	}
+
+	r.block.pos = 1
+	if v, err := r.blockValue(0); err != nil || v != int64(42) {
+		t.Errorf("expected 42, got %#v (%v)", v, err)
+	}
+	if v, err := r.blockValue(1); err != nil || v != "hello" {
+		t.Errorf("expected hello, got %#v (%v)", v, err)
+	}
+	want := time.Date(2024, 3, 9, 8, 0, 0, 0, time.UTC)
+	if v, err := r.blockValue(2); err != nil || v != want {
+		t.Errorf("expected %v, got %#v (%v)", want, v, err)
+	}
+	if _, err := r.blockValue(3); err == nil {
+		t.Error("expected an error for a value larger than its bound buffer")
+	}
+}
+
+func TestWithRowArraySize(t *testing.T) {
+	c := &Connector{}
+	WithRowArraySize(1000)(c)
+	if c.RowArraySize != 1000 {
+		t.Errorf("expected 1000, got %d", c.RowArraySize)
+	}
+}
+
+// =============================================================================
+// Prefetch Tests (prefetch.go)
+// =============================================================================
+
+func TestBlockFetchSibling(t *testing.T) {
+	b := &blockFetch{
+		columns: []blockColumn{
+			{cType: SQL_C_SBIGINT, elemSize: 8, data: make([]byte, 32), ind: make([]SQLLEN, 4)},
+			{cType: SQL_C_CHAR, elemSize: 11, data: make([]byte, 44), ind: make([]SQLLEN, 4)},
+		},
+		statuses: make([]SQLUSMALLINT, 4),
+		fetched:  3,
+		pos:      2,
+	}
+	s := b.sibling()
+	if len(s.columns) != 2 || len(s.statuses) != 4 || s.fetched != 0 || s.pos != 0 {
+		t.Fatalf("unexpected sibling layout: %+v", s)
+	}
+	for i, col := range s.columns {
+		orig := b.columns[i]
+		if col.cType != orig.cType || col.elemSize != orig.elemSize || len(col.data) != len(orig.data) || len(col.ind) != len(orig.ind) {
+			t.Errorf("column %d: layout differs from original", i)
+		}
+		if &col.data[0] == &orig.data[0] {
+			t.Errorf("column %d: sibling shares the data buffer", i)
+		}
+	}
+}
+
+func TestRowsStartPrefetcher(t *testing.T) {
+	block := &blockFetch{statuses: make([]SQLUSMALLINT, 2)}
+
+	r := &Rows{stmt: &Stmt{conn: &Conn{}}, block: block}
+	r.startPrefetcher()
+	if r.prefetch != nil {
+		t.Error("expected no prefetcher when prefetch is disabled")
+	}
+
+	r.stmt.conn.prefetch = true
+	r.startPrefetcher()
+	if r.prefetch == nil || r.prefetch.spare == nil || r.prefetch.spare == block {
+		t.Fatal("expected a prefetcher with separate spare buffers")
+	}
+
+	r = &Rows{stmt: &Stmt{conn: &Conn{prefetch: true}}}
+	r.startPrefetcher()
+	if r.prefetch != nil {
+		t.Error("expected no prefetcher without block fetching")
+	}
+}
+
+func TestRowsNextRowsetFromPrefetch(t *testing.T) {
+	current := &blockFetch{statuses: make([]SQLUSMALLINT, 2), fetched: 2, pos: 2}
+	fetched := &blockFetch{statuses: make([]SQLUSMALLINT, 2), fetched: 1}
+	r := &Rows{block: current, prefetch: &prefetcher{result: make(chan prefetchResult, 1)}}
+
+	// End of the result set: the fetched buffers become current, the consumed ones spare
+	r.prefetch.result <- prefetchResult{block: fetched, ret: SQL_NO_DATA}
+	if err := r.nextRowset(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+	if r.block != fetched || r.prefetch.spare != current || r.prefetch.result != nil {
+		t.Error("expected the rowset buffers to be swapped")
+	}
+	if fetched.fetched != 0 {
+		t.Errorf("expected no rows in the final rowset, got %d", fetched.fetched)
+	}
+
+	// A failed background fetch reports its error
+	fetchErr := errors.New("fetch failed")
+	r.prefetch.spare = nil
+	r.prefetch.result = make(chan prefetchResult, 1)
+	r.prefetch.result <- prefetchResult{block: current, ret: SQL_ERROR, err: fetchErr}
+	if err := r.nextRowset(); err != fetchErr {
+		t.Errorf("expected fetch error, got %v", err)
+	}
+}
+
+func TestRowsStopPrefetch(t *testing.T) {
+	current := &blockFetch{}
+	fetched := &blockFetch{}
+	r := &Rows{block: current, prefetch: &prefetcher{result: make(chan prefetchResult, 1)}}
+	r.prefetch.result <- prefetchResult{block: fetched, ret: SQL_SUCCESS}
+
+	r.stopPrefetch()
+	if r.prefetch != nil {
+		t.Error("expected prefetcher to be discarded")
+	}
+	if r.block != fetched {
+		t.Error("expected the buffers bound by the last fetch to be kept until reset")
+	}
+	r.stopPrefetch() // no-op without a prefetcher
+}
+
+func TestWithPrefetch(t *testing.T) {
+	c := &Connector{}
+	WithPrefetch(true)(c)
+	if !c.Prefetch {
+		t.Error("expected Prefetch to be enabled")
+	}
+}
+
+// =============================================================================
+// Streamed Parameter Tests (stream.go)
+// =============================================================================
+
+func TestStreamParamOf(t *testing.T) {
+	if _, ok := streamParamOf([]byte("abc")); ok {
+		t.Error("expected []byte not to be streamed")
+	}
+	if _, ok := streamParamOf(StreamParam{}); ok {
+		t.Error("expected StreamParam without a reader not to be streamed")
+	}
+
+	sp, ok := streamParamOf(strings.NewReader("hello"))
+	if !ok || sp.Size != 5 || sp.Text {
+		t.Errorf("expected binary stream of size 5, got %+v (ok=%v)", sp, ok)
+	}
+
+	sp, ok = streamParamOf(io.MultiReader(strings.NewReader("hello")))
+	if !ok || sp.Size != 0 {
+		t.Errorf("expected stream of unknown size, got %+v (ok=%v)", sp, ok)
+	}
+
+	sp, ok = streamParamOf(StreamParam{Reader: strings.NewReader("text"), Text: true})
+	if !ok || !sp.Text {
+		t.Errorf("expected text stream, got %+v (ok=%v)", sp, ok)
+	}
+}
+
+func TestHasStreamParams(t *testing.T) {
+	plain := [][]driver.NamedValue{{{Ordinal: 1, Value: int64(1)}, {Ordinal: 2, Value: "a"}}}
+	if hasStreamParams(plain) {
+		t.Error("expected no streamed parameters")
+	}
+	streamed := append(plain, []driver.NamedValue{{Ordinal: 1, Value: int64(2)}, {Ordinal: 2, Value: strings.NewReader("b")}})
+	if !hasStreamParams(streamed) {
+		t.Error("expected a streamed parameter")
+	}
+}
+
+func TestSQLLenDataAtExec(t *testing.T) {
+	if got, ok := sqlLenDataAtExec(0); !ok || got != -100 {
+		t.Errorf("expected -100, got %d (ok=%v)", got, ok)
+	}
+	// 2 GiB fits in a 64-bit SQLLEN but not in a 32-bit one
+	got, ok := sqlLenDataAtExec(2 << 30)
+	if sqlLenSize == 8 {
+		if !ok || int64(got) != -100-(2<<30) {
+			t.Errorf("expected %d, got %d (ok=%v)", int64(-100-(2<<30)), got, ok)
+		}
+	} else if ok {
+		t.Errorf("expected 2 GiB not to fit in a 32-bit SQLLEN, got %d", got)
+	}
+	if got, ok := sqlLenDataAtExec(maxSQLLEN); ok {
+		t.Errorf("expected the largest SQLLEN not to fit, got %d", got)
+	}
+	if got, ok := sqlLenDataAtExec(maxSQLLEN - 99); !ok || int64(got) != -maxSQLLEN-1 {
+		t.Errorf("expected the smallest SQLLEN, got %d (ok=%v)", got, ok)
+	}
+}
+
+func TestStmt_BindStream_SizeTooLarge(t *testing.T) {
+	bind := sqlBindParameter
+	t.Cleanup(func() { sqlBindParameter = bind })
+	var gotColSize SQLULEN
+	sqlBindParameter = func(stmt SQLHSTMT, paramNum SQLUSMALLINT, ioType SQLSMALLINT, valueType SQLSMALLINT, paramType SQLSMALLINT, colSize SQLULEN, decDigits SQLSMALLINT, paramValue uintptr, bufferLen SQLLEN, strLenOrInd *SQLLEN) SQLRETURN {
+		gotColSize = colSize
+		return SQL_SUCCESS
+	}
+
+	s := &Stmt{paramBuffers: make([]interface{}, 2), paramLengths: make([]SQLLEN, 2)}
+	if err := s.bindStream(1, 0, StreamParam{Reader: strings.NewReader("x"), Size: 1 << 20}); err != nil {
+		t.Fatalf("bindStream: %v", err)
+	}
+	if want, _ := sqlLenDataAtExec(1 << 20); s.paramLengths[0] != want || gotColSize != 1<<20 {
+		t.Errorf("expected length %d and column size %d, got %d and %d", want, 1<<20, s.paramLengths[0], gotColSize)
+	}
+
+	if err := s.bindStream(2, 1, StreamParam{Reader: strings.NewReader("x"), Size: maxSQLLEN}); err != nil {
+		t.Fatalf("bindStream: %v", err)
+	}
+	if s.paramLengths[1] != SQL_DATA_AT_EXEC || gotColSize != 0 {
+		t.Errorf("expected SQL_DATA_AT_EXEC with column size 0, got %d and %d", s.paramLengths[1], gotColSize)
+	}
+}
+
+func TestStmt_ExecBatch_ArrayNeedData(t *testing.T) {
+	// A driver asking for data-at-execution values the array did not bind
+	fakeArrayBatch(t, func(SQLHSTMT) SQLRETURN { return SQL_NEED_DATA })
+	origData, origCancel := sqlParamData, sqlCancel
+	t.Cleanup(func() { sqlParamData, sqlCancel = origData, origCancel })
+	sqlParamData = func(_ SQLHSTMT, value *uintptr) SQLRETURN {
+		*value = 1
+		return SQL_NEED_DATA
+	}
+	cancels := 0
+	sqlCancel = func(SQLHSTMT) SQLRETURN {
+		cancels++
+		return SQL_SUCCESS
+	}
+
+	s := &Stmt{conn: &Conn{}, query: "INSERT INTO t VALUES (?)"}
+	paramSets := [][]driver.NamedValue{{{Ordinal: 1, Value: int64(1)}}, {{Ordinal: 1, Value: int64(2)}}}
+	result, err := s.ExecBatch(context.Background(), paramSets)
+	if err != nil {
+		t.Fatalf("ExecBatch() error: %v", err)
+	}
+	if cancels != 1 {
+		t.Errorf("expected the data-at-execution exchange to be cancelled once, got %d cancels", cancels)
+	}
+	for i, rowErr := range result.Errors {
+		if rowErr == nil || !strings.Contains(rowErr.Error(), "driver requested data for unknown parameter 1") {
+			t.Errorf("row %d: expected the data-at-execution error, got %v", i, rowErr)
+		}
+	}
+	if result.RowCounts[0] != 0 || result.RowCounts[1] != 0 {
+		t.Errorf("expected no rows affected, got %v", result.RowCounts)
+	}
+}
+
+func TestIncompleteRuneSuffix(t *testing.T) {
+	euro := []byte("€") // 3 bytes
+	tests := []struct {
+		input    []byte
+		expected int
+	}{
+		{[]byte("abc"), 0},
+		{[]byte(""), 0},
+		{append([]byte("a"), euro...), 0},
+		{append([]byte("a"), euro[:1]...), 1},
+		{append([]byte("a"), euro[:2]...), 2},
+		{[]byte("😀")[:3], 3},
+	}
+	for _, tt := range tests {
+		if got := incompleteRuneSuffix(tt.input); got != tt.expected {
+			t.Errorf("incompleteRuneSuffix(%q) = %d, expected %d", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestBindNamedParams_StreamReused(t *testing.T) {
+	s := &Stmt{namedParams: ParseNamedParams("SELECT :doc WHERE :doc IS NOT NULL")}
+	if s.namedParams == nil {
+		t.Fatal("expected named parameters to be parsed")
+	}
+	err := s.bindNamedParams([]driver.NamedValue{{Name: "doc", Value: strings.NewReader("x")}})
+	var paramErr *ParameterError
+	if !errors.As(err, &paramErr) {
+		t.Fatalf("expected ParameterError, got %v", err)
+	}
+}
+
+// =============================================================================
+// Dedicated Connection Tests (dedicated.go)
+// =============================================================================
+
+// staticConnector hands out a fixed driver connection to database/sql
+type staticConnector struct{ conn driver.Conn }
+
+func (s staticConnector) Connect(context.Context) (driver.Conn, error) { return s.conn, nil }
+func (s staticConnector) Driver() driver.Driver                        { return &Driver{} }
+
+// otherConn is a driver connection that is not a godbc connection
+type otherConn struct{ driver.Conn }
+
+func (otherConn) Close() error { return nil }
+
+func TestWithDedicatedConn(t *testing.T) {
+	c := &Conn{}
+	db := sql.OpenDB(staticConnector{conn: c})
+	defer db.Close()
+
+	var got *Conn
+	err := WithDedicatedConn(context.Background(), db, func(dc *Conn) error {
+		got = dc
+		return errors.New("callback failed")
+	})
+	if err == nil || err.Error() != "callback failed" {
+		t.Fatalf("expected the callback error, got %v", err)
+	}
+	if got != c {
+		t.Fatalf("expected the callback to get the pooled *Conn, got %p", got)
+	}
+	if !c.dead {
+		t.Error("expected the connection to be marked for discard")
+	}
+	if open := db.Stats().OpenConnections; open != 0 {
+		t.Errorf("expected the connection to be closed, %d still open", open)
+	}
+}
+
+func TestWithDedicatedConn_NotGodbc(t *testing.T) {
+	db := sql.OpenDB(staticConnector{conn: otherConn{}})
+	defer db.Close()
+
+	called := false
+	err := WithDedicatedConn(context.Background(), db, func(*Conn) error {
+		called = true
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "not a godbc connection") {
+		t.Errorf("expected a not a godbc connection error, got %v", err)
+	}
+	if called {
+		t.Error("callback should not run on a foreign connection")
+	}
+}
+
+// =============================================================================
+// Export Tests (export.go)
+// =============================================================================
+
+func TestCSVField(t *testing.T) {
+	tests := []struct {
+		value driver.Value
+		want  string
+	}{
+		{nil, ""},
+		{"text", "text"},
+		{[]byte{0xde, 0xad, 0xbe, 0xef}, "3q2+7w=="},
+		{time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC), "2024-03-01T12:30:00.0000005Z"},
+		{true, "true"},
+		{int64(-42), "-42"},
+		{1.5, "1.5"},
+	}
+	for _, tt := range tests {
+		if got := csvField(tt.value); got != tt.want {
+			t.Errorf("csvField(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestExportFileName(t *testing.T) {
+	if got := exportFileName(TableInfo{Schema: "sales", Name: "orders"}, ".csv"); got != "sales.orders.csv" {
+		t.Errorf("got %q", got)
+	}
+	if got := exportFileName(TableInfo{Name: "a/b:c"}, ".csv"); got != "a%2Fb%3Ac.csv" {
+		t.Errorf("got %q", got)
+	}
+	// The separator and '%' are escaped within names, so these do not collide
+	names := []TableInfo{{Schema: "a.b", Name: "c"}, {Schema: "a", Name: "b.c"}, {Schema: "a", Name: "b%2Ec"}}
+	seen := make(map[string]TableInfo)
+	for _, table := range names {
+		name := exportFileName(table, ".csv")
+		if other, ok := seen[name]; ok {
+			t.Errorf("%+v and %+v both map to %q", other, table, name)
+		}
+		seen[name] = table
+	}
+}
+
+func TestCSVSink(t *testing.T) {
+	dir := t.TempDir()
+	sink := CSVSink{Dir: dir}
+	table := TableInfo{Schema: "sales", Name: "orders"}
+
+	w, err := sink.Begin(table, []string{"id", "note"})
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	w.WriteRow([]driver.Value{int64(1), "a,b"})
+	w.WriteRow([]driver.Value{int64(2), nil})
+	if err := w.Close(nil); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "sales.orders.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "id,note\n1,\"a,b\"\n2,\n"; string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+
+	// A failed table leaves no file behind
+	failed := TableInfo{Schema: "sales", Name: "broken"}
+	w, err = sink.Begin(failed, []string{"id"})
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	w.WriteRow([]driver.Value{int64(1)})
+	w.Close(errors.New("fetch failed"))
+	if _, err := os.Stat(filepath.Join(dir, "sales.broken.csv")); !os.IsNotExist(err) {
+		t.Errorf("expected partial file to be removed, got %v", err)
+	}
+}
+
+func TestExportFunc(t *testing.T) {
+	var got []string
+	sink := ExportFunc(func(table TableInfo, columns []string, values []driver.Value) error {
+		got = append(got, table.Name+":"+columns[0]+"="+castToString(values[0]))
+		return nil
+	})
+	w, err := sink.Begin(TableInfo{Name: "t"}, []string{"c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteRow([]driver.Value{"x"})
+	if err := w.Close(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "t:c=x" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestExportReport_Failed(t *testing.T) {
+	report := &ExportReport{Tables: []ExportTableResult{
+		{Table: TableInfo{Name: "a"}, Rows: 10},
+		{Table: TableInfo{Name: "b"}, Err: errors.New("boom")},
+	}}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Table.Name != "b" {
+		t.Errorf("Failed() = %v", failed)
+	}
+}
+
+func TestWithExportReadOnlyTx(t *testing.T) {
+	e := &exporter{readOnlyTx: true}
+	WithExportReadOnlyTx(false)(e)
+	if e.readOnlyTx {
+		t.Error("expected WithExportReadOnlyTx(false) to disable the read-only transaction")
+	}
+}
+
+func TestExport_InvalidParallelism(t *testing.T) {
+	_, err := Export(context.Background(), nil, "", CSVSink{}, WithExportParallelism(0))
+	if err == nil {
+		t.Error("expected error for parallelism 0")
+	}
+}
+
+// =============================================================================
+// Keepalive Tests (keepalive.go)
+// =============================================================================
+
+func TestKeepAlivePinger_StartStop(t *testing.T) {
+	p := newKeepAlivePinger(time.Hour)
+	a, b := &Conn{}, &Conn{}
+	p.add(a)
+	p.add(b)
+	if p.stop == nil {
+		t.Fatal("expected ticker to run while connections are registered")
+	}
+	p.remove(a)
+	if p.stop == nil {
+		t.Fatal("expected ticker to keep running with one connection left")
+	}
+	p.remove(b)
+	if p.stop != nil {
+		t.Error("expected ticker to stop after the last connection")
+	}
+}
+
+func TestConn_IdleTracking(t *testing.T) {
+	c := &Conn{dbc: 1}
+	if !c.IsValid() {
+		t.Fatal("expected connection to be valid")
+	}
+	if c.idleSince.IsZero() {
+		t.Error("expected IsValid to start the idle period")
+	}
+	if err := c.ResetSession(context.Background()); err != nil {
+		t.Fatalf("ResetSession: %v", err)
+	}
+	if !c.idleSince.IsZero() {
+		t.Error("expected ResetSession to end the idle period")
+	}
+
+	c.dead = true
+	if c.IsValid() {
+		t.Error("expected dead connection to be invalid")
+	}
+	if err := c.ResetSession(context.Background()); err != driver.ErrBadConn {
+		t.Errorf("ResetSession on dead connection = %v, want ErrBadConn", err)
+	}
+}
+
+func TestConn_KeepAlivePingSkips(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		conn *Conn
+	}{
+		{"in use", &Conn{}},
+		{"recently idle", &Conn{idleSince: now.Add(-time.Second)}},
+		{"closed", &Conn{closed: true, idleSince: now.Add(-time.Hour)}},
+		{"dead", &Conn{dead: true, idleSince: now.Add(-time.Hour)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idleSince := tt.conn.idleSince
+			// None of these reach the driver, which is not loaded in unit tests
+			tt.conn.keepAlivePing(now, time.Minute)
+			if tt.conn.idleSince != idleSince {
+				t.Error("expected connection not to be pinged")
+			}
+		})
+	}
+
+	// A connection locked by a running operation is skipped
+	c := &Conn{idleSince: now.Add(-time.Hour)}
+	c.mu.Lock()
+	c.keepAlivePing(now, time.Minute)
+	c.mu.Unlock()
+}
+
+func TestConn_PingConnectionDead(t *testing.T) {
+	orig := sqlGetConnectAttr
+	t.Cleanup(func() { sqlGetConnectAttr = orig })
+	dead := SQLULEN(SQL_CD_FALSE)
+	sqlGetConnectAttr = func(_ SQLHDBC, attr SQLINTEGER, value uintptr, _ SQLINTEGER, _ *SQLINTEGER) SQLRETURN {
+		if attr != SQL_ATTR_CONNECTION_DEAD {
+			return SQL_ERROR
+		}
+		*(*SQLULEN)(unsafe.Add(nil, value)) = dead
+		return SQL_SUCCESS
+	}
+
+	// The attribute answers without executing a query, which would reach the unloaded driver
+	c := &Conn{dbc: 1}
+	if err := c.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() on a live connection = %v, want nil", err)
+	}
+	dead = SQL_CD_TRUE
+	if err := c.Ping(context.Background()); err != driver.ErrBadConn {
+		t.Errorf("Ping() on a lost connection = %v, want ErrBadConn", err)
+	}
+}
+
+func TestConn_PingCancelled(t *testing.T) {
+	origAttr, origAlloc, origFree := sqlGetConnectAttr, sqlAllocHandle, sqlFreeHandle
+	origExec, origCancel, savedWide := sqlExecDirect, sqlCancel, wideAPI
+	t.Cleanup(func() {
+		sqlGetConnectAttr, sqlAllocHandle, sqlFreeHandle = origAttr, origAlloc, origFree
+		sqlExecDirect, sqlCancel, wideAPI = origExec, origCancel, savedWide
+	})
+	wideAPI = false
+	sqlGetConnectAttr = func(SQLHDBC, SQLINTEGER, uintptr, SQLINTEGER, *SQLINTEGER) SQLRETURN { return SQL_ERROR }
+	sqlAllocHandle = func(_ SQLSMALLINT, _ SQLHANDLE, out *SQLHANDLE) SQLRETURN {
+		*out = 2
+		return SQL_SUCCESS
+	}
+	var freed atomic.Bool
+	sqlFreeHandle = func(SQLSMALLINT, SQLHANDLE) SQLRETURN {
+		freed.Store(true)
+		return SQL_SUCCESS
+	}
+	cancelled := make(chan struct{})
+	sqlCancel = func(SQLHSTMT) SQLRETURN {
+		if freed.Load() {
+			t.Error("statement cancelled after it was freed")
+		}
+		close(cancelled)
+		return SQL_SUCCESS
+	}
+	// The ping query hangs until it is cancelled
+	sqlExecDirect = func(SQLHSTMT, *byte, SQLINTEGER) SQLRETURN {
+		<-cancelled
+		return SQL_ERROR
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	c := &Conn{dbc: 1}
+	if err := c.Ping(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Ping() = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestCancelOnDone(t *testing.T) {
+	calls := 0
+	stop := cancelOnDone(context.Background(), func() { calls++ })
+	stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop = cancelOnDone(ctx, func() { calls++ })
+	stop()
+	cancel()
+	if calls != 0 {
+		t.Errorf("expected no cancel before the context is done, got %d", calls)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	called := make(chan struct{})
+	stop = cancelOnDone(ctx, func() { close(called) })
+	cancel()
+	<-called
+	stop()
+}
+
+func TestCancelHandle_Unavailable(t *testing.T) {
+	orig := sqlCancelHandle
+	t.Cleanup(func() { sqlCancelHandle = orig })
+	sqlCancelHandle = nil
+	if ret := CancelHandle(SQL_HANDLE_DBC, 1); ret != SQL_ERROR {
+		t.Errorf("CancelHandle() without SQLCancelHandle = %s, want SQL_ERROR", FormatReturnCode(ret))
+	}
+
+	var got SQLSMALLINT
+	sqlCancelHandle = func(handleType SQLSMALLINT, _ SQLHANDLE) SQLRETURN {
+		got = handleType
+		return SQL_SUCCESS
+	}
+	if ret := CancelHandle(SQL_HANDLE_DBC, 1); ret != SQL_SUCCESS || got != SQL_HANDLE_DBC {
+		t.Errorf("CancelHandle() = %s for handle type %d", FormatReturnCode(ret), got)
+	}
+}
+
+func TestConn_PingQueryFor(t *testing.T) {
+	tests := []struct {
+		dbType, pingQuery, want string
+	}{
+		{"Microsoft SQL Server", "", "SELECT 1"},
+		{"", "", "SELECT 1"},
+		{"Oracle", "", "SELECT 1 FROM DUAL"},
+		{"DB2/LINUXX8664", "", "SELECT 1 FROM SYSIBM.SYSDUMMY1"},
+		{"Firebird", "", "SELECT 1 FROM RDB$DATABASE"},
+		{"Oracle", "SELECT 2 FROM DUAL", "SELECT 2 FROM DUAL"},
+	}
+	for _, tt := range tests {
+		c := &Conn{dbType: tt.dbType, pingQuery: tt.pingQuery}
+		if got := c.pingQueryFor(); got != tt.want {
+			t.Errorf("pingQueryFor(%q, %q) = %q, want %q", tt.dbType, tt.pingQuery, got, tt.want)
+		}
+	}
+}
+
+func TestWithPingQuery(t *testing.T) {
+	c := &Connector{}
+	WithPingQuery("VALUES 1")(c)
+	if c.PingQuery != "VALUES 1" {
+		t.Errorf("PingQuery = %q, want VALUES 1", c.PingQuery)
+	}
+}
+
+// =============================================================================
+// Unicode Mode Tests (unicode.go)
+// =============================================================================
+
+func TestUseWideAPI(t *testing.T) {
+	saved, savedDefault := wideAPI, wideByDefault
+	defer func() { wideAPI, wideByDefault = saved, savedDefault }()
+
+	wideAPI, wideByDefault = true, false
+	tests := []struct {
+		mode UnicodeMode
+		text string
+		want bool
+	}{
+		{UnicodeAuto, "SELECT 1", false},
+		{UnicodeAuto, "SELECT * FROM café", true},
+		{UnicodeAuto, "PWD=pässword", true},
+		{UnicodeANSI, "SELECT * FROM café", false},
+		{UnicodeWide, "SELECT 1", true},
+	}
+	for _, tt := range tests {
+		if got := useWideAPI(tt.mode, tt.text); got != tt.want {
+			t.Errorf("useWideAPI(%d, %q) = %v, want %v", tt.mode, tt.text, got, tt.want)
+		}
+	}
+
+	// On Windows, Auto passes all text through the W functions
+	wideByDefault = true
+	if !useWideAPI(UnicodeAuto, "SELECT 1") || useWideAPI(UnicodeANSI, "SELECT 1") {
+		t.Error("expected Auto to use the W entry points for ASCII text on Windows")
+	}
+
+	// Without the W functions, every mode falls back to ANSI
+	wideAPI = false
+	if useWideAPI(UnicodeWide, "café") || useWideAPI(UnicodeAuto, "café") {
+		t.Error("expected ANSI entry points when the library has no W functions")
+	}
+}
+
+func TestDescribeCol_WideByDefault(t *testing.T) {
+	saved, savedDefault := wideAPI, wideByDefault
+	origW, orig := sqlDescribeColW, sqlDescribeCol
+	t.Cleanup(func() {
+		wideAPI, wideByDefault = saved, savedDefault
+		sqlDescribeColW, sqlDescribeCol = origW, orig
+	})
+	wideAPI, wideByDefault = true, true
+	var calls []string
+	sqlDescribeCol = func(SQLHSTMT, SQLUSMALLINT, *byte, SQLSMALLINT, *SQLSMALLINT, *SQLSMALLINT, *SQLULEN, *SQLSMALLINT, *SQLSMALLINT) SQLRETURN {
+		calls = append(calls, "A")
+		return SQL_ERROR
+	}
+	sqlDescribeColW = func(SQLHSTMT, SQLUSMALLINT, uintptr, SQLSMALLINT, *SQLSMALLINT, *SQLSMALLINT, *SQLULEN, *SQLSMALLINT, *SQLSMALLINT) SQLRETURN {
+		calls = append(calls, "W")
+		return SQL_ERROR
+	}
+
+	c := &Conn{}
+	c.describeCol(0, 1, make([]byte, 64))
+	c.unicode = UnicodeANSI
+	c.describeCol(0, 1, make([]byte, 64))
+	if !reflect.DeepEqual(calls, []string{"W", "A"}) {
+		t.Errorf("expected SQLDescribeColW in Auto mode on Windows and SQLDescribeCol in ANSI mode, got %v", calls)
+	}
+}
+
+// putWide writes s and a null terminator to the SQLWCHAR buffer at p, which
+// holds max characters, returning the length of s in characters
+func putWide(p uintptr, max int, s string) int {
+	buf, n, byteLen := wideStringParam(s)
+	src, _ := getBufferPtr(buf)
+	size := wcharSize()
+	copy(unsafe.Slice((*byte)(unsafe.Add(nil, p)), max*size), unsafe.Slice((*byte)(unsafe.Add(nil, src)), byteLen+size))
+	runtime.KeepAlive(buf)
+	return n
+}
+
+// wideArg decodes the null-terminated SQLWCHAR string at p, or "" for NULL
+func wideArg(p uintptr) string {
+	if p == 0 {
+		return ""
+	}
+	size := wcharSize()
+	n := 0
+	for {
+		c := unsafe.Slice((*byte)(unsafe.Add(nil, p+uintptr(n*size))), size)
+		if bytes.Count(c, []byte{0}) == size {
+			break
+		}
+		n++
+	}
+	return wideBufferString(unsafe.Slice((*byte)(unsafe.Add(nil, p)), n*size), n)
+}
+
+func TestConn_CatalogWide(t *testing.T) {
+	saved, savedDefault := wideAPI, wideByDefault
+	origW, orig := sqlTablesW, sqlTables
+	t.Cleanup(func() {
+		wideAPI, wideByDefault = saved, savedDefault
+		sqlTablesW, sqlTables = origW, orig
+	})
+	wideAPI, wideByDefault = true, false
+	var calls []string
+	sqlTables = func(SQLHSTMT, *byte, SQLSMALLINT, *byte, SQLSMALLINT, *byte, SQLSMALLINT, *byte, SQLSMALLINT) SQLRETURN {
+		calls = append(calls, "A")
+		return SQL_SUCCESS
+	}
+	sqlTablesW = func(_ SQLHSTMT, catalog uintptr, _ SQLSMALLINT, schema uintptr, _ SQLSMALLINT, table uintptr, _ SQLSMALLINT, types uintptr, _ SQLSMALLINT) SQLRETURN {
+		calls = append(calls, "W:"+wideArg(schema)+"."+wideArg(table))
+		if catalog != 0 || types != 0 {
+			t.Error("expected empty arguments passed as NULL")
+		}
+		return SQL_SUCCESS
+	}
+
+	c := &Conn{}
+	c.odbcTables(0, "", "sales", "orders", "")
+	c.odbcTables(0, "", "ventes", "commandes_été", "")
+	c.unicode = UnicodeANSI
+	c.odbcTables(0, "", "ventes", "commandes_été", "")
+	// On Windows, Auto passes all catalog arguments through the W functions
+	c.unicode, wideByDefault = UnicodeAuto, true
+	c.odbcTables(0, "", "sales", "orders", "")
+	want := []string{"A", "W:ventes.commandes_été", "A", "W:sales.orders"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected calls %q, got %q", want, calls)
+	}
+}
+
+func TestConn_WideOutput(t *testing.T) {
+	saved, savedDefault := wideAPI, wideByDefault
+	origInfo, origAttr, origCursor := sqlGetInfoW, sqlColAttributeW, sqlGetCursorNameW
+	t.Cleanup(func() {
+		wideAPI, wideByDefault = saved, savedDefault
+		sqlGetInfoW, sqlColAttributeW, sqlGetCursorNameW = origInfo, origAttr, origCursor
+	})
+	wideAPI, wideByDefault = true, true
+
+	// SQLGetInfoW and SQLColAttributeW lengths are in bytes
+	size := wcharSize()
+	sqlGetInfoW = func(_ SQLHDBC, _ SQLUSMALLINT, value uintptr, bufferLength SQLSMALLINT, strLen *SQLSMALLINT) SQLRETURN {
+		*strLen = SQLSMALLINT(len([]rune("Société DB")) * size)
+		if value != 0 {
+			putWide(value, int(bufferLength)/size, "Société DB")
+		}
+		return SQL_SUCCESS
+	}
+	sqlColAttributeW = func(_ SQLHSTMT, _, _ SQLUSMALLINT, charAttr uintptr, bufferLen SQLSMALLINT, strLen *SQLSMALLINT, _ *SQLLEN) SQLRETURN {
+		*strLen = SQLSMALLINT(putWide(charAttr, int(bufferLen)/size, "zeichenkette") * size)
+		return SQL_SUCCESS
+	}
+	sqlGetCursorNameW = func(_ SQLHSTMT, name uintptr, bufferLength SQLSMALLINT, nameLen *SQLSMALLINT) SQLRETURN {
+		*nameLen = SQLSMALLINT(putWide(name, int(bufferLength), "curseur_1"))
+		return SQL_SUCCESS
+	}
+
+	c := &Conn{}
+	if got, ok := c.getInfoString(SQL_DBMS_NAME); !ok || got != "Société DB" {
+		t.Errorf("getInfoString = %q, %v", got, ok)
+	}
+	if got, ret := c.colAttributeString(0, 1, SQL_DESC_TYPE_NAME, make([]byte, 128)); !IsSuccess(ret) || got != "zeichenkette" {
+		t.Errorf("colAttributeString = %q, %d", got, ret)
+	}
+	if got, ret := c.cursorName(0, maxCursorNameLen); !IsSuccess(ret) || got != "curseur_1" {
+		t.Errorf("cursorName = %q, %d", got, ret)
+	}
+}
+
+func TestGetDiagRecords_Wide(t *testing.T) {
+	saved, savedDefault := wideAPI, wideByDefault
+	origW, origRec, origField := sqlGetDiagRecW, sqlGetDiagRec, sqlGetDiagField
+	limits := diagLimits()
+	t.Cleanup(func() {
+		wideAPI, wideByDefault = saved, savedDefault
+		sqlGetDiagRecW, sqlGetDiagRec, sqlGetDiagField = origW, origRec, origField
+		SetDiagLimits(limits)
+	})
+	wideAPI, wideByDefault = true, true
+	const msg = "Größe fehlt"
+	sqlGetDiagRecW = func(_ SQLSMALLINT, _ SQLHANDLE, recNum SQLSMALLINT, state uintptr, native *SQLINTEGER, text uintptr, bufferLen SQLSMALLINT, textLen *SQLSMALLINT) SQLRETURN {
+		if recNum > 1 {
+			return SQL_NO_DATA
+		}
+		putWide(state, 6, "42S02")
+		*native = 208
+		*textLen = SQLSMALLINT(len([]rune(msg)))
+		if int(*textLen) >= int(bufferLen) {
+			putWide(text, int(bufferLen), string([]rune(msg)[:bufferLen-1]))
+			return SQL_SUCCESS_WITH_INFO
+		}
+		putWide(text, int(bufferLen), msg)
+		return SQL_SUCCESS
+	}
+	sqlGetDiagRec = func(SQLSMALLINT, SQLHANDLE, SQLSMALLINT, *byte, *SQLINTEGER, *byte, SQLSMALLINT, *SQLSMALLINT) SQLRETURN {
+		t.Error("expected SQLGetDiagRecW")
+		return SQL_NO_DATA
+	}
+	sqlGetDiagField = func(SQLSMALLINT, SQLHANDLE, SQLSMALLINT, SQLSMALLINT, uintptr, SQLSMALLINT, *SQLSMALLINT) SQLRETURN {
+		return SQL_ERROR
+	}
+
+	records := GetDiagRecords(SQL_HANDLE_STMT, 1)
+	want := []DiagRecord{{SQLState: "42S02", NativeError: 208, Message: msg}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("expected %+v, got %+v", want, records)
+	}
+
+	// Messages are cut at a character boundary within the byte limit
+	SetDiagLimits(DiagLimits{MaxMessageLen: 8})
+	if records := GetDiagRecords(SQL_HANDLE_STMT, 1); len(records) != 1 || records[0].Message != "Größe ..." {
+		t.Errorf("expected the message cut to %q, got %+v", "Größe ...", records)
+	}
+}
+
+func TestNeedsWideName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"customer_id", false},
+		{"Bestellmenge", false},
+		{"Gr??e", true},
+		{"Gr\xf6\xdfe", true},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := needsWideName(tt.name); got != tt.want {
+			t.Errorf("needsWideName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWideBufferString(t *testing.T) {
+	if wcharSize() != 2 {
+		t.Skip("requires 2-byte SQLWCHAR")
+	}
+	units := utf16.Encode([]rune("Größe 😀"))
+	buf := make([]byte, (len(units)+4)*2)
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(buf[i*2:], u)
+	}
+
+	if got := wideBufferString(buf, len(units)); got != "Größe 😀" {
+		t.Errorf("got %q", got)
+	}
+	// Lengths past the buffer are clamped and decoding stops at the terminator
+	if got := wideBufferString(buf, 1000); got != "Größe 😀" {
+		t.Errorf("clamped: got %q", got)
+	}
+	if got := wideBufferString(buf, 3); got != "Grö" {
+		t.Errorf("prefix: got %q", got)
+	}
+	if got := wideBufferString(buf, -1); got != "" {
+		t.Errorf("negative length: got %q", got)
+	}
+}
+
+func TestConn_CheckText(t *testing.T) {
+	reject := &Conn{invalidText: InvalidTextReject}
+	tests := []struct {
+		value  interface{}
+		offset int // -1 = valid
+	}{
+		{"plain ascii", -1},
+		{"héllo wörld 😀", -1},
+		{"abc\xed\xa0\x80def", 3}, // UTF-8 encoded surrogate half U+D800
+		{WideString("ok\xff"), 2},
+		{[]byte("\xff"), -1}, // binary values are not text
+		{42, -1},
+	}
+	for _, tt := range tests {
+		err := reject.checkText(2, tt.value)
+		var textErr *InvalidTextError
+		switch {
+		case tt.offset < 0 && err != nil:
+			t.Errorf("checkText(%q) = %v, want nil", tt.value, err)
+		case tt.offset >= 0 && (!errors.As(err, &textErr) || textErr.Param != 2 || textErr.Offset != tt.offset):
+			t.Errorf("checkText(%q) = %v, want an InvalidTextError for parameter 2 at byte %d", tt.value, err, tt.offset)
+		}
+	}
+
+	// Invalid text is replaced by default
+	if err := (&Conn{}).checkText(1, "\xff"); err != nil {
+		t.Errorf("checkText() with InvalidTextReplace = %v, want nil", err)
+	}
+	if got := stringToUTF16("a\xed\xa0\x80"); !reflect.DeepEqual(got, []uint16{'a', 0xFFFD, 0xFFFD, 0xFFFD, 0}) {
+		t.Errorf("stringToUTF16() = %x, want each invalid byte replaced with U+FFFD", got)
+	}
+}
+
+func TestStmt_BindParamRejectsInvalidText(t *testing.T) {
+	s := &Stmt{conn: &Conn{invalidText: InvalidTextReject}}
+	err := s.bindParam(3, "bad \xc3(")
+	var textErr *InvalidTextError
+	if !errors.As(err, &textErr) || textErr.Param != 3 || textErr.Offset != 4 {
+		t.Fatalf("bindParam() = %v, want an InvalidTextError for parameter 3 at byte 4", err)
+	}
+	if !strings.Contains(err.Error(), "parameter 3") {
+		t.Errorf("Error() = %q, want the parameter number", err.Error())
+	}
+}
+
+func TestWithInvalidTextMode(t *testing.T) {
+	c := &Connector{}
+	WithInvalidTextMode(InvalidTextReject)(c)
+	if c.InvalidText != InvalidTextReject {
+		t.Errorf("InvalidText = %v, want InvalidTextReject", c.InvalidText)
+	}
+}
+
+// =============================================================================
+// Procedure Call Tests (proc.go)
+// =============================================================================
+
+func TestIsProcCall(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"{CALL dbo.p(?, ?)}", true},
+		{"  { call p }", true},
+		{"{? = CALL p(?)}", true},
+		{"{?=call p(?)}", true},
+		{"CALL p(?)", false},
+		{"{callx p}", false},
+		{"{fn UCASE(?)}", false},
+		{"SELECT 1", false},
+	}
+	for _, tt := range tests {
+		if got := isProcCall(tt.query); got != tt.want {
+			t.Errorf("isProcCall(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHasReturnValue(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"{? = CALL dbo.p(?)}", true},
+		{" {?=call p}", true},
+		{"{CALL p(?)}", false},
+		{"{? = fn UCASE(?)}", false},
+		{"SELECT ?", false},
+	}
+	for _, tt := range tests {
+		if got := hasReturnValue(tt.query); got != tt.want {
+			t.Errorf("hasReturnValue(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestWithReturnValue(t *testing.T) {
+	// Without a ReturnValue, arguments move to the following markers
+	args := withReturnValue([]driver.NamedValue{{Ordinal: 1, Value: "a"}, {Ordinal: 2, Value: int64(2)}})
+	want := []driver.NamedValue{{Ordinal: 1, Value: ReturnValue{}}, {Ordinal: 2, Value: "a"}, {Ordinal: 3, Value: int64(2)}}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("withReturnValue() = %v, want %v", args, want)
+	}
+
+	// An explicit ReturnValue binds the first marker
+	explicit := []driver.NamedValue{{Ordinal: 1, Value: ReturnValue{Value: int64(0)}}, {Ordinal: 2, Value: "a"}}
+	if got := withReturnValue(explicit); !reflect.DeepEqual(got, explicit) {
+		t.Errorf("withReturnValue() = %v, want arguments unchanged", got)
+	}
+}
+
+func TestStmt_ReturnValueNumInput(t *testing.T) {
+	s := &Stmt{numInput: 2, returnValue: true}
+	if got := s.NumInput(); got != -1 {
+		t.Errorf("NumInput() = %d, want -1", got)
+	}
+	s.returnValue = false
+	if got := s.NumInput(); got != 2 {
+		t.Errorf("NumInput() = %d, want 2", got)
+	}
+}
+
+func TestResult_ReturnValue(t *testing.T) {
+	r := &Result{outputParams: []interface{}{int32(3), nil}, returnValue: int32(3)}
+	if got := r.ReturnValue(); got != int32(3) {
+		t.Errorf("ReturnValue() = %v, want 3", got)
+	}
+	if got := (&Result{}).ReturnValue(); got != nil {
+		t.Errorf("ReturnValue() = %v, want nil", got)
+	}
+}
+
+func TestProcParamNames(t *testing.T) {
+	query := "{CALL dbo.p(@b, @a)}"
+	np := ParseNamedParams(query)
+
+	c := &Conn{dbType: "Microsoft SQL Server"}
+	names := c.procParamNames(query, np)
+	if !reflect.DeepEqual(names, []string{"@b", "@a"}) {
+		t.Errorf("names = %v", names)
+	}
+
+	// Not a procedure call
+	if names := c.procParamNames("SELECT @a", ParseNamedParams("SELECT @a")); names != nil {
+		t.Errorf("expected nil for a query, got %v", names)
+	}
+
+	// A name used twice cannot be bound by name
+	dup := "{CALL p(@a, @a)}"
+	if names := c.procParamNames(dup, ParseNamedParams(dup)); names != nil {
+		t.Errorf("expected nil for a repeated name, got %v", names)
+	}
+
+	// Databases without named binding keep positional binding
+	pg := &Conn{dbType: "PostgreSQL"}
+	if names := pg.procParamNames(query, np); names != nil {
+		t.Errorf("expected nil for PostgreSQL, got %v", names)
+	}
+}
+
+func TestConn_CallProc_QuotedName(t *testing.T) {
+	origAlloc, origFree, origPrepare, origDiag, savedWide := sqlAllocHandle, sqlFreeHandle, sqlPrepare, sqlGetDiagRec, wideAPI
+	t.Cleanup(func() {
+		sqlAllocHandle, sqlFreeHandle, sqlPrepare, sqlGetDiagRec, wideAPI = origAlloc, origFree, origPrepare, origDiag, savedWide
+	})
+	wideAPI = false
+	sqlAllocHandle = func(_ SQLSMALLINT, _ SQLHANDLE, out *SQLHANDLE) SQLRETURN {
+		*out = 2
+		return SQL_SUCCESS
+	}
+	sqlFreeHandle = func(SQLSMALLINT, SQLHANDLE) SQLRETURN { return SQL_SUCCESS }
+	sqlGetDiagRec = func(SQLSMALLINT, SQLHANDLE, SQLSMALLINT, *byte, *SQLINTEGER, *byte, SQLSMALLINT, *SQLSMALLINT) SQLRETURN {
+		return SQL_NO_DATA
+	}
+	var prepared string
+	sqlPrepare = func(_ SQLHSTMT, text *byte, _ SQLINTEGER) SQLRETURN {
+		prepared = unsafe.String(text, cStringLen(text))
+		return SQL_ERROR
+	}
+
+	c := &Conn{dbc: 1, identifierQuote: `"`}
+	c.CallProc(context.Background(), `dbo.[p}; DROP TABLE t; {CALL q]`)
+	if want := `{CALL dbo."p}; DROP TABLE t; {CALL q"}`; prepared != want {
+		t.Errorf("prepared %q, want %q", prepared, want)
+	}
+
+	c = &Conn{dbc: 1, identifierQuote: " "}
+	prepared = ""
+	if _, err := c.CallProc(context.Background(), "[p}; x]"); err == nil || prepared != "" {
+		t.Errorf("expected a quoted name to be rejected without quoting support, got %v (prepared %q)", err, prepared)
+	}
+}
+
+// cStringLen returns the length of the NUL-terminated string at p
+func cStringLen(p *byte) int {
+	n := 0
+	for *(*byte)(unsafe.Add(unsafe.Pointer(p), n)) != 0 {
+		n++
+	}
+	return n
+}
+
+func TestProcCallQuery(t *testing.T) {
+	tests := []struct {
+		args    []driver.NamedValue
+		want    string
+		wantErr bool
+	}{
+		{nil, "{CALL dbo.p}", false},
+		{[]driver.NamedValue{{Value: 1}, {Value: 2}}, "{CALL dbo.p(?, ?)}", false},
+		{[]driver.NamedValue{{Name: "a", Value: 1}, {Name: "@c", Value: 3}}, "{CALL dbo.p(@a, @c)}", false},
+		{[]driver.NamedValue{{Name: "a", Value: 1}, {Value: 2}}, "", true},
+		{[]driver.NamedValue{{Name: "a; DROP", Value: 1}}, "", true},
+		{[]driver.NamedValue{{Value: ReturnValue{}}}, "{? = CALL dbo.p}", false},
+		{[]driver.NamedValue{{Value: ReturnValue{}}, {Value: 1}}, "{? = CALL dbo.p(?)}", false},
+		{[]driver.NamedValue{{Value: ReturnValue{}}, {Name: "a", Value: 1}}, "", true},
+		{[]driver.NamedValue{{Value: 1}, {Value: ReturnValue{}}}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := procCallQuery("dbo.p", tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("procCallQuery(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("procCallQuery(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestStmt_ReadProcResults(t *testing.T) {
+	fakeResultColumns(t)
+	fakeBatch(t, []SQLRETURN{SQL_SUCCESS, SQL_NO_DATA}, []SQLLEN{3, 1})
+	origClose := sqlCloseCursor
+	t.Cleanup(func() { sqlCloseCursor = origClose })
+	sqlCloseCursor = func(SQLHSTMT) SQLRETURN { return SQL_SUCCESS }
+
+	s := &Stmt{conn: &Conn{}, query: "{CALL dbo.p}"}
+	res, err := s.readProcResults()
+	if err != nil {
+		t.Fatalf("readProcResults failed: %v", err)
+	}
+	if len(res.ResultSets) != 0 {
+		t.Errorf("expected no result sets, got %+v", res.ResultSets)
+	}
+	if got := res.AllRowsAffected(); !reflect.DeepEqual(got, []int64{3, 1}) {
+		t.Errorf("AllRowsAffected = %v, want [3 1]", got)
+	}
+	if n, _ := res.RowsAffected(); n != 3 {
+		t.Errorf("RowsAffected = %d, want 3", n)
+	}
+	if res.OutputParams() != nil || res.ReturnValue() != nil {
+		t.Errorf("expected no output parameters, got %v", res.OutputParams())
+	}
+}
+
+// =============================================================================
+// Statement Description Tests (describe.go)
+// =============================================================================
+
+func TestIsRowQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT id FROM users WHERE id = ?", true},
+		{"  select 1", true},
+		{"(SELECT a FROM t) UNION (SELECT b FROM u)", true},
+		{"VALUES (1, 2)", true},
+		{"SELECT * INTO backup FROM users", false},
+		{"SELECT *\nINTO\tbackup FROM users", false},
+		{"SELECT a,b INTO#tmp FROM users", false},
+		{"SELECT 'INTO; x' AS \"into\" FROM users WHERE intonation = 1;", true},
+		{"SELECT 1; DELETE FROM users", false},
+		{"VALUES (1); DROP TABLE t", false},
+		{"INSERT INTO t VALUES (?)", false},
+		{"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", false},
+		{"SELECTED", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isRowQuery(tt.query); got != tt.want {
+			t.Errorf("isRowQuery(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestStmt_DescribeClosed(t *testing.T) {
+	s := &Stmt{conn: &Conn{}, closed: true}
+	if _, err := s.Describe(context.Background()); err != ErrStmtClosed {
+		t.Errorf("expected ErrStmtClosed, got %v", err)
+	}
+	s = &Stmt{conn: &Conn{closed: true}}
+	if _, err := s.Describe(context.Background()); err != driver.ErrBadConn {
+		t.Errorf("expected ErrBadConn, got %v", err)
+	}
+}
+
+// =============================================================================
+// Bulk Copy Tests (bulkcopy.go)
+// =============================================================================
+
+func TestBulkColumnKind(t *testing.T) {
+	tests := []struct {
+		dataType SQLSMALLINT
+		kind     bulkKind
+		standard bool
+	}{
+		{SQL_INTEGER, bulkInt, true},
+		{SQL_BIGINT, bulkInt, true},
+		{SQL_DOUBLE, bulkFloat, true},
+		{SQL_BIT, bulkBool, true},
+		{SQL_TYPE_TIMESTAMP, bulkTime, true},
+		{SQL_VARBINARY, bulkBinary, true},
+		{SQL_WVARCHAR, bulkString, true},
+		{SQL_DECIMAL, bulkString, true},
+		{-155, bulkString, false}, // SQL Server datetimeoffset
+	}
+	for _, tt := range tests {
+		kind, standard := bulkColumnKind(tt.dataType)
+		if kind != tt.kind || standard != tt.standard {
+			t.Errorf("bulkColumnKind(%d) = %v, %v; want %v, %v", tt.dataType, kind, standard, tt.kind, tt.standard)
+		}
+	}
+}
+
+func TestBulkValue(t *testing.T) {
+	ts := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		kind  bulkKind
+		value interface{}
+		want  driver.Value
+	}{
+		{bulkInt, 42, int64(42)},
+		{bulkInt, int32(7), int64(7)},
+		{bulkInt, " 12 ", int64(12)},
+		{bulkInt, 3.0, int64(3)},
+		{bulkInt, true, int64(1)},
+		{bulkFloat, 2, float64(2)},
+		{bulkFloat, "1.5", 1.5},
+		{bulkBool, int64(0), false},
+		{bulkBool, "true", true},
+		{bulkTime, ts, ts},
+		{bulkTime, "2024-03-15 10:30:00", ts},
+		{bulkTime, "2024-03-15T10:30:00Z", ts},
+		{bulkString, 42, "42"},
+		{bulkString, []byte("abc"), "abc"},
+		{bulkString, Decimal{Value: "12.50", Precision: 5, Scale: 2}, "12.50"},
+		{bulkString, ts, "2024-03-15 10:30:00"},
+		{bulkString, nil, nil},
+		{bulkInt, nil, nil},
+		{bulkInt, sql.NullInt64{Int64: 5, Valid: true}, int64(5)},
+		{bulkInt, sql.NullInt64{}, nil},
+	}
+	for _, tt := range tests {
+		got, err := bulkValue(tt.kind, tt.value)
+		if err != nil {
+			t.Errorf("bulkValue(%v, %#v) error: %v", tt.kind, tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("bulkValue(%v, %#v) = %#v, want %#v", tt.kind, tt.value, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		kind  bulkKind
+		value interface{}
+	}{
+		{bulkInt, "abc"},
+		{bulkInt, 1.5},
+		{bulkFloat, ts},
+		{bulkBool, "maybe"},
+		{bulkTime, "yesterday"},
+		{bulkBinary, 42},
+	} {
+		if _, err := bulkValue(tt.kind, tt.value); err == nil {
+			t.Errorf("bulkValue(%v, %#v) expected error", tt.kind, tt.value)
+		}
+	}
+
+	buf := []byte{1, 2, 3}
+	got, _ := bulkValue(bulkBinary, buf)
+	buf[0] = 9
+	if got.([]byte)[0] != 1 {
+		t.Error("bulkValue should copy byte slices")
+	}
+}
+
+func TestColumnBuffer_ApplyColumnType(t *testing.T) {
+	buf, _ := AllocateColumnArray([]interface{}{"12.50", "3.00"}, 2)
+	buf.applyColumnType(ColumnInfo{DataType: SQL_DECIMAL, ColumnSize: 10, DecimalDigits: 2})
+	if buf.SQLType != SQL_DECIMAL || buf.ColSize != 10 || buf.DecDigits != 2 {
+		t.Errorf("got type %d size %d digits %d", buf.SQLType, buf.ColSize, buf.DecDigits)
+	}
+
+	buf, _ = AllocateColumnArray([]interface{}{time.Now()}, 1)
+	buf.applyColumnType(ColumnInfo{DataType: SQL_TYPE_DATE, ColumnSize: 10})
+	if buf.SQLType != SQL_TYPE_DATE || buf.ColSize != 23 || buf.DecDigits != 3 {
+		t.Errorf("timestamp buffer: got type %d size %d digits %d", buf.SQLType, buf.ColSize, buf.DecDigits)
+	}
+
+	buf, _ = AllocateColumnArray([]interface{}{"x"}, 1)
+	buf.applyColumnType(ColumnInfo{})
+	if buf.SQLType != SQL_WVARCHAR {
+		t.Errorf("unknown column type should keep inferred type, got %d", buf.SQLType)
+	}
+}
+
+func TestBulkColumns(t *testing.T) {
+	table := []ColumnInfo{
+		{Name: "ID", DataType: SQL_INTEGER, AutoIncrement: true},
+		{Name: "NAME", DataType: SQL_VARCHAR},
+		{Name: "Total", DataType: SQL_DECIMAL},
+	}
+	c := &Conn{identifierCase: IdentifierCaseUpper}
+
+	cols, err := c.bulkColumns(table, nil)
+	if err != nil || len(cols) != 2 || cols[0].Name != "NAME" || cols[1].Name != "Total" {
+		t.Errorf("default columns = %v, %v", cols, err)
+	}
+
+	cols, err = c.bulkColumns(table, []string{`"Total"`, "id"})
+	if err != nil || len(cols) != 2 || cols[0].Name != "Total" || cols[1].Name != "ID" {
+		t.Errorf("named columns = %v, %v", cols, err)
+	}
+
+	if _, err := c.bulkColumns(table, []string{"missing"}); err == nil {
+		t.Error("expected error for unknown column")
+	}
+	if _, err := c.bulkColumns(table, []string{"a; DROP"}); err == nil {
+		t.Error("expected error for invalid column name")
+	}
+	if _, err := c.bulkColumns(table[:1], nil); err == nil {
+		t.Error("expected error when only auto-increment columns exist")
+	}
+}
+
+func TestBulkCopy_AddRow(t *testing.T) {
+	b := &BulkCopy{
+		columns:   []ColumnInfo{{Name: "id"}, {Name: "name"}},
+		kinds:     []bulkKind{bulkInt, bulkString},
+		batchSize: 10,
+	}
+	b.values = make([]driver.NamedValue, b.batchSize*len(b.columns))
+
+	if err := b.AddRow(context.Background(), 1); err == nil {
+		t.Error("expected error for wrong value count")
+	}
+	if err := b.AddRow(context.Background(), "x", "a"); err == nil {
+		t.Error("expected conversion error")
+	}
+	if err := b.AddRow(context.Background(), 1, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddRow(context.Background(), "2", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.pending) != 2 {
+		t.Fatalf("expected 2 pending rows, got %d", len(b.pending))
+	}
+	row := b.pending[1]
+	if row[0].Ordinal != 1 || row[0].Value != int64(2) || row[1].Ordinal != 2 || row[1].Value != nil {
+		t.Errorf("unexpected row %v", row)
+	}
+
+	b.err = errBulkCopyClosed
+	if err := b.AddRow(context.Background(), 3, "c"); err != errBulkCopyClosed {
+		t.Errorf("expected errBulkCopyClosed, got %v", err)
+	}
+}
+
+func TestBulkCopyError(t *testing.T) {
+	cause := errors.New("constraint violation")
+	err := &BulkCopyError{Row: 1000, Rows: 500, Err: cause}
+	if err.Error() != "bulk copy rows 1000-1499: constraint violation" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("BulkCopyError should unwrap to its cause")
+	}
+}
+
+// =============================================================================
+// Copy Tests (copy.go)
+// =============================================================================
+
+// sliceRows is a driver.Rows over in-memory rows, failing with err after them
+type sliceRows struct {
+	rows [][]driver.Value
+	err  error
+}
+
+func (r *sliceRows) Columns() []string { return []string{"id"} }
+func (r *sliceRows) Close() error      { return nil }
+func (r *sliceRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		if r.err != nil {
+			return r.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func intRows(n int) [][]driver.Value {
+	rows := make([][]driver.Value, n)
+	for i := range rows {
+		rows[i] = []driver.Value{int64(i)}
+	}
+	return rows
+}
+
+func TestReadBatches(t *testing.T) {
+	batches := make(chan [][]driver.Value, 10)
+	if err := readBatches(context.Background(), &sliceRows{rows: intRows(7)}, 1, 3, batches); err != nil {
+		t.Fatalf("readBatches failed: %v", err)
+	}
+	close(batches)
+	var sizes []int
+	var next int64
+	for batch := range batches {
+		sizes = append(sizes, len(batch))
+		for _, row := range batch {
+			if row[0] != next {
+				t.Fatalf("row %v out of order, want %d", row, next)
+			}
+			next++
+		}
+	}
+	if !reflect.DeepEqual(sizes, []int{3, 3, 1}) {
+		t.Errorf("batch sizes = %v, want [3 3 1]", sizes)
+	}
+
+	// A source error ends the reading without sending the partial batch
+	batches = make(chan [][]driver.Value, 10)
+	srcErr := errors.New("connection lost")
+	if err := readBatches(context.Background(), &sliceRows{rows: intRows(4), err: srcErr}, 1, 3, batches); err != srcErr {
+		t.Errorf("expected the source error, got %v", err)
+	}
+	if len(batches) != 1 {
+		t.Errorf("expected 1 full batch sent, got %d", len(batches))
+	}
+
+	// A cancelled copy stops a reader waiting for the writer
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := readBatches(ctx, &sliceRows{rows: intRows(3)}, 1, 3, make(chan [][]driver.Value)); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSkipRows(t *testing.T) {
+	src := &sliceRows{rows: intRows(5)}
+	if err := skipRows(context.Background(), src, 1, 3); err != nil {
+		t.Fatalf("skipRows failed: %v", err)
+	}
+	if len(src.rows) != 2 || src.rows[0][0] != int64(3) {
+		t.Errorf("expected to resume at row 3, got %v", src.rows)
+	}
+	if err := skipRows(context.Background(), src, 1, 3); err == nil {
+		t.Error("expected an error when resuming past the end of the source")
+	}
+}
+
+func TestCopy_Options(t *testing.T) {
+	if _, err := Copy(context.Background(), &Conn{}, "SELECT 1", &Conn{}, "t", WithCopyBatchSize(0)); err == nil {
+		t.Error("expected an error for batch size 0")
+	}
+	if _, err := Copy(context.Background(), &Conn{}, "SELECT 1", &Conn{}, "t", WithCopyResume(-1)); err == nil {
+		t.Error("expected an error for a negative resume position")
+	}
+	c := &Conn{}
+	if _, err := Copy(context.Background(), c, "SELECT 1", c, "t"); err == nil {
+		t.Error("expected an error for the same source and destination connection")
+	}
+}
+
+// =============================================================================
+// Bool Rule Tests (boolmap.go)
+// =============================================================================
+
+func TestBoolRule_ToBool(t *testing.T) {
+	numeric := &BoolRule{}
+	yn := &BoolRule{True: "Y", False: "N"}
+	tests := []struct {
+		rule  *BoolRule
+		value interface{}
+		want  interface{}
+	}{
+		{numeric, int64(1), true},
+		{numeric, int64(0), false},
+		{numeric, int64(-1), true},
+		{numeric, "1", true},
+		{numeric, " 0 ", false},
+		{numeric, float64(0), false},
+		{numeric, true, true},
+		{numeric, nil, nil},
+		{numeric, "abc", "abc"},
+		{yn, "Y", true},
+		{yn, "n", false},
+		{yn, "N ", false},
+		{yn, []byte("Y"), true},
+		{yn, "?", "?"},
+		{yn, int64(1), int64(1)},
+		{yn, nil, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.toBool(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("toBool(%#v) with True=%q = %#v, want %#v", tt.value, tt.rule.True, got, tt.want)
+		}
+	}
+}
+
+func TestBoolRule_FromBool(t *testing.T) {
+	numeric := &BoolRule{}
+	if numeric.fromBool(true) != int64(1) || numeric.fromBool(false) != int64(0) {
+		t.Error("numeric rule should bind 1 and 0")
+	}
+	yn := &BoolRule{True: "Y", False: "N"}
+	if yn.fromBool(true) != "Y" || yn.fromBool(false) != "N" {
+		t.Error("textual rule should bind its True and False values")
+	}
+}
+
+func TestConn_ColumnBoolRules(t *testing.T) {
+	c := &Conn{
+		dbType: "MySQL",
+		boolRules: []BoolRule{
+			{Dialect: "oracle", TypeName: regexp.MustCompile(`(?i)^char$`), ColumnSize: 1, True: "Y", False: "N"},
+			{Dialect: "mysql", TypeName: regexp.MustCompile(`(?i)^tinyint$`), ColumnSize: 1},
+			{TypeName: regexp.MustCompile(`(?i)^char$`), ColumnSize: 1, True: "T", False: "F"},
+		},
+	}
+	rules := c.columnBoolRules(
+		[]string{"tinyint", "tinyint", "CHAR", "varchar"},
+		[]SQLULEN{1, 4, 1, 1},
+	)
+	if len(rules) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(rules))
+	}
+	if rules[0] != &c.boolRules[1] {
+		t.Error("tinyint(1) should use the mysql rule")
+	}
+	if rules[1] != nil {
+		t.Error("tinyint(4) should not be converted")
+	}
+	if rules[2] != &c.boolRules[2] {
+		t.Error("char(1) should use the dialect-independent rule, not the oracle one")
+	}
+	if rules[3] != nil {
+		t.Error("varchar should not be converted")
+	}
+
+	if c.columnBoolRules([]string{"varchar"}, []SQLULEN{10}) != nil {
+		t.Error("expected nil when no column is converted")
+	}
+	if (&Conn{}).columnBoolRules([]string{"tinyint"}, []SQLULEN{1}) != nil {
+		t.Error("expected nil without rules")
+	}
+}
+
+func TestConn_BindBool(t *testing.T) {
+	c := &Conn{
+		dbType: "Oracle",
+		boolRules: []BoolRule{
+			{Dialect: "mysql", Bind: true},
+			{Dialect: "oracle", True: "Y", False: "N", Bind: true},
+		},
+	}
+	if got := c.bindBool(true); got != "Y" {
+		t.Errorf("bindBool(true) = %v, want Y", got)
+	}
+	if got := c.bindBool(int64(1)); got != int64(1) {
+		t.Errorf("non-bool values should be unchanged, got %v", got)
+	}
+	c.boolRules[1].Bind = false
+	if got := c.bindBool(true); got != true {
+		t.Errorf("bool should be unchanged without a Bind rule, got %v", got)
+	}
+	var nilConn *Conn
+	if got := nilConn.bindBool(true); got != true {
+		t.Errorf("nil connection should leave bool unchanged, got %v", got)
+	}
+}
+
+func TestRows_BoolRuleScanType(t *testing.T) {
+	r := &Rows{
+		colTypes:  []SQLSMALLINT{SQL_TINYINT, SQL_TINYINT},
+		boolRules: []*BoolRule{{}, nil},
+	}
+	if got := r.ColumnTypeScanType(0); got != reflect.TypeOf(false) {
+		t.Errorf("expected bool scan type, got %v", got)
+	}
+	if got := r.ColumnTypeScanType(1); got == reflect.TypeOf(false) {
+		t.Error("column without a rule should keep its scan type")
+	}
+}
+
+// =============================================================================
+// Decimal Fetch Tests (decimal.go)
+// =============================================================================
+
+func TestDecimalFromNumeric(t *testing.T) {
+	tests := []struct {
+		name string
+		ns   SQL_NUMERIC_STRUCT
+		want Decimal
+	}{
+		{"scaled", SQL_NUMERIC_STRUCT{Precision: 10, Scale: 2, Sign: 1, Val: [16]SQLCHAR{1}}, Decimal{Value: "0.01", Precision: 10, Scale: 2}},
+		{"negative", SQL_NUMERIC_STRUCT{Precision: 5, Scale: 1, Sign: 0, Val: [16]SQLCHAR{0x39, 0x30}}, Decimal{Value: "-1234.5", Precision: 5, Scale: 1}},
+		{"zero scale", SQL_NUMERIC_STRUCT{Precision: 3, Scale: 0, Sign: 1, Val: [16]SQLCHAR{42}}, Decimal{Value: "42", Precision: 3, Scale: 0}},
+		{"negative scale", SQL_NUMERIC_STRUCT{Precision: 3, Scale: -2, Sign: 1, Val: [16]SQLCHAR{7}}, Decimal{Value: "700", Precision: 3, Scale: 0}},
+		{"128-bit", SQL_NUMERIC_STRUCT{Precision: 38, Scale: 0, Sign: 1, Val: [16]SQLCHAR{15: 1}}, Decimal{Value: "1329227995784915872903807060280344576", Precision: 38, Scale: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decimalFromNumeric(tt.ns); got != tt.want {
+				t.Errorf("decimalFromNumeric() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalFromText(t *testing.T) {
+	tests := []struct {
+		in               string
+		precision, scale int
+		want             string
+		wantScale        int
+		ok               bool
+	}{
+		{".01", 10, 2, "0.01", 2, true},
+		{"+0.010", 10, 2, "0.01", 2, true},
+		{"-.5", 10, 2, "-0.50", 2, true},
+		{" 12 ", 5, 0, "12", 0, true},
+		{"1.2345", 10, 2, "1.2345", 4, true},
+		{"1.5E2", 10, 1, "150.0", 1, true},
+		{"3.14", 0, -1, "3.14", 2, true},
+		{"abc", 10, 2, "", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := decimalFromText(tt.in, tt.precision, tt.scale)
+		if ok != tt.ok {
+			t.Errorf("decimalFromText(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			continue
+		}
+		if ok && (got.Value != tt.want || got.Scale != tt.wantScale) {
+			t.Errorf("decimalFromText(%q) = %+v, want value %q scale %d", tt.in, got, tt.want, tt.wantScale)
+		}
+	}
+
+	if got, _ := decimalFromText("-0012.50", 0, -1); got.Precision != 3 || got.Scale != 1 {
+		t.Errorf("decimalFromText() computed precision/scale = %d/%d, want 3/1", got.Precision, got.Scale)
+	}
+}
+
+func TestDecimal_RatAndString(t *testing.T) {
+	d := Decimal{Value: "-12.50", Precision: 4, Scale: 2}
+	if d.String() != "-12.50" {
+		t.Errorf("String() = %q, want -12.50", d.String())
+	}
+	r, err := d.Rat()
+	if err != nil {
+		t.Fatalf("Rat() error = %v", err)
+	}
+	if r.Cmp(big.NewRat(-25, 2)) != 0 {
+		t.Errorf("Rat() = %v, want -25/2", r)
+	}
+	if _, err := (Decimal{Value: "x"}).Rat(); err == nil {
+		t.Error("Rat() expected error for invalid value")
+	}
+}
+
+func TestRows_DecimalScanType(t *testing.T) {
+	rows := &Rows{colTypes: []SQLSMALLINT{SQL_DECIMAL}, colSizes: []SQLULEN{10}, decDigits: []SQLSMALLINT{2}}
+	if got := rows.ColumnTypeScanType(0); got != reflect.TypeOf("") {
+		t.Errorf("default ScanType = %v, want string", got)
+	}
+	rows.stmt = &Stmt{conn: &Conn{decimalFetchMode: DecimalFetchNumeric}}
+	if got := rows.ColumnTypeScanType(0); got != reflect.TypeOf(Decimal{}) {
+		t.Errorf("numeric ScanType = %v, want Decimal", got)
+	}
+}
+
+func TestCastValue_Decimal(t *testing.T) {
+	got, err := castValue(Decimal{Value: "1.50", Precision: 3, Scale: 2}, CastFloat64)
+	if err != nil {
+		t.Fatalf("castValue() error = %v", err)
+	}
+	if got != 1.5 {
+		t.Errorf("castValue() = %v, want 1.5", got)
+	}
+}
+
+// =============================================================================
+// Connection Attribute Tests (connattr.go)
+// =============================================================================
+
+func TestWithConnectAttr(t *testing.T) {
+	c := &Connector{}
+	WithConnectAttr(SQL_ATTR_LOGIN_TIMEOUT, 5*time.Second)(c)
+	WithConnectAttr(SQL_ATTR_CURRENT_CATALOG, "sales")(c)
+	want := []ConnectAttr{
+		{Attr: SQL_ATTR_LOGIN_TIMEOUT, Value: 5 * time.Second},
+		{Attr: SQL_ATTR_CURRENT_CATALOG, Value: "sales"},
+	}
+	if !reflect.DeepEqual(c.ConnectAttrs, want) {
+		t.Errorf("ConnectAttrs = %v, want %v", c.ConnectAttrs, want)
+	}
+}
+
+func TestConnectAttrInt(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  uintptr
+		ok    bool
+	}{
+		{4096, 4096, true},
+		{int32(7), 7, true},
+		{uint64(9), 9, true},
+		{true, SQL_TRUE, true},
+		{false, SQL_FALSE, true},
+		{1500 * time.Millisecond, 2, true},
+		{30 * time.Second, 30, true},
+		{-1, 0, false},
+		{1.5, 0, false},
+		{nil, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := connectAttrInt(tt.value)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("connectAttrInt(%v) = %d, %v, want %d, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAccessModeOf(t *testing.T) {
+	if got := accessModeOf(nil); got != SQL_MODE_READ_WRITE {
+		t.Errorf("accessModeOf(nil) = %d, want read-write", got)
+	}
+	attrs := []ConnectAttr{
+		{Attr: SQL_ATTR_PACKET_SIZE, Value: 8192},
+		{Attr: SQL_ATTR_ACCESS_MODE, Value: SQL_MODE_READ_ONLY},
+	}
+	if got := accessModeOf(attrs); got != SQL_MODE_READ_ONLY {
+		t.Errorf("accessModeOf() = %d, want read-only", got)
+	}
+}
+
+// =============================================================================
+// Quirk Tests (quirks.go)
+// =============================================================================
+
+// registerTestQuirk registers a quirk for the duration of a test
+func registerTestQuirk(t *testing.T, pattern string, q Quirk) {
+	t.Helper()
+	quirksMu.Lock()
+	orig := append([]registeredQuirk(nil), quirks...)
+	quirksMu.Unlock()
+	t.Cleanup(func() {
+		quirksMu.Lock()
+		quirks = orig
+		quirksMu.Unlock()
+	})
+	RegisterQuirk(pattern, q)
+}
+
+func TestRegisterQuirk(t *testing.T) {
+	registerTestQuirk(t, "Exasol", Quirk{PingQuery: "SELECT 1 FROM DUAL"})
+	registerTestQuirk(t, "exasol", Quirk{PingQuery: "SELECT 2 FROM DUAL"})
+	registerTestQuirk(t, "exa", Quirk{LastInsertIdQuery: "SELECT LAST_IDENTITY()"})
+
+	if q := lookupQuirk("PostgreSQL"); q != nil {
+		t.Errorf("expected no quirk for PostgreSQL, got %+v", q)
+	}
+	if q := lookupQuirk(""); q != nil {
+		t.Errorf("expected no quirk for an unknown database, got %+v", q)
+	}
+
+	// The last registered match applies
+	q := lookupQuirk("EXASolution")
+	if q == nil || q.LastInsertIdQuery != "SELECT LAST_IDENTITY()" {
+		t.Fatalf("expected the last registered quirk, got %+v", q)
+	}
+
+	// Registering a pattern again replaces its quirk
+	registerTestQuirk(t, "EXASOL", Quirk{PingQuery: "SELECT 3 FROM DUAL"})
+	if q := lookupQuirk("EXASolution"); q == nil || q.PingQuery != "SELECT 3 FROM DUAL" {
+		t.Errorf("expected the replaced quirk, got %+v", q)
+	}
+	if len(quirks) != 2 {
+		t.Errorf("expected 2 registered quirks, got %d", len(quirks))
+	}
+}
+
+func TestConn_QuirkOverrides(t *testing.T) {
+	registerTestQuirk(t, "oracle", Quirk{PingQuery: "SELECT 1 FROM SYS.DUAL"})
+
+	c := &Conn{dbType: "Oracle", quirk: lookupQuirk("Oracle")}
+	if got := c.pingQueryFor(); got != "SELECT 1 FROM SYS.DUAL" {
+		t.Errorf("pingQueryFor() = %q, want the quirk's query", got)
+	}
+	c.pingQuery = "SELECT 2 FROM DUAL"
+	if got := c.pingQueryFor(); got != "SELECT 2 FROM DUAL" {
+		t.Errorf("pingQueryFor() = %q, want WithPingQuery to take precedence", got)
+	}
+}
+
+func TestRows_ResolveCasts_QuirkTypeCasts(t *testing.T) {
+	registerTestQuirk(t, "sql server", Quirk{TypeCasts: map[string]CastType{"MONEY": CastFloat64, "xml": CastString}})
+	conn := &Conn{quirk: lookupQuirk("Microsoft SQL Server")}
+
+	r := &Rows{
+		stmt:        &Stmt{conn: conn},
+		columns:     []string{"id", "price", "doc"},
+		nativeTypes: []string{"int", "money", "xml"},
+	}
+	r.setColumnCasts(nil)
+	if want := []CastType{CastNone, CastFloat64, CastString}; !reflect.DeepEqual(r.casts, want) {
+		t.Errorf("casts = %v, want %v", r.casts, want)
+	}
+
+	// A cast set for the column takes precedence
+	r.setColumnCasts(ColumnCasts{"price": CastString, "id": CastString})
+	if want := []CastType{CastString, CastString, CastString}; !reflect.DeepEqual(r.casts, want) {
+		t.Errorf("casts = %v, want %v", r.casts, want)
+	}
+
+	// Without the quirk no cast applies
+	r.stmt.conn = &Conn{}
+	r.setColumnCasts(nil)
+	if r.casts != nil {
+		t.Errorf("expected no casts, got %v", r.casts)
+	}
+}
+
+func TestConn_ApplyStmtAttrs(t *testing.T) {
+	origSet := sqlSetStmtAttr
+	t.Cleanup(func() { sqlSetStmtAttr = origSet })
+	var set []SQLINTEGER
+	sqlSetStmtAttr = func(_ SQLHSTMT, attr SQLINTEGER, _ uintptr, _ SQLINTEGER) SQLRETURN {
+		set = append(set, attr)
+		return SQL_SUCCESS
+	}
+
+	c := &Conn{quirk: &Quirk{StmtAttrs: []StmtAttr{
+		{Attr: SQL_ATTR_MAX_ROWS, Value: 1000},
+		{Attr: 1227, Value: 1.5}, // unsupported value, skipped
+		{Attr: 1228, Value: "on"},
+	}}}
+	c.applyStmtAttrs(context.Background(), 1)
+	if want := []SQLINTEGER{SQL_ATTR_MAX_ROWS, 1228}; !reflect.DeepEqual(set, want) {
+		t.Errorf("set attributes %v, want %v", set, want)
+	}
+
+	set = nil
+	(&Conn{}).applyStmtAttrs(context.Background(), 1)
+	if set != nil {
+		t.Errorf("expected no attributes without a quirk, got %v", set)
+	}
+}
+
+// =============================================================================
+// Pagination Tests (paginate.go)
+// =============================================================================
+
+func TestPager_KeysetQuery(t *testing.T) {
+	tests := []struct {
+		dbType string
+		hasKey bool
+		want   string
+	}{
+		{"PostgreSQL", false, "SELECT * FROM (SELECT id, name FROM users WHERE active = ?) godbc_page ORDER BY id LIMIT 50"},
+		{"PostgreSQL", true, "SELECT * FROM (SELECT id, name FROM users WHERE active = ?) godbc_page WHERE id > ? ORDER BY id LIMIT 50"},
+		{"Microsoft SQL Server", true, "SELECT TOP 50 * FROM (SELECT id, name FROM users WHERE active = ?) godbc_page WHERE id > ? ORDER BY id"},
+		{"Oracle", true, "SELECT * FROM (SELECT id, name FROM users WHERE active = ?) godbc_page WHERE id > ? ORDER BY id FETCH FIRST 50 ROWS ONLY"},
+	}
+	for _, tt := range tests {
+		p := &Pager{
+			conn:     &Conn{dbType: tt.dbType},
+			query:    "SELECT id, name FROM users WHERE active = ?;\n",
+			pageSize: 50,
+			key:      "id",
+			hasKey:   tt.hasKey,
+		}
+		if got := p.keysetQuery(); got != tt.want {
+			t.Errorf("%s: keysetQuery() = %q, want %q", tt.dbType, got, tt.want)
+		}
+	}
+}
+
+func TestPaginate_InvalidOptions(t *testing.T) {
+	ctx := context.Background()
+	if _, err := Paginate(ctx, &Conn{}, "SELECT 1", 0); err == nil {
+		t.Error("expected error for page size 0")
+	}
+	if _, err := Paginate(ctx, &Conn{}, "SELECT 1", 10, WithPageKey("id; DROP TABLE t")); err == nil {
+		t.Error("expected error for invalid key column")
+	}
+}
+
+func TestPager_PageRequiresCursor(t *testing.T) {
+	p := &Pager{conn: &Conn{}, pageSize: 10, key: "id"}
+	if p.Scrollable() {
+		t.Error("expected keyset pager not to be scrollable")
+	}
+	if _, err := p.Page(context.Background(), 2); err == nil {
+		t.Error("expected error reading a page out of order without a cursor")
+	}
+	if err := p.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+// =============================================================================
+// Batch Error Value Tests (batcherr.go)
+// =============================================================================
+
+func TestStmt_KeepBatchErrorValues(t *testing.T) {
+	rowErr := errors.New("constraint violation")
+	paramSets := [][]driver.NamedValue{
+		{{Ordinal: 1, Value: int64(1)}, {Ordinal: 2, Value: "ok"}},
+		{{Ordinal: 1, Value: int64(2)}, {Ordinal: 2, Value: "héllo wörld"}, {Ordinal: 3, Value: []byte("abcdef")}, {Ordinal: 4, Value: "secret"}},
+		{{Ordinal: 1, Value: StreamParam{Reader: strings.NewReader("x")}}},
+	}
+	result := &BatchResult{Errors: []error{nil, rowErr, rowErr}}
+
+	s := &Stmt{conn: &Conn{batchErrorValues: &BatchErrorValues{
+		MaxValueSize: 2,
+		Redact: func(p driver.NamedValue) interface{} {
+			if p.Ordinal == 4 {
+				return "***"
+			}
+			return p.Value
+		},
+	}}}
+	s.keepBatchErrorValues(paramSets, result)
+
+	if result.Errors[0] != nil {
+		t.Errorf("expected successful row to stay nil, got %v", result.Errors[0])
+	}
+	var be *BatchRowError
+	if !errors.As(result.Errors[1], &be) {
+		t.Fatalf("expected *BatchRowError, got %T", result.Errors[1])
+	}
+	if be.Row != 1 || !errors.Is(be, rowErr) || be.Error() != rowErr.Error() {
+		t.Errorf("unexpected row error: row=%d err=%v", be.Row, be.Err)
+	}
+	expected := []interface{}{int64(2), "h", []byte("ab"), "**"}
+	if !reflect.DeepEqual(be.Values, expected) {
+		t.Errorf("expected values %#v, got %#v", expected, be.Values)
+	}
+
+	// Truncation must not change the caller's slices
+	if string(paramSets[1][2].Value.([]byte)) != "abcdef" {
+		t.Error("expected parameter values to be copied")
+	}
+
+	if !errors.As(result.Errors[2], &be) || be.Values[0] != nil {
+		t.Errorf("expected streamed values to be kept as nil, got %v", be.Values)
+	}
+
+	// Wrapping again is a no-op
+	s.keepBatchErrorValues(paramSets, result)
+	if _, ok := result.Errors[1].(*BatchRowError).Err.(*BatchRowError); ok {
+		t.Error("expected errors not to be wrapped twice")
+	}
+}
+
+func TestStmt_KeepBatchErrorValuesDisabled(t *testing.T) {
+	rowErr := errors.New("failed")
+	result := &BatchResult{Errors: []error{rowErr}}
+	s := &Stmt{conn: &Conn{}}
+	s.keepBatchErrorValues([][]driver.NamedValue{{{Ordinal: 1, Value: "x"}}}, result)
+	if result.Errors[0] != rowErr {
+		t.Errorf("expected error unchanged without the option, got %v", result.Errors[0])
+	}
+}
+
+// =============================================================================
+// Metrics Tests (metrics.go)
+// =============================================================================
+
+type recordingMetrics struct {
+	NopMetrics
+	events []string
+}
+
+func (m *recordingMetrics) Prepare(query string, d time.Duration) {
+	m.events = append(m.events, "prepare "+query)
+}
+
+func (m *recordingMetrics) Exec(op MetricsOp, query string, d time.Duration, rowsAffected int64) {
+	m.events = append(m.events, fmt.Sprintf("%s %s %d", op, query, rowsAffected))
+}
+
+func (m *recordingMetrics) Error(op MetricsOp, query string, err error) {
+	m.events = append(m.events, fmt.Sprintf("error %s %s: %v", op, query, err))
+}
+
+func TestConn_MetricsCallbacks(t *testing.T) {
+	var _ MetricsCollector = NopMetrics{}
+
+	m := &recordingMetrics{}
+	c := &Conn{metrics: m}
+	c.observePrepare("SELECT 1", time.Millisecond, nil)
+	c.observePrepare("SELEC 1", time.Millisecond, errors.New("syntax error"))
+	c.observeExec(MetricsExec, "DELETE FROM t", time.Millisecond, 3)
+	c.observeExec(MetricsQuery, "SELECT 1", time.Millisecond, -1)
+	if err := c.observeError(MetricsFetch, "SELECT 1", nil); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+	fetchErr := errors.New("connection lost")
+	if err := c.observeError(MetricsFetch, "SELECT 1", fetchErr); err != fetchErr {
+		t.Errorf("expected observeError to return its error, got %v", err)
+	}
+
+	expected := []string{
+		"prepare SELECT 1",
+		"error prepare SELEC 1: syntax error",
+		"exec DELETE FROM t 3",
+		"query SELECT 1 -1",
+		"error fetch SELECT 1: connection lost",
+	}
+	if !reflect.DeepEqual(m.events, expected) {
+		t.Errorf("expected events %q, got %q", expected, m.events)
+	}
+}
+
+func TestConn_MetricsDisabled(t *testing.T) {
+	var c *Conn
+	c.observePrepare("SELECT 1", 0, nil)
+	c.observeExec(MetricsExec, "SELECT 1", 0, 0)
+	err := errors.New("failed")
+	if got := (&Conn{}).observeError(MetricsExec, "SELECT 1", err); got != err {
+		t.Errorf("expected error returned without metrics, got %v", got)
+	}
+}
+
+// fakeDirectQuery makes direct execution return ret, with a one-column result
+// set when it succeeds
+func fakeDirectQuery(t *testing.T, ret SQLRETURN) {
+	t.Helper()
+	fakeCatalogStmt(t)
+	fakeResultColumns(t, "id")
+	origExec, origClose := sqlExecDirect, sqlCloseCursor
+	t.Cleanup(func() { sqlExecDirect, sqlCloseCursor = origExec, origClose })
+	sqlExecDirect = func(SQLHSTMT, *byte, SQLINTEGER) SQLRETURN { return ret }
+	sqlCloseCursor = func(SQLHSTMT) SQLRETURN { return SQL_SUCCESS }
+}
+
+func TestConn_QueryContext_DirectMetrics(t *testing.T) {
+	m := &recordingMetrics{}
+	c := &Conn{dbc: 1, metrics: m}
+
+	fakeDirectQuery(t, SQL_SUCCESS)
+	rows, err := c.QueryContext(context.Background(), "SELECT 1", nil)
+	if err != nil {
+		t.Fatalf("QueryContext: %v", err)
+	}
+	rows.Close()
+
+	fakeDirectQuery(t, SQL_ERROR)
+	if _, err := c.QueryContext(context.Background(), "SELEC 1", nil); err == nil {
+		t.Fatal("expected QueryContext to fail")
+	}
+
+	if len(m.events) != 2 || m.events[0] != "query SELECT 1 -1" || !strings.HasPrefix(m.events[1], "error query SELEC 1: ") {
+		t.Errorf("unexpected events %q", m.events)
+	}
+}
+
+// =============================================================================
+// Tracing Tests (tracing.go)
+// =============================================================================
+
+type recordingSpan struct {
+	name  string
+	attrs map[string]interface{}
+	errs  []error
+	ended bool
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordingSpan) RecordError(err error) { s.errs = append(s.errs, err) }
+func (s *recordingSpan) End()                  { s.ended = true }
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs []Attribute) Span {
+	span := &recordingSpan{name: name, attrs: map[string]interface{}{}}
+	span.SetAttributes(attrs...)
+	t.spans = append(t.spans, span)
+	return span
+}
+
+func TestConn_QueryContext_DirectSpans(t *testing.T) {
+	tracer := &recordingTracer{}
+	c := &Conn{dbc: 1, tracer: tracer}
+
+	fakeDirectQuery(t, SQL_SUCCESS)
+	rows, err := c.QueryContext(context.Background(), "SELECT 1", nil)
+	if err != nil {
+		t.Fatalf("QueryContext: %v", err)
+	}
+	if len(tracer.spans) != 2 || tracer.spans[0].name != SpanQuery || !tracer.spans[0].ended ||
+		tracer.spans[1].name != SpanFetch || tracer.spans[1].ended {
+		t.Fatalf("expected an ended query span and an open fetch span, got %+v", tracer.spans)
+	}
+	rows.Close()
+	if !tracer.spans[1].ended {
+		t.Error("expected closing the rows to end the fetch span")
+	}
+
+	fakeDirectQuery(t, SQL_ERROR)
+	if _, err := c.QueryContext(context.Background(), "SELEC 1", nil); err == nil {
+		t.Fatal("expected QueryContext to fail")
+	}
+	if len(tracer.spans) != 3 || !tracer.spans[2].ended || len(tracer.spans[2].errs) != 1 {
+		t.Errorf("expected a failed query span, got %+v", tracer.spans[2:])
+	}
+}
+
+func TestSanitizeSQL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"SELECT * FROM t WHERE id = 42", "SELECT * FROM t WHERE id = ?"},
+		{"SELECT * FROM t WHERE name = 'O''Brien' AND x = 1.5e3", "SELECT * FROM t WHERE name = ? AND x = ?"},
+		{"SELECT col1, t2.c3 FROM t2 WHERE v = ?", "SELECT col1, t2.c3 FROM t2 WHERE v = ?"},
+		{`SELECT "a 1" FROM t LIMIT 10`, `SELECT "a 1" FROM t LIMIT ?`},
+		{"INSERT INTO t VALUES (-7, 'x')", "INSERT INTO t VALUES (-?, ?)"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeSQL(tt.input); got != tt.expected {
+			t.Errorf("sanitizeSQL(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	tests := map[string]string{
+		"select 1":               "SELECT",
+		"  (SELECT 1) UNION ALL": "SELECT",
+		"{CALL dbo.p(?)}":        "CALL",
+		"{? = CALL dbo.p(?)}":    "CALL",
+		"":                       "",
+	}
+	for query, expected := range tests {
+		if got := operationName(query); got != expected {
+			t.Errorf("operationName(%q) = %q, expected %q", query, got, expected)
+		}
+	}
+}
+
+func TestDBSystemName(t *testing.T) {
+	tests := map[string]string{
+		"Microsoft SQL Server": "microsoft.sql_server",
+		"PostgreSQL":           "postgresql",
+		"MariaDB":              "mariadb",
+		"Oracle":               "oracle.db",
+		"DB2/LINUXX8664":       "ibm.db2",
+		"Snowflake":            "other_sql",
+	}
+	for dbms, expected := range tests {
+		if got := dbSystemName(dbms); got != expected {
+			t.Errorf("dbSystemName(%q) = %q, expected %q", dbms, got, expected)
+		}
+	}
+}
+
+func TestConn_StartSpan(t *testing.T) {
+	if span := (&Conn{}).startSpan(context.Background(), SpanExec, "SELECT 1"); span != nil {
+		t.Error("expected no span without a tracer")
+	}
+	endSpan(nil, errors.New("ignored"))
+
+	tracer := &recordingTracer{}
+	c := &Conn{tracer: tracer, dbType: "PostgreSQL"}
+	span := c.startSpan(context.Background(), SpanExec, "DELETE FROM t WHERE id = 5")
+	endSpan(span, &Error{SQLState: "23503", Message: "foreign key violation"})
+
+	rec := tracer.spans[0]
+	expected := map[string]interface{}{
+		AttrDBSystemName: "postgresql",
+		AttrDBMSName:     "PostgreSQL",
+		AttrDBQueryText:  "DELETE FROM t WHERE id = ?",
+		AttrDBOperation:  "DELETE",
+		AttrDBStatusCode: "23503",
+		AttrErrorType:    "23503",
+	}
+	if rec.name != SpanExec || !rec.ended || len(rec.errs) != 1 || !reflect.DeepEqual(rec.attrs, expected) {
+		t.Errorf("unexpected span %s ended=%v errs=%v attrs=%v", rec.name, rec.ended, rec.errs, rec.attrs)
+	}
+
+	span = c.startSpan(context.Background(), SpanQuery, "SELECT 1")
+	endSpan(span, context.DeadlineExceeded)
+	if got := tracer.spans[1].attrs[AttrErrorType]; got != "context.DeadlineExceeded" {
+		t.Errorf("expected context error type, got %v", got)
+	}
+	if _, ok := tracer.spans[1].attrs[AttrDBStatusCode]; ok {
+		t.Error("expected no status code for a non-ODBC error")
+	}
+}
+
+func TestSQLStateOf(t *testing.T) {
+	if got := sqlStateOf(fmt.Errorf("exec: %w", Errors{{SQLState: "40001"}, {SQLState: "01000"}})); got != "40001" {
+		t.Errorf("expected first SQLSTATE, got %q", got)
+	}
+	if got := sqlStateOf(io.EOF); got != "" {
+		t.Errorf("expected no SQLSTATE, got %q", got)
+	}
+}
+
+// =============================================================================
+// Type Support Tests (typesupport.go)
+// =============================================================================
+
+func TestTypeSupport(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		sqlType  SQLSMALLINT
+		expected TypeFidelity
+	}{
+		{"Microsoft SQL Server", SQL_INTEGER, TypeExact},
+		{"Microsoft SQL Server", SQL_TYPE_TIMESTAMP, TypeLossy},
+		{"Microsoft SQL Server", SQL_SS_UDT, TypeUnsupported},
+		{"Oracle", SQL_VARCHAR, TypeLossy},
+		{"oracle", SQL_BIT, TypeUnsupported},
+		{"PostgreSQL", SQL_VARCHAR, TypeExact},
+		{"PostgreSQL", SQL_SS_UDT, TypeFidelityUnknown},
+		{"", SQL_REAL, TypeLossy},
+		{"", SQL_GUID, TypeExact},
+		{"SQLite", SQL_GUID, TypeUnsupported},
+		{"", SQL_SS_XML, TypeFidelityUnknown},
+	}
+	for _, tt := range tests {
+		info := TypeSupport(tt.dialect, tt.sqlType)
+		if info.Fidelity != tt.expected {
+			t.Errorf("TypeSupport(%q, %d) = %v, expected %v", tt.dialect, tt.sqlType, info.Fidelity, tt.expected)
+		}
+		if (info.Fidelity == TypeLossy || info.Fidelity == TypeUnsupported) && info.Note == "" {
+			t.Errorf("TypeSupport(%q, %d) has no note", tt.dialect, tt.sqlType)
+		}
+	}
+	if s := TypeUnsupported.String(); s != "unsupported" {
+		t.Errorf("expected unsupported, got %q", s)
+	}
+}
+
+// =============================================================================
+// Time Binding Tests (timebind.go)
+// =============================================================================
+
+func TestConn_BindTime(t *testing.T) {
+	ts := time.Date(2024, 3, 9, 8, 30, 15, 123456789, time.UTC)
+
+	if got := (&Conn{dbType: "Microsoft SQL Server"}).bindTime(ts); got != ts {
+		t.Errorf("expected time bound as timestamp by default, got %v", got)
+	}
+	if got := (&Conn{dbType: "ACCESS"}).bindTime(ts); got != "2024-03-09 08:30:15" {
+		t.Errorf("expected Access literal, got %v", got)
+	}
+	if got := (&Conn{dbType: "Informix Dynamic Server"}).bindTime(NewTimestamp(ts, TimestampPrecisionMicroseconds)); got != "2024-03-09 08:30:15.12345" {
+		t.Errorf("expected Informix literal, got %v", got)
+	}
+	if got := (&Conn{dbType: "ACCESS", timeBindMode: TimeBindTimestamp}).bindTime(ts); got != ts {
+		t.Errorf("expected TimeBindTimestamp to override the dialect, got %v", got)
+	}
+
+	c := &Conn{timeBindMode: TimeBindString}
+	if got := c.bindTime(ts); got != "2024-03-09 08:30:15.123" {
+		t.Errorf("expected default layout, got %v", got)
+	}
+	c.timeLayout = "01/02/2006 15h"
+	if got := c.bindTime(ts); got != "03/09/2024 08h" {
+		t.Errorf("expected custom layout, got %v", got)
+	}
+	est := time.FixedZone("EST", -5*3600)
+	if got := c.bindTime(TimestampTZ{Time: ts.In(est), TZ: est}); got != "03/09/2024 08h" {
+		t.Errorf("expected TimestampTZ formatted in UTC, got %v", got)
+	}
+	if got := c.bindTime("2024-03-09"); got != "2024-03-09" {
+		t.Errorf("expected other values unchanged, got %v", got)
+	}
+}
+
+// =============================================================================
+// Logging Tests (logging.go)
+// =============================================================================
+
+func TestLogDiagnostics_OnlyWithInfo(t *testing.T) {
+	var buf strings.Builder
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	// Only SQL_SUCCESS_WITH_INFO has diagnostics to log, so no handle is read here
+	for _, ret := range []SQLRETURN{SQL_SUCCESS, SQL_NO_DATA, SQL_ERROR} {
+		logDiagnostics(context.Background(), logger, ret, SQL_HANDLE_STMT, 0, "execute")
+	}
+	logDiagnostics(context.Background(), nil, SQL_SUCCESS_WITH_INFO, SQL_HANDLE_STMT, 0, "execute")
+	(&Conn{}).logInfo(context.Background(), SQL_SUCCESS_WITH_INFO, 0, "execute")
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing logged, got %q", buf.String())
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	c := &Connector{}
+	WithLogger(logger)(c)
+	if c.Logger != logger {
+		t.Error("expected WithLogger to set the connector's logger")
+	}
+}
+
+// =============================================================================
+// Session Reset Tests (reset.go)
+// =============================================================================
+
+func TestWithResetQuery(t *testing.T) {
+	c := &Connector{}
+	WithResetQuery("DISCARD ALL")(c)
+	if c.ResetQuery != "DISCARD ALL" {
+		t.Errorf("ResetQuery = %q, want %q", c.ResetQuery, "DISCARD ALL")
+	}
+}
+
+func TestConn_ResetSessionState(t *testing.T) {
+	c := &Conn{dbc: 1, txnIsolationSet: true, resetQuery: "DISCARD ALL"}
+	if err := c.ResetSession(context.Background()); err != nil {
+		t.Fatalf("ResetSession: %v", err)
+	}
+
+	c.inTx = true
+	if err := c.ResetSession(context.Background()); err == nil {
+		t.Error("expected ResetSession to fail inside a transaction")
+	}
+}
+
+func TestConn_ResetFailed(t *testing.T) {
+	var buf strings.Builder
+	c := &Conn{dbType: "PostgreSQL", logger: slog.New(slog.NewTextHandler(&buf, nil))}
+	if err := c.resetFailed(context.Background(), errors.New("reset query: boom")); err != driver.ErrBadConn {
+		t.Errorf("resetFailed() = %v, want ErrBadConn", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "session reset failed") || !strings.Contains(out, "boom") {
+		t.Errorf("log output = %q, want the reset failure", out)
+	}
+}
+
+// =============================================================================
+// Fetch Cancellation Tests (rows.go)
+// =============================================================================
+
+// stallFetch replaces SQLFetch with a server that never returns a row until
+// the statement is cancelled, and reports the number of SQLCancel calls
+func stallFetch(t *testing.T) *int32 {
+	t.Helper()
+	fetch, cancel := sqlFetch, sqlCancel
+	t.Cleanup(func() { sqlFetch, sqlCancel = fetch, cancel })
+
+	var mu sync.Mutex
+	var cancels int32
+	cancelled := make(chan struct{})
+	sqlFetch = func(stmt SQLHSTMT) SQLRETURN {
+		<-cancelled
+		return SQL_ERROR
+	}
+	sqlCancel = func(stmt SQLHSTMT) SQLRETURN {
+		mu.Lock()
+		defer mu.Unlock()
+		if cancels++; cancels == 1 {
+			close(cancelled)
+		}
+		return SQL_SUCCESS
+	}
+	return &cancels
+}
+
+func TestRows_Next_CancelsStalledFetch(t *testing.T) {
+	cancels := stallFetch(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	r := &Rows{stmt: &Stmt{conn: &Conn{}, stmt: 1}, columns: []string{"id"}}
+	r.watchContext(ctx)
+	defer r.stopWatch()
+
+	errc := make(chan error, 1)
+	go func() { errc <- r.Next(nil) }()
+	select {
+	case err := <-errc:
+		if err != context.DeadlineExceeded {
+			t.Errorf("Next() = %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next did not return after the context deadline")
+	}
+	if *cancels != 1 {
+		t.Errorf("SQLCancel called %d times, want 1", *cancels)
+	}
+}
+
+func TestRows_Next_ContextDoneBeforeFetch(t *testing.T) {
+	stallFetch(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := &Rows{stmt: &Stmt{conn: &Conn{}, stmt: 1}, columns: []string{"id"}}
+	r.ctx = ctx
+	if err := r.Next(nil); err != context.Canceled {
+		t.Errorf("Next() = %v, want context.Canceled", err)
+	}
+}
+
+func TestRows_StopWatch(t *testing.T) {
+	cancels := stallFetch(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &Rows{stmt: &Stmt{conn: &Conn{}, stmt: 1}, columns: []string{"id"}}
+	r.watchContext(ctx)
+	r.stopWatch()
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if *cancels != 0 {
+		t.Errorf("SQLCancel called %d times after the rows stopped watching, want 0", *cancels)
+	}
+
+	r.watchContext(context.Background())
+	if r.unwatch != nil {
+		t.Error("expected no watcher for a context that is never done")
+	}
+}
+
+// =============================================================================
+// Statement Cache Tests (stmtcache.go)
+// =============================================================================
+
+func TestWithStmtCacheSize(t *testing.T) {
+	c := &Connector{}
+	WithStmtCacheSize(16)(c)
+	if c.StmtCacheSize != 16 {
+		t.Errorf("StmtCacheSize = %d, want 16", c.StmtCacheSize)
+	}
+	if newStmtCache(0) != nil {
+		t.Error("expected no cache for size 0")
+	}
+}
+
+func TestStmtCache_Reuse(t *testing.T) {
+	sc := newStmtCache(2)
+	s := &Stmt{query: "SELECT ?"}
+	cs := sc.add("SELECT ?", s)
+	if cs == nil {
+		t.Fatal("expected the statement to be cached")
+	}
+	if sc.take("SELECT ?") != nil {
+		t.Error("expected a statement in use not to be handed out again")
+	}
+	if sc.add("SELECT ?", &Stmt{}) != nil {
+		t.Error("expected a second statement for a cached query not to be cached")
+	}
+
+	sc.release(cs, nil)
+	if got := sc.take("SELECT ?"); got != cs {
+		t.Fatalf("take() = %v, want the released statement", got)
+	}
+	sc.release(cs, nil)
+	if s.closed {
+		t.Error("expected a released statement to stay open")
+	}
+}
+
+func TestStmtCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	sc := newStmtCache(2)
+	a, b, c := &Stmt{}, &Stmt{}, &Stmt{}
+	sc.release(sc.add("a", a), nil)
+	csB := sc.add("b", b)
+	sc.release(sc.take("a"), nil)
+	sc.release(sc.add("c", c), nil)
+
+	if sc.len() != 2 {
+		t.Errorf("len() = %d, want 2", sc.len())
+	}
+	if b.closed {
+		t.Error("expected the statement in use to stay open until released")
+	}
+	if sc.take("b") != nil {
+		t.Error("expected the least recently used statement to be evicted")
+	}
+	sc.release(csB, nil)
+	if !b.closed {
+		t.Error("expected the evicted statement to be closed on release")
+	}
+	if a.closed || c.closed {
+		t.Error("expected recently used statements to stay open")
+	}
+}
+
+func TestStmtCache_Invalidation(t *testing.T) {
+	sc := newStmtCache(4)
+	failed, idle, busy := &Stmt{}, &Stmt{}, &Stmt{}
+	sc.release(sc.add("idle", idle), nil)
+	csBusy := sc.add("busy", busy)
+
+	sc.release(sc.add("failed", failed), nil)
+	sc.release(sc.take("failed"), &Error{SQLState: "42000"})
+	if !failed.closed || sc.take("failed") != nil {
+		t.Error("expected a failed statement to be closed and dropped")
+	}
+	if idle.closed {
+		t.Error("expected other statements to survive a statement error")
+	}
+
+	sc.release(csBusy, &Error{SQLState: SQLStateConnectionError})
+	if !idle.closed || !busy.closed || sc.len() != 0 {
+		t.Errorf("expected a connection error to close every statement, len() = %d", sc.len())
+	}
+}
+
+func TestConn_PrepareCached_SkipsTaggedQueries(t *testing.T) {
+	c := &Conn{stmtCache: newStmtCache(4), correlationComments: true}
+	ctx := WithCorrelationID(context.Background(), "req-1")
+	if c.tagQuery(ctx, "SELECT ?") == "SELECT ?" {
+		t.Fatal("expected the query to be tagged")
+	}
+	c.closed = true
+	if _, _, err := c.prepareCached(ctx, "SELECT ?"); err != driver.ErrBadConn {
+		t.Errorf("prepareCached() on closed connection = %v, want ErrBadConn", err)
+	}
+	if c.stmtCache.len() != 0 {
+		t.Error("expected nothing cached")
+	}
+}
+
+// =============================================================================
+// ODBC Signature Tests (odbc.go)
+// =============================================================================
+
+// odbcPrototypes are the parameter types of the registered ODBC functions as
+// declared in sql.h, sqlext.h and sqlucode.h; every function returns SQLRETURN
+var odbcPrototypes = map[string][]string{
+	"SQLAllocHandle":    {"SQLSMALLINT", "SQLHANDLE", "SQLHANDLE*"},
+	"SQLFreeHandle":     {"SQLSMALLINT", "SQLHANDLE"},
+	"SQLSetEnvAttr":     {"SQLHENV", "SQLINTEGER", "SQLPOINTER", "SQLINTEGER"},
+	"SQLGetEnvAttr":     {"SQLHENV", "SQLINTEGER", "SQLPOINTER", "SQLINTEGER", "SQLINTEGER*"},
+	"SQLDriverConnect":  {"SQLHDBC", "SQLHWND", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLSMALLINT*", "SQLUSMALLINT"},
+	"SQLGetInfo":        {"SQLHDBC", "SQLUSMALLINT", "SQLPOINTER", "SQLSMALLINT", "SQLSMALLINT*"},
+	"SQLDisconnect":     {"SQLHDBC"},
+	"SQLSetConnectAttr": {"SQLHDBC", "SQLINTEGER", "SQLPOINTER", "SQLINTEGER"},
+	"SQLGetConnectAttr": {"SQLHDBC", "SQLINTEGER", "SQLPOINTER", "SQLINTEGER", "SQLINTEGER*"},
+	"SQLExecDirect":     {"SQLHSTMT", "SQLCHAR*", "SQLINTEGER"},
+	"SQLPrepare":        {"SQLHSTMT", "SQLCHAR*", "SQLINTEGER"},
+	"SQLDescribeCol":    {"SQLHSTMT", "SQLUSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLSMALLINT*", "SQLSMALLINT*", "SQLULEN*", "SQLSMALLINT*", "SQLSMALLINT*"},
+	"SQLColAttribute":   {"SQLHSTMT", "SQLUSMALLINT", "SQLUSMALLINT", "SQLPOINTER", "SQLSMALLINT", "SQLSMALLINT*", "SQLLEN*"},
+	"SQLGetDiagRec":     {"SQLSMALLINT", "SQLHANDLE", "SQLSMALLINT", "SQLCHAR*", "SQLINTEGER*", "SQLCHAR*", "SQLSMALLINT", "SQLSMALLINT*"},
+	"SQLTables":         {"SQLHSTMT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT"},
+	"SQLColumns":        {"SQLHSTMT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT"},
+	"SQLPrimaryKeys":    {"SQLHSTMT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT"},
+	"SQLStatistics":     {"SQLHSTMT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLUSMALLINT", "SQLUSMALLINT"},
+	"SQLForeignKeys":    {"SQLHSTMT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLCHAR*", "SQLSMALLINT"},
+	"SQLGetTypeInfo":    {"SQLHSTMT", "SQLSMALLINT"},
+	"SQLSetCursorName":  {"SQLHSTMT", "SQLCHAR*", "SQLSMALLINT"},
+	"SQLGetCursorName":  {"SQLHSTMT", "SQLCHAR*", "SQLSMALLINT", "SQLSMALLINT*"},
+	"SQLExecute":        {"SQLHSTMT"},
+	"SQLNumResultCols":  {"SQLHSTMT", "SQLSMALLINT*"},
+	"SQLBindCol":        {"SQLHSTMT", "SQLUSMALLINT", "SQLSMALLINT", "SQLPOINTER", "SQLLEN", "SQLLEN*"},
+	"SQLBindParameter":  {"SQLHSTMT", "SQLUSMALLINT", "SQLSMALLINT", "SQLSMALLINT", "SQLSMALLINT", "SQLULEN", "SQLSMALLINT", "SQLPOINTER", "SQLLEN", "SQLLEN*"},
+	"SQLParamData":      {"SQLHSTMT", "SQLPOINTER*"},
+	"SQLPutData":        {"SQLHSTMT", "SQLPOINTER", "SQLLEN"},
+	"SQLFetch":          {"SQLHSTMT"},
+	"SQLFetchScroll":    {"SQLHSTMT", "SQLSMALLINT", "SQLLEN"},
+	"SQLGetData":        {"SQLHSTMT", "SQLUSMALLINT", "SQLSMALLINT", "SQLPOINTER", "SQLLEN", "SQLLEN*"},
+	"SQLRowCount":       {"SQLHSTMT", "SQLLEN*"},
+	"SQLNumParams":      {"SQLHSTMT", "SQLSMALLINT*"},
+	"SQLDescribeParam":  {"SQLHSTMT", "SQLUSMALLINT", "SQLSMALLINT*", "SQLULEN*", "SQLSMALLINT*", "SQLSMALLINT*"},
+	"SQLGetDiagField":   {"SQLSMALLINT", "SQLHANDLE", "SQLSMALLINT", "SQLSMALLINT", "SQLPOINTER", "SQLSMALLINT", "SQLSMALLINT*"},
+	"SQLEndTran":        {"SQLSMALLINT", "SQLHANDLE", "SQLSMALLINT"},
+	"SQLCloseCursor":    {"SQLHSTMT"},
+	"SQLCancel":         {"SQLHSTMT"},
+	"SQLFreeStmt":       {"SQLHSTMT", "SQLUSMALLINT"},
+	"SQLMoreResults":    {"SQLHSTMT"},
+	"SQLSetStmtAttr":    {"SQLHSTMT", "SQLINTEGER", "SQLPOINTER", "SQLINTEGER"},
+	"SQLGetStmtAttr":    {"SQLHSTMT", "SQLINTEGER", "SQLPOINTER", "SQLINTEGER", "SQLINTEGER*"},
+	"SQLSetDescField":   {"SQLHDESC", "SQLSMALLINT", "SQLSMALLINT", "SQLPOINTER", "SQLINTEGER"},
+	"SQLDriverConnectW": {"SQLHDBC", "SQLHWND", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLSMALLINT*", "SQLUSMALLINT"},
+	"SQLExecDirectW":    {"SQLHSTMT", "SQLWCHAR*", "SQLINTEGER"},
+	"SQLPrepareW":       {"SQLHSTMT", "SQLWCHAR*", "SQLINTEGER"},
+	"SQLDescribeColW":   {"SQLHSTMT", "SQLUSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLSMALLINT*", "SQLSMALLINT*", "SQLULEN*", "SQLSMALLINT*", "SQLSMALLINT*"},
+	"SQLGetInfoW":       {"SQLHDBC", "SQLUSMALLINT", "SQLPOINTER", "SQLSMALLINT", "SQLSMALLINT*"},
+	"SQLColAttributeW":  {"SQLHSTMT", "SQLUSMALLINT", "SQLUSMALLINT", "SQLPOINTER", "SQLSMALLINT", "SQLSMALLINT*", "SQLLEN*"},
+	"SQLGetDiagRecW":    {"SQLSMALLINT", "SQLHANDLE", "SQLSMALLINT", "SQLWCHAR*", "SQLINTEGER*", "SQLWCHAR*", "SQLSMALLINT", "SQLSMALLINT*"},
+	"SQLTablesW":        {"SQLHSTMT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT"},
+	"SQLColumnsW":       {"SQLHSTMT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT"},
+	"SQLPrimaryKeysW":   {"SQLHSTMT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT"},
+	"SQLStatisticsW":    {"SQLHSTMT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLUSMALLINT", "SQLUSMALLINT"},
+	"SQLForeignKeysW":   {"SQLHSTMT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLWCHAR*", "SQLSMALLINT"},
+	"SQLGetTypeInfoW":   {"SQLHSTMT", "SQLSMALLINT"},
+	"SQLSetCursorNameW": {"SQLHSTMT", "SQLWCHAR*", "SQLSMALLINT"},
+	"SQLGetCursorNameW": {"SQLHSTMT", "SQLWCHAR*", "SQLSMALLINT", "SQLSMALLINT*"},
+	"SQLDriversW":       {"SQLHENV", "SQLUSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLSMALLINT*", "SQLWCHAR*", "SQLSMALLINT", "SQLSMALLINT*"},
+	"SQLDataSourcesW":   {"SQLHENV", "SQLUSMALLINT", "SQLWCHAR*", "SQLSMALLINT", "SQLSMALLINT*", "SQLWCHAR*", "SQLSMALLINT", "SQLSMALLINT*"},
+	"SQLDrivers":        {"SQLHENV", "SQLUSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLSMALLINT*", "SQLCHAR*", "SQLSMALLINT", "SQLSMALLINT*"},
+	"SQLDataSources":    {"SQLHENV", "SQLUSMALLINT", "SQLCHAR*", "SQLSMALLINT", "SQLSMALLINT*", "SQLCHAR*", "SQLSMALLINT", "SQLSMALLINT*"},
+}
+
+// argLayout is how an argument is passed: its width in bytes and, for
+// pointers, the width of the pointed-to value (0 = untyped)
+type argLayout struct {
+	pointer bool
+	width   int
+	elem    int
+}
+
+// cArgLayout returns the layout of a C ODBC type for a SQLLEN width
+func cArgLayout(cType string, lenSize int) (argLayout, bool) {
+	ptrSize := int(unsafe.Sizeof(uintptr(0)))
+	if base, ok := strings.CutSuffix(cType, "*"); ok {
+		elem, ok := cArgLayout(base, lenSize)
+		if !ok {
+			return argLayout{}, false
+		}
+		if elem.pointer && base != "SQLPOINTER" && !strings.HasPrefix(base, "SQLH") {
+			return argLayout{}, false
+		}
+		return argLayout{pointer: true, width: ptrSize, elem: elem.width}, true
+	}
+	switch cType {
+	case "SQLCHAR", "SQLSCHAR":
+		return argLayout{width: 1}, true
+	case "SQLSMALLINT", "SQLUSMALLINT", "SQLRETURN", "SQLWCHAR":
+		if cType == "SQLWCHAR" && runtime.GOOS != "windows" {
+			// unixODBC defaults to UTF-16; iODBC uses wchar_t
+			return argLayout{width: 0}, true
+		}
+		return argLayout{width: 2}, true
+	case "SQLINTEGER", "SQLUINTEGER":
+		return argLayout{width: 4}, true
+	case "SQLLEN", "SQLULEN", "SQLSETPOSIROW":
+		return argLayout{width: lenSize}, true
+	case "SQLPOINTER", "SQLHANDLE", "SQLHENV", "SQLHDBC", "SQLHSTMT", "SQLHDESC", "SQLHWND":
+		return argLayout{pointer: true, width: ptrSize}, true
+	}
+	return argLayout{}, false
+}
+
+// goArgLayout returns the layout of a Go parameter type of a purego function
+func goArgLayout(t reflect.Type) argLayout {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return argLayout{pointer: true, width: int(t.Size()), elem: int(t.Elem().Size())}
+	case reflect.Uintptr, reflect.UnsafePointer:
+		return argLayout{pointer: true, width: int(t.Size())}
+	}
+	return argLayout{width: int(t.Size())}
+}
+
+// compatible reports whether a Go argument can be passed for a C argument.
+// Untyped pointers (uintptr, SQLPOINTER) match any pointer.
+func (g argLayout) compatible(c argLayout) bool {
+	if g.pointer != c.pointer || g.width != c.width {
+		return false
+	}
+	return g.elem == 0 || c.elem == 0 || g.elem == c.elem
+}
+
+func TestODBCSignatures(t *testing.T) {
+	type registered struct {
+		name string
+		fptr interface{}
+	}
+	var funcs []registered
+	for _, f := range odbcFuncs {
+		funcs = append(funcs, registered{f.name, f.fptr})
+	}
+	for _, f := range wideFuncs {
+		funcs = append(funcs, registered{f.name, f.fptr})
+	}
+	for _, f := range driverManagerFuncs {
+		funcs = append(funcs, registered{f.name, f.fptr})
+	}
+
+	for _, f := range funcs {
+		proto, ok := odbcPrototypes[f.name]
+		if !ok {
+			t.Errorf("%s: no prototype to check the signature against", f.name)
+			continue
+		}
+		fn := reflect.TypeOf(f.fptr).Elem()
+		if fn.NumIn() != len(proto) {
+			t.Errorf("%s: %d parameters, want %d", f.name, fn.NumIn(), len(proto))
+			continue
+		}
+		if fn.NumOut() != 1 || fn.Out(0).Size() != 2 {
+			t.Errorf("%s: must return SQLRETURN", f.name)
+		}
+		for i, cType := range proto {
+			want, ok := cArgLayout(cType, sqlLenSize)
+			if !ok {
+				t.Fatalf("%s: unknown C type %s", f.name, cType)
+			}
+			if got := goArgLayout(fn.In(i)); !got.compatible(want) {
+				t.Errorf("%s parameter %d: Go %v %+v does not match C %s %+v with %d-bit SQLLEN",
+					f.name, i+1, fn.In(i), got, cType, want, sqlLenSize*8)
+			}
+		}
+	}
+}
+
+func TestODBCPrototypes_LenModels(t *testing.T) {
+	// Every prototype must be describable under both SQLLEN models, and the
+	// functions taking SQLLEN arguments must change layout between them
+	lenDependent := map[string]bool{}
+	for name, proto := range odbcPrototypes {
+		for _, cType := range proto {
+			l32, ok32 := cArgLayout(cType, 4)
+			l64, ok64 := cArgLayout(cType, 8)
+			if !ok32 || !ok64 {
+				t.Errorf("%s: unknown C type %s", name, cType)
+			}
+			if l32 != l64 {
+				lenDependent[name] = true
+			}
+		}
+	}
+	for _, name := range []string{"SQLBindCol", "SQLBindParameter", "SQLGetData", "SQLRowCount", "SQLPutData", "SQLFetchScroll", "SQLDescribeCol", "SQLDescribeParam", "SQLColAttribute"} {
+		if !lenDependent[name] {
+			t.Errorf("%s: expected SQLLEN-dependent arguments", name)
+		}
+	}
+}
+
+// parseODBCPrototypes extracts the parameter types of the SQLRETURN
+// functions declared in an ODBC header
+func parseODBCPrototypes(header string) map[string][]string {
+	header = regexp.MustCompile(`(?s)/\*.*?\*/`).ReplaceAllString(header, " ")
+	header = regexp.MustCompile(`(?m)^\s*#.*$`).ReplaceAllString(header, " ")
+	decl := regexp.MustCompile(`SQLRETURN\s+(?:SQL_API\s+)?(SQL\w+)\s*\(([^;{]*?)\)\s*;`)
+
+	protos := make(map[string][]string)
+	for _, m := range decl.FindAllStringSubmatch(header, -1) {
+		name := m[1]
+		if _, ok := protos[name]; ok {
+			continue
+		}
+		var params []string
+		for _, param := range strings.Split(m[2], ",") {
+			stars := strings.Count(param, "*")
+			fields := strings.Fields(strings.ReplaceAll(param, "*", " "))
+			if len(fields) > 0 && fields[0] == "const" {
+				fields = fields[1:]
+			}
+			if len(fields) == 0 || fields[0] == "void" {
+				continue
+			}
+			params = append(params, fields[0]+strings.Repeat("*", stars))
+		}
+		protos[name] = params
+	}
+	return protos
+}
+
+func TestParseODBCPrototypes(t *testing.T) {
+	header := `
+#define ODBCVER 0x0380
+SQLRETURN  SQL_API SQLBindCol(SQLHSTMT StatementHandle,
+                              SQLUSMALLINT ColumnNumber, SQLSMALLINT TargetType,
+                              SQLPOINTER TargetValue, SQLLEN BufferLength,
+                              SQLLEN *StrLen_or_Ind);
+SQLRETURN  SQL_API SQLColAttribute(SQLHSTMT StatementHandle,
+                                   SQLUSMALLINT ColumnNumber, SQLUSMALLINT FieldIdentifier,
+                                   SQLPOINTER CharacterAttribute, SQLSMALLINT BufferLength,
+                                   SQLSMALLINT *StringLength, SQLLEN *NumericAttribute
+                                   /* spec says (SQLPOINTER) not (SQLEN*) - PAH */ );
+SQLRETURN SQL_API SQLParamData(SQLHSTMT hstmt, SQLPOINTER *prgbValue);
+`
+	got := parseODBCPrototypes(header)
+	want := map[string][]string{
+		"SQLBindCol":      {"SQLHSTMT", "SQLUSMALLINT", "SQLSMALLINT", "SQLPOINTER", "SQLLEN", "SQLLEN*"},
+		"SQLColAttribute": {"SQLHSTMT", "SQLUSMALLINT", "SQLUSMALLINT", "SQLPOINTER", "SQLSMALLINT", "SQLSMALLINT*", "SQLLEN*"},
+		"SQLParamData":    {"SQLHSTMT", "SQLPOINTER*"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseODBCPrototypes() = %v, want %v", got, want)
+	}
+}
+
+// TestODBCPrototypes_Headers checks odbcPrototypes against the installed ODBC
+// headers under both SQLLEN models. Set GODBC_ODBC_INCLUDE to the directory
+// holding sql.h, sqlext.h and sqlucode.h (e.g. /usr/include with unixodbc-dev).
+func TestODBCPrototypes_Headers(t *testing.T) {
+	dir := os.Getenv("GODBC_ODBC_INCLUDE")
+	if dir == "" {
+		t.Skip("GODBC_ODBC_INCLUDE not set")
+	}
+	headers := make(map[string][]string)
+	for _, file := range []string{"sql.h", "sqlext.h", "sqlucode.h"} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m := regexp.MustCompile(`#define\s+ODBCVER\s+(0x[0-9a-fA-F]+)`).FindSubmatch(data); m != nil {
+			t.Logf("%s: ODBCVER %s", file, m[1])
+		}
+		for name, params := range parseODBCPrototypes(string(data)) {
+			if _, ok := headers[name]; !ok {
+				headers[name] = params
+			}
+		}
+	}
+
+	for name, proto := range odbcPrototypes {
+		declared, ok := headers[name]
+		if !ok {
+			t.Errorf("%s: not declared in %s", name, dir)
+			continue
+		}
+		if len(declared) != len(proto) {
+			t.Errorf("%s: %d parameters, headers declare %d", name, len(proto), len(declared))
+			continue
+		}
+		for _, lenSize := range []int{4, 8} {
+			for i := range proto {
+				want, ok := cArgLayout(declared[i], lenSize)
+				if !ok {
+					t.Errorf("%s parameter %d: unknown header type %s", name, i+1, declared[i])
+					continue
+				}
+				if got, _ := cArgLayout(proto[i], lenSize); got != want {
+					t.Errorf("%s parameter %d: %s, headers declare %s (%d-bit SQLLEN)", name, i+1, proto[i], declared[i], lenSize*8)
+				}
+			}
+		}
+	}
+}
+
+// =============================================================================
+// Diagnostic Limit Tests (diaglimits.go)
+// =============================================================================
+
+// fakeDiagRecords replaces SQLGetDiagRec and SQLGetDiagField with a handle
+// holding n records with the given message. SQL_DIAG_NUMBER is reported
+// unless hideCount is set.
+func fakeDiagRecords(t *testing.T, n int, msg string, hideCount bool) *int {
+	t.Helper()
+	getRec, getField := sqlGetDiagRec, sqlGetDiagField
+	limits := diagLimits()
+	t.Cleanup(func() {
+		sqlGetDiagRec, sqlGetDiagField = getRec, getField
+		SetDiagLimits(limits)
+	})
+
+	calls := 0
+	sqlGetDiagRec = func(handleType SQLSMALLINT, handle SQLHANDLE, recNum SQLSMALLINT, sqlState *byte, nativeError *SQLINTEGER, msgText *byte, bufferLen SQLSMALLINT, textLen *SQLSMALLINT) SQLRETURN {
+		calls++
+		if int(recNum) > n {
+			return SQL_NO_DATA
+		}
+		copy(unsafe.Slice(sqlState, 6), "01000\x00")
+		*nativeError = SQLINTEGER(recNum)
+		out := unsafe.Slice(msgText, int(bufferLen))
+		copy(out[:len(out)-1], msg)
+		out[min(len(msg), len(out)-1)] = 0
+		*textLen = SQLSMALLINT(len(msg))
+		if len(msg) >= len(out) {
+			return SQL_SUCCESS_WITH_INFO
+		}
+		return SQL_SUCCESS
+	}
+	sqlGetDiagField = func(handleType SQLSMALLINT, handle SQLHANDLE, recNum SQLSMALLINT, diagId SQLSMALLINT, diagInfo uintptr, bufferLen SQLSMALLINT, stringLen *SQLSMALLINT) SQLRETURN {
+		if hideCount || recNum != 0 || diagId != SQL_DIAG_NUMBER {
+			return SQL_ERROR
+		}
+		*(*SQLINTEGER)(unsafe.Add(nil, diagInfo)) = SQLINTEGER(n)
+		return SQL_SUCCESS
+	}
+	return &calls
+}
+
+func TestSetDiagLimits(t *testing.T) {
+	limits := diagLimits()
+	defer SetDiagLimits(limits)
+
+	SetDiagLimits(DiagLimits{MaxRecords: 5})
+	if got := diagLimits(); got.MaxRecords != 5 || got.MaxMessageLen != DefaultMaxDiagMessageLen {
+		t.Errorf("diagLimits() = %+v, want 5 records and the default message length", got)
+	}
+	SetDiagLimits(DiagLimits{MaxRecords: 1 << 20, MaxMessageLen: 1 << 20})
+	if got := diagLimits(); got.MaxRecords >= math.MaxInt16 || got.MaxMessageLen >= math.MaxInt16 {
+		t.Errorf("diagLimits() = %+v, want limits that fit a SQLSMALLINT", got)
+	}
+}
+
+func TestGetDiagRecords_CapsRecords(t *testing.T) {
+	calls := fakeDiagRecords(t, 245, "warning", false)
+	SetDiagLimits(DiagLimits{MaxRecords: 32})
+
+	records := GetDiagRecords(SQL_HANDLE_STMT, 1)
+	if len(records) != 32 {
+		t.Fatalf("got %d records, want 32", len(records))
+	}
+	if *calls != 32 {
+		t.Errorf("SQLGetDiagRec called %d times, want 32", *calls)
+	}
+	if got := records[31].Message; got != "warning (and 213 more)" {
+		t.Errorf("last message = %q, want the number of records left out", got)
+	}
+	if records[0].Message != "warning" || records[0].SQLState != "01000" || records[0].NativeError != 1 {
+		t.Errorf("first record = %+v", records[0])
+	}
+}
+
+func TestGetDiagRecords_UnknownCount(t *testing.T) {
+	fakeDiagRecords(t, 10, "warning", true)
+	SetDiagLimits(DiagLimits{MaxRecords: 3})
+	records := GetDiagRecords(SQL_HANDLE_STMT, 1)
+	if got := records[len(records)-1].Message; got != "warning (and more)" {
+		t.Errorf("last message = %q, want %q", got, "warning (and more)")
+	}
+
+	fakeDiagRecords(t, 3, "warning", true)
+	SetDiagLimits(DiagLimits{MaxRecords: 3})
+	records = GetDiagRecords(SQL_HANDLE_STMT, 1)
+	if got := records[len(records)-1].Message; got != "warning" {
+		t.Errorf("last message = %q, want no summary when nothing was left out", got)
+	}
+}
+
+func TestGetDiagRecords_TruncatesMessages(t *testing.T) {
+	fakeDiagRecords(t, 1, "naïve failure", false)
+	SetDiagLimits(DiagLimits{MaxMessageLen: 3})
+	records := GetDiagRecords(SQL_HANDLE_STMT, 1)
+	if len(records) != 1 || records[0].Message != "na..." {
+		t.Errorf("records = %+v, want the message cut before the split character", records)
+	}
+}
+
+func TestDiagMessage(t *testing.T) {
+	buf := append([]byte("hello"), 0)
+	if got := diagMessage(buf, 5); got != "hello" {
+		t.Errorf("diagMessage() = %q, want %q", got, "hello")
+	}
+	if got := diagMessage(buf, 12); got != "hello..." {
+		t.Errorf("diagMessage() = %q, want %q", got, "hello...")
+	}
+	if got := diagMessage(buf, -1); got != "" {
+		t.Errorf("diagMessage() = %q, want empty", got)
+	}
+}
+
+// =============================================================================
+// Circuit Breaker Tests (circuit.go)
+// =============================================================================
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var transitions []string
+	cb := NewCircuitBreaker(3, time.Second)
+	cb.now = func() time.Time { return now }
+	cb.OnStateChange = func(from, to CircuitState) { transitions = append(transitions, from.String()+"->"+to.String()) }
+	loginErr := &Error{SQLState: "08001", Message: "server not reachable"}
+
+	for i := 0; i < 3; i++ {
+		if err := cb.allow(); err != nil {
+			t.Fatalf("connect %d: allow() = %v, want nil", i+1, err)
+		}
+		cb.done(loginErr, false)
+	}
+	if cb.State() != CircuitOpen {
+		t.Fatalf("State() = %v, want open", cb.State())
+	}
+
+	err := cb.allow()
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() = %v, want a *CircuitOpenError", err)
+	}
+	if openErr.Failures != 3 || !openErr.RetryAt.Equal(now.Add(time.Second)) || openErr.Err != loginErr {
+		t.Errorf("CircuitOpenError = %+v", openErr)
+	}
+	if !strings.Contains(err.Error(), "server not reachable") {
+		t.Errorf("Error() = %q, want the last connect error", err.Error())
+	}
+
+	// After the cooldown one probe is let through; a successful probe closes the circuit
+	now = now.Add(time.Second)
+	if err := cb.allow(); err != nil {
+		t.Fatalf("probe: allow() = %v, want nil", err)
+	}
+	if err := cb.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second connect during the probe: allow() = %v, want ErrCircuitOpen", err)
+	}
+	cb.done(nil, false)
+	if cb.State() != CircuitClosed || cb.allow() != nil {
+		t.Errorf("State() = %v, want closed after a successful probe", cb.State())
+	}
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Errorf("transitions = %v, want %v", transitions, want)
+	}
+}
+
+func TestCircuitBreaker_FailedProbeBacksOff(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreaker(1, time.Second)
+	cb.MaxCooldown = 3 * time.Second
+	cb.now = func() time.Time { return now }
+	loginErr := errors.New("login timeout")
+
+	cb.allow()
+	cb.done(loginErr, false)
+	for _, wantCooldown := range []time.Duration{2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		now = cb.retryAt
+		if err := cb.allow(); err != nil {
+			t.Fatalf("probe: allow() = %v, want nil", err)
+		}
+		cb.done(loginErr, false)
+		if cb.State() != CircuitOpen || cb.retryAt.Sub(now) != wantCooldown {
+			t.Errorf("after a failed probe: state %v, cooldown %v, want open for %v", cb.State(), cb.retryAt.Sub(now), wantCooldown)
+		}
+	}
+}
+
+func TestCircuitBreaker_IgnoresAbandonedConnects(t *testing.T) {
+	cb := NewCircuitBreaker(1, time.Hour)
+	cb.allow()
+	cb.done(context.Canceled, true)
+	if cb.State() != CircuitClosed {
+		t.Errorf("State() = %v, want closed after a cancelled connect", cb.State())
+	}
+
+	cb.allow()
+	cb.done(errors.New("refused"), false)
+	cb.retryAt = time.Now()
+	if err := cb.allow(); err != nil {
+		t.Fatalf("probe: allow() = %v", err)
+	}
+	cb.done(context.DeadlineExceeded, true)
+	if err := cb.allow(); err != nil {
+		t.Errorf("allow() after an abandoned probe = %v, want another probe", err)
+	}
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	cb := NewCircuitBreaker(5, time.Second)
+	c := &Connector{}
+	WithCircuitBreaker(cb)(c)
+	if c.CircuitBreaker != cb {
+		t.Error("expected the circuit breaker to be set")
+	}
+
+	cb.state, cb.retryAt = CircuitOpen, time.Now().Add(time.Hour)
+	if _, err := c.Connect(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Connect() = %v, want ErrCircuitOpen without connecting", err)
+	}
+}
+
+// =============================================================================
+// Retry Tests (retry.go)
+// =============================================================================
+
+// noSleep makes a Retryer record its waits instead of sleeping
+func noSleep(r *Retryer) *[]time.Duration {
+	var waits []time.Duration
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return &waits
+}
+
+func TestRetryer_Do(t *testing.T) {
+	r := NewRetryer(4, 100*time.Millisecond)
+	r.Jitter = 0
+	waits := noSleep(r)
+	var notified []int
+	r.OnRetry = func(attempt int, err error, wait time.Duration) { notified = append(notified, attempt) }
+
+	calls := 0
+	err := r.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return &Error{SQLState: SQLStateDeadlock, Message: "deadlock victim"}
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("Do() = %v after %d calls, want nil after 3", err, calls)
+	}
+	if want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}; !reflect.DeepEqual(*waits, want) {
+		t.Errorf("waits = %v, want %v", *waits, want)
+	}
+	if !reflect.DeepEqual(notified, []int{1, 2}) {
+		t.Errorf("OnRetry attempts = %v, want [1 2]", notified)
+	}
+
+	// The last error is returned once the attempts are used up
+	calls = 0
+	timeout := &Error{SQLState: SQLStateTimeout, Message: "timeout expired"}
+	if err := r.Do(context.Background(), func() error { calls++; return timeout }); err != timeout || calls != 4 {
+		t.Errorf("Do() = %v after %d calls, want the timeout after 4", err, calls)
+	}
+
+	// Errors that are not transient are returned at once
+	calls = 0
+	syntax := &Error{SQLState: SQLStateSyntaxError, Message: "syntax error"}
+	if err := r.Do(context.Background(), func() error { calls++; return syntax }); err != syntax || calls != 1 {
+		t.Errorf("Do() = %v after %d calls, want the syntax error after 1", err, calls)
+	}
+}
+
+func TestRetryer_RetryOn(t *testing.T) {
+	r := NewRetryer(3, 0)
+	noSleep(r)
+	errBusy := errors.New("busy")
+	r.RetryOn = func(err error) bool { return errors.Is(err, errBusy) }
+
+	calls := 0
+	r.Do(context.Background(), func() error { calls++; return errBusy })
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3 for an error RetryOn accepts", calls)
+	}
+	calls = 0
+	r.Do(context.Background(), func() error { calls++; return &Error{SQLState: SQLStateDeadlock} })
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 for an error RetryOn rejects", calls)
+	}
+}
+
+func TestRetryer_Wait(t *testing.T) {
+	r := &Retryer{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := r.wait(attempt + 1); got != want {
+			t.Errorf("wait(%d) = %v, want %v", attempt+1, got, want)
+		}
+	}
+	if got := (&Retryer{Backoff: time.Second}).wait(100); got <= 0 {
+		t.Errorf("uncapped wait(100) = %v, want a positive wait", got)
+	}
+
+	r.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := r.wait(1); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("wait(1) with 50%% jitter = %v, want within [500ms, 1.5s]", got)
+		}
+	}
+}
+
+func TestRetryer_ContextDone(t *testing.T) {
+	r := NewRetryer(5, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	timeout := &Error{SQLState: SQLStateTimeout}
+	calls := 0
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := r.Do(ctx, func() error { calls++; return timeout }); err != timeout || calls != 1 {
+		t.Errorf("Do() = %v after %d calls, want the error of the attempt before cancellation", err, calls)
+	}
+
+	calls = 0
+	if err := r.Do(ctx, func() error { calls++; return timeout }); err != timeout || calls != 1 {
+		t.Errorf("Do() with a done context = %v after %d calls, want no retry", err, calls)
+	}
+}
+
+func TestConn_RetriesQuery(t *testing.T) {
+	c := &Conn{retryer: NewRetryer(3, 0)}
+	tests := []struct {
+		query string
+		args  []driver.NamedValue
+		inTx  bool
+		want  bool
+	}{
+		{"SELECT * FROM t", nil, false, true},
+		{"  (VALUES (1))", nil, false, true},
+		{"SELECT * FROM t WHERE id = ?", []driver.NamedValue{{Ordinal: 1, Value: 1}}, false, true},
+		{"SELECT * FROM t", nil, true, false},
+		{"UPDATE t SET x = 1", nil, false, false},
+		{"SELECT * INTO t2 FROM t", nil, false, false},
+		{"SELECT ?", []driver.NamedValue{{Ordinal: 1, Value: strings.NewReader("x")}}, false, false},
+	}
+	for _, tt := range tests {
+		c.inTx = tt.inTx
+		if got := c.retriesQuery(tt.query, tt.args); got != tt.want {
+			t.Errorf("retriesQuery(%q, inTx=%v) = %v, want %v", tt.query, tt.inTx, got, tt.want)
+		}
+	}
+	if (&Conn{}).retriesQuery("SELECT 1", nil) {
+		t.Error("retriesQuery() without a retryer = true, want false")
+	}
+}
+
+func TestConn_RetryQuery(t *testing.T) {
+	c := &Conn{retryer: NewRetryer(3, 0)}
+	noSleep(c.retryer)
+
+	calls := 0
+	want := &Rows{}
+	rows, err := c.retryQuery(context.Background(), func() (driver.Rows, error) {
+		calls++
+		if calls == 1 {
+			return nil, &Error{SQLState: SQLStateTimeout}
+		}
+		return want, nil
+	})
+	if err != nil || rows != want || calls != 2 {
+		t.Errorf("retryQuery() = %v, %v after %d calls, want the rows after 2", rows, err, calls)
+	}
+
+	// A failed connection cannot run the query again
+	calls = 0
+	linkErr := &Error{SQLState: "08S01", Message: "communication link failure"}
+	if _, err := c.retryQuery(context.Background(), func() (driver.Rows, error) { calls++; return nil, linkErr }); err != linkErr || calls != 1 {
+		t.Errorf("retryQuery() = %v after %d calls, want the link failure after 1", err, calls)
+	}
+}
+
+func TestWithRetryer(t *testing.T) {
+	r := NewRetryer(3, time.Millisecond)
+	noSleep(r)
+	r.RetryOn = func(err error) bool { return errors.Is(err, ErrCircuitOpen) }
+	attempts := 0
+	r.OnRetry = func(attempt int, err error, wait time.Duration) { attempts = attempt }
+
+	c := &Connector{}
+	WithRetryer(r)(c)
+	WithCircuitBreaker(NewCircuitBreaker(1, time.Hour))(c)
+	if c.Retryer != r {
+		t.Fatal("expected the retryer to be set")
+	}
+
+	// An open circuit fails each connect without touching the driver
+	c.CircuitBreaker.state, c.CircuitBreaker.retryAt = CircuitOpen, time.Now().Add(time.Hour)
+	if _, err := c.Connect(context.Background()); !errors.Is(err, ErrCircuitOpen) || attempts != 2 {
+		t.Errorf("Connect() = %v after %d retries, want ErrCircuitOpen after 2", err, attempts)
+	}
+}
+
+// =============================================================================
+// Time Zone Tests (timezone.go)
+// =============================================================================
+
+func TestSSDateTimeStructLayout(t *testing.T) {
+	// Sizes from msodbcsql.h
+	if size := unsafe.Sizeof(SQL_SS_TIME2_STRUCT{}); size != 12 {
+		t.Errorf("expected SQL_SS_TIME2_STRUCT to be 12 bytes, got %d", size)
+	}
+	if size := unsafe.Sizeof(SQL_SS_TIMESTAMPOFFSET_STRUCT{}); size != 20 {
+		t.Errorf("expected SQL_SS_TIMESTAMPOFFSET_STRUCT to be 20 bytes, got %d", size)
+	}
+}
+
+func TestOffsetTimeValue(t *testing.T) {
+	got, ok := offsetTimeValue(2024, 3, 9, 8, 30, 0, 1234500, -5, -30, OutOfRangeTimeValue).(time.Time)
+	if !ok {
+		t.Fatalf("expected time.Time, got %T", got)
+	}
+	if _, offset := got.Zone(); offset != -(5*3600 + 30*60) {
+		t.Errorf("expected offset -05:30, got %d seconds", offset)
+	}
+	want := time.Date(2024, 3, 9, 14, 0, 0, 1234500, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got.Hour() != 8 || got.Nanosecond() != 1234500 {
+		t.Errorf("expected local fields to be kept, got %v", got)
+	}
+
+	if _, ok := offsetTimeValue(10000, 1, 1, 0, 0, 0, 0, 2, 0, OutOfRangeTimeValue).(OutOfRangeTime); !ok {
+		t.Error("expected OutOfRangeTime for a year after 9999")
+	}
+}
+
+func TestParseTimeWithZone(t *testing.T) {
+	tests := []struct {
+		input      string
+		timeOnly   bool
+		want       time.Time
+		wantOffset int
+	}{
+		{"2024-03-09 08:30:00.123456+02", false, time.Date(2024, 3, 9, 6, 30, 0, 123456000, time.UTC), 7200},
+		{"2024-03-09 08:30:00 +05:30", false, time.Date(2024, 3, 9, 3, 0, 0, 0, time.UTC), 19800},
+		{"2024-03-09T08:30:00Z", false, time.Date(2024, 3, 9, 8, 30, 0, 0, time.UTC), 0},
+		{"2024-03-09 08:30:00.5-0700", false, time.Date(2024, 3, 9, 15, 30, 0, 500000000, time.UTC), -25200},
+		{"08:30:00+02", true, time.Date(0, 1, 1, 6, 30, 0, 0, time.UTC), 7200},
+	}
+	for _, tt := range tests {
+		got, ok := parseTimeWithZone(tt.input, tt.timeOnly)
+		if !ok {
+			t.Errorf("parseTimeWithZone(%q) failed", tt.input)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTimeWithZone(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if _, offset := got.Zone(); offset != tt.wantOffset {
+			t.Errorf("parseTimeWithZone(%q) offset = %d, want %d", tt.input, offset, tt.wantOffset)
+		}
+	}
+
+	if _, ok := parseTimeWithZone("infinity", false); ok {
+		t.Error("expected infinity not to parse")
+	}
+}
+
+func TestRows_BlockValue_SSDateTime(t *testing.T) {
+	times := blockColumn{cType: SQL_C_SS_TIME2, elemSize: 12, data: make([]byte, 12), ind: []SQLLEN{12}}
+	*(*SQL_SS_TIME2_STRUCT)(unsafe.Pointer(&times.data[0])) = SQL_SS_TIME2_STRUCT{Hour: 13, Minute: 5, Second: 7, Fraction: 1234567}
+
+	offsets := blockColumn{cType: SQL_C_SS_TIMESTAMPOFFSET, elemSize: 20, data: make([]byte, 20), ind: []SQLLEN{20}}
+	*(*SQL_SS_TIMESTAMPOFFSET_STRUCT)(unsafe.Pointer(&offsets.data[0])) = SQL_SS_TIMESTAMPOFFSET_STRUCT{
+		Year: 2024, Month: 3, Day: 9, Hour: 8, TimezoneHour: 2,
+	}
+
+	r := &Rows{columns: []string{"t", "ts"}, block: &blockFetch{columns: []blockColumn{times, offsets}}}
+	if v, err := r.blockValue(0); err != nil || v != time.Date(0, 1, 1, 13, 5, 7, 1234567, time.UTC) {
+		t.Errorf("expected 13:05:07.001234567, got %v (%v)", v, err)
+	}
+	v, err := r.blockValue(1)
+	ts, ok := v.(time.Time)
+	if err != nil || !ok || !ts.Equal(time.Date(2024, 3, 9, 6, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected 2024-03-09 08:00 +02:00, got %v (%v)", v, err)
+	}
+	if _, offset := ts.Zone(); offset != 7200 {
+		t.Errorf("expected offset +02:00, got %d seconds", offset)
+	}
+}
+
+func TestTimeZoneTypes_ScanTypeAndName(t *testing.T) {
+	types := []SQLSMALLINT{SQL_SS_TIMESTAMPOFFSET, SQL_TYPE_TIME_WITH_TIMEZONE, SQL_TYPE_TIMESTAMP_WITH_TIMEZONE}
+	r := &Rows{colTypes: types, nativeTypes: make([]string, len(types))}
+	for i := range types {
+		if got := r.ColumnTypeScanType(i); got != reflect.TypeOf(time.Time{}) {
+			t.Errorf("column %d: expected time.Time, got %v", i, got)
+		}
+	}
+	if got := r.ColumnTypeDatabaseTypeName(2); got != "TIMESTAMP WITH TIME ZONE" {
+		t.Errorf("expected TIMESTAMP WITH TIME ZONE, got %q", got)
+	}
+	if got := SQLTypeName(SQL_TYPE_TIME_WITH_TIMEZONE); got != "TIME WITH TIME ZONE" {
+		t.Errorf("expected TIME WITH TIME ZONE, got %q", got)
+	}
+}
+
+// =============================================================================
+// Multi-Statement Batch Tests (multi.go)
+// =============================================================================
+
+// fakeBatch makes SQLMoreResults return rets in turn and SQLRowCount return counts in turn
+func fakeBatch(t *testing.T, rets []SQLRETURN, counts []SQLLEN) {
+	t.Helper()
+	origMore, origCount := sqlMoreResults, sqlRowCount
+	t.Cleanup(func() { sqlMoreResults, sqlRowCount = origMore, origCount })
+	sqlMoreResults = func(SQLHSTMT) SQLRETURN {
+		ret := rets[0]
+		rets = rets[1:]
+		return ret
+	}
+	sqlRowCount = func(_ SQLHSTMT, rowCount *SQLLEN) SQLRETURN {
+		*rowCount = counts[0]
+		counts = counts[1:]
+		return SQL_SUCCESS
+	}
+}
+
+func TestConn_BatchResults(t *testing.T) {
+	fakeDiagRecords(t, 1, "Changed database context", false)
+	fakeBatch(t, []SQLRETURN{SQL_SUCCESS_WITH_INFO, SQL_SUCCESS, SQL_NO_DATA}, []SQLLEN{2, 3, -1})
+
+	results, err := (&Conn{}).batchResults(context.Background(), 1, SQL_SUCCESS)
+	if err != nil {
+		t.Fatalf("batchResults failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", results)
+	}
+	for i, want := range []int64{2, 3, -1} {
+		if results[i].RowsAffected != want || results[i].Err != nil {
+			t.Errorf("result %d: expected %d rows, got %+v", i, want, results[i])
+		}
+	}
+	if len(results[0].Diagnostics) != 0 || len(results[1].Diagnostics) != 1 {
+		t.Errorf("expected diagnostics only on the second statement, got %+v", results)
+	}
+}
+
+func TestConn_BatchResults_StatementError(t *testing.T) {
+	fakeDiagRecords(t, 1, "Violation of PRIMARY KEY constraint", false)
+	fakeBatch(t, []SQLRETURN{SQL_ERROR, SQL_SUCCESS, SQL_NO_DATA}, []SQLLEN{1, 5})
+
+	results, err := (&Conn{}).batchResults(context.Background(), 1, SQL_SUCCESS)
+	if err == nil || !strings.Contains(err.Error(), "PRIMARY KEY") {
+		t.Fatalf("expected the statement error, got %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", results)
+	}
+	if results[0].RowsAffected != 1 || results[1].Err != err || results[1].RowsAffected != -1 || results[2].RowsAffected != 5 {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func TestRowCounts(t *testing.T) {
+	fakeBatch(t, []SQLRETURN{SQL_SUCCESS, SQL_SUCCESS_WITH_INFO, SQL_NO_DATA}, []SQLLEN{3, -1})
+	if got := rowCounts(1, 2); !reflect.DeepEqual(got, []int64{2, 3, -1}) {
+		t.Errorf("rowCounts() = %v, want [2 3 -1]", got)
+	}
+
+	// A failed statement ends the counts
+	fakeBatch(t, []SQLRETURN{SQL_SUCCESS, SQL_ERROR}, []SQLLEN{4})
+	if got := rowCounts(1, 1); !reflect.DeepEqual(got, []int64{1, 4}) {
+		t.Errorf("rowCounts() = %v, want [1 4]", got)
+	}
+}
+
+func TestResult_AllRowsAffected(t *testing.T) {
+	r := &Result{rowsAffected: 2, allAffected: []int64{2, 5}}
+	if got := r.AllRowsAffected(); !reflect.DeepEqual(got, []int64{2, 5}) {
+		t.Errorf("AllRowsAffected() = %v, want [2 5]", got)
+	}
+	if n, _ := r.RowsAffected(); n != 2 {
+		t.Errorf("RowsAffected() = %d, want the first statement's count 2", n)
+	}
+	if got := (&Result{rowsAffected: 7}).AllRowsAffected(); !reflect.DeepEqual(got, []int64{7}) {
+		t.Errorf("AllRowsAffected() without batch counts = %v, want [7]", got)
+	}
+}
+
+// =============================================================================
+// Correlation ID Tests (correlation.go)
+// =============================================================================
+
+func TestCorrelationID(t *testing.T) {
+	if id := CorrelationID(context.Background()); id != "" {
+		t.Errorf("expected no correlation ID, got %q", id)
+	}
+	ctx := WithCorrelationID(context.Background(), "req-42")
+	if id := CorrelationID(ctx); id != "req-42" {
+		t.Errorf("expected req-42, got %q", id)
+	}
+}
+
+func TestCorrelationComment(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected string
+	}{
+		{"4bf92f3577b34da6", "/* correlation_id=4bf92f3577b34da6 */ "},
+		{"x */ DROP TABLE t; /*", "/* correlation_id=x __ DROP TABLE t; __ */ "},
+		{"a'b\"c?d", "/* correlation_id=a_b_c_d */ "},
+		{"line\nbreak", "/* correlation_id=line_break */ "},
+	}
+	for _, tt := range tests {
+		if got := correlationComment(tt.id); got != tt.expected {
+			t.Errorf("correlationComment(%q) = %q, expected %q", tt.id, got, tt.expected)
+		}
+	}
+}
+
+func TestConnTagQuery(t *testing.T) {
+	ctx := WithCorrelationID(context.Background(), "req-42")
+
+	c := &Conn{}
+	if got := c.tagQuery(ctx, "SELECT 1"); got != "SELECT 1" {
+		t.Errorf("expected untagged query when disabled, got %q", got)
+	}
+
+	c.correlationComments = true
+	if got := c.tagQuery(context.Background(), "SELECT 1"); got != "SELECT 1" {
+		t.Errorf("expected untagged query without an ID, got %q", got)
+	}
+	if got := c.tagQuery(ctx, "SELECT 1"); got != "/* correlation_id=req-42 */ SELECT 1" {
+		t.Errorf("unexpected tagged query %q", got)
+	}
+	if got := c.tagQuery(ctx, " {call proc(?)}"); got != " {call proc(?)}" {
+		t.Errorf("expected escape call to be left untouched, got %q", got)
+	}
+}
+
+func TestConn_StartSpan_CorrelationID(t *testing.T) {
+	tracer := &recordingTracer{}
+	c := &Conn{tracer: tracer, dbType: "PostgreSQL"}
+	endSpan(c.startSpan(context.Background(), SpanQuery, "SELECT 1"), nil)
+	endSpan(c.startSpan(WithCorrelationID(context.Background(), "req-42"), SpanQuery, "SELECT 1"), nil)
+
+	if _, ok := tracer.spans[0].attrs[AttrCorrelationID]; ok {
+		t.Error("expected no correlation ID attribute without an ID")
+	}
+	if got := tracer.spans[1].attrs[AttrCorrelationID]; got != "req-42" {
+		t.Errorf("expected correlation ID req-42, got %v", got)
+	}
+}
+
+func TestLogAttrs_CorrelationID(t *testing.T) {
+	var buf strings.Builder
+	c := &Conn{dbType: "PostgreSQL", logger: slog.New(slog.NewTextHandler(&buf, nil))}
+	c.resetFailed(WithCorrelationID(context.Background(), "req-42"), errors.New("boom"))
+	if out := buf.String(); !strings.Contains(out, "correlation_id=req-42") {
+		t.Errorf("log output = %q, want the correlation ID", out)
+	}
+
+	buf.Reset()
+	logRetry(context.Background(), c.logger, "query")(1, errors.New("boom"), time.Second)
+	if out := buf.String(); strings.Contains(out, "correlation_id") {
+		t.Errorf("log output = %q, want no correlation ID", out)
+	}
+}
+
+func TestWithCorrelationComments(t *testing.T) {
+	c := &Connector{}
+	WithCorrelationComments(true)(c)
+	if !c.CorrelationComments {
+		t.Error("expected CorrelationComments to be enabled")
+	}
+}
+
+// =============================================================================
+// Workload Hint Tests (workload.go)
+// =============================================================================
+
+func TestWorkloadHintFromContext(t *testing.T) {
+	if hint := WorkloadHintFromContext(context.Background()); !hint.isZero() {
+		t.Errorf("WorkloadHintFromContext() without a hint = %+v, want zero", hint)
+	}
+	want := WorkloadHint{Tag: "etl", Attributes: map[string]string{"Priority": "low"}}
+	if hint := WorkloadHintFromContext(WithWorkloadHint(context.Background(), want)); !hint.equal(want) {
+		t.Errorf("WorkloadHintFromContext() = %+v, want %+v", hint, want)
+	}
+}
+
+func TestConn_WorkloadStatements(t *testing.T) {
+	hint := WorkloadHint{Tag: "it's etl", Attributes: map[string]string{"Priority": "low", "Team": "a;b=c"}}
+	ctx := WithWorkloadHint(context.Background(), hint)
+	tests := []struct {
+		dbType string
+		want   []string
+	}{
+		{"Snowflake", []string{"ALTER SESSION SET QUERY_TAG = 'it''s etl'"}},
+		{"Microsoft SQL Server", []string{
+			"EXEC sp_set_session_context N'Priority', N'low'",
+			"EXEC sp_set_session_context N'Team', N'a;b=c'",
+			"EXEC sp_set_session_context N'query_tag', N'it''s etl'",
+		}},
+		{"Teradata", []string{"SET QUERY_BAND = 'QueryTag=it''s etl;Priority=low;Team=a_b_c;' FOR SESSION"}},
+		{"PostgreSQL", []string{"SET application_name = 'it''s etl'"}},
+		{"MySQL", nil},
+	}
+	for _, tt := range tests {
+		c := &Conn{dbType: tt.dbType}
+		stmts, applied := c.workloadStatements(ctx)
+		if !reflect.DeepEqual(stmts, tt.want) || !applied.equal(hint) {
+			t.Errorf("%s: workloadStatements() = %q, want %q", tt.dbType, stmts, tt.want)
+		}
+	}
+}
+
+func TestConn_WorkloadStatements_Changes(t *testing.T) {
+	hint := WorkloadHint{Tag: "etl", Attributes: map[string]string{"Priority": "low"}}
+	c := &Conn{dbType: "Microsoft SQL Server", workloadHint: hint}
+
+	// An applied hint is not applied again
+	if stmts, _ := c.workloadStatements(WithWorkloadHint(context.Background(), hint)); stmts != nil {
+		t.Errorf("workloadStatements() for the applied hint = %q, want none", stmts)
+	}
+
+	// A context without a hint clears the keys the applied hint set
+	stmts, applied := c.workloadStatements(context.Background())
+	want := []string{"EXEC sp_set_session_context N'Priority', NULL", "EXEC sp_set_session_context N'query_tag', NULL"}
+	if !reflect.DeepEqual(stmts, want) || !applied.isZero() {
+		t.Errorf("workloadStatements() without a hint = %q, want %q", stmts, want)
+	}
+
+	for dbType, want := range map[string]string{
+		"Snowflake":  "ALTER SESSION UNSET QUERY_TAG",
+		"Teradata":   "SET QUERY_BAND = NONE FOR SESSION",
+		"PostgreSQL": "RESET application_name",
+	} {
+		c := &Conn{dbType: dbType, workloadHint: hint}
+		if stmts, _ := c.workloadStatements(context.Background()); !reflect.DeepEqual(stmts, []string{want}) {
+			t.Errorf("%s: workloadStatements() without a hint = %q, want [%q]", dbType, stmts, want)
+		}
+	}
+
+	// Nothing is applied to a connection that never had a hint
+	if stmts, _ := (&Conn{dbType: "Snowflake"}).workloadStatements(context.Background()); stmts != nil {
+		t.Errorf("workloadStatements() = %q, want none", stmts)
+	}
+}
+
+// =============================================================================
+// LOB Streaming Tests (lob.go)
+// =============================================================================
+
+func TestWithLOBStreaming(t *testing.T) {
+	if lobStreamingFromContext(context.Background()) {
+		t.Error("expected LOB streaming to be disabled by default")
+	}
+	if !lobStreamingFromContext(WithLOBStreaming(context.Background())) {
+		t.Error("expected LOB streaming to be enabled")
+	}
+}
+
+func TestLOBCType(t *testing.T) {
+	tests := []struct {
+		colType     SQLSMALLINT
+		sizeUnknown bool
+		expected    SQLSMALLINT
+	}{
+		{SQL_LONGVARBINARY, false, SQL_C_BINARY},
+		{SQL_LONGVARCHAR, false, SQL_C_CHAR},
+		{SQL_WLONGVARCHAR, false, SQL_C_WCHAR},
+		{SQL_DB2_CLOB, false, SQL_C_WCHAR},
+		{SQL_VARCHAR, false, 0},
+		{SQL_VARCHAR, true, SQL_C_CHAR},
+		{SQL_WVARCHAR, true, SQL_C_WCHAR},
+		{SQL_VARBINARY, true, SQL_C_BINARY},
+		{SQL_INTEGER, true, 0},
+	}
+	for _, tt := range tests {
+		if got := lobCType(tt.colType, tt.sizeUnknown); got != tt.expected {
+			t.Errorf("lobCType(%d, %v) = %d, expected %d", tt.colType, tt.sizeUnknown, got, tt.expected)
+		}
+	}
+}
+
+func TestLOBColumnTypes(t *testing.T) {
+	// Only the trailing run of LOB columns is streamed
+	colTypes := []SQLSMALLINT{SQL_LONGVARCHAR, SQL_INTEGER, SQL_LONGVARBINARY, SQL_WLONGVARCHAR}
+	types := lobColumnTypes(colTypes, []bool{false, false, false, false}, true)
+	expected := []SQLSMALLINT{0, 0, SQL_C_BINARY, SQL_C_WCHAR}
+	if !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected %v, got %v", expected, types)
+	}
+
+	// Drivers that read columns in order only stream the last column
+	types = lobColumnTypes(colTypes, []bool{false, false, false, false}, false)
+	expected = []SQLSMALLINT{0, 0, 0, SQL_C_WCHAR}
+	if !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected %v in column order, got %v", expected, types)
+	}
+
+	if types := lobColumnTypes([]SQLSMALLINT{SQL_LONGVARCHAR, SQL_INTEGER}, []bool{false, false}, true); types != nil {
+		t.Errorf("expected nil when the last column is not a LOB, got %v", types)
+	}
+}
+
+func TestRowsLOBScanType(t *testing.T) {
+	r := &Rows{
+		colTypes:    []SQLSMALLINT{SQL_INTEGER, SQL_LONGVARBINARY},
+		sizeUnknown: []bool{false, false},
+	}
+	if st := r.ColumnTypeScanType(1); st != reflect.TypeOf([]byte{}) {
+		t.Errorf("expected []byte without streaming, got %v", st)
+	}
+	r.setLOBStreaming(true)
+	if st := r.ColumnTypeScanType(1); st != reflect.TypeOf((*LOBReader)(nil)) {
+		t.Errorf("expected *LOBReader with streaming, got %v", st)
+	}
+	if st := r.ColumnTypeScanType(0); st != reflect.TypeOf(int64(0)) {
+		t.Errorf("expected int64 for the non-LOB column, got %v", st)
+	}
+}
+
+func TestLOBReader_Stale(t *testing.T) {
+	r := &Rows{rowGen: 2}
+	l := &LOBReader{rows: r, cType: SQL_C_BINARY, row: 1, size: -1}
+	if _, err := l.Read(make([]byte, 16)); err != ErrLOBReaderStale {
+		t.Errorf("expected ErrLOBReaderStale, got %v", err)
+	}
+
+	r.rowGen = 1
+	r.closed = true
+	if _, err := l.Read(make([]byte, 16)); err != ErrLOBReaderStale {
+		t.Errorf("expected ErrLOBReaderStale after close, got %v", err)
+	}
+}
+
+func TestLOBReader_ReadPending(t *testing.T) {
+	l := &LOBReader{pending: []byte("hello world"), eof: true}
+	data, err := io.ReadAll(l)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("expected %q, got %q", "hello world", data)
+	}
+}
+
+func TestLOBReader_DecodeWideSurrogates(t *testing.T) {
+	if wcharSize() != 2 {
+		t.Skip("UTF-16 SQLWCHAR only")
+	}
+	units := utf16.Encode([]rune("a\U0001F600b"))
+	chunk := func(u []uint16) []byte {
+		return unsafe.Slice((*byte)(unsafe.Pointer(&u[0])), len(u)*2)
+	}
+
+	// Split the surrogate pair across two chunks
+	l := &LOBReader{}
+	first := string(l.decodeWide(chunk(units[:2])))
+	l.eof = true
+	second := string(l.decodeWide(chunk(units[2:])))
+	if first != "a" || second != "\U0001F600b" {
+		t.Errorf("expected %q and %q, got %q and %q", "a", "\U0001F600b", first, second)
+	}
+}
+
+// =============================================================================
+// Query Statistics Tests (stats.go)
+// =============================================================================
+
+func TestWithStatsCollector(t *testing.T) {
+	if c := statsCollectorFromContext(context.Background()); c != nil {
+		t.Errorf("expected no collector in background context, got %v", c)
+	}
+
+	ctx, collector := WithStatsCollector(context.Background())
+	if got := statsCollectorFromContext(ctx); got != collector {
+		t.Errorf("expected collector from context, got %v", got)
+	}
+
+	// A nil collector ignores stats
+	var none *StatsCollector
+	none.add(QueryStats{Queries: 1})
+}
+
+func TestStatsCollector_Add(t *testing.T) {
+	_, collector := WithStatsCollector(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			collector.add(QueryStats{
+				Queries:      1,
+				RowsFetched:  100,
+				GetDataCalls: 300,
+				BytesRead:    4096,
+				PrepareTime:  time.Millisecond,
+				ExecuteTime:  2 * time.Millisecond,
+				FetchTime:    3 * time.Millisecond,
+			})
+		}()
+	}
+	wg.Wait()
+
+	expected := QueryStats{
+		Queries:      10,
+		RowsFetched:  1000,
+		GetDataCalls: 3000,
+		BytesRead:    40960,
+		PrepareTime:  10 * time.Millisecond,
+		ExecuteTime:  20 * time.Millisecond,
+		FetchTime:    30 * time.Millisecond,
+	}
+	if got := collector.Stats(); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestRows_SetStats(t *testing.T) {
+	ctx, collector := WithStatsCollector(context.Background())
+	r := &Rows{}
+	r.setStats(ctx, time.Millisecond, 2*time.Millisecond)
+
+	if r.collector != collector {
+		t.Error("expected rows to use the context collector")
+	}
+	expected := QueryStats{Queries: 1, PrepareTime: time.Millisecond, ExecuteTime: 2 * time.Millisecond}
+	if got := r.Stats(); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestStmt_TakePrepareTime(t *testing.T) {
+	s := &Stmt{prepareTime: time.Millisecond}
+	if got := s.takePrepareTime(); got != time.Millisecond {
+		t.Errorf("expected 1ms on first execution, got %v", got)
+	}
+	if got := s.takePrepareTime(); got != 0 {
+		t.Errorf("expected 0 on later executions, got %v", got)
+	}
+}
+
+// =============================================================================
+// Shutdown Tests (shutdown.go)
+// =============================================================================
+
+func TestDriverState_Shutdown(t *testing.T) {
+	s := newDriverState()
+	c := &Conn{}
+	s.addConn(c)
+
+	if err := s.shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.closed {
+		t.Error("expected registered connection to be closed")
+	}
+	if _, err := s.begin(); !errors.Is(err, ErrShutdown) {
+		t.Errorf("expected ErrShutdown after shutdown, got %v", err)
+	}
+
+	// Shutting down again is a no-op
+	if err := s.shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected error on second shutdown: %v", err)
+	}
+}
+
+func TestDriverState_ShutdownWaitsForOperations(t *testing.T) {
+	s := newDriverState()
+	op, err := s.begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.shutdown(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("shutdown returned before the operation ended: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	op.end()
+	op.end() // ending twice must not release another operation
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shutdown did not return after the operation ended")
+	}
+}
+
+func TestDriverState_ShutdownTimeout(t *testing.T) {
+	s := newDriverState()
+	op, err := s.begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := &Conn{}
+	s.addConn(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = s.shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if c.closed {
+		t.Error("expected connection to stay open while an operation is running")
+	}
+	op.end()
+}
+
+func TestOperation_Handoff(t *testing.T) {
+	s := newDriverState()
+	op, err := s.begin()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	held := op.handoff()
+	op.end()
+	if s.active != 1 {
+		t.Errorf("expected handed-off operation to stay active, got %d", s.active)
+	}
+	held.end()
+	if s.active != 0 {
+		t.Errorf("expected no active operations, got %d", s.active)
+	}
+
+	// Rows without an operation close cleanly
+	var none *operation
+	none.end()
+}
+
+// fakeArrayBatch makes array binding succeed and SQLExecute call execute, so
+// ExecBatch runs its parameter sets as one array
+func fakeArrayBatch(t *testing.T, execute func(SQLHSTMT) SQLRETURN) {
+	t.Helper()
+	origAttr, origBind, origExec, origFree, origCount := sqlSetStmtAttr, sqlBindParameter, sqlExecute, sqlFreeStmt, sqlRowCount
+	t.Cleanup(func() {
+		sqlSetStmtAttr, sqlBindParameter, sqlExecute, sqlFreeStmt, sqlRowCount = origAttr, origBind, origExec, origFree, origCount
+	})
+	sqlSetStmtAttr = func(SQLHSTMT, SQLINTEGER, uintptr, SQLINTEGER) SQLRETURN { return SQL_SUCCESS }
+	sqlBindParameter = func(SQLHSTMT, SQLUSMALLINT, SQLSMALLINT, SQLSMALLINT, SQLSMALLINT, SQLULEN, SQLSMALLINT, uintptr, SQLLEN, *SQLLEN) SQLRETURN {
+		return SQL_SUCCESS
+	}
+	sqlFreeStmt = func(SQLHSTMT, SQLUSMALLINT) SQLRETURN { return SQL_SUCCESS }
+	sqlRowCount = func(_ SQLHSTMT, rowCount *SQLLEN) SQLRETURN {
+		*rowCount = 0
+		return SQL_SUCCESS
+	}
+	sqlExecute = execute
+}
+
+func TestShutdown_WaitsForExecBatch(t *testing.T) {
+	saved := lifecycle
+	lifecycle = newDriverState()
+	t.Cleanup(func() { lifecycle = saved })
+
+	started, release := make(chan struct{}), make(chan struct{})
+	fakeArrayBatch(t, func(SQLHSTMT) SQLRETURN {
+		close(started)
+		<-release
+		return SQL_SUCCESS
+	})
+
+	s := &Stmt{conn: &Conn{}, query: "INSERT INTO t VALUES (?)"}
+	paramSets := [][]driver.NamedValue{{{Ordinal: 1, Value: int64(1)}}, {{Ordinal: 1, Value: int64(2)}}}
+	batchDone := make(chan error, 1)
+	go func() {
+		_, err := s.ExecBatch(context.Background(), paramSets)
+		batchDone <- err
+	}()
+	<-started
+
+	shutdownDone := make(chan error, 1)
+	go func() { shutdownDone <- Shutdown(context.Background()) }()
+	select {
+	case err := <-shutdownDone:
+		t.Fatalf("Shutdown returned while the batch was running: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-batchDone; err != nil {
+		t.Fatalf("ExecBatch() error: %v", err)
+	}
+	select {
+	case err := <-shutdownDone:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown did not return after the batch finished")
+	}
+
+	if _, err := s.ExecBatch(context.Background(), paramSets); !errors.Is(err, ErrShutdown) {
+		t.Errorf("expected ErrShutdown for a batch after Shutdown, got %v", err)
+	}
+}
+
+// =============================================================================
+// Driver Listing Tests (drivers.go)
+// =============================================================================
+
+func TestParseDriverAttributes(t *testing.T) {
+	buf := []byte("Driver=/usr/lib/libmsodbcsql-18.so\x00UsageCount=1\x00Setup=\x00=ignored\x00bad\x00\x00")
+	got := parseDriverAttributes(buf)
+	want := map[string]string{
+		"Driver":     "/usr/lib/libmsodbcsql-18.so",
+		"UsageCount": "1",
+		"Setup":      "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDriverAttributes = %v, want %v", got, want)
+	}
+	if got := parseDriverAttributes(nil); len(got) != 0 {
+		t.Errorf("parseDriverAttributes(nil) = %v, want empty", got)
+	}
+}
+
+func TestClampLen(t *testing.T) {
+	tests := []struct {
+		n    SQLSMALLINT
+		size int
+		want int
+	}{
+		{5, 256, 5},
+		{300, 256, 255}, // truncated value reports its full length
+		{-1, 256, 0},
+	}
+	for _, tt := range tests {
+		if got := clampLen(tt.n, tt.size); got != tt.want {
+			t.Errorf("clampLen(%d, %d) = %d, want %d", tt.n, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestListDrivers_TruncatedAttributes(t *testing.T) {
+	orig := sqlDrivers
+	t.Cleanup(func() { sqlDrivers = orig })
+
+	// The driver manager truncates attribute lists longer than the buffer
+	attrs := "Driver=/usr/lib/libdriver.so\x00Description=" + strings.Repeat("x", 5000) + "\x00\x00"
+	entries := []string{"Driver A", "Driver B"}
+	next, truncated := 0, 0
+	sqlDrivers = func(_ SQLHENV, direction SQLUSMALLINT, desc *byte, descMax SQLSMALLINT, descLen *SQLSMALLINT, attr *byte, attrMax SQLSMALLINT, attrLen *SQLSMALLINT) SQLRETURN {
+		if direction == SQLUSMALLINT(SQL_FETCH_FIRST) {
+			next = 0
+		}
+		if next == len(entries) {
+			return SQL_NO_DATA
+		}
+		name := entries[next]
+		next++
+		copy(unsafe.Slice(desc, int(descMax)), name+"\x00")
+		*descLen = SQLSMALLINT(len(name))
+		out := unsafe.Slice(attr, int(attrMax))
+		*attrLen = SQLSMALLINT(len(attrs) - 1)
+		if len(attrs) > len(out) {
+			truncated++
+			copy(out[:len(out)-1], attrs)
+			out[len(out)-1] = 0
+			return SQL_SUCCESS_WITH_INFO
+		}
+		copy(out, attrs)
+		return SQL_SUCCESS
+	}
+
+	drivers, err := listDrivers(1)
+	if err != nil {
+		t.Fatalf("listDrivers: %v", err)
+	}
+	if truncated != 1 || len(drivers) != 2 || drivers[0].Name != "Driver A" || drivers[1].Name != "Driver B" {
+		t.Fatalf("expected one truncated fetch and 2 drivers, got %d and %+v", truncated, drivers)
+	}
+	if got := drivers[1].Attributes["Description"]; len(got) != 5000 {
+		t.Errorf("expected the full 5000-byte description, got %d bytes", len(got))
+	}
+}
+
+func TestGrowCatalogBuffer(t *testing.T) {
+	buf := make([]byte, 256)
+	if got, grown := growCatalogBuffer(buf, 100, 1); grown || len(got) != 256 {
+		t.Errorf("expected the buffer kept, got %d bytes (grown=%v)", len(got), grown)
+	}
+	if got, grown := growCatalogBuffer(buf, 256, 1); !grown || len(got) != 257 {
+		t.Errorf("expected a 257-byte buffer, got %d bytes (grown=%v)", len(got), grown)
+	}
+	full := make([]byte, math.MaxInt16)
+	if _, grown := growCatalogBuffer(full, math.MaxInt16, 1); grown {
+		t.Error("expected no buffer larger than math.MaxInt16")
+	}
+	// Wide buffers are sized in characters
+	if got, grown := growCatalogBuffer(buf, 200, 2); !grown || len(got) != 402 {
+		t.Errorf("expected a 402-byte buffer, got %d bytes (grown=%v)", len(got), grown)
+	}
+}
+
+func TestListDrivers_Wide(t *testing.T) {
+	savedDefault := wideByDefault
+	orig, origW := sqlDrivers, sqlDriversW
+	t.Cleanup(func() {
+		wideByDefault = savedDefault
+		sqlDrivers, sqlDriversW = orig, origW
+	})
+	wideByDefault = true
+	sqlDrivers = func(SQLHENV, SQLUSMALLINT, *byte, SQLSMALLINT, *SQLSMALLINT, *byte, SQLSMALLINT, *SQLSMALLINT) SQLRETURN {
+		t.Error("expected SQLDriversW")
+		return SQL_ERROR
+	}
+	// Lengths are in characters, and the attribute list keeps its separators
+	sqlDriversW = func(_ SQLHENV, direction SQLUSMALLINT, desc uintptr, descMax SQLSMALLINT, descLen *SQLSMALLINT, attrs uintptr, attrsMax SQLSMALLINT, attrsLen *SQLSMALLINT) SQLRETURN {
+		if direction != SQLUSMALLINT(SQL_FETCH_FIRST) {
+			return SQL_NO_DATA
+		}
+		*descLen = SQLSMALLINT(putWide(desc, int(descMax), "Pilote ODBC Ünicode"))
+		*attrsLen = SQLSMALLINT(putWide(attrs, int(attrsMax), "Driver=C:\\Programme\\pilote.dll\x00Setup=réglage.dll\x00"))
+		return SQL_SUCCESS
+	}
+
+	drivers, err := listDrivers(1)
+	if err != nil {
+		t.Fatalf("listDrivers: %v", err)
+	}
+	want := []DriverInfo{{Name: "Pilote ODBC Ünicode", Attributes: map[string]string{
+		"Driver": "C:\\Programme\\pilote.dll", "Setup": "réglage.dll",
+	}}}
+	if !reflect.DeepEqual(drivers, want) {
+		t.Errorf("expected %+v, got %+v", want, drivers)
+	}
+}
+
+// =============================================================================
+// Driver Manager Detection Tests (driver_manager.go)
+// =============================================================================
+
+func TestDetectDriverManager(t *testing.T) {
+	symbols := func(names ...string) func(string) bool {
+		return func(name string) bool {
+			for _, n := range names {
+				if n == name {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	tests := []struct {
+		name      string
+		libPath   string
+		hasSymbol func(string) bool
+		expected  DriverManager
+	}{
+		{"unixODBC symbols", "/custom/libdm.so", symbols("uodbc_get_stats"), DriverManagerUnixODBC},
+		{"iODBC symbols", "/custom/libdm.so", symbols("_iodbcdm_getproc"), DriverManagerIODBC},
+		{"symbols win over name", "/usr/lib/libiodbc.so.2", symbols("uodbc_open_stats"), DriverManagerUnixODBC},
+		{"unixODBC name", "/usr/lib/x86_64-linux-gnu/libodbc.so.2", symbols(), DriverManagerUnixODBC},
+		{"iODBC name", "/usr/lib/libiodbc.2.dylib", symbols(), DriverManagerIODBC},
+		{"direct driver", "/opt/simba/lib/libsnowflakeodbc.so", symbols(), DriverManagerUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDriverManager(tt.libPath, tt.hasSymbol); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWCharSizeFor(t *testing.T) {
+	if got := wcharSizeFor(DriverManagerUnixODBC); got != 2 {
+		t.Errorf("expected 2 for unixODBC, got %d", got)
+	}
+	if got := wcharSizeFor(DriverManagerUnknown); got != 2 {
+		t.Errorf("expected 2 for unknown driver manager, got %d", got)
+	}
+	expected := 4
+	if runtime.GOOS == "windows" {
+		expected = 2
+	}
+	if got := wcharSizeFor(DriverManagerIODBC); got != expected {
+		t.Errorf("expected %d for iODBC, got %d", expected, got)
+	}
+}
+
+func TestUTF32RoundTrip(t *testing.T) {
+	for _, s := range []string{"", "hello", "日本語", "emoji 😀 test"} {
+		u := stringToUTF32(s)
+		if u[len(u)-1] != 0 {
+			t.Errorf("%q: expected null terminator", s)
+		}
+		if got := utf32ToString(u[:len(u)-1]); got != s {
+			t.Errorf("round trip: got %q, expected %q", got, s)
+		}
+	}
+	if got := utf32ToString([]uint32{'a', 0xD800, 0x110000}); got != "a��" {
+		t.Errorf("expected invalid code points to be replaced, got %q", got)
+	}
+}
+
+func TestWideStringParam_WCharSize(t *testing.T) {
+	saved := libraryInfo
+	defer func() { libraryInfo = saved }()
+
+	libraryInfo.WCharSize = 2
+	buf, chars, n := wideStringParam("a😀")
+	if _, ok := buf.([]uint16); !ok || chars != 3 || n != 6 {
+		t.Errorf("UTF-16: got %T, %d chars, %d bytes", buf, chars, n)
+	}
+
+	libraryInfo.WCharSize = 4
+	buf, chars, n = wideStringParam("a😀")
+	if _, ok := buf.([]uint32); !ok || chars != 2 || n != 8 {
+		t.Errorf("UTF-32: got %T, %d chars, %d bytes", buf, chars, n)
+	}
+
+	result, err := AllocateColumnArray([]interface{}{"ab", nil}, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Lengths[0] != 8 {
+		t.Errorf("expected 8 bytes for 'ab' as UTF-32, got %d", result.Lengths[0])
+	}
+	data := result.Data.([]byte)
+	if got := binary.LittleEndian.Uint32(data[4:]); got != 'b' {
+		t.Errorf("expected 'b' as second UTF-32 unit, got %d", got)
+	}
+}
+
+// =============================================================================
+// Column Metadata Tests (columns.go)
+// =============================================================================
+
+func TestConn_IdentityQuery(t *testing.T) {
+	for _, dbType := range []string{"Microsoft SQL Server", "PostgreSQL", "MySQL", "MariaDB", "Oracle", "DB2/LINUXX8664", "Snowflake"} {
+		c := &Conn{dbType: dbType}
+		if c.identityQuery() == nil {
+			t.Errorf("expected identity query for %s", dbType)
+		}
+	}
+	if q := (&Conn{dbType: "SQLite"}).identityQuery(); q != nil {
+		t.Error("expected no identity query for SQLite")
+	}
+}
+
+func TestIdentityQueries_Args(t *testing.T) {
+	query, args := identityQueries["postgresql"]("", "orders")
+	if !strings.Contains(query, "current_schema()") || !strings.Contains(query, "LIKE 'nextval(%'") {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if len(args) != 1 || args[0] != "orders" {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	query, args = identityQueries["mysql"]("shop", "orders")
+	if strings.Count(query, "?") != 2 || !strings.Contains(query, "'%auto_increment%'") {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if len(args) != 2 || args[0] != "shop" || args[1] != "orders" {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	_, args = identityQueries["sql server"]("dbo", "orders")
+	if len(args) != 1 || args[0] != "dbo.orders" {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestCatalogInt64(t *testing.T) {
+	tests := []struct {
+		value    driver.Value
+		expected int64
+	}{
+		{nil, 0},
+		{int64(42), 42},
+		{"17", 17},
+		{"10.000", 10},
+		{"abc", 0},
+	}
+	for _, tt := range tests {
+		if got := catalogInt64(tt.value); got != tt.expected {
+			t.Errorf("catalogInt64(%v) = %d, expected %d", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestIsTruthy(t *testing.T) {
+	for _, v := range []driver.Value{"YES", "yes", "Y", "1", int64(1), true} {
+		if !isTruthy(v) {
+			t.Errorf("expected %v to be true", v)
+		}
+	}
+	for _, v := range []driver.Value{"NO", "", nil, int64(0), false} {
+		if isTruthy(v) {
+			t.Errorf("expected %v to be false", v)
+		}
+	}
+}
+
+// =============================================================================
+// Index Metadata Tests (indexes.go)
+// =============================================================================
+
+// fakeCatalogStmt replaces statement allocation for catalog calls that fail,
+// so the arguments passed to the catalog function can be checked
+func fakeCatalogStmt(t *testing.T) {
+	t.Helper()
+	origAlloc, origFree, origDiag := sqlAllocHandle, sqlFreeHandle, sqlGetDiagRec
+	t.Cleanup(func() { sqlAllocHandle, sqlFreeHandle, sqlGetDiagRec = origAlloc, origFree, origDiag })
+	sqlAllocHandle = func(_ SQLSMALLINT, _ SQLHANDLE, out *SQLHANDLE) SQLRETURN {
+		*out = 2
+		return SQL_SUCCESS
+	}
+	sqlFreeHandle = func(SQLSMALLINT, SQLHANDLE) SQLRETURN { return SQL_SUCCESS }
+	sqlGetDiagRec = func(SQLSMALLINT, SQLHANDLE, SQLSMALLINT, *byte, *SQLINTEGER, *byte, SQLSMALLINT, *SQLSMALLINT) SQLRETURN {
+		return SQL_NO_DATA
+	}
+}
+
+func TestConn_Indexes_SchemaAndTable(t *testing.T) {
+	fakeCatalogStmt(t)
+	orig := sqlStatistics
+	t.Cleanup(func() { sqlStatistics = orig })
+	var schema, table string
+	sqlStatistics = func(_ SQLHSTMT, _ *byte, _ SQLSMALLINT, schemaName *byte, _ SQLSMALLINT, tableName *byte, _ SQLSMALLINT, _, _ SQLUSMALLINT) SQLRETURN {
+		schema, table = unsafe.String(schemaName, cStringLen(schemaName)), unsafe.String(tableName, cStringLen(tableName))
+		return SQL_ERROR
+	}
+
+	c := &Conn{dbc: 1}
+	c.Indexes(context.Background(), "a.b", "c")
+	if schema != "a.b" || table != "c" {
+		t.Errorf("expected schema %q and table %q, got %q and %q", "a.b", "c", schema, table)
+	}
+	if _, err := c.Indexes(context.Background(), "sales", ""); err == nil {
+		t.Error("expected an error without a table name")
+	}
+}
+
+func TestGroupIndexRows(t *testing.T) {
+	rows := []indexRow{
+		{name: "pk_orders", indexType: SQL_INDEX_CLUSTERED, ordinal: 1, column: "id", ascOrDesc: "A", cardinality: 1000},
+		{name: "ix_customer_date", nonUnique: true, indexType: SQL_INDEX_OTHER, ordinal: 2, column: "created_at", ascOrDesc: "D"},
+		{name: "ix_customer_date", nonUnique: true, indexType: SQL_INDEX_OTHER, ordinal: 1, column: "customer_id", ascOrDesc: "A"},
+		{name: "ix_active", nonUnique: true, indexType: SQL_INDEX_OTHER, ordinal: 1, column: "status", filter: "([status]='active')"},
+	}
+	indexes := groupIndexRows(rows)
+	if len(indexes) != 3 {
+		t.Fatalf("expected 3 indexes, got %d", len(indexes))
+	}
+
+	pk := indexes[0]
+	if pk.Name != "pk_orders" || !pk.Unique || pk.Type != SQL_INDEX_CLUSTERED || pk.Cardinality != 1000 {
+		t.Errorf("unexpected primary key index: %+v", pk)
+	}
+
+	ix := indexes[1]
+	if ix.Unique {
+		t.Error("expected ix_customer_date to be non-unique")
+	}
+	expected := []IndexColumn{{Name: "customer_id"}, {Name: "created_at", Descending: true}}
+	if !reflect.DeepEqual(ix.Columns, expected) {
+		t.Errorf("expected columns %+v in key order, got %+v", expected, ix.Columns)
+	}
+
+	if indexes[2].Filter != "([status]='active')" {
+		t.Errorf("expected filter condition, got %q", indexes[2].Filter)
+	}
+}
+
+func TestGroupIndexRows_Qualifier(t *testing.T) {
+	// Indexes with the same name in different qualifiers are distinct
+	rows := []indexRow{
+		{qualifier: "a", name: "ix", ordinal: 1, column: "x"},
+		{qualifier: "b", name: "ix", ordinal: 1, column: "y"},
+	}
+	if got := len(groupIndexRows(rows)); got != 2 {
+		t.Errorf("expected 2 indexes, got %d", got)
+	}
+	if got := groupIndexRows(nil); got != nil {
+		t.Errorf("expected nil for no rows, got %+v", got)
+	}
+}
+
+// =============================================================================
+// Foreign Key Tests (foreignkeys.go)
+// =============================================================================
+
+func TestConn_ForeignKeys_SchemaAndTable(t *testing.T) {
+	fakeCatalogStmt(t)
+	orig := sqlForeignKeys
+	t.Cleanup(func() { sqlForeignKeys = orig })
+	var schema, table string
+	sqlForeignKeys = func(_ SQLHSTMT, _ *byte, _ SQLSMALLINT, _ *byte, _ SQLSMALLINT, _ *byte, _ SQLSMALLINT, _ *byte, _ SQLSMALLINT, fkSchemaName *byte, _ SQLSMALLINT, fkTableName *byte, _ SQLSMALLINT) SQLRETURN {
+		schema, table = unsafe.String(fkSchemaName, cStringLen(fkSchemaName)), unsafe.String(fkTableName, cStringLen(fkTableName))
+		return SQL_ERROR
+	}
+
+	c := &Conn{dbc: 1}
+	c.ForeignKeys(context.Background(), "sales", "order.lines")
+	if schema != "sales" || table != "order.lines" {
+		t.Errorf("expected schema %q and table %q, got %q and %q", "sales", "order.lines", schema, table)
+	}
+	if _, err := c.ForeignKeys(context.Background(), "sales", ""); err == nil {
+		t.Error("expected an error without a table name")
+	}
+}
+
+func TestGroupForeignKeyRows(t *testing.T) {
+	rows := []foreignKeyRow{
+		{pkSchema: "sales", pkTable: "customers", pkColumn: "id", fkColumn: "customer_id", keySeq: 1,
+			updateRule: SQL_NO_ACTION, deleteRule: SQL_CASCADE, fkName: "fk_customer", pkName: "pk_customers"},
+		{pkSchema: "sales", pkTable: "order_lines", pkColumn: "line_no", fkColumn: "line_no", keySeq: 2, fkName: "fk_line"},
+		{pkSchema: "sales", pkTable: "order_lines", pkColumn: "order_id", fkColumn: "order_id", keySeq: 1, fkName: "fk_line"},
+	}
+	keys := groupForeignKeyRows(rows)
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 foreign keys, got %d", len(keys))
+	}
+
+	fk := keys[0]
+	if fk.Name != "fk_customer" || fk.ReferencedTable != "customers" || fk.ReferencedKey != "pk_customers" {
+		t.Errorf("unexpected first foreign key %+v", fk)
+	}
+	if fk.UpdateRule != SQL_NO_ACTION || fk.DeleteRule != SQL_CASCADE {
+		t.Errorf("expected NO ACTION/CASCADE rules, got %d/%d", fk.UpdateRule, fk.DeleteRule)
+	}
+
+	// Composite key columns are ordered by KEY_SEQ
+	fk = keys[1]
+	if !reflect.DeepEqual(fk.Columns, []string{"order_id", "line_no"}) ||
+		!reflect.DeepEqual(fk.ReferencedColumns, []string{"order_id", "line_no"}) {
+		t.Errorf("unexpected composite key columns %v -> %v", fk.Columns, fk.ReferencedColumns)
+	}
+
+	if got := groupForeignKeyRows(nil); got != nil {
+		t.Errorf("expected nil for no rows, got %+v", got)
+	}
+}
+
+func TestCatalogRule(t *testing.T) {
+	if got := catalogRule(nil); got != -1 {
+		t.Errorf("expected -1 for NULL, got %d", got)
+	}
+	if got := catalogRule(int64(SQL_SET_NULL)); got != SQL_SET_NULL {
+		t.Errorf("expected SQL_SET_NULL, got %d", got)
+	}
+}
+
+// =============================================================================
+// Type Info Tests (typeinfo.go)
+// =============================================================================
+
+func TestTypeInfoFromRow(t *testing.T) {
+	row := []driver.Value{
+		"nvarchar", int64(SQL_WVARCHAR), int64(4000), "N'", "'", "max length",
+		int64(SQL_NULLABLE), int64(0), int64(SQL_SEARCHABLE), nil, int64(0), int64(0),
+		nil, nil, nil, int64(SQL_WVARCHAR), nil, nil, nil,
+	}
+	want := TypeInfo{
+		Name:          "nvarchar",
+		DataType:      SQL_WVARCHAR,
+		ColumnSize:    4000,
+		LiteralPrefix: "N'",
+		LiteralSuffix: "'",
+		CreateParams:  []string{"max length"},
+		Nullable:      SQL_NULLABLE,
+		Searchable:    SQL_SEARCHABLE,
+	}
+	if got := typeInfoFromRow(row); !reflect.DeepEqual(got, want) {
+		t.Errorf("typeInfoFromRow() = %+v, want %+v", got, want)
+	}
+
+	row = []driver.Value{
+		"decimal", int64(SQL_DECIMAL), int64(38), nil, nil, "precision, scale",
+		int64(SQL_NULLABLE), int64(0), int64(SQL_PRED_BASIC), int64(0), int64(0), int64(1),
+		"decimal", int64(0), int64(38), int64(SQL_DECIMAL), nil, int64(10), nil,
+	}
+	got := typeInfoFromRow(row)
+	if !reflect.DeepEqual(got.CreateParams, []string{"precision", "scale"}) {
+		t.Errorf("CreateParams = %q, want [precision scale]", got.CreateParams)
+	}
+	if got.LiteralPrefix != "" || !got.AutoUnique || got.MaxScale != 38 || got.NumPrecRadix != 10 || got.LocalName != "decimal" {
+		t.Errorf("unexpected type info %+v", got)
+	}
+}
+
+// =============================================================================
+// Map Row Tests (rows.go)
+// =============================================================================
+
+func TestMapKeys(t *testing.T) {
+	got := mapKeys([]string{"id", "name", "id", "id_2", "id"})
+	expected := []string{"id", "name", "id_2", "id_2_2", "id_3"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestRows_NormalizeMapValue(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	ts := time.Date(2024, 3, 15, 10, 30, 0, 500, time.UTC)
+
+	r := &Rows{colTypes: []SQLSMALLINT{SQL_TYPE_TIMESTAMP, SQL_BIGINT}}
+	if got := r.normalizeMapValue(0, ts); !got.(time.Time).Equal(ts) {
+		t.Errorf("expected UTC timestamp by default, got %v", got)
+	}
+
+	r.stmt = &Stmt{conn: &Conn{timezone: ny}}
+	got := r.normalizeMapValue(0, ts).(time.Time)
+	if got.Location() != ny || got.Hour() != 10 || got.Minute() != 30 || got.Nanosecond() != 500 {
+		t.Errorf("expected wall clock 10:30 in New York, got %v", got)
+	}
+
+	// Epoch nanoseconds are converted back to time.Time; other integers are unchanged
+	r.stmt.conn.timestampFetchMode = TimestampFetchEpochNanos
+	got = r.normalizeMapValue(0, ts.UnixNano()).(time.Time)
+	if got.Location() != ny || got.Hour() != 10 || got.Nanosecond() != 500 {
+		t.Errorf("expected epoch nanos as 10:30 in New York, got %v", got)
+	}
+	if v := r.normalizeMapValue(1, int64(42)); v != int64(42) {
+		t.Errorf("expected int64 42, got %v", v)
+	}
+	if v := r.normalizeMapValue(1, nil); v != nil {
+		t.Errorf("expected nil, got %v", v)
+	}
+}
+
+func TestRows_NextMap_NoColumns(t *testing.T) {
+	r := &Rows{closed: true}
+	if _, err := r.NextMap(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+// =============================================================================
+// Column Order Tests (rows.go)
+// =============================================================================
+
+// fakeInOrderGetData makes SQLGetData fail, as drivers without
+// SQL_GD_ANY_ORDER do, for a column at or before the last one read on the
+// current row, and returns the columns read. Columns read through it must be
+// character columns, whose targets are heap buffers: a uintptr to a stack
+// variable would go stale if the stack moved before the fake wrote to it.
+func fakeInOrderGetData(t *testing.T) *[]SQLUSMALLINT {
+	orig, origScroll := sqlGetData, sqlFetchScroll
+	t.Cleanup(func() { sqlGetData, sqlFetchScroll = orig, origScroll })
+	var reads []SQLUSMALLINT
+	var last SQLUSMALLINT
+	sqlGetData = func(_ SQLHSTMT, colNum SQLUSMALLINT, _ SQLSMALLINT, targetValue uintptr, bufferLen SQLLEN, ind *SQLLEN) SQLRETURN {
+		if colNum <= last {
+			return SQL_ERROR
+		}
+		last = colNum
+		reads = append(reads, colNum)
+		*ind = SQLLEN(copy(unsafe.Slice((*byte)(unsafe.Add(nil, targetValue)), bufferLen), fmt.Sprint(colNum*10)))
+		return SQL_SUCCESS
+	}
+	sqlFetchScroll = func(SQLHSTMT, SQLSMALLINT, SQLLEN) SQLRETURN {
+		last = 0
+		return SQL_SUCCESS
+	}
+	return &reads
+}
+
+func TestRows_GetRowData_ReadsColumnsOnceInOrder(t *testing.T) {
+	reads := fakeInOrderGetData(t)
+	r := &Rows{
+		stmt:     &Stmt{},
+		columns:  []string{"a", "b", "c"},
+		colTypes: []SQLSMALLINT{SQL_VARCHAR, SQL_VARCHAR, SQL_VARCHAR},
+		colSizes: []SQLULEN{10, 10, 10},
+	}
+	if err := r.First(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A partial read still reads every column, in order
+	partial := make([]driver.Value, 1)
+	if err := r.GetRowData(partial); err != nil || partial[0] != "10" {
+		t.Fatalf("expected the first column, got %v (%v)", partial, err)
+	}
+	row := make([]driver.Value, 3)
+	if err := r.GetRowData(row); err != nil || !reflect.DeepEqual(row, []driver.Value{"10", "20", "30"}) {
+		t.Fatalf("expected the row to be read again from the kept values, got %v (%v)", row, err)
+	}
+	if !reflect.DeepEqual(*reads, []SQLUSMALLINT{1, 2, 3}) {
+		t.Errorf("expected each column read once in order, got %v", *reads)
+	}
+
+	// Moving the cursor reads the next row from the driver
+	if err := r.Relative(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.GetRowData(row); err != nil {
+		t.Fatal(err)
+	}
+	if len(*reads) != 6 {
+		t.Errorf("expected the new row to be read, got reads %v", *reads)
+	}
+}
+
+// =============================================================================
+// Column Lookup Tests (rows.go)
+// =============================================================================
+
+func TestRows_ColumnIndex(t *testing.T) {
+	r := &Rows{columns: []string{"ID", "CUSTOMER_NAME", "Amount", "amount", "MixedCase"}}
+
+	tests := []struct {
+		name     string
+		expected int
+	}{
+		{"ID", 0},              // exact
+		{"id", 0},              // case-insensitive
+		{"customer_name", 1},   // case-insensitive
+		{"Amount", 2},          // exact wins over case variants
+		{"amount", 3},          // exact
+		{"AMOUNT", -1},         // ambiguous
+		{`"MixedCase"`, 4},     // quoted exact
+		{`"mixedcase"`, -1},    // quoted must match exactly
+		{"[CUSTOMER_NAME]", 1}, // bracket quoted
+		{"missing", -1},        // not found
+	}
+	for _, tt := range tests {
+		if got := r.ColumnIndex(tt.name); got != tt.expected {
+			t.Errorf("ColumnIndex(%q) = %d, expected %d", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestRows_ColumnIndex_IdentifierCase(t *testing.T) {
+	// On an upper-casing database, folding resolves a name that is ambiguous case-insensitively
+	r := &Rows{
+		columns: []string{"Total", "TOTAL"},
+		stmt:    &Stmt{conn: &Conn{identifierCase: IdentifierCaseUpper}},
+	}
+	if got := r.ColumnIndex("total"); got != 1 {
+		t.Errorf("expected folded match at 1, got %d", got)
+	}
+}
+
+// =============================================================================
+// Re-prepare Tests (reprepare.go)
+// =============================================================================
+
+func TestConn_IsSchemaChangeError(t *testing.T) {
+	cursorChanged := &Error{SQLState: "42000", NativeError: 16943}
+	c := &Conn{dbType: "Microsoft SQL Server"}
+	if !c.isSchemaChangeError(cursorChanged) {
+		t.Error("expected SQL Server error 16943 to be a schema change")
+	}
+	if c.isSchemaChangeError(&Error{SQLState: "24000"}) {
+		t.Error("an invalid cursor state should not be a schema change by default")
+	}
+	if (&Conn{dbType: "PostgreSQL"}).isSchemaChangeError(cursorChanged) {
+		t.Error("native error codes should only apply to their database")
+	}
+
+	c = &Conn{dbType: "Exasol", quirk: &Quirk{ReprepareErrors: []int32{42}}}
+	if !c.isSchemaChangeError(Errors{{SQLState: "01000"}, {SQLState: "HY000", NativeError: 42}}) {
+		t.Error("expected the quirk's native error code to be a schema change")
+	}
+	if c.isSchemaChangeError(&Error{SQLState: "HY000", NativeError: 43}) {
+		t.Error("unexpected schema change for an unlisted native error code")
+	}
+
+	// A quirk can opt in to treating an invalid cursor state as a schema change
+	c = &Conn{dbType: "Exasol", quirk: &Quirk{ReprepareOnInvalidCursorState: true}}
+	if !c.isSchemaChangeError(Errors{{SQLState: "01000"}, {SQLState: "24000"}}) {
+		t.Error("expected an invalid cursor state to be a schema change with the quirk")
+	}
+}
+
+// fakeSchemaChange makes SQLExecute fail with a schema change error (26000)
+// while *failures is positive, decrementing it, and records the prepare and
+// execute calls
+func fakeSchemaChange(t *testing.T) (calls *[]string, failures *int) {
+	t.Helper()
+	origExecute, origPrepare, origFree := sqlExecute, sqlPrepare, sqlFreeStmt
+	origRec, origField, savedWide := sqlGetDiagRec, sqlGetDiagField, wideAPI
+	t.Cleanup(func() {
+		sqlExecute, sqlPrepare, sqlFreeStmt = origExecute, origPrepare, origFree
+		sqlGetDiagRec, sqlGetDiagField, wideAPI = origRec, origField, savedWide
+	})
+	wideAPI = false
+
+	calls, failures = new([]string), new(int)
+	sqlFreeStmt = func(SQLHSTMT, SQLUSMALLINT) SQLRETURN { return SQL_SUCCESS }
+	sqlPrepare = func(SQLHSTMT, *byte, SQLINTEGER) SQLRETURN {
+		*calls = append(*calls, "prepare")
+		return SQL_SUCCESS
+	}
+	sqlExecute = func(SQLHSTMT) SQLRETURN {
+		*calls = append(*calls, "execute")
+		if *failures > 0 {
+			*failures--
+			return SQL_ERROR
+		}
+		return SQL_SUCCESS
+	}
+	sqlGetDiagField = func(SQLSMALLINT, SQLHANDLE, SQLSMALLINT, SQLSMALLINT, uintptr, SQLSMALLINT, *SQLSMALLINT) SQLRETURN {
+		return SQL_ERROR
+	}
+	sqlGetDiagRec = func(handleType SQLSMALLINT, handle SQLHANDLE, recNum SQLSMALLINT, sqlState *byte, nativeError *SQLINTEGER, msgText *byte, bufferLen SQLSMALLINT, textLen *SQLSMALLINT) SQLRETURN {
+		if recNum > 1 {
+			return SQL_NO_DATA
+		}
+		copy(unsafe.Slice(sqlState, 6), "26000\x00")
+		*msgText = 0
+		*textLen = 0
+		return SQL_SUCCESS
+	}
+	return calls, failures
+}
+
+func TestStmt_ReprepareAfterSchemaChange(t *testing.T) {
+	calls, failures := fakeSchemaChange(t)
+	*failures = 1
+
+	s := &Stmt{conn: &Conn{}, query: "SELECT a FROM t"}
+	s.resultCols = &resultColumns{}
+	ret, err := s.executeChecked(context.Background(), nil)
+	if err != nil || ret != SQL_SUCCESS {
+		t.Fatalf("executeChecked() = %v, %v; want success after preparing again", ret, err)
+	}
+	if !reflect.DeepEqual(*calls, []string{"execute", "prepare", "execute"}) {
+		t.Errorf("expected the statement to be prepared again and executed once more, got %v", *calls)
+	}
+	if s.resultCols != nil {
+		t.Error("expected the cached result columns to be cleared")
+	}
+
+	// A second failure is reported rather than retried again
+	*calls, *failures = nil, 2
+	if _, err := s.executeChecked(context.Background(), nil); !IsSchemaChangeError(err) {
+		t.Errorf("expected the schema change error after one retry, got %v", err)
+	}
+	if len(*calls) != 3 {
+		t.Errorf("expected a single retry, got %v", *calls)
+	}
+}
+
+func TestStmt_ReprepareAfterSchemaChange_InTransaction(t *testing.T) {
+	calls, failures := fakeSchemaChange(t)
+	*failures = 1
+
+	// PostgreSQL has aborted the transaction, so the original error is returned
+	s := &Stmt{conn: &Conn{inTx: true}, query: "SELECT a FROM t"}
+	if _, err := s.executeChecked(context.Background(), nil); !IsSchemaChangeError(err) {
+		t.Errorf("expected the schema change error inside a transaction, got %v", err)
+	}
+	if !reflect.DeepEqual(*calls, []string{"execute"}) {
+		t.Errorf("expected no retry inside a transaction, got %v", *calls)
+	}
+}
+
+// =============================================================================
+// Server Info Tests (serverinfo.go)
+// =============================================================================
+
+func TestConn_ServerInfo(t *testing.T) {
+	orig := sqlGetInfo
+	t.Cleanup(func() { sqlGetInfo = orig })
+	keywords := strings.Repeat("KW,", 200) + "LAST"
+	strs := map[SQLUSMALLINT]string{
+		SQL_DBMS_NAME:             "Microsoft SQL Server",
+		SQL_DBMS_VER:              "16.00.1000",
+		SQL_IDENTIFIER_QUOTE_CHAR: `"`,
+		SQL_KEYWORDS:              keywords,
+	}
+	calls := 0
+	sqlGetInfo = func(_ SQLHDBC, infoType SQLUSMALLINT, value uintptr, bufferLength SQLSMALLINT, strLen *SQLSMALLINT) SQLRETURN {
+		calls++
+		switch infoType {
+		case SQL_MAX_COLUMN_NAME_LEN:
+			*(*SQLUSMALLINT)(unsafe.Add(nil, value)) = 128
+			return SQL_SUCCESS
+		case SQL_MAX_STATEMENT_LEN:
+			*(*SQLUINTEGER)(unsafe.Add(nil, value)) = 65536
+			return SQL_SUCCESS
+		}
+		s, ok := strs[infoType]
+		if !ok {
+			return SQL_ERROR
+		}
+		*strLen = SQLSMALLINT(len(s))
+		if bufferLength > 0 {
+			out := unsafe.Slice((*byte)(unsafe.Add(nil, value)), int(bufferLength))
+			n := copy(out[:len(out)-1], s)
+			out[n] = 0
+			if n < len(s) {
+				return SQL_SUCCESS_WITH_INFO
+			}
+		}
+		return SQL_SUCCESS
+	}
+
+	c := &Conn{dbc: 1, identifierCase: IdentifierCaseMixed}
+	info, err := c.ServerInfo()
+	if err != nil {
+		t.Fatalf("ServerInfo() error: %v", err)
+	}
+	if info.DBMSName != "Microsoft SQL Server" || info.DBMSVersion != "16.00.1000" || info.IdentifierQuote != `"` {
+		t.Errorf("unexpected strings %+v", info)
+	}
+	if info.DriverName != "" || info.MaxTableNameLen != 0 {
+		t.Errorf("values the driver does not report should be zero, got %q and %d", info.DriverName, info.MaxTableNameLen)
+	}
+	if info.MaxColumnNameLen != 128 || info.MaxStatementLen != 65536 || info.IdentifierCase != IdentifierCaseMixed {
+		t.Errorf("unexpected limits %+v", info)
+	}
+	if len(info.Keywords) != 201 || info.Keywords[200] != "LAST" {
+		t.Errorf("expected all 201 keywords of the long SQL_KEYWORDS value, got %d", len(info.Keywords))
+	}
+
+	// Later calls are served from the cache
+	n := calls
+	info.Keywords[0] = "CHANGED"
+	again, _ := c.ServerInfo()
+	if calls != n {
+		t.Errorf("expected the cached values, got %d more SQLGetInfo calls", calls-n)
+	}
+	if again.Keywords[0] != "KW" {
+		t.Error("changing the returned keywords should not change the cache")
+	}
+
+	c.closed = true
+	if _, err := c.ServerInfo(); err != driver.ErrBadConn {
+		t.Errorf("expected driver.ErrBadConn on a closed connection, got %v", err)
+	}
+}
+
+// =============================================================================
+// Columnar Fetch Tests (columnar.go)
+// =============================================================================
+
+func TestRows_NextBatch_BlockFetch(t *testing.T) {
+	const size = 3
+	newColumn := func(cType SQLSMALLINT, elemSize int) blockColumn {
+		return blockColumn{cType: cType, elemSize: elemSize, data: make([]byte, size*elemSize), ind: make([]SQLLEN, size)}
+	}
+	ints := newColumn(SQL_C_SBIGINT, 8)
+	floats := newColumn(SQL_C_DOUBLE, 8)
+	tsSize := int(unsafe.Sizeof(SQL_TIMESTAMP_STRUCT{}))
+	timestamps := newColumn(SQL_C_TIMESTAMP, tsSize)
+	chars := newColumn(SQL_C_CHAR, 8)
+	for i := 0; i < size; i++ {
+		binary.LittleEndian.PutUint64(ints.data[i*8:], uint64(i+1))
+		binary.LittleEndian.PutUint64(floats.data[i*8:], math.Float64bits(float64(i)+0.5))
+		*(*SQL_TIMESTAMP_STRUCT)(unsafe.Pointer(&timestamps.data[i*tsSize])) = SQL_TIMESTAMP_STRUCT{Year: 2024, Month: 1, Day: SQLUSMALLINT(i + 1)}
+		copy(chars.data[i*8:], "row\x00")
+		ints.ind[i], floats.ind[i], timestamps.ind[i], chars.ind[i] = 8, 8, SQLLEN(tsSize), 3
+	}
+	floats.ind[2] = SQLLEN(SQL_NULL_DATA)
+
+	block := &blockFetch{
+		columns:  []blockColumn{ints, floats, timestamps, chars},
+		statuses: []SQLUSMALLINT{SQL_ROW_SUCCESS, SQL_ROW_NOROW, SQL_ROW_SUCCESS},
+	}
+	orig := sqlFetch
+	t.Cleanup(func() { sqlFetch = orig })
+	fetches := 0
+	sqlFetch = func(SQLHSTMT) SQLRETURN {
+		if fetches++; fetches > 1 {
+			return SQL_NO_DATA
+		}
+		block.fetched = size
+		return SQL_SUCCESS
+	}
+
+	r := &Rows{
+		stmt:         &Stmt{conn: &Conn{}},
+		columns:      []string{"id", "amount", "created_at", "name"},
+		colTypes:     []SQLSMALLINT{SQL_INTEGER, SQL_DOUBLE, SQL_TYPE_TIMESTAMP, SQL_VARCHAR},
+		block:        block,
+		blockChecked: true,
+	}
+	var batch ColumnBatch
+	if err := r.NextBatch(&batch); err != nil {
+		t.Fatalf("NextBatch() error: %v", err)
+	}
+	if batch.Len != 2 {
+		t.Fatalf("expected the 2 rows of the rowset, got %d", batch.Len)
+	}
+	kinds := []ColumnKind{ColumnInt64, ColumnFloat64, ColumnTime, ColumnValues}
+	for i, want := range kinds {
+		if batch.Columns[i].Kind != want {
+			t.Errorf("column %d: kind %d, want %d", i, batch.Columns[i].Kind, want)
+		}
+	}
+	if !reflect.DeepEqual(batch.Columns[0].Int64, []int64{1, 3}) {
+		t.Errorf("Int64 = %v, want [1 3]", batch.Columns[0].Int64)
+	}
+	if !reflect.DeepEqual(batch.Columns[1].Float64, []float64{0.5, 0}) || !reflect.DeepEqual(batch.Columns[1].Nulls, []bool{false, true}) {
+		t.Errorf("Float64 = %v, Nulls = %v, want [0.5 0] with the second NULL", batch.Columns[1].Float64, batch.Columns[1].Nulls)
+	}
+	if want := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC); len(batch.Columns[2].Time) != 2 || !batch.Columns[2].Time[1].Equal(want) {
+		t.Errorf("Time = %v, want the second to be %v", batch.Columns[2].Time, want)
+	}
+	if !reflect.DeepEqual(batch.Columns[3].Values, []driver.Value{"row", "row"}) {
+		t.Errorf("Values = %v", batch.Columns[3].Values)
+	}
+
+	if err := r.NextBatch(&batch); err != io.EOF {
+		t.Errorf("expected io.EOF after the last rowset, got %v", err)
+	}
+}
+
+func TestColumnVector_Append(t *testing.T) {
+	v := ColumnVector{Kind: ColumnInt64}
+	if err := v.append(int64(7)); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.append(nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v.Int64, []int64{7, 0}) || !reflect.DeepEqual(v.Nulls, []bool{false, true}) {
+		t.Errorf("Int64 = %v, Nulls = %v", v.Int64, v.Nulls)
+	}
+
+	tv := ColumnVector{Kind: ColumnTime}
+	if err := tv.append(OutOfRangeTime{Year: 10000, Month: 1, Day: 1}); err == nil {
+		t.Error("expected an error for a time outside the range of a time column")
+	}
+}
+
+func TestRows_ColumnSQLTypeAndKind(t *testing.T) {
+	r := &Rows{
+		stmt:     &Stmt{conn: &Conn{}},
+		columns:  []string{"id", "born", "name"},
+		colTypes: []SQLSMALLINT{SQL_INTEGER, SQL_TYPE_DATE, SQL_VARCHAR},
+	}
+	if got := r.ColumnSQLType(1); got != SQL_TYPE_DATE {
+		t.Errorf("ColumnSQLType(1) = %d, want SQL_TYPE_DATE", got)
+	}
+	if got := r.ColumnSQLType(3); got != 0 {
+		t.Errorf("ColumnSQLType(3) = %d, want 0 for an out of range index", got)
+	}
+	kinds := []ColumnKind{ColumnInt64, ColumnTime, ColumnValues}
+	for i, want := range kinds {
+		if got := r.ColumnKind(i); got != want {
+			t.Errorf("ColumnKind(%d) = %d, want %d", i, got, want)
+		}
+	}
+	if got := r.ColumnKind(-1); got != ColumnValues {
+		t.Errorf("ColumnKind(-1) = %d, want ColumnValues", got)
+	}
+}
+
+// =============================================================================
+// Copy Mode Tests (copymode.go)
+// =============================================================================
+
+func TestRows_ReadRow_CopyAlways(t *testing.T) {
+	r := &Rows{
+		stmt:      &Stmt{conn: &Conn{}},
+		columns:   []string{"data"},
+		rowValues: []driver.Value{[]byte("abc")},
+	}
+	first := make([]driver.Value, 1)
+	if err := r.readRow(first); err != nil {
+		t.Fatalf("readRow() error: %v", err)
+	}
+	first[0].([]byte)[0] = 'X'
+	again := make([]driver.Value, 1)
+	if err := r.readRow(again); err != nil {
+		t.Fatalf("readRow() error: %v", err)
+	}
+	if got := string(again[0].([]byte)); got != "abc" {
+		t.Errorf("row read again = %q, want abc unchanged by the caller's edit", got)
+	}
+
+	r.stmt.conn.copyMode = CopyBorrowUntilNext
+	borrowed := make([]driver.Value, 1)
+	if err := r.readRow(borrowed); err != nil {
+		t.Fatalf("readRow() error: %v", err)
+	}
+	if &borrowed[0].([]byte)[0] != &r.rowValues[0].([]byte)[0] {
+		t.Error("expected CopyBorrowUntilNext to return the kept value without copying")
+	}
+}
+
+func TestRows_ReadRow_CopiesOnce(t *testing.T) {
+	// The kept row borrows the rowset; only the value handed out is a copy
+	col := blockColumn{cType: SQL_C_BINARY, elemSize: 4, data: []byte("abcdwxyz"), ind: []SQLLEN{3, 4}}
+	r := &Rows{
+		stmt:     &Stmt{conn: &Conn{}},
+		columns:  []string{"data"},
+		colTypes: []SQLSMALLINT{SQL_VARBINARY},
+		raw:      []bool{true},
+		block:    &blockFetch{columns: []blockColumn{col}},
+	}
+	dest := make([]driver.Value, 1)
+	if err := r.readRow(dest); err != nil || !reflect.DeepEqual(dest[0], []byte("abc")) {
+		t.Fatalf("readRow() = %#v (%v), want abc", dest[0], err)
+	}
+	if &r.rowValues[0].([]byte)[0] != &col.data[0] {
+		t.Error("expected the kept value to borrow the rowset")
+	}
+	if &dest[0].([]byte)[0] == &col.data[0] {
+		t.Error("expected CopyAlways to copy the value handed out")
+	}
+	if r.readingRow {
+		t.Error("expected readingRow to be cleared after the row is read")
+	}
+	if v, _ := r.blockValue(0); &v.([]byte)[0] == &col.data[0] {
+		t.Error("expected values read outside readRow to be copied under CopyAlways")
+	}
+}
+
+func TestRows_BlockValue_CopyMode(t *testing.T) {
+	col := blockColumn{cType: SQL_C_BINARY, elemSize: 4, data: []byte("abcdwxyz"), ind: []SQLLEN{3, 4}}
+	r := &Rows{
+		stmt:     &Stmt{conn: &Conn{}},
+		columns:  []string{"data"},
+		colTypes: []SQLSMALLINT{SQL_VARBINARY},
+		block:    &blockFetch{columns: []blockColumn{col}},
+	}
+	v, err := r.blockValue(0)
+	if err != nil || !reflect.DeepEqual(v, []byte("abc")) {
+		t.Fatalf("blockValue() = %#v (%v), want abc", v, err)
+	}
+	if &v.([]byte)[0] == &col.data[0] {
+		t.Error("expected CopyAlways to copy the value out of the rowset")
+	}
+
+	r.stmt.conn.copyMode = CopyBorrowUntilNext
+	v, err = r.blockValue(0)
+	if err != nil || !reflect.DeepEqual(v, []byte("abc")) {
+		t.Fatalf("blockValue() = %#v (%v), want abc", v, err)
+	}
+	b := v.([]byte)
+	if &b[0] != &col.data[0] {
+		t.Error("expected CopyBorrowUntilNext to return the rowset's memory")
+	}
+	_ = append(b, '!')
+	if string(col.data) != "abcdwxyz" {
+		t.Errorf("appending to a borrowed value changed the rowset to %q", col.data)
+	}
+}
+
+func TestRows_GetBytes_CopyMode(t *testing.T) {
+	orig := sqlGetData
+	t.Cleanup(func() { sqlGetData = orig })
+	values := []string{"first", "other"}
+	calls := 0
+	sqlGetData = func(_ SQLHSTMT, _ SQLUSMALLINT, _ SQLSMALLINT, targetValue uintptr, bufferLen SQLLEN, ind *SQLLEN) SQLRETURN {
+		value := values[calls%len(values)]
+		calls++
+		copy(unsafe.Slice((*byte)(unsafe.Add(nil, targetValue)), bufferLen), value)
+		*ind = SQLLEN(len(value))
+		return SQL_SUCCESS
+	}
+
+	r := &Rows{
+		stmt:     &Stmt{conn: &Conn{}},
+		columns:  []string{"data"},
+		colTypes: []SQLSMALLINT{SQL_VARBINARY},
+		colSizes: []SQLULEN{8},
+	}
+	a, _ := r.getBytes(1, 8)
+	b, _ := r.getBytes(1, 8)
+	if string(a.([]byte)) != "first" || string(b.([]byte)) != "other" {
+		t.Errorf("CopyAlways: got %q and %q, want each row's own value", a, b)
+	}
+
+	r.stmt.conn.copyMode = CopyBorrowUntilNext
+	a, _ = r.getBytes(1, 8)
+	b, _ = r.getBytes(1, 8)
+	if string(b.([]byte)) != "other" {
+		t.Errorf("CopyBorrowUntilNext: got %q, want other", b)
+	}
+	if &a.([]byte)[0] != &b.([]byte)[0] {
+		t.Error("expected CopyBorrowUntilNext to reuse the column's buffer from row to row")
+	}
+	if n := len(b.([]byte)); cap(b.([]byte)) != n {
+		t.Errorf("borrowed value has capacity %d beyond its length %d", cap(b.([]byte)), n)
+	}
+}
@@ -10,3 +10,9 @@ import (
 func loadODBCLibrary(libPath string) (uintptr, error) {
 	return purego.Dlopen(libPath, purego.RTLD_NOW|purego.RTLD_GLOBAL)
 }
+
+// hasSymbol reports whether the loaded library exports the named symbol
+func hasSymbol(lib uintptr, name string) bool {
+	addr, err := purego.Dlsym(lib, name)
+	return err == nil && addr != 0
+}
@@ -14,3 +14,9 @@ func loadODBCLibrary(libPath string) (uintptr, error) {
 	}
 	return uintptr(handle), nil
 }
+
+// hasSymbol reports whether the loaded library exports the named symbol
+func hasSymbol(lib uintptr, name string) bool {
+	addr, err := syscall.GetProcAddress(syscall.Handle(lib), name)
+	return err == nil && addr != 0
+}
@@ -0,0 +1,283 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Page is one page of rows read by a Pager
+type Page struct {
+	Number  int // 1-based page number
+	Columns []string
+	Rows    [][]driver.Value
+}
+
+// Pager reads the result of a query one page at a time. Where the driver
+// supports static cursors with absolute positioning, the query is executed
+// once and the cursor is held open between pages, so any page can be read in
+// any order. Otherwise, if a key column is configured, each page is a separate
+// query that continues after the key of the previous page (keyset pagination),
+// and pages can only be read in order.
+//
+// A held cursor keeps the statement open until Close; on drivers without
+// multiple active result sets, the connection cannot run other statements
+// in the meantime.
+type Pager struct {
+	conn     *Conn
+	query    string
+	args     []interface{}
+	pageSize int
+
+	// Held cursor (nil in keyset mode)
+	rows *Rows
+
+	// Keyset pagination
+	key     string
+	keyName string // key with identifier quotes removed, to find it in results
+	lastKey interface{}
+	hasKey  bool // whether lastKey is set and pages start after it
+
+	page int // Number of the last page read
+	done bool
+}
+
+// PagerOption configures a Pager
+type PagerOption func(*Pager)
+
+// WithPageKey sets the column used for keyset pagination when the driver
+// does not support scrollable cursors. It must be unique and non-null in the
+// query's result. The query is wrapped as a derived table and ordered by the
+// key, so it must not have its own ORDER BY.
+func WithPageKey(column string) PagerOption {
+	return func(p *Pager) {
+		p.key = column
+	}
+}
+
+// WithPageArgs sets the arguments of the query's parameter markers
+func WithPageArgs(args ...interface{}) PagerOption {
+	return func(p *Pager) {
+		p.args = args
+	}
+}
+
+// Paginate returns a Pager that reads the result of query pageSize rows at a
+// time, using a held static cursor where the driver supports one, or keyset
+// pagination on the column set with WithPageKey otherwise. It returns an
+// error if neither is available. Close the pager when done.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    pager, err := godbc.Paginate(ctx, dc.(*godbc.Conn), "SELECT id, name FROM users WHERE active = ?", 100,
+//	        godbc.WithPageArgs(true), godbc.WithPageKey("id"))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    defer pager.Close()
+//	    page, err := pager.Next(ctx)
+//	    ...
+//	})
+func Paginate(ctx context.Context, conn *Conn, query string, pageSize int, opts ...PagerOption) (*Pager, error) {
+	p := &Pager{
+		conn:     conn,
+		query:    query,
+		pageSize: pageSize,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.pageSize < 1 {
+		return nil, fmt.Errorf("invalid page size %d", p.pageSize)
+	}
+	if p.key != "" {
+		var err error
+		if p.keyName, err = unquoteColumnName(p.key); err != nil {
+			return nil, err
+		}
+	}
+
+	held, err := p.openCursor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !held && p.key == "" {
+		return nil, fmt.Errorf("driver does not support scrollable cursors; use WithPageKey for keyset pagination")
+	}
+	return p, nil
+}
+
+// Scrollable reports whether the pager holds a scrollable cursor, so pages can
+// be read in any order with Page
+func (p *Pager) Scrollable() bool {
+	return p.rows != nil
+}
+
+// Next reads the next page. It returns io.EOF when there are no more rows.
+func (p *Pager) Next(ctx context.Context) (*Page, error) {
+	if p.rows != nil {
+		return p.Page(ctx, p.page+1)
+	}
+	if p.done {
+		return nil, io.EOF
+	}
+	return p.keysetPage(ctx)
+}
+
+// Page reads page n (1-based). It requires a held cursor (see Scrollable) and
+// returns io.EOF for pages past the end of the result.
+func (p *Pager) Page(ctx context.Context, n int) (*Page, error) {
+	if p.rows == nil {
+		return nil, fmt.Errorf("page %d: reading pages out of order requires a scrollable cursor", n)
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("invalid page number %d", n)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := p.rows.Absolute(int64(n-1)*int64(p.pageSize) + 1); err != nil {
+		return nil, err
+	}
+	page := &Page{Number: n, Columns: p.rows.Columns()}
+	for len(page.Rows) < p.pageSize {
+		dest := make([]driver.Value, len(page.Columns))
+		var err error
+		if len(page.Rows) == 0 {
+			err = p.rows.GetRowData(dest)
+		} else {
+			err = p.rows.Next(dest)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		page.Rows = append(page.Rows, dest)
+	}
+	p.page = n
+	return page, nil
+}
+
+// Close releases the held cursor, if any
+func (p *Pager) Close() error {
+	if p.rows == nil {
+		return nil
+	}
+	err := p.rows.Close()
+	p.rows = nil
+	return err
+}
+
+// openCursor executes the query with a static cursor, reporting whether the
+// driver supports absolute positioning and kept the cursor scrollable
+func (p *Pager) openCursor(ctx context.Context) (bool, error) {
+	if !p.conn.supportsStaticAbsolute() {
+		return false, nil
+	}
+	sr, err := p.conn.QueryScrollable(ctx, p.query, CursorStatic, namedValues(p.args)...)
+	if err != nil {
+		return false, err
+	}
+	rows := sr.(*Rows)
+
+	// Drivers may substitute a forward-only cursor when executing
+	var cursorType SQLULEN
+	ret := GetStmtAttr(rows.stmt.stmt, SQL_ATTR_CURSOR_TYPE, uintptr(unsafe.Pointer(&cursorType)), 0, nil)
+	if !IsSuccess(ret) || cursorType == SQL_CURSOR_FORWARD_ONLY {
+		rows.Close()
+		return false, nil
+	}
+	p.rows = rows
+	return true, nil
+}
+
+// supportsStaticAbsolute reports whether the driver supports SQL_FETCH_ABSOLUTE
+// on static cursors
+func (c *Conn) supportsStaticAbsolute() bool {
+	attrs, ret := GetInfoBitmask(c.dbc, SQL_STATIC_CURSOR_ATTRIBUTES1)
+	if !IsSuccess(ret) {
+		return false
+	}
+	return attrs&SQL_CA1_ABSOLUTE != 0
+}
+
+// keysetPage reads the page after the last key read
+func (p *Pager) keysetPage(ctx context.Context) (*Page, error) {
+	args := p.args
+	if p.hasKey {
+		args = append(append([]interface{}(nil), p.args...), p.lastKey)
+	}
+	rows, err := p.conn.QueryContext(ctx, p.keysetQuery(), namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := rows.Columns()
+	keyIndex := -1
+	for i, col := range columns {
+		if strings.EqualFold(col, p.keyName) {
+			keyIndex = i
+			break
+		}
+	}
+	if keyIndex < 0 {
+		return nil, fmt.Errorf("key column %s not found in result", p.key)
+	}
+
+	page := &Page{Number: p.page + 1, Columns: columns}
+	for len(page.Rows) < p.pageSize {
+		dest := make([]driver.Value, len(columns))
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		page.Rows = append(page.Rows, dest)
+	}
+
+	if len(page.Rows) < p.pageSize {
+		p.done = true
+	}
+	if len(page.Rows) == 0 {
+		return nil, io.EOF
+	}
+	p.lastKey = page.Rows[len(page.Rows)-1][keyIndex]
+	p.hasKey = true
+	p.page = page.Number
+	return page, nil
+}
+
+// keysetQuery wraps the query as a derived table limited to the rows of the
+// next page in key order
+func (p *Pager) keysetQuery() string {
+	limit := strconv.Itoa(p.pageSize)
+	style := p.conn.paginationStyle()
+
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	if style == paginationTop {
+		sb.WriteString("TOP " + limit + " ")
+	}
+	sb.WriteString("* FROM (" + strings.TrimRight(p.query, "; \t\r\n") + ") godbc_page")
+	if p.hasKey {
+		sb.WriteString(" WHERE " + p.key + " > ?")
+	}
+	sb.WriteString(" ORDER BY " + p.key)
+	switch style {
+	case paginationFetchFirst:
+		sb.WriteString(" FETCH FIRST " + limit + " ROWS ONLY")
+	case paginationLimit:
+		sb.WriteString(" LIMIT " + limit)
+	}
+	return sb.String()
+}
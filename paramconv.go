@@ -0,0 +1,144 @@
+package godbc
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ODBCBinding describes how a parameter value of an application type is bound,
+// as returned by a converter registered with RegisterConverter
+type ODBCBinding struct {
+	// Value is bound in place of the original value: nil for NULL, or any
+	// parameter type the driver binds natively, such as string, []byte, int64,
+	// float64, time.Time, GUID or Decimal
+	Value interface{}
+
+	// SQLType, if set, overrides the SQL type Value is bound as, e.g.
+	// SQL_DECIMAL for the text of a decimal or SQL_TYPE_DATE for a date
+	SQLType SQLSMALLINT
+
+	// ColumnSize and DecimalDigits override the column size and decimal digits
+	// when SQLType is set (0 = keep those of Value)
+	ColumnSize    SQLULEN
+	DecimalDigits SQLSMALLINT
+
+	// CType, if set, binds Value, which must then be a []byte, unchanged as
+	// this C type, e.g. the bytes of a SQL_NUMERIC_STRUCT as SQL_C_NUMERIC.
+	// SQLType must be set with it. Such bindings cannot be used in ExecBatch.
+	CType SQLSMALLINT
+}
+
+// converterFunc converts a parameter value to its binding
+type converterFunc func(value interface{}) (ODBCBinding, error)
+
+var (
+	convertersMu sync.RWMutex
+
+	// converters maps application types to their registered converters
+	converters = map[reflect.Type]converterFunc{}
+)
+
+// RegisterConverter teaches the driver to bind parameters of type t, such as
+// decimal.Decimal, uuid.UUID or civil.Date, which would otherwise be bound as
+// the text fmt.Sprint produces. fn is called with each parameter value of
+// exactly type t and returns its binding. A converter takes precedence over the
+// driver's own conversion of t. Registering a type again replaces its
+// converter, and a nil fn removes it. Converters apply to all connections.
+//
+// Example:
+//
+//	godbc.RegisterConverter(reflect.TypeOf(uuid.UUID{}), func(v any) (godbc.ODBCBinding, error) {
+//	    return godbc.ODBCBinding{Value: godbc.GUID(v.(uuid.UUID))}, nil
+//	})
+//	godbc.RegisterConverter(reflect.TypeOf(decimal.Decimal{}), func(v any) (godbc.ODBCBinding, error) {
+//	    d := v.(decimal.Decimal)
+//	    return godbc.ODBCBinding{Value: d.String(), SQLType: godbc.SQL_DECIMAL, ColumnSize: 38, DecimalDigits: godbc.SQLSMALLINT(-d.Exponent())}, nil
+//	})
+func RegisterConverter(t reflect.Type, fn func(value interface{}) (ODBCBinding, error)) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	if fn == nil {
+		delete(converters, t)
+		return
+	}
+	converters[t] = fn
+}
+
+// lookupConverter returns the converter registered for the type of value, or nil
+func lookupConverter(value interface{}) converterFunc {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	if len(converters) == 0 {
+		return nil
+	}
+	return converters[reflect.TypeOf(value)]
+}
+
+// convertRegistered converts a value with its registered converter to ODBC
+// binding parameters, as convertToODBC does
+func convertRegistered(value interface{}, conv converterFunc) (interface{}, SQLSMALLINT, SQLSMALLINT, SQLULEN, SQLSMALLINT, SQLLEN, error) {
+	b, err := conv(value)
+	if err != nil {
+		return nil, 0, 0, 0, 0, 0, fmt.Errorf("convert %T: %w", value, err)
+	}
+	if b.CType != 0 {
+		raw, ok := b.Value.([]byte)
+		if !ok && b.Value != nil {
+			return nil, 0, 0, 0, 0, 0, fmt.Errorf("convert %T: a binding with a C type needs a []byte value, got %T", value, b.Value)
+		}
+		if b.SQLType == 0 {
+			return nil, 0, 0, 0, 0, 0, fmt.Errorf("convert %T: a binding with a C type needs a SQL type", value)
+		}
+		if raw == nil {
+			return nil, b.CType, b.SQLType, b.ColumnSize, b.DecimalDigits, SQL_NULL_DATA, nil
+		}
+		colSize := b.ColumnSize
+		if colSize == 0 {
+			colSize = SQLULEN(len(raw))
+		}
+		return raw, b.CType, b.SQLType, colSize, b.DecimalDigits, SQLLEN(len(raw)), nil
+	}
+
+	buf, cType, sqlType, colSize, decDigits, length, err := convertValueToODBC(b.Value)
+	if err != nil || b.SQLType == 0 {
+		return buf, cType, sqlType, colSize, decDigits, length, err
+	}
+	if b.ColumnSize != 0 {
+		colSize = b.ColumnSize
+	}
+	if b.DecimalDigits != 0 {
+		decDigits = b.DecimalDigits
+	}
+	return buf, cType, b.SQLType, colSize, decDigits, length, nil
+}
+
+// convertColumnValues converts the values of a batch column with registered
+// converters, returning the bound values and the SQL type overrides of the
+// first converted value, or the values themselves if none is converted
+func convertColumnValues(values []interface{}) ([]interface{}, *ODBCBinding, error) {
+	var converted []interface{}
+	var first *ODBCBinding
+	for i, v := range values {
+		conv := lookupConverter(v)
+		if conv == nil {
+			continue
+		}
+		b, err := conv(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("convert %T: %w", v, err)
+		}
+		if b.CType != 0 {
+			return nil, nil, fmt.Errorf("convert %T: bindings with a C type cannot be used in a batch", v)
+		}
+		if converted == nil {
+			converted = append([]interface{}(nil), values...)
+			first = &b
+		}
+		converted[i] = b.Value
+	}
+	if converted == nil {
+		return values, nil, nil
+	}
+	return converted, first, nil
+}
@@ -32,6 +32,8 @@ type NamedParams struct {
 //   - @name  (SQL Server style)
 //   - $name  (PostgreSQL style - not $1 which is positional)
 //
+// PostgreSQL casts such as value::type and SQL Server variables such as
+// @@ROWCOUNT are left as they are.
 // Returns nil if no named parameters are found (query uses positional ? only).
 // The original query is preserved if it contains only ? placeholders.
 func ParseNamedParams(query string) *NamedParams {
@@ -132,6 +134,14 @@ func ParseNamedParams(query string) *NamedParams {
 			continue
 		}
 
+		// Skip PostgreSQL casts (value::type) and SQL Server system variables
+		// (@@ROWCOUNT), which are not parameters
+		if (c == ':' || c == '@') && i+1 < len(query) && query[i+1] == c {
+			output = append(output, c, c)
+			i += 2
+			continue
+		}
+
 		// Check for named parameter
 		if (c == ':' || c == '@' || c == '$') && i+1 < len(query) && isIdentStart(query[i+1]) {
 			// Extract the parameter name
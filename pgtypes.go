@@ -0,0 +1,148 @@
+package godbc
+
+import (
+	"strings"
+)
+
+// BitString is a PostgreSQL bit or bit varying value, one '0' or '1' per bit
+// in column order. It is returned for bit/varbit columns on PostgreSQL and can
+// be passed back as a parameter.
+type BitString string
+
+// BitStringFromBools builds a BitString with one bit per element
+func BitStringFromBools(bits []bool) BitString {
+	buf := make([]byte, len(bits))
+	for i, bit := range bits {
+		buf[i] = '0'
+		if bit {
+			buf[i] = '1'
+		}
+	}
+	return BitString(buf)
+}
+
+// Bools returns the bits as a slice, true for each '1'
+func (b BitString) Bools() []bool {
+	bits := make([]bool, len(b))
+	for i := 0; i < len(b); i++ {
+		bits[i] = b[i] == '1'
+	}
+	return bits
+}
+
+// pgValueKind identifies PostgreSQL types that psqlODBC returns as text
+// through the default character path
+type pgValueKind int
+
+const (
+	pgValueDefault pgValueKind = iota
+	pgValueBitString
+	pgValueBoolArray
+)
+
+// pgValueKindOf maps a native type name reported by psqlODBC to its value kind
+func pgValueKindOf(typeName string) pgValueKind {
+	switch strings.ToLower(strings.TrimSpace(typeName)) {
+	case "bit", "varbit", "bit varying":
+		return pgValueBitString
+	case "_bool", "bool[]", "boolean[]":
+		return pgValueBoolArray
+	default:
+		return pgValueDefault
+	}
+}
+
+// pgValueKinds returns the value kind of each column for PostgreSQL connections,
+// or nil when the database is not PostgreSQL or no column needs decoding
+func pgValueKinds(dbType string, nativeTypes []string) []pgValueKind {
+	if !strings.Contains(strings.ToLower(dbType), "postgresql") {
+		return nil
+	}
+	var kinds []pgValueKind
+	for i, name := range nativeTypes {
+		if kind := pgValueKindOf(name); kind != pgValueDefault {
+			if kinds == nil {
+				kinds = make([]pgValueKind, len(nativeTypes))
+			}
+			kinds[i] = kind
+		}
+	}
+	return kinds
+}
+
+// decodePGValue converts the text psqlODBC returns for bit strings and boolean
+// arrays to BitString and []bool. Values that do not parse, such as arrays with
+// NULL elements or more than one dimension, are returned unchanged.
+func decodePGValue(value interface{}, kind pgValueKind) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	switch kind {
+	case pgValueBitString:
+		if bits, ok := parseBitString(s); ok {
+			return bits
+		}
+	case pgValueBoolArray:
+		if bools, ok := parseBoolArray(s); ok {
+			return bools
+		}
+	}
+	return value
+}
+
+// parseBitString validates a bit string of '0' and '1' characters
+func parseBitString(s string) (BitString, bool) {
+	s = strings.TrimSpace(s)
+	for i := 0; i < len(s); i++ {
+		if s[i] != '0' && s[i] != '1' {
+			return "", false
+		}
+	}
+	return BitString(s), true
+}
+
+// parseBoolArray parses a one-dimensional PostgreSQL boolean array literal such as {t,f,t}
+func parseBoolArray(s string) ([]bool, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, false
+	}
+	body := strings.TrimSpace(s[1 : len(s)-1])
+	if body == "" {
+		return []bool{}, true
+	}
+
+	elems := strings.Split(body, ",")
+	bools := make([]bool, len(elems))
+	for i, elem := range elems {
+		elem = strings.Trim(strings.TrimSpace(elem), `"`)
+		switch strings.ToLower(elem) {
+		case "t", "true":
+			bools[i] = true
+		case "f", "false":
+			bools[i] = false
+		default:
+			return nil, false // NULL element or nested array
+		}
+	}
+	return bools, true
+}
+
+// formatBoolArray formats bools as a PostgreSQL array literal such as {t,f,t}
+func formatBoolArray(bools []bool) string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, b := range bools {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		if b {
+			sb.WriteByte('t')
+		} else {
+			sb.WriteByte('f')
+		}
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
@@ -0,0 +1,83 @@
+package godbc
+
+// prefetcher double-buffers block fetching: while the caller consumes the
+// current rowset, the next one is fetched into a spare set of buffers on a
+// background goroutine, hiding the round trip to the server.
+type prefetcher struct {
+	spare  *blockFetch         // buffers free for the next fetch, nil while a fetch is in flight
+	result chan prefetchResult // the in-flight fetch, nil if none
+}
+
+// prefetchResult is the outcome of a background fetch
+type prefetchResult struct {
+	block *blockFetch
+	ret   SQLRETURN
+	err   error
+}
+
+// prefetchEnabled reports whether the connection prefetches rowsets
+func (r *Rows) prefetchEnabled() bool {
+	return r.stmt != nil && r.stmt.conn != nil && r.stmt.conn.prefetch
+}
+
+// startPrefetcher allocates the spare buffers for the current block fetch
+func (r *Rows) startPrefetcher() {
+	if r.block != nil && r.prefetchEnabled() {
+		r.prefetch = &prefetcher{spare: r.block.sibling()}
+	}
+}
+
+// startPrefetch fetches the next rowset into the spare buffers in the
+// background. The statement lock is held for the duration of the fetch, so
+// statement operations on other goroutines wait for it to finish.
+func (r *Rows) startPrefetch() {
+	p := r.prefetch
+	if p == nil || p.spare == nil || p.result != nil {
+		return
+	}
+	b := p.spare
+	p.spare = nil
+	ch := make(chan prefetchResult, 1)
+	p.result = ch
+
+	s := r.stmt
+	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		res := prefetchResult{block: b, ret: SQL_ERROR}
+		switch {
+		case s.closed:
+			res.err = ErrStmtClosed
+		case !b.bind(s.stmt):
+			res.err = r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+		default:
+			res.ret = Fetch(s.stmt)
+			if res.ret != SQL_NO_DATA && !IsSuccess(res.ret) {
+				res.err = r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+			}
+		}
+		ch <- res
+	}()
+}
+
+// waitPrefetch waits for the in-flight fetch. ok is false if none is running.
+func (r *Rows) waitPrefetch() (res prefetchResult, ok bool) {
+	p := r.prefetch
+	if p == nil || p.result == nil {
+		return prefetchResult{}, false
+	}
+	res = <-p.result
+	p.result = nil
+	return res, true
+}
+
+// stopPrefetch waits for any in-flight fetch and discards the prefetcher, so
+// the statement can be closed or moved to the next result set
+func (r *Rows) stopPrefetch() {
+	if res, ok := r.waitPrefetch(); ok {
+		// The statement stays bound to the fetched buffers until it is reset
+		r.block = res.block
+	}
+	r.prefetch = nil
+}
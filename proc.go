@@ -0,0 +1,372 @@
+package godbc
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// procParamPrefixes maps database types whose drivers bind procedure
+// parameters by name, through the SQL_DESC_NAME field of the implementation
+// parameter descriptor, to the prefix their parameter names carry. Named
+// parameters in procedure calls are bound by position for other databases.
+var procParamPrefixes = map[string]string{
+	"sql server": "@",
+}
+
+// procParamPrefix returns the parameter name prefix of the connection's
+// database, and whether it supports binding procedure parameters by name
+func (c *Conn) procParamPrefix() (string, bool) {
+	if dbTypeLower := strings.ToLower(c.dbType); dbTypeLower != "" {
+		for dbName, prefix := range procParamPrefixes {
+			if strings.Contains(dbTypeLower, dbName) {
+				return prefix, true
+			}
+		}
+	}
+	return "", false
+}
+
+// isProcCall reports whether a query is an ODBC procedure call escape
+// sequence, {CALL proc(...)} or {? = CALL proc(...)}
+func isProcCall(query string) bool {
+	isCall, _ := parseProcCall(query)
+	return isCall
+}
+
+// hasReturnValue reports whether a query is a procedure call escape sequence
+// whose first parameter marker receives the return value, {? = CALL proc(...)}
+func hasReturnValue(query string) bool {
+	isCall, returns := parseProcCall(query)
+	return isCall && returns
+}
+
+// parseProcCall reports whether a query is a procedure call escape sequence,
+// and whether it starts with a return value marker
+func parseProcCall(query string) (isCall, returns bool) {
+	q := strings.TrimSpace(query)
+	if !strings.HasPrefix(q, "{") {
+		return false, false
+	}
+	q = strings.TrimSpace(q[1:])
+	if strings.HasPrefix(q, "?") {
+		q = strings.TrimSpace(q[1:])
+		if !strings.HasPrefix(q, "=") {
+			return false, false
+		}
+		q = strings.TrimSpace(q[1:])
+		returns = true
+	}
+	isCall = len(q) > 4 && strings.EqualFold(q[:4], "call") && !isIdentChar(q[4])
+	return isCall, returns
+}
+
+// withReturnValue prepares the arguments of a {? = CALL proc(...)} statement.
+// A ReturnValue passed first binds the return value marker; otherwise it is
+// bound as an int32 return status and the arguments move to the following
+// markers.
+func withReturnValue(args []driver.NamedValue) []driver.NamedValue {
+	for _, arg := range args {
+		if _, ok := arg.Value.(ReturnValue); ok && arg.Ordinal == 1 {
+			return args
+		}
+	}
+	shifted := make([]driver.NamedValue, 0, len(args)+1)
+	shifted = append(shifted, driver.NamedValue{Ordinal: 1, Value: ReturnValue{}})
+	for _, arg := range args {
+		arg.Ordinal++
+		shifted = append(shifted, arg)
+	}
+	return shifted
+}
+
+// procParamNames returns the driver parameter names of a procedure call that
+// uses named parameters, by position, or nil if its parameters are bound by
+// position. Each name must appear once, since a procedure parameter can only
+// be bound to one marker.
+func (c *Conn) procParamNames(query string, namedParams *NamedParams) []string {
+	if namedParams == nil || !isProcCall(query) {
+		return nil
+	}
+	prefix, ok := c.procParamPrefix()
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(namedParams.Names))
+	for _, name := range namedParams.Names {
+		positions := namedParams.Positions[name]
+		if len(positions) != 1 {
+			return nil
+		}
+		for len(names) < positions[0] {
+			names = append(names, "")
+		}
+		names[positions[0]-1] = prefix + name
+	}
+	return names
+}
+
+// setParamNames names the bound parameters in the implementation parameter
+// descriptor, so the driver matches them to procedure parameters by name
+func (s *Stmt) setParamNames() error {
+	var ipd SQLHDESC
+	ret := GetStmtAttr(s.stmt, SQL_ATTR_IMP_PARAM_DESC, uintptr(unsafe.Pointer(&ipd)), 0, nil)
+	if !IsSuccess(ret) {
+		return s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	for i, name := range s.paramNames {
+		if name == "" {
+			continue
+		}
+		nameBytes := append([]byte(name), 0)
+		ret := SetDescField(ipd, SQLSMALLINT(i+1), SQL_DESC_NAME, uintptr(unsafe.Pointer(&nameBytes[0])), SQLINTEGER(SQL_NTS))
+		runtime.KeepAlive(nameBytes)
+		if !IsSuccess(ret) {
+			return s.conn.newError(SQL_HANDLE_DESC, SQLHANDLE(ipd))
+		}
+	}
+	return nil
+}
+
+// ProcResultSet is a result set returned by a stored procedure called with
+// CallProc
+type ProcResultSet struct {
+	Columns []string
+	Rows    [][]driver.Value
+}
+
+// ProcResult is the outcome of a stored procedure called with CallProc. The
+// embedded Result holds the output parameters, the return value and the row
+// counts of the statements the procedure ran, from AllRowsAffected.
+type ProcResult struct {
+	Result
+
+	// ResultSets are the result sets the procedure returned, in order
+	ResultSets []ProcResultSet
+}
+
+// CallProc executes a stored procedure using the ODBC call escape sequence,
+// so callers need not write {CALL proc(?, ?)} themselves, and reads every
+// result set it returns. Output parameters are available once all results
+// are read, so the result sets are held in memory; use QueryContext with the
+// call escape to stream large ones.
+//
+// Arguments with a Name are written as named parameters, {CALL proc(@a, @c)},
+// and on databases that support it (SQL Server) are bound to the procedure
+// parameters of the same name, so optional parameters can be skipped and
+// arguments passed in any order. Arguments without a Name are bound by
+// position; the two cannot be mixed. Pass OutputParam values for output
+// parameters and read them from ProcResult.OutputParams. A ReturnValue passed
+// as the first argument calls {? = CALL proc(...)} and receives the return
+// value, such as a SQL Server return status; it can only be combined with
+// positional arguments.
+//
+// The same binding applies to procedure calls executed through database/sql
+// with named parameters, e.g. db.Exec("{CALL dbo.p(@a, @c)}", sql.Named("a", 1), sql.Named("c", 3)).
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    res, err := dc.(*godbc.Conn).CallProc(ctx, "dbo.create_order",
+//	        driver.NamedValue{Name: "customer_id", Value: 42},
+//	        driver.NamedValue{Name: "order_id", Value: godbc.NewOutputParam(int64(0))})
+//	    if err != nil {
+//	        return err
+//	    }
+//	    orderID := res.OutputParam(1)
+//	    for _, set := range res.ResultSets {
+//	        ...
+//	    }
+//	})
+func (c *Conn) CallProc(ctx context.Context, proc string, args ...driver.NamedValue) (*ProcResult, error) {
+	parts, err := parseTableName(proc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid procedure name %q", proc)
+	}
+	name, err := c.qualifiedName(parts)
+	if err != nil {
+		return nil, err
+	}
+	query, err := procCallQuery(name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := c.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer ds.Close()
+
+	values := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		values[i] = driver.NamedValue{Name: strings.TrimLeft(arg.Name, ":@$"), Ordinal: i + 1, Value: arg.Value}
+	}
+	return ds.(*Stmt).call(ctx, values)
+}
+
+// call executes a procedure call and reads all of its results
+func (s *Stmt) call(ctx context.Context, args []driver.NamedValue) (_ *ProcResult, err error) {
+	op, err := lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer op.end()
+
+	s.conn.applyWorkloadHint(ctx)
+	span := s.conn.startSpan(ctx, SpanExec, s.query)
+	defer func() { endSpan(span, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrStmtClosed
+	}
+	if s.conn.isClosed() {
+		return nil, driver.ErrBadConn
+	}
+
+	s.setQueryTimeout(ctx)
+	if ctx.Done() != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				Cancel(s.stmt)
+			case <-done:
+			}
+		}()
+	}
+
+	if err := s.bindParams(args); err != nil {
+		return nil, s.conn.observeError(MetricsExec, s.query, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	_, err = s.executeChecked(ctx, args)
+	if err == nil {
+		var res *ProcResult
+		res, err = s.readProcResults()
+		executeTime := time.Since(start)
+		if err == nil {
+			s.conn.observeExec(MetricsExec, s.query, executeTime, res.rowsAffected)
+			statsCollectorFromContext(ctx).add(QueryStats{
+				Queries:     1,
+				PrepareTime: s.takePrepareTime(),
+				ExecuteTime: executeTime,
+			})
+			return res, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, s.conn.observeError(MetricsExec, s.query, ctx.Err())
+	}
+	return nil, s.conn.observeError(MetricsExec, s.query, err)
+}
+
+// readProcResults reads the results of an executed procedure call: its result
+// sets and row counts, then the output parameters, which drivers deliver once
+// the last result is read
+func (s *Stmt) readProcResults() (*ProcResult, error) {
+	rows, err := newRows(s, false)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := &ProcResult{}
+	for len(res.ResultSets)+len(res.allAffected) < maxBatchResults {
+		if len(rows.columns) > 0 {
+			set := ProcResultSet{Columns: rows.Columns()}
+			for {
+				dest := make([]driver.Value, len(set.Columns))
+				if err := rows.Next(dest); err == io.EOF {
+					break
+				} else if err != nil {
+					return nil, err
+				}
+				for i, v := range dest {
+					if b, ok := v.([]byte); ok {
+						dest[i] = bytes.Clone(b)
+					}
+				}
+				set.Rows = append(set.Rows, dest)
+			}
+			res.ResultSets = append(res.ResultSets, set)
+		} else {
+			var rowCount SQLLEN
+			if !IsSuccess(RowCount(s.stmt, &rowCount)) {
+				rowCount = -1
+			}
+			res.allAffected = append(res.allAffected, int64(rowCount))
+		}
+
+		if err := rows.NextResultSet(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(res.allAffected) > 0 {
+		res.rowsAffected = res.allAffected[0]
+	} else {
+		res.rowsAffected = -1
+	}
+	res.outputParams = s.retrieveOutputParams()
+	if s.returnValue && len(res.outputParams) > 0 {
+		res.returnValue = res.outputParams[0]
+	}
+	// Parameter bindings are kept for the next execution to reuse
+	s.outputParams = nil
+	return res, nil
+}
+
+// procCallQuery builds the call escape sequence for a procedure, with a named
+// or positional parameter marker per argument
+func procCallQuery(proc string, args []driver.NamedValue) (string, error) {
+	call := "{CALL "
+	if len(args) > 0 {
+		if _, ok := args[0].Value.(ReturnValue); ok {
+			call = "{? = CALL "
+			args = args[1:]
+		}
+	}
+	if len(args) == 0 {
+		return call + proc + "}", nil
+	}
+	named := args[0].Name != ""
+	if named && call != "{CALL " {
+		return "", fmt.Errorf("procedure %s: a return value cannot be combined with named arguments", proc)
+	}
+	markers := make([]string, len(args))
+	for i, arg := range args {
+		if (arg.Name != "") != named {
+			return "", fmt.Errorf("procedure %s: arguments must be all named or all positional", proc)
+		}
+		if _, ok := arg.Value.(ReturnValue); ok {
+			return "", fmt.Errorf("procedure %s: a return value must be the first argument", proc)
+		}
+		if !named {
+			markers[i] = "?"
+			continue
+		}
+		name := strings.TrimLeft(arg.Name, ":@$")
+		if name == "" || !isIdentStart(name[0]) || strings.IndexFunc(name, func(r rune) bool { return r > 0x7f || !isIdentChar(byte(r)) }) >= 0 {
+			return "", &ParameterError{Name: arg.Name, Message: "invalid procedure parameter name"}
+		}
+		markers[i] = "@" + name
+	}
+	return call + proc + "(" + strings.Join(markers, ", ") + ")}", nil
+}
@@ -0,0 +1,177 @@
+package godbc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// Quirk adapts the driver to a database or ODBC driver it has no built-in
+// handling for, or corrects the built-in handling of one, registered with
+// RegisterQuirk. Zero fields keep the driver's behavior.
+type Quirk struct {
+	// TypeCasts maps native column type names, as the driver reports them in
+	// SQL_DESC_TYPE_NAME and matched case-insensitively, to the cast applied
+	// to their values, e.g. "money": CastFloat64. Casts set for a column with
+	// WithColumnCasts take precedence.
+	TypeCasts map[string]CastType
+
+	// PingQuery replaces the query Ping and keepalives run, e.g.
+	// "SELECT 1 FROM DUAL" (WithPingQuery takes precedence)
+	PingQuery string
+
+	// LastInsertIdQuery is run after an INSERT to read the generated identity
+	// for Result.LastInsertId, e.g. "SELECT @@IDENTITY"
+	LastInsertIdQuery string
+
+	// StmtAttrs are set on every statement handle the connection prepares or
+	// executes queries with, e.g. SQL_ATTR_NOSCAN or a driver-specific
+	// attribute. Failures are logged and the statement still runs.
+	StmtAttrs []StmtAttr
+
+	// ReprepareErrors are native error codes meaning a prepared statement no
+	// longer matches the schema; a statement whose execution fails with one
+	// is prepared again and retried once, as for IsSchemaChangeError
+	ReprepareErrors []int32
+
+	// ReprepareOnInvalidCursorState treats an invalid cursor state (24000) as
+	// a schema change too, for drivers that report DDL on a table a prepared
+	// statement reads that way. It is off by default, since 24000 usually
+	// means the application misused a cursor.
+	ReprepareOnInvalidCursorState bool
+}
+
+// StmtAttr is a statement attribute set with SQLSetStmtAttr
+type StmtAttr struct {
+	Attr  SQLINTEGER  // Attribute, e.g. SQL_ATTR_MAX_ROWS or a driver-specific value
+	Value interface{} // Integer, bool, time.Duration (whole seconds) or string
+}
+
+// registeredQuirk is a quirk and the database types it applies to
+type registeredQuirk struct {
+	pattern string // lowercase substring of the DBMS name
+	quirk   Quirk
+}
+
+var (
+	quirksMu sync.RWMutex
+
+	// quirks are the registered quirks, in registration order
+	quirks []registeredQuirk
+)
+
+// RegisterQuirk registers a quirk for the databases whose DBMS name, as
+// reported by SQL_DBMS_NAME, contains dbmsNamePattern (case-insensitive),
+// so applications hitting a new ODBC driver can adapt the driver from their
+// own code. A quirk takes precedence over the driver's built-in handling of
+// the same database; if several match, the last registered applies.
+// Registering a pattern again replaces its quirk. Quirks apply to
+// connections made after they are registered.
+//
+// Example:
+//
+//	godbc.RegisterQuirk("exasol", godbc.Quirk{
+//	    PingQuery:         "SELECT 1 FROM DUAL",
+//	    LastInsertIdQuery: "SELECT LAST_IDENTITY()",
+//	    TypeCasts:         map[string]godbc.CastType{"DECIMAL": godbc.CastString},
+//	})
+func RegisterQuirk(dbmsNamePattern string, q Quirk) {
+	pattern := strings.ToLower(dbmsNamePattern)
+	casts := make(map[string]CastType, len(q.TypeCasts))
+	for name, cast := range q.TypeCasts {
+		casts[strings.ToLower(name)] = cast
+	}
+	q.TypeCasts = casts
+	q.StmtAttrs = append([]StmtAttr(nil), q.StmtAttrs...)
+	q.ReprepareErrors = append([]int32(nil), q.ReprepareErrors...)
+
+	quirksMu.Lock()
+	defer quirksMu.Unlock()
+	for i, r := range quirks {
+		if r.pattern == pattern {
+			quirks = append(quirks[:i], quirks[i+1:]...)
+			break
+		}
+	}
+	quirks = append(quirks, registeredQuirk{pattern: pattern, quirk: q})
+}
+
+// lookupQuirk returns the quirk registered for a DBMS name, or nil
+func lookupQuirk(dbType string) *Quirk {
+	dbTypeLower := strings.ToLower(dbType)
+	if dbTypeLower == "" {
+		return nil
+	}
+	quirksMu.RLock()
+	defer quirksMu.RUnlock()
+	for i := len(quirks) - 1; i >= 0; i-- {
+		if strings.Contains(dbTypeLower, quirks[i].pattern) {
+			q := quirks[i].quirk
+			return &q
+		}
+	}
+	return nil
+}
+
+// typeCasts returns the cast of each column by native type name, or nil if
+// the quirk casts none of them
+func (q *Quirk) typeCasts(nativeTypes []string) []CastType {
+	if q == nil || len(q.TypeCasts) == 0 {
+		return nil
+	}
+	var result []CastType
+	for i, name := range nativeTypes {
+		if cast, ok := q.TypeCasts[strings.ToLower(name)]; ok && cast != CastNone {
+			if result == nil {
+				result = make([]CastType, len(nativeTypes))
+			}
+			result[i] = cast
+		}
+	}
+	return result
+}
+
+// applyStmtAttrs sets the statement attributes of the connection's quirk on
+// a new statement handle
+func (c *Conn) applyStmtAttrs(ctx context.Context, stmt SQLHSTMT) {
+	if c.quirk == nil {
+		return
+	}
+	for _, a := range c.quirk.StmtAttrs {
+		ret, err := setStmtAttr(stmt, a)
+		if err != nil {
+			if c.logger != nil {
+				c.logger.LogAttrs(ctx, slog.LevelWarn, "odbc statement attribute not set", logAttrs(ctx,
+					slog.String("dbms", c.dbType),
+					slog.String("error", err.Error()))...)
+			}
+			continue
+		}
+		logDiagnostics(ctx, c.logger, ret, SQL_HANDLE_STMT, SQLHANDLE(stmt), "set stmt attr")
+	}
+}
+
+// setStmtAttr sets a single statement attribute, converting its value as
+// setConnectAttr does
+func setStmtAttr(stmt SQLHSTMT, a StmtAttr) (SQLRETURN, error) {
+	var ret SQLRETURN
+	if s, ok := a.Value.(string); ok {
+		buf := append([]byte(s), 0)
+		ret = SetStmtAttr(stmt, a.Attr, uintptr(unsafe.Pointer(&buf[0])), SQL_NTS)
+		runtime.KeepAlive(buf)
+	} else {
+		value, ok := connectAttrInt(a.Value)
+		if !ok {
+			return SQL_ERROR, fmt.Errorf("statement attribute %d: unsupported value %v (%T)", a.Attr, a.Value, a.Value)
+		}
+		ret = SetStmtAttr(stmt, a.Attr, value, 0)
+	}
+	if !IsSuccess(ret) {
+		return ret, fmt.Errorf("statement attribute %d: %w", a.Attr, NewError(SQL_HANDLE_STMT, SQLHANDLE(stmt)))
+	}
+	return ret, nil
+}
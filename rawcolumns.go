@@ -0,0 +1,85 @@
+package godbc
+
+import "fmt"
+
+// SetColumnRaw makes Next return a column of the current result set as the
+// bytes the driver produces, skipping every conversion: BINARY, VARBINARY and
+// LONGVARBINARY columns are fetched as SQL_C_BINARY and all other columns as
+// SQL_C_CHAR text, returned as []byte. Decimal normalization, casts, boolean
+// rules and PostgreSQL decoding do not apply, so values can be copied to a
+// target without conversions it would have to undo. index is zero-based.
+//
+// It must be called before the first Next of the result set; NextResultSet
+// resets every column to the normal conversions.
+//
+// Example:
+//
+//	rows, err := conn.QueryContext(ctx, "SELECT id, amount FROM ledger", nil)
+//	...
+//	if err := rows.(*godbc.Rows).SetColumnRaw(1, true); err != nil {
+//	    return err
+//	}
+func (r *Rows) SetColumnRaw(index int, raw bool) error {
+	if r.closed {
+		return fmt.Errorf("rows are closed")
+	}
+	if index < 0 || index >= len(r.columns) {
+		return fmt.Errorf("column index %d out of range [0, %d)", index, len(r.columns))
+	}
+	if r.blockChecked {
+		return fmt.Errorf("column %q: SetColumnRaw must be called before the first Next", r.columns[index])
+	}
+	if r.raw == nil {
+		if !raw {
+			return nil
+		}
+		r.raw = make([]bool, len(r.columns))
+	}
+	r.raw[index] = raw
+	return nil
+}
+
+// isRaw reports whether a column is fetched as raw bytes
+func (r *Rows) isRaw(idx int) bool {
+	return idx < len(r.raw) && r.raw[idx]
+}
+
+// rawCType returns the C type a raw column is fetched as
+func rawCType(colType SQLSMALLINT) SQLSMALLINT {
+	switch colType {
+	case SQL_BINARY, SQL_VARBINARY, SQL_LONGVARBINARY:
+		return SQL_C_BINARY
+	}
+	return SQL_C_CHAR
+}
+
+// rawBlockLayout returns the block fetch layout of a raw column. Text is
+// allowed four bytes per character of the reported size, which also covers
+// the display size of numeric and date/time types.
+func rawBlockLayout(colType SQLSMALLINT, colSize SQLULEN, sizeUnknown bool) (cType SQLSMALLINT, elemSize int, ok bool) {
+	if sizeUnknown {
+		return 0, 0, false
+	}
+	cType = rawCType(colType)
+	if cType == SQL_C_BINARY {
+		elemSize = int(colSize)
+	} else {
+		elemSize = int(colSize)*4 + 1
+	}
+	if elemSize > maxBlockElemSize {
+		return 0, 0, false
+	}
+	return cType, elemSize, true
+}
+
+// getRaw fetches a raw column of the current row with SQLGetData
+func (r *Rows) getRaw(colNum SQLUSMALLINT, colType SQLSMALLINT, colSize SQLULEN) (interface{}, error) {
+	if rawCType(colType) == SQL_C_BINARY {
+		return r.getBytes(colNum, colSize)
+	}
+	v, err := r.getString(colNum, colSize)
+	if s, ok := v.(string); ok {
+		return []byte(s), err
+	}
+	return v, err
+}
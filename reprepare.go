@@ -0,0 +1,95 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"strings"
+)
+
+// reprepareErrors maps database types to the native error codes that mean a
+// prepared statement no longer matches the schema, beyond the SQLStates
+// IsSchemaChangeError recognizes
+var reprepareErrors = map[string][]int32{
+	"sql server": {16943},            // Table schema changed after the cursor was declared
+	"oracle":     {4061, 4065, 4068}, // Package or procedure state invalidated by recompilation
+}
+
+// isSchemaChangeError reports whether err means a prepared statement must be
+// prepared again: IsSchemaChangeError, a native error code of the database's
+// built-in list or its quirk, or an invalid cursor state if the quirk opts in
+func (c *Conn) isSchemaChangeError(err error) bool {
+	if IsSchemaChangeError(err) {
+		return true
+	}
+	var records []Error
+	if e, ok := err.(*Error); ok {
+		records = []Error{*e}
+	} else if es, ok := err.(Errors); ok {
+		records = es
+	}
+	if len(records) == 0 {
+		return false
+	}
+	var codes []int32
+	invalidCursor := false
+	if c.quirk != nil {
+		codes = c.quirk.ReprepareErrors
+		invalidCursor = c.quirk.ReprepareOnInvalidCursorState
+	}
+	dbType := strings.ToLower(c.dbType)
+	for name, native := range reprepareErrors {
+		if dbType != "" && strings.Contains(dbType, name) {
+			codes = append(codes, native...)
+		}
+	}
+	for _, e := range records {
+		if invalidCursor && e.SQLState == SQLStateInvalidCursorState {
+			return true
+		}
+		for _, code := range codes {
+			if e.NativeError == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reprepare handles an execution that failed because the prepared statement
+// no longer matches the schema, as after DDL on a table it reads: the cursor
+// is closed, the parameters unbound and the statement prepared again, then
+// args are bound again and the execution retried once. Databases report such
+// errors before running the statement, so the retry cannot run it twice. If
+// preparing fails, as when the DDL dropped a column the statement names, that
+// error is returned. Streamed parameters cannot be read twice, and PostgreSQL
+// aborts the transaction the error happens in, where a retry would only fail
+// with 25P02, so executeChecked does not reprepare statements with streamed
+// parameters or inside a transaction.
+func (s *Stmt) reprepare(ctx context.Context, args []driver.NamedValue, schemaErr error) (SQLRETURN, error) {
+	if s.conn.logger != nil {
+		s.conn.logger.LogAttrs(ctx, slog.LevelWarn, "odbc preparing statement again after schema change", logAttrs(ctx,
+			slog.String("error", schemaErr.Error()))...)
+	}
+	FreeStmt(s.stmt, SQL_CLOSE)
+	s.resetParams()
+	s.resultCols = nil
+	if ret := s.conn.prepare(s.stmt, s.conn.tagQuery(ctx, s.preparedQuery())); !IsSuccess(ret) {
+		s.lastErr = s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+		return ret, s.lastErr
+	}
+	if err := s.bindParams(args); err != nil {
+		return SQL_ERROR, err
+	}
+	ret, err := s.execute()
+	if err == nil && (IsSuccess(ret) || ret == SQL_NO_DATA) {
+		s.lastErr = nil
+		s.conn.logInfo(ctx, ret, s.stmt, "execute")
+		return ret, nil
+	}
+	if err == nil {
+		err = s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	s.lastErr = err
+	return ret, err
+}
@@ -0,0 +1,105 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"unsafe"
+)
+
+// sessionStmtAttrs are statement attributes that can also be set on the
+// connection, as defaults for its new statements, and so outlive the borrower
+// that set them. ResetSession puts them back to their configured values.
+var sessionStmtAttrs = []SQLINTEGER{SQL_ATTR_QUERY_TIMEOUT, SQL_ATTR_MAX_ROWS}
+
+// resetSession restores the session state a borrower of a pooled connection
+// may have changed: autocommit, the transaction isolation level, statement
+// attribute defaults and the current catalog, then runs the reset query. The
+// caller must hold c.mu.
+func (c *Conn) resetSession(ctx context.Context) error {
+	if sqlGetConnectAttr == nil {
+		// The ODBC library was never loaded, so no session exists to reset
+		return nil
+	}
+	if v, ok := getConnectAttrInt(c.dbc, SQL_ATTR_AUTOCOMMIT); ok && v == SQL_AUTOCOMMIT_OFF {
+		if !IsSuccess(SetConnectAttr(c.dbc, SQL_ATTR_AUTOCOMMIT, SQL_AUTOCOMMIT_ON, 0)) {
+			return fmt.Errorf("restore autocommit: %w", c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc)))
+		}
+	}
+
+	if c.txnIsolationSet {
+		if c.defaultTxnIsolation != 0 {
+			if !IsSuccess(SetConnectAttr(c.dbc, SQL_ATTR_TXN_ISOLATION, uintptr(c.defaultTxnIsolation), 0)) {
+				return fmt.Errorf("restore isolation level: %w", c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc)))
+			}
+		}
+		c.txnIsolationSet = false
+	}
+
+	for _, attr := range sessionStmtAttrs {
+		want := uint64(0)
+		for _, a := range c.connectAttrs {
+			if n, ok := connectAttrInt(a.Value); ok && a.Attr == attr {
+				want = uint64(n)
+			}
+		}
+		// Drivers that do not accept the attribute on connections report it unsupported
+		if v, ok := getConnectAttrInt(c.dbc, attr); ok && v != want {
+			SetConnectAttr(c.dbc, attr, uintptr(want), 0)
+		}
+	}
+
+	if c.catalog != "" {
+		if current, ok := getConnectAttrString(c.dbc, SQL_ATTR_CURRENT_CATALOG); ok && current != c.catalog {
+			buf := append([]byte(c.catalog), 0)
+			ret := SetConnectAttr(c.dbc, SQL_ATTR_CURRENT_CATALOG, uintptr(unsafe.Pointer(&buf[0])), SQL_NTS)
+			runtime.KeepAlive(buf)
+			if !IsSuccess(ret) {
+				return fmt.Errorf("restore catalog %q: %w", c.catalog, c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc)))
+			}
+		}
+	}
+
+	if c.resetQuery != "" {
+		if err := c.execResetQuery(ctx); err != nil {
+			return fmt.Errorf("reset query: %w", err)
+		}
+		// Queries such as DISCARD ALL also deallocate prepared statements
+		if c.stmtCache != nil {
+			c.stmtCache.purge()
+		}
+	}
+	return nil
+}
+
+// execResetQuery runs the connection's reset query. The caller must hold c.mu.
+func (c *Conn) execResetQuery(ctx context.Context) error {
+	var stmtHandle SQLHSTMT
+	if !IsSuccess(AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(c.dbc), (*SQLHANDLE)(&stmtHandle))) {
+		return c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+	}
+	defer FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+
+	if secs := c.queryTimeoutSecs(ctx); secs > 0 {
+		SetStmtAttr(stmtHandle, SQL_ATTR_QUERY_TIMEOUT, secs, 0)
+	}
+	ret := c.execDirect(stmtHandle, c.resetQuery)
+	if !IsSuccess(ret) && ret != SQL_NO_DATA {
+		return c.newError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+	}
+	c.logInfo(ctx, ret, stmtHandle, "reset query")
+	return nil
+}
+
+// resetFailed logs a failed session reset and reports the connection as bad,
+// so database/sql discards it instead of handing its state to the next borrower
+func (c *Conn) resetFailed(ctx context.Context, err error) error {
+	if c.logger != nil {
+		c.logger.LogAttrs(ctx, slog.LevelWarn, "odbc session reset failed; connection will be discarded", logAttrs(ctx,
+			slog.String("dbms", c.dbType),
+			slog.String("error", err.Error()))...)
+	}
+	return driver.ErrBadConn
+}
@@ -8,7 +8,9 @@ import (
 type Result struct {
 	lastInsertId int64
 	rowsAffected int64
+	allAffected  []int64 // row count of each statement, from the first
 	outputParams []interface{}
+	returnValue  interface{}
 }
 
 // LastInsertId returns the ID of the last inserted row.
@@ -23,6 +25,26 @@ func (r *Result) RowsAffected() (int64, error) {
 	return r.rowsAffected, nil
 }
 
+// AllRowsAffected returns the row count of each statement of a batch executed
+// with ExecContext, such as "INSERT ...; UPDATE ...", in order, read by walking
+// the results with SQLMoreResults; RowsAffected reports only the first. A
+// statement the driver reports no count for has -1. Statements that produce no
+// result, such as those run with SET NOCOUNT ON on SQL Server, have no entry,
+// and the counts end at the first statement that fails; use Conn.ExecMulti to
+// see the errors of later statements.
+//
+// Example:
+//
+//	res, err := db.ExecContext(ctx, "UPDATE a SET x = 1; DELETE FROM b")
+//	...
+//	counts := res.(interface{ AllRowsAffected() []int64 }).AllRowsAffected()
+func (r *Result) AllRowsAffected() []int64 {
+	if r.allAffected == nil {
+		return []int64{r.rowsAffected}
+	}
+	return r.allAffected
+}
+
 // OutputParams returns the values of output parameters after executing a stored procedure.
 // The values are returned in the same order as the parameters were bound.
 // Only parameters marked as ParamOutput or ParamInputOutput will have values.
@@ -40,5 +62,12 @@ func (r *Result) OutputParam(index int) interface{} {
 	return r.outputParams[index]
 }
 
+// ReturnValue returns the return value of a stored procedure called with the
+// ODBC escape sequence {? = CALL proc(...)}, such as a SQL Server return status.
+// It is also OutputParam(0). Returns nil for other statements or a NULL value.
+func (r *Result) ReturnValue() interface{} {
+	return r.returnValue
+}
+
 // Ensure Result implements driver.Result
 var _ driver.Result = (*Result)(nil)
@@ -0,0 +1,169 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Retryer retries operations that fail with transient errors, waiting
+// between attempts with exponential backoff and jitter. Set on a connector
+// with WithRetryer, it retries failed connects and failed executions of
+// read-only queries (SELECT and VALUES statements outside a transaction,
+// without INTO or further statements after a ';'); other statements are
+// never retried, since they may have taken effect.
+// Do retries any operation.
+//
+// A Retryer may be shared by connectors and goroutines.
+//
+// Example:
+//
+//	r := godbc.NewRetryer(4, 100*time.Millisecond)
+//	r.MaxBackoff = 2 * time.Second
+//	connector, _ := godbc.OpenConnectorWithOptions(connString, godbc.WithRetryer(r))
+type Retryer struct {
+	// MaxAttempts is the number of attempts, including the first (< 2 = no retries)
+	MaxAttempts int
+
+	// Backoff is the wait before the first retry, doubled for each later one
+	Backoff time.Duration
+
+	// MaxBackoff caps the wait between attempts (0 = no cap)
+	MaxBackoff time.Duration
+
+	// Jitter randomizes each wait by up to this fraction of it, in [0, 1], so
+	// clients that failed together do not retry in lockstep
+	Jitter float64
+
+	// RetryOn reports whether an error should be retried (nil = IsRetryable,
+	// which covers connection failures (08xxx), deadlocks and serialization
+	// failures (40001) and timeouts (HYT00, HYT01))
+	RetryOn func(error) bool
+
+	// OnRetry, if set, is called before waiting to retry, with the number of
+	// the attempt that failed
+	OnRetry func(attempt int, err error, wait time.Duration)
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryer returns a Retryer that makes up to maxAttempts attempts, waiting
+// backoff before the first retry and doubling the wait for each later one,
+// with 20% jitter
+func NewRetryer(maxAttempts int, backoff time.Duration) *Retryer {
+	return &Retryer{MaxAttempts: maxAttempts, Backoff: backoff, Jitter: 0.2}
+}
+
+// Do calls fn until it succeeds, returns an error RetryOn rejects, or
+// MaxAttempts attempts have been made, and returns its last error. It stops
+// early with the context's error if ctx is done while waiting.
+func (r *Retryer) Do(ctx context.Context, fn func() error) error {
+	return r.do(ctx, fn, r.retryable, nil)
+}
+
+// do is Do with the retry predicate and an extra notification before each retry
+func (r *Retryer) do(ctx context.Context, fn func() error, retryable func(error) bool, notify func(attempt int, err error, wait time.Duration)) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= r.MaxAttempts || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+		wait := r.wait(attempt)
+		if r.OnRetry != nil {
+			r.OnRetry(attempt, err, wait)
+		}
+		if notify != nil {
+			notify(attempt, err, wait)
+		}
+		if sleepErr := r.sleepFor(ctx, wait); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+// retryable reports whether err should be retried
+func (r *Retryer) retryable(err error) bool {
+	if r.RetryOn != nil {
+		return r.RetryOn(err)
+	}
+	return IsRetryable(err)
+}
+
+// wait returns the wait after the given failed attempt
+func (r *Retryer) wait(attempt int) time.Duration {
+	d := r.Backoff
+	for i := 1; i < attempt && d > 0; i++ {
+		if (r.MaxBackoff > 0 && d >= r.MaxBackoff) || d > math.MaxInt64/2 {
+			break
+		}
+		d *= 2
+	}
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+	if jitter := min(max(r.Jitter, 0), 1); jitter > 0 && d > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * jitter * float64(d))
+	}
+	return d
+}
+
+// sleepFor waits for d, returning early with the context's error if ctx is done
+func (r *Retryer) sleepFor(ctx context.Context, d time.Duration) error {
+	if r.sleep != nil {
+		return r.sleep(ctx, d)
+	}
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// logRetry returns a notification that logs a retry of op to logger, or nil
+// if logger is nil
+func logRetry(ctx context.Context, logger *slog.Logger, op string) func(attempt int, err error, wait time.Duration) {
+	if logger == nil {
+		return nil
+	}
+	return func(attempt int, err error, wait time.Duration) {
+		logger.LogAttrs(ctx, slog.LevelWarn, "odbc retrying "+op+" after transient error", logAttrs(ctx,
+			slog.Int("attempt", attempt),
+			slog.Duration("wait", wait),
+			slog.String("error", err.Error()))...)
+	}
+}
+
+// retriesQuery reports whether a query run on the connection with args is
+// retried: a read-only query outside a transaction, without streamed
+// parameters that cannot be read twice
+func (c *Conn) retriesQuery(query string, args []driver.NamedValue) bool {
+	if c.retryer == nil || !isRowQuery(query) || hasStreamParams([][]driver.NamedValue{args}) {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.inTx
+}
+
+// retryQuery runs a read-only query with the connection's retryer. A
+// connection error is not retried, since the connection cannot be used again.
+func (c *Conn) retryQuery(ctx context.Context, query func() (driver.Rows, error)) (driver.Rows, error) {
+	var rows driver.Rows
+	err := c.retryer.do(ctx, func() error {
+		var err error
+		rows, err = query()
+		return err
+	}, func(err error) bool {
+		return !IsConnectionError(err) && c.retryer.retryable(err)
+	}, logRetry(ctx, c.logger, "query"))
+	return rows, err
+}
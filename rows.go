@@ -1,9 +1,13 @@
 package godbc
 
 import (
+	"context"
 	"database/sql/driver"
+	"fmt"
 	"io"
+	"math"
 	"reflect"
+	"strings"
 	"time"
 	"unsafe"
 )
@@ -16,7 +20,18 @@ const maxFetchIterations = 1000
 // Some ODBC drivers return -1 as a 32-bit value that gets zero-extended to 64-bit
 // (0xFFFFFFFF = 4294967295 instead of -1), so we check for both.
 func isNullIndicator(indicator SQLLEN) bool {
-	return indicator == SQLLEN(SQL_NULL_DATA) || indicator == 0xFFFFFFFF
+	return indicator == SQLLEN(SQL_NULL_DATA) || int64(indicator) == 0xFFFFFFFF
+}
+
+// sanitizeColumnSize validates a column size reported by SQLDescribeCol.
+// Drivers report 0 for max/LOB types, and negative values such as SQL_NO_TOTAL
+// arrive as huge unsigned numbers (or zero-extended 32-bit values like 0xFFFFFFFF).
+// Sizes outside (0, math.MaxInt32] are clamped to 0 and reported as unknown.
+func sanitizeColumnSize(colSize SQLULEN) (size SQLULEN, unknown bool) {
+	if colSize == 0 || colSize > math.MaxInt32 {
+		return 0, true
+	}
+	return colSize, false
 }
 
 // Rows implements driver.Rows for result set iteration
@@ -25,11 +40,74 @@ type Rows struct {
 	columns     []string
 	colTypes    []SQLSMALLINT
 	colSizes    []SQLULEN
+	sizeUnknown []bool        // true when the driver reported no usable column size
 	decDigits   []SQLSMALLINT // decimal digits (scale) for NUMERIC/DECIMAL types
 	nullable    []SQLSMALLINT
 	nativeTypes []string // native database type names (e.g., "VARCHAR", "DATETIME2", "BIGINT")
+	unsigned    []bool   // integer columns the driver reports as unsigned, nil if none
 	closed      bool
 	closeStmt   bool // Whether to close the statement when rows are closed
+
+	// release returns the statement to the connection's statement cache, or
+	// closes it, when the rows are closed (nil = not owned by the rows)
+	release func(error)
+
+	// Fetch-time type casting
+	castMap ColumnCasts // per-query cast map (from WithColumnCasts)
+	casts   []CastType  // resolved cast per column, nil if no casts apply
+
+	// pgKinds marks PostgreSQL bit string and boolean array columns, nil if none
+	pgKinds []pgValueKind
+
+	// boolRules holds the BoolRule converting each column to bool, nil if none
+	boolRules []*BoolRule
+
+	// raw marks the columns fetched as raw bytes (see SetColumnRaw), nil if none
+	raw []bool
+
+	// LOB streaming (see WithLOBStreaming): lobTypes holds the C type of each
+	// streamed column, nil if none. rowGen counts rows so readers of earlier rows fail.
+	lobStreaming bool
+	lobTypes     []SQLSMALLINT
+	rowGen       uint64
+
+	// rowValues holds the values of the row rowValuesGen, read in column
+	// order once (see readRow); readingRow is set while they are read
+	rowValues    []driver.Value
+	rowValuesGen uint64
+	readingRow   bool
+
+	// Block fetch state (see WithRowArraySize); blockChecked is set once the
+	// current result set has been considered for block fetching
+	block        *blockFetch
+	blockChecked bool
+
+	// prefetch fetches the next rowset in the background (see WithPrefetch), nil if disabled
+	prefetch *prefetcher
+
+	// tsBuf is the SQLGetData target for timestamp columns, reused across
+	// columns and rows so fetching does not allocate a struct per value
+	tsBuf SQL_TIMESTAMP_STRUCT
+
+	// byteBufs holds the SQLGetData buffer of each binary column, reused from
+	// row to row under CopyBorrowUntilNext (see byteBuffer)
+	byteBufs [][]byte
+
+	// Query statistics
+	stats     QueryStats
+	span      Span            // fetch span, ended when the rows are closed
+	collector *StatsCollector // context collector receiving stats on Close
+
+	// op is the in-flight operation held until Close, so Shutdown waits for open rows
+	op *operation
+
+	// ctx is the query's context, checked before each fetch. unwatch stops the
+	// goroutine that cancels a fetch in progress when ctx is done, nil if none.
+	ctx     context.Context
+	unwatch func()
+
+	// mapKeys are the map keys for NextMap, built on first use for each result set
+	mapKeys []string
 }
 
 // newRows creates a new Rows from a statement
@@ -37,11 +115,12 @@ func newRows(stmt *Stmt, closeStmt bool) (*Rows, error) {
 	var numCols SQLSMALLINT
 	ret := NumResultCols(stmt.stmt, &numCols)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(stmt.stmt))
+		return nil, stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(stmt.stmt))
 	}
 
 	if numCols == 0 {
-		// No result set (e.g., UPDATE/INSERT)
+		// No result set (e.g., UPDATE/INSERT run via Query). Next returns io.EOF
+		// without calling SQLFetch, which some drivers reject with a sequence error.
 		return &Rows{
 			stmt:      stmt,
 			columns:   nil,
@@ -49,44 +128,99 @@ func newRows(stmt *Stmt, closeStmt bool) (*Rows, error) {
 		}, nil
 	}
 
-	columns := make([]string, numCols)
-	colTypes := make([]SQLSMALLINT, numCols)
-	colSizes := make([]SQLULEN, numCols)
-	decDigits := make([]SQLSMALLINT, numCols)
-	nullable := make([]SQLSMALLINT, numCols)
-	nativeTypes := make([]string, numCols)
+	// Reuse the metadata of an earlier execution of the statement; a changed
+	// column count means the result is no longer the one described
+	rc := stmt.resultCols
+	if rc == nil || len(rc.columns) != int(numCols) {
+		var err error
+		if rc, err = describeResult(stmt, numCols); err != nil {
+			return nil, err
+		}
+		if !closeStmt && !isProcCall(stmt.query) {
+			stmt.resultCols = rc
+		}
+	}
+
+	r := &Rows{stmt: stmt, closeStmt: closeStmt}
+	r.setResultColumns(rc)
+	return r, nil
+}
+
+// resultColumns is the column metadata of a result set. It is shared by every
+// Rows created from a statement and must not be modified.
+type resultColumns struct {
+	columns     []string
+	colTypes    []SQLSMALLINT
+	colSizes    []SQLULEN
+	sizeUnknown []bool
+	decDigits   []SQLSMALLINT
+	nullable    []SQLSMALLINT
+	nativeTypes []string
+	unsigned    []bool
+	pgKinds     []pgValueKind
+	boolRules   []*BoolRule
+}
+
+// describeResult describes the numCols columns of the statement's current result set
+func describeResult(stmt *Stmt, numCols SQLSMALLINT) (*resultColumns, error) {
+	rc := &resultColumns{
+		columns:     make([]string, numCols),
+		colTypes:    make([]SQLSMALLINT, numCols),
+		colSizes:    make([]SQLULEN, numCols),
+		sizeUnknown: make([]bool, numCols),
+		decDigits:   make([]SQLSMALLINT, numCols),
+		nullable:    make([]SQLSMALLINT, numCols),
+		nativeTypes: make([]string, numCols),
+	}
 
 	colName := make([]byte, 256)
 	typeName := make([]byte, 256)
 	for i := SQLUSMALLINT(1); i <= SQLUSMALLINT(numCols); i++ {
-		nameLen, dataType, colSize, decDigitsVal, nullableVal, ret := DescribeCol(stmt.stmt, i, colName)
+		name, dataType, colSize, decDigitsVal, nullableVal, ret := stmt.conn.describeCol(stmt.stmt, i, colName)
 		if !IsSuccess(ret) {
-			return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(stmt.stmt))
+			return nil, stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(stmt.stmt))
 		}
 
-		columns[i-1] = string(colName[:nameLen])
-		colTypes[i-1] = dataType
-		colSizes[i-1] = colSize
-		decDigits[i-1] = decDigitsVal
-		nullable[i-1] = nullableVal
+		rc.columns[i-1] = name
+		rc.colTypes[i-1] = dataType
+		rc.colSizes[i-1], rc.sizeUnknown[i-1] = sanitizeColumnSize(colSize)
+		rc.decDigits[i-1] = decDigitsVal
+		rc.nullable[i-1] = nullableVal
 
 		// Get native type name using SQLColAttribute with SQL_DESC_TYPE_NAME
-		strLen, _, attrRet := ColAttribute(stmt.stmt, i, SQL_DESC_TYPE_NAME, typeName)
-		if IsSuccess(attrRet) && strLen > 0 {
-			nativeTypes[i-1] = string(typeName[:strLen])
+		if name, attrRet := stmt.conn.colAttributeString(stmt.stmt, i, SQL_DESC_TYPE_NAME, typeName); IsSuccess(attrRet) {
+			rc.nativeTypes[i-1] = name
+		}
+
+		// MySQL and MariaDB have unsigned integer types that overflow the signed
+		// C types the columns are otherwise fetched with
+		switch dataType {
+		case SQL_TINYINT, SQL_SMALLINT, SQL_INTEGER, SQL_BIGINT:
+			if _, unsigned, ret := ColAttribute(stmt.stmt, i, SQL_DESC_UNSIGNED, nil); IsSuccess(ret) && unsigned == SQL_TRUE {
+				if rc.unsigned == nil {
+					rc.unsigned = make([]bool, numCols)
+				}
+				rc.unsigned[i-1] = true
+			}
 		}
 	}
+	rc.pgKinds = pgValueKinds(stmt.dbType(), rc.nativeTypes)
+	rc.boolRules = stmt.conn.columnBoolRules(rc.nativeTypes, rc.colSizes)
+	return rc, nil
+}
 
-	return &Rows{
-		stmt:        stmt,
-		columns:     columns,
-		colTypes:    colTypes,
-		colSizes:    colSizes,
-		decDigits:   decDigits,
-		nullable:    nullable,
-		nativeTypes: nativeTypes,
-		closeStmt:   closeStmt,
-	}, nil
+// setResultColumns sets the column metadata of the current result set
+func (r *Rows) setResultColumns(rc *resultColumns) {
+	r.columns = rc.columns
+	r.colTypes = rc.colTypes
+	r.colSizes = rc.colSizes
+	r.sizeUnknown = rc.sizeUnknown
+	r.decDigits = rc.decDigits
+	r.nullable = rc.nullable
+	r.nativeTypes = rc.nativeTypes
+	r.unsigned = rc.unsigned
+	r.pgKinds = rc.pgKinds
+	r.boolRules = rc.boolRules
 }
 
 // Columns returns the names of all columns in the result set.
@@ -101,14 +235,29 @@ func (r *Rows) Close() error {
 		return nil
 	}
 	r.closed = true
+	r.collector.add(r.stats)
+	if r.stmt != nil && r.stmt.conn != nil && r.stmt.conn.metrics != nil {
+		r.stmt.conn.metrics.Fetch(r.stmt.query, r.stats.RowsFetched, r.stats.FetchTime)
+	}
+	if r.span != nil {
+		r.span.SetAttributes(Attribute{Key: AttrDBReturnedRows, Value: r.stats.RowsFetched})
+		r.span.End()
+	}
+	defer r.op.end()
+	r.stopWatch()
 
-	// Close cursor
+	// Close cursor once no background fetch is using it
+	r.stopPrefetch()
 	CloseCursor(r.stmt.stmt)
+	r.endBlockFetch()
 
 	// Close statement if we own it
 	if r.closeStmt && r.stmt != nil {
 		return r.stmt.Close()
 	}
+	if r.release != nil {
+		r.release(r.stmt.lastErr)
+	}
 
 	return nil
 }
@@ -120,36 +269,280 @@ func (r *Rows) Next(dest []driver.Value) error {
 		return io.EOF
 	}
 
-	ret := Fetch(r.stmt.stmt)
-	if ret == SQL_NO_DATA {
+	// Statements without result columns have no rows to fetch
+	if len(r.columns) == 0 {
 		return io.EOF
 	}
-	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+
+	if err := r.ctxErr(); err != nil {
+		return err
 	}
 
-	// Get data for each column
-	for i := 0; i < len(dest); i++ {
-		val, err := r.getColumnData(SQLUSMALLINT(i + 1))
-		if err != nil {
+	start := time.Now()
+	defer func() { r.stats.FetchTime += time.Since(start) }()
+
+	r.rowGen++
+
+	if !r.blockChecked {
+		r.blockChecked = true
+		r.block = r.startBlockFetch()
+		r.startPrefetcher()
+	}
+	if r.block != nil {
+		if err := r.advanceBlock(); err != nil {
+			if ctxErr := r.ctxErr(); ctxErr != nil && err != io.EOF {
+				err = ctxErr
+			}
+			if err != io.EOF {
+				r.stmt.lastErr = err
+				r.stmt.conn.observeError(MetricsFetch, r.stmt.query, err)
+				recordSpanError(r.span, err)
+			}
 			return err
 		}
-		dest[i] = val
+	} else {
+		ret := Fetch(r.stmt.stmt)
+		if ret == SQL_NO_DATA {
+			return io.EOF
+		}
+		if !IsSuccess(ret) {
+			err := r.ctxErr()
+			if err == nil {
+				err = r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+			}
+			r.stmt.lastErr = err
+			recordSpanError(r.span, err)
+			return r.stmt.conn.observeError(MetricsFetch, r.stmt.query, err)
+		}
 	}
+	r.stats.RowsFetched++
 
+	return r.readRow(dest)
+}
+
+// readRow copies the values of the current row into dest. The columns are
+// read once per row, all of them and in increasing column order, and kept
+// until the cursor moves: drivers that do not report SQL_GD_ANY_ORDER cannot
+// return a column before the last one read or the same column again, so a
+// row read again, or read into fewer values than there are columns, is
+// served from the kept values. The kept values borrow driver buffers in every
+// copy mode, since they are dropped when the cursor moves; under CopyAlways,
+// dest gets copies of their []byte values (see ownValues), so each value is
+// copied once.
+func (r *Rows) readRow(dest []driver.Value) error {
+	if r.rowValues == nil || r.rowValuesGen != r.rowGen {
+		values := r.rowValues[:0]
+		r.rowValues = nil
+		r.readingRow = true
+		for i := range r.columns {
+			val, err := r.getColumnData(SQLUSMALLINT(i + 1))
+			if err != nil {
+				r.readingRow = false
+				return err
+			}
+			values = append(values, val)
+		}
+		r.readingRow = false
+		r.rowValues, r.rowValuesGen = values, r.rowGen
+	}
+	copy(dest, r.rowValues)
+	r.ownValues(dest)
 	return nil
 }
 
-// getColumnData retrieves data for a single column
+// ColumnIndex returns the index of the named result column, or -1 if there is none.
+// Quoted names ("Name", [Name] or `Name`) must match exactly. Unquoted names match
+// exactly, then as the database folds identifiers (see Conn.IdentifierCase), then
+// case-insensitively if exactly one column matches, so lookups keep working when a
+// driver reports result column names in upper or lower case.
+func (r *Rows) ColumnIndex(name string) int {
+	if unquoted, ok := unquoteIdentifier(name); ok {
+		return indexOf(r.columns, unquoted)
+	}
+	if i := indexOf(r.columns, name); i >= 0 {
+		return i
+	}
+	if r.stmt != nil && r.stmt.conn != nil {
+		if folded := r.stmt.conn.NormalizeIdentifier(name); folded != name {
+			if i := indexOf(r.columns, folded); i >= 0 {
+				return i
+			}
+		}
+	}
+
+	match := -1
+	for i, col := range r.columns {
+		if strings.EqualFold(col, name) {
+			if match >= 0 {
+				return -1 // Ambiguous: columns differ only in case
+			}
+			match = i
+		}
+	}
+	return match
+}
+
+// indexOf returns the index of the first exact match of name in columns, or -1
+func indexOf(columns []string, name string) int {
+	for i, col := range columns {
+		if col == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// watchContext ties fetching to the query's context. Next returns ctx.Err()
+// once ctx is done, and a fetch blocked on a stalled server is cancelled with
+// SQLCancel so it returns instead of hanging.
+func (r *Rows) watchContext(ctx context.Context) {
+	r.ctx = ctx
+	if ctx.Done() == nil {
+		return
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	stmt := r.stmt.stmt
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			Cancel(stmt)
+		case <-done:
+		}
+	}()
+	r.unwatch = func() {
+		close(done)
+		// Wait for a Cancel in progress, which must not outlive the handle
+		<-exited
+	}
+}
+
+// stopWatch stops cancelling fetches when the query's context is done
+func (r *Rows) stopWatch() {
+	if r.unwatch != nil {
+		r.unwatch()
+		r.unwatch = nil
+	}
+}
+
+// ctxErr returns the error of the query's context once it is done
+func (r *Rows) ctxErr() error {
+	if r.ctx == nil {
+		return nil
+	}
+	return r.ctx.Err()
+}
+
+// setStats starts statistics for an executed query and attaches the context's collector
+func (r *Rows) setStats(ctx context.Context, prepareTime, executeTime time.Duration) {
+	r.stats = QueryStats{Queries: 1, PrepareTime: prepareTime, ExecuteTime: executeTime}
+	r.collector = statsCollectorFromContext(ctx)
+}
+
+// Stats returns the statistics of the query: rows fetched, SQLGetData calls and
+// bytes read, and time spent preparing, executing and fetching. Values are
+// final once the rows are closed.
+func (r *Rows) Stats() QueryStats {
+	return r.stats
+}
+
+// getData calls SQLGetData and records the call and the bytes it returned
+func (r *Rows) getData(colNum SQLUSMALLINT, targetType SQLSMALLINT, targetValue uintptr, bufferLen SQLLEN, strLenOrInd *SQLLEN) SQLRETURN {
+	ret := GetData(r.stmt.stmt, colNum, targetType, targetValue, bufferLen, strLenOrInd)
+	r.stats.GetDataCalls++
+	if IsSuccess(ret) && !isNullIndicator(*strLenOrInd) {
+		n := *strLenOrInd
+		if n < 0 || n > bufferLen {
+			n = bufferLen // SQL_NO_TOTAL or truncated: the buffer was filled
+		}
+		r.stats.BytesRead += int64(n)
+	}
+	return ret
+}
+
+// setColumnCasts installs a per-query cast map and resolves it against the current columns
+func (r *Rows) setColumnCasts(casts ColumnCasts) {
+	r.castMap = casts
+	r.resolveCasts()
+}
+
+// resolveCasts resolves the cast of each column of the current result set:
+// the per-query cast of its name, or else the quirk cast of its native type
+func (r *Rows) resolveCasts() {
+	r.casts = r.castMap.resolve(r.columns)
+	if r.stmt == nil || r.stmt.conn == nil {
+		return
+	}
+	typeCasts := r.stmt.conn.quirk.typeCasts(r.nativeTypes)
+	if typeCasts == nil {
+		return
+	}
+	if r.casts == nil {
+		r.casts = typeCasts
+		return
+	}
+	for i, cast := range r.casts {
+		if cast == CastNone {
+			r.casts[i] = typeCasts[i]
+		}
+	}
+}
+
+// getColumnData retrieves data for a single column, applying any configured cast
 func (r *Rows) getColumnData(colNum SQLUSMALLINT) (interface{}, error) {
+	idx := int(colNum) - 1
+	if r.isRaw(idx) {
+		if r.block != nil {
+			return r.blockValue(idx)
+		}
+		return r.getRaw(colNum, r.colTypes[idx], r.colSizes[idx])
+	}
+	if idx >= 0 && idx < len(r.lobTypes) && r.lobTypes[idx] != 0 {
+		return r.newLOBReader(colNum, r.lobTypes[idx])
+	}
+	val, err := r.fetchColumnData(colNum)
+	if err == nil && idx >= 0 && idx < len(r.pgKinds) {
+		val = decodePGValue(val, r.pgKinds[idx])
+	}
+	if err == nil && idx >= 0 && idx < len(r.boolRules) && r.boolRules[idx] != nil {
+		val = r.boolRules[idx].toBool(val)
+	}
+	if err != nil || r.casts == nil {
+		return val, err
+	}
+	if idx < 0 || idx >= len(r.casts) {
+		return val, nil
+	}
+	val, err = castValue(val, r.casts[idx])
+	if err != nil {
+		return nil, fmt.Errorf("column %q: %w", r.columns[idx], err)
+	}
+	return val, nil
+}
+
+// fetchColumnData retrieves the native value for a single column
+func (r *Rows) fetchColumnData(colNum SQLUSMALLINT) (interface{}, error) {
 	idx := int(colNum) - 1
 	if idx < 0 || idx >= len(r.colTypes) {
 		return nil, nil
 	}
 
+	if r.block != nil {
+		return r.blockValue(idx)
+	}
+
 	colType := r.colTypes[idx]
 	colSize := r.colSizes[idx]
 
+	if r.isUnsigned(idx) {
+		if colType == SQL_BIGINT {
+			return r.getUint64(colNum)
+		}
+		// Every unsigned value of a smaller type fits in an int64
+		return r.getInt64(colNum)
+	}
+
 	switch colType {
 	case SQL_BIT, SQL_BOOLEAN:
 		return r.getBool(colNum)
@@ -166,9 +559,15 @@ func (r *Rows) getColumnData(colNum SQLUSMALLINT) (interface{}, error) {
 	case SQL_FLOAT, SQL_DOUBLE:
 		return r.getFloat64(colNum)
 	case SQL_NUMERIC, SQL_DECIMAL:
+		if r.decimalFetchMode() == DecimalFetchNumeric {
+			return r.getNumeric(colNum, colSize)
+		}
 		// Get as string and parse
 		return r.getString(colNum, colSize)
 	case SQL_CHAR, SQL_VARCHAR, SQL_LONGVARCHAR:
+		if r.wideFetch() {
+			return r.getWideString(colNum, colSize)
+		}
 		return r.getString(colNum, colSize)
 	case SQL_WCHAR, SQL_WVARCHAR, SQL_WLONGVARCHAR:
 		return r.getWideString(colNum, colSize)
@@ -189,8 +588,20 @@ func (r *Rows) getColumnData(colNum SQLUSMALLINT) (interface{}, error) {
 		SQL_INTERVAL_DAY_TO_HOUR, SQL_INTERVAL_DAY_TO_MINUTE, SQL_INTERVAL_DAY_TO_SECOND,
 		SQL_INTERVAL_HOUR_TO_MINUTE, SQL_INTERVAL_HOUR_TO_SECOND, SQL_INTERVAL_MINUTE_TO_SECOND:
 		return r.getIntervalDaySecond(colNum)
+	case SQL_TYPE_TIME_WITH_TIMEZONE, SQL_TYPE_TIMESTAMP_WITH_TIMEZONE:
+		return r.getTimeWithZone(colNum, colSize, colType == SQL_TYPE_TIME_WITH_TIMEZONE)
+	// Driver-specific types
+	case SQL_SS_TIME2:
+		return r.getTime2(colNum)
+	case SQL_SS_TIMESTAMPOFFSET:
+		return r.getTimestampOffset(colNum)
+	case SQL_SS_XML, SQL_DB2_XML, SQL_DB2_CLOB, SQL_DB2_DBCLOB,
+		SQL_DB2_GRAPHIC, SQL_DB2_VARGRAPHIC, SQL_DB2_LONGVARGRAPHIC:
+		return r.getWideString(colNum, colSize)
+	case SQL_DB2_BLOB, SQL_SS_UDT:
+		return r.getBytes(colNum, colSize)
 	default:
-		// Default to string
+		// Default to string (also covers SQL_SS_VARIANT, SQL_DB2_DECFLOAT)
 		return r.getString(colNum, colSize)
 	}
 }
@@ -198,9 +609,9 @@ func (r *Rows) getColumnData(colNum SQLUSMALLINT) (interface{}, error) {
 func (r *Rows) getBool(colNum SQLUSMALLINT) (interface{}, error) {
 	var value byte
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_BIT, uintptr(unsafe.Pointer(&value)), 1, &indicator)
+	ret := r.getData(colNum, SQL_C_BIT, uintptr(unsafe.Pointer(&value)), 1, &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	// Check for NULL - some ODBC drivers return -1 as a 32-bit value that gets
 	// zero-extended to 64-bit (0xFFFFFFFF = 4294967295 instead of -1)
@@ -213,9 +624,9 @@ func (r *Rows) getBool(colNum SQLUSMALLINT) (interface{}, error) {
 func (r *Rows) getInt8(colNum SQLUSMALLINT) (interface{}, error) {
 	var value int8
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_STINYINT, uintptr(unsafe.Pointer(&value)), 1, &indicator)
+	ret := r.getData(colNum, SQL_C_STINYINT, uintptr(unsafe.Pointer(&value)), 1, &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
@@ -226,9 +637,9 @@ func (r *Rows) getInt8(colNum SQLUSMALLINT) (interface{}, error) {
 func (r *Rows) getInt16(colNum SQLUSMALLINT) (interface{}, error) {
 	var value int16
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_SSHORT, uintptr(unsafe.Pointer(&value)), 2, &indicator)
+	ret := r.getData(colNum, SQL_C_SSHORT, uintptr(unsafe.Pointer(&value)), 2, &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
@@ -239,9 +650,9 @@ func (r *Rows) getInt16(colNum SQLUSMALLINT) (interface{}, error) {
 func (r *Rows) getInt32(colNum SQLUSMALLINT) (interface{}, error) {
 	var value int32
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_SLONG, uintptr(unsafe.Pointer(&value)), 4, &indicator)
+	ret := r.getData(colNum, SQL_C_SLONG, uintptr(unsafe.Pointer(&value)), 4, &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
@@ -252,9 +663,9 @@ func (r *Rows) getInt32(colNum SQLUSMALLINT) (interface{}, error) {
 func (r *Rows) getInt64(colNum SQLUSMALLINT) (interface{}, error) {
 	var value int64
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_SBIGINT, uintptr(unsafe.Pointer(&value)), 8, &indicator)
+	ret := r.getData(colNum, SQL_C_SBIGINT, uintptr(unsafe.Pointer(&value)), 8, &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
@@ -262,12 +673,32 @@ func (r *Rows) getInt64(colNum SQLUSMALLINT) (interface{}, error) {
 	return value, nil
 }
 
+// getUint64 fetches an unsigned BIGINT column, whose values above
+// math.MaxInt64 would wrap around to negative numbers as an int64
+func (r *Rows) getUint64(colNum SQLUSMALLINT) (interface{}, error) {
+	var value uint64
+	var indicator SQLLEN
+	ret := r.getData(colNum, SQL_C_UBIGINT, uintptr(unsafe.Pointer(&value)), 8, &indicator)
+	if !IsSuccess(ret) {
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+	}
+	if isNullIndicator(indicator) {
+		return nil, nil
+	}
+	return value, nil
+}
+
+// isUnsigned reports whether a column is an unsigned integer column
+func (r *Rows) isUnsigned(idx int) bool {
+	return idx < len(r.unsigned) && r.unsigned[idx]
+}
+
 func (r *Rows) getFloat32(colNum SQLUSMALLINT) (interface{}, error) {
 	var value float32
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_FLOAT, uintptr(unsafe.Pointer(&value)), 4, &indicator)
+	ret := r.getData(colNum, SQL_C_FLOAT, uintptr(unsafe.Pointer(&value)), 4, &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
@@ -278,9 +709,9 @@ func (r *Rows) getFloat32(colNum SQLUSMALLINT) (interface{}, error) {
 func (r *Rows) getFloat64(colNum SQLUSMALLINT) (interface{}, error) {
 	var value float64
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_DOUBLE, uintptr(unsafe.Pointer(&value)), 8, &indicator)
+	ret := r.getData(colNum, SQL_C_DOUBLE, uintptr(unsafe.Pointer(&value)), 8, &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
@@ -301,13 +732,16 @@ func (r *Rows) getString(colNum SQLUSMALLINT, colSize SQLULEN) (interface{}, err
 	buf := make([]byte, bufSize)
 	var indicator SQLLEN
 
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_CHAR, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(len(buf)), &indicator)
+	ret := r.getData(colNum, SQL_C_CHAR, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(len(buf)), &indicator)
 	if !IsSuccess(ret) && ret != SQL_SUCCESS_WITH_INFO {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
 	}
+	if r.exceedsColumnLimit(ret, indicator) {
+		return r.limitedValue(colNum, SQL_C_CHAR, buf, indicator)
+	}
 
 	// Handle data truncation - need larger buffer
 	if ret == SQL_SUCCESS_WITH_INFO && indicator > SQLLEN(len(buf)-1) {
@@ -327,7 +761,7 @@ func (r *Rows) getString(colNum SQLUSMALLINT, colSize SQLULEN) (interface{}, err
 			if chunkSize > len(buf) {
 				chunkSize = len(buf)
 			}
-			ret = GetData(r.stmt.stmt, colNum, SQL_C_CHAR, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(chunkSize), &indicator)
+			ret = r.getData(colNum, SQL_C_CHAR, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(chunkSize), &indicator)
 			if !IsSuccess(ret) && ret != SQL_SUCCESS_WITH_INFO {
 				break
 			}
@@ -367,16 +801,19 @@ func (r *Rows) getBytes(colNum SQLUSMALLINT, colSize SQLULEN) (interface{}, erro
 		bufSize = 65536 // Cap initial buffer
 	}
 
-	buf := make([]byte, bufSize)
+	buf := r.byteBuffer(colNum, bufSize)
 	var indicator SQLLEN
 
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_BINARY, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(len(buf)), &indicator)
+	ret := r.getData(colNum, SQL_C_BINARY, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(len(buf)), &indicator)
 	if !IsSuccess(ret) && ret != SQL_SUCCESS_WITH_INFO {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
 	}
+	if r.exceedsColumnLimit(ret, indicator) {
+		return r.limitedValue(colNum, SQL_C_BINARY, buf, indicator)
+	}
 
 	// Handle data truncation
 	if ret == SQL_SUCCESS_WITH_INFO && indicator > SQLLEN(len(buf)) {
@@ -395,7 +832,7 @@ func (r *Rows) getBytes(colNum SQLUSMALLINT, colSize SQLULEN) (interface{}, erro
 			if chunkSize > len(buf) {
 				chunkSize = len(buf)
 			}
-			ret = GetData(r.stmt.stmt, colNum, SQL_C_BINARY, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(chunkSize), &indicator)
+			ret = r.getData(colNum, SQL_C_BINARY, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(chunkSize), &indicator)
 			if !IsSuccess(ret) && ret != SQL_SUCCESS_WITH_INFO {
 				break
 			}
@@ -413,7 +850,7 @@ func (r *Rows) getBytes(colNum SQLUSMALLINT, colSize SQLULEN) (interface{}, erro
 	}
 
 	if indicator >= 0 && int(indicator) <= len(buf) {
-		return buf[:indicator], nil
+		return buf[:indicator:indicator], nil
 	}
 	return buf, nil
 }
@@ -421,22 +858,22 @@ func (r *Rows) getBytes(colNum SQLUSMALLINT, colSize SQLULEN) (interface{}, erro
 func (r *Rows) getDate(colNum SQLUSMALLINT) (interface{}, error) {
 	var date SQL_DATE_STRUCT
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_DATE, uintptr(unsafe.Pointer(&date)), SQLLEN(unsafe.Sizeof(date)), &indicator)
+	ret := r.getData(colNum, SQL_C_DATE, uintptr(unsafe.Pointer(&date)), SQLLEN(unsafe.Sizeof(date)), &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
 	}
-	return time.Date(int(date.Year), time.Month(date.Month), int(date.Day), 0, 0, 0, 0, time.UTC), nil
+	return dateTimeValue(int(date.Year), int(date.Month), int(date.Day), 0, 0, 0, 0, r.outOfRangeTimeMode()), nil
 }
 
 func (r *Rows) getTime(colNum SQLUSMALLINT) (interface{}, error) {
 	var t SQL_TIME_STRUCT
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_TIME, uintptr(unsafe.Pointer(&t)), SQLLEN(unsafe.Sizeof(t)), &indicator)
+	ret := r.getData(colNum, SQL_C_TIME, uintptr(unsafe.Pointer(&t)), SQLLEN(unsafe.Sizeof(t)), &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
@@ -445,23 +882,127 @@ func (r *Rows) getTime(colNum SQLUSMALLINT) (interface{}, error) {
 }
 
 func (r *Rows) getTimestamp(colNum SQLUSMALLINT) (interface{}, error) {
-	var ts SQL_TIMESTAMP_STRUCT
+	ts := &r.tsBuf
+	*ts = SQL_TIMESTAMP_STRUCT{}
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_TIMESTAMP, uintptr(unsafe.Pointer(&ts)), SQLLEN(unsafe.Sizeof(ts)), &indicator)
+	ret := r.getData(colNum, SQL_C_TIMESTAMP, uintptr(unsafe.Pointer(ts)), SQLLEN(unsafe.Sizeof(*ts)), &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
 	}
+	if r.timestampFetchMode() == TimestampFetchEpochNanos {
+		return timestampToEpochNanos(ts), nil
+	}
 	// Fraction is in billionths of a second, convert to nanoseconds
 	nanos := int(ts.Fraction)
-	return time.Date(int(ts.Year), time.Month(ts.Month), int(ts.Day),
-		int(ts.Hour), int(ts.Minute), int(ts.Second), nanos, time.UTC), nil
+	return dateTimeValue(int(ts.Year), int(ts.Month), int(ts.Day),
+		int(ts.Hour), int(ts.Minute), int(ts.Second), nanos, r.outOfRangeTimeMode()), nil
+}
+
+// getTime2 fetches a SQL Server time(n) column with its fractional seconds
+func (r *Rows) getTime2(colNum SQLUSMALLINT) (interface{}, error) {
+	var t SQL_SS_TIME2_STRUCT
+	var indicator SQLLEN
+	ret := r.getData(colNum, SQL_C_SS_TIME2, uintptr(unsafe.Pointer(&t)), SQLLEN(unsafe.Sizeof(t)), &indicator)
+	if !IsSuccess(ret) {
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+	}
+	if isNullIndicator(indicator) {
+		return nil, nil
+	}
+	return time.Date(0, 1, 1, int(t.Hour), int(t.Minute), int(t.Second), int(t.Fraction), time.UTC), nil
+}
+
+// getTimestampOffset fetches a SQL Server datetimeoffset column as a
+// time.Time in a zone with the stored offset
+func (r *Rows) getTimestampOffset(colNum SQLUSMALLINT) (interface{}, error) {
+	var ts SQL_SS_TIMESTAMPOFFSET_STRUCT
+	var indicator SQLLEN
+	ret := r.getData(colNum, SQL_C_SS_TIMESTAMPOFFSET, uintptr(unsafe.Pointer(&ts)), SQLLEN(unsafe.Sizeof(ts)), &indicator)
+	if !IsSuccess(ret) {
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+	}
+	if isNullIndicator(indicator) {
+		return nil, nil
+	}
+	return offsetTimeValue(int(ts.Year), int(ts.Month), int(ts.Day), int(ts.Hour), int(ts.Minute), int(ts.Second),
+		int(ts.Fraction), int(ts.TimezoneHour), int(ts.TimezoneMinute), r.outOfRangeTimeMode()), nil
+}
+
+// getTimeWithZone fetches an ODBC 4.0 TIME or TIMESTAMP WITH TIME ZONE column.
+// Few drivers support the matching C types, so the value is read as text and
+// parsed; text that cannot be parsed is returned unchanged.
+func (r *Rows) getTimeWithZone(colNum SQLUSMALLINT, colSize SQLULEN, timeOnly bool) (interface{}, error) {
+	v, err := r.getString(colNum, colSize)
+	s, ok := v.(string)
+	if err != nil || !ok {
+		return v, err
+	}
+	if t, ok := parseTimeWithZone(s, timeOnly); ok {
+		return t, nil
+	}
+	return s, nil
+}
+
+// outOfRangeTimeMode returns the connection's handling of unrepresentable dates and timestamps
+func (r *Rows) outOfRangeTimeMode() OutOfRangeTimeMode {
+	if r.stmt == nil || r.stmt.conn == nil {
+		return OutOfRangeTimeValue
+	}
+	return r.stmt.conn.outOfRangeTime
+}
+
+// timestampFetchMode returns the connection's timestamp fetch mode
+func (r *Rows) timestampFetchMode() TimestampFetchMode {
+	if r.stmt == nil || r.stmt.conn == nil {
+		return TimestampFetchTime
+	}
+	return r.stmt.conn.timestampFetchMode
+}
+
+// timestampToEpochNanos converts a timestamp struct (interpreted as UTC) to
+// nanoseconds since the Unix epoch without constructing a time.Time.
+// Results outside the int64 range are clamped.
+func timestampToEpochNanos(ts *SQL_TIMESTAMP_STRUCT) int64 {
+	const (
+		maxSeconds = math.MaxInt64 / 1_000_000_000
+		minSeconds = math.MinInt64 / 1_000_000_000
+	)
+	days := daysFromCivil(int64(ts.Year), int64(ts.Month), int64(ts.Day))
+	secs := days*86400 + int64(ts.Hour)*3600 + int64(ts.Minute)*60 + int64(ts.Second)
+	if secs >= maxSeconds {
+		return math.MaxInt64
+	}
+	if secs <= minSeconds {
+		return math.MinInt64
+	}
+	return secs*1_000_000_000 + int64(ts.Fraction)
+}
+
+// daysFromCivil returns the number of days since 1970-01-01 for a proleptic
+// Gregorian date (Howard Hinnant's days_from_civil algorithm)
+func daysFromCivil(y, m, d int64) int64 {
+	if m <= 2 {
+		y--
+	}
+	era := y / 400
+	if y < 0 && y%400 != 0 {
+		era--
+	}
+	yoe := y - era*400                     // [0, 399]
+	doy := (153*((m+9)%12)+2)/5 + d - 1    // [0, 365]
+	doe := yoe*365 + yoe/4 - yoe/100 + doy // [0, 146096]
+	return era*146097 + doe - 719468
 }
 
 // getWideString retrieves a wide character (UTF-16) string and converts to UTF-8
 func (r *Rows) getWideString(colNum SQLUSMALLINT, colSize SQLULEN) (interface{}, error) {
+	if wcharSize() == 4 {
+		return r.getWideString32(colNum, colSize)
+	}
+
 	// Buffer size in UTF-16 code units (2 bytes each)
 	bufSize := int(colSize) + 1
 	if bufSize < 256 {
@@ -475,13 +1016,16 @@ func (r *Rows) getWideString(colNum SQLUSMALLINT, colSize SQLULEN) (interface{},
 	buf := make([]uint16, bufSize)
 	var indicator SQLLEN
 
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_WCHAR, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(len(buf)*2), &indicator)
+	ret := r.getData(colNum, SQL_C_WCHAR, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(len(buf)*2), &indicator)
 	if !IsSuccess(ret) && ret != SQL_SUCCESS_WITH_INFO {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
 	}
+	if r.exceedsColumnLimit(ret, indicator) {
+		return r.limitedValue(colNum, SQL_C_WCHAR, unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), len(buf)*2), indicator)
+	}
 
 	// Handle data truncation - need larger buffer
 	if ret == SQL_SUCCESS_WITH_INFO && indicator > SQLLEN((len(buf)-1)*2) {
@@ -504,7 +1048,7 @@ func (r *Rows) getWideString(colNum SQLUSMALLINT, colSize SQLULEN) (interface{},
 			if chunkUnits > len(buf) {
 				chunkUnits = len(buf)
 			}
-			ret = GetData(r.stmt.stmt, colNum, SQL_C_WCHAR, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(chunkUnits*2), &indicator)
+			ret = r.getData(colNum, SQL_C_WCHAR, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(chunkUnits*2), &indicator)
 			if !IsSuccess(ret) && ret != SQL_SUCCESS_WITH_INFO {
 				break
 			}
@@ -538,48 +1082,178 @@ func (r *Rows) getWideString(colNum SQLUSMALLINT, colSize SQLULEN) (interface{},
 	return utf16ToString(buf), nil
 }
 
-// utf16ToString converts a UTF-16 encoded slice to a UTF-8 string
-func utf16ToString(u []uint16) string {
-	// Convert UTF-16 to runes, then to string
-	runes := make([]rune, 0, len(u))
-	for i := 0; i < len(u); i++ {
-		r := u[i]
-		if r >= 0xD800 && r <= 0xDBFF && i+1 < len(u) {
-			// High surrogate - check for low surrogate
-			r2 := u[i+1]
-			if r2 >= 0xDC00 && r2 <= 0xDFFF {
-				// Valid surrogate pair - decode to rune
-				runes = append(runes, rune(((int(r)-0xD800)<<10)+(int(r2)-0xDC00)+0x10000))
-				i++
-				continue
+// getWideString32 retrieves a wide character string from a driver manager with
+// 4-byte SQLWCHAR (UTF-32, such as iODBC) and converts to UTF-8
+func (r *Rows) getWideString32(colNum SQLUSMALLINT, colSize SQLULEN) (interface{}, error) {
+	// Buffer size in UTF-32 code units (4 bytes each)
+	bufSize := int(colSize) + 1
+	if bufSize < 256 {
+		bufSize = 256
+	}
+	if bufSize > 16384 {
+		bufSize = 16384 // Cap initial buffer (in code units)
+	}
+
+	buf := make([]uint32, bufSize)
+	var indicator SQLLEN
+
+	ret := r.getData(colNum, SQL_C_WCHAR, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(len(buf)*4), &indicator)
+	if !IsSuccess(ret) && ret != SQL_SUCCESS_WITH_INFO {
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+	}
+	if isNullIndicator(indicator) {
+		return nil, nil
+	}
+	if r.exceedsColumnLimit(ret, indicator) {
+		return r.limitedValue(colNum, SQL_C_WCHAR, unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), len(buf)*4), indicator)
+	}
+
+	// Handle data truncation - fetch the remaining data in chunks
+	if ret == SQL_SUCCESS_WITH_INFO && indicator > SQLLEN((len(buf)-1)*4) {
+		totalUnits := int(indicator) / 4
+		fetchedUnits := len(buf) - 1
+		result := make([]uint32, 0, totalUnits)
+		result = append(result, buf[:fetchedUnits]...)
+
+		remaining := totalUnits - fetchedUnits
+		iterations := 0
+		for remaining > 0 {
+			iterations++
+			if iterations > maxFetchIterations {
+				break // Prevent infinite loop on driver bugs
 			}
+			chunkUnits := remaining + 1
+			if chunkUnits > len(buf) {
+				chunkUnits = len(buf)
+			}
+			ret = r.getData(colNum, SQL_C_WCHAR, uintptr(unsafe.Pointer(&buf[0])), SQLLEN(chunkUnits*4), &indicator)
+			if !IsSuccess(ret) && ret != SQL_SUCCESS_WITH_INFO {
+				break
+			}
+			if ret == SQL_NO_DATA || isNullIndicator(indicator) {
+				break
+			}
+			copyUnits := int(indicator) / 4
+			if copyUnits > chunkUnits-1 {
+				copyUnits = chunkUnits - 1
+			}
+			result = append(result, buf[:copyUnits]...)
+			remaining -= copyUnits
 		}
-		runes = append(runes, rune(r))
+		return utf32ToString(result), nil
 	}
-	return string(runes)
+
+	// Normal case - data fit in buffer
+	if indicator >= 0 {
+		numUnits := int(indicator) / 4
+		if numUnits > len(buf)-1 {
+			numUnits = len(buf) - 1
+		}
+		return utf32ToString(buf[:numUnits]), nil
+	}
+	// Find null terminator
+	for i, c := range buf {
+		if c == 0 {
+			return utf32ToString(buf[:i]), nil
+		}
+	}
+	return utf32ToString(buf), nil
 }
 
 // getGUID retrieves a GUID value as a formatted string
 func (r *Rows) getGUID(colNum SQLUSMALLINT) (interface{}, error) {
 	var guid SQL_GUID_STRUCT
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_GUID, uintptr(unsafe.Pointer(&guid)), SQLLEN(unsafe.Sizeof(guid)), &indicator)
+	ret := r.getData(colNum, SQL_C_GUID, uintptr(unsafe.Pointer(&guid)), SQLLEN(unsafe.Sizeof(guid)), &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
 	}
+	if r.guidFetchMode() == GUIDFetchBinary {
+		g := guidFromStruct(guid)
+		return g[:], nil
+	}
 	return guid.String(), nil
 }
 
+// guidFetchMode returns the connection's GUID fetch mode
+func (r *Rows) guidFetchMode() GUIDFetchMode {
+	if r.stmt == nil || r.stmt.conn == nil {
+		return GUIDFetchString
+	}
+	return r.stmt.conn.guidFetchMode
+}
+
+// getNumeric retrieves a DECIMAL/NUMERIC value as SQL_C_NUMERIC and returns
+// it as a Decimal. SQLGetData with SQL_C_NUMERIC uses the driver's default
+// scale, usually 0, so the column's precision and scale are set on the
+// application row descriptor and the value is fetched with SQL_ARD_TYPE.
+// Columns without a usable precision, or drivers that do not support
+// descriptor fields, are read as text and normalized instead.
+func (r *Rows) getNumeric(colNum SQLUSMALLINT, colSize SQLULEN) (interface{}, error) {
+	idx := int(colNum) - 1
+	precision, scale := int(colSize), int(r.decDigits[idx])
+	if r.sizeUnknown[idx] || precision < 1 || precision > 38 || !r.setNumericARD(colNum, precision, scale) {
+		value, err := r.getString(colNum, colSize)
+		if s, ok := value.(string); ok && err == nil {
+			if r.sizeUnknown[idx] {
+				precision, scale = 0, -1
+			}
+			if d, ok := decimalFromText(s, precision, scale); ok {
+				return d, nil
+			}
+		}
+		return value, err
+	}
+
+	var ns SQL_NUMERIC_STRUCT
+	var indicator SQLLEN
+	ret := r.getData(colNum, SQL_ARD_TYPE, uintptr(unsafe.Pointer(&ns)), SQLLEN(unsafe.Sizeof(ns)), &indicator)
+	if !IsSuccess(ret) {
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+	}
+	if isNullIndicator(indicator) {
+		return nil, nil
+	}
+	return decimalFromNumeric(ns), nil
+}
+
+// setNumericARD describes a column as SQL_C_NUMERIC with the given precision
+// and scale in the application row descriptor, reporting whether the driver
+// accepted it. SQL_DESC_TYPE resets the precision and scale, so it is set first.
+func (r *Rows) setNumericARD(colNum SQLUSMALLINT, precision, scale int) bool {
+	var ard SQLHDESC
+	if !IsSuccess(GetStmtAttr(r.stmt.stmt, SQL_ATTR_APP_ROW_DESC, uintptr(unsafe.Pointer(&ard)), 0, nil)) {
+		return false
+	}
+	recNum := SQLSMALLINT(colNum)
+	return IsSuccess(SetDescField(ard, recNum, SQL_DESC_TYPE, uintptr(SQL_C_NUMERIC), 0)) &&
+		IsSuccess(SetDescField(ard, recNum, SQL_DESC_PRECISION, uintptr(precision), 0)) &&
+		IsSuccess(SetDescField(ard, recNum, SQL_DESC_SCALE, uintptr(scale), 0))
+}
+
+// wideFetch reports whether character columns are fetched as SQL_C_WCHAR
+func (r *Rows) wideFetch() bool {
+	return r.stmt != nil && r.stmt.conn != nil && r.stmt.conn.wideFetch
+}
+
+// decimalFetchMode returns the connection's decimal fetch mode
+func (r *Rows) decimalFetchMode() DecimalFetchMode {
+	if r.stmt == nil || r.stmt.conn == nil {
+		return DecimalFetchString
+	}
+	return r.stmt.conn.decimalFetchMode
+}
+
 // getIntervalYearMonth retrieves a year-month interval value
 func (r *Rows) getIntervalYearMonth(colNum SQLUSMALLINT) (interface{}, error) {
 	var is SQL_INTERVAL_STRUCT
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_INTERVAL_YEAR_TO_MONTH, uintptr(unsafe.Pointer(&is)), SQLLEN(unsafe.Sizeof(is)), &indicator)
+	ret := r.getData(colNum, SQL_C_INTERVAL_YEAR_TO_MONTH, uintptr(unsafe.Pointer(&is)), SQLLEN(unsafe.Sizeof(is)), &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
@@ -595,9 +1269,9 @@ func (r *Rows) getIntervalYearMonth(colNum SQLUSMALLINT) (interface{}, error) {
 func (r *Rows) getIntervalDaySecond(colNum SQLUSMALLINT) (interface{}, error) {
 	var is SQL_INTERVAL_STRUCT
 	var indicator SQLLEN
-	ret := GetData(r.stmt.stmt, colNum, SQL_C_INTERVAL_DAY_TO_SECOND, uintptr(unsafe.Pointer(&is)), SQLLEN(unsafe.Sizeof(is)), &indicator)
+	ret := r.getData(colNum, SQL_C_INTERVAL_DAY_TO_SECOND, uintptr(unsafe.Pointer(&is)), SQLLEN(unsafe.Sizeof(is)), &indicator)
 	if !IsSuccess(ret) {
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return nil, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	if isNullIndicator(indicator) {
 		return nil, nil
@@ -619,29 +1293,80 @@ func (r *Rows) ColumnTypeScanType(index int) reflect.Type {
 		return reflect.TypeOf(new(interface{})).Elem()
 	}
 
+	if r.isRaw(index) {
+		return reflect.TypeOf([]byte{})
+	}
+
+	// A configured cast determines the scan type
+	if index < len(r.casts) {
+		if t := castScanType(r.casts[index]); t != nil {
+			return t
+		}
+	}
+
+	if index < len(r.lobTypes) && r.lobTypes[index] != 0 {
+		return reflect.TypeOf((*LOBReader)(nil))
+	}
+
+	if index < len(r.boolRules) && r.boolRules[index] != nil {
+		return reflect.TypeOf(false)
+	}
+
+	if index < len(r.pgKinds) {
+		switch r.pgKinds[index] {
+		case pgValueBitString:
+			return reflect.TypeOf(BitString(""))
+		case pgValueBoolArray:
+			return reflect.TypeOf([]bool{})
+		}
+	}
+
 	switch r.colTypes[index] {
 	case SQL_BIT:
 		return reflect.TypeOf(false)
 	case SQL_TINYINT, SQL_SMALLINT, SQL_INTEGER, SQL_BIGINT:
+		if r.colTypes[index] == SQL_BIGINT && r.isUnsigned(index) {
+			return reflect.TypeOf(uint64(0))
+		}
 		return reflect.TypeOf(int64(0))
 	case SQL_REAL:
 		return reflect.TypeOf(float32(0))
 	case SQL_FLOAT, SQL_DOUBLE:
 		return reflect.TypeOf(float64(0))
 	case SQL_NUMERIC, SQL_DECIMAL:
+		if r.decimalFetchMode() == DecimalFetchNumeric {
+			return reflect.TypeOf(Decimal{})
+		}
 		return reflect.TypeOf("") // String preserves decimal precision
 	case SQL_CHAR, SQL_VARCHAR, SQL_LONGVARCHAR, SQL_WCHAR, SQL_WVARCHAR, SQL_WLONGVARCHAR:
 		return reflect.TypeOf("")
 	case SQL_BINARY, SQL_VARBINARY, SQL_LONGVARBINARY:
 		return reflect.TypeOf([]byte{})
-	case SQL_TYPE_DATE, SQL_TYPE_TIME, SQL_TYPE_TIMESTAMP, SQL_DATETIME:
+	case SQL_TYPE_TIMESTAMP, SQL_DATETIME:
+		if r.timestampFetchMode() == TimestampFetchEpochNanos {
+			return reflect.TypeOf(int64(0))
+		}
+		return reflect.TypeOf(time.Time{})
+	case SQL_TYPE_DATE, SQL_TYPE_TIME:
 		return reflect.TypeOf(time.Time{})
+	case SQL_GUID:
+		if r.guidFetchMode() == GUIDFetchBinary {
+			return reflect.TypeOf([]byte{})
+		}
+		return reflect.TypeOf("")
 	case SQL_INTERVAL_YEAR, SQL_INTERVAL_MONTH, SQL_INTERVAL_YEAR_TO_MONTH:
 		return reflect.TypeOf(IntervalYearMonth{})
 	case SQL_INTERVAL_DAY, SQL_INTERVAL_HOUR, SQL_INTERVAL_MINUTE, SQL_INTERVAL_SECOND,
 		SQL_INTERVAL_DAY_TO_HOUR, SQL_INTERVAL_DAY_TO_MINUTE, SQL_INTERVAL_DAY_TO_SECOND,
 		SQL_INTERVAL_HOUR_TO_MINUTE, SQL_INTERVAL_HOUR_TO_SECOND, SQL_INTERVAL_MINUTE_TO_SECOND:
 		return reflect.TypeOf(IntervalDaySecond{})
+	case SQL_SS_TIME2, SQL_SS_TIMESTAMPOFFSET, SQL_TYPE_TIME_WITH_TIMEZONE, SQL_TYPE_TIMESTAMP_WITH_TIMEZONE:
+		return reflect.TypeOf(time.Time{})
+	case SQL_SS_XML, SQL_DB2_XML, SQL_DB2_CLOB, SQL_DB2_DBCLOB, SQL_DB2_GRAPHIC, SQL_DB2_VARGRAPHIC,
+		SQL_DB2_LONGVARGRAPHIC, SQL_SS_VARIANT, SQL_DB2_DECFLOAT:
+		return reflect.TypeOf("")
+	case SQL_DB2_BLOB, SQL_SS_UDT:
+		return reflect.TypeOf([]byte{})
 	default:
 		return reflect.TypeOf(new(interface{})).Elem()
 	}
@@ -664,6 +1389,15 @@ func (r *Rows) ColumnTypeDatabaseTypeName(index int) string {
 	return r.odbcTypeName(index)
 }
 
+// ColumnSQLType returns the ODBC SQL type of a column as the driver describes
+// it, such as SQL_INTEGER or SQL_TYPE_DATE, or 0 if index is out of range
+func (r *Rows) ColumnSQLType(index int) SQLSMALLINT {
+	if index < 0 || index >= len(r.colTypes) {
+		return 0
+	}
+	return r.colTypes[index]
+}
+
 // odbcTypeName returns a generic type name based on the ODBC SQL type code.
 // This is used as a fallback when the native type name is not available.
 func (r *Rows) odbcTypeName(index int) string {
@@ -716,6 +1450,10 @@ func (r *Rows) odbcTypeName(index int) string {
 		return "TIME"
 	case SQL_TYPE_TIMESTAMP, SQL_DATETIME:
 		return "TIMESTAMP"
+	case SQL_TYPE_TIME_WITH_TIMEZONE:
+		return "TIME WITH TIME ZONE"
+	case SQL_TYPE_TIMESTAMP_WITH_TIMEZONE:
+		return "TIMESTAMP WITH TIME ZONE"
 	case SQL_GUID:
 		return "GUID"
 	// Interval types
@@ -746,12 +1484,17 @@ func (r *Rows) odbcTypeName(index int) string {
 	case SQL_INTERVAL_MINUTE_TO_SECOND:
 		return "INTERVAL MINUTE TO SECOND"
 	default:
+		if name, ok := LookupSQLTypeName(r.colTypes[index]); ok {
+			return name
+		}
 		return "UNKNOWN"
 	}
 }
 
 // ColumnTypeLength returns the maximum length for variable-length column types.
 // Returns ok=true for VARCHAR, VARBINARY, and similar types; ok=false for fixed types.
+// When the driver reports no usable size (e.g. VARCHAR(MAX)), the result depends on
+// the connection's UnknownColumnSizeBehavior; see ColumnSizeUnknown.
 func (r *Rows) ColumnTypeLength(index int) (length int64, ok bool) {
 	if index < 0 || index >= len(r.colSizes) {
 		return 0, false
@@ -760,11 +1503,35 @@ func (r *Rows) ColumnTypeLength(index int) (length int64, ok bool) {
 	switch r.colTypes[index] {
 	case SQL_CHAR, SQL_VARCHAR, SQL_LONGVARCHAR, SQL_WCHAR, SQL_WVARCHAR, SQL_WLONGVARCHAR,
 		SQL_BINARY, SQL_VARBINARY, SQL_LONGVARBINARY:
+		if r.ColumnSizeUnknown(index) {
+			if r.unknownColumnSizeBehavior() == UnknownColumnSizeNotOK {
+				return 0, false
+			}
+			return math.MaxInt64, true
+		}
 		return int64(r.colSizes[index]), true
 	}
 	return 0, false
 }
 
+// ColumnSizeUnknown reports whether the driver returned no usable size for a column
+// (0, a negative value such as SQL_NO_TOTAL, or an overflowed value).
+// Callers sizing buffers or generating DDL should treat such columns as unbounded.
+func (r *Rows) ColumnSizeUnknown(index int) bool {
+	if index < 0 || index >= len(r.sizeUnknown) {
+		return false
+	}
+	return r.sizeUnknown[index]
+}
+
+// unknownColumnSizeBehavior returns the connection's behavior for unknown column sizes
+func (r *Rows) unknownColumnSizeBehavior() UnknownColumnSizeBehavior {
+	if r.stmt == nil || r.stmt.conn == nil {
+		return UnknownColumnSizeUnbounded
+	}
+	return r.stmt.conn.unknownColumnSize
+}
+
 // ColumnTypeNullable reports whether a column may be null.
 // Returns ok=false if nullability cannot be determined.
 func (r *Rows) ColumnTypeNullable(index int) (nullable, ok bool) {
@@ -791,6 +1558,9 @@ func (r *Rows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok b
 	switch r.colTypes[index] {
 	case SQL_NUMERIC, SQL_DECIMAL:
 		// colSize = precision (total digits), decDigits = scale (digits after decimal)
+		if r.ColumnSizeUnknown(index) {
+			return 0, 0, false // Unconstrained NUMERIC
+		}
 		return int64(r.colSizes[index]), int64(r.decDigits[index]), true
 	default:
 		return 0, 0, false
@@ -806,57 +1576,144 @@ func (r *Rows) HasNextResultSet() bool {
 // NextResultSet advances to the next result set from a multi-result query.
 // Returns io.EOF if there are no more result sets.
 func (r *Rows) NextResultSet() error {
+	r.endBlockFetch()
+	r.rowGen++
 	ret := MoreResults(r.stmt.stmt)
 	if ret == SQL_NO_DATA {
 		return io.EOF
 	}
 	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 
 	// Re-fetch column info for new result set
 	var numCols SQLSMALLINT
 	ret = NumResultCols(r.stmt.stmt, &numCols)
 	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 
-	columns := make([]string, numCols)
-	colTypes := make([]SQLSMALLINT, numCols)
-	colSizes := make([]SQLULEN, numCols)
-	decDigits := make([]SQLSMALLINT, numCols)
-	nullable := make([]SQLSMALLINT, numCols)
-	nativeTypes := make([]string, numCols)
+	// The statement returns more than one result set; describe each one again
+	// on every execution
+	r.stmt.resultCols = nil
+	rc, err := describeResult(r.stmt, numCols)
+	if err != nil {
+		return err
+	}
+	r.setResultColumns(rc)
+	r.resolveCasts()
+	r.setLOBStreaming(r.lobStreaming)
+	r.raw = nil
+	r.mapKeys = nil
 
-	colName := make([]byte, 256)
-	typeName := make([]byte, 256)
-	for i := SQLUSMALLINT(1); i <= SQLUSMALLINT(numCols); i++ {
-		nameLen, dataType, colSize, decDigitsVal, nullableVal, ret := DescribeCol(r.stmt.stmt, i, colName)
-		if !IsSuccess(ret) {
-			return NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+	return nil
+}
+
+// =============================================================================
+// Null Bitmap Fetch Support
+// =============================================================================
+
+// NextWithNulls advances to the next row like Next, but reports NULL values through
+// the nulls slice instead of nil interfaces. A NULL column is set to the typed zero
+// value of its scan type (0, "", time.Time{}, ...) and nulls[i] is set to true, so
+// consumers can read dest without type-switching on nil.
+// Columns whose scan type is unknown keep a nil value.
+// Returns io.EOF when no more rows are available.
+func (r *Rows) NextWithNulls(dest []driver.Value, nulls []bool) error {
+	if len(nulls) < len(dest) {
+		return fmt.Errorf("nulls slice too short: got %d, need %d", len(nulls), len(dest))
+	}
+	if err := r.Next(dest); err != nil {
+		return err
+	}
+	for i := range dest {
+		nulls[i] = dest[i] == nil
+		if nulls[i] {
+			dest[i] = r.zeroValue(i)
 		}
+	}
+	return nil
+}
 
-		columns[i-1] = string(colName[:nameLen])
-		colTypes[i-1] = dataType
-		colSizes[i-1] = colSize
-		decDigits[i-1] = decDigitsVal
-		nullable[i-1] = nullableVal
+// zeroValue returns the typed zero value for a column based on its scan type
+func (r *Rows) zeroValue(index int) driver.Value {
+	t := r.ColumnTypeScanType(index)
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	return reflect.Zero(t).Interface()
+}
 
-		// Get native type name using SQLColAttribute with SQL_DESC_TYPE_NAME
-		strLen, _, attrRet := ColAttribute(r.stmt.stmt, i, SQL_DESC_TYPE_NAME, typeName)
-		if IsSuccess(attrRet) && strLen > 0 {
-			nativeTypes[i-1] = string(typeName[:strLen])
+// =============================================================================
+// Map Row Support
+// =============================================================================
+
+// NextMap advances to the next row and returns it as a map keyed by column name,
+// for dynamic pipelines and scripts where scanning into structs is overkill.
+// Values have normalized Go types: integers as int64, floating point as float64,
+// DECIMAL/NUMERIC as string, binary as []byte, NULL as nil, and DATE/TIME/TIMESTAMP
+// as time.Time in the connector's timezone (WithTimezone, defaults to UTC), also
+// when TimestampFetchEpochNanos is configured. Duplicate column names get a
+// numeric suffix ("id", "id_2"). Returns io.EOF when no more rows are available.
+func (r *Rows) NextMap() (map[string]interface{}, error) {
+	dest := make([]driver.Value, len(r.columns))
+	if err := r.Next(dest); err != nil {
+		return nil, err
+	}
+	if r.mapKeys == nil {
+		r.mapKeys = mapKeys(r.columns)
+	}
+
+	row := make(map[string]interface{}, len(dest))
+	for i, v := range dest {
+		row[r.mapKeys[i]] = r.normalizeMapValue(i, v)
+	}
+	return row, nil
+}
+
+// normalizeMapValue converts a fetched value to its normalized map type
+func (r *Rows) normalizeMapValue(index int, v driver.Value) interface{} {
+	switch val := v.(type) {
+	case time.Time:
+		loc := r.location()
+		return time.Date(val.Year(), val.Month(), val.Day(), val.Hour(), val.Minute(), val.Second(), val.Nanosecond(), loc)
+	case int64:
+		// Epoch nanoseconds from TimestampFetchEpochNanos, unless a cast produced the value
+		if index < len(r.colTypes) && r.timestampFetchMode() == TimestampFetchEpochNanos &&
+			(r.casts == nil || r.casts[index] == CastNone) {
+			switch r.colTypes[index] {
+			case SQL_TYPE_TIMESTAMP, SQL_DATETIME:
+				t := time.Unix(0, val).UTC()
+				return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), r.location())
+			}
 		}
+		return val
+	default:
+		return val
 	}
+}
 
-	r.columns = columns
-	r.colTypes = colTypes
-	r.colSizes = colSizes
-	r.decDigits = decDigits
-	r.nullable = nullable
-	r.nativeTypes = nativeTypes
+// location returns the timezone that timestamp values are interpreted in
+func (r *Rows) location() *time.Location {
+	if r.stmt != nil && r.stmt.conn != nil && r.stmt.conn.timezone != nil {
+		return r.stmt.conn.timezone
+	}
+	return time.UTC
+}
 
-	return nil
+// mapKeys returns unique map keys for the columns, suffixing duplicate names
+func mapKeys(columns []string) []string {
+	keys := make([]string, len(columns))
+	used := make(map[string]bool, len(columns))
+	for i, name := range columns {
+		key := name
+		for n := 2; used[key]; n++ {
+			key = fmt.Sprintf("%s_%d", name, n)
+		}
+		used[key] = true
+		keys[i] = key
+	}
+	return keys
 }
 
 // =============================================================================
@@ -864,8 +1721,10 @@ func (r *Rows) NextResultSet() error {
 // =============================================================================
 
 // ScrollableRows provides methods for scrollable cursor navigation.
-// These methods are only available when the statement was prepared with
-// PrepareWithCursor using a scrollable cursor type (CursorStatic, CursorKeyset, or CursorDynamic).
+// These methods are only available when the query was executed with
+// QueryScrollable, or prepared with PrepareWithCursor, using a scrollable
+// cursor type (CursorStatic, CursorKeyset, or CursorDynamic). After moving
+// the cursor, read the current row with GetRowData.
 type ScrollableRows interface {
 	driver.Rows
 	First() error
@@ -873,6 +1732,8 @@ type ScrollableRows interface {
 	Prior() error
 	Absolute(row int64) error
 	Relative(offset int64) error
+	RowCount() (int64, error)
+	GetRowData(dest []driver.Value) error
 }
 
 // First moves the cursor to the first row
@@ -880,12 +1741,13 @@ func (r *Rows) First() error {
 	if r.closed {
 		return io.EOF
 	}
+	r.rowGen++
 	ret := FetchScroll(r.stmt.stmt, SQL_FETCH_FIRST, 0)
 	if ret == SQL_NO_DATA {
 		return io.EOF
 	}
 	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	return nil
 }
@@ -895,12 +1757,13 @@ func (r *Rows) Last() error {
 	if r.closed {
 		return io.EOF
 	}
+	r.rowGen++
 	ret := FetchScroll(r.stmt.stmt, SQL_FETCH_LAST, 0)
 	if ret == SQL_NO_DATA {
 		return io.EOF
 	}
 	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	return nil
 }
@@ -910,12 +1773,13 @@ func (r *Rows) Prior() error {
 	if r.closed {
 		return io.EOF
 	}
+	r.rowGen++
 	ret := FetchScroll(r.stmt.stmt, SQL_FETCH_PRIOR, 0)
 	if ret == SQL_NO_DATA {
 		return io.EOF
 	}
 	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	return nil
 }
@@ -926,12 +1790,13 @@ func (r *Rows) Absolute(row int64) error {
 	if r.closed {
 		return io.EOF
 	}
+	r.rowGen++
 	ret := FetchScroll(r.stmt.stmt, SQL_FETCH_ABSOLUTE, SQLLEN(row))
 	if ret == SQL_NO_DATA {
 		return io.EOF
 	}
 	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	return nil
 }
@@ -941,32 +1806,58 @@ func (r *Rows) Relative(offset int64) error {
 	if r.closed {
 		return io.EOF
 	}
+	r.rowGen++
 	ret := FetchScroll(r.stmt.stmt, SQL_FETCH_RELATIVE, SQLLEN(offset))
 	if ret == SQL_NO_DATA {
 		return io.EOF
 	}
 	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+		return r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 	return nil
 }
 
-// GetRowData retrieves the current row's data after a scroll operation
-func (r *Rows) GetRowData(dest []driver.Value) error {
+// RowCount returns the number of rows in the result set. The cursor is moved
+// to the last row to count them and then back to its position, or before the
+// first row if it was not on a row. Keyset and dynamic cursors count the rows
+// at the time of the call.
+func (r *Rows) RowCount() (int64, error) {
 	if r.closed {
-		return io.EOF
+		return 0, io.EOF
+	}
+	var current SQLULEN
+	if !IsSuccess(GetStmtAttr(r.stmt.stmt, SQL_ATTR_ROW_NUMBER, uintptr(unsafe.Pointer(&current)), 0, nil)) {
+		current = 0
 	}
 
-	// Get data for each column
-	for i := 0; i < len(dest); i++ {
-		val, err := r.getColumnData(SQLUSMALLINT(i + 1))
-		if err != nil {
-			return err
-		}
-		dest[i] = val
+	r.rowGen++
+	ret := FetchScroll(r.stmt.stmt, SQL_FETCH_LAST, 0)
+	if ret == SQL_NO_DATA {
+		return 0, nil
+	}
+	if !IsSuccess(ret) {
+		return 0, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+	}
+	var last SQLULEN
+	if !IsSuccess(GetStmtAttr(r.stmt.stmt, SQL_ATTR_ROW_NUMBER, uintptr(unsafe.Pointer(&last)), 0, nil)) {
+		return 0, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
 	}
 
-	return nil
+	// Absolute row 0 positions the cursor before the first row
+	ret = FetchScroll(r.stmt.stmt, SQL_FETCH_ABSOLUTE, SQLLEN(current))
+	if !IsSuccess(ret) && ret != SQL_NO_DATA {
+		return 0, r.stmt.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(r.stmt.stmt))
+	}
+	return int64(last), nil
+}
+
+// GetRowData retrieves the current row's data after a scroll operation. It
+// can be called again for the same row.
+func (r *Rows) GetRowData(dest []driver.Value) error {
+	if r.closed {
+		return io.EOF
+	}
+	return r.readRow(dest)
 }
 
 // Ensure Rows implements the required interfaces
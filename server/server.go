@@ -0,0 +1,416 @@
+// Package server exposes an ODBC data source over a streaming HTTP protocol,
+// so programs in any language can query it through godbc without an ODBC
+// driver of their own. A query posted to the server is answered with its
+// schema followed by record batches, streamed as the rows are fetched.
+//
+// The server runs any statement it receives. Put it behind authentication
+// and restrict the statements with WithQueryFilter before exposing it.
+//
+// # Protocol
+//
+// The client POSTs a JSON request:
+//
+//	{"query": "SELECT id, name FROM customers WHERE region = ?", "args": ["EU"], "batch_size": 5000}
+//
+// The response is newline-delimited JSON (application/x-ndjson). The first
+// message is the schema, then one message per record batch holding the
+// values column by column, and a final end or error message:
+//
+//	{"schema":[{"name":"id","type":"INTEGER","nullable":false},{"name":"name","type":"VARCHAR","nullable":true,"length":100}]}
+//	{"batch":{"length":2,"columns":[[1,2],["Ada",null]]}}
+//	{"end":{"rows":2}}
+//
+// Rows are read with godbc's NextBatch and converted by the arrow package,
+// so with WithRowArraySize each rowset is fetched in one call. Binary values
+// are base64 strings, dates and timestamps RFC 3339 strings in UTC, times of
+// day "15:04:05.999999999" strings, and the floating point values NaN, +Inf and -Inf, which JSON cannot represent as
+// numbers, the strings "NaN", "Infinity" and "-Infinity". An error before
+// the schema is sent is returned with an HTTP error status and an error
+// message; an error while streaming ends the stream with an error message
+// instead of an end message.
+package server
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/slingdata-io/godbc"
+	"github.com/slingdata-io/godbc/arrow"
+)
+
+// DefaultBatchSize is the number of rows per record batch when neither the
+// server nor the request sets one
+const DefaultBatchSize = 1024
+
+// maxRequestSize bounds the size of a request body
+const maxRequestSize = 1 << 20
+
+// Server answers queries against a database over HTTP. It implements
+// http.Handler and can be mounted on any path.
+type Server struct {
+	db           *sql.DB
+	batchSize    int
+	maxBatchSize int
+	filter       func(ctx context.Context, query string) error
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithBatchSize sets the number of rows per record batch for requests that
+// do not set one (defaults to DefaultBatchSize)
+func WithBatchSize(n int) Option {
+	return func(s *Server) {
+		s.batchSize = n
+	}
+}
+
+// WithMaxBatchSize bounds the batch size a request can ask for, and so the
+// rows the server holds per query (defaults to 64 times the batch size)
+func WithMaxBatchSize(n int) Option {
+	return func(s *Server) {
+		s.maxBatchSize = n
+	}
+}
+
+// WithQueryFilter sets a function called with each query before it runs; an
+// error rejects the query with 403 Forbidden. The context is the request's,
+// so the filter can read values set by authentication middleware.
+func WithQueryFilter(fn func(ctx context.Context, query string) error) Option {
+	return func(s *Server) {
+		s.filter = fn
+	}
+}
+
+// New returns a Server that runs queries on db, which must be opened with the
+// godbc driver. Open db with godbc options
+// such as WithRowArraySize, so rows are block fetched as they are streamed.
+//
+// Example:
+//
+//	connector, err := (&godbc.Driver{}).OpenConnectorWithOptions(dsn, godbc.WithRowArraySize(1024))
+//	db := sql.OpenDB(connector)
+//	http.Handle("/query", requireToken(server.New(db, server.WithBatchSize(5000))))
+//	log.Fatal(http.ListenAndServe(":8080", nil))
+func New(db *sql.DB, opts ...Option) *Server {
+	s := &Server{db: db, batchSize: DefaultBatchSize}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.maxBatchSize == 0 {
+		s.maxBatchSize = 64 * s.batchSize
+	}
+	return s
+}
+
+// Request is a query posted to the server
+type Request struct {
+	Query     string        `json:"query"`
+	Args      []interface{} `json:"args,omitempty"`
+	BatchSize int           `json:"batch_size,omitempty"`
+}
+
+// Field describes a result column in the schema message
+type Field struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+	Length   int64  `json:"length,omitempty"`
+}
+
+// Batch is a record batch: Length rows, held column by column
+type Batch struct {
+	Length  int             `json:"length"`
+	Columns [][]interface{} `json:"columns"`
+}
+
+// End is the final message of a successful stream
+type End struct {
+	Rows int64 `json:"rows"`
+}
+
+// Message is one line of the response stream; exactly one field is set
+type Message struct {
+	Schema []Field `json:"schema,omitempty"`
+	Batch  *Batch  `json:"batch,omitempty"`
+	End    *End    `json:"end,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// ServeHTTP runs the posted query and streams its results
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("queries must be posted"))
+		return
+	}
+	var req Request
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	dec.UseNumber()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, errors.New("invalid request: no query"))
+		return
+	}
+	batchSize := req.BatchSize
+	if batchSize == 0 {
+		batchSize = s.batchSize
+	}
+	if batchSize < 1 || batchSize > s.maxBatchSize {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: batch size must be between 1 and %d", s.maxBatchSize))
+		return
+	}
+
+	args, err := requestArgs(req.Args)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+
+	ctx := r.Context()
+	if s.filter != nil {
+		if err := s.filter(ctx, req.Query); err != nil {
+			writeError(w, http.StatusForbidden, err)
+			return
+		}
+	}
+	started := false
+	err = queryBatches(ctx, s.db, req.Query, args, func(rs resultSet) error {
+		started = true
+		fields := rs.Fields()
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		st := &stream{enc: json.NewEncoder(w)}
+		st.flusher, _ = w.(http.Flusher)
+		if err := st.send(Message{Schema: fields}); err != nil {
+			return nil
+		}
+		n, err := st.sendBatches(rs, len(fields), batchSize)
+		if err != nil {
+			// The client may be gone; the error message is sent in case it is not
+			st.send(Message{Error: err.Error()})
+			return nil
+		}
+		st.send(Message{End: &End{Rows: n}})
+		return nil
+	})
+	if err != nil && !started {
+		writeError(w, http.StatusBadRequest, err)
+	}
+}
+
+// requestArgs converts the JSON arguments of a request to query arguments:
+// numbers become int64 if they are integers and float64 otherwise. Arrays
+// and objects are rejected.
+func requestArgs(args []interface{}) ([]driver.NamedValue, error) {
+	out := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		out[i].Ordinal = i + 1
+		switch a := a.(type) {
+		case json.Number:
+			if v, err := a.Int64(); err == nil {
+				out[i].Value = v
+			} else if v, err := a.Float64(); err == nil {
+				out[i].Value = v
+			} else {
+				out[i].Value = a.String()
+			}
+		case nil, bool, string:
+			out[i].Value = a
+		default:
+			return nil, fmt.Errorf("argument %d is not a string, number, boolean or null", i+1)
+		}
+	}
+	return out, nil
+}
+
+// resultSet is the result of a query, read as Arrow record batches
+type resultSet interface {
+	Fields() []Field
+	Next() (*arrow.RecordBatch, error)
+}
+
+// queryBatches runs a query on a connection from db and calls fn with its
+// result set. An error returned before fn is called is the query's. It is a
+// variable so tests can serve result sets without an ODBC driver.
+var queryBatches = func(ctx context.Context, db *sql.DB, query string, args []driver.NamedValue, fn func(resultSet) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(dc interface{}) error {
+		qc, ok := dc.(driver.QueryerContext)
+		if !ok {
+			return errors.New("the database is not opened with the godbc driver")
+		}
+		rows, err := qc.QueryContext(ctx, query, args)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		gr, ok := rows.(*godbc.Rows)
+		if !ok {
+			return errors.New("the database is not opened with the godbc driver")
+		}
+		return fn(&godbcResult{rows: gr, reader: arrow.NewReader(gr)})
+	})
+}
+
+// godbcResult is the resultSet of godbc rows
+type godbcResult struct {
+	rows   *godbc.Rows
+	reader *arrow.Reader
+}
+
+// Fields describes the result columns
+func (g *godbcResult) Fields() []Field {
+	names := g.rows.Columns()
+	fields := make([]Field, len(names))
+	for i, name := range names {
+		fields[i] = Field{Name: name, Type: g.rows.ColumnTypeDatabaseTypeName(i), Nullable: true}
+		if nullable, ok := g.rows.ColumnTypeNullable(i); ok {
+			fields[i].Nullable = nullable
+		}
+		if length, ok := g.rows.ColumnTypeLength(i); ok && length > 0 && length < 1<<62 {
+			fields[i].Length = length
+		}
+	}
+	return fields
+}
+
+// Next reads the next record batch
+func (g *godbcResult) Next() (*arrow.RecordBatch, error) {
+	return g.reader.Next()
+}
+
+// stream writes the messages of a response
+type stream struct {
+	enc     *json.Encoder
+	flusher http.Flusher
+}
+
+// send writes a message and flushes it to the client
+func (st *stream) send(m Message) error {
+	if err := st.enc.Encode(m); err != nil {
+		return err
+	}
+	if st.flusher != nil {
+		st.flusher.Flush()
+	}
+	return nil
+}
+
+// sendBatches regroups the record batches of rs into batches of up to size
+// rows, sending each one when it is full and the last one at the end, and
+// returns the number of rows sent
+func (st *stream) sendBatches(rs resultSet, numCols, size int) (int64, error) {
+	batch := newBatch(numCols, size)
+	var total int64
+	for {
+		rb, err := rs.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+		for j := 0; j < rb.NumRows; j++ {
+			for i, col := range rb.Columns {
+				batch.Columns[i] = append(batch.Columns[i], jsonValue(col, j))
+			}
+			batch.Length++
+			if batch.Length == size {
+				if err := st.send(Message{Batch: batch}); err != nil {
+					return total, err
+				}
+				total += int64(batch.Length)
+				batch = newBatch(numCols, size)
+			}
+		}
+	}
+	if batch.Length > 0 {
+		if err := st.send(Message{Batch: batch}); err != nil {
+			return total, err
+		}
+		total += int64(batch.Length)
+	}
+	return total, nil
+}
+
+// newBatch returns an empty record batch with room for size rows
+func newBatch(numCols, size int) *Batch {
+	b := &Batch{Columns: make([][]interface{}, numCols)}
+	for i := range b.Columns {
+		b.Columns[i] = make([]interface{}, 0, size)
+	}
+	return b
+}
+
+// jsonValue returns value j of an Arrow column as it is encoded in a record batch
+func jsonValue(col arrow.Column, j int) interface{} {
+	if validity := col.Buffers[0]; validity != nil && validity[j/8]&(1<<(j%8)) == 0 {
+		return nil
+	}
+	values := col.Buffers[1]
+	switch col.Type {
+	case arrow.Boolean:
+		return values[j/8]&(1<<(j%8)) != 0
+	case arrow.Int64:
+		return int64(binary.LittleEndian.Uint64(values[8*j:]))
+	case arrow.Uint64:
+		return binary.LittleEndian.Uint64(values[8*j:])
+	case arrow.Float64:
+		return jsonFloat(math.Float64frombits(binary.LittleEndian.Uint64(values[8*j:])))
+	case arrow.Date32:
+		days := int32(binary.LittleEndian.Uint32(values[4*j:]))
+		return time.Unix(int64(days)*86400, 0).UTC()
+	case arrow.TimestampMicro:
+		return time.UnixMicro(int64(binary.LittleEndian.Uint64(values[8*j:]))).UTC()
+	case arrow.TimestampNano:
+		return time.Unix(0, int64(binary.LittleEndian.Uint64(values[8*j:]))).UTC()
+	case arrow.Time64:
+		d := time.Duration(binary.LittleEndian.Uint64(values[8*j:]))
+		return time.Time{}.Add(d).Format("15:04:05.999999999")
+	case arrow.Utf8, arrow.Binary:
+		start, end := binary.LittleEndian.Uint32(values[4*j:]), binary.LittleEndian.Uint32(values[4*(j+1):])
+		data := col.Buffers[2][start:end]
+		if col.Type == arrow.Utf8 {
+			return string(data)
+		}
+		return data
+	}
+	return nil
+}
+
+// jsonFloat returns f, or its name for the values JSON numbers cannot hold
+func jsonFloat(f float64) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return f
+}
+
+// writeError sends an error response before the stream has started
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Message{Error: err.Error()})
+}
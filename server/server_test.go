@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slingdata-io/godbc/arrow"
+)
+
+// stubResult is a resultSet serving fixed record batches, then err
+type stubResult struct {
+	fields  []Field
+	batches []*arrow.RecordBatch
+	err     error
+}
+
+func (r *stubResult) Fields() []Field { return r.fields }
+
+func (r *stubResult) Next() (*arrow.RecordBatch, error) {
+	if len(r.batches) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, io.EOF
+	}
+	rb := r.batches[0]
+	r.batches = r.batches[1:]
+	return rb, nil
+}
+
+// stubQuery makes queries return rs, or fail with queryErr, and records the
+// arguments they were run with
+func stubQuery(t *testing.T, rs *stubResult, queryErr error) *[]driver.NamedValue {
+	t.Helper()
+	saved := queryBatches
+	t.Cleanup(func() { queryBatches = saved })
+	var got []driver.NamedValue
+	queryBatches = func(ctx context.Context, db *sql.DB, query string, args []driver.NamedValue, fn func(resultSet) error) error {
+		got = args
+		if queryErr != nil {
+			return queryErr
+		}
+		return fn(rs)
+	}
+	return &got
+}
+
+// validity returns the validity bitmap of values whose nulls are set, or nil
+func validity(nulls ...bool) []byte {
+	var bitmap []byte
+	for j, null := range nulls {
+		if null && bitmap == nil {
+			bitmap = make([]byte, (len(nulls)+7)/8)
+			for k := 0; k < j; k++ {
+				bitmap[k/8] |= 1 << (k % 8)
+			}
+		}
+		if !null && bitmap != nil {
+			bitmap[j/8] |= 1 << (j % 8)
+		}
+	}
+	return bitmap
+}
+
+func int64Column(values ...int64) arrow.Column {
+	buf := make([]byte, 8*len(values))
+	for j, v := range values {
+		binary.LittleEndian.PutUint64(buf[8*j:], uint64(v))
+	}
+	return arrow.Column{Type: arrow.Int64, Len: len(values), Buffers: [][]byte{nil, buf}}
+}
+
+func float64Column(values ...float64) arrow.Column {
+	buf := make([]byte, 8*len(values))
+	for j, v := range values {
+		binary.LittleEndian.PutUint64(buf[8*j:], math.Float64bits(v))
+	}
+	return arrow.Column{Type: arrow.Float64, Len: len(values), Buffers: [][]byte{nil, buf}}
+}
+
+// utf8Column returns a string column; nil values are NULL
+func utf8Column(values ...*string) arrow.Column {
+	offsets := make([]byte, 4*(len(values)+1))
+	nulls := make([]bool, len(values))
+	var data []byte
+	for j, v := range values {
+		if v == nil {
+			nulls[j] = true
+		} else {
+			data = append(data, *v...)
+		}
+		binary.LittleEndian.PutUint32(offsets[4*(j+1):], uint32(len(data)))
+	}
+	col := arrow.Column{Type: arrow.Utf8, Len: len(values), Buffers: [][]byte{validity(nulls...), offsets, data}}
+	for _, null := range nulls {
+		if null {
+			col.NullCount++
+		}
+	}
+	return col
+}
+
+func str(s string) *string { return &s }
+
+// postQuery posts a request body to the server and returns the response
+func postQuery(t *testing.T, s *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body)))
+	return w
+}
+
+var testFields = []Field{
+	{Name: "id", Type: "INTEGER"},
+	{Name: "name", Type: "VARCHAR", Nullable: true, Length: 100},
+	{Name: "score", Type: "DOUBLE", Nullable: true},
+}
+
+func TestServeHTTP_Stream(t *testing.T) {
+	rs := &stubResult{
+		fields: testFields,
+		batches: []*arrow.RecordBatch{
+			{NumRows: 2, Columns: []arrow.Column{int64Column(1, 2), utf8Column(str("Ada"), nil), float64Column(1.5, math.NaN())}},
+			{NumRows: 1, Columns: []arrow.Column{int64Column(3), utf8Column(str("Grace")), float64Column(math.Inf(-1))}},
+		},
+	}
+	args := stubQuery(t, rs, nil)
+
+	w := postQuery(t, New(nil), `{"query": "SELECT * FROM people WHERE region = ? AND age > ?", "args": ["EU", 30, 1.5, true, null], "batch_size": 2}`)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("expected a 200 ndjson response, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	want := `{"schema":[{"name":"id","type":"INTEGER","nullable":false},{"name":"name","type":"VARCHAR","nullable":true,"length":100},{"name":"score","type":"DOUBLE","nullable":true}]}
+{"batch":{"length":2,"columns":[[1,2],["Ada",null],[1.5,"NaN"]]}}
+{"batch":{"length":1,"columns":[[3],["Grace"],["-Infinity"]]}}
+{"end":{"rows":3}}
+`
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected stream:\n%s\nwant:\n%s", got, want)
+	}
+
+	wantArgs := []driver.NamedValue{
+		{Ordinal: 1, Value: "EU"}, {Ordinal: 2, Value: int64(30)}, {Ordinal: 3, Value: 1.5},
+		{Ordinal: 4, Value: true}, {Ordinal: 5, Value: nil},
+	}
+	if !reflect.DeepEqual(*args, wantArgs) {
+		t.Errorf("expected args %v, got %v", wantArgs, *args)
+	}
+}
+
+func TestServeHTTP_StreamError(t *testing.T) {
+	rs := &stubResult{
+		fields:  testFields[:1],
+		batches: []*arrow.RecordBatch{{NumRows: 2, Columns: []arrow.Column{int64Column(1, 2)}}},
+		err:     errors.New("connection lost"),
+	}
+	stubQuery(t, rs, nil)
+
+	// Full batches are sent before the error
+	w := postQuery(t, New(nil, WithBatchSize(2)), `{"query": "SELECT id FROM t"}`)
+	want := `{"schema":[{"name":"id","type":"INTEGER","nullable":false}]}
+{"batch":{"length":2,"columns":[[1,2]]}}
+{"error":"connection lost"}
+`
+	if w.Code != http.StatusOK || w.Body.String() != want {
+		t.Errorf("unexpected response %d:\n%s\nwant:\n%s", w.Code, w.Body.String(), want)
+	}
+}
+
+func TestServeHTTP_RequestErrors(t *testing.T) {
+	stubQuery(t, &stubResult{fields: testFields}, errors.New("syntax error"))
+	s := New(nil, WithBatchSize(10), WithMaxBatchSize(100), WithQueryFilter(func(_ context.Context, query string) error {
+		if strings.HasPrefix(query, "DELETE") {
+			return errors.New("only SELECT queries are accepted")
+		}
+		return nil
+	}))
+
+	tests := []struct {
+		body   string
+		status int
+		want   string
+	}{
+		{`{"query": `, http.StatusBadRequest, "invalid request: unexpected EOF"},
+		{`{}`, http.StatusBadRequest, "invalid request: no query"},
+		{`{"query": "SELECT 1", "batch_size": 1000}`, http.StatusBadRequest, "invalid request: batch size must be between 1 and 100"},
+		{`{"query": "SELECT ?", "args": [[1, 2]]}`, http.StatusBadRequest, "invalid request: argument 1 is not a string, number, boolean or null"},
+		{`{"query": "DELETE FROM t"}`, http.StatusForbidden, "only SELECT queries are accepted"},
+		{`{"query": "SELEC 1"}`, http.StatusBadRequest, "syntax error"},
+	}
+	for _, tt := range tests {
+		w := postQuery(t, s, tt.body)
+		if want := `{"error":"` + tt.want + `"}` + "\n"; w.Code != tt.status || w.Body.String() != want {
+			t.Errorf("%s: got %d %q, want %d %q", tt.body, w.Code, w.Body.String(), tt.status, want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/query", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("expected 405 with Allow: POST, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	at := time.Date(2024, 3, 9, 12, 30, 0, 123456000, time.UTC)
+	date := make([]byte, 4)
+	dayBefore := int32(-1) // 1969-12-31
+	binary.LittleEndian.PutUint32(date, uint32(dayBefore))
+	micros := make([]byte, 8)
+	binary.LittleEndian.PutUint64(micros, uint64(at.UnixMicro()))
+	clock := make([]byte, 8)
+	binary.LittleEndian.PutUint64(clock, uint64(12*time.Hour+30*time.Minute+5*time.Second+100))
+	binOffsets := make([]byte, 8)
+	binary.LittleEndian.PutUint32(binOffsets[4:], 2)
+
+	tests := []struct {
+		col  arrow.Column
+		want interface{}
+	}{
+		{arrow.Column{Type: arrow.Boolean, Buffers: [][]byte{nil, {0b10}}}, true},
+		{arrow.Column{Type: arrow.Date32, Buffers: [][]byte{nil, date}}, time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{arrow.Column{Type: arrow.TimestampMicro, Buffers: [][]byte{nil, micros}}, at},
+		{arrow.Column{Type: arrow.Time64, Buffers: [][]byte{nil, clock}}, "12:30:05.0000001"},
+		{arrow.Column{Type: arrow.Binary, Buffers: [][]byte{nil, binOffsets, {0xde, 0xad}}}, []byte{0xde, 0xad}},
+		{arrow.Column{Type: arrow.Int64, Buffers: [][]byte{{0b01}, make([]byte, 16)}}, nil},
+		{float64Column(math.Inf(1)), "Infinity"},
+	}
+	for _, tt := range tests {
+		j := 0
+		if tt.col.Type == arrow.Boolean || tt.col.Type == arrow.Int64 {
+			j = 1
+		}
+		if got := jsonValue(tt.col, j); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("jsonValue(%s) = %#v, want %#v", tt.col.Type, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package godbc
+
+import (
+	"database/sql/driver"
+	"strings"
+)
+
+// ServerInfo describes the driver and data source of a connection, as
+// reported by SQLGetInfo, for tools that generate SQL. Values the driver does
+// not report are left empty or zero; a zero length limit means there is no
+// limit or it is unknown.
+type ServerInfo struct {
+	DriverName        string // Driver library file name (SQL_DRIVER_NAME)
+	DriverVersion     string // Driver version (SQL_DRIVER_VER)
+	DriverODBCVersion string // ODBC version the driver supports (SQL_DRIVER_ODBC_VER)
+	DBMSName          string // Database product name (SQL_DBMS_NAME)
+	DBMSVersion       string // Database product version (SQL_DBMS_VER)
+	ServerName        string // Data source server name (SQL_SERVER_NAME)
+	DatabaseName      string // Current database (SQL_DATABASE_NAME)
+	UserName          string // User name in the database (SQL_USER_NAME)
+
+	// IdentifierQuote is the character that quotes identifiers
+	// (SQL_IDENTIFIER_QUOTE_CHAR), or "" if the database does not quote them
+	IdentifierQuote string
+
+	// IdentifierCase is how the database stores unquoted identifiers (SQL_IDENTIFIER_CASE)
+	IdentifierCase IdentifierCase
+
+	CatalogSeparator    string // Separator between a catalog and the name it qualifies (SQL_CATALOG_NAME_SEPARATOR)
+	SearchPatternEscape string // Escape for '_' and '%' in catalog function patterns (SQL_SEARCH_PATTERN_ESCAPE)
+	SpecialCharacters   string // Characters beyond a-z, A-Z, 0-9 and '_' allowed in identifiers (SQL_SPECIAL_CHARACTERS)
+
+	// Keywords are the database's reserved words beyond the ODBC reserved
+	// words, which must be quoted to be used as identifiers (SQL_KEYWORDS)
+	Keywords []string
+
+	MaxIdentifierLen   int // Maximum identifier length in characters (SQL_MAX_IDENTIFIER_LEN)
+	MaxCatalogNameLen  int // Maximum catalog name length (SQL_MAX_CATALOG_NAME_LEN)
+	MaxSchemaNameLen   int // Maximum schema name length (SQL_MAX_SCHEMA_NAME_LEN)
+	MaxTableNameLen    int // Maximum table name length (SQL_MAX_TABLE_NAME_LEN)
+	MaxColumnNameLen   int // Maximum column name length (SQL_MAX_COLUMN_NAME_LEN)
+	MaxColumnsInSelect int // Maximum columns in a select list (SQL_MAX_COLUMNS_IN_SELECT)
+	MaxStatementLen    int // Maximum SQL statement length in bytes (SQL_MAX_STATEMENT_LEN)
+}
+
+// ServerInfo returns the driver and data source information of the
+// connection. The values are read with SQLGetInfo on the first call and
+// cached for the life of the connection, so DatabaseName is the database
+// current at that call.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    info, err := dc.(*godbc.Conn).ServerInfo()
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(info.DBMSName, info.DBMSVersion, info.IdentifierQuote)
+//	    return nil
+//	})
+func (c *Conn) ServerInfo() (ServerInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ServerInfo{}, driver.ErrBadConn
+	}
+	if c.serverInfo == nil {
+		c.serverInfo = c.readServerInfo()
+	}
+	info := *c.serverInfo
+	info.Keywords = append([]string(nil), info.Keywords...)
+	return info, nil
+}
+
+// readServerInfo reads the ServerInfo values from the driver
+func (c *Conn) readServerInfo() *ServerInfo {
+	str := func(infoType SQLUSMALLINT) string {
+		s, _ := c.getInfoString(infoType)
+		return s
+	}
+	length := func(infoType SQLUSMALLINT) int {
+		n, _ := GetInfoInt(c.dbc, infoType)
+		return int(n)
+	}
+
+	info := &ServerInfo{
+		DriverName:          str(SQL_DRIVER_NAME),
+		DriverVersion:       str(SQL_DRIVER_VER),
+		DriverODBCVersion:   str(SQL_DRIVER_ODBC_VER),
+		DBMSName:            str(SQL_DBMS_NAME),
+		DBMSVersion:         str(SQL_DBMS_VER),
+		ServerName:          str(SQL_SERVER_NAME),
+		DatabaseName:        str(SQL_DATABASE_NAME),
+		UserName:            str(SQL_USER_NAME),
+		IdentifierQuote:     strings.TrimSpace(str(SQL_IDENTIFIER_QUOTE_CHAR)),
+		IdentifierCase:      c.identifierCase,
+		CatalogSeparator:    str(SQL_CATALOG_NAME_SEPARATOR),
+		SearchPatternEscape: str(SQL_SEARCH_PATTERN_ESCAPE),
+		SpecialCharacters:   str(SQL_SPECIAL_CHARACTERS),
+		MaxIdentifierLen:    length(SQL_MAX_IDENTIFIER_LEN),
+		MaxCatalogNameLen:   length(SQL_MAX_CATALOG_NAME_LEN),
+		MaxSchemaNameLen:    length(SQL_MAX_SCHEMA_NAME_LEN),
+		MaxTableNameLen:     length(SQL_MAX_TABLE_NAME_LEN),
+		MaxColumnNameLen:    length(SQL_MAX_COLUMN_NAME_LEN),
+		MaxColumnsInSelect:  length(SQL_MAX_COLUMNS_IN_SELECT),
+	}
+	if n, ret := GetInfoBitmask(c.dbc, SQL_MAX_STATEMENT_LEN); IsSuccess(ret) {
+		info.MaxStatementLen = int(n)
+	}
+	for _, kw := range strings.Split(str(SQL_KEYWORDS), ",") {
+		if kw = strings.TrimSpace(kw); kw != "" {
+			info.Keywords = append(info.Keywords, kw)
+		}
+	}
+	return info
+}
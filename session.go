@@ -0,0 +1,301 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// sessionDialect describes how a database sets and reads session variables
+type sessionDialect struct {
+	// set returns the statement (and any bound arguments) that sets a variable
+	set func(name string, value interface{}) (string, []interface{}, error)
+
+	// get returns the query (and any bound arguments) that reads a variable
+	get func(name string) (string, []interface{})
+
+	// valueColumn is the zero-based result column holding the value
+	valueColumn int
+}
+
+// sqlServerSessionProperties are SET options read through SESSIONPROPERTY rather than @@
+var sqlServerSessionProperties = map[string]bool{
+	"ANSI_NULLS":              true,
+	"ANSI_PADDING":            true,
+	"ANSI_WARNINGS":           true,
+	"ARITHABORT":              true,
+	"CONCAT_NULL_YIELDS_NULL": true,
+	"NUMERIC_ROUNDABORT":      true,
+	"QUOTED_IDENTIFIER":       true,
+}
+
+// sessionDialects maps database types to their session variable SQL
+var sessionDialects = map[string]sessionDialect{
+	"sql server": {
+		set: func(name string, value interface{}) (string, []interface{}, error) {
+			v, err := sessionLiteral(value, false)
+			if err != nil {
+				return "", nil, err
+			}
+			return "SET " + name + " " + v, nil, nil
+		},
+		get: func(name string) (string, []interface{}) {
+			if sqlServerSessionProperties[strings.ToUpper(name)] {
+				return "SELECT SESSIONPROPERTY(?)", []interface{}{name}
+			}
+			return "SELECT @@" + name, nil
+		},
+	},
+	"postgresql": {
+		set: func(name string, value interface{}) (string, []interface{}, error) {
+			v, err := sessionString(value)
+			if err != nil {
+				return "", nil, err
+			}
+			return "SELECT set_config(?, ?, false)", []interface{}{name, v}, nil
+		},
+		get: func(name string) (string, []interface{}) {
+			return "SELECT current_setting(?)", []interface{}{name}
+		},
+	},
+	"mysql": {
+		set: func(name string, value interface{}) (string, []interface{}, error) {
+			v, err := sessionLiteral(value, true)
+			if err != nil {
+				return "", nil, err
+			}
+			return "SET SESSION " + name + " = " + v, nil, nil
+		},
+		get: func(name string) (string, []interface{}) {
+			return "SELECT @@SESSION." + name, nil
+		},
+	},
+	"oracle": {
+		set: func(name string, value interface{}) (string, []interface{}, error) {
+			v, err := sessionLiteral(value, false)
+			if err != nil {
+				return "", nil, err
+			}
+			return "ALTER SESSION SET " + name + " = " + v, nil, nil
+		},
+		get: func(name string) (string, []interface{}) {
+			if strings.HasPrefix(strings.ToUpper(name), "NLS_") {
+				return "SELECT VALUE FROM NLS_SESSION_PARAMETERS WHERE PARAMETER = ?", []interface{}{strings.ToUpper(name)}
+			}
+			return "SELECT SYS_CONTEXT('USERENV', ?) FROM DUAL", []interface{}{name}
+		},
+	},
+	"db2": {
+		set: func(name string, value interface{}) (string, []interface{}, error) {
+			v, err := sessionLiteral(value, false)
+			if err != nil {
+				return "", nil, err
+			}
+			return "SET " + name + " = " + v, nil, nil
+		},
+		get: func(name string) (string, []interface{}) {
+			return "VALUES " + name, nil
+		},
+	},
+	"snowflake": {
+		set: func(name string, value interface{}) (string, []interface{}, error) {
+			v, err := sessionLiteral(value, true)
+			if err != nil {
+				return "", nil, err
+			}
+			return "ALTER SESSION SET " + name + " = " + v, nil, nil
+		},
+		get: func(name string) (string, []interface{}) {
+			return "SHOW PARAMETERS LIKE '" + name + "' IN SESSION", nil
+		},
+		valueColumn: 1, // key, value, default, level, ...
+	},
+	"sqlite": {
+		set: func(name string, value interface{}) (string, []interface{}, error) {
+			v, err := sessionLiteral(value, false)
+			if err != nil {
+				return "", nil, err
+			}
+			return "PRAGMA " + name + " = " + v, nil, nil
+		},
+		get: func(name string) (string, []interface{}) {
+			return "PRAGMA " + name, nil
+		},
+	},
+}
+
+func init() {
+	sessionDialects["mariadb"] = sessionDialects["mysql"]
+}
+
+// SetSessionVar sets a session-level variable or option using the SQL appropriate
+// for the connected database, e.g. SET LOCK_TIMEOUT 5000 on SQL Server,
+// set_config() on PostgreSQL or SET SESSION on MySQL.
+// The name must be a plain identifier; value may be a string, bool or number.
+func (c *Conn) SetSessionVar(ctx context.Context, name string, value interface{}) error {
+	dialect, err := c.sessionDialect(name)
+	if err != nil {
+		return err
+	}
+	query, args, err := dialect.set(name, value)
+	if err != nil {
+		return fmt.Errorf("session variable %q: %w", name, err)
+	}
+	_, err = c.ExecContext(ctx, query, namedValues(args))
+	return err
+}
+
+// GetSessionVar returns the current value of a session-level variable or option
+// as a string. It returns an empty string if the database reports NULL.
+func (c *Conn) GetSessionVar(ctx context.Context, name string) (string, error) {
+	dialect, err := c.sessionDialect(name)
+	if err != nil {
+		return "", err
+	}
+	query, args := dialect.get(name)
+	rows, err := c.QueryContext(ctx, query, namedValues(args))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, len(rows.Columns()))
+	if dialect.valueColumn >= len(dest) {
+		return "", fmt.Errorf("session variable %q: unexpected result shape", name)
+	}
+	if err := rows.Next(dest); err != nil {
+		if err == io.EOF {
+			return "", fmt.Errorf("session variable %q not found", name)
+		}
+		return "", err
+	}
+
+	switch v := dest[dialect.valueColumn].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// sessionDialect validates the variable name and returns the connection's dialect
+func (c *Conn) sessionDialect(name string) (sessionDialect, error) {
+	if !isSessionVarName(name) {
+		return sessionDialect{}, fmt.Errorf("invalid session variable name %q", name)
+	}
+	if dbTypeLower := strings.ToLower(c.dbType); dbTypeLower != "" {
+		for dbName, dialect := range sessionDialects {
+			if strings.Contains(dbTypeLower, dbName) {
+				return dialect, nil
+			}
+		}
+	}
+	return sessionDialect{}, fmt.Errorf("session variables are not supported for database %q", c.dbType)
+}
+
+// isSessionVarName reports whether name is safe to embed in a SET statement.
+// Names may contain letters, digits, '_', '.', '$' and single inner spaces
+// (for DB2 special registers such as CURRENT SCHEMA).
+func isSessionVarName(name string) bool {
+	if name == "" || name[0] == ' ' || name[len(name)-1] == ' ' || strings.Contains(name, "  ") {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !isIdentChar(c) && c != '.' && c != '$' && c != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// sessionLiteral renders a value as a SQL literal for statements that cannot bind parameters.
+// Single-word strings (ON, OFF, DEFAULT, us_english) are emitted as-is unless
+// quoteWords is set; everything else is single-quoted with quotes doubled.
+func sessionLiteral(value interface{}, quoteWords bool) (string, error) {
+	switch v := value.(type) {
+	case string:
+		if !quoteWords && isSessionWord(v) {
+			return v, nil
+		}
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	case bool:
+		if v {
+			return "ON", nil
+		}
+		return "OFF", nil
+	default:
+		return sessionString(value)
+	}
+}
+
+// sessionString formats a numeric, bool or string value as a plain string
+func sessionString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		if v {
+			return "on", nil
+		}
+		return "off", nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// isSessionWord reports whether s is a bare word that needs no quoting
+func isSessionWord(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// namedValues converts positional arguments to driver.NamedValue
+func namedValues(args []interface{}) []driver.NamedValue {
+	if len(args) == 0 {
+		return nil
+	}
+	nv := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return nv
+}
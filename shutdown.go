@@ -0,0 +1,170 @@
+package godbc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrShutdown is returned for new connections and operations after Shutdown
+var ErrShutdown = errors.New("odbc driver is shut down")
+
+// driverState tracks open connections, shared environments and in-flight
+// operations so they can be drained and released on shutdown
+type driverState struct {
+	mu           sync.Mutex
+	shuttingDown bool
+	active       int           // operations currently calling into the driver
+	idle         chan struct{} // closed when shutting down and no operations are active
+	conns        map[*Conn]struct{}
+	envs         map[*Environment]struct{}
+}
+
+// lifecycle is the process-wide driver state used by Shutdown
+var lifecycle = newDriverState()
+
+func newDriverState() *driverState {
+	return &driverState{
+		idle:  make(chan struct{}),
+		conns: make(map[*Conn]struct{}),
+		envs:  make(map[*Environment]struct{}),
+	}
+}
+
+// operation is an in-flight driver operation. end is idempotent.
+type operation struct {
+	state *driverState
+	done  bool
+}
+
+// begin registers an in-flight operation, failing once shutdown has started
+func (s *driverState) begin() (*operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shuttingDown {
+		return nil, ErrShutdown
+	}
+	s.active++
+	return &operation{state: s}, nil
+}
+
+// end marks the operation as finished
+func (op *operation) end() {
+	if op == nil || op.done {
+		return
+	}
+	op.done = true
+
+	s := op.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+	if s.active == 0 && s.shuttingDown {
+		close(s.idle)
+	}
+}
+
+// handoff transfers the operation to a new owner (such as Rows, which end it on
+// Close) and returns the new handle; ending the original becomes a no-op
+func (op *operation) handoff() *operation {
+	op.done = true
+	return &operation{state: op.state}
+}
+
+// addConn registers an open connection
+func (s *driverState) addConn(c *Conn) {
+	s.mu.Lock()
+	s.conns[c] = struct{}{}
+	s.mu.Unlock()
+}
+
+// removeConn unregisters a closed connection
+func (s *driverState) removeConn(c *Conn) {
+	s.mu.Lock()
+	delete(s.conns, c)
+	s.mu.Unlock()
+}
+
+// addEnvironment registers a shared environment
+func (s *driverState) addEnvironment(e *Environment) {
+	s.mu.Lock()
+	s.envs[e] = struct{}{}
+	s.mu.Unlock()
+}
+
+// removeEnvironment unregisters a closed shared environment
+func (s *driverState) removeEnvironment(e *Environment) {
+	s.mu.Lock()
+	delete(s.envs, e)
+	s.mu.Unlock()
+}
+
+// shutdown stops new work, waits for active operations and releases all handles
+func (s *driverState) shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.shuttingDown {
+		s.shuttingDown = true
+		if s.active == 0 {
+			close(s.idle)
+		}
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.idle:
+	case <-ctx.Done():
+		// Freeing handles while the driver is still using them is what crashes
+		// at exit, so leave them allocated and report what is still running
+		s.mu.Lock()
+		active := s.active
+		s.mu.Unlock()
+		return fmt.Errorf("shutdown: %d operations still running: %w", active, ctx.Err())
+	}
+
+	s.mu.Lock()
+	conns := make([]*Conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	envs := make([]*Environment, 0, len(s.envs))
+	for e := range s.envs {
+		envs = append(envs, e)
+	}
+	s.mu.Unlock()
+
+	// Connections first: environments cannot be freed while connections use them
+	var errs []error
+	for _, c := range conns {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, e := range envs {
+		if err := e.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown prepares the driver for process exit. It makes new connections and
+// statements fail with ErrShutdown, waits for in-flight operations (executing
+// statements and batches, BulkCopy and Copy writes, and open Rows) to finish,
+// then closes all connections and frees their connection and environment
+// handles, including shared Environments.
+//
+// If ctx expires before in-flight operations finish, Shutdown returns an error
+// and leaves the handles allocated, since freeing them while the ODBC driver
+// is still using them can crash the process. Shutdown is permanent for the process.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := godbc.Shutdown(ctx); err != nil {
+//	    log.Printf("odbc shutdown: %v", err)
+//	}
+func Shutdown(ctx context.Context) error {
+	return lifecycle.shutdown(ctx)
+}
@@ -0,0 +1,95 @@
+package godbc
+
+import "sync"
+
+// Driver-specific SQL type codes.
+// These are reported by SQLDescribeCol for vendor types outside the ODBC standard.
+const (
+	// Microsoft SQL Server (msodbcsql / sqlncli)
+	SQL_SS_VARIANT         SQLSMALLINT = -150
+	SQL_SS_UDT             SQLSMALLINT = -151
+	SQL_SS_XML             SQLSMALLINT = -152
+	SQL_SS_TABLE           SQLSMALLINT = -153
+	SQL_SS_TIME2           SQLSMALLINT = -154
+	SQL_SS_TIMESTAMPOFFSET SQLSMALLINT = -155
+
+	// IBM DB2 (CLI/ODBC)
+	SQL_DB2_GRAPHIC        SQLSMALLINT = -95
+	SQL_DB2_VARGRAPHIC     SQLSMALLINT = -96
+	SQL_DB2_LONGVARGRAPHIC SQLSMALLINT = -97
+	SQL_DB2_BLOB           SQLSMALLINT = -98
+	SQL_DB2_CLOB           SQLSMALLINT = -99
+	SQL_DB2_DBCLOB         SQLSMALLINT = -350
+	SQL_DB2_DECFLOAT       SQLSMALLINT = -360
+	SQL_DB2_XML            SQLSMALLINT = -370
+)
+
+// Driver-specific C type codes for SQL Server date/time types, which keep the
+// fractional seconds and offset that SQL_C_TIME and SQL_C_TIMESTAMP drop
+const (
+	SQL_C_SS_TIME2           SQLSMALLINT = 0x4000
+	SQL_C_SS_TIMESTAMPOFFSET SQLSMALLINT = 0x4001
+)
+
+// SQL_SS_TIME2_STRUCT holds a SQL Server time(n) value fetched as SQL_C_SS_TIME2
+type SQL_SS_TIME2_STRUCT struct {
+	Hour     SQLUSMALLINT
+	Minute   SQLUSMALLINT
+	Second   SQLUSMALLINT
+	Fraction SQLUINTEGER // billionths of a second
+}
+
+// SQL_SS_TIMESTAMPOFFSET_STRUCT holds a SQL Server datetimeoffset value fetched
+// as SQL_C_SS_TIMESTAMPOFFSET. The date and time fields are local to the offset;
+// TimezoneMinute has the same sign as TimezoneHour.
+type SQL_SS_TIMESTAMPOFFSET_STRUCT struct {
+	Year           SQLSMALLINT
+	Month          SQLUSMALLINT
+	Day            SQLUSMALLINT
+	Hour           SQLUSMALLINT
+	Minute         SQLUSMALLINT
+	Second         SQLUSMALLINT
+	Fraction       SQLUINTEGER // billionths of a second
+	TimezoneHour   SQLSMALLINT
+	TimezoneMinute SQLSMALLINT
+}
+
+var (
+	sqlTypeNamesMu sync.RWMutex
+
+	// sqlTypeNames maps driver-specific SQL type codes to readable names
+	sqlTypeNames = map[SQLSMALLINT]string{
+		SQL_SS_VARIANT:         "SQL_VARIANT",
+		SQL_SS_UDT:             "UDT",
+		SQL_SS_XML:             "XML",
+		SQL_SS_TABLE:           "TABLE",
+		SQL_SS_TIME2:           "TIME2",
+		SQL_SS_TIMESTAMPOFFSET: "DATETIMEOFFSET",
+		SQL_DB2_GRAPHIC:        "GRAPHIC",
+		SQL_DB2_VARGRAPHIC:     "VARGRAPHIC",
+		SQL_DB2_LONGVARGRAPHIC: "LONG VARGRAPHIC",
+		SQL_DB2_BLOB:           "BLOB",
+		SQL_DB2_CLOB:           "CLOB",
+		SQL_DB2_DBCLOB:         "DBCLOB",
+		SQL_DB2_DECFLOAT:       "DECFLOAT",
+		SQL_DB2_XML:            "XML",
+	}
+)
+
+// RegisterSQLTypeName registers a readable name for a driver-specific SQL type code.
+// Registered names are used by SQLTypeName and as the fallback for
+// ColumnTypeDatabaseTypeName when the driver does not report a native type name.
+// Registering a code that already has a name replaces it.
+func RegisterSQLTypeName(sqlType SQLSMALLINT, name string) {
+	sqlTypeNamesMu.Lock()
+	defer sqlTypeNamesMu.Unlock()
+	sqlTypeNames[sqlType] = name
+}
+
+// LookupSQLTypeName returns the registered name for a driver-specific SQL type code
+func LookupSQLTypeName(sqlType SQLSMALLINT) (string, bool) {
+	sqlTypeNamesMu.RLock()
+	defer sqlTypeNamesMu.RUnlock()
+	name, ok := sqlTypeNames[sqlType]
+	return name, ok
+}
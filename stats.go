@@ -0,0 +1,81 @@
+package godbc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// QueryStats holds execution statistics for a query
+type QueryStats struct {
+	Queries      int64         // Number of statements executed
+	RowsFetched  int64         // Rows returned by SQLFetch
+	GetDataCalls int64         // SQLGetData calls, including chunked reads of long values
+	BytesRead    int64         // Bytes copied out of the driver by SQLGetData
+	PrepareTime  time.Duration // Time spent in SQLPrepare
+	ExecuteTime  time.Duration // Time spent in SQLExecute/SQLExecDirect
+	FetchTime    time.Duration // Time spent fetching rows and reading column data
+}
+
+// add accumulates other into s
+func (s *QueryStats) add(other QueryStats) {
+	s.Queries += other.Queries
+	s.RowsFetched += other.RowsFetched
+	s.GetDataCalls += other.GetDataCalls
+	s.BytesRead += other.BytesRead
+	s.PrepareTime += other.PrepareTime
+	s.ExecuteTime += other.ExecuteTime
+	s.FetchTime += other.FetchTime
+}
+
+// StatsCollector accumulates QueryStats for every query executed with its context.
+// It is safe for concurrent use by queries sharing the context.
+type StatsCollector struct {
+	mu    sync.Mutex
+	stats QueryStats
+}
+
+// Stats returns the statistics accumulated so far. Statistics of a query are
+// added when it completes: on Exec return, or when its Rows are closed.
+func (c *StatsCollector) Stats() QueryStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// add accumulates the statistics of a completed query
+func (c *StatsCollector) add(stats QueryStats) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.stats.add(stats)
+	c.mu.Unlock()
+}
+
+// statsCollectorKey is the context key for query statistics collection
+type statsCollectorKey struct{}
+
+// WithStatsCollector returns a context that collects statistics for queries
+// executed with it, and the collector to read them from.
+//
+// Example:
+//
+//	ctx, stats := godbc.WithStatsCollector(ctx)
+//	rows, err := db.QueryContext(ctx, "SELECT * FROM orders")
+//	// ... iterate and close rows ...
+//	s := stats.Stats()
+//	log.Printf("%d rows, %d bytes, fetch %v", s.RowsFetched, s.BytesRead, s.FetchTime)
+func WithStatsCollector(ctx context.Context) (context.Context, *StatsCollector) {
+	collector := &StatsCollector{}
+	return context.WithValue(ctx, statsCollectorKey{}, collector), collector
+}
+
+// statsCollectorFromContext returns the collector stored in ctx, if any
+func statsCollectorFromContext(ctx context.Context) *StatsCollector {
+	if ctx == nil {
+		return nil
+	}
+	collector, _ := ctx.Value(statsCollectorKey{}).(*StatsCollector)
+	return collector
+}
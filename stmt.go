@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql/driver"
 	"fmt"
+	"log/slog"
+	"reflect"
 	"sync"
 	"time"
 	"unsafe"
@@ -28,6 +30,18 @@ type outputParamInfo struct {
 	goType    interface{} // Original Go type hint for conversion
 }
 
+// paramBinding is the layout an input parameter was last bound with. A new
+// value with the same C and SQL types that fits the bound buffer is copied
+// into it, without calling SQLBindParameter again.
+type paramBinding struct {
+	bound     bool // Bound as an input parameter that can be reused
+	owned     bool // The buffer belongs to the statement, not the caller, so it can be overwritten
+	cType     SQLSMALLINT
+	sqlType   SQLSMALLINT
+	colSize   SQLULEN
+	decDigits SQLSMALLINT
+}
+
 // Stmt implements driver.Stmt for prepared statements
 type Stmt struct {
 	conn     *Conn
@@ -37,18 +51,46 @@ type Stmt struct {
 	mu       sync.Mutex
 	closed   bool
 
-	// Parameter buffers - kept alive during execution
+	// Parameter buffers - kept alive during execution. While the number of
+	// parameters is unchanged they are kept between executions with their
+	// bindings, since the driver holds their addresses.
 	paramBuffers []interface{}
 	paramLengths []SQLLEN
+	bindings     []paramBinding // Layout of each bound parameter (nil = nothing reusable)
 
 	// Output parameter tracking
 	outputParams []outputParamInfo
 
+	// Parameters sent with SQLPutData during execution
+	streams []streamBinding
+
 	// Cursor configuration
 	cursorType CursorType
 
+	// SQL_ATTR_QUERY_TIMEOUT last set on the handle, in seconds (0 = none)
+	timeoutSecs uintptr
+
+	// paramColumns are the target columns of the parameters of a BulkCopy
+	// INSERT; array binding binds each parameter with its column's SQL type
+	// (a zero DataType keeps the type inferred from the values)
+	paramColumns []ColumnInfo
+
 	// Named parameter support
 	namedParams *NamedParams
+	paramNames  []string // Driver names of procedure parameters bound by name, by position
+	returnValue bool     // Query is {? = CALL proc(...)}; the first marker receives the return value
+
+	// prepareTime is the SQLPrepare duration, reported with the first execution's stats
+	prepareTime time.Duration
+
+	// lastErr is the most recent failure on the statement, reported as the
+	// cause if a later execution fails with a function sequence error (HY010)
+	lastErr error
+
+	// resultCols is the column metadata of the first result set, kept after
+	// the first execution so later ones skip SQLDescribeCol. It is cleared when
+	// the statement is prepared again or returns more than one result set.
+	resultCols *resultColumns
 }
 
 // Close releases all resources associated with the prepared statement.
@@ -70,14 +112,28 @@ func (s *Stmt) Close() error {
 	// Clear parameter buffers
 	s.paramBuffers = nil
 	s.paramLengths = nil
+	s.bindings = nil
 	s.outputParams = nil
+	s.streams = nil
 
 	return nil
 }
 
+// dbType returns the database type of the statement's connection, if known
+func (s *Stmt) dbType() string {
+	if s.conn == nil {
+		return ""
+	}
+	return s.conn.dbType
+}
+
 // NumInput returns the number of placeholder parameters in the prepared statement.
-// Returns -1 if the driver cannot determine the count.
+// Returns -1 if the driver cannot determine the count, or for {? = CALL proc(...)}
+// statements, whose return value marker may be passed as a ReturnValue or left out.
 func (s *Stmt) NumInput() int {
+	if s.returnValue {
+		return -1
+	}
 	return s.numInput
 }
 
@@ -97,22 +153,29 @@ func (s *Stmt) Exec(args []driver.Value) (driver.Result, error) {
 // ExecContext executes a prepared statement that doesn't return rows.
 // It supports context cancellation and named/positional parameters.
 // Returns a Result with rows affected and output parameter values.
-func (s *Stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+func (s *Stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (_ driver.Result, err error) {
+	op, err := lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer op.end()
+
+	s.conn.applyWorkloadHint(ctx)
+	span := s.conn.startSpan(ctx, SpanExec, s.query)
+	defer func() { endSpan(span, err) }()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if s.closed {
+		return nil, ErrStmtClosed
+	}
+	if s.conn.isClosed() {
 		return nil, driver.ErrBadConn
 	}
 
-	// Set query timeout if configured
-	if s.conn.queryTimeout > 0 {
-		timeoutSecs := int(s.conn.queryTimeout.Seconds())
-		if timeoutSecs < 1 {
-			timeoutSecs = 1
-		}
-		SetStmtAttr(s.stmt, SQL_ATTR_QUERY_TIMEOUT, uintptr(timeoutSecs), 0)
-	}
+	// Set query timeout from the context deadline or connection default
+	s.setQueryTimeout(ctx)
 
 	// Start cancellation goroutine if context has deadline/cancel
 	if ctx.Done() != nil {
@@ -129,7 +192,7 @@ func (s *Stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (drive
 
 	// Bind parameters
 	if err := s.bindParams(args); err != nil {
-		return nil, err
+		return nil, s.conn.observeError(MetricsExec, s.query, err)
 	}
 
 	// Check context before executing
@@ -138,18 +201,28 @@ func (s *Stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (drive
 	}
 
 	// Execute the statement
-	ret := Execute(s.stmt)
-	if !IsSuccess(ret) && ret != SQL_NO_DATA {
+	start := time.Now()
+	_, err = s.executeChecked(ctx, args)
+	executeTime := time.Since(start)
+	if err != nil {
 		// Check if cancelled by context
 		if ctx.Err() != nil {
-			return nil, ctx.Err()
+			return nil, s.conn.observeError(MetricsExec, s.query, ctx.Err())
 		}
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+		return nil, s.conn.observeError(MetricsExec, s.query, err)
 	}
 
 	// Get rows affected
 	var rowCount SQLLEN
 	RowCount(s.stmt, &rowCount)
+	s.conn.observeExec(MetricsExec, s.query, executeTime, int64(rowCount))
+	if span != nil {
+		span.SetAttributes(Attribute{Key: AttrRowsAffected, Value: int64(rowCount)})
+	}
+
+	// Read the counts of later statements of a batch, which also completes the
+	// results some drivers deliver output parameters after
+	allAffected := rowCounts(s.stmt, int64(rowCount))
 
 	// Retrieve output parameter values
 	outputValues := s.retrieveOutputParams()
@@ -160,15 +233,25 @@ func (s *Stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (drive
 		lastInsertId = s.conn.getLastInsertId()
 	}
 
-	// Reset parameters for next execution
-	FreeStmt(s.stmt, SQL_RESET_PARAMS)
+	// Parameter bindings are kept for the next execution to reuse
 	s.outputParams = nil
 
-	return &Result{
+	statsCollectorFromContext(ctx).add(QueryStats{
+		Queries:     1,
+		PrepareTime: s.takePrepareTime(),
+		ExecuteTime: executeTime,
+	})
+
+	result := &Result{
 		rowsAffected: int64(rowCount),
+		allAffected:  allAffected,
 		lastInsertId: lastInsertId,
 		outputParams: outputValues,
-	}, nil
+	}
+	if s.returnValue && len(outputValues) > 0 {
+		result.returnValue = outputValues[0]
+	}
+	return result, nil
 }
 
 // Query executes a prepared statement that returns rows.
@@ -185,23 +268,39 @@ func (s *Stmt) Query(args []driver.Value) (driver.Rows, error) {
 }
 
 // QueryContext executes a prepared statement that returns rows.
-// It supports context cancellation and named/positional parameters.
+// It supports context cancellation and named/positional parameters. Read-only
+// queries are retried on transient errors if the connector has a Retryer.
 func (s *Stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if s.conn.retriesQuery(s.query, args) {
+		return s.conn.retryQuery(ctx, func() (driver.Rows, error) { return s.queryContext(ctx, args) })
+	}
+	return s.queryContext(ctx, args)
+}
+
+// queryContext makes a single attempt to execute the statement
+func (s *Stmt) queryContext(ctx context.Context, args []driver.NamedValue) (_ driver.Rows, err error) {
+	op, err := lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer op.end() // no-op once handed off to the rows
+
+	s.conn.applyWorkloadHint(ctx)
+	span := s.conn.startSpan(ctx, SpanQuery, s.query)
+	defer func() { endSpan(span, err) }()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if s.closed {
+		return nil, ErrStmtClosed
+	}
+	if s.conn.isClosed() {
 		return nil, driver.ErrBadConn
 	}
 
-	// Set query timeout if configured
-	if s.conn.queryTimeout > 0 {
-		timeoutSecs := int(s.conn.queryTimeout.Seconds())
-		if timeoutSecs < 1 {
-			timeoutSecs = 1
-		}
-		SetStmtAttr(s.stmt, SQL_ATTR_QUERY_TIMEOUT, uintptr(timeoutSecs), 0)
-	}
+	// Set query timeout from the context deadline or connection default
+	s.setQueryTimeout(ctx)
 
 	// Start cancellation goroutine if context has deadline/cancel
 	if ctx.Done() != nil {
@@ -218,7 +317,7 @@ func (s *Stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driv
 
 	// Bind parameters
 	if err := s.bindParams(args); err != nil {
-		return nil, err
+		return nil, s.conn.observeError(MetricsQuery, s.query, err)
 	}
 
 	// Check context before executing
@@ -227,17 +326,138 @@ func (s *Stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driv
 	}
 
 	// Execute the statement
-	ret := Execute(s.stmt)
-	if !IsSuccess(ret) {
+	start := time.Now()
+	ret, err := s.executeChecked(ctx, args)
+	executeTime := time.Since(start)
+	if err == nil && ret == SQL_NO_DATA {
+		err = s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	if err != nil {
 		// Check if cancelled by context
 		if ctx.Err() != nil {
-			return nil, ctx.Err()
+			return nil, s.conn.observeError(MetricsQuery, s.query, ctx.Err())
 		}
-		return nil, NewError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+		return nil, s.conn.observeError(MetricsQuery, s.query, err)
 	}
+	s.conn.observeExec(MetricsQuery, s.query, executeTime, -1)
 
 	// Create rows - don't close stmt when rows close (we own it)
-	return newRows(s, false)
+	rows, err := newRows(s, false)
+	if err != nil {
+		return nil, s.conn.observeError(MetricsQuery, s.query, err)
+	}
+	rows.setColumnCasts(columnCastsFromContext(ctx))
+	rows.setLOBStreaming(lobStreamingFromContext(ctx))
+	rows.setStats(ctx, s.takePrepareTime(), executeTime)
+	rows.watchContext(ctx)
+	rows.span = s.conn.startSpan(ctx, SpanFetch, s.query)
+	rows.op = op.handoff()
+	return rows, nil
+}
+
+// setQueryTimeout sets SQL_ATTR_QUERY_TIMEOUT for the next execution,
+// clearing a timeout left by an earlier execution with a deadline
+func (s *Stmt) setQueryTimeout(ctx context.Context) {
+	secs := s.conn.queryTimeoutSecs(ctx)
+	if secs != s.timeoutSecs && IsSuccess(SetStmtAttr(s.stmt, SQL_ATTR_QUERY_TIMEOUT, secs, 0)) {
+		s.timeoutSecs = secs
+	}
+}
+
+// takePrepareTime returns the prepare duration once, so it is only counted for
+// the first execution of a statement
+func (s *Stmt) takePrepareTime() time.Duration {
+	d := s.prepareTime
+	s.prepareTime = 0
+	return d
+}
+
+// executeChecked executes the statement with args bound, returning an error
+// unless it succeeds or returns SQL_NO_DATA. A function sequence error (HY010)
+// is handed to recoverSequence, and an error meaning the statement no longer
+// matches the schema to reprepare outside a transaction, before it is reported.
+func (s *Stmt) executeChecked(ctx context.Context, args []driver.NamedValue) (SQLRETURN, error) {
+	ret, err := s.execute()
+	if err == nil && (IsSuccess(ret) || ret == SQL_NO_DATA) {
+		s.lastErr = nil
+		s.conn.logInfo(ctx, ret, s.stmt, "execute")
+		return ret, nil
+	}
+	if err == nil {
+		err = s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	if IsFunctionSequenceError(err) && len(s.streams) == 0 && ctx.Err() == nil {
+		return s.recoverSequence(ctx, args, err)
+	}
+	if s.conn.isSchemaChangeError(err) && len(s.streams) == 0 && !s.conn.inTx && ctx.Err() == nil {
+		return s.reprepare(ctx, args, err)
+	}
+	s.lastErr = err
+	return ret, err
+}
+
+// recoverSequence handles a function sequence error (HY010) from executing
+// the statement. An earlier failure can leave the handle mid-sequence, with a
+// cursor still open or a data-at-execution exchange unfinished, so that every
+// call fails until it is reset. The statement is closed and its parameters
+// unbound and, if that is not enough, prepared again; each time args are
+// bound again and the execution retried. HY010 is raised before anything is
+// sent to the database, so a retry cannot run the statement twice. Streamed
+// parameters cannot be read twice, so executeChecked does not recover them.
+func (s *Stmt) recoverSequence(ctx context.Context, args []driver.NamedValue, seqErr error) (SQLRETURN, error) {
+	resets := []func() SQLRETURN{
+		func() SQLRETURN {
+			Cancel(s.stmt)
+			FreeStmt(s.stmt, SQL_CLOSE)
+			return s.resetParams()
+		},
+		func() SQLRETURN {
+			s.resultCols = nil
+			return s.conn.prepare(s.stmt, s.conn.tagQuery(ctx, s.preparedQuery()))
+		},
+	}
+	err := seqErr
+	for i, reset := range resets {
+		if !IsSuccess(reset()) {
+			continue
+		}
+		if s.conn.logger != nil {
+			s.conn.logger.LogAttrs(ctx, slog.LevelWarn, "odbc retrying statement after function sequence error", logAttrs(ctx,
+				slog.Int("attempt", i+1),
+				slog.Bool("reprepared", i > 0),
+				slog.String("error", err.Error()))...)
+		}
+		if bindErr := s.bindParams(args); bindErr != nil {
+			return SQL_ERROR, bindErr
+		}
+		ret, execErr := s.execute()
+		if execErr == nil && (IsSuccess(ret) || ret == SQL_NO_DATA) {
+			s.lastErr = nil
+			s.conn.logInfo(ctx, ret, s.stmt, "execute")
+			return ret, nil
+		}
+		if execErr == nil {
+			execErr = s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+		}
+		if err = execErr; !IsFunctionSequenceError(err) {
+			s.lastErr = err
+			return SQL_ERROR, err
+		}
+	}
+
+	if s.lastErr == nil {
+		return SQL_ERROR, err
+	}
+	return SQL_ERROR, &SequenceError{Cause: s.lastErr, Err: err}
+}
+
+// preparedQuery returns the statement text passed to SQLPrepare, with named
+// parameters converted to positional markers
+func (s *Stmt) preparedQuery() string {
+	if s.namedParams != nil {
+		return s.namedParams.Query
+	}
+	return s.query
 }
 
 // bindParams binds parameters to the statement
@@ -246,11 +466,11 @@ func (s *Stmt) bindParams(args []driver.NamedValue) error {
 	if s.namedParams != nil {
 		return s.bindNamedParams(args)
 	}
+	if s.returnValue {
+		args = withReturnValue(args)
+	}
 
-	// Clear previous parameter buffers
-	s.paramBuffers = make([]interface{}, len(args))
-	s.paramLengths = make([]SQLLEN, len(args))
-	s.outputParams = nil
+	s.paramSlots(len(args))
 
 	for _, arg := range args {
 		paramNum := SQLUSMALLINT(arg.Ordinal)
@@ -266,7 +486,9 @@ func (s *Stmt) bindParams(args []driver.NamedValue) error {
 	return nil
 }
 
-// bindNamedParams handles binding for named parameters
+// bindNamedParams handles binding for named parameters. Arguments are matched
+// by name, as passed with sql.Named, or else by the order of the names' first
+// appearance in the query.
 func (s *Stmt) bindNamedParams(args []driver.NamedValue) error {
 	// Calculate total number of parameter positions needed
 	totalPositions := 0
@@ -276,10 +498,7 @@ func (s *Stmt) bindNamedParams(args []driver.NamedValue) error {
 		}
 	}
 
-	// Clear previous parameter buffers
-	s.paramBuffers = make([]interface{}, totalPositions)
-	s.paramLengths = make([]SQLLEN, totalPositions)
-	s.outputParams = nil
+	s.paramSlots(totalPositions)
 
 	// Build a map from parameter name to value for quick lookup
 	valueByName := make(map[string]interface{})
@@ -287,6 +506,10 @@ func (s *Stmt) bindNamedParams(args []driver.NamedValue) error {
 
 	for _, arg := range args {
 		if arg.Name != "" {
+			// A name the query does not use is most likely misspelled
+			if _, ok := s.namedParams.Positions[arg.Name]; !ok {
+				return &ParameterError{Name: arg.Name, Message: "no such named parameter in the query"}
+			}
 			valueByName[arg.Name] = arg.Value
 		} else if arg.Ordinal > 0 {
 			valueByOrdinal[arg.Ordinal] = arg.Value
@@ -313,6 +536,9 @@ func (s *Stmt) bindNamedParams(args []driver.NamedValue) error {
 		if !ok {
 			return &ParameterError{Name: name, Message: "missing value for named parameter"}
 		}
+		if _, stream := streamParamOf(value); stream && len(positions) > 1 {
+			return &ParameterError{Name: name, Message: "streamed value cannot be bound to more than one position"}
+		}
 
 		// Bind the value to each position where this parameter appears
 		for _, pos := range positions {
@@ -322,9 +548,73 @@ func (s *Stmt) bindNamedParams(args []driver.NamedValue) error {
 		}
 	}
 
+	if s.paramNames != nil {
+		return s.setParamNames()
+	}
 	return nil
 }
 
+// paramSlots prepares the parameter buffers for binding n parameters. The
+// buffers, length indicators and bindings of the previous execution are kept
+// if it had the same number of parameters, so bindParam can reuse them;
+// otherwise the parameters are unbound first.
+func (s *Stmt) paramSlots(n int) {
+	if s.bindings == nil || len(s.bindings) != n {
+		if s.bindings != nil {
+			FreeStmt(s.stmt, SQL_RESET_PARAMS)
+		}
+		s.paramBuffers = make([]interface{}, n)
+		s.paramLengths = make([]SQLLEN, n)
+		s.bindings = make([]paramBinding, n)
+	}
+	s.outputParams = nil
+	s.streams = nil
+}
+
+// resetParams unbinds all parameters, so the next execution binds them again
+func (s *Stmt) resetParams() SQLRETURN {
+	s.bindings = nil
+	return FreeStmt(s.stmt, SQL_RESET_PARAMS)
+}
+
+// reuseBinding writes an input parameter value into the buffer bound by the
+// previous execution, reporting false if the value needs a new binding. NULL
+// only sets the length indicator, whatever the bound type.
+func (s *Stmt) reuseBinding(idx int, buf interface{}, cType, sqlType SQLSMALLINT, colSize SQLULEN, decDigits SQLSMALLINT, length SQLLEN) bool {
+	if idx >= len(s.bindings) || !s.bindings[idx].bound {
+		return false
+	}
+	b := s.bindings[idx]
+	if buf == nil {
+		if length != SQL_NULL_DATA {
+			return false
+		}
+		s.paramLengths[idx] = SQL_NULL_DATA
+		return true
+	}
+	if !b.owned || b.cType != cType || b.sqlType != sqlType || b.decDigits != decDigits || colSize > b.colSize {
+		return false
+	}
+
+	bound, value := reflect.ValueOf(s.paramBuffers[idx]), reflect.ValueOf(buf)
+	if !bound.IsValid() || bound.Type() != value.Type() {
+		return false
+	}
+	switch bound.Kind() {
+	case reflect.Pointer:
+		bound.Elem().Set(value.Elem())
+	case reflect.Slice:
+		if value.Len() > bound.Len() {
+			return false
+		}
+		reflect.Copy(bound, value)
+	default:
+		return false
+	}
+	s.paramLengths[idx] = length
+	return true
+}
+
 // bindParam binds a single parameter
 func (s *Stmt) bindParam(paramNum SQLUSMALLINT, value interface{}) error {
 	idx := int(paramNum) - 1
@@ -339,6 +629,9 @@ func (s *Stmt) bindParam(paramNum SQLUSMALLINT, value interface{}) error {
 		for len(s.paramBuffers) <= idx {
 			s.paramBuffers = append(s.paramBuffers, nil)
 			s.paramLengths = append(s.paramLengths, 0)
+			if s.bindings != nil {
+				s.bindings = append(s.bindings, paramBinding{})
+			}
 		}
 	}
 
@@ -351,6 +644,30 @@ func (s *Stmt) bindParam(paramNum SQLUSMALLINT, value interface{}) error {
 		direction = op.Direction
 		actualValue = op.Value
 		outputSize = op.Size
+	} else if rv, ok := value.(ReturnValue); ok {
+		direction = ParamOutput
+		actualValue = rv.Value
+		if actualValue == nil {
+			actualValue = int32(0)
+		}
+	} else {
+		actualValue = s.conn.bindTime(s.conn.bindBool(value))
+	}
+	if direction != ParamOutput {
+		if err := s.conn.checkText(int(paramNum), actualValue); err != nil {
+			return err
+		}
+	}
+
+	// Readers are sent in chunks during execution
+	if sp, ok := streamParamOf(actualValue); ok {
+		if direction != ParamInput {
+			return fmt.Errorf("parameter %d: streamed values can only be input parameters", paramNum)
+		}
+		if idx < len(s.bindings) {
+			s.bindings[idx] = paramBinding{}
+		}
+		return s.bindStream(paramNum, idx, sp)
 	}
 
 	// Determine ODBC parameter direction
@@ -380,6 +697,9 @@ func (s *Stmt) bindParam(paramNum SQLUSMALLINT, value interface{}) error {
 	if err != nil {
 		return err
 	}
+	if direction == ParamInput && s.reuseBinding(idx, buf, cType, sqlType, colSize, decDigits, length) {
+		return nil
+	}
 
 	// Store buffer to keep it alive
 	s.paramBuffers[idx] = buf
@@ -406,7 +726,22 @@ func (s *Stmt) bindParam(paramNum SQLUSMALLINT, value interface{}) error {
 	)
 
 	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+		if idx < len(s.bindings) {
+			s.bindings[idx] = paramBinding{}
+		}
+		return s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	if idx < len(s.bindings) {
+		// A []byte value is bound directly and must not be overwritten
+		_, callerBytes := actualValue.([]byte)
+		s.bindings[idx] = paramBinding{
+			bound:     direction == ParamInput,
+			owned:     !callerBytes,
+			cType:     cType,
+			sqlType:   sqlType,
+			colSize:   colSize,
+			decDigits: decDigits,
+		}
 	}
 
 	// Track output parameters for later retrieval
@@ -643,15 +978,19 @@ func (s *Stmt) convertOutputBuffer(op outputParamInfo) interface{} {
 		return result
 
 	case *SQL_TIMESTAMP_STRUCT:
-		return time.Date(
+		var mode OutOfRangeTimeMode
+		if s.conn != nil {
+			mode = s.conn.outOfRangeTime
+		}
+		return dateTimeValue(
 			int(buf.Year),
-			time.Month(buf.Month),
+			int(buf.Month),
 			int(buf.Day),
 			int(buf.Hour),
 			int(buf.Minute),
 			int(buf.Second),
 			int(buf.Fraction),
-			time.UTC,
+			mode,
 		)
 
 	default:
@@ -667,10 +1006,19 @@ func (s *Stmt) convertOutputBuffer(op outputParamInfo) interface{} {
 // This uses ODBC array binding (SQL_ATTR_PARAMSET_SIZE) for efficient bulk operations.
 // Returns a BatchResult with per-row status information.
 func (s *Stmt) ExecBatch(ctx context.Context, paramSets [][]driver.NamedValue) (*BatchResult, error) {
+	op, err := lifecycle.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer op.end()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if s.closed {
+		return nil, ErrStmtClosed
+	}
+	if s.conn.isClosed() {
 		return nil, driver.ErrBadConn
 	}
 
@@ -685,17 +1033,24 @@ func (s *Stmt) ExecBatch(ctx context.Context, paramSets [][]driver.NamedValue) (
 	}
 
 	result := &BatchResult{
-		RowCounts: make([]int64, numRows),
-		Errors:    make([]error, numRows),
+		RowCounts:   make([]int64, numRows),
+		Errors:      make([]error, numRows),
+		StatusCodes: make([]uint16, numRows),
+	}
+	for i := range result.StatusCodes {
+		result.StatusCodes[i] = SQL_PARAM_UNUSED
 	}
 
 	// Try to use true array binding
 	arrayBindingWorked := s.execBatchArrayBinding(ctx, paramSets, numRows, numParams, result)
 
 	if !arrayBindingWorked {
-		// Fall back to row-by-row execution if array binding fails
-		s.execBatchRowByRow(ctx, paramSets, result)
+		// Fall back to multi-row INSERT synthesis if enabled, then row-by-row
+		if !s.conn.multiRowInsert || !s.execBatchMultiRow(ctx, paramSets, result) {
+			s.execBatchRowByRow(ctx, paramSets, result)
+		}
 	}
+	s.keepBatchErrorValues(paramSets, result)
 
 	s.outputParams = nil
 
@@ -705,7 +1060,7 @@ func (s *Stmt) ExecBatch(ctx context.Context, paramSets [][]driver.NamedValue) (
 // execBatchArrayBinding attempts to use ODBC array binding for batch execution
 // Returns true if array binding was successful, false if fallback is needed
 func (s *Stmt) execBatchArrayBinding(ctx context.Context, paramSets [][]driver.NamedValue, numRows, numParams int, result *BatchResult) bool {
-	if numParams == 0 {
+	if numParams == 0 || hasStreamParams(paramSets) {
 		return false
 	}
 
@@ -748,7 +1103,13 @@ func (s *Stmt) execBatchArrayBinding(ctx context.Context, paramSets [][]driver.N
 		values := make([]interface{}, numRows)
 		for rowIdx := 0; rowIdx < numRows; rowIdx++ {
 			if paramIdx < len(paramSets[rowIdx]) {
-				values[rowIdx] = paramSets[rowIdx][paramIdx].Value
+				values[rowIdx] = s.conn.bindTime(s.conn.bindBool(paramSets[rowIdx][paramIdx].Value))
+				// Invalid text is reported with its row by row-by-row execution
+				if s.conn.checkText(paramIdx+1, values[rowIdx]) != nil {
+					SetStmtAttr(s.stmt, SQL_ATTR_PARAMSET_SIZE, 1, 0)
+					s.resetParams()
+					return false
+				}
 			}
 		}
 
@@ -757,9 +1118,12 @@ func (s *Stmt) execBatchArrayBinding(ctx context.Context, paramSets [][]driver.N
 		if err != nil || colBuf == nil {
 			// Reset and fall back
 			SetStmtAttr(s.stmt, SQL_ATTR_PARAMSET_SIZE, 1, 0)
-			FreeStmt(s.stmt, SQL_RESET_PARAMS)
+			s.resetParams()
 			return false
 		}
+		if paramIdx < len(s.paramColumns) {
+			colBuf.applyColumnType(s.paramColumns[paramIdx])
+		}
 		columnBuffers[paramIdx] = colBuf
 
 		// Bind the parameter array
@@ -778,13 +1142,21 @@ func (s *Stmt) execBatchArrayBinding(ctx context.Context, paramSets [][]driver.N
 		)
 		if !IsSuccess(ret) {
 			SetStmtAttr(s.stmt, SQL_ATTR_PARAMSET_SIZE, 1, 0)
-			FreeStmt(s.stmt, SQL_RESET_PARAMS)
+			s.resetParams()
 			return false
 		}
 	}
 
-	// Execute the batch
-	ret = Execute(s.stmt)
+	// Execute the batch. A driver that asks for data-at-execution values here
+	// is answered through execute, which cancels the exchange rather than
+	// leaving the statement mid-sequence.
+	ret, execErr := s.execute()
+
+	// The driver fills the status array and processed count even when execution fails
+	result.RowsProcessed = uint64(rowsProcessed)
+	for i := 0; i < numRows; i++ {
+		result.StatusCodes[i] = uint16(statusArray[i])
+	}
 
 	// Process results
 	if IsSuccess(ret) || ret == SQL_SUCCESS_WITH_INFO {
@@ -826,7 +1198,10 @@ func (s *Stmt) execBatchArrayBinding(ctx context.Context, paramSets [][]driver.N
 		result.TotalRowsAffected = 0
 	} else {
 		// Batch failed entirely
-		err := NewError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+		err := execErr
+		if err == nil {
+			err = s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+		}
 		for i := 0; i < numRows; i++ {
 			result.Errors[i] = err
 		}
@@ -834,7 +1209,7 @@ func (s *Stmt) execBatchArrayBinding(ctx context.Context, paramSets [][]driver.N
 
 	// Reset for normal operation
 	SetStmtAttr(s.stmt, SQL_ATTR_PARAMSET_SIZE, 1, 0)
-	FreeStmt(s.stmt, SQL_RESET_PARAMS)
+	s.resetParams()
 
 	return true
 }
@@ -842,10 +1217,11 @@ func (s *Stmt) execBatchArrayBinding(ctx context.Context, paramSets [][]driver.N
 // execBatchRowByRow executes each parameter set individually (fallback)
 func (s *Stmt) execBatchRowByRow(ctx context.Context, paramSets [][]driver.NamedValue, result *BatchResult) {
 	for i, params := range paramSets {
-		// Clear and bind parameters for this set
-		s.paramBuffers = make([]interface{}, len(params))
-		s.paramLengths = make([]SQLLEN, len(params))
-		s.outputParams = nil
+		result.RowsProcessed++
+		result.StatusCodes[i] = SQL_PARAM_ERROR
+
+		// Bind parameters for this set, reusing the previous set's bindings
+		s.paramSlots(len(params))
 
 		for _, param := range params {
 			paramNum := SQLUSMALLINT(param.Ordinal)
@@ -863,20 +1239,26 @@ func (s *Stmt) execBatchRowByRow(ctx context.Context, paramSets [][]driver.Named
 		}
 
 		// Execute
-		ret := Execute(s.stmt)
+		ret, err := s.execute()
+		if err != nil {
+			result.Errors[i] = err
+			continue
+		}
 		if !IsSuccess(ret) && ret != SQL_NO_DATA {
-			result.Errors[i] = NewError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+			result.Errors[i] = s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
 			continue
 		}
 
+		result.StatusCodes[i] = SQL_PARAM_SUCCESS
+		if ret == SQL_SUCCESS_WITH_INFO {
+			result.StatusCodes[i] = SQL_PARAM_SUCCESS_WITH_INFO
+		}
+
 		// Get rows affected
 		var rowCount SQLLEN
 		RowCount(s.stmt, &rowCount)
 		result.RowCounts[i] = int64(rowCount)
 		result.TotalRowsAffected += int64(rowCount)
-
-		// Reset parameters for next set
-		FreeStmt(s.stmt, SQL_RESET_PARAMS)
 	}
 }
 
@@ -909,6 +1291,50 @@ func isInsertStatement(query string) bool {
 	return false
 }
 
+// =============================================================================
+// Cursor Name Support
+// =============================================================================
+
+// maxCursorNameLen is the buffer size used to read cursor names.
+// ODBC drivers must support cursor names of at least 18 characters.
+const maxCursorNameLen = 256
+
+// SetCursorName sets the name of the statement's cursor (SQLSetCursorName).
+// Another statement on the same connection can then update or delete the current
+// row with positioned DML: UPDATE t SET ... WHERE CURRENT OF <name>.
+// The name must be set before the statement is executed.
+func (s *Stmt) SetCursorName(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrStmtClosed
+	}
+
+	ret := s.conn.setCursorName(s.stmt, name)
+	if !IsSuccess(ret) {
+		return s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	return nil
+}
+
+// CursorName returns the name of the statement's cursor (SQLGetCursorName).
+// If no name was set, the driver generates one (typically prefixed with SQL_CUR).
+func (s *Stmt) CursorName() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return "", ErrStmtClosed
+	}
+
+	name, ret := s.conn.cursorName(s.stmt, maxCursorNameLen)
+	if !IsSuccess(ret) {
+		return "", s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	return name, nil
+}
+
 // Ensure Stmt implements the required interfaces
 var (
 	_ driver.Stmt             = (*Stmt)(nil)
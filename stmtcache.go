@@ -0,0 +1,168 @@
+package godbc
+
+import (
+	"container/list"
+	"context"
+	"database/sql/driver"
+	"sync"
+)
+
+// stmtCache keeps the statements Conn.ExecContext and Conn.QueryContext
+// prepare for queries with arguments, by query text, so running the same
+// query again reuses its SQLPrepare handle. When the cache is full the least
+// recently used statement is closed.
+type stmtCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List               // *cachedStmt, most recently used first
+	entries map[string]*list.Element // by query text
+}
+
+// cachedStmt is a statement in the cache. A statement in use by an execution
+// or open rows is not handed out again until it is released.
+type cachedStmt struct {
+	query   string
+	stmt    *Stmt
+	inUse   bool
+	evicted bool // removed from the cache while in use; closed on release
+}
+
+// newStmtCache returns a cache of size statements, or nil if size is 0 or less
+func newStmtCache(size int) *stmtCache {
+	if size <= 0 {
+		return nil
+	}
+	return &stmtCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// take returns the idle cached statement for a query and marks it in use
+func (sc *stmtCache) take(query string) *cachedStmt {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	el, ok := sc.entries[query]
+	if !ok {
+		return nil
+	}
+	cs := el.Value.(*cachedStmt)
+	if cs.inUse {
+		return nil
+	}
+	cs.inUse = true
+	sc.order.MoveToFront(el)
+	return cs
+}
+
+// add caches a statement prepared for a query, marked in use, and returns the
+// entry, or nil if the query is already cached by a statement in use
+func (sc *stmtCache) add(query string, stmt *Stmt) *cachedStmt {
+	sc.mu.Lock()
+	var evicted []*Stmt
+	defer func() {
+		sc.mu.Unlock()
+		for _, s := range evicted {
+			s.Close()
+		}
+	}()
+
+	if _, ok := sc.entries[query]; ok {
+		return nil
+	}
+	cs := &cachedStmt{query: query, stmt: stmt, inUse: true}
+	sc.entries[query] = sc.order.PushFront(cs)
+	for sc.order.Len() > sc.size {
+		if s := sc.removeLocked(sc.order.Back()); s != nil {
+			evicted = append(evicted, s)
+		}
+	}
+	return cs
+}
+
+// release returns a statement to the cache after use. A statement that
+// failed is closed rather than reused; a connection error closes every
+// cached statement, since their handles may no longer be valid.
+func (sc *stmtCache) release(cs *cachedStmt, err error) {
+	if IsConnectionError(err) || err == driver.ErrBadConn {
+		sc.purge()
+	}
+	sc.mu.Lock()
+	cs.inUse = false
+	closeStmt := cs.evicted
+	if err != nil && !cs.evicted {
+		sc.removeLocked(sc.entries[cs.query])
+		closeStmt = true
+	}
+	sc.mu.Unlock()
+	if closeStmt {
+		cs.stmt.Close()
+	}
+}
+
+// purge closes every idle cached statement and empties the cache. Statements
+// in use are closed when they are released.
+func (sc *stmtCache) purge() {
+	sc.mu.Lock()
+	var idle []*Stmt
+	for el := sc.order.Front(); el != nil; {
+		next := el.Next()
+		if s := sc.removeLocked(el); s != nil {
+			idle = append(idle, s)
+		}
+		el = next
+	}
+	sc.mu.Unlock()
+	for _, s := range idle {
+		s.Close()
+	}
+}
+
+// len returns the number of cached statements
+func (sc *stmtCache) len() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.order.Len()
+}
+
+// removeLocked removes an entry from the cache, returning its statement if
+// it is idle and should be closed by the caller. An entry in use is marked
+// evicted and closed when released.
+func (sc *stmtCache) removeLocked(el *list.Element) *Stmt {
+	cs := el.Value.(*cachedStmt)
+	sc.order.Remove(el)
+	delete(sc.entries, cs.query)
+	if cs.inUse {
+		cs.evicted = true
+		return nil
+	}
+	return cs.stmt
+}
+
+// prepareCached returns a prepared statement for a query with arguments and
+// the function that releases it after execution, reusing a cached statement
+// when one is idle. Queries tagged with a correlation ID differ per request
+// and are not cached.
+func (c *Conn) prepareCached(ctx context.Context, query string) (*Stmt, func(error), error) {
+	if c.stmtCache == nil || c.tagQuery(ctx, query) != query {
+		ds, err := c.PrepareContext(ctx, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ds.(*Stmt), func(error) { ds.Close() }, nil
+	}
+
+	if cs := c.stmtCache.take(query); cs != nil {
+		return cs.stmt, func(err error) { c.stmtCache.release(cs, err) }, nil
+	}
+	ds, err := c.PrepareContext(ctx, query)
+	if err != nil {
+		if IsConnectionError(err) || err == driver.ErrBadConn {
+			c.stmtCache.purge()
+		}
+		return nil, nil, err
+	}
+	stmt := ds.(*Stmt)
+	cs := c.stmtCache.add(query, stmt)
+	if cs == nil {
+		return stmt, func(error) { stmt.Close() }, nil
+	}
+	return stmt, func(err error) { c.stmtCache.release(cs, err) }, nil
+}
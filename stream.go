@@ -0,0 +1,228 @@
+package godbc
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"runtime"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// streamChunkSize is the amount of data read from a StreamParam per SQLPutData call
+const streamChunkSize = 64 << 10
+
+// StreamParam is a parameter whose value is sent in chunks with SQLPutData
+// during execution (SQL_DATA_AT_EXEC), so large values such as a 2 GB
+// VARBINARY never have to be held in memory. A plain io.Reader passed as a
+// parameter is streamed as binary data.
+//
+// Example:
+//
+//	f, _ := os.Open("backup.bin")
+//	_, err := db.ExecContext(ctx, "INSERT INTO files (name, content) VALUES (?, ?)",
+//	    "backup.bin", godbc.StreamParam{Reader: f, Size: info.Size()})
+type StreamParam struct {
+	// Reader supplies the value. It is read to EOF when the statement executes.
+	Reader io.Reader
+
+	// Size is the length of a binary value in bytes, or 0 if unknown. Drivers
+	// that need the length up front (SQL_NEED_LONG_DATA_LEN) require it. A
+	// size too large for SQLLEN, as over 2 GiB with 32-bit lengths, is not sent.
+	Size int64
+
+	// Text streams UTF-8 character data (WLONGVARCHAR) instead of binary data
+	Text bool
+}
+
+// streamBinding is a StreamParam bound to a parameter marker. The parameter
+// number is bound as the data-at-exec token that SQLParamData hands back.
+type streamBinding struct {
+	paramNum SQLUSMALLINT
+	param    StreamParam
+}
+
+// streamParamOf returns the StreamParam for a parameter value, if it is streamed.
+// The size of readers that report their remaining length, such as
+// bytes.Reader and strings.Reader, is filled in.
+func streamParamOf(value interface{}) (StreamParam, bool) {
+	switch v := value.(type) {
+	case StreamParam:
+		return v, v.Reader != nil
+	case io.Reader:
+		sp := StreamParam{Reader: v}
+		if l, ok := v.(interface{ Len() int }); ok {
+			sp.Size = int64(l.Len())
+		}
+		return sp, true
+	}
+	return StreamParam{}, false
+}
+
+// hasStreamParams reports whether any parameter set contains a streamed value
+func hasStreamParams(paramSets [][]driver.NamedValue) bool {
+	for _, params := range paramSets {
+		for _, param := range params {
+			if _, ok := streamParamOf(param.Value); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maxSQLLEN is the largest SQLLEN value in this build
+const maxSQLLEN = int64(^SQLULEN(0) >> 1)
+
+// sqlLenDataAtExec is the SQL_LEN_DATA_AT_EXEC(length) macro. It reports false
+// if the result does not fit in SQLLEN, as with large sizes and 32-bit lengths.
+func sqlLenDataAtExec(length int64) (SQLLEN, bool) {
+	if length < 0 || length > maxSQLLEN+int64(SQL_LEN_DATA_AT_EXEC_OFFSET)+1 {
+		return 0, false
+	}
+	return SQL_LEN_DATA_AT_EXEC_OFFSET - SQLLEN(length), true
+}
+
+// bindStream binds a parameter as data-at-execution
+func (s *Stmt) bindStream(paramNum SQLUSMALLINT, idx int, sp StreamParam) error {
+	cType, sqlType := SQL_C_BINARY, SQL_LONGVARBINARY
+	length := SQL_DATA_AT_EXEC
+	var colSize SQLULEN
+	if sp.Size > 0 {
+		// A size too large for SQLLEN is not reported; the stream is then
+		// bound as plain SQL_DATA_AT_EXEC with an unknown column size
+		if n, ok := sqlLenDataAtExec(sp.Size); ok {
+			colSize = SQLULEN(sp.Size)
+			if !sp.Text {
+				length = n
+			}
+		}
+	}
+	if sp.Text {
+		// The length of the converted data is not known in advance
+		cType, sqlType = SQL_C_WCHAR, SQL_WLONGVARCHAR
+	}
+
+	s.paramBuffers[idx] = nil
+	s.paramLengths[idx] = length
+	ret := BindParameter(s.stmt, paramNum, SQL_PARAM_INPUT, cType, sqlType, colSize, 0, uintptr(paramNum), 0, &s.paramLengths[idx])
+	if !IsSuccess(ret) {
+		return s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	s.streams = append(s.streams, streamBinding{paramNum: paramNum, param: sp})
+	return nil
+}
+
+// execute runs the prepared statement, sending streamed parameter values when
+// the driver asks for them. The returned error is set only when a stream could
+// not be sent; driver errors are reported through the return code as usual.
+func (s *Stmt) execute() (SQLRETURN, error) {
+	ret := Execute(s.stmt)
+	if ret != SQL_NEED_DATA {
+		return ret, nil
+	}
+	return s.putStreams()
+}
+
+// putStreams answers the driver's SQLParamData requests until every streamed
+// parameter has been sent, returning the result of the execution
+func (s *Stmt) putStreams() (SQLRETURN, error) {
+	for {
+		token, ret := ParamData(s.stmt)
+		if ret != SQL_NEED_DATA {
+			return ret, nil
+		}
+
+		var sp *StreamParam
+		for i := range s.streams {
+			if uintptr(s.streams[i].paramNum) == token {
+				sp = &s.streams[i].param
+				break
+			}
+		}
+		if sp == nil {
+			Cancel(s.stmt)
+			return SQL_ERROR, fmt.Errorf("driver requested data for unknown parameter %d", token)
+		}
+		if err := s.putStream(*sp); err != nil {
+			// Cancel ends the data-at-execution sequence so the statement can be reused
+			Cancel(s.stmt)
+			return SQL_ERROR, fmt.Errorf("parameter %d: %w", token, err)
+		}
+	}
+}
+
+// putStream sends a parameter value with one SQLPutData call per chunk
+func (s *Stmt) putStream(sp StreamParam) error {
+	buf := make([]byte, streamChunkSize)
+	var carry []byte // incomplete UTF-8 sequence held for the next chunk
+	sent := false
+	for {
+		n, readErr := sp.Reader.Read(buf)
+		eof := readErr == io.EOF
+		if readErr != nil && !eof {
+			return readErr
+		}
+
+		chunk := buf[:n]
+		if sp.Text {
+			chunk = append(carry, chunk...)
+			carry = nil
+			if !eof {
+				cut := len(chunk) - incompleteRuneSuffix(chunk)
+				carry = append([]byte(nil), chunk[cut:]...)
+				chunk = chunk[:cut]
+			}
+		}
+		if len(chunk) > 0 || (eof && !sent) {
+			if err := s.putChunk(chunk, sp.Text); err != nil {
+				return err
+			}
+			sent = true
+		}
+		if eof {
+			return nil
+		}
+	}
+}
+
+// putChunk sends one chunk of a value, converting text to SQLWCHAR
+func (s *Stmt) putChunk(chunk []byte, text bool) error {
+	var data interface{} = chunk
+	length := len(chunk)
+	if text {
+		var byteLen int
+		data, _, byteLen = wideStringParam(string(chunk))
+		length = byteLen
+	}
+
+	var ptr uintptr
+	if length > 0 {
+		ptr, _ = getBufferPtr(data)
+	} else {
+		// Zero-length values still need a valid data pointer
+		var empty [4]byte
+		data = &empty
+		ptr = uintptr(unsafe.Pointer(&empty[0]))
+	}
+	ret := PutData(s.stmt, ptr, SQLLEN(length))
+	runtime.KeepAlive(data)
+	if !IsSuccess(ret) {
+		return s.conn.newError(SQL_HANDLE_STMT, SQLHANDLE(s.stmt))
+	}
+	return nil
+}
+
+// incompleteRuneSuffix returns the number of bytes at the end of p that start
+// a UTF-8 sequence continued in the next chunk
+func incompleteRuneSuffix(p []byte) int {
+	for i := 1; i <= utf8.UTFMax-1 && i <= len(p); i++ {
+		if utf8.RuneStart(p[len(p)-i]) {
+			if utf8.FullRune(p[len(p)-i:]) {
+				return 0
+			}
+			return i
+		}
+	}
+	return 0
+}
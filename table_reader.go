@@ -0,0 +1,258 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// defaultChunkSize is the number of rows read per chunk when none is configured
+const defaultChunkSize = 10000
+
+// paginationStyle is the syntax used to limit the rows returned by a chunk query
+type paginationStyle int
+
+const (
+	paginationLimit      paginationStyle = iota // ... LIMIT n
+	paginationTop                               // SELECT TOP n ...
+	paginationFetchFirst                        // ... FETCH FIRST n ROWS ONLY
+)
+
+// paginationStyles maps database types to their row-limiting syntax.
+// Databases not listed use LIMIT.
+var paginationStyles = map[string]paginationStyle{
+	"sql server": paginationTop,
+	"oracle":     paginationFetchFirst,
+	"db2":        paginationFetchFirst,
+}
+
+// TableChunk is one key-ordered chunk of rows read by a TableReader
+type TableChunk struct {
+	Columns []string
+	Rows    [][]driver.Value
+	LastKey interface{} // Key value of the last row in the chunk
+}
+
+// TableReader reads an entire table in primary-key ordered chunks.
+// Each chunk is a separate query (WHERE key > last ORDER BY key, limited to the
+// chunk size), so no cursor is held open between chunks. If a chunk fails, the
+// reader's position is unchanged and Next can simply be called again; to resume
+// in a new process, persist LastKey and pass it to WithResumeAfter.
+type TableReader struct {
+	conn      *Conn
+	table     string
+	key       string
+	keyName   string // key with identifier quotes removed, to find it in results
+	columns   []string
+	chunkSize int
+	lastKey   interface{}
+	hasKey    bool // whether lastKey is set and chunks start after it
+	done      bool
+}
+
+// TableReaderOption configures a TableReader
+type TableReaderOption func(*TableReader)
+
+// WithKeyColumn sets the column used to order and page through the table.
+// It must be unique and non-null. Defaults to the table's single-column primary key.
+func WithKeyColumn(column string) TableReaderOption {
+	return func(r *TableReader) {
+		r.key = column
+	}
+}
+
+// WithSelectColumns restricts the columns read (defaults to all columns).
+// The key column is added if not listed.
+func WithSelectColumns(columns ...string) TableReaderOption {
+	return func(r *TableReader) {
+		r.columns = columns
+	}
+}
+
+// WithChunkSize sets the number of rows read per chunk (defaults to 10000)
+func WithChunkSize(n int) TableReaderOption {
+	return func(r *TableReader) {
+		r.chunkSize = n
+	}
+}
+
+// WithResumeAfter starts reading after the given key value, as returned by LastKey
+func WithResumeAfter(key interface{}) TableReaderOption {
+	return func(r *TableReader) {
+		r.lastKey = key
+		r.hasKey = key != nil
+	}
+}
+
+// NewTableReader creates a chunked reader for a table.
+// If no key column is configured, the table's primary key is looked up through
+// SQLPrimaryKeys; tables without a single-column primary key require WithKeyColumn.
+func (c *Conn) NewTableReader(ctx context.Context, table string, opts ...TableReaderOption) (*TableReader, error) {
+	parts, err := parseTableName(table)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &TableReader{
+		conn:      c,
+		table:     table,
+		chunkSize: defaultChunkSize,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.chunkSize < 1 {
+		return nil, fmt.Errorf("invalid chunk size %d", r.chunkSize)
+	}
+
+	if r.key == "" {
+		var schema string
+		if len(parts) > 1 {
+			schema = parts[len(parts)-2]
+		}
+		keys, err := c.primaryKeyColumns(ctx, schema, parts[len(parts)-1])
+		if err != nil {
+			return nil, fmt.Errorf("looking up primary key of %s: %w", table, err)
+		}
+		if len(keys) != 1 {
+			return nil, fmt.Errorf("table %s has %d primary key columns; use WithKeyColumn to choose a unique column", table, len(keys))
+		}
+		// Quote the stored name unless the database would fold it back to itself
+		r.key = keys[0]
+		if _, err := unquoteColumnName(r.key); err != nil || c.NormalizeIdentifier(r.key) != r.key {
+			r.key = c.QuoteIdentifier(r.key)
+		}
+	}
+
+	if r.keyName, err = unquoteColumnName(r.key); err != nil {
+		return nil, err
+	}
+	hasKey := false
+	for _, col := range r.columns {
+		name, err := unquoteColumnName(col)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(name, r.keyName) {
+			hasKey = true
+		}
+	}
+	if len(r.columns) > 0 && !hasKey {
+		r.columns = append(append([]string(nil), r.columns...), r.key)
+	}
+
+	return r, nil
+}
+
+// Next reads the next chunk. It returns io.EOF when the table has been fully read.
+// On error the position is not advanced, so calling Next again retries the same chunk.
+func (r *TableReader) Next(ctx context.Context) (*TableChunk, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	var args []interface{}
+	if r.hasKey {
+		args = []interface{}{r.lastKey}
+	}
+	rows, err := r.conn.QueryContext(ctx, r.chunkQuery(), namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := rows.Columns()
+	keyIndex := -1
+	for i, col := range columns {
+		if strings.EqualFold(col, r.keyName) {
+			keyIndex = i
+			break
+		}
+	}
+	if keyIndex < 0 {
+		return nil, fmt.Errorf("key column %s not found in result", r.key)
+	}
+
+	chunk := &TableChunk{Columns: columns}
+	for len(chunk.Rows) < r.chunkSize {
+		dest := make([]driver.Value, len(columns))
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		chunk.Rows = append(chunk.Rows, dest)
+	}
+
+	if len(chunk.Rows) < r.chunkSize {
+		r.done = true
+	}
+	if len(chunk.Rows) == 0 {
+		return nil, io.EOF
+	}
+
+	chunk.LastKey = chunk.Rows[len(chunk.Rows)-1][keyIndex]
+	r.lastKey = chunk.LastKey
+	r.hasKey = true
+	return chunk, nil
+}
+
+// LastKey returns the key value of the last row read, or nil if nothing has been read
+func (r *TableReader) LastKey() interface{} {
+	return r.lastKey
+}
+
+// chunkQuery builds the query for the next chunk
+func (r *TableReader) chunkQuery() string {
+	selectList := "*"
+	if len(r.columns) > 0 {
+		selectList = strings.Join(r.columns, ", ")
+	}
+	limit := strconv.Itoa(r.chunkSize)
+	style := r.conn.paginationStyle()
+
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	if style == paginationTop {
+		sb.WriteString("TOP " + limit + " ")
+	}
+	sb.WriteString(selectList + " FROM " + r.table)
+	if r.hasKey {
+		sb.WriteString(" WHERE " + r.key + " > ?")
+	}
+	sb.WriteString(" ORDER BY " + r.key)
+	switch style {
+	case paginationFetchFirst:
+		sb.WriteString(" FETCH FIRST " + limit + " ROWS ONLY")
+	case paginationLimit:
+		sb.WriteString(" LIMIT " + limit)
+	}
+	return sb.String()
+}
+
+// paginationStyle returns the row-limiting syntax for the connection's database type
+func (c *Conn) paginationStyle() paginationStyle {
+	if dbTypeLower := strings.ToLower(c.dbType); dbTypeLower != "" {
+		for dbName, style := range paginationStyles {
+			if strings.Contains(dbTypeLower, dbName) {
+				return style
+			}
+		}
+	}
+	return paginationLimit
+}
+
+// unquoteColumnName checks that a column name is a single, safely embeddable
+// identifier and returns it without identifier quotes
+func unquoteColumnName(column string) (string, error) {
+	parts, err := parseTableName(column)
+	if err != nil || len(parts) != 1 {
+		return "", fmt.Errorf("invalid column name %q", column)
+	}
+	name, _ := unquoteIdentifier(parts[0])
+	return name, nil
+}
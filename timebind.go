@@ -0,0 +1,67 @@
+package godbc
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultTimeLayout is the layout time.Time parameters are formatted with
+// when they are bound as strings and neither WithTimeBinding nor the
+// database's entry in timeLiteralLayouts supplies one
+const DefaultTimeLayout = "2006-01-02 15:04:05.000"
+
+// timeLiteralLayouts maps database types whose drivers reject SQL_C_TIMESTAMP
+// parameters to the layout of the datetime literals they accept. TimeBindAuto
+// binds time parameters as strings for these databases.
+var timeLiteralLayouts = map[string]string{
+	"access":   "2006-01-02 15:04:05",
+	"informix": "2006-01-02 15:04:05.00000",
+}
+
+// timeBindLayout returns the layout to format time parameters with, or false
+// if they are bound as SQL_C_TIMESTAMP
+func (c *Conn) timeBindLayout() (string, bool) {
+	if c == nil || c.timeBindMode == TimeBindTimestamp {
+		return "", false
+	}
+	dialectLayout := ""
+	if dbTypeLower := strings.ToLower(c.dbType); dbTypeLower != "" {
+		for dbName, layout := range timeLiteralLayouts {
+			if strings.Contains(dbTypeLower, dbName) {
+				dialectLayout = layout
+				break
+			}
+		}
+	}
+	if c.timeBindMode == TimeBindAuto && dialectLayout == "" {
+		return "", false
+	}
+	switch {
+	case c.timeLayout != "":
+		return c.timeLayout, true
+	case dialectLayout != "":
+		return dialectLayout, true
+	}
+	return DefaultTimeLayout, true
+}
+
+// bindTime formats time parameters as strings when the connection binds them
+// as literals. Other values are returned unchanged.
+func (c *Conn) bindTime(value interface{}) interface{} {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case Timestamp:
+		t = v.Time
+	case TimestampTZ:
+		t = v.Time.UTC()
+	default:
+		return value
+	}
+	layout, ok := c.timeBindLayout()
+	if !ok {
+		return value
+	}
+	return t.Format(layout)
+}
@@ -0,0 +1,82 @@
+package godbc
+
+import (
+	"fmt"
+	"time"
+)
+
+// MinTime and MaxTime are the earliest and latest timestamps representable in
+// SQL (0001-01-01 and 9999-12-31 23:59:59.999999999 UTC). OutOfRangeTimeClamp
+// maps values outside this range, such as PostgreSQL '-infinity' and 'infinity', to them.
+var (
+	MinTime = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	MaxTime = time.Date(9999, time.December, 31, 23, 59, 59, 999999999, time.UTC)
+)
+
+// OutOfRangeTime is returned in place of time.Time for DATE and TIMESTAMP values
+// that cannot be represented faithfully: years before 1 or after 9999 (BC dates,
+// 'infinity' sentinels) and fields the driver reported out of range. The fields
+// hold the raw values reported by the driver.
+type OutOfRangeTime struct {
+	Year       int
+	Month      int
+	Day        int
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+// String formats the value as YYYY-MM-DD HH:MM:SS[.fffffffff], with a sign for years before 1
+func (t OutOfRangeTime) String() string {
+	year := fmt.Sprintf("%04d", t.Year)
+	if t.Year < 0 {
+		year = fmt.Sprintf("-%04d", -t.Year)
+	}
+	s := fmt.Sprintf("%s-%02d-%02d %02d:%02d:%02d", year, t.Month, t.Day, t.Hour, t.Minute, t.Second)
+	if t.Nanosecond != 0 {
+		s += fmt.Sprintf(".%09d", t.Nanosecond)
+	}
+	return s
+}
+
+// BeforeMin reports whether the value lies before MinTime (year 0 or earlier)
+func (t OutOfRangeTime) BeforeMin() bool {
+	return t.Year < 1
+}
+
+// AfterMax reports whether the value lies after MaxTime (year 10000 or later)
+func (t OutOfRangeTime) AfterMax() bool {
+	return t.Year > 9999
+}
+
+// dateTimeValue converts the fields of a DATE or TIMESTAMP struct to a
+// time.Time in UTC, or handles them according to mode when the fields do not
+// describe a valid time between MinTime and MaxTime
+func dateTimeValue(year, month, day, hour, minute, second, nanos int, mode OutOfRangeTimeMode) interface{} {
+	t := time.Date(year, time.Month(month), day, hour, minute, second, nanos, time.UTC)
+	if year >= 1 && year <= 9999 &&
+		t.Year() == year && int(t.Month()) == month && t.Day() == day &&
+		t.Hour() == hour && t.Minute() == minute && t.Second() == second && t.Nanosecond() == nanos {
+		return t
+	}
+
+	// time.Date normalizes invalid fields and accepts years SQL cannot represent,
+	// which would silently turn the value into a different time
+	raw := OutOfRangeTime{
+		Year: year, Month: month, Day: day,
+		Hour: hour, Minute: minute, Second: second, Nanosecond: nanos,
+	}
+	switch mode {
+	case OutOfRangeTimeString:
+		return raw.String()
+	case OutOfRangeTimeClamp:
+		if raw.BeforeMin() {
+			return MinTime
+		}
+		if raw.AfterMax() {
+			return MaxTime
+		}
+	}
+	return raw
+}
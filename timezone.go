@@ -0,0 +1,61 @@
+package godbc
+
+import (
+	"strings"
+	"time"
+)
+
+// timeWithZoneLayouts are the text forms drivers use for TIMESTAMP WITH TIME
+// ZONE values, after a 'T' separator is replaced by a space. Fractional
+// seconds are accepted after the seconds field without being in the layout.
+var timeWithZoneLayouts = []string{
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z07",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02 15:04:05 Z07:00",
+	"2006-01-02 15:04:05 Z07",
+	"2006-01-02 15:04:05 Z0700",
+}
+
+// timeOnlyWithZoneLayouts are the text forms of TIME WITH TIME ZONE values
+var timeOnlyWithZoneLayouts = []string{
+	"15:04:05Z07:00",
+	"15:04:05Z07",
+	"15:04:05Z0700",
+	"15:04:05 Z07:00",
+	"15:04:05 Z07",
+	"15:04:05 Z0700",
+}
+
+// offsetTimeValue converts the fields of a timestamp with a UTC offset to a
+// time.Time in a fixed zone. Fields that do not describe a valid time between
+// MinTime and MaxTime are handled according to mode, ignoring the offset.
+func offsetTimeValue(year, month, day, hour, minute, second, nanos, tzHour, tzMinute int, mode OutOfRangeTimeMode) interface{} {
+	v := dateTimeValue(year, month, day, hour, minute, second, nanos, mode)
+	t, ok := v.(time.Time)
+	if !ok {
+		return v
+	}
+	zone := time.FixedZone("", tzHour*3600+tzMinute*60)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), zone)
+}
+
+// parseTimeWithZone parses the text a driver returns for a TIME or TIMESTAMP
+// WITH TIME ZONE value. Times without a date are returned on 0000-01-01 like
+// TIME columns.
+func parseTimeWithZone(s string, timeOnly bool) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	layouts := timeOnlyWithZoneLayouts
+	if !timeOnly {
+		layouts = timeWithZoneLayouts
+		if len(s) > 10 && s[10] == 'T' {
+			s = s[:10] + " " + s[11:]
+		}
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,204 @@
+package godbc
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+)
+
+const (
+	// defaultTraceMaxSize is the trace file size that triggers rotation
+	defaultTraceMaxSize = 64 << 20
+
+	// defaultTraceMaxFiles is the number of rotated trace files kept
+	defaultTraceMaxFiles = 3
+
+	// defaultTraceDuration is how long tracing stays on unless MaxDuration is set
+	defaultTraceDuration = 10 * time.Minute
+)
+
+// traceCheckInterval is how often the trace file size and deadline are checked
+var traceCheckInterval = time.Second
+
+// TraceOptions configures driver manager tracing started with Conn.StartTrace
+type TraceOptions struct {
+	// Path is the trace file to write. Rotated files get .1, .2, ... suffixes.
+	Path string
+
+	// MaxSize is the file size in bytes at which the trace file is rotated (defaults to 64 MiB)
+	MaxSize int64
+
+	// MaxFiles is the number of rotated files to keep besides Path (defaults to 3)
+	MaxFiles int
+
+	// MaxDuration turns tracing off automatically after the given time, so a
+	// forgotten trace cannot fill disks in production (defaults to 10 minutes).
+	// A negative value disables the limit.
+	MaxDuration time.Duration
+}
+
+// withDefaults returns the options with zero values replaced by defaults
+func (o TraceOptions) withDefaults() TraceOptions {
+	if o.MaxSize <= 0 {
+		o.MaxSize = defaultTraceMaxSize
+	}
+	if o.MaxFiles <= 0 {
+		o.MaxFiles = defaultTraceMaxFiles
+	}
+	if o.MaxDuration == 0 {
+		o.MaxDuration = defaultTraceDuration
+	}
+	return o
+}
+
+// tracer is an active trace on a connection, monitored by a background goroutine
+type tracer struct {
+	opts TraceOptions
+	stop chan struct{}
+}
+
+// StartTrace enables the driver manager's protocol trace (SQL_ATTR_TRACE and
+// SQL_ATTR_TRACEFILE) for the connection, without editing odbcinst.ini.
+// The trace file is rotated when it exceeds MaxSize, and tracing is turned off
+// automatically after MaxDuration, when StopTrace is called, or when the
+// connection is closed. On Windows the driver manager traces process-wide.
+//
+// Example:
+//
+//	err := conn.Raw(func(dc interface{}) error {
+//	    return dc.(*godbc.Conn).StartTrace(godbc.TraceOptions{
+//	        Path:        "/tmp/odbc.trace",
+//	        MaxDuration: 2 * time.Minute,
+//	    })
+//	})
+func (c *Conn) StartTrace(opts TraceOptions) error {
+	if opts.Path == "" {
+		return fmt.Errorf("trace file path is required")
+	}
+	opts = opts.withDefaults()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return driver.ErrBadConn
+	}
+	if c.trace != nil {
+		return fmt.Errorf("trace is already running to %q", c.trace.opts.Path)
+	}
+	if err := c.enableTrace(opts.Path); err != nil {
+		return err
+	}
+
+	t := &tracer{opts: opts, stop: make(chan struct{})}
+	c.trace = t
+	go c.monitorTrace(t)
+	return nil
+}
+
+// StopTrace turns off tracing started with StartTrace. It is a no-op if no trace is running.
+func (c *Conn) StopTrace() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopTraceLocked()
+}
+
+// Tracing reports whether a trace started with StartTrace is running
+func (c *Conn) Tracing() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.trace != nil
+}
+
+// stopTraceLocked turns off the active trace. c.mu must be held.
+func (c *Conn) stopTraceLocked() error {
+	t := c.trace
+	if t == nil {
+		return nil
+	}
+	c.trace = nil
+	close(t.stop)
+	if c.closed || c.dbc == 0 {
+		return nil
+	}
+	if ret := SetConnectAttr(c.dbc, SQL_ATTR_TRACE, SQL_OPT_TRACE_OFF, 0); !IsSuccess(ret) {
+		return c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+	}
+	return nil
+}
+
+// enableTrace points the driver manager at path and turns tracing on. c.mu must be held.
+func (c *Conn) enableTrace(path string) error {
+	file := append([]byte(path), 0)
+	if ret := SetConnectAttr(c.dbc, SQL_ATTR_TRACEFILE, uintptr(unsafe.Pointer(&file[0])), SQL_NTS); !IsSuccess(ret) {
+		return c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+	}
+	if ret := SetConnectAttr(c.dbc, SQL_ATTR_TRACE, SQL_OPT_TRACE_ON, 0); !IsSuccess(ret) {
+		return c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+	}
+	return nil
+}
+
+// monitorTrace rotates the trace file when it grows past MaxSize and stops
+// tracing after MaxDuration, until the trace is stopped
+func (c *Conn) monitorTrace(t *tracer) {
+	ticker := time.NewTicker(traceCheckInterval)
+	defer ticker.Stop()
+	var deadline <-chan time.Time
+	if t.opts.MaxDuration > 0 {
+		timer := time.NewTimer(t.opts.MaxDuration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-deadline:
+			c.mu.Lock()
+			if c.trace == t {
+				c.stopTraceLocked()
+			}
+			c.mu.Unlock()
+			return
+		case <-ticker.C:
+			info, err := os.Stat(t.opts.Path)
+			if err != nil || info.Size() < t.opts.MaxSize {
+				continue
+			}
+			c.mu.Lock()
+			if c.trace != t {
+				c.mu.Unlock()
+				return
+			}
+			// Turn tracing off while files move so the driver manager reopens the new file
+			SetConnectAttr(c.dbc, SQL_ATTR_TRACE, SQL_OPT_TRACE_OFF, 0)
+			rotateTraceFiles(t.opts.Path, t.opts.MaxFiles)
+			if err := c.enableTrace(t.opts.Path); err != nil {
+				c.trace = nil
+				close(t.stop)
+				c.mu.Unlock()
+				return
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// rotateTraceFiles shifts path.1 ... path.(maxFiles-1) up by one, discarding
+// path.maxFiles, and moves path to path.1
+func rotateTraceFiles(path string, maxFiles int) error {
+	os.Remove(fmt.Sprintf("%s.%d", path, maxFiles))
+	for i := maxFiles - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", path, i)
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(path, path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package godbc
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Tracer starts spans around driver operations. It covers the part of an
+// OpenTelemetry trace.Tracer the driver needs, so godbc does not depend on
+// the OpenTelemetry module; see the README for an adapter.
+type Tracer interface {
+	// Start starts a span as a child of any span in ctx
+	Start(ctx context.Context, name string, attrs []Attribute) Span
+}
+
+// Span is a span started by a Tracer
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a span attribute. Value is a string, int64 or bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span names
+const (
+	SpanConnect = "connect"
+	SpanPrepare = "prepare"
+	SpanExec    = "exec"
+	SpanQuery   = "query"
+	SpanFetch   = "fetch"
+)
+
+// Span attribute keys, following the OpenTelemetry database semantic conventions
+const (
+	AttrDBSystemName   = "db.system.name"            // Well-known database system, e.g. "postgresql", or "other_sql"
+	AttrDBQueryText    = "db.query.text"             // SQL with literals replaced by ?
+	AttrDBOperation    = "db.operation.name"         // First keyword of the statement, e.g. "SELECT"
+	AttrDBStatusCode   = "db.response.status_code"   // SQLSTATE of a failed operation
+	AttrDBReturnedRows = "db.response.returned_rows" // Rows fetched from a result set
+	AttrErrorType      = "error.type"                // SQLSTATE, or the Go error type for other errors
+	AttrDBMSName       = "godbc.dbms.name"           // DBMS name reported by SQLGetInfo(SQL_DBMS_NAME)
+	AttrRowsAffected   = "godbc.rows_affected"       // Rows affected by a statement
+	AttrCorrelationID  = "godbc.correlation_id"      // Correlation ID from WithCorrelationID
+)
+
+// dbSystemNames maps DBMS names, as reported by SQL_DBMS_NAME, to well-known
+// db.system.name values
+var dbSystemNames = []struct {
+	dbms   string
+	system string
+}{
+	{"sql server", "microsoft.sql_server"},
+	{"postgres", "postgresql"},
+	{"mariadb", "mariadb"},
+	{"mysql", "mysql"},
+	{"oracle", "oracle.db"},
+	{"db2", "ibm.db2"},
+	{"sqlite", "sqlite"},
+}
+
+// dbSystemName returns the db.system.name value for a DBMS name
+func dbSystemName(dbType string) string {
+	dbTypeLower := strings.ToLower(dbType)
+	for _, n := range dbSystemNames {
+		if strings.Contains(dbTypeLower, n.dbms) {
+			return n.system
+		}
+	}
+	return "other_sql"
+}
+
+// startSpan starts a span for an operation on the connection, or returns nil
+// if tracing is disabled
+func (c *Conn) startSpan(ctx context.Context, name, query string) Span {
+	if c == nil || c.tracer == nil {
+		return nil
+	}
+	attrs := []Attribute{
+		{Key: AttrDBSystemName, Value: dbSystemName(c.dbType)},
+		{Key: AttrDBMSName, Value: c.dbType},
+	}
+	if query != "" {
+		attrs = append(attrs, Attribute{Key: AttrDBQueryText, Value: sanitizeSQL(query)})
+		if op := operationName(query); op != "" {
+			attrs = append(attrs, Attribute{Key: AttrDBOperation, Value: op})
+		}
+	}
+	if id := CorrelationID(ctx); id != "" {
+		attrs = append(attrs, Attribute{Key: AttrCorrelationID, Value: id})
+	}
+	return c.tracer.Start(ctx, name, attrs)
+}
+
+// endSpan records err, with its SQLSTATE, on a span and ends it. A nil span is ignored.
+func endSpan(span Span, err error) {
+	if span == nil {
+		return
+	}
+	recordSpanError(span, err)
+	span.End()
+}
+
+// recordSpanError records a failed operation on a span
+func recordSpanError(span Span, err error) {
+	if span == nil || err == nil {
+		return
+	}
+	span.RecordError(err)
+	if state := sqlStateOf(err); state != "" {
+		span.SetAttributes(Attribute{Key: AttrDBStatusCode, Value: state}, Attribute{Key: AttrErrorType, Value: state})
+		return
+	}
+	errType := "_OTHER"
+	switch {
+	case errors.Is(err, context.Canceled):
+		errType = "context.Canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		errType = "context.DeadlineExceeded"
+	}
+	span.SetAttributes(Attribute{Key: AttrErrorType, Value: errType})
+}
+
+// sqlStateOf returns the SQLSTATE of an ODBC error, or "" for other errors
+func sqlStateOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.SQLState
+	}
+	var es Errors
+	if errors.As(err, &es) && len(es) > 0 {
+		return es[0].SQLState
+	}
+	return ""
+}
+
+// sanitizeSQL replaces string and numeric literals in a statement with ?, so
+// span attributes do not carry the values of unparameterized queries. Quoted
+// identifiers are kept.
+func sanitizeSQL(query string) string {
+	var sb strings.Builder
+	sb.Grow(len(query))
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			i = skipQuoted(query, i)
+			sb.WriteByte('?')
+		case c == '"' || c == '`':
+			end := skipQuoted(query, i)
+			sb.WriteString(query[i : end+1])
+			i = end
+		case c >= '0' && c <= '9' && (i == 0 || !isIdentChar(query[i-1])):
+			for i+1 < len(query) && (isIdentChar(query[i+1]) || query[i+1] == '.') {
+				i++
+			}
+			sb.WriteByte('?')
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// operationName returns the first keyword of a statement in upper case, e.g.
+// SELECT, or CALL for procedure call escape sequences
+func operationName(query string) string {
+	q := strings.TrimLeft(query, " \t\r\n({?=")
+	end := 0
+	for end < len(q) && isIdentChar(q[end]) {
+		end++
+	}
+	return strings.ToUpper(q[:end])
+}
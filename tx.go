@@ -24,14 +24,14 @@ func (t *Tx) Commit() error {
 
 	// Check commit result first
 	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_DBC, SQLHANDLE(t.conn.dbc))
+		return t.conn.newError(SQL_HANDLE_DBC, SQLHANDLE(t.conn.dbc))
 	}
 
 	// Re-enable autocommit (commit succeeded, so this is best-effort)
 	SetConnectAttr(t.conn.dbc, SQL_ATTR_AUTOCOMMIT, uintptr(SQL_AUTOCOMMIT_ON), 0)
 
-	// Reset access mode to read-write (best-effort)
-	SetConnectAttr(t.conn.dbc, SQL_ATTR_ACCESS_MODE, SQL_MODE_READ_WRITE, 0)
+	// Restore the connection's access mode (best-effort)
+	SetConnectAttr(t.conn.dbc, SQL_ATTR_ACCESS_MODE, t.conn.accessMode, 0)
 
 	return nil
 }
@@ -51,14 +51,14 @@ func (t *Tx) Rollback() error {
 
 	// Check rollback result first
 	if !IsSuccess(ret) {
-		return NewError(SQL_HANDLE_DBC, SQLHANDLE(t.conn.dbc))
+		return t.conn.newError(SQL_HANDLE_DBC, SQLHANDLE(t.conn.dbc))
 	}
 
 	// Re-enable autocommit (rollback succeeded, so this is best-effort)
 	SetConnectAttr(t.conn.dbc, SQL_ATTR_AUTOCOMMIT, uintptr(SQL_AUTOCOMMIT_ON), 0)
 
-	// Reset access mode to read-write (best-effort)
-	SetConnectAttr(t.conn.dbc, SQL_ATTR_ACCESS_MODE, SQL_MODE_READ_WRITE, 0)
+	// Restore the connection's access mode (best-effort)
+	SetConnectAttr(t.conn.dbc, SQL_ATTR_ACCESS_MODE, t.conn.accessMode, 0)
 
 	return nil
 }
@@ -0,0 +1,131 @@
+package godbc
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TypeInfo describes a data type the data source supports, as reported by
+// SQLGetTypeInfo
+type TypeInfo struct {
+	Name     string      // Native type name to use in DDL, e.g. "nvarchar" or "NUMBER"
+	DataType SQLSMALLINT // SQL data type (SQL_VARCHAR, SQL_INTEGER, ...)
+
+	// ColumnSize is the maximum length or precision of the type, or 0 if it
+	// does not apply or is unknown
+	ColumnSize int64
+
+	// LiteralPrefix and LiteralSuffix quote literals of the type, e.g. "'" or
+	// "0x" (empty if literals need none)
+	LiteralPrefix string
+	LiteralSuffix string
+
+	// CreateParams are the parameters given with the type in DDL, in order,
+	// e.g. ["max length"] or ["precision", "scale"]
+	CreateParams []string
+
+	Nullable      SQLSMALLINT // SQL_NO_NULLS, SQL_NULLABLE or SQL_NULLABLE_UNKNOWN
+	CaseSensitive bool        // Whether comparisons of character values are case-sensitive
+
+	// Searchable is how the type can be used in a WHERE clause: SQL_PRED_NONE,
+	// SQL_PRED_CHAR (LIKE only), SQL_PRED_BASIC (all but LIKE) or SQL_SEARCHABLE
+	Searchable SQLSMALLINT
+
+	Unsigned       bool   // Whether a numeric type is unsigned
+	FixedPrecScale bool   // Whether the type has fixed precision and scale, like a money type
+	AutoUnique     bool   // Whether the type is auto-incrementing
+	LocalName      string // Localized type name, if the driver reports one
+
+	// MinScale and MaxScale bound the scale of numeric and time types
+	MinScale int64
+	MaxScale int64
+
+	// NumPrecRadix is 10 or 2 for numeric types whose ColumnSize counts digits
+	// or bits, and 0 for other types
+	NumPrecRadix int64
+}
+
+// TypeInfo returns the data types the data source supports for a SQL type,
+// or all of them for SQL_ALL_TYPES, using the SQLGetTypeInfo catalog
+// function. A SQL type may map to several native types, which drivers list
+// most closely matching first, e.g. SQL_WVARCHAR as "nvarchar" and "sysname".
+// Schema tools can use it to choose native types and their length limits
+// when generating DDL.
+//
+// Example:
+//
+//	types, err := c.TypeInfo(ctx, godbc.SQL_WVARCHAR)
+//	if err == nil && len(types) > 0 {
+//	    ddl := fmt.Sprintf("%s(%d)", types[0].Name, min(types[0].ColumnSize, 4000))
+//	}
+func (c *Conn) TypeInfo(ctx context.Context, sqlType SQLSMALLINT) ([]TypeInfo, error) {
+	rows, err := c.catalogRows(ctx, func(stmt SQLHSTMT) SQLRETURN {
+		return c.odbcTypeInfo(stmt, sqlType)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Result columns: TYPE_NAME, DATA_TYPE, COLUMN_SIZE, LITERAL_PREFIX,
+	// LITERAL_SUFFIX, CREATE_PARAMS, NULLABLE, CASE_SENSITIVE, SEARCHABLE,
+	// UNSIGNED_ATTRIBUTE, FIXED_PREC_SCALE, AUTO_UNIQUE_VALUE, LOCAL_TYPE_NAME,
+	// MINIMUM_SCALE, MAXIMUM_SCALE, SQL_DATA_TYPE, SQL_DATETIME_SUB,
+	// NUM_PREC_RADIX, INTERVAL_PRECISION
+	dest := make([]driver.Value, len(rows.Columns()))
+	if len(dest) < 15 {
+		return nil, fmt.Errorf("unexpected SQLGetTypeInfo result with %d columns", len(dest))
+	}
+	var types []TypeInfo
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				return types, nil
+			}
+			return nil, err
+		}
+		types = append(types, typeInfoFromRow(dest))
+	}
+}
+
+// typeInfoFromRow converts a row of an SQLGetTypeInfo result
+func typeInfoFromRow(dest []driver.Value) TypeInfo {
+	info := TypeInfo{
+		Name:           castToString(dest[0]),
+		DataType:       SQLSMALLINT(catalogInt64(dest[1])),
+		ColumnSize:     catalogInt64(dest[2]),
+		LiteralPrefix:  catalogString(dest[3]),
+		LiteralSuffix:  catalogString(dest[4]),
+		Nullable:       SQLSMALLINT(catalogInt64(dest[6])),
+		CaseSensitive:  isTruthy(dest[7]),
+		Searchable:     SQLSMALLINT(catalogInt64(dest[8])),
+		Unsigned:       isTruthy(dest[9]),
+		FixedPrecScale: isTruthy(dest[10]),
+		AutoUnique:     isTruthy(dest[11]),
+		LocalName:      catalogString(dest[12]),
+		MinScale:       catalogInt64(dest[13]),
+		MaxScale:       catalogInt64(dest[14]),
+	}
+	if params := catalogString(dest[5]); params != "" {
+		for _, p := range strings.Split(params, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				info.CreateParams = append(info.CreateParams, p)
+			}
+		}
+	}
+	if len(dest) > 17 {
+		info.NumPrecRadix = catalogInt64(dest[17])
+	}
+	return info
+}
+
+// catalogString reads a string catalog value, returning "" for NULL
+func catalogString(value driver.Value) string {
+	if value == nil {
+		return ""
+	}
+	return castToString(value)
+}
@@ -14,10 +14,11 @@ type SQLSMALLINT int16
 type SQLUSMALLINT uint16
 type SQLINTEGER int32
 type SQLUINTEGER uint32
-type SQLLEN int64   // 64-bit for portability across platforms
-type SQLULEN uint64 // 64-bit for portability across platforms
 type SQLRETURN SQLSMALLINT
 
+// SQLLEN and SQLULEN match the pointer width of the platform and are defined
+// in types_len64.go and types_len32.go
+
 // ODBC Character types
 type SQLCHAR byte
 type SQLWCHAR uint16 // UTF-16 on Windows
@@ -46,8 +47,9 @@ const SQL_NULL_HANDLE SQLHANDLE = 0
 
 // ODBC version constants
 const (
-	SQL_OV_ODBC2 = 2
-	SQL_OV_ODBC3 = 3
+	SQL_OV_ODBC2    = 2
+	SQL_OV_ODBC3    = 3
+	SQL_OV_ODBC3_80 = 380
 )
 
 // Environment attributes
@@ -60,11 +62,35 @@ const (
 
 // Connection attributes
 const (
-	SQL_ATTR_AUTOCOMMIT      SQLINTEGER = 102
-	SQL_ATTR_CONNECTION_DEAD SQLINTEGER = 1209
-	SQL_ATTR_LOGIN_TIMEOUT   SQLINTEGER = 103
-	SQL_ATTR_ACCESS_MODE     SQLINTEGER = 101
-	SQL_ATTR_TXN_ISOLATION   SQLINTEGER = 108
+	SQL_ATTR_AUTOCOMMIT         SQLINTEGER = 102
+	SQL_ATTR_CONNECTION_DEAD    SQLINTEGER = 1209
+	SQL_ATTR_LOGIN_TIMEOUT      SQLINTEGER = 103
+	SQL_ATTR_ACCESS_MODE        SQLINTEGER = 101
+	SQL_ATTR_TXN_ISOLATION      SQLINTEGER = 108
+	SQL_ATTR_CURRENT_CATALOG    SQLINTEGER = 109
+	SQL_ATTR_PACKET_SIZE        SQLINTEGER = 112
+	SQL_ATTR_CONNECTION_TIMEOUT SQLINTEGER = 113
+	SQL_ATTR_TRACE              SQLINTEGER = 104
+	SQL_ATTR_TRACEFILE          SQLINTEGER = 105
+	SQL_ATTR_AUTO_IPD           SQLINTEGER = 10001
+)
+
+// SQL_ATTR_CONNECTION_DEAD values
+const (
+	SQL_CD_FALSE = 0 // Connection is open and available
+	SQL_CD_TRUE  = 1 // Connection is closed or lost
+)
+
+// Trace values
+const (
+	SQL_OPT_TRACE_OFF = 0
+	SQL_OPT_TRACE_ON  = 1
+)
+
+// Boolean attribute values
+const (
+	SQL_FALSE = 0
+	SQL_TRUE  = 1
 )
 
 // Autocommit values
@@ -85,6 +111,9 @@ const (
 	SQL_TXN_READ_COMMITTED   = 2
 	SQL_TXN_REPEATABLE_READ  = 4
 	SQL_TXN_SERIALIZABLE     = 8
+
+	// SQL_TXN_SS_SNAPSHOT is SQL Server's driver-specific snapshot isolation level
+	SQL_TXN_SS_SNAPSHOT = 32
 )
 
 // Statement attributes
@@ -92,12 +121,19 @@ const (
 	SQL_ATTR_CURSOR_TYPE        SQLINTEGER = 6
 	SQL_ATTR_CONCURRENCY        SQLINTEGER = 7
 	SQL_ATTR_ROW_ARRAY_SIZE     SQLINTEGER = 27
+	SQL_ATTR_ROW_BIND_TYPE      SQLINTEGER = 5
 	SQL_ATTR_ROW_STATUS_PTR     SQLINTEGER = 25
 	SQL_ATTR_ROWS_FETCHED       SQLINTEGER = 26
+	SQL_ATTR_ROW_NUMBER         SQLINTEGER = 14
 	SQL_ATTR_QUERY_TIMEOUT      SQLINTEGER = 0
 	SQL_ATTR_MAX_ROWS           SQLINTEGER = 1
+	SQL_ATTR_NOSCAN             SQLINTEGER = 2
+	SQL_ATTR_MAX_LENGTH         SQLINTEGER = 3
 	SQL_ATTR_CURSOR_SCROLLABLE  SQLINTEGER = -1
 	SQL_ATTR_CURSOR_SENSITIVITY SQLINTEGER = -2
+	SQL_ATTR_APP_ROW_DESC       SQLINTEGER = 10010
+	SQL_ATTR_IMP_PARAM_DESC     SQLINTEGER = 10013
+	SQL_ATTR_ENABLE_AUTO_IPD    SQLINTEGER = 15
 )
 
 // Cursor types
@@ -111,10 +147,17 @@ const (
 // String terminator
 const SQL_NTS SQLINTEGER = -3
 
+// Diagnostic header fields
+const SQL_DIAG_NUMBER SQLSMALLINT = 2
+
 // Null data indicators
 const (
 	SQL_NULL_DATA    SQLLEN = -1
 	SQL_DATA_AT_EXEC SQLLEN = -2
+	SQL_NO_TOTAL     SQLLEN = -4 // Length unknown: more data remains
+
+	// SQL_LEN_DATA_AT_EXEC_OFFSET is the base of SQL_LEN_DATA_AT_EXEC(length)
+	SQL_LEN_DATA_AT_EXEC_OFFSET SQLLEN = -100
 )
 
 // SQLDriverConnect options
@@ -155,6 +198,13 @@ const (
 	SQL_GUID           SQLSMALLINT = -11
 )
 
+// ODBC 4.0 time zone aware types, reported by drivers for columns such as
+// TIMESTAMP WITH TIME ZONE
+const (
+	SQL_TYPE_TIME_WITH_TIMEZONE      SQLSMALLINT = 94
+	SQL_TYPE_TIMESTAMP_WITH_TIMEZONE SQLSMALLINT = 95
+)
+
 // C data type identifiers for binding
 const (
 	SQL_SIGNED_OFFSET   SQLSMALLINT = -20
@@ -169,6 +219,7 @@ const (
 	SQL_C_DOUBLE    = SQL_DOUBLE
 	SQL_C_NUMERIC   = SQL_NUMERIC
 	SQL_C_DEFAULT   = 99
+	SQL_ARD_TYPE    = -99 // SQLGetData target type taken from the application row descriptor
 	SQL_C_DATE      = SQL_TYPE_DATE
 	SQL_C_TIME      = SQL_TYPE_TIME
 	SQL_C_TIMESTAMP = SQL_TYPE_TIMESTAMP
@@ -203,6 +254,19 @@ const (
 	SQL_FETCH_RELATIVE SQLSMALLINT = 6
 )
 
+// Row binding and row status values for block fetches
+const (
+	SQL_BIND_BY_COLUMN = 0
+
+	SQL_ROW_SUCCESS           SQLUSMALLINT = 0
+	SQL_ROW_DELETED           SQLUSMALLINT = 1
+	SQL_ROW_UPDATED           SQLUSMALLINT = 2
+	SQL_ROW_NOROW             SQLUSMALLINT = 3
+	SQL_ROW_ADDED             SQLUSMALLINT = 4
+	SQL_ROW_ERROR             SQLUSMALLINT = 5
+	SQL_ROW_SUCCESS_WITH_INFO SQLUSMALLINT = 6
+)
+
 // Free statement options
 const (
 	SQL_CLOSE        SQLUSMALLINT = 0
@@ -224,6 +288,39 @@ const (
 	SQL_NULLABLE_UNKNOWN SQLSMALLINT = 2
 )
 
+// SQLStatistics options and index types
+const (
+	SQL_INDEX_UNIQUE SQLUSMALLINT = 0
+	SQL_INDEX_ALL    SQLUSMALLINT = 1
+
+	SQL_QUICK  SQLUSMALLINT = 0
+	SQL_ENSURE SQLUSMALLINT = 1
+
+	SQL_TABLE_STAT      SQLSMALLINT = 0
+	SQL_INDEX_CLUSTERED SQLSMALLINT = 1
+	SQL_INDEX_HASHED    SQLSMALLINT = 2
+	SQL_INDEX_OTHER     SQLSMALLINT = 3
+)
+
+// SQLGetTypeInfo data type selector and SEARCHABLE values
+const (
+	SQL_ALL_TYPES SQLSMALLINT = 0
+
+	SQL_PRED_NONE  SQLSMALLINT = 0 // Not usable in a WHERE clause
+	SQL_PRED_CHAR  SQLSMALLINT = 1 // Only with LIKE
+	SQL_PRED_BASIC SQLSMALLINT = 2 // With all comparison operators except LIKE
+	SQL_SEARCHABLE SQLSMALLINT = 3 // With any comparison operator
+)
+
+// SQLForeignKeys update and delete rules
+const (
+	SQL_CASCADE     SQLSMALLINT = 0
+	SQL_RESTRICT    SQLSMALLINT = 1
+	SQL_SET_NULL    SQLSMALLINT = 2
+	SQL_NO_ACTION   SQLSMALLINT = 3
+	SQL_SET_DEFAULT SQLSMALLINT = 4
+)
+
 // Column attribute identifiers
 const (
 	SQL_DESC_COUNT                  SQLUSMALLINT = 1001
@@ -259,15 +356,57 @@ const (
 
 // SQLGetInfo information types
 const (
-	SQL_DRIVER_NAME           SQLUSMALLINT = 6
-	SQL_DRIVER_VER            SQLUSMALLINT = 7
-	SQL_DBMS_NAME             SQLUSMALLINT = 17
-	SQL_DBMS_VER              SQLUSMALLINT = 18
-	SQL_DATABASE_NAME         SQLUSMALLINT = 16
-	SQL_SERVER_NAME           SQLUSMALLINT = 13
-	SQL_USER_NAME             SQLUSMALLINT = 47
-	SQL_IDENTIFIER_QUOTE_CHAR SQLUSMALLINT = 29
-	SQL_MAX_IDENTIFIER_LEN    SQLUSMALLINT = 10005
+	SQL_DRIVER_NAME            SQLUSMALLINT = 6
+	SQL_DRIVER_VER             SQLUSMALLINT = 7
+	SQL_DRIVER_ODBC_VER        SQLUSMALLINT = 77
+	SQL_DBMS_NAME              SQLUSMALLINT = 17
+	SQL_DBMS_VER               SQLUSMALLINT = 18
+	SQL_DATABASE_NAME          SQLUSMALLINT = 16
+	SQL_SERVER_NAME            SQLUSMALLINT = 13
+	SQL_USER_NAME              SQLUSMALLINT = 47
+	SQL_IDENTIFIER_CASE        SQLUSMALLINT = 28
+	SQL_IDENTIFIER_QUOTE_CHAR  SQLUSMALLINT = 29
+	SQL_MAX_IDENTIFIER_LEN     SQLUSMALLINT = 10005
+	SQL_MAX_COLUMN_NAME_LEN    SQLUSMALLINT = 30
+	SQL_MAX_SCHEMA_NAME_LEN    SQLUSMALLINT = 32
+	SQL_MAX_CATALOG_NAME_LEN   SQLUSMALLINT = 34
+	SQL_MAX_TABLE_NAME_LEN     SQLUSMALLINT = 35
+	SQL_MAX_COLUMNS_IN_SELECT  SQLUSMALLINT = 100
+	SQL_MAX_STATEMENT_LEN      SQLUSMALLINT = 105
+	SQL_SEARCH_PATTERN_ESCAPE  SQLUSMALLINT = 14
+	SQL_CATALOG_NAME_SEPARATOR SQLUSMALLINT = 41
+	SQL_KEYWORDS               SQLUSMALLINT = 89
+	SQL_SPECIAL_CHARACTERS     SQLUSMALLINT = 94
+	SQL_DEFAULT_TXN_ISOLATION  SQLUSMALLINT = 26
+	SQL_TXN_ISOLATION_OPTION   SQLUSMALLINT = 72
+
+	SQL_MAX_CONCURRENT_ACTIVITIES SQLUSMALLINT = 1
+	SQL_GETDATA_EXTENSIONS        SQLUSMALLINT = 81
+	SQL_STATIC_CURSOR_ATTRIBUTES1 SQLUSMALLINT = 167
+)
+
+// SQL_GETDATA_EXTENSIONS bits
+const (
+	SQL_GD_ANY_COLUMN    = 0x00000001 // SQLGetData can be called for any unbound column, not only those after the last bound one
+	SQL_GD_ANY_ORDER     = 0x00000002 // SQLGetData can be called for unbound columns in any order
+	SQL_GD_BLOCK         = 0x00000004 // SQLGetData can be called for a row of a block cursor
+	SQL_GD_BOUND         = 0x00000008 // SQLGetData can be called for bound columns
+	SQL_GD_OUTPUT_PARAMS = 0x00000010 // SQLGetData can be called for streamed output parameters
+)
+
+// SQL_STATIC_CURSOR_ATTRIBUTES1 bits
+const (
+	SQL_CA1_NEXT     = 0x00000001
+	SQL_CA1_ABSOLUTE = 0x00000002
+	SQL_CA1_RELATIVE = 0x00000004
+)
+
+// SQL_IDENTIFIER_CASE values
+const (
+	SQL_IC_UPPER     SQLUSMALLINT = 1
+	SQL_IC_LOWER     SQLUSMALLINT = 2
+	SQL_IC_SENSITIVE SQLUSMALLINT = 3
+	SQL_IC_MIXED     SQLUSMALLINT = 4
 )
 
 // Timestamp struct for date/time binding
@@ -756,6 +895,22 @@ func NewInputOutputParamWithSize(value interface{}, size int) OutputParam {
 	}
 }
 
+// ReturnValue receives the return value of a stored procedure called with the
+// ODBC escape sequence {? = CALL proc(...)}, bound as an output parameter to
+// the first marker. Passing it as the first argument is optional; it sets the
+// type of the return value. Value is a type hint as for OutputParam, and
+// defaults to int32, the type of a SQL Server return status.
+//
+// Example:
+//
+//	res, err := db.Exec("{? = CALL dbo.archive_orders(?)}", godbc.ReturnValue{Value: int32(0)}, cutoff)
+//	if r, ok := res.(*godbc.Result); ok {
+//	    status := r.ReturnValue()
+//	}
+type ReturnValue struct {
+	Value interface{}
+}
+
 // =============================================================================
 // Batch Operations Support
 // =============================================================================
@@ -792,6 +947,17 @@ type BatchResult struct {
 
 	// Errors contains any error that occurred for each parameter set (nil if success)
 	Errors []error
+
+	// StatusCodes contains the SQL_PARAM_* status of each parameter set. With
+	// array binding these are the values the driver wrote to
+	// SQL_ATTR_PARAM_STATUS_PTR; the row-by-row and multi-row INSERT fallbacks
+	// report the equivalent codes. Parameter sets that were never sent are
+	// SQL_PARAM_UNUSED, so a failed batch can be resumed from the first of them.
+	StatusCodes []uint16
+
+	// RowsProcessed is the number of parameter sets the driver processed,
+	// including those that failed (SQL_ATTR_PARAMS_PROCESSED_PTR with array binding)
+	RowsProcessed uint64
 }
 
 // HasErrors returns true if any parameter set resulted in an error
@@ -804,6 +970,19 @@ func (r *BatchResult) HasErrors() bool {
 	return false
 }
 
+// Unprocessed returns the indexes of the parameter sets that were never sent to
+// the database (SQL_PARAM_UNUSED), which are the ones to resubmit after a
+// batch stopped early. Failed parameter sets are reported in Errors instead.
+func (r *BatchResult) Unprocessed() []int {
+	var indexes []int
+	for i, code := range r.StatusCodes {
+		if code == SQL_PARAM_UNUSED {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
 // =============================================================================
 // Scrollable Cursor Support
 // =============================================================================
@@ -846,3 +1025,170 @@ const (
 	// LastInsertIdReturning expects the query to use a RETURNING clause (PostgreSQL style)
 	LastInsertIdReturning
 )
+
+// TimestampFetchMode specifies how TIMESTAMP columns are returned from Rows.Next
+type TimestampFetchMode int
+
+const (
+	// TimestampFetchTime returns timestamps as time.Time in UTC (the default)
+	TimestampFetchTime TimestampFetchMode = iota
+
+	// TimestampFetchEpochNanos returns timestamps as int64 nanoseconds since the
+	// Unix epoch (UTC), avoiding time.Time construction on large scans.
+	// Values outside the int64 range (before 1677 or after 2262) are clamped.
+	TimestampFetchEpochNanos
+)
+
+// OutOfRangeTimeMode specifies how DATE and TIMESTAMP values that cannot be
+// represented as a time.Time between MinTime and MaxTime are returned
+type OutOfRangeTimeMode int
+
+const (
+	// OutOfRangeTimeValue returns such values as OutOfRangeTime (the default)
+	OutOfRangeTimeValue OutOfRangeTimeMode = iota
+
+	// OutOfRangeTimeString returns such values as strings formatted by OutOfRangeTime.String
+	OutOfRangeTimeString
+
+	// OutOfRangeTimeClamp maps values before year 1 to MinTime and values after
+	// year 9999 to MaxTime, as is common for '-infinity' and 'infinity'.
+	// Values with invalid fields are still returned as OutOfRangeTime.
+	OutOfRangeTimeClamp
+)
+
+// GUIDFetchMode specifies how GUID/UNIQUEIDENTIFIER columns are returned from Rows.Next
+type GUIDFetchMode int
+
+const (
+	// GUIDFetchString returns GUIDs as formatted strings such as
+	// "6F9619FF-8B86-D011-B42D-00C04FC964FF" (the default)
+	GUIDFetchString GUIDFetchMode = iota
+
+	// GUIDFetchBinary returns GUIDs as 16-byte []byte values in the byte
+	// layout ParseGUID produces, so values can be stored as binary, or scanned
+	// into a godbc.GUID and passed back as parameters without re-parsing
+	GUIDFetchBinary
+)
+
+// DecimalFetchMode specifies how DECIMAL/NUMERIC columns are returned from Rows.Next
+type DecimalFetchMode int
+
+const (
+	// DecimalFetchString returns decimals as the text the driver produces,
+	// whose formatting varies between drivers (".01" or "0.01") (the default)
+	DecimalFetchString DecimalFetchMode = iota
+
+	// DecimalFetchNumeric fetches decimals as SQL_C_NUMERIC and returns them
+	// as godbc.Decimal with the column's precision and scale, formatted the
+	// same way for every driver
+	DecimalFetchNumeric
+)
+
+// TimeBindMode specifies how time.Time, Timestamp and TimestampTZ parameters are bound
+type TimeBindMode int
+
+const (
+	// TimeBindAuto binds time parameters as SQL_C_TIMESTAMP, except on
+	// databases whose drivers are known to reject it (Microsoft Access and
+	// Informix), where they are bound as datetime strings (the default)
+	TimeBindAuto TimeBindMode = iota
+
+	// TimeBindTimestamp always binds time parameters as SQL_C_TIMESTAMP
+	TimeBindTimestamp
+
+	// TimeBindString always binds time parameters as strings formatted with
+	// the configured layout
+	TimeBindString
+)
+
+// UnicodeMode specifies whether connection strings, SQL text, catalog
+// function arguments, cursor names, column names and attributes, driver
+// information and diagnostic messages are exchanged through the ANSI or the
+// wide-character (W) ODBC entry points
+type UnicodeMode int
+
+const (
+	// UnicodeAuto uses the W entry points for connection strings, SQL text,
+	// catalog arguments and cursor names that are not plain ASCII, and
+	// describes columns again with SQLDescribeColW when the ANSI name is not
+	// plain ASCII (the default). ASCII text takes the ANSI path, exactly as
+	// with UnicodeANSI. On Windows, where the ANSI entry points are limited to
+	// the system code page, it uses the W entry points for all text, as
+	// UnicodeWide does; Drivers and DataSources also use SQLDriversW and
+	// SQLDataSourcesW there.
+	UnicodeAuto UnicodeMode = iota
+
+	// UnicodeANSI always uses the ANSI entry points, leaving character set
+	// conversion to the driver manager and driver
+	UnicodeANSI
+
+	// UnicodeWide always uses the W entry points. Connecting fails if the
+	// ODBC library does not export them.
+	UnicodeWide
+)
+
+// InvalidTextMode specifies how string parameters that are not valid UTF-8
+// are bound, such as text holding unpaired UTF-16 surrogate halves decoded
+// leniently from another system, which cannot be converted to UTF-16 as is
+type InvalidTextMode int
+
+const (
+	// InvalidTextReplace binds each invalid byte sequence as U+FFFD, the
+	// Unicode replacement character (the default)
+	InvalidTextReplace InvalidTextMode = iota
+
+	// InvalidTextReject fails the execution with an *InvalidTextError naming
+	// the parameter, before anything is sent to the database
+	InvalidTextReject
+)
+
+// TruncationPolicy specifies what happens to character and binary values
+// larger than the connection's MaxColumnBytes
+type TruncationPolicy int
+
+const (
+	// TruncationError fails the fetch with a *ColumnTooLargeError naming the
+	// column (the default)
+	TruncationError TruncationPolicy = iota
+
+	// TruncationTruncate returns the first MaxColumnBytes bytes of the value,
+	// cut at a character boundary for character data
+	TruncationTruncate
+
+	// TruncationStream returns the value as a *LOBReader when the column is
+	// the last of the row, so it can be read in chunks before the next row;
+	// values of other columns fail as with TruncationError
+	TruncationStream
+)
+
+// CopyMode specifies who owns the []byte values returned by Rows, such as
+// binary and raw columns
+type CopyMode int
+
+const (
+	// CopyAlways returns []byte values the caller owns: each value is a copy
+	// the driver does not read or write again, so it can be kept and modified,
+	// including when the same row is read again (the default)
+	CopyAlways CopyMode = iota
+
+	// CopyBorrowUntilNext returns []byte values that may share the driver's
+	// buffers, such as the bound arrays of a block fetch or a buffer reused
+	// from row to row, saving a copy per value. A value is valid until the next
+	// call to Next, NextBatch, NextResultSet or Close and must not be modified;
+	// copy it to keep it. database/sql copies []byte values scanned into
+	// *[]byte or *any, so only sql.RawBytes sees the borrowed memory.
+	CopyBorrowUntilNext
+)
+
+// UnknownColumnSizeBehavior specifies how ColumnTypeLength reports variable-length
+// columns whose size the driver could not determine (e.g. VARCHAR(MAX), TEXT)
+type UnknownColumnSizeBehavior int
+
+const (
+	// UnknownColumnSizeUnbounded reports math.MaxInt64 with ok=true, the
+	// database/sql convention for variable-length types without a limit
+	UnknownColumnSizeUnbounded UnknownColumnSizeBehavior = iota
+
+	// UnknownColumnSizeNotOK reports 0 with ok=false
+	UnknownColumnSizeNotOK
+)
@@ -0,0 +1,9 @@
+//go:build 386 || arm || mips || mipsle || godbc_sqllen32
+
+package godbc
+
+// SQLLEN and SQLULEN are 32-bit on 32-bit platforms. The godbc_sqllen32 build tag
+// selects them on 64-bit platforms for driver managers built with 32-bit lengths
+// (such as unixODBC built with BUILD_LEGACY_64_BIT_MODE).
+type SQLLEN int32
+type SQLULEN uint32
@@ -0,0 +1,7 @@
+//go:build !(386 || arm || mips || mipsle || godbc_sqllen32)
+
+package godbc
+
+// SQLLEN and SQLULEN are 64-bit on 64-bit platforms (the ODBC 3.52 64-bit ABI)
+type SQLLEN int64
+type SQLULEN uint64
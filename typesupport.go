@@ -0,0 +1,135 @@
+package godbc
+
+import (
+	"strings"
+)
+
+// TypeFidelity describes how faithfully values of a SQL type survive a round
+// trip through the driver: bound as a parameter, stored and fetched back
+type TypeFidelity int
+
+const (
+	// TypeFidelityUnknown means the type is not covered by the support table
+	TypeFidelityUnknown TypeFidelity = iota
+	// TypeExact means values round-trip unchanged
+	TypeExact
+	// TypeLossy means values round-trip with reduced precision or range, or
+	// come back as a different Go type
+	TypeLossy
+	// TypeUnsupported means values cannot be transferred natively; use a
+	// string representation instead
+	TypeUnsupported
+)
+
+// String returns the name of the fidelity level
+func (f TypeFidelity) String() string {
+	switch f {
+	case TypeExact:
+		return "exact"
+	case TypeLossy:
+		return "lossy"
+	case TypeUnsupported:
+		return "unsupported"
+	}
+	return "unknown"
+}
+
+// TypeSupportInfo describes the known round-trip fidelity of a SQL type
+type TypeSupportInfo struct {
+	Fidelity TypeFidelity
+	Note     string // What is lost, or why the type is unsupported; empty for exact types
+}
+
+// typeSupportEntry is a row of the type support table. An empty dialect
+// applies to every database without a row of its own.
+type typeSupportEntry struct {
+	dialect  string
+	sqlType  SQLSMALLINT
+	fidelity TypeFidelity
+	note     string
+}
+
+// typeSupport is the table of known round-trip behavior, dialect rows first
+var typeSupport = []typeSupportEntry{
+	// SQL Server
+	{"sql server", SQL_TYPE_TIMESTAMP, TypeLossy, "DATETIME rounds to 1/300 second and SMALLDATETIME to the minute; DATETIME2 keeps 100ns"},
+	{"sql server", SQL_SS_TIME2, TypeExact, ""},
+	{"sql server", SQL_SS_TIMESTAMPOFFSET, TypeLossy, "returned as time.Time with the stored offset; time.Time parameters are bound without a time zone"},
+	{"sql server", SQL_SS_VARIANT, TypeLossy, "returned as text"},
+	{"sql server", SQL_SS_XML, TypeExact, ""},
+	{"sql server", SQL_SS_UDT, TypeUnsupported, "CLR types such as geometry and hierarchyid are returned as their binary serialization"},
+
+	// PostgreSQL
+	{"postgres", SQL_TYPE_TIMESTAMP, TypeLossy, "microsecond precision; the time zone is not stored for timestamp without time zone"},
+
+	// MySQL and MariaDB
+	{"mysql", SQL_TYPE_TIMESTAMP, TypeLossy, "fractional seconds are dropped unless the column declares a precision; TIMESTAMP converts through the session time zone"},
+	{"mysql", SQL_TYPE_TIME, TypeLossy, "fractional seconds are dropped and values outside 00:00:00-23:59:59 cannot be represented"},
+	{"mariadb", SQL_TYPE_TIMESTAMP, TypeLossy, "fractional seconds are dropped unless the column declares a precision; TIMESTAMP converts through the session time zone"},
+	{"mariadb", SQL_TYPE_TIME, TypeLossy, "fractional seconds are dropped and values outside 00:00:00-23:59:59 cannot be represented"},
+
+	// Oracle
+	{"oracle", SQL_CHAR, TypeLossy, "empty strings are stored as NULL"},
+	{"oracle", SQL_VARCHAR, TypeLossy, "empty strings are stored as NULL"},
+	{"oracle", SQL_WCHAR, TypeLossy, "empty strings are stored as NULL"},
+	{"oracle", SQL_WVARCHAR, TypeLossy, "empty strings are stored as NULL"},
+	{"oracle", SQL_BIT, TypeUnsupported, "no boolean column type; store NUMBER(1) or CHAR(1) and map it with WithBoolRules"},
+	{"oracle", SQL_GUID, TypeUnsupported, "no UUID type; store RAW(16) or text"},
+
+	// SQLite
+	{"sqlite", SQL_NUMERIC, TypeLossy, "stored as REAL or INTEGER; digits beyond double precision are rounded"},
+	{"sqlite", SQL_DECIMAL, TypeLossy, "stored as REAL or INTEGER; digits beyond double precision are rounded"},
+	{"sqlite", SQL_TYPE_TIMESTAMP, TypeLossy, "stored as text; precision depends on the format written"},
+	{"sqlite", SQL_GUID, TypeUnsupported, "no UUID type; store text or a blob"},
+
+	// DB2
+	{"db2", SQL_DB2_DECFLOAT, TypeLossy, "returned as text"},
+	{"db2", SQL_DB2_XML, TypeExact, ""},
+
+	// Every database
+	{"", SQL_BIT, TypeExact, ""},
+	{"", SQL_BOOLEAN, TypeExact, ""},
+	{"", SQL_TINYINT, TypeExact, ""},
+	{"", SQL_SMALLINT, TypeExact, ""},
+	{"", SQL_INTEGER, TypeExact, ""},
+	{"", SQL_BIGINT, TypeExact, ""},
+	{"", SQL_REAL, TypeLossy, "float64 parameters are rounded to single precision"},
+	{"", SQL_FLOAT, TypeExact, ""},
+	{"", SQL_DOUBLE, TypeExact, ""},
+	{"", SQL_NUMERIC, TypeExact, ""},
+	{"", SQL_DECIMAL, TypeExact, ""},
+	{"", SQL_CHAR, TypeExact, ""},
+	{"", SQL_VARCHAR, TypeExact, ""},
+	{"", SQL_LONGVARCHAR, TypeExact, ""},
+	{"", SQL_WCHAR, TypeExact, ""},
+	{"", SQL_WVARCHAR, TypeExact, ""},
+	{"", SQL_WLONGVARCHAR, TypeExact, ""},
+	{"", SQL_BINARY, TypeExact, ""},
+	{"", SQL_VARBINARY, TypeExact, ""},
+	{"", SQL_LONGVARBINARY, TypeExact, ""},
+	{"", SQL_TYPE_DATE, TypeExact, ""},
+	{"", SQL_TYPE_TIME, TypeLossy, "fractional seconds are dropped"},
+	{"", SQL_TYPE_TIMESTAMP, TypeLossy, "time.Time parameters are bound with the connector's timestamp precision (milliseconds by default) and without a time zone"},
+	{"", SQL_TYPE_TIME_WITH_TIMEZONE, TypeLossy, "parsed from the driver's text form; time.Time parameters are bound without a time zone"},
+	{"", SQL_TYPE_TIMESTAMP_WITH_TIMEZONE, TypeLossy, "parsed from the driver's text form; time.Time parameters are bound without a time zone"},
+	{"", SQL_GUID, TypeExact, ""},
+}
+
+// TypeSupport returns the known round-trip fidelity of a SQL type on a
+// database, so pipelines can choose between native typed transfer and a
+// string fallback. dialect is matched case-insensitively against the DBMS
+// name, as reported by Capabilities.DBMSName (e.g. "Microsoft SQL Server"),
+// or a short name such as "postgres", "mysql" or "oracle". Types the table
+// does not cover report TypeFidelityUnknown.
+func TypeSupport(dialect string, sqlType SQLSMALLINT) TypeSupportInfo {
+	dialectLower := strings.ToLower(dialect)
+	for _, e := range typeSupport {
+		if e.sqlType != sqlType {
+			continue
+		}
+		if e.dialect == "" || (dialectLower != "" && strings.Contains(dialectLower, e.dialect)) {
+			return TypeSupportInfo{Fidelity: e.fidelity, Note: e.note}
+		}
+	}
+	return TypeSupportInfo{}
+}
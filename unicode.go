@@ -0,0 +1,270 @@
+package godbc
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+)
+
+// wideByDefault makes UnicodeAuto use the W entry points for all text, as on
+// Windows, where the ANSI functions are limited to the system code page and
+// the driver manager converts W calls for ANSI drivers itself
+var wideByDefault = runtime.GOOS == "windows"
+
+// useWideAPI reports whether text is passed through the W entry points in the given mode
+func useWideAPI(mode UnicodeMode, text string) bool {
+	switch mode {
+	case UnicodeWide:
+		return wideAPI
+	case UnicodeANSI:
+		return false
+	default:
+		return wideAPI && (wideByDefault || asciiPrefixLen(text) != len(text))
+	}
+}
+
+// wideOutput reports whether text returned by the driver, such as column
+// names and messages, is read through the W entry points in the given mode:
+// always in UnicodeWide mode, and in UnicodeAuto mode on Windows
+func wideOutput(mode UnicodeMode) bool {
+	return wideAPI && (mode == UnicodeWide || mode == UnicodeAuto && wideByDefault)
+}
+
+// unicodeMode returns the connection's Unicode mode, or UnicodeAuto for a nil
+// connection
+func (c *Conn) unicodeMode() UnicodeMode {
+	if c == nil {
+		return UnicodeAuto
+	}
+	return c.unicode
+}
+
+// driverConnect connects with SQLDriverConnect or SQLDriverConnectW according
+// to the Unicode mode, returning the completed connection string
+func driverConnect(dbc SQLHDBC, mode UnicodeMode, connStr string) (string, SQLRETURN) {
+	if useWideAPI(mode, connStr) {
+		return DriverConnectW(dbc, 0, connStr, connStringOutSize, SQL_DRIVER_NOPROMPT)
+	}
+	outConnStr := make([]byte, connStringOutSize)
+	outLen, ret := DriverConnect(dbc, 0, connStr, outConnStr, SQL_DRIVER_NOPROMPT)
+	return connStringFromBuffer(outConnStr, outLen), ret
+}
+
+// execDirect executes a statement with SQLExecDirect or SQLExecDirectW
+// according to the connection's Unicode mode
+func (c *Conn) execDirect(stmt SQLHSTMT, query string) SQLRETURN {
+	if useWideAPI(c.unicode, query) {
+		return ExecDirectW(stmt, query)
+	}
+	return ExecDirect(stmt, query)
+}
+
+// prepare prepares a statement with SQLPrepare or SQLPrepareW according to
+// the connection's Unicode mode
+func (c *Conn) prepare(stmt SQLHSTMT, query string) SQLRETURN {
+	if useWideAPI(c.unicode, query) {
+		return PrepareW(stmt, query)
+	}
+	return Prepare(stmt, query)
+}
+
+// describeCol describes a result column according to the connection's Unicode
+// mode. In UnicodeAuto mode on Windows names are read with SQLDescribeColW;
+// elsewhere a name that the ANSI call returned with non-ASCII
+// bytes or '?' replacement characters is read again with SQLDescribeColW.
+// colName is the buffer for ANSI names; its length also bounds wide names.
+func (c *Conn) describeCol(stmt SQLHSTMT, colNum SQLUSMALLINT, colName []byte) (name string, dataType SQLSMALLINT, colSize SQLULEN, decDigits SQLSMALLINT, nullable SQLSMALLINT, ret SQLRETURN) {
+	mode := c.unicodeMode()
+	if wideOutput(mode) {
+		return DescribeColW(stmt, colNum, len(colName))
+	}
+
+	var nameLen SQLSMALLINT
+	nameLen, dataType, colSize, decDigits, nullable, ret = DescribeCol(stmt, colNum, colName)
+	if !IsSuccess(ret) {
+		return
+	}
+	name = connStringFromBuffer(colName, nameLen)
+	if mode == UnicodeAuto && wideAPI && needsWideName(name) {
+		if wide, _, _, _, _, wret := DescribeColW(stmt, colNum, len(colName)); IsSuccess(wret) {
+			name = wide
+		}
+	}
+	return
+}
+
+// getInfoString reads a string SQLGetInfo value with SQLGetInfo or
+// SQLGetInfoW according to the connection's Unicode mode
+func (c *Conn) getInfoString(infoType SQLUSMALLINT) (string, bool) {
+	getInfo, unit := GetInfo, 1
+	if wideOutput(c.unicodeMode()) {
+		getInfo, unit = GetInfoW, wcharSize()
+	}
+	buf := make([]byte, 256*unit)
+	strLen, ret := getInfo(c.dbc, infoType, buf)
+	if IsSuccess(ret) && int(strLen)+unit > len(buf) {
+		// Long values such as SQL_KEYWORDS are read again in full
+		buf = make([]byte, int(strLen)+unit)
+		strLen, ret = getInfo(c.dbc, infoType, buf)
+	}
+	if !IsSuccess(ret) {
+		return "", false
+	}
+	end := min(int(strLen), len(buf))
+	if unit > 1 {
+		return wideBufferString(buf, end/unit), true
+	}
+	return strings.TrimRight(string(buf[:end]), "\x00"), true
+}
+
+// colAttributeString reads a string column attribute such as
+// SQL_DESC_TYPE_NAME with SQLColAttribute or SQLColAttributeW according to
+// the connection's Unicode mode. buf is the buffer for ANSI values; its
+// length also bounds wide values, in characters.
+func (c *Conn) colAttributeString(stmt SQLHSTMT, colNum, fieldId SQLUSMALLINT, buf []byte) (string, SQLRETURN) {
+	if wideOutput(c.unicodeMode()) {
+		wide := make([]byte, len(buf)*wcharSize())
+		strLen, _, ret := ColAttributeW(stmt, colNum, fieldId, wide)
+		if !IsSuccess(ret) || strLen <= 0 {
+			return "", ret
+		}
+		return wideBufferString(wide, int(strLen)/wcharSize()), ret
+	}
+	strLen, _, ret := ColAttribute(stmt, colNum, fieldId, buf)
+	if !IsSuccess(ret) || strLen <= 0 {
+		return "", ret
+	}
+	return string(buf[:min(int(strLen), len(buf))]), ret
+}
+
+// wideCatalog reports whether the arguments of a catalog function are passed
+// through its W entry point in the connection's Unicode mode
+func (c *Conn) wideCatalog(args ...string) bool {
+	for _, s := range args {
+		if useWideAPI(c.unicodeMode(), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// odbcTables calls SQLTables or SQLTablesW according to the connection's Unicode mode
+func (c *Conn) odbcTables(stmt SQLHSTMT, catalogName, schemaName, tableName, tableType string) SQLRETURN {
+	if c.wideCatalog(catalogName, schemaName, tableName, tableType) {
+		return TablesW(stmt, catalogName, schemaName, tableName, tableType)
+	}
+	return Tables(stmt, catalogName, schemaName, tableName, tableType)
+}
+
+// odbcColumns calls SQLColumns or SQLColumnsW according to the connection's Unicode mode
+func (c *Conn) odbcColumns(stmt SQLHSTMT, catalogName, schemaName, tableName, columnName string) SQLRETURN {
+	if c.wideCatalog(catalogName, schemaName, tableName, columnName) {
+		return ColumnsW(stmt, catalogName, schemaName, tableName, columnName)
+	}
+	return Columns(stmt, catalogName, schemaName, tableName, columnName)
+}
+
+// odbcPrimaryKeys calls SQLPrimaryKeys or SQLPrimaryKeysW according to the
+// connection's Unicode mode
+func (c *Conn) odbcPrimaryKeys(stmt SQLHSTMT, catalogName, schemaName, tableName string) SQLRETURN {
+	if c.wideCatalog(catalogName, schemaName, tableName) {
+		return PrimaryKeysW(stmt, catalogName, schemaName, tableName)
+	}
+	return PrimaryKeys(stmt, catalogName, schemaName, tableName)
+}
+
+// odbcStatistics calls SQLStatistics or SQLStatisticsW according to the
+// connection's Unicode mode
+func (c *Conn) odbcStatistics(stmt SQLHSTMT, catalogName, schemaName, tableName string, unique, reserved SQLUSMALLINT) SQLRETURN {
+	if c.wideCatalog(catalogName, schemaName, tableName) {
+		return StatisticsW(stmt, catalogName, schemaName, tableName, unique, reserved)
+	}
+	return Statistics(stmt, catalogName, schemaName, tableName, unique, reserved)
+}
+
+// odbcForeignKeys calls SQLForeignKeys or SQLForeignKeysW according to the
+// connection's Unicode mode
+func (c *Conn) odbcForeignKeys(stmt SQLHSTMT, pkCatalogName, pkSchemaName, pkTableName, fkCatalogName, fkSchemaName, fkTableName string) SQLRETURN {
+	if c.wideCatalog(pkCatalogName, pkSchemaName, pkTableName, fkCatalogName, fkSchemaName, fkTableName) {
+		return ForeignKeysW(stmt, pkCatalogName, pkSchemaName, pkTableName, fkCatalogName, fkSchemaName, fkTableName)
+	}
+	return ForeignKeys(stmt, pkCatalogName, pkSchemaName, pkTableName, fkCatalogName, fkSchemaName, fkTableName)
+}
+
+// odbcTypeInfo calls SQLGetTypeInfo or SQLGetTypeInfoW according to the
+// connection's Unicode mode
+func (c *Conn) odbcTypeInfo(stmt SQLHSTMT, dataType SQLSMALLINT) SQLRETURN {
+	if wideOutput(c.unicodeMode()) {
+		return GetTypeInfoW(stmt, dataType)
+	}
+	return GetTypeInfo(stmt, dataType)
+}
+
+// setCursorName calls SQLSetCursorName or SQLSetCursorNameW according to the
+// connection's Unicode mode
+func (c *Conn) setCursorName(stmt SQLHSTMT, name string) SQLRETURN {
+	if useWideAPI(c.unicodeMode(), name) {
+		return SetCursorNameW(stmt, name)
+	}
+	return SetCursorName(stmt, name)
+}
+
+// cursorName reads a statement's cursor name with SQLGetCursorName or
+// SQLGetCursorNameW according to the connection's Unicode mode
+func (c *Conn) cursorName(stmt SQLHSTMT, maxLen int) (string, SQLRETURN) {
+	if wideOutput(c.unicodeMode()) {
+		return GetCursorNameW(stmt, maxLen-1)
+	}
+	buf := make([]byte, maxLen)
+	nameLen, ret := GetCursorName(stmt, buf)
+	if !IsSuccess(ret) {
+		return "", ret
+	}
+	return string(buf[:clampLen(nameLen, len(buf))]), ret
+}
+
+// needsWideName reports whether an ANSI column name may have lost characters
+// in conversion: it is not plain ASCII, or contains '?' substitutions
+func needsWideName(name string) bool {
+	return asciiPrefixLen(name) != len(name) || strings.Contains(name, "?")
+}
+
+// InvalidTextError is returned for a string parameter that is not valid UTF-8
+// when the connector rejects invalid text (see WithInvalidTextMode)
+type InvalidTextError struct {
+	Param  int // 1-based parameter number
+	Offset int // byte offset of the first invalid sequence
+}
+
+func (e *InvalidTextError) Error() string {
+	return fmt.Sprintf("parameter %d: string is not valid UTF-8 (invalid sequence at byte %d)", e.Param, e.Offset)
+}
+
+// checkText returns an *InvalidTextError if value is a string that is not
+// valid UTF-8 and the connection rejects invalid text
+func (c *Conn) checkText(paramNum int, value interface{}) error {
+	if c == nil || c.invalidText != InvalidTextReject {
+		return nil
+	}
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case WideString:
+		s = string(v)
+	default:
+		return nil
+	}
+	if n := asciiPrefixLen(s); n == len(s) || utf8.ValidString(s[n:]) {
+		return nil
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			return &InvalidTextError{Param: paramNum, Offset: i}
+		}
+		i += size
+	}
+	return nil
+}
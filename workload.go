@@ -0,0 +1,197 @@
+package godbc
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// workloadHintKey is the context key for workload hints
+type workloadHintKey struct{}
+
+// WorkloadHint labels statements for the database's resource governance, so
+// workload management rules and monitoring can tell services and request
+// classes apart. It is applied to the session before statements executed with
+// a context carrying it (see WithWorkloadHint).
+type WorkloadHint struct {
+	// Tag labels the statements: the QUERY_TAG on Snowflake, application_name
+	// on PostgreSQL, the session context key "query_tag" on SQL Server and the
+	// QueryTag query band name on Teradata
+	Tag string
+
+	// Attributes are further name/value pairs, set as session context keys on
+	// SQL Server and query band names on Teradata (e.g. "Priority": "low"), and
+	// ignored on other databases
+	Attributes map[string]string
+}
+
+// isZero reports whether the hint sets nothing
+func (h WorkloadHint) isZero() bool {
+	return h.Tag == "" && len(h.Attributes) == 0
+}
+
+// equal reports whether two hints set the same values
+func (h WorkloadHint) equal(o WorkloadHint) bool {
+	return h.Tag == o.Tag && maps.Equal(h.Attributes, o.Attributes)
+}
+
+// WithWorkloadHint returns a context carrying a workload hint for the
+// statements executed with it. Before running a statement, the connection
+// applies the hint with the database's session statement if it differs from
+// the one last applied, e.g. ALTER SESSION SET QUERY_TAG on Snowflake,
+// sp_set_session_context on SQL Server or SET QUERY_BAND ... FOR SESSION on
+// Teradata, and clears it for statements whose context has none, so a pooled
+// connection does not carry one request's hint to the next. Hints are ignored
+// on databases without a known mechanism. A hint that fails to apply is logged
+// and the statement still runs.
+//
+// Example:
+//
+//	ctx = godbc.WithWorkloadHint(ctx, godbc.WorkloadHint{Tag: "billing-report"})
+//	rows, err := db.QueryContext(ctx, "SELECT * FROM invoices")
+//	// preceded on Snowflake by: ALTER SESSION SET QUERY_TAG = 'billing-report'
+func WithWorkloadHint(ctx context.Context, hint WorkloadHint) context.Context {
+	return context.WithValue(ctx, workloadHintKey{}, hint)
+}
+
+// WorkloadHintFromContext returns the workload hint stored in ctx, or a zero
+// hint if there is none
+func WorkloadHintFromContext(ctx context.Context) WorkloadHint {
+	if ctx == nil {
+		return WorkloadHint{}
+	}
+	hint, _ := ctx.Value(workloadHintKey{}).(WorkloadHint)
+	return hint
+}
+
+// workloadDialects maps database types to the statements that change the
+// session's workload hint from prev to hint, which is zero to clear it
+var workloadDialects = map[string]func(hint, prev WorkloadHint) []string{
+	"snowflake": func(hint, prev WorkloadHint) []string {
+		if hint.Tag == "" {
+			return []string{"ALTER SESSION UNSET QUERY_TAG"}
+		}
+		return []string{"ALTER SESSION SET QUERY_TAG = " + workloadLiteral(hint.Tag)}
+	},
+	"sql server": func(hint, prev WorkloadHint) []string {
+		values := map[string]string{"query_tag": hint.Tag}
+		for k, v := range hint.Attributes {
+			values[k] = v
+		}
+		// Keys of the previous hint that the new one drops are set to NULL
+		keys := []string{"query_tag"}
+		for k := range prev.Attributes {
+			keys = append(keys, k)
+		}
+		for k := range hint.Attributes {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		var stmts []string
+		for _, k := range slices.Compact(keys) {
+			value := "NULL"
+			if v := values[k]; v != "" {
+				value = "N" + workloadLiteral(v)
+			}
+			stmts = append(stmts, "EXEC sp_set_session_context N"+workloadLiteral(k)+", "+value)
+		}
+		return stmts
+	},
+	"teradata": func(hint, prev WorkloadHint) []string {
+		if hint.isZero() {
+			return []string{"SET QUERY_BAND = NONE FOR SESSION"}
+		}
+		var band strings.Builder
+		if hint.Tag != "" {
+			band.WriteString("QueryTag=" + queryBandValue(hint.Tag) + ";")
+		}
+		for _, k := range slices.Sorted(maps.Keys(hint.Attributes)) {
+			band.WriteString(queryBandValue(k) + "=" + queryBandValue(hint.Attributes[k]) + ";")
+		}
+		return []string{"SET QUERY_BAND = " + workloadLiteral(band.String()) + " FOR SESSION"}
+	},
+	"postgresql": func(hint, prev WorkloadHint) []string {
+		if hint.Tag == "" {
+			return []string{"RESET application_name"}
+		}
+		return []string{"SET application_name = " + workloadLiteral(hint.Tag)}
+	},
+}
+
+// workloadLiteral quotes s as a SQL string literal
+func workloadLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// queryBandValue replaces the characters that separate Teradata query band
+// pairs, so a name or value cannot add pairs of its own
+func queryBandValue(s string) string {
+	return strings.NewReplacer(";", "_", "=", "_").Replace(s)
+}
+
+// workloadStatements returns the statements that apply the context's workload
+// hint to the session, and the hint they apply. It returns no statements if
+// the hint is already applied or the database has no known mechanism.
+func (c *Conn) workloadStatements(ctx context.Context) ([]string, WorkloadHint) {
+	hint := WorkloadHintFromContext(ctx)
+	if hint.equal(c.workloadHint) {
+		return nil, hint
+	}
+	if dbTypeLower := strings.ToLower(c.dbType); dbTypeLower != "" {
+		for dbName, dialect := range workloadDialects {
+			if strings.Contains(dbTypeLower, dbName) {
+				return dialect(hint, c.workloadHint), hint
+			}
+		}
+	}
+	return nil, hint
+}
+
+// applyWorkloadHint applies the context's workload hint to the session before
+// a statement runs. A statement that fails is logged; the hint is still
+// recorded as applied, so a database that rejects it is not asked again for
+// every statement.
+func (c *Conn) applyWorkloadHint(ctx context.Context) {
+	stmts, hint := c.workloadStatements(ctx)
+	c.workloadHint = hint
+	for _, query := range stmts {
+		if err := c.execWorkloadStatement(ctx, query); err != nil {
+			if c.logger != nil {
+				c.logger.LogAttrs(ctx, slog.LevelWarn, "odbc workload hint not applied", logAttrs(ctx,
+					slog.String("dbms", c.dbType),
+					slog.String("query", query),
+					slog.String("error", err.Error()))...)
+			}
+			return
+		}
+	}
+}
+
+// execWorkloadStatement runs a statement that applies a workload hint
+func (c *Conn) execWorkloadStatement(ctx context.Context, query string) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	var stmtHandle SQLHSTMT
+	if !IsSuccess(AllocHandle(SQL_HANDLE_STMT, SQLHANDLE(c.dbc), (*SQLHANDLE)(&stmtHandle))) {
+		err := c.newError(SQL_HANDLE_DBC, SQLHANDLE(c.dbc))
+		c.mu.Unlock()
+		return err
+	}
+	c.mu.Unlock()
+	defer FreeHandle(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+
+	if secs := c.queryTimeoutSecs(ctx); secs > 0 {
+		SetStmtAttr(stmtHandle, SQL_ATTR_QUERY_TIMEOUT, secs, 0)
+	}
+	ret := c.execDirect(stmtHandle, query)
+	if !IsSuccess(ret) && ret != SQL_NO_DATA {
+		return c.newError(SQL_HANDLE_STMT, SQLHANDLE(stmtHandle))
+	}
+	c.logInfo(ctx, ret, stmtHandle, "workload hint")
+	return nil
+}